@@ -0,0 +1,58 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	rollbackBackupFlag = &cli.StringFlag{
+		Name:  "backup",
+		Usage: "Name of the backup to restore, the most recent one if not set",
+	}
+	rollbackListFlag = &cli.BoolFlag{
+		Name:  "list",
+		Usage: "List the backups instead of restoring one",
+	}
+
+	rollbackCommand = &cli.Command{
+		Action:    rollbackAction,
+		Name:      "rollback-to-backup",
+		Usage:     "Restore the databases backed up before an upgrade, see --backup-on-upgrade; the node must be stopped",
+		ArgsUsage: " ",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Flags:     []cli.Flag{rollbackBackupFlag, rollbackListFlag},
+	}
+)
+
+func rollbackAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	names, err := cfg.ListBackups()
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(rollbackListFlag.Name) {
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	name := ctx.String(rollbackBackupFlag.Name)
+	if name == "" {
+		if len(names) == 0 {
+			return fmt.Errorf("there are no backups in the data directory %v", cfg.DataPath)
+		}
+		name = names[len(names)-1]
+	}
+	version, err := cfg.RollbackToBackup(name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored the backup %v, start the node with version %v\n", name, version)
+	return nil
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	configCommand = &cli.Command{
+		Name:     "config",
+		Usage:    "Inspect the configuration of the node",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    configCheckAction,
+				Name:      "check",
+				Usage:     "Validate the config file and the flags and print the effective configuration, including the defaults, without starting the node",
+				ArgsUsage: " ",
+			},
+		},
+	}
+)
+
+func configCheckAction(ctx *cli.Context) error {
+	cfg, err := loadConfig(ctx, true)
+	if err != nil {
+		return err
+	}
+	j, err := json.MarshalIndent(cfg.Redacted(), "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%v\n", string(j))
+	if cfg.Dev.Enabled {
+		fmt.Printf("The developer mode settings are applied when the node starts\n")
+	}
+	fmt.Printf("The configuration is valid\n")
+	return nil
+}
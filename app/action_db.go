@@ -0,0 +1,46 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	dbCompactDatabaseFlag = &cli.StringSliceFlag{
+		Name:  "database",
+		Usage: "Name of a database to compact, like nom or consensus, all of them if not set",
+	}
+
+	dbCommand = &cli.Command{
+		Name:     "db",
+		Usage:    "Maintain the databases of the data directory",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    dbCompactAction,
+				Name:      "compact",
+				Usage:     "Compact the databases, reclaiming the space of the deleted and overwritten values; the node must be stopped",
+				ArgsUsage: " ",
+				Flags:     []cli.Flag{dbCompactDatabaseFlag},
+			},
+		},
+	}
+)
+
+func dbCompactAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = cfg.CompactDatabases(ctx.StringSlice(dbCompactDatabaseFlag.Name), func(name string, elapsed time.Duration) {
+		fmt.Printf("compacted %v in %v\n", name, elapsed.Round(time.Millisecond))
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("compacted the databases of %v in %v\n", cfg.DataPath, time.Since(start).Round(time.Millisecond))
+	return nil
+}
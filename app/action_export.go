@@ -0,0 +1,155 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/chain/export"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	exportAddressFlag = &cli.StringFlag{
+		Name:     "address",
+		Usage:    "Address of the account-chain to export",
+		Required: true,
+	}
+	exportFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Export format, csv or json",
+		Value: export.FormatCSV,
+	}
+	exportOutFlag = &cli.StringFlag{
+		Name:     "out",
+		Usage:    "File to write the export to",
+		Required: true,
+	}
+	exportChainFormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Export format, parquet or protobuf",
+		Value: export.FormatParquet,
+	}
+	exportFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "Height of the first momentum to export",
+		Value: 1,
+	}
+	exportToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Height of the last momentum to export, the frontier momentum if 0",
+	}
+	exportOutDirFlag = &cli.StringFlag{
+		Name:     "out",
+		Usage:    "Directory to write the momentums and account-blocks files to",
+		Required: true,
+	}
+
+	exportCommand = &cli.Command{
+		Name:     "export",
+		Usage:    "Export ledger data, the node must be stopped",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    exportAccountAction,
+				Name:      "account",
+				Usage:     "Export the account-chain of an address with token symbols, display amounts and momentum timestamps",
+				ArgsUsage: " ",
+				Flags:     []cli.Flag{exportAddressFlag, exportFormatFlag, exportOutFlag},
+			},
+			{
+				Action:    exportChainAction,
+				Name:      "chain",
+				Usage:     "Export the momentums and account-blocks of a range of momentums for the analytics tools, with the calls of the embedded contracts decoded",
+				ArgsUsage: " ",
+				Flags:     []cli.Flag{exportChainFormatFlag, exportFromFlag, exportToFlag, exportOutDirFlag},
+			},
+		},
+	}
+)
+
+func exportAccountAction(ctx *cli.Context) error {
+	address, err := types.ParseAddress(ctx.String(exportAddressFlag.Name))
+	if err != nil {
+		return err
+	}
+	format := ctx.String(exportFormatFlag.Name)
+	if format != export.FormatCSV && format != export.FormatJSON {
+		return fmt.Errorf("invalid format %q, expected %v or %v", format, export.FormatCSV, export.FormatJSON)
+	}
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(ctx.String(exportOutFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ch, err := cfg.OpenChain()
+	if err != nil {
+		return err
+	}
+	defer ch.Stop()
+
+	return export.AccountChain(ch, address, format, file)
+}
+
+func exportChainAction(ctx *cli.Context) error {
+	format := ctx.String(exportChainFormatFlag.Name)
+	extension := map[string]string{export.FormatParquet: "parquet", export.FormatProtobuf: "pb"}[format]
+	if extension == "" {
+		return fmt.Errorf("invalid format %q, expected %v or %v", format, export.FormatParquet, export.FormatProtobuf)
+	}
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	dir := ctx.String(exportOutDirFlag.Name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	momentums, err := os.Create(filepath.Join(dir, "momentums."+extension))
+	if err != nil {
+		return err
+	}
+	defer momentums.Close()
+	accountBlocks, err := os.Create(filepath.Join(dir, "account-blocks."+extension))
+	if err != nil {
+		return err
+	}
+	defer accountBlocks.Close()
+
+	ch, err := cfg.OpenChain()
+	if err != nil {
+		return err
+	}
+	defer ch.Stop()
+
+	momentumsWriter := bufio.NewWriter(momentums)
+	accountBlocksWriter := bufio.NewWriter(accountBlocks)
+	var exported uint64
+	err = export.Ledger(ch, ctx.Uint64(exportFromFlag.Name), ctx.Uint64(exportToFlag.Name), format, momentumsWriter, accountBlocksWriter, func(height uint64) {
+		exported += 1
+		if height%verifyProgressStep == 0 {
+			fmt.Printf("exported momentums up to height %v\n", height)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if err := momentumsWriter.Flush(); err != nil {
+		return err
+	}
+	if err := accountBlocksWriter.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("exported %v momentums to %v\n", exported, dir)
+	return nil
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	genesisChainIdFlag = &cli.Uint64Flag{
+		Name:     "chain-id",
+		Usage:    "Chain identifier of the network, also used as p2p network id; 1 is the mainnet",
+		Required: true,
+	}
+	genesisTimestampFlag = &cli.Int64Flag{
+		Name:  "timestamp",
+		Usage: "Unix timestamp of the genesis, the current time if not set",
+	}
+	genesisExtraDataFlag = &cli.StringFlag{
+		Name:  "extra-data",
+		Usage: "Extra data of the genesis momentum",
+	}
+	genesisSporkAddressFlag = &cli.StringFlag{
+		Name:  "spork-address",
+		Usage: "Address allowed to create and activate sporks, the address of the first pillar if not set",
+	}
+	genesisPillarFlag = &cli.StringSliceFlag{
+		Name:     "pillar",
+		Usage:    "Pillar as name:address, the address produces the momentums; can be repeated",
+		Required: true,
+	}
+	genesisBalanceFlag = &cli.StringSliceFlag{
+		Name:  "balance",
+		Usage: "Initial balance as address:znn:qsr, amounts in coins with up to 8 decimals; can be repeated",
+	}
+	genesisFusionFlag = &cli.StringSliceFlag{
+		Name:  "fusion",
+		Usage: "QSR fused for the plasma of an address as address:qsr; can be repeated",
+	}
+	genesisSporkFlag = &cli.StringSliceFlag{
+		Name:  "spork",
+		Usage: "Id of an implemented spork enforced from genesis, or all; can be repeated",
+	}
+	genesisOutFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the genesis to",
+		Value: "genesis.json",
+	}
+
+	genesisCommand = &cli.Command{
+		Name:     "genesis",
+		Usage:    "Manage the genesis of private networks",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action: genesisGenerateAction,
+				Name:   "generate",
+				Usage:  "Generate a genesis file, the nodes of the network are started with --genesis",
+				Flags: []cli.Flag{
+					genesisChainIdFlag, genesisTimestampFlag, genesisExtraDataFlag, genesisSporkAddressFlag,
+					genesisPillarFlag, genesisBalanceFlag, genesisFusionFlag, genesisSporkFlag, genesisOutFlag,
+				},
+				ArgsUsage: " ",
+			},
+		},
+	}
+)
+
+func genesisGenerateAction(ctx *cli.Context) error {
+	cfg := &genesis.DevnetConfig{
+		ChainIdentifier:     ctx.Uint64(genesisChainIdFlag.Name),
+		ExtraData:           ctx.String(genesisExtraDataFlag.Name),
+		GenesisTimestampSec: time.Now().Unix(),
+	}
+	if ctx.IsSet(genesisTimestampFlag.Name) {
+		cfg.GenesisTimestampSec = ctx.Int64(genesisTimestampFlag.Name)
+	}
+
+	for _, value := range ctx.StringSlice(genesisPillarFlag.Name) {
+		fields, err := splitGenesisValue(value, 2, genesisPillarFlag.Name)
+		if err != nil {
+			return err
+		}
+		address, err := types.ParseAddress(fields[1])
+		if err != nil {
+			return err
+		}
+		cfg.Pillars = append(cfg.Pillars, &genesis.DevnetPillar{Name: fields[0], Address: address})
+	}
+	for _, value := range ctx.StringSlice(genesisBalanceFlag.Name) {
+		fields, err := splitGenesisValue(value, 3, genesisBalanceFlag.Name)
+		if err != nil {
+			return err
+		}
+		balance := new(genesis.DevnetBalance)
+		if balance.Address, err = types.ParseAddress(fields[0]); err != nil {
+			return err
+		}
+		if balance.Znn, err = parseCoins(fields[1]); err != nil {
+			return err
+		}
+		if balance.Qsr, err = parseCoins(fields[2]); err != nil {
+			return err
+		}
+		cfg.Balances = append(cfg.Balances, balance)
+	}
+	for _, value := range ctx.StringSlice(genesisFusionFlag.Name) {
+		fields, err := splitGenesisValue(value, 2, genesisFusionFlag.Name)
+		if err != nil {
+			return err
+		}
+		fusion := new(genesis.DevnetFusion)
+		if fusion.Address, err = types.ParseAddress(fields[0]); err != nil {
+			return err
+		}
+		if fusion.Qsr, err = parseCoins(fields[1]); err != nil {
+			return err
+		}
+		cfg.Fusions = append(cfg.Fusions, fusion)
+	}
+	for _, value := range ctx.StringSlice(genesisSporkFlag.Name) {
+		if value == "all" {
+			cfg.ActiveSporks = append(cfg.ActiveSporks, types.AcceleratorSpork.SporkId, types.HtlcSpork.SporkId,
+				types.BridgeAndLiquiditySpork.SporkId, types.PayloadLimitsSpork.SporkId)
+			continue
+		}
+		id, err := types.HexToHash(value)
+		if err != nil {
+			return err
+		}
+		cfg.ActiveSporks = append(cfg.ActiveSporks, id)
+	}
+
+	if ctx.IsSet(genesisSporkAddressFlag.Name) {
+		address, err := types.ParseAddress(ctx.String(genesisSporkAddressFlag.Name))
+		if err != nil {
+			return err
+		}
+		cfg.SporkAddress = address
+	} else if len(cfg.Pillars) != 0 {
+		cfg.SporkAddress = cfg.Pillars[0].Address
+	}
+
+	config, err := genesis.GenerateGenesis(cfg)
+	if err != nil {
+		return err
+	}
+	out := ctx.String(genesisOutFlag.Name)
+	if err := genesis.WriteGenesisConfigToFile(config, out); err != nil {
+		return err
+	}
+	fmt.Printf("wrote the genesis of chain %v with the momentum %v to %v\n", config.ChainIdentifier, genesis.NewGenesis(config).GetGenesisMomentum().Hash, out)
+	fmt.Printf("start the nodes with --genesis %v and set their seeders to nodes of this network\n", out)
+	return nil
+}
+
+func splitGenesisValue(value string, count int, flag string) ([]string, error) {
+	fields := strings.Split(value, ":")
+	if len(fields) != count {
+		return nil, fmt.Errorf("invalid --%v %q, expected %v fields separated by ':'", flag, value, count)
+	}
+	return fields, nil
+}
+
+// parseCoins parses an amount in coins of 8 decimals, like 12.5, to the base unit
+func parseCoins(value string) (*big.Int, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) > 8 {
+		return nil, fmt.Errorf("invalid amount %q, at most 8 decimals are allowed", value)
+	}
+	amount, ok := new(big.Int).SetString(whole+fraction+strings.Repeat("0", 8-len(fraction)), 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/pillar"
+)
+
+var (
+	leaseListenFlag = &cli.StringFlag{
+		Name:  "listen",
+		Usage: "Address the nodes connect to, unix:<path> for a local socket or host:port",
+		Value: pillar.DefaultLeaseAddress,
+	}
+	leaseSecretFileFlag = &cli.StringFlag{
+		Name:     "secret-file",
+		Usage:    "File with the secret the nodes authenticate with, see Producer.LeaseSecretFile",
+		Required: true,
+	}
+
+	leaseCommand = &cli.Command{
+		Action:    leaseAction,
+		Name:      "lease",
+		Usage:     "Run the lease server which lets one of the nodes sharing a producer address produce at a time, see Producer.LeaseAddress",
+		Category:  "MISCELLANEOUS COMMANDS",
+		ArgsUsage: " ",
+		Flags:     []cli.Flag{leaseListenFlag, leaseSecretFileFlag},
+	}
+)
+
+func leaseAction(ctx *cli.Context) error {
+	secret, err := pillar.ReadSecretFile(ctx.String(leaseSecretFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	server, err := pillar.NewLeaseServer(secret)
+	if err != nil {
+		return err
+	}
+
+	network, listenAddress := pillar.SplitSignerAddress(ctx.String(leaseListenFlag.Name))
+	if network == "unix" {
+		// a socket left behind by a previous run
+		_ = os.Remove(listenAddress)
+	}
+	listener, err := net.Listen(network, listenAddress)
+	if err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	fmt.Printf("granting the producer lease on %v, after %v\n", listenAddress, pillar.MaxLeaseTerm)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pillar"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+var (
+	signerKeyStoreFlag = &cli.StringFlag{
+		Name:     "keystore",
+		Usage:    "Key store file with the producer key, relative to the wallet directory",
+		Required: true,
+	}
+	signerAddressFlag = &cli.StringFlag{
+		Name:     "address",
+		Usage:    "Producer address, it must match the key derived at --index",
+		Required: true,
+	}
+	signerIndexFlag = &cli.UintFlag{
+		Name:  "index",
+		Usage: "Derivation index of the producer key in the key store",
+	}
+	signerListenFlag = &cli.StringFlag{
+		Name:  "listen",
+		Usage: "Address the node connects to, unix:<path> for a local socket or host:port",
+		Value: pillar.DefaultSignerAddress,
+	}
+	signerSecretFileFlag = &cli.StringFlag{
+		Name:     "secret-file",
+		Usage:    "File with the secret the node authenticates with, see Producer.SignerSecretFile",
+		Required: true,
+	}
+	signerStateFlag = &cli.StringFlag{
+		Name:  "state",
+		Usage: "File recording the last signed momentum, so no conflicting momentum is signed after a restart",
+		Value: "signer-state.json",
+	}
+
+	signerCommand = &cli.Command{
+		Action:    signerAction,
+		Name:      "signer",
+		Usage:     "Run a remote signer which holds the producer key, so the producing node doesn't need it, see Producer.SignerAddress",
+		Category:  "MISCELLANEOUS COMMANDS",
+		ArgsUsage: " ",
+		Flags:     []cli.Flag{signerKeyStoreFlag, signerAddressFlag, signerIndexFlag, signerListenFlag, signerSecretFileFlag, signerStateFlag, walletPasswordFileFlag},
+	}
+)
+
+func signerAction(ctx *cli.Context) error {
+	address, err := types.ParseAddress(ctx.String(signerAddressFlag.Name))
+	if err != nil {
+		return err
+	}
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	manager := wallet.New(&wallet.Config{WalletDir: cfg.WalletPath})
+	if err := manager.Start(); err != nil {
+		return err
+	}
+	defer manager.Stop()
+
+	password, err := readPassword(bufio.NewReader(os.Stdin), ctx.String(walletPasswordFileFlag.Name), "Password: ")
+	if err != nil {
+		return err
+	}
+	keyStore, err := manager.GetKeyFileAndDecrypt(ctx.String(signerKeyStoreFlag.Name), password)
+	if err != nil {
+		return err
+	}
+	_, keyPair, err := keyStore.DeriveForIndexPath(uint32(ctx.Uint(signerIndexFlag.Name)))
+	if err != nil {
+		return err
+	}
+	if keyPair.Address != address {
+		return fmt.Errorf("producer address doesn't match. Expected %v but got %v", address, keyPair.Address)
+	}
+
+	secret, err := pillar.ReadSecretFile(ctx.String(signerSecretFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	statePath := ctx.String(signerStateFlag.Name)
+	if !filepath.IsAbs(statePath) {
+		statePath = filepath.Join(cfg.DataPath, statePath)
+	}
+	server, err := pillar.NewSignerServer(keyPair, secret, statePath)
+	if err != nil {
+		return err
+	}
+
+	network, listenAddress := pillar.SplitSignerAddress(ctx.String(signerListenFlag.Name))
+	if network == "tcp" {
+		host, port, err := net.SplitHostPort(listenAddress)
+		if err != nil {
+			return err
+		}
+		if host == "" {
+			// only reachable from the same host unless an address is given
+			listenAddress = net.JoinHostPort("127.0.0.1", port)
+		} else if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			fmt.Printf("warning: the signer listens on %v, it's reachable from other hosts\n", listenAddress)
+		}
+	}
+	if network == "unix" {
+		// a socket left behind by a previous run
+		_ = os.Remove(listenAddress)
+	}
+	listener, err := net.Listen(network, listenAddress)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		// keep the socket to the user of the node
+		if err := os.Chmod(listenAddress, 0600); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	fmt.Printf("signing for %v on %v\n", address, listenAddress)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
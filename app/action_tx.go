@@ -0,0 +1,419 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+var (
+	txKeyStoreFlag = &cli.StringFlag{
+		Name:     "keystore",
+		Usage:    "Key store file with the key of the account, relative to the wallet directory",
+		Required: true,
+	}
+	txAddressFlag = &cli.StringFlag{
+		Name:  "address",
+		Usage: "Address of the account, searched in the key store; the key at --index is used if not set",
+	}
+	txIndexFlag = &cli.UintFlag{
+		Name:  "index",
+		Usage: "Derivation index of the key of the account in the key store",
+	}
+	txRPCFlag = &cli.StringFlag{
+		Name:  "rpc",
+		Usage: "RPC endpoint of the node which prepares and publishes the blocks, an http(s) or ws(s) URL or an IPC path",
+		Value: fmt.Sprintf("ws://127.0.0.1:%v", p2p.DefaultWSPort),
+	}
+	txToFlag = &cli.StringFlag{
+		Name:     "to",
+		Usage:    "Address receiving the tokens",
+		Required: true,
+	}
+	txAmountFlag = &cli.StringFlag{
+		Name:     "amount",
+		Usage:    "Amount sent in base units, e.g. 100000000 for 1 ZNN",
+		Required: true,
+	}
+	txTokenFlag = &cli.StringFlag{
+		Name:  "token",
+		Usage: "Token standard of the tokens sent",
+		Value: types.ZnnTokenStandard.String(),
+	}
+	txDataFlag = &cli.StringFlag{
+		Name:  "data",
+		Usage: "Hex encoded data of the block, like the call of an embedded contract",
+	}
+	txHashFlag = &cli.StringFlag{
+		Name:     "hash",
+		Usage:    "Hash of the send block to receive",
+		Required: true,
+	}
+	txUnsignedFlag = &cli.BoolFlag{
+		Name:  "unsigned",
+		Usage: "Write the prepared block template to --out instead of signing it, see tx sign",
+	}
+	txOfflineFlag = &cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Write the signed block to --out instead of publishing it, see tx publish",
+	}
+	txOutFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "File to write the block to",
+	}
+
+	txCommand = &cli.Command{
+		Name:     "tx",
+		Usage:    "Build, sign and publish account-blocks with the keys of the wallet directory",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    txSendAction,
+				Name:      "send",
+				Usage:     "Send tokens, the node at --rpc prepares and publishes the block",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{txKeyStoreFlag, txAddressFlag, txIndexFlag, walletPasswordFileFlag, txRPCFlag,
+					txToFlag, txAmountFlag, txTokenFlag, txDataFlag, txUnsignedFlag, txOutFlag},
+			},
+			{
+				Action:    txReceiveAction,
+				Name:      "receive",
+				Usage:     "Receive a send block, the node at --rpc prepares and publishes the block",
+				ArgsUsage: " ",
+				Flags: []cli.Flag{txKeyStoreFlag, txAddressFlag, txIndexFlag, walletPasswordFileFlag, txRPCFlag,
+					txHashFlag, txUnsignedFlag, txOutFlag},
+			},
+			{
+				Action:    txSignAction,
+				Name:      "sign",
+				Usage:     "Sign a block template written by send or receive with --unsigned, computing the PoW if needed",
+				ArgsUsage: "<template file>",
+				Flags:     []cli.Flag{txKeyStoreFlag, walletPasswordFileFlag, txRPCFlag, txOfflineFlag, txOutFlag},
+			},
+			{
+				Action:    txPublishAction,
+				Name:      "publish",
+				Usage:     "Publish a block signed with sign --offline",
+				ArgsUsage: "<block file>",
+				Flags:     []cli.Flag{txRPCFlag},
+			},
+		},
+	}
+)
+
+// blockTemplate is the result of ledger.prepareAccountBlockTemplate, the format of the --unsigned templates.
+type blockTemplate struct {
+	Block              *nom.AccountBlock `json:"block"`
+	AvailablePlasma    uint64            `json:"availablePlasma"`
+	BasePlasma         uint64            `json:"basePlasma"`
+	RequiredDifficulty uint64            `json:"requiredDifficulty"`
+}
+
+func txSendAction(ctx *cli.Context) error {
+	toAddress, err := types.ParseAddress(ctx.String(txToFlag.Name))
+	if err != nil {
+		return err
+	}
+	amount, ok := new(big.Int).SetString(ctx.String(txAmountFlag.Name), 10)
+	if !ok || amount.Sign() < 0 {
+		return fmt.Errorf("invalid amount %q", ctx.String(txAmountFlag.Name))
+	}
+	token, err := types.ParseZTS(ctx.String(txTokenFlag.Name))
+	if err != nil {
+		return err
+	}
+	data, err := hex.DecodeString(ctx.String(txDataFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid data, %v", err)
+	}
+	return sendTemplate(ctx, &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		ToAddress:     toAddress,
+		Amount:        amount,
+		TokenStandard: token,
+		Data:          data,
+	})
+}
+
+func txReceiveAction(ctx *cli.Context) error {
+	fromBlockHash, err := types.HexToHash(ctx.String(txHashFlag.Name))
+	if err != nil {
+		return err
+	}
+	return sendTemplate(ctx, &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserReceive,
+		FromBlockHash: fromBlockHash,
+		Amount:        big.NewInt(0),
+	})
+}
+
+// sendTemplate prepares the block of the account set by the flags on the node, then signs and publishes it.
+// The prepared block is checked against the requested one first, the node only fills in the chain fields.
+func sendTemplate(ctx *cli.Context, block *nom.AccountBlock) error {
+	unsigned := ctx.Bool(txUnsignedFlag.Name)
+	if unsigned && !ctx.IsSet(txOutFlag.Name) {
+		return fmt.Errorf("--%v requires --%v", txUnsignedFlag.Name, txOutFlag.Name)
+	}
+
+	var keyPair *wallet.KeyPair
+	if unsigned {
+		if !ctx.IsSet(txAddressFlag.Name) {
+			return fmt.Errorf("--%v requires --%v", txUnsignedFlag.Name, txAddressFlag.Name)
+		}
+		address, err := types.ParseAddress(ctx.String(txAddressFlag.Name))
+		if err != nil {
+			return err
+		}
+		block.Address = address
+	} else {
+		keyStore, err := openTxKeyStore(ctx)
+		if err != nil {
+			return err
+		}
+		if ctx.IsSet(txAddressFlag.Name) {
+			address, err := types.ParseAddress(ctx.String(txAddressFlag.Name))
+			if err != nil {
+				return err
+			}
+			if keyPair, _, err = keyStore.FindAddress(address); err != nil {
+				return err
+			}
+		} else if _, keyPair, err = keyStore.DeriveForIndexPath(uint32(ctx.Uint(txIndexFlag.Name))); err != nil {
+			return err
+		}
+		block.Address = keyPair.Address
+	}
+
+	client, err := rpc.DialContext(ctx.Context, ctx.String(txRPCFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	template := new(blockTemplate)
+	if err := client.CallContext(ctx.Context, template, "ledger.prepareAccountBlockTemplate", block); err != nil {
+		return err
+	}
+	var frontier *types.HashHeight
+	if err := client.CallContext(ctx.Context, &frontier, "ledger.getFrontierAccountBlock", block.Address); err != nil {
+		return err
+	}
+	if err := checkTemplate(block, template.Block, frontier); err != nil {
+		return err
+	}
+	if unsigned {
+		if err := writeTxFile(ctx.String(txOutFlag.Name), template); err != nil {
+			return err
+		}
+		fmt.Printf("wrote the template of %v at height %v to %v\n", template.Block.Address, template.Block.Height, ctx.String(txOutFlag.Name))
+		return nil
+	}
+
+	if err := signBlock(ctx, template.Block, keyPair); err != nil {
+		return err
+	}
+	return publishBlock(ctx, client, template.Block)
+}
+
+func txSignAction(ctx *cli.Context) error {
+	offline := ctx.Bool(txOfflineFlag.Name)
+	if offline && !ctx.IsSet(txOutFlag.Name) {
+		return fmt.Errorf("--%v requires --%v", txOfflineFlag.Name, txOutFlag.Name)
+	}
+	if ctx.Args().Len() != 1 {
+		return fmt.Errorf("expected the template file")
+	}
+	template := new(blockTemplate)
+	if err := readTxFile(ctx.Args().First(), template); err != nil {
+		return err
+	}
+	if template.Block == nil {
+		return fmt.Errorf("the template has no block")
+	}
+
+	keyStore, err := openTxKeyStore(ctx)
+	if err != nil {
+		return err
+	}
+	keyPair, _, err := keyStore.FindAddress(template.Block.Address)
+	if err != nil {
+		return err
+	}
+	fmt.Print(describeBlock(template.Block))
+	if err := signBlock(ctx, template.Block, keyPair); err != nil {
+		return err
+	}
+
+	if offline {
+		if err := writeTxFile(ctx.String(txOutFlag.Name), template.Block); err != nil {
+			return err
+		}
+		fmt.Printf("wrote block %v to %v\n", template.Block.Hash, ctx.String(txOutFlag.Name))
+		return nil
+	}
+	client, err := rpc.DialContext(ctx.Context, ctx.String(txRPCFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return publishBlock(ctx, client, template.Block)
+}
+
+func txPublishAction(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return fmt.Errorf("expected the block file")
+	}
+	block := new(nom.AccountBlock)
+	if err := readTxFile(ctx.Args().First(), block); err != nil {
+		return err
+	}
+	if block.Hash != block.ComputeHash() {
+		return fmt.Errorf("block hash is %v, computed %v", block.Hash, block.ComputeHash())
+	}
+
+	client, err := rpc.DialContext(ctx.Context, ctx.String(txRPCFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return publishBlock(ctx, client, block)
+}
+
+func openTxKeyStore(ctx *cli.Context) (*wallet.KeyStore, error) {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manager := wallet.New(&wallet.Config{WalletDir: cfg.WalletPath})
+	if err := manager.Start(); err != nil {
+		return nil, err
+	}
+	defer manager.Stop()
+
+	password, err := readPassword(bufio.NewReader(os.Stdin), ctx.String(walletPasswordFileFlag.Name), "Password: ")
+	if err != nil {
+		return nil, err
+	}
+	return manager.GetKeyFileAndDecrypt(ctx.String(txKeyStoreFlag.Name), password)
+}
+
+// signBlock computes the PoW of the block if it has a difficulty, then its hash and signature.
+func signBlock(ctx *cli.Context, block *nom.AccountBlock, keyPair *wallet.KeyPair) error {
+	if block.Address != keyPair.Address {
+		return fmt.Errorf("the block is of %v, the key is of %v", block.Address, keyPair.Address)
+	}
+	if block.Difficulty > 0 {
+		fmt.Printf("computing the PoW of difficulty %v\n", block.Difficulty)
+		nonce, err := pow.GetPoWNonceWithContext(ctx.Context, new(big.Int).SetUint64(block.Difficulty), pow.GetAccountBlockHash(block))
+		if err != nil {
+			return err
+		}
+		copy(block.Nonce.Data[:], nonce)
+	}
+	block.Hash = block.ComputeHash()
+	block.PublicKey = keyPair.Public
+	block.Signature = keyPair.Sign(block.Hash.Bytes())
+	return nil
+}
+
+// checkTemplate returns an error if the node changed the requested fields of the block it prepared, or didn't
+// chain it to frontier, the frontier block of the account or nil if the account has none.
+func checkTemplate(requested, prepared *nom.AccountBlock, frontier *types.HashHeight) error {
+	if prepared == nil {
+		return fmt.Errorf("the node returned no block")
+	}
+	amount := requested.Amount
+	if requested.BlockType == nom.BlockTypeUserReceive {
+		amount = big.NewInt(0)
+	}
+	switch {
+	case prepared.BlockType != requested.BlockType:
+		return fmt.Errorf("the node changed the block type to %v", prepared.BlockType)
+	case prepared.Address != requested.Address:
+		return fmt.Errorf("the node changed the address to %v", prepared.Address)
+	case prepared.ToAddress != requested.ToAddress:
+		return fmt.Errorf("the node changed the receiving address to %v", prepared.ToAddress)
+	case prepared.Amount == nil || prepared.Amount.Cmp(amount) != 0:
+		return fmt.Errorf("the node changed the amount to %v", prepared.Amount)
+	case prepared.TokenStandard != requested.TokenStandard:
+		return fmt.Errorf("the node changed the token standard to %v", prepared.TokenStandard)
+	case !bytes.Equal(prepared.Data, requested.Data):
+		return fmt.Errorf("the node changed the data to %x", prepared.Data)
+	case prepared.FromBlockHash != requested.FromBlockHash:
+		return fmt.Errorf("the node changed the received block to %v", prepared.FromBlockHash)
+	}
+
+	previous := types.HashHeight{}
+	if frontier != nil {
+		previous = *frontier
+	}
+	if prepared.PreviousHash != previous.Hash || prepared.Height != previous.Height+1 {
+		return fmt.Errorf("the block at height %v follows %v, the frontier of the account is %v at height %v",
+			prepared.Height, prepared.PreviousHash, previous.Hash, previous.Height)
+	}
+	return nil
+}
+
+// describeBlock returns the fields of the block shown before it's signed, with the decoded call if it's sent
+// to an embedded contract.
+func describeBlock(block *nom.AccountBlock) string {
+	var description bytes.Buffer
+	fmt.Fprintf(&description, "block type:     %v\n", block.BlockType)
+	fmt.Fprintf(&description, "address:        %v\n", block.Address)
+	fmt.Fprintf(&description, "height:         %v\n", block.Height)
+	fmt.Fprintf(&description, "previous hash:  %v\n", block.PreviousHash)
+	if nom.IsSendBlock(block.BlockType) {
+		fmt.Fprintf(&description, "to address:     %v\n", block.ToAddress)
+		fmt.Fprintf(&description, "amount:         %v\n", block.Amount)
+		fmt.Fprintf(&description, "token standard: %v\n", block.TokenStandard)
+	} else {
+		fmt.Fprintf(&description, "received block: %v\n", block.FromBlockHash)
+	}
+	if len(block.Data) != 0 {
+		fmt.Fprintf(&description, "data:           %x\n", block.Data)
+		if call, err := embeddedabi.Decode(block.ToAddress, block.Data); err == nil {
+			fmt.Fprintf(&description, "method:         %v\n", call.Method)
+			for _, input := range call.Inputs {
+				fmt.Fprintf(&description, "  %v (%v): %v\n", input.Name, input.Type, input.Value)
+			}
+		}
+	}
+	return description.String()
+}
+
+func publishBlock(ctx *cli.Context, client *rpc.Client, block *nom.AccountBlock) error {
+	if err := client.CallContext(ctx.Context, nil, "ledger.publishRawTransaction", block); err != nil {
+		return err
+	}
+	fmt.Printf("published block %v of %v at height %v\n", block.Hash, block.Address, block.Height)
+	return nil
+}
+
+func readTxFile(file string, value interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+func writeTxFile(file string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0600)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+var (
+	testTxAddress = types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+	testTxTo      = types.ParseAddressPanic("z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7")
+)
+
+func testTxRequest() *nom.AccountBlock {
+	return &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       testTxAddress,
+		ToAddress:     testTxTo,
+		Amount:        big.NewInt(100),
+		TokenStandard: types.ZnnTokenStandard,
+		Data:          []byte{1, 2},
+	}
+}
+
+// testTxPrepared returns the block the node prepares for request, after the frontier.
+func testTxPrepared(request *nom.AccountBlock, frontier *types.HashHeight) *nom.AccountBlock {
+	prepared := *request
+	prepared.Amount = new(big.Int).Set(request.Amount)
+	if frontier != nil {
+		prepared.PreviousHash = frontier.Hash
+		prepared.Height = frontier.Height + 1
+	} else {
+		prepared.Height = 1
+	}
+	prepared.ChainIdentifier = 1
+	prepared.FusedPlasma = 21000
+	return &prepared
+}
+
+// Test checkTemplate
+//   - test the templates which only fill the chain fields are accepted
+//   - test a change of any requested field is refused
+//   - test a template which isn't chained to the frontier of the account is refused
+func TestCheckTemplate(t *testing.T) {
+	frontier := &types.HashHeight{Hash: types.HexToHashPanic("aa00000000000000000000000000000000000000000000000000000000000000"), Height: 5}
+	if err := checkTemplate(testTxRequest(), testTxPrepared(testTxRequest(), frontier), frontier); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkTemplate(testTxRequest(), testTxPrepared(testTxRequest(), nil), nil); err != nil {
+		t.Fatalf("first block of the account refused: %v", err)
+	}
+	receive := &nom.AccountBlock{BlockType: nom.BlockTypeUserReceive, Address: testTxAddress, FromBlockHash: frontier.Hash}
+	prepared := testTxPrepared(&nom.AccountBlock{BlockType: nom.BlockTypeUserReceive, Address: testTxAddress, FromBlockHash: frontier.Hash, Amount: big.NewInt(0)}, frontier)
+	if err := checkTemplate(receive, prepared, frontier); err != nil {
+		t.Fatalf("receive block refused: %v", err)
+	}
+
+	for name, change := range map[string]func(*nom.AccountBlock){
+		"block type":     func(b *nom.AccountBlock) { b.BlockType = nom.BlockTypeUserReceive },
+		"address":        func(b *nom.AccountBlock) { b.Address = testTxTo },
+		"to address":     func(b *nom.AccountBlock) { b.ToAddress = testTxAddress },
+		"amount":         func(b *nom.AccountBlock) { b.Amount = big.NewInt(1000) },
+		"token standard": func(b *nom.AccountBlock) { b.TokenStandard = types.QsrTokenStandard },
+		"data":           func(b *nom.AccountBlock) { b.Data = []byte{1} },
+		"received block": func(b *nom.AccountBlock) { b.FromBlockHash = frontier.Hash },
+		"previous hash":  func(b *nom.AccountBlock) { b.PreviousHash = types.ZeroHash },
+		"height":         func(b *nom.AccountBlock) { b.Height = 1 },
+	} {
+		prepared := testTxPrepared(testTxRequest(), frontier)
+		change(prepared)
+		if err := checkTemplate(testTxRequest(), prepared, frontier); err == nil {
+			t.Errorf("template with another %v accepted", name)
+		}
+	}
+}
+
+// Test describeBlock shows the fields of the block and the decoded call of an embedded contract
+func TestDescribeBlock(t *testing.T) {
+	block := testTxPrepared(testTxRequest(), nil)
+	block.ToAddress = types.PillarContract
+	block.Data = definition.ABICommon.PackMethodPanic(definition.CollectRewardMethodName)
+	description := describeBlock(block)
+	for _, expected := range []string{testTxAddress.String(), types.PillarContract.String(), "100", types.ZnnTokenStandard.String(), definition.CollectRewardMethodName} {
+		if !strings.Contains(description, expected) {
+			t.Errorf("description doesn't show %v:\n%v", expected, description)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/chain/verify"
+)
+
+const (
+	// the verification progress is printed every verifyProgressStep momentums
+	verifyProgressStep = 10000
+)
+
+var (
+	verifyFromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "Height of the first momentum to verify",
+		Value: 1,
+	}
+	verifyToFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "Height of the last momentum to verify, the frontier momentum if 0",
+	}
+
+	verifyChainCommand = &cli.Command{
+		Action:    verifyChainAction,
+		Name:      "verify-chain",
+		Usage:     "Verify the hashes, signatures and links of the momentums and account-blocks and the hashes of their changes, the node must be stopped",
+		ArgsUsage: " ",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Flags:     []cli.Flag{verifyFromFlag, verifyToFlag},
+	}
+)
+
+func verifyChainAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	ch, err := cfg.OpenChain()
+	if err != nil {
+		return err
+	}
+	defer ch.Stop()
+
+	var verified uint64
+	corruption, err := verify.Momentums(ch, ctx.Uint64(verifyFromFlag.Name), ctx.Uint64(verifyToFlag.Name), func(height uint64) {
+		verified += 1
+		if height%verifyProgressStep == 0 {
+			fmt.Printf("verified momentums up to height %v\n", height)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if corruption != nil {
+		fmt.Printf("first corrupt height: %v\n", corruption.Height)
+		return corruption
+	}
+	fmt.Printf("verified %v momentums, no corruption found\n", verified)
+	return nil
+}
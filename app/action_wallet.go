@@ -0,0 +1,125 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+var (
+	walletKeyStoreFlag = &cli.StringFlag{
+		Name:  "keystore",
+		Usage: "Key store file to upgrade, relative to the wallet directory; all the key stores are upgraded if not set",
+	}
+	walletPasswordFileFlag = &cli.StringFlag{
+		Name:  "password-file",
+		Usage: "File with the password of the key stores, asked on the standard input if not set",
+	}
+	walletNewPasswordFileFlag = &cli.StringFlag{
+		Name:  "new-password-file",
+		Usage: "File with the new password, changes the password of the key store set by --keystore",
+	}
+
+	walletCommand = &cli.Command{
+		Name:     "wallet",
+		Usage:    "Manage the key stores of the wallet directory",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []*cli.Command{
+			{
+				Action:    walletUpgradeAction,
+				Name:      "upgrade",
+				Usage:     "Re-encrypt the key stores with the default argon2 parameters, or with a new password",
+				ArgsUsage: " ",
+				Flags:     []cli.Flag{walletKeyStoreFlag, walletPasswordFileFlag, walletNewPasswordFileFlag},
+			},
+		},
+	}
+)
+
+func walletUpgradeAction(ctx *cli.Context) error {
+	cfg, err := MakeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	manager := wallet.New(&wallet.Config{WalletDir: cfg.WalletPath})
+	if err := manager.Start(); err != nil {
+		return err
+	}
+	defer manager.Stop()
+
+	stdin := bufio.NewReader(os.Stdin)
+	password, err := readPassword(stdin, ctx.String(walletPasswordFileFlag.Name), "Password: ")
+	if err != nil {
+		return err
+	}
+	keyStore := ctx.String(walletKeyStoreFlag.Name)
+
+	if ctx.IsSet(walletNewPasswordFileFlag.Name) {
+		if keyStore == "" {
+			return fmt.Errorf("--%v requires --%v", walletNewPasswordFileFlag.Name, walletKeyStoreFlag.Name)
+		}
+		newPassword, err := readPassword(stdin, ctx.String(walletNewPasswordFileFlag.Name), "")
+		if err != nil {
+			return err
+		}
+		if err := manager.ChangePassword(keyStore, password, newPassword); err != nil {
+			return err
+		}
+		fmt.Printf("changed the password of %v\n", manager.MakePathAbsolut(keyStore))
+		return nil
+	}
+
+	if keyStore != "" {
+		upgraded, err := manager.Upgrade(keyStore, password)
+		if err != nil {
+			return err
+		}
+		printUpgradeResult(&wallet.UpgradeResult{Path: manager.MakePathAbsolut(keyStore), Upgraded: upgraded})
+		return nil
+	}
+
+	results, err := manager.UpgradeAll(password)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Printf("no key stores found in %v\n", cfg.WalletPath)
+	}
+	for _, result := range results {
+		printUpgradeResult(result)
+	}
+	return nil
+}
+
+func printUpgradeResult(result *wallet.UpgradeResult) {
+	switch {
+	case result.Error != "":
+		fmt.Printf("%v: skipped, %v\n", result.Path, result.Error)
+	case result.Upgraded:
+		fmt.Printf("%v: upgraded\n", result.Path)
+	default:
+		fmt.Printf("%v: already up to date\n", result.Path)
+	}
+}
+
+// readPassword reads the first line of the file, or asks for it on the standard input if there's no file.
+func readPassword(stdin *bufio.Reader, file, prompt string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+	}
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
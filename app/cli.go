@@ -2,8 +2,6 @@ package app
 
 import (
 	"fmt"
-	"net/http"
-	_ "net/http/pprof"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -53,7 +51,17 @@ func init() {
 	//Import: Please add the New command here
 	app.Commands = []*cli.Command{
 		versionCommand,
+		exportCommand,
+		genesisCommand,
+		rollbackCommand,
+		walletCommand,
+		signerCommand,
+		leaseCommand,
+		txCommand,
+		verifyChainCommand,
+		dbCommand,
 		licenseCommand,
+		configCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -73,22 +81,6 @@ func beforeAction(ctx *cli.Context) error {
 	fmt.Printf("znnd will use at most %v cpu-cores\n", max)
 	runtime.GOMAXPROCS(max)
 
-	// pprof server
-	if ctx.IsSet(PprofFlag.Name) {
-		listenHost := ctx.String(PprofAddrFlag.Name)
-
-		port := ctx.Int(PprofPortFlag.Name)
-
-		address := fmt.Sprintf("%s:%d", listenHost, port)
-
-		log.Info("Starting pprof server", "addr", fmt.Sprintf("http://%s/debug/pprof", address))
-		go func() {
-			if err := http.ListenAndServe(address, nil); err != nil {
-				log.Error("Failure in running pprof server", "err", err)
-			}
-		}()
-	}
-
 	return nil
 }
 
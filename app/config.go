@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 
@@ -15,6 +16,29 @@ import (
 var defaultNodeConfigFileName = "config.json"
 
 func MakeConfig(ctx *cli.Context) (*node.Config, error) {
+	cfg, err := loadConfig(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4: Config log to file
+	common.InitLogging(cfg.DataPath, cfg.MakeLogConfig())
+
+	// 5: Log config, without its secrets
+	if j, err := json.MarshalIndent(cfg.Redacted(), "", "    "); err == nil {
+		fmt.Printf("Using the following znnd config: %v\n", string(j))
+	}
+	log.Info("using znnd config", "config", cfg.Redacted())
+	if cfg.Dev.Enabled {
+		printDevAccounts()
+	}
+
+	return cfg, nil
+}
+
+// loadConfig builds the config from the defaults, the config file and the flags, and validates it. A dry run
+// doesn't write anything, so the developer mode settings are not applied.
+func loadConfig(ctx *cli.Context, dryRun bool) (*node.Config, error) {
 	cfg := node.DefaultNodeConfig
 
 	// 1: Load config file.
@@ -25,21 +49,26 @@ func MakeConfig(ctx *cli.Context) (*node.Config, error) {
 
 	// 2: Apply flags, Overwrite the configuration file configuration
 	applyFlagsToConfig(ctx, &cfg)
+	if cfg.Dev.Enabled && !ctx.IsSet(DataPathFlag.Name) && !dryRun {
+		dataDir, err := os.MkdirTemp("", "znnd-dev-")
+		if err != nil {
+			return nil, err
+		}
+		cfg.DataPath = dataDir
+	}
 
 	// 3: Make dir paths absolute
 	if err := cfg.MakePathsAbsolute(); err != nil {
 		return nil, err
 	}
-
-	// 4: Config log to file
-	common.InitLogging(cfg.DataPath, cfg.LogLevel)
-
-	// 5: Log config
-	if j, err := json.MarshalIndent(cfg, "", "    "); err == nil {
-		fmt.Printf("Using the following znnd config: %v\n", string(j))
+	if cfg.Dev.Enabled && !dryRun {
+		if err := cfg.SetupDev(); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
-	log.Info("using znnd config", "config", cfg)
-
 	return &cfg, nil
 }
 
@@ -66,10 +95,63 @@ func applyFlagsToConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.Net.MaxPeers = ctx.Int(MaxPeersFlag.Name)
 	}
 
+	if ctx.IsSet(MaxInboundPeersFlag.Name) {
+		cfg.Net.MaxInboundPeers = ctx.Int(MaxInboundPeersFlag.Name)
+	}
+
+	if ctx.IsSet(MaxPeersPerSubnetFlag.Name) {
+		cfg.Net.MaxPeersPerSubnet = ctx.Int(MaxPeersPerSubnetFlag.Name)
+	}
+
+	if ctx.IsSet(MaxInboundPerIPFlag.Name) {
+		cfg.Net.MaxInboundPerIP = ctx.Int(MaxInboundPerIPFlag.Name)
+	}
+
 	if ctx.IsSet(MaxPendingPeersFlag.Name) {
 		cfg.Net.MaxPendingPeers = ctx.Int(MaxPendingPeersFlag.Name)
 	}
 
+	if ctx.IsSet(HandshakeTimeoutFlag.Name) {
+		cfg.Net.HandshakeTimeout = ctx.Int(HandshakeTimeoutFlag.Name)
+	}
+
+	if ctx.IsSet(MaxUploadRateFlag.Name) {
+		cfg.Net.MaxUploadRate = ctx.Int(MaxUploadRateFlag.Name)
+	}
+	if ctx.IsSet(MaxDownloadRateFlag.Name) {
+		cfg.Net.MaxDownloadRate = ctx.Int(MaxDownloadRateFlag.Name)
+	}
+	if ctx.IsSet(MaxPeerUploadRateFlag.Name) {
+		cfg.Net.MaxPeerUploadRate = ctx.Int(MaxPeerUploadRateFlag.Name)
+	}
+	if ctx.IsSet(MaxPeerDownloadRateFlag.Name) {
+		cfg.Net.MaxPeerDownloadRate = ctx.Int(MaxPeerDownloadRateFlag.Name)
+	}
+
+	if ctx.IsSet(PropagationDiversityFlag.Name) {
+		cfg.Net.PropagationDiversity = ctx.Int(PropagationDiversityFlag.Name)
+	}
+
+	if ctx.IsSet(CapabilitiesFlag.Name) {
+		cfg.Net.Capabilities = splitAndTrim(ctx.String(CapabilitiesFlag.Name))
+	}
+
+	if ctx.IsSet(ProxyFlag.Name) {
+		cfg.Net.Proxy = ctx.String(ProxyFlag.Name)
+	}
+	if ctx.IsSet(SentriesFlag.Name) {
+		cfg.Net.Sentries = splitAndTrim(ctx.String(SentriesFlag.Name))
+	}
+	if ctx.IsSet(PrivatePeersFlag.Name) {
+		cfg.Net.PrivatePeers = splitAndTrim(ctx.String(PrivatePeersFlag.Name))
+	}
+	if ctx.IsSet(AEADFramesFlag.Name) {
+		cfg.Net.AEADFrames = ctx.Bool(AEADFramesFlag.Name)
+	}
+	if ctx.IsSet(TelemetryFlag.Name) {
+		cfg.Net.Telemetry = ctx.Bool(TelemetryFlag.Name)
+	}
+
 	if listenHost := ctx.String(ListenHostFlag.Name); ctx.IsSet(ListenHostFlag.Name) && len(listenHost) > 0 {
 		cfg.RPC.HTTPHost = listenHost
 	}
@@ -91,6 +173,14 @@ func applyFlagsToConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.RPC.HTTPPort = ctx.Int(RPCPortFlag.Name)
 	}
 
+	if ctx.IsSet(RPCCorsFlag.Name) {
+		cfg.RPC.HTTPCors = splitAndTrim(ctx.String(RPCCorsFlag.Name))
+	}
+
+	if ctx.IsSet(RPCVirtualHostsFlag.Name) {
+		cfg.RPC.HTTPVirtualHosts = splitAndTrim(ctx.String(RPCVirtualHostsFlag.Name))
+	}
+
 	// WS Config
 	if ctx.IsSet(WSEnabledFlag.Name) {
 		cfg.RPC.EnableWS = ctx.Bool(WSEnabledFlag.Name)
@@ -104,22 +194,267 @@ func applyFlagsToConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.RPC.WSPort = ctx.Int(WSPortFlag.Name)
 	}
 
+	if ctx.IsSet(WSOriginsFlag.Name) {
+		cfg.RPC.WSOrigins = splitAndTrim(ctx.String(WSOriginsFlag.Name))
+	}
+
+	// IPC Config
+	if ctx.IsSet(IPCEnabledFlag.Name) {
+		cfg.RPC.EnableIPC = ctx.Bool(IPCEnabledFlag.Name)
+	}
+
+	if ctx.IsSet(IPCPathFlag.Name) {
+		cfg.RPC.IPCPath = ctx.String(IPCPathFlag.Name)
+	}
+
+	// RPC namespaces
+	if ctx.IsSet(RPCEndpointsFlag.Name) {
+		cfg.RPC.Endpoints = splitAndTrim(ctx.String(RPCEndpointsFlag.Name))
+	}
+
+	if ctx.IsSet(RPCDisabledEndpointsFlag.Name) {
+		cfg.RPC.DisabledEndpoints = splitAndTrim(ctx.String(RPCDisabledEndpointsFlag.Name))
+	}
+
+	if ctx.IsSet(RPCWalletFlag.Name) {
+		cfg.RPC.EnableWallet = ctx.Bool(RPCWalletFlag.Name)
+	}
+
+	if ctx.IsSet(RPCTLSCertFlag.Name) {
+		cfg.RPC.TLSCertFile = ctx.String(RPCTLSCertFlag.Name)
+	}
+
+	if ctx.IsSet(RPCTLSKeyFlag.Name) {
+		cfg.RPC.TLSKeyFile = ctx.String(RPCTLSKeyFlag.Name)
+	}
+
+	if ctx.IsSet(RPCAuthTokensFlag.Name) {
+		cfg.RPC.AuthTokens = splitAndTrim(ctx.String(RPCAuthTokensFlag.Name))
+	}
+
+	if ctx.IsSet(RPCJWTSecretFlag.Name) {
+		cfg.RPC.JWTSecretFile = ctx.String(RPCJWTSecretFlag.Name)
+	}
+
+	if ctx.IsSet(HealthMinPeersFlag.Name) {
+		cfg.RPC.HealthMinPeers = ctx.Int(HealthMinPeersFlag.Name)
+	}
+
+	if ctx.IsSet(HealthMaxMomentumAgeFlag.Name) {
+		cfg.RPC.HealthMaxMomentumAge = ctx.Int(HealthMaxMomentumAgeFlag.Name)
+	}
+
+	if ctx.IsSet(RPCSlowQueryThresholdFlag.Name) {
+		cfg.RPC.SlowQueryThreshold = ctx.Int(RPCSlowQueryThresholdFlag.Name)
+	}
+
+	// PoW Config
+	if ctx.IsSet(PoWEnabledFlag.Name) {
+		cfg.PoW.Enabled = ctx.Bool(PoWEnabledFlag.Name)
+	}
+
+	if ctx.IsSet(PoWMaxJobsFlag.Name) {
+		cfg.PoW.MaxJobs = ctx.Int(PoWMaxJobsFlag.Name)
+	}
+
+	if ctx.IsSet(PoWMaxQueuedJobsFlag.Name) {
+		cfg.PoW.MaxQueuedJobs = ctx.Int(PoWMaxQueuedJobsFlag.Name)
+	}
+
+	// Debug Config
+	if ctx.IsSet(PprofFlag.Name) {
+		cfg.Debug.EnablePprof = ctx.Bool(PprofFlag.Name)
+	}
+
+	if pprofHost := ctx.String(PprofAddrFlag.Name); ctx.IsSet(PprofAddrFlag.Name) && len(pprofHost) > 0 {
+		cfg.Debug.PprofHost = pprofHost
+	}
+
+	if ctx.IsSet(PprofPortFlag.Name) {
+		cfg.Debug.PprofPort = ctx.Int(PprofPortFlag.Name)
+	}
+
+	if ctx.IsSet(ProfilesPathFlag.Name) {
+		cfg.Debug.ProfilesPath = ctx.String(ProfilesPathFlag.Name)
+	}
+
+	if ctx.IsSet(TracerFlag.Name) {
+		cfg.Debug.EnableTracer = ctx.Bool(TracerFlag.Name)
+	}
+
+	if ctx.IsSet(TracingEndpointFlag.Name) {
+		cfg.Debug.TracingEndpoint = ctx.String(TracingEndpointFlag.Name)
+	}
+
+	if ctx.IsSet(ShutdownTimeoutFlag.Name) {
+		cfg.Debug.ShutdownTimeout = ctx.Int(ShutdownTimeoutFlag.Name)
+	}
+
+	// Indexer Config
+	if ctx.IsSet(IndexerFlag.Name) {
+		cfg.EnableIndexer = ctx.Bool(IndexerFlag.Name)
+	}
+	if ctx.IsSet(TokenMetadataFlag.Name) {
+		cfg.EnableTokenMetadata = ctx.Bool(TokenMetadataFlag.Name)
+	}
+	if ctx.IsSet(LightFlag.Name) {
+		cfg.Light = ctx.Bool(LightFlag.Name)
+	}
+
+	// Developer mode
+	if ctx.IsSet(DevFlag.Name) {
+		cfg.Dev.Enabled = ctx.Bool(DevFlag.Name)
+	}
+	if ctx.IsSet(DevPeriodFlag.Name) {
+		cfg.Dev.Period = ctx.Int64(DevPeriodFlag.Name)
+	}
+
+	// Database Config
+	if ctx.IsSet(AncientPathFlag.Name) {
+		cfg.Database.AncientPath = ctx.String(AncientPathFlag.Name)
+	}
+
+	if ctx.IsSet(AncientThresholdFlag.Name) {
+		cfg.Database.AncientThreshold = ctx.Uint64(AncientThresholdFlag.Name)
+	}
+
+	if ctx.IsSet(CacheSizeFlag.Name) {
+		cfg.Database.CacheSize = ctx.Int(CacheSizeFlag.Name)
+	}
+
+	if ctx.IsSet(CompactionWindowFlag.Name) {
+		cfg.Database.CompactionWindow = ctx.String(CompactionWindowFlag.Name)
+	}
+
+	if ctx.IsSet(BackupOnUpgradeFlag.Name) {
+		cfg.Database.BackupOnUpgrade = ctx.Bool(BackupOnUpgradeFlag.Name)
+	}
+
+	if ctx.IsSet(MaxBackupsFlag.Name) {
+		cfg.Database.MaxBackups = ctx.Int(MaxBackupsFlag.Name)
+	}
+
+	// Rewards Config
+	if ctx.IsSet(RewardsAutoCollectFlag.Name) {
+		cfg.Rewards.AutoCollect = ctx.Bool(RewardsAutoCollectFlag.Name)
+	}
+
+	if ctx.IsSet(RewardsZnnThresholdFlag.Name) {
+		cfg.Rewards.ZnnThreshold = ctx.Uint64(RewardsZnnThresholdFlag.Name)
+	}
+
+	if ctx.IsSet(RewardsQsrThresholdFlag.Name) {
+		cfg.Rewards.QsrThreshold = ctx.Uint64(RewardsQsrThresholdFlag.Name)
+	}
+
+	if ctx.IsSet(RewardsIntervalFlag.Name) {
+		cfg.Rewards.Interval = ctx.Int(RewardsIntervalFlag.Name)
+	}
+
+	// Replica Config
+	if ctx.IsSet(ReplicaOfFlag.Name) {
+		cfg.Replica.PrimaryDataPath = ctx.String(ReplicaOfFlag.Name)
+	}
+
+	if ctx.IsSet(ReplicaIntervalFlag.Name) {
+		cfg.Replica.RefreshInterval = ctx.Int(ReplicaIntervalFlag.Name)
+	}
+
+	// AutoReceive Config
+	if ctx.IsSet(AutoReceiveFlag.Name) {
+		cfg.AutoReceive.Enabled = ctx.Bool(AutoReceiveFlag.Name)
+	}
+
+	if ctx.IsSet(AutoReceiveAddressesFlag.Name) {
+		cfg.AutoReceive.Addresses = splitAndTrim(ctx.String(AutoReceiveAddressesFlag.Name))
+	}
+
+	if ctx.IsSet(AutoReceiveIntervalFlag.Name) {
+		cfg.AutoReceive.Interval = ctx.Int(AutoReceiveIntervalFlag.Name)
+	}
+
+	if ctx.IsSet(AutoReceiveMaxBlocksFlag.Name) {
+		cfg.AutoReceive.MaxBlocks = ctx.Int(AutoReceiveMaxBlocksFlag.Name)
+	}
+
+	if ctx.IsSet(AutoReceivePoWFlag.Name) {
+		cfg.AutoReceive.PoW = ctx.Bool(AutoReceivePoWFlag.Name)
+	}
+
+	// Clock Config
+	if ctx.IsSet(NTPServerFlag.Name) {
+		cfg.Clock.NTPServer = ctx.String(NTPServerFlag.Name)
+	}
+
+	if ctx.IsSet(ClockDriftWarningFlag.Name) {
+		cfg.Clock.DriftWarning = ctx.Int(ClockDriftWarningFlag.Name)
+	}
+
+	if ctx.IsSet(MaxProducerDriftFlag.Name) {
+		cfg.Clock.MaxProducerDrift = ctx.Int(MaxProducerDriftFlag.Name)
+	}
+
 	// Log Level Config
 	if logLevel := ctx.String(LogLvlFlag.Name); ctx.IsSet(LogLvlFlag.Name) && len(logLevel) > 0 {
 		cfg.LogLevel = logLevel
 	}
-}
-func readConfigFromFile(ctx *cli.Context, cfg *node.Config) error {
-	if file := ctx.String(ConfigFileFlag.Name); file != "" {
-		if jsonConf, err := os.ReadFile(file); err == nil {
-			err = json.Unmarshal(jsonConf, &cfg)
-			if err == nil {
-				return nil
+
+	if ctx.IsSet(LogModuleLevelsFlag.Name) {
+		cfg.Log.ModuleLevels = make(map[string]string)
+		for _, entry := range splitAndTrim(ctx.String(LogModuleLevelsFlag.Name)) {
+			if module, level, ok := strings.Cut(entry, "="); ok {
+				cfg.Log.ModuleLevels[strings.TrimSpace(module)] = strings.TrimSpace(level)
 			}
+		}
+	}
 
-			log.Error("Config malformed: cannot unmarshal the config file content", "error", err)
+	if ctx.IsSet(LogJSONFlag.Name) {
+		cfg.Log.JSON = ctx.Bool(LogJSONFlag.Name)
+	}
+
+	if ctx.IsSet(LogMaxSizeFlag.Name) {
+		cfg.Log.MaxSize = ctx.Int(LogMaxSizeFlag.Name)
+	}
+
+	if ctx.IsSet(LogMaxBackupsFlag.Name) {
+		cfg.Log.MaxBackups = ctx.Int(LogMaxBackupsFlag.Name)
+	}
+
+	if ctx.IsSet(LogMaxAgeFlag.Name) {
+		cfg.Log.MaxAge = ctx.Int(LogMaxAgeFlag.Name)
+	}
+
+	if ctx.IsSet(LogRotateHoursFlag.Name) {
+		cfg.Log.RotateHours = ctx.Int(LogRotateHoursFlag.Name)
+	}
+
+	if ctx.IsSet(LogCompressFlag.Name) {
+		cfg.Log.Compress = ctx.Bool(LogCompressFlag.Name)
+	}
+}
+
+// splitAndTrim splits input separated by a comma
+// and trims excessive white space from the substrings.
+func splitAndTrim(input string) (ret []string) {
+	l := strings.Split(input, ",")
+	for _, r := range l {
+		if r = strings.TrimSpace(r); r != "" {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+func readConfigFromFile(ctx *cli.Context, cfg *node.Config) error {
+	if file := ctx.String(ConfigFileFlag.Name); ctx.IsSet(ConfigFileFlag.Name) && file != "" {
+		jsonConf, err := os.ReadFile(file)
+		if err != nil {
 			return err
 		}
+		if err := node.DecodeConfig(jsonConf, cfg); err != nil {
+			log.Error("Config malformed: cannot decode the config file content", "error", err)
+			return fmt.Errorf("config file %v: %w", file, err)
+		}
+		return nil
 	}
 
 	// second read default settings
@@ -131,14 +466,21 @@ func readConfigFromFile(ctx *cli.Context, cfg *node.Config) error {
 	configPath := filepath.Join(dataPath, defaultNodeConfigFileName)
 
 	if jsonConf, err := os.ReadFile(configPath); err == nil {
-		err = json.Unmarshal(jsonConf, &cfg)
-		if err == nil {
-			return nil
+		if err := node.DecodeConfig(jsonConf, cfg); err != nil {
+			log.Error("Config malformed: please check", "error", err)
+			return fmt.Errorf("config file %v: %w", configPath, err)
 		}
-		log.Error("Config malformed: please check", "error", err)
-		return err
+		return nil
 	} else {
 		log.Warn("Config file missing: you can provide a data path using the --data flag or provide a config file using the --config flag", "configPath", configPath)
 	}
 	return nil
 }
+
+func printDevAccounts() {
+	fmt.Printf("Developer mode, the accounts are derived from the mnemonic %q\n", node.DevMnemonic)
+	for i, keyPair := range node.DevKeyPairs() {
+		fmt.Printf("  (%v) %v private key %x\n", i, keyPair.Address, keyPair.Private.Seed())
+	}
+	fmt.Printf("Account 0 produces the momentums and is the spork address\n")
+}
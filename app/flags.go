@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/zenon-network/go-zenon/common/ntp"
 	"github.com/zenon-network/go-zenon/node"
 	"github.com/zenon-network/go-zenon/p2p"
 
@@ -15,16 +16,34 @@ var (
 		Name:  "pprof",
 		Usage: "Enable the pprof HTTP server",
 	}
-	PprofPortFlag = &cli.Uint64Flag{
+	PprofPortFlag = &cli.IntFlag{
 		Name:  "pprof.port",
 		Usage: "pprof HTTP server listening port",
-		Value: 6060,
+		Value: node.DefaultPprofPort,
 	}
 
 	PprofAddrFlag = &cli.StringFlag{
 		Name:  "pprof.addr",
 		Usage: "pprof HTTP server listening interface",
-		Value: "127.0.0.1",
+		Value: node.DefaultPprofHost,
+	}
+	ProfilesPathFlag = &cli.StringFlag{
+		Name:  "profiles",
+		Usage: "Directory for the profiles captured over the debug RPC namespace and on SIGUSR1/SIGUSR2",
+		Value: "DataPath/" + node.DefaultProfilesPath,
+	}
+	TracerFlag = &cli.BoolFlag{
+		Name:  "tracer",
+		Usage: "Trace the embedded contract calls of the applied account-blocks, served by debug.traceAccountBlock",
+	}
+	TracingEndpointFlag = &cli.StringFlag{
+		Name:  "tracing-endpoint",
+		Usage: "OTLP/HTTP collector to export the spans of the RPC calls to, like http://localhost:4318",
+	}
+	ShutdownTimeoutFlag = &cli.IntFlag{
+		Name:  "shutdown-timeout",
+		Usage: "Seconds the node has to stop before the goroutine stacks are dumped to the profiles directory and it exits, 0 waits forever",
+		Value: node.DefaultShutdownTimeout,
 	}
 
 	// config
@@ -74,11 +93,75 @@ var (
 		Usage: "Maximum number of network peers (network disabled if set to 0)",
 		Value: p2p.DefaultMaxPeers,
 	}
+	MaxInboundPeersFlag = &cli.UintFlag{
+		Name:  "max-inbound-peers",
+		Usage: "Maximum number of inbound network peers (max-peers minus the dialed peers if set to 0)",
+	}
+	MaxPeersPerSubnetFlag = &cli.UintFlag{
+		Name:  "max-peers-per-subnet",
+		Usage: "Maximum number of network peers from the same /24 IPv4 or /64 IPv6 subnet (unlimited if set to 0)",
+		Value: p2p.DefaultMaxPeersPerSubnet,
+	}
+	MaxInboundPerIPFlag = &cli.UintFlag{
+		Name:  "max-inbound-per-ip",
+		Usage: "Maximum number of inbound network connections from the same IP (unlimited if set to 0)",
+		Value: p2p.DefaultMaxInboundPerIP,
+	}
 	MaxPendingPeersFlag = &cli.UintFlag{
 		Name:  "max-pending-peers",
 		Usage: "Maximum number of db connection attempts (defaults used if set to 0)",
 		Value: p2p.DefaultMaxPeers,
 	}
+	HandshakeTimeoutFlag = &cli.IntFlag{
+		Name:  "handshake-timeout",
+		Usage: "Seconds a new peer connection has to complete the encryption and protocol handshakes",
+		Value: p2p.DefaultHandshakeTimeout,
+	}
+	MaxUploadRateFlag = &cli.IntFlag{
+		Name:  "max-upload-rate",
+		Usage: "Maximum upload bandwidth of all the peers together in KiB/s (unlimited if set to 0)",
+	}
+	MaxDownloadRateFlag = &cli.IntFlag{
+		Name:  "max-download-rate",
+		Usage: "Maximum download bandwidth of all the peers together in KiB/s (unlimited if set to 0)",
+	}
+	MaxPeerUploadRateFlag = &cli.IntFlag{
+		Name:  "max-peer-upload-rate",
+		Usage: "Maximum upload bandwidth of each peer in KiB/s (unlimited if set to 0)",
+	}
+	MaxPeerDownloadRateFlag = &cli.IntFlag{
+		Name:  "max-peer-download-rate",
+		Usage: "Maximum download bandwidth of each peer in KiB/s (unlimited if set to 0)",
+	}
+	PropagationDiversityFlag = &cli.IntFlag{
+		Name:  "propagation-diversity",
+		Usage: "Percentage of the momentum propagation slots which go to peers from distinct networks instead of the lowest latency ones",
+		Value: p2p.DefaultPropagationDiversity,
+	}
+	CapabilitiesFlag = &cli.StringFlag{
+		Name:  "capabilities",
+		Usage: "Comma separated list of capabilities advertised to the network (archival, bridge, public-rpc)",
+	}
+	ProxyFlag = &cli.StringFlag{
+		Name:  "proxy",
+		Usage: "SOCKS5 proxy for the outbound peer connections (e.g. socks5://127.0.0.1:9050 for Tor), disables discovery and inbound connections",
+	}
+	SentriesFlag = &cli.StringFlag{
+		Name:  "sentries",
+		Usage: "Comma separated enode URLs of the sentry nodes, the node peers only with them",
+	}
+	PrivatePeersFlag = &cli.StringFlag{
+		Name:  "private-peers",
+		Usage: "Comma separated enode URLs of the nodes always kept connected and always sent the momentums, e.g. a pillar behind this sentry node",
+	}
+	AEADFramesFlag = &cli.BoolFlag{
+		Name:  "p2p-aead",
+		Usage: "Seal the peer connections with ChaCha20-Poly1305 when the peer supports it, other peers keep AES-CTR",
+	}
+	TelemetryFlag = &cli.BoolFlag{
+		Name:  "p2p-telemetry",
+		Usage: "Advertise the client version, OS, architecture and archival flag of the node to its peers",
+	}
 
 	// rpc
 
@@ -95,6 +178,14 @@ var (
 		Usage: "HTTP-RPC server listening port",
 		Value: p2p.DefaultHTTPPort,
 	}
+	RPCCorsFlag = &cli.StringFlag{
+		Name:  "http-cors",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+	}
+	RPCVirtualHostsFlag = &cli.StringFlag{
+		Name:  "http-vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+	}
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:  "ws",
 		Usage: "Enable the WS-RPC server",
@@ -108,6 +199,216 @@ var (
 		Usage: "WS-RPC server listening port",
 		Value: p2p.DefaultWSPort,
 	}
+	WSOriginsFlag = &cli.StringFlag{
+		Name:  "ws-origins",
+		Usage: "Comma separated list of origins from which to accept websockets requests",
+	}
+	IPCEnabledFlag = &cli.BoolFlag{
+		Name:  "ipc",
+		Usage: "Enable the IPC-RPC server",
+	}
+	IPCPathFlag = &cli.StringFlag{
+		Name:  "ipc-path",
+		Usage: "Filename for the IPC socket/pipe within the data directory (explicit paths escape it)",
+		Value: node.DefaultIPCPath,
+	}
+	RPCEndpointsFlag = &cli.StringFlag{
+		Name:  "rpc-endpoints",
+		Usage: "Comma separated list of API namespaces exposed over HTTP and WS (all public if empty)",
+	}
+	RPCWalletFlag = &cli.BoolFlag{
+		Name:  "rpc-wallet",
+		Usage: "Enable the wallet RPC namespace on the IPC endpoint",
+	}
+	RPCDisabledEndpointsFlag = &cli.StringFlag{
+		Name:  "rpc-disabled-endpoints",
+		Usage: "Comma separated list of API namespaces never exposed over HTTP and WS (e.g. embedded,stats)",
+	}
+	RPCTLSCertFlag = &cli.StringFlag{
+		Name:  "rpc-tls-cert",
+		Usage: "TLS certificate file of the HTTP and WS endpoints, relative to the data directory if not absolute",
+	}
+	RPCTLSKeyFlag = &cli.StringFlag{
+		Name:  "rpc-tls-key",
+		Usage: "TLS key file of the HTTP and WS endpoints, relative to the data directory if not absolute",
+	}
+	RPCAuthTokensFlag = &cli.StringFlag{
+		Name:  "rpc-auth-tokens",
+		Usage: "Comma separated list of bearer tokens accepted by the HTTP and WS endpoints",
+	}
+	RPCJWTSecretFlag = &cli.StringFlag{
+		Name:  "rpc-jwt-secret",
+		Usage: "File holding the hex encoded secret of the HS256 JWTs accepted by the HTTP and WS endpoints",
+	}
+
+	HealthMinPeersFlag = &cli.IntFlag{
+		Name:  "health-min-peers",
+		Usage: "Minimum number of peers below which the /health endpoint reports the node as unhealthy (disabled if 0)",
+		Value: node.DefaultHealthMinPeers,
+	}
+	HealthMaxMomentumAgeFlag = &cli.IntFlag{
+		Name:  "health-max-momentum-age",
+		Usage: "Seconds since the frontier momentum above which the /health endpoint reports the node as unhealthy (disabled if 0)",
+		Value: node.DefaultHealthMaxMomentumAge,
+	}
+	RPCSlowQueryThresholdFlag = &cli.IntFlag{
+		Name:  "rpc-slow-query-threshold",
+		Usage: "Milliseconds above which the RPC calls are logged as slow queries (disabled if 0)",
+		Value: node.DefaultRPCSlowQueryThreshold,
+	}
+
+	// pow
+
+	PoWEnabledFlag = &cli.BoolFlag{
+		Name:  "pow",
+		Usage: "Enable utilities.generatePoW which generates PoW for the clients",
+	}
+	PoWMaxJobsFlag = &cli.IntFlag{
+		Name:  "pow-max-jobs",
+		Usage: "Maximum number of concurrent PoW jobs, each of them uses one CPU core",
+		Value: node.DefaultPoWMaxJobs,
+	}
+	PoWMaxQueuedJobsFlag = &cli.IntFlag{
+		Name:  "pow-max-queued-jobs",
+		Usage: "Maximum number of PoW jobs waiting for a free worker, further jobs are rejected",
+		Value: node.DefaultPoWMaxQueuedJobs,
+	}
+
+	// light
+
+	LightFlag = &cli.BoolFlag{
+		Name:  "light",
+		Usage: "Sync and verify only the momentum headers, the account states are fetched from the peers on demand",
+	}
+
+	// developer mode
+
+	DevFlag = &cli.BoolFlag{
+		Name:  "dev",
+		Usage: "Run a single node developer network with pre-funded accounts, in a temporary data directory if --data isn't set",
+	}
+	DevPeriodFlag = &cli.Int64Flag{
+		Name:  "dev.period",
+		Usage: "Seconds between the momentums of the developer network, 0 produces them only when there are account-blocks to confirm",
+	}
+
+	// indexer
+
+	IndexerFlag = &cli.BoolFlag{
+		Name:  "indexer",
+		Usage: "Build secondary indexes of the account-blocks and enable the indexer RPC namespace",
+	}
+	TokenMetadataFlag = &cli.BoolFlag{
+		Name:  "token-metadata",
+		Usage: "Fetch the metadata published by the domains of the tokens and return the verified one with the tokens",
+	}
+
+	// database
+
+	AncientPathFlag = &cli.StringFlag{
+		Name:  "ancient",
+		Usage: "Directory for the momentums and account-blocks older than --ancient-threshold momentums, e.g. on slower storage",
+	}
+	AncientThresholdFlag = &cli.Uint64Flag{
+		Name:  "ancient-threshold",
+		Usage: "Number of momentums kept in the data directory, older ones are moved to --ancient",
+		Value: node.DefaultAncientThreshold,
+	}
+	CacheSizeFlag = &cli.IntFlag{
+		Name:  "cache-size",
+		Usage: "Number of recently read momentums, account-blocks and token infos each kept in memory (disabled if set to 0)",
+		Value: node.DefaultCacheSize,
+	}
+	CompactionWindowFlag = &cli.StringFlag{
+		Name:  "compaction-window",
+		Usage: "Daily time range in UTC, like 02:00-04:00, during which the databases are compacted once, see db compact",
+	}
+	BackupOnUpgradeFlag = &cli.BoolFlag{
+		Name:  "backup-on-upgrade",
+		Usage: "Back up the databases in the data directory when the node is started by a new version, see rollback-to-backup",
+	}
+	MaxBackupsFlag = &cli.IntFlag{
+		Name:  "max-backups",
+		Usage: "Number of upgrade backups kept, the oldest are removed (all are kept if 0)",
+		Value: node.DefaultMaxBackups,
+	}
+
+	// rewards
+
+	RewardsAutoCollectFlag = &cli.BoolFlag{
+		Name:  "rewards-autocollect",
+		Usage: "Collect the rewards of the producer address once they reach --rewards-znn-threshold or --rewards-qsr-threshold",
+	}
+	RewardsZnnThresholdFlag = &cli.Uint64Flag{
+		Name:  "rewards-znn-threshold",
+		Usage: "Uncollected ZNN, in base units, above which the rewards are collected (ignored if 0)",
+		Value: node.DefaultRewardsZnnThreshold,
+	}
+	RewardsQsrThresholdFlag = &cli.Uint64Flag{
+		Name:  "rewards-qsr-threshold",
+		Usage: "Uncollected QSR, in base units, above which the rewards are collected (ignored if 0)",
+		Value: node.DefaultRewardsQsrThreshold,
+	}
+	RewardsIntervalFlag = &cli.IntFlag{
+		Name:  "rewards-interval",
+		Usage: "Seconds between the checks of the uncollected rewards",
+		Value: node.DefaultRewardsInterval,
+	}
+
+	// replica
+
+	ReplicaOfFlag = &cli.StringFlag{
+		Name:  "replica-of",
+		Usage: "Data directory of another znnd on this host, the node serves the RPC from copies of its databases instead of syncing",
+	}
+	ReplicaIntervalFlag = &cli.IntFlag{
+		Name:  "replica-interval",
+		Usage: "Seconds between the refreshes of the copies of --replica-of",
+		Value: node.DefaultReplicaRefreshInterval,
+	}
+
+	// auto-receive
+
+	AutoReceiveFlag = &cli.BoolFlag{
+		Name:  "auto-receive",
+		Usage: "Receive the blocks sent to --auto-receive-addresses once they are unlocked over the wallet apis",
+	}
+	AutoReceiveAddressesFlag = &cli.StringFlag{
+		Name:  "auto-receive-addresses",
+		Usage: "Comma separated addresses to receive the blocks of",
+	}
+	AutoReceiveIntervalFlag = &cli.IntFlag{
+		Name:  "auto-receive-interval",
+		Usage: "Seconds between the checks of the unreceived blocks",
+		Value: node.DefaultAutoReceiveInterval,
+	}
+	AutoReceiveMaxBlocksFlag = &cli.IntFlag{
+		Name:  "auto-receive-max-blocks",
+		Usage: "Maximum number of receive blocks published at each check",
+		Value: node.DefaultAutoReceiveMaxBlocks,
+	}
+	AutoReceivePoWFlag = &cli.BoolFlag{
+		Name:  "auto-receive-pow",
+		Usage: "Compute PoW for the addresses without enough fused plasma",
+	}
+
+	// clock
+
+	NTPServerFlag = &cli.StringFlag{
+		Name:  "ntp-server",
+		Usage: "SNTP server queried for the drift of the local clock, the check is disabled if empty",
+		Value: ntp.DefaultServer,
+	}
+	ClockDriftWarningFlag = &cli.IntFlag{
+		Name:  "clock-drift-warning",
+		Usage: "Milliseconds of drift of the local clock past which warnings are logged (disabled if 0)",
+		Value: node.DefaultClockDriftWarning,
+	}
+	MaxProducerDriftFlag = &cli.IntFlag{
+		Name:  "max-producer-drift",
+		Usage: "Milliseconds of NTP drift of the local clock past which the pillar stops producing momentums (disabled if 0)",
+		Value: node.DefaultMaxProducerDrift,
+	}
 
 	// log
 
@@ -115,6 +416,37 @@ var (
 		Name:  "loglevel",
 		Usage: "log level (info,error,warn,debug)",
 	}
+	LogModuleLevelsFlag = &cli.StringFlag{
+		Name:  "log-module-levels",
+		Usage: "Comma separated list of per module log levels overriding --loglevel (e.g. p2p=debug,vm=warn)",
+	}
+	LogJSONFlag = &cli.BoolFlag{
+		Name:  "log-json",
+		Usage: "Write the log records as JSON objects instead of logfmt",
+	}
+	LogMaxSizeFlag = &cli.IntFlag{
+		Name:  "log-max-size",
+		Usage: "Size in megabytes a log file reaches before it is rotated",
+		Value: node.DefaultLogMaxSize,
+	}
+	LogMaxBackupsFlag = &cli.IntFlag{
+		Name:  "log-max-backups",
+		Usage: "Maximum number of rotated log files which are kept",
+		Value: node.DefaultLogMaxBackups,
+	}
+	LogMaxAgeFlag = &cli.IntFlag{
+		Name:  "log-max-age",
+		Usage: "Maximum number of days rotated log files are kept",
+		Value: node.DefaultLogMaxAge,
+	}
+	LogRotateHoursFlag = &cli.IntFlag{
+		Name:  "log-rotate-hours",
+		Usage: "Rotate the log files every given number of hours regardless of their size (disabled if 0)",
+	}
+	LogCompressFlag = &cli.BoolFlag{
+		Name:  "log-compress",
+		Usage: "Compress the rotated log files using gzip",
+	}
 
 	AllFlags = []cli.Flag{
 
@@ -125,6 +457,10 @@ var (
 		PprofFlag,
 		PprofPortFlag,
 		PprofAddrFlag,
+		ProfilesPathFlag,
+		TracerFlag,
+		TracingEndpointFlag,
+		ShutdownTimeoutFlag,
 
 		// general
 		DataPathFlag,
@@ -136,19 +472,100 @@ var (
 		ListenHostFlag,
 		ListenPortFlag,
 		MaxPeersFlag,
+		MaxInboundPeersFlag,
+		MaxPeersPerSubnetFlag,
+		MaxInboundPerIPFlag,
 		MaxPendingPeersFlag,
+		HandshakeTimeoutFlag,
+		MaxUploadRateFlag,
+		MaxDownloadRateFlag,
+		MaxPeerUploadRateFlag,
+		MaxPeerDownloadRateFlag,
+		PropagationDiversityFlag,
+		CapabilitiesFlag,
+		ProxyFlag,
+		SentriesFlag,
+		PrivatePeersFlag,
+		AEADFramesFlag,
+		TelemetryFlag,
 
 		// http rpc
 		RPCEnabledFlag,
 		RPCListenAddrFlag,
 		RPCPortFlag,
+		RPCCorsFlag,
+		RPCVirtualHostsFlag,
 
 		// ws
 		WSEnabledFlag,
 		WSListenAddrFlag,
 		WSPortFlag,
+		WSOriginsFlag,
+		IPCEnabledFlag,
+		IPCPathFlag,
+
+		// rpc namespaces
+		RPCEndpointsFlag,
+		RPCDisabledEndpointsFlag,
+		RPCWalletFlag,
+		RPCTLSCertFlag,
+		RPCTLSKeyFlag,
+		RPCAuthTokensFlag,
+		RPCJWTSecretFlag,
+		HealthMinPeersFlag,
+		HealthMaxMomentumAgeFlag,
+		RPCSlowQueryThresholdFlag,
+
+		// pow
+		PoWEnabledFlag,
+		PoWMaxJobsFlag,
+		PoWMaxQueuedJobsFlag,
+
+		// indexer
+		IndexerFlag,
+		TokenMetadataFlag,
+		LightFlag,
+
+		// developer mode
+		DevFlag,
+		DevPeriodFlag,
+
+		// database
+		AncientPathFlag,
+		AncientThresholdFlag,
+		CacheSizeFlag,
+		CompactionWindowFlag,
+		BackupOnUpgradeFlag,
+		MaxBackupsFlag,
+
+		// rewards
+		RewardsAutoCollectFlag,
+		RewardsZnnThresholdFlag,
+		RewardsQsrThresholdFlag,
+		RewardsIntervalFlag,
+		ReplicaOfFlag,
+		ReplicaIntervalFlag,
+
+		// auto-receive
+		AutoReceiveFlag,
+		AutoReceiveAddressesFlag,
+		AutoReceiveIntervalFlag,
+		AutoReceiveMaxBlocksFlag,
+		AutoReceivePoWFlag,
+
+		// clock
+		NTPServerFlag,
+		ClockDriftWarningFlag,
+		MaxProducerDriftFlag,
 
 		// log
 		LogLvlFlag,
+		LogModuleLevelsFlag,
+		LogJSONFlag,
+		LogMaxSizeFlag,
+		LogMaxBackupsFlag,
+		LogMaxAgeFlag,
+		LogRotateHoursFlag,
+		LogCompressFlag,
 	}
 )
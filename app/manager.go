@@ -49,8 +49,9 @@ func (nodeManager *Manager) Start() error {
 	} else {
 		fmt.Println("znnd successfully started")
 		fmt.Println("*** Node status ***")
-		address := nodeManager.node.Zenon().Producer().GetCoinBase()
-		if address == nil {
+		if nodeManager.node.Zenon() == nil {
+			fmt.Println("* Light node, only the momentum headers are synced")
+		} else if address := nodeManager.node.Zenon().Producer().GetCoinBase(); address == nil {
 			fmt.Println("* No Pillar configured for current node")
 		} else {
 			fmt.Printf("* Producer address detected: %v\n", address)
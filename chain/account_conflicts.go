@@ -0,0 +1,118 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// maxAccountChainConflicts is the number of the most recent conflicts kept as evidence
+	maxAccountChainConflicts = 100
+)
+
+// AccountChainConflict is the evidence of two account-blocks seen at the same height of an account-chain.
+// Two signed user blocks are a double-spend attempt, two blocks of an embedded contract point to a corruption
+// of the local chain since they are generated by the node.
+type AccountChainConflict struct {
+	Address types.Address `json:"address"`
+	Height  uint64        `json:"height"`
+	// Existing is the block known by the node when Observed was received, Confirmed is set if a momentum
+	// confirmed it, otherwise it's an uncommitted block of the pool
+	Existing  *nom.AccountBlock `json:"existing"`
+	Observed  *nom.AccountBlock `json:"observed"`
+	Confirmed bool              `json:"confirmed"`
+	Timestamp int64             `json:"timestamp"`
+}
+
+// AccountChainConflictListener can be implemented by a MomentumEventListener to be notified about the
+// account-chain conflicts, see AccountChainConflicts.
+type AccountChainConflictListener interface {
+	AccountChainConflict(*AccountChainConflict)
+}
+
+type AccountChainConflicts interface {
+	// ReportAccountChainConflict records observed, a verified account-block at the height of existing with
+	// another hash, as a conflict. Pairs which were already reported are ignored.
+	ReportAccountChainConflict(existing, observed *nom.AccountBlock, confirmed bool)
+	// GetAccountChainConflicts returns the conflicts seen since the node started, the most recent first.
+	GetAccountChainConflicts() []*AccountChainConflict
+}
+
+type accountConflicts struct {
+	log    common.Logger
+	notify func(*AccountChainConflict)
+
+	lock      sync.Mutex
+	conflicts []*AccountChainConflict
+	// conflicts recorded while the account pool is locked, notified once it's unlocked
+	pending []*AccountChainConflict
+}
+
+func newAccountConflicts() *accountConflicts {
+	return &accountConflicts{
+		log:       common.ChainLogger.New("submodule", "account-conflicts"),
+		conflicts: make([]*AccountChainConflict, 0),
+	}
+}
+
+// record keeps the conflict and returns false if the pair was already recorded, in any order.
+func (ac *accountConflicts) record(existing, observed *nom.AccountBlock, confirmed bool) bool {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	for _, conflict := range ac.conflicts {
+		if (conflict.Existing.Hash == existing.Hash && conflict.Observed.Hash == observed.Hash) ||
+			(conflict.Existing.Hash == observed.Hash && conflict.Observed.Hash == existing.Hash) {
+			return false
+		}
+	}
+
+	conflict := &AccountChainConflict{
+		Address:   existing.Address,
+		Height:    existing.Height,
+		Existing:  existing.Copy(),
+		Observed:  observed.Copy(),
+		Confirmed: confirmed,
+		Timestamp: common.Clock.Now().Unix(),
+	}
+	ac.log.Warn("account-chain conflict detected", "address", conflict.Address, "height", conflict.Height,
+		"existing", existing.Hash, "observed", observed.Hash, "confirmed", confirmed)
+	if len(ac.conflicts) == maxAccountChainConflicts {
+		ac.conflicts = append(ac.conflicts[:0], ac.conflicts[1:]...)
+	}
+	ac.conflicts = append(ac.conflicts, conflict)
+	ac.pending = append(ac.pending, conflict)
+	return true
+}
+
+// notifyPending notifies the conflicts recorded since the last call, it must be called without holding the
+// lock of the account pool, the listeners may take it.
+func (ac *accountConflicts) notifyPending() {
+	ac.lock.Lock()
+	pending := ac.pending
+	ac.pending = nil
+	ac.lock.Unlock()
+
+	if ac.notify == nil {
+		return
+	}
+	for _, conflict := range pending {
+		ac.notify(conflict)
+	}
+}
+
+func (ac *accountConflicts) ReportAccountChainConflict(existing, observed *nom.AccountBlock, confirmed bool) {
+	ac.record(existing, observed, confirmed)
+	ac.notifyPending()
+}
+func (ac *accountConflicts) GetAccountChainConflicts() []*AccountChainConflict {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	result := make([]*AccountChainConflict, len(ac.conflicts))
+	for i, conflict := range ac.conflicts {
+		result[len(result)-1-i] = conflict
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+func TestAccountConflicts_record(t *testing.T) {
+	ac := newAccountConflicts()
+	notified := make([]*AccountChainConflict, 0)
+	ac.notify = func(conflict *AccountChainConflict) {
+		notified = append(notified, conflict)
+	}
+
+	a := &nom.AccountBlock{Address: types.PillarContract, Height: 5, Hash: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000001")}
+	b := &nom.AccountBlock{Address: types.PillarContract, Height: 5, Hash: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000002")}
+	c := &nom.AccountBlock{Address: types.PillarContract, Height: 5, Hash: types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000003")}
+
+	common.ExpectTrue(t, ac.record(a, b, false))
+	// the pair is the same once the pool replaces a with b
+	common.ExpectTrue(t, !ac.record(b, a, false))
+	common.Expect(t, len(notified), 0)
+	ac.notifyPending()
+	common.Expect(t, len(notified), 1)
+
+	ac.ReportAccountChainConflict(a, c, true)
+	common.Expect(t, len(notified), 2)
+	conflicts := ac.GetAccountChainConflicts()
+	common.Expect(t, len(conflicts), 2)
+	common.Expect(t, conflicts[0].Observed.Hash, c.Hash)
+	common.ExpectTrue(t, conflicts[0].Confirmed)
+	common.Expect(t, conflicts[1].Existing.Hash, a.Hash)
+	common.ExpectTrue(t, !conflicts[1].Confirmed)
+
+	for i := 0; i < maxAccountChainConflicts; i += 1 {
+		observed := &nom.AccountBlock{Address: types.PillarContract, Height: 5}
+		observed.Hash[0] = byte(i)
+		observed.Hash[1] = 1
+		ac.record(a, observed, false)
+	}
+	conflicts = ac.GetAccountChainConflicts()
+	common.Expect(t, len(conflicts), maxAccountChainConflicts)
+	// the first two conflicts are dropped
+	common.Expect(t, conflicts[len(conflicts)-1].Observed.Hash[0], byte(0))
+}
@@ -23,6 +23,8 @@ var (
 
 	// MaxAccountBlocksInMomentum takes into account batched account-blocks
 	MaxAccountBlocksInMomentum = 100
+	// MaxAccountBlocksInMomentumAfterPayloadSpork replaces MaxAccountBlocksInMomentum once the payload limits spork is enforced
+	MaxAccountBlocksInMomentumAfterPayloadSpork = 200
 )
 
 type Stable interface {
@@ -34,6 +36,8 @@ type accountPool struct {
 	stable   Stable
 	managers map[types.Address]db.Manager
 	changes  sync.Mutex
+
+	*accountConflicts
 }
 
 func (ap *accountPool) getAccountManager(address types.Address) db.Manager {
@@ -95,6 +99,7 @@ func (ap *accountPool) AddAccountBlockTransaction(insertLocker sync.Locker, tran
 	if insertLocker == nil {
 		return errors.Errorf("insertLocker can't be nil")
 	}
+	defer ap.notifyPending()
 	ap.changes.Lock()
 	defer ap.changes.Unlock()
 	return ap.addAccountBlockTransaction(transaction, false)
@@ -103,6 +108,7 @@ func (ap *accountPool) ForceAddAccountBlockTransaction(insertLocker sync.Locker,
 	if insertLocker == nil {
 		return errors.Errorf("insertLocker can't be nil")
 	}
+	defer ap.notifyPending()
 	ap.changes.Lock()
 	defer ap.changes.Unlock()
 	return ap.addAccountBlockTransaction(transaction, true)
@@ -134,6 +140,9 @@ func (ap *accountPool) addAccountBlockTransaction(transaction *nom.AccountBlockT
 		log.Info("account-block is already inserted")
 		return nil
 	}
+	if trueBlock != nil {
+		ap.record(trueBlock, block, ap.getStableAccountStore(address).Identifier().Height >= identifier.Height)
+	}
 
 	if err := ap.canRollback(block); err != nil {
 		return err
@@ -268,16 +277,13 @@ func (ap *accountPool) rebuild(detailed *nom.DetailedMomentum) error {
 	return nil
 }
 
-func (ap *accountPool) GetNewMomentumContent() []*nom.AccountBlock {
-	return ap.filterBlocksToCommit(ap.GetAllUncommittedAccountBlocks())
-}
-func (ap *accountPool) filterBlocksToCommit(blocks []*nom.AccountBlock) []*nom.AccountBlock {
+func (ap *accountPool) filterBlocksToCommit(blocks []*nom.AccountBlock, maxBlocks int) []*nom.AccountBlock {
 	toCommit := make([]*nom.AccountBlock, 0, len(blocks))
-	batch := make([]*nom.AccountBlock, 0, MaxAccountBlocksInMomentum)
+	batch := make([]*nom.AccountBlock, 0, maxBlocks)
 	for index := range blocks {
 		batch = append(batch, blocks[index])
 		if blocks[index].BlockType != nom.BlockTypeContractSend {
-			if len(toCommit)+len(batch) > MaxAccountBlocksInMomentum {
+			if len(toCommit)+len(batch) > maxBlocks {
 				break
 			}
 			toCommit = append(toCommit, batch...)
@@ -323,6 +329,8 @@ func newAccountPool(stable Stable) *accountPool {
 		log:      common.ChainLogger.New("module", "account-pool"),
 		stable:   stable,
 		managers: make(map[types.Address]db.Manager),
+
+		accountConflicts: newAccountConflicts(),
 	}
 }
 func NewAccountPool(stable Stable) AccountPool {
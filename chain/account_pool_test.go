@@ -9,16 +9,15 @@ import (
 
 func TestAccountPool_filterBlocksToCommit(t *testing.T) {
 	ap := accountPool{}
-	MaxAccountBlocksInMomentum = 2
 	common.Expect(t, len(ap.filterBlocksToCommit([]*nom.AccountBlock{
 		{Height: 1, BlockType: nom.BlockTypeUserSend},
 		{Height: 2, BlockType: nom.BlockTypeUserSend},
 		{Height: 3, BlockType: nom.BlockTypeUserSend},
-	})), 2)
+	}, 2)), 2)
 
 	common.Expect(t, len(ap.filterBlocksToCommit([]*nom.AccountBlock{
 		{Height: 1, BlockType: nom.BlockTypeContractSend},
 		{Height: 2, BlockType: nom.BlockTypeContractSend},
 		{Height: 3, BlockType: nom.BlockTypeUserReceive},
-	})), 0)
+	}, 2)), 0)
 }
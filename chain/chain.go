@@ -7,6 +7,8 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/db"
@@ -24,19 +26,34 @@ type chain struct {
 	*accountPool
 	*momentumPool
 	*momentumEventManager
+	receipts *receiptStore
 
 	chainManager db.Manager
 	insert       sync.Mutex
 }
 
-func NewChain(chainManager db.Manager, genesis store.Genesis) *chain {
-	momentumPool := NewMomentumPool(chainManager, genesis)
+// NewChain creates a chain which stores the momentums in chainManager and the logs emitted by embedded contracts in receiptsDB.
+func NewChain(chainManager db.Manager, receiptsDB db.DB, genesis store.Genesis) *chain {
+	return NewChainWithCache(chainManager, receiptsDB, genesis, 0)
+}
+
+// NewChainWithCache is like NewChain, the momentum stores keep up to cacheSize recently read momentums,
+// account-blocks and token infos each in memory, see momentum.Cache. Zero disables the cache.
+func NewChainWithCache(chainManager db.Manager, receiptsDB db.DB, genesis store.Genesis, cacheSize int) *chain {
+	var cache *momentum.Cache
+	if cacheSize > 0 {
+		cache = momentum.NewCache(cacheSize)
+	}
+	momentumPool := NewMomentumPool(chainManager, genesis, cache)
+	accountPool := newAccountPool(momentumPool)
+	accountPool.notify = momentumPool.momentumEventManager.broadcastAccountChainConflict
 	return &chain{
 		log:                  common.ChainLogger,
 		Genesis:              genesis,
-		accountPool:          newAccountPool(momentumPool),
+		accountPool:          accountPool,
 		momentumPool:         momentumPool,
 		momentumEventManager: momentumPool.momentumEventManager,
+		receipts:             newReceiptStore(receiptsDB),
 		chainManager:         chainManager,
 	}
 }
@@ -52,6 +69,8 @@ func (c *chain) Init() error {
 		return err
 	}
 	types.SporkAddress = c.genesis.GetSporkAddress()
+	// logs are saved before other listeners are notified about the momentum
+	c.Register(c.receipts)
 	c.Register(c.accountPool)
 
 	frontierStore := c.GetFrontierMomentumStore()
@@ -93,10 +112,32 @@ func (c *chain) Stop() error {
 	defer c.log.Info("stopped")
 
 	c.UnRegister(c.accountPool)
+	c.UnRegister(c.receipts)
 
 	return c.chainManager.Stop()
 }
 
+func (c *chain) AddAccountBlockTransaction(insertLocker sync.Locker, transaction *nom.AccountBlockTransaction) error {
+	if err := c.accountPool.AddAccountBlockTransaction(insertLocker, transaction); err != nil {
+		return err
+	}
+	c.receipts.addPending(transaction)
+	return nil
+}
+func (c *chain) ForceAddAccountBlockTransaction(insertLocker sync.Locker, transaction *nom.AccountBlockTransaction) error {
+	if err := c.accountPool.ForceAddAccountBlockTransaction(insertLocker, transaction); err != nil {
+		return err
+	}
+	c.receipts.addPending(transaction)
+	return nil
+}
+func (c *chain) GetAccountBlockLogs(hash types.Hash) ([]*nom.Log, error) {
+	return c.receipts.GetAccountBlockLogs(hash)
+}
+func (c *chain) GetAccountBlockFailure(hash types.Hash) (*nom.ReceiveFailure, error) {
+	return c.receipts.GetAccountBlockFailure(hash)
+}
+
 func (c *chain) checkGenesisCompatibility() error {
 	frontierStore := c.GetFrontierMomentumStore()
 	if frontierStore.Identifier().IsZero() {
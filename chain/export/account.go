@@ -0,0 +1,265 @@
+// Package export writes the ledger in formats which can be processed without a node, for example by auditors.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	// account-blocks are read from the store in batches of batchSize
+	batchSize = 100
+)
+
+// AccountBlock is an exported account-block. The transfer of a receive block is the one of its send
+// block, Counterparty is the sender of receive blocks and the receiver of send blocks.
+type AccountBlock struct {
+	Height            uint64        `json:"height"`
+	Hash              types.Hash    `json:"hash"`
+	BlockType         string        `json:"blockType"`
+	Address           types.Address `json:"address"`
+	Counterparty      types.Address `json:"counterparty"`
+	FromBlockHash     types.Hash    `json:"fromBlockHash"`
+	TokenStandard     string        `json:"tokenStandard"`
+	TokenSymbol       string        `json:"tokenSymbol"`
+	Amount            string        `json:"amount"`
+	MomentumHeight    uint64        `json:"momentumHeight"`
+	MomentumTimestamp string        `json:"momentumTimestamp"`
+}
+
+var csvHeader = []string{"height", "hash", "blockType", "address", "counterparty", "fromBlockHash", "tokenStandard", "tokenSymbol", "amount", "momentumHeight", "momentumTimestamp"}
+
+func (b *AccountBlock) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(b.Height, 10),
+		b.Hash.String(),
+		b.BlockType,
+		b.Address.String(),
+		b.Counterparty.String(),
+		b.FromBlockHash.String(),
+		b.TokenStandard,
+		b.TokenSymbol,
+		b.Amount,
+		strconv.FormatUint(b.MomentumHeight, 10),
+		b.MomentumTimestamp,
+	}
+}
+
+var blockTypeNames = map[uint64]string{
+	nom.BlockTypeGenesisReceive:  "genesisReceive",
+	nom.BlockTypeUserSend:        "userSend",
+	nom.BlockTypeUserReceive:     "userReceive",
+	nom.BlockTypeContractSend:    "contractSend",
+	nom.BlockTypeContractReceive: "contractReceive",
+}
+
+// AccountChain streams the confirmed account-chain of address to w, in ascending height order.
+// Amounts are in display units, momentum timestamps in RFC 3339 format and UTC.
+func AccountChain(c chain.Chain, address types.Address, format string, w io.Writer) error {
+	var writer recordWriter
+	switch format {
+	case FormatCSV:
+		writer = newCSVWriter(w)
+	case FormatJSON:
+		writer = &jsonWriter{w: w}
+	default:
+		return fmt.Errorf("unknown export format %q, expected %v or %v", format, FormatCSV, FormatJSON)
+	}
+
+	momentumStore := c.GetFrontierMomentumStore()
+	accountStore := c.GetFrontierAccountStore(address)
+	frontier, err := accountStore.Frontier()
+	if err != nil {
+		return err
+	}
+	e := &exporter{
+		store:  momentumStore,
+		tokens: make(map[types.ZenonTokenStandard]*definition.TokenInfo),
+	}
+
+	if err := writer.begin(); err != nil {
+		return err
+	}
+	for height := uint64(1); frontier != nil && height <= frontier.Height; height += batchSize {
+		count := uint64(batchSize)
+		if height+count > frontier.Height+1 {
+			count = frontier.Height + 1 - height
+		}
+		blocks, err := accountStore.MoreByHeight(height, count)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			exported, err := e.accountBlock(block)
+			if err != nil {
+				return err
+			}
+			if err := writer.write(exported); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.end()
+}
+
+type exporter struct {
+	store  store.Momentum
+	tokens map[types.ZenonTokenStandard]*definition.TokenInfo
+}
+
+func (e *exporter) accountBlock(block *nom.AccountBlock) (*AccountBlock, error) {
+	exported := &AccountBlock{
+		Height:        block.Height,
+		Hash:          block.Hash,
+		BlockType:     blockTypeNames[block.BlockType],
+		Address:       block.Address,
+		FromBlockHash: block.FromBlockHash,
+	}
+
+	transfer := block
+	if block.IsReceiveBlock() && block.BlockType != nom.BlockTypeGenesisReceive {
+		send, err := e.store.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if send == nil {
+			return nil, fmt.Errorf("send block %v of %v not found", block.FromBlockHash, block.Hash)
+		}
+		transfer = send
+		exported.Counterparty = send.Address
+	} else if block.IsSendBlock() {
+		exported.Counterparty = block.ToAddress
+	}
+
+	if transfer.TokenStandard != types.ZeroTokenStandard {
+		token, err := e.token(transfer.TokenStandard)
+		if err != nil {
+			return nil, err
+		}
+		exported.TokenStandard = transfer.TokenStandard.String()
+		if token != nil {
+			exported.TokenSymbol = token.TokenSymbol
+			exported.Amount = formatAmount(transfer.Amount, token.Decimals)
+		} else {
+			exported.Amount = formatAmount(transfer.Amount, 0)
+		}
+	} else {
+		exported.Amount = "0"
+	}
+
+	height, err := e.store.GetBlockConfirmationHeight(block.Hash)
+	if err != nil {
+		return nil, err
+	}
+	momentum, err := e.store.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if momentum != nil {
+		exported.MomentumHeight = momentum.Height
+		exported.MomentumTimestamp = time.Unix(int64(momentum.TimestampUnix), 0).UTC().Format(time.RFC3339)
+	}
+	return exported, nil
+}
+
+func (e *exporter) token(zts types.ZenonTokenStandard) (*definition.TokenInfo, error) {
+	if token, ok := e.tokens[zts]; ok {
+		return token, nil
+	}
+	token, err := e.store.GetTokenInfoByTs(zts)
+	if err == constants.ErrDataNonExistent {
+		token = nil
+	} else if err != nil {
+		return nil, err
+	}
+	e.tokens[zts] = token
+	return token, nil
+}
+
+// formatAmount formats amount with decimals digits after the decimal point, trailing zeros are removed.
+func formatAmount(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	integer, fraction := digits[:len(digits)-int(decimals)], strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	result := integer
+	if fraction != "" {
+		result += "." + fraction
+	}
+	if amount.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+type recordWriter interface {
+	begin() error
+	write(block *AccountBlock) error
+	end() error
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+func (w *csvWriter) begin() error {
+	return w.w.Write(csvHeader)
+}
+func (w *csvWriter) write(block *AccountBlock) error {
+	return w.w.Write(block.csvRecord())
+}
+func (w *csvWriter) end() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonWriter writes a JSON array with one account-block per line, without holding the chain in memory.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (w *jsonWriter) begin() error {
+	_, err := io.WriteString(w.w, "[")
+	return err
+}
+func (w *jsonWriter) write(block *AccountBlock) error {
+	data, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+	separator := "\n"
+	if w.count > 0 {
+		separator = ",\n"
+	}
+	w.count += 1
+	_, err = io.WriteString(w.w, separator+string(data))
+	return err
+}
+func (w *jsonWriter) end() error {
+	_, err := io.WriteString(w.w, "\n]\n")
+	return err
+}
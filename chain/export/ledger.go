@@ -0,0 +1,219 @@
+package export
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
+)
+
+const (
+	FormatParquet  = "parquet"
+	FormatProtobuf = "protobuf"
+)
+
+// Momentum is an exported momentum, Timestamp is a unix timestamp and AccountBlocks the number of
+// account-blocks it confirms, including the descendant blocks.
+type Momentum struct {
+	Height        uint64
+	Hash          types.Hash
+	PreviousHash  types.Hash
+	Timestamp     uint64
+	Producer      types.Address
+	AccountBlocks uint64
+}
+
+var momentumsTable = &table{
+	name: "momentums",
+	columns: []column{
+		{"height", int64Column},
+		{"hash", stringColumn},
+		{"previousHash", stringColumn},
+		{"timestamp", int64Column},
+		{"producer", stringColumn},
+		{"accountBlocks", int64Column},
+	},
+}
+
+func (m *Momentum) row() []interface{} {
+	return []interface{}{m.Height, m.Hash.String(), m.PreviousHash.String(), m.Timestamp, m.Producer.String(), m.AccountBlocks}
+}
+
+// LedgerBlock is an exported account-block with the momentum which confirmed it. ParentHash is the
+// contract receive block which generated a descendant block, Amount is in base units and Data is hex
+// encoded. The calls of the embedded contracts are decoded into Method and Arguments, a JSON list of
+// embeddedabi.Argument, both are empty if the data can't be decoded.
+type LedgerBlock struct {
+	MomentumHeight    uint64
+	MomentumTimestamp uint64
+	Hash              types.Hash
+	Height            uint64
+	BlockType         string
+	Address           types.Address
+	ToAddress         types.Address
+	FromBlockHash     types.Hash
+	ParentHash        string
+	TokenStandard     string
+	Amount            string
+	Data              string
+	Method            string
+	Arguments         string
+	FusedPlasma       uint64
+	BasePlasma        uint64
+	TotalPlasma       uint64
+	Difficulty        uint64
+}
+
+var accountBlocksTable = &table{
+	name: "accountBlocks",
+	columns: []column{
+		{"momentumHeight", int64Column},
+		{"momentumTimestamp", int64Column},
+		{"hash", stringColumn},
+		{"height", int64Column},
+		{"blockType", stringColumn},
+		{"address", stringColumn},
+		{"toAddress", stringColumn},
+		{"fromBlockHash", stringColumn},
+		{"parentHash", stringColumn},
+		{"tokenStandard", stringColumn},
+		{"amount", stringColumn},
+		{"data", stringColumn},
+		{"method", stringColumn},
+		{"arguments", stringColumn},
+		{"fusedPlasma", int64Column},
+		{"basePlasma", int64Column},
+		{"totalPlasma", int64Column},
+		{"difficulty", int64Column},
+	},
+}
+
+func (b *LedgerBlock) row() []interface{} {
+	return []interface{}{
+		b.MomentumHeight, b.MomentumTimestamp, b.Hash.String(), b.Height, b.BlockType, b.Address.String(),
+		b.ToAddress.String(), b.FromBlockHash.String(), b.ParentHash, b.TokenStandard, b.Amount, b.Data,
+		b.Method, b.Arguments, b.FusedPlasma, b.BasePlasma, b.TotalPlasma, b.Difficulty,
+	}
+}
+
+// Ledger writes the momentums from..to, both included, of the frontier of c to momentums and the
+// account-blocks they confirm to accountBlocks, one row per block in the order of the momentums, for the
+// analytics tools. A to of 0 is the frontier momentum. progress, if not nil, is called after each momentum.
+func Ledger(c chain.Chain, from, to uint64, format string, momentums, accountBlocks io.Writer, progress func(height uint64)) error {
+	newWriter := newTableWriter(format)
+	if newWriter == nil {
+		return fmt.Errorf("unknown export format %q, expected %v or %v", format, FormatParquet, FormatProtobuf)
+	}
+
+	momentumStore := c.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return err
+	}
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 || to > frontier.Height {
+		to = frontier.Height
+	}
+	if from > to {
+		return errors.Errorf("invalid range %v to %v, the frontier momentum is at height %v", from, to, frontier.Height)
+	}
+
+	momentumsWriter := newWriter(momentumsTable, momentums)
+	blocksWriter := newWriter(accountBlocksTable, accountBlocks)
+	for height := from; height <= to; height += 1 {
+		momentum, err := momentumStore.GetMomentumByHeight(height)
+		if err != nil {
+			return err
+		}
+		if momentum == nil {
+			return errors.Errorf("momentum at height %v is missing", height)
+		}
+
+		blocks := make([]*LedgerBlock, 0, len(momentum.Content))
+		for _, header := range momentum.Content {
+			block, err := momentumStore.GetAccountBlock(*header)
+			if err != nil {
+				return err
+			}
+			if block == nil {
+				return errors.Errorf("account-block %v of momentum %v is missing", header.Hash, momentum.Height)
+			}
+			blocks = appendLedgerBlocks(blocks, momentum, block, "")
+		}
+
+		exported := &Momentum{
+			Height:        momentum.Height,
+			Hash:          momentum.Hash,
+			PreviousHash:  momentum.PreviousHash,
+			Timestamp:     momentum.TimestampUnix,
+			Producer:      momentum.Producer(),
+			AccountBlocks: uint64(len(blocks)),
+		}
+		if err := momentumsWriter.write(exported.row()); err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			if err := blocksWriter.write(block.row()); err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			progress(height)
+		}
+	}
+
+	if err := momentumsWriter.end(); err != nil {
+		return err
+	}
+	return blocksWriter.end()
+}
+
+// appendLedgerBlocks appends block and its descendant blocks to blocks.
+func appendLedgerBlocks(blocks []*LedgerBlock, momentum *nom.Momentum, block *nom.AccountBlock, parentHash string) []*LedgerBlock {
+	exported := &LedgerBlock{
+		MomentumHeight:    momentum.Height,
+		MomentumTimestamp: momentum.TimestampUnix,
+		Hash:              block.Hash,
+		Height:            block.Height,
+		BlockType:         blockTypeNames[block.BlockType],
+		Address:           block.Address,
+		ToAddress:         block.ToAddress,
+		FromBlockHash:     block.FromBlockHash,
+		ParentHash:        parentHash,
+		Amount:            "0",
+		Data:              hex.EncodeToString(block.Data),
+		FusedPlasma:       block.FusedPlasma,
+		BasePlasma:        block.BasePlasma,
+		TotalPlasma:       block.TotalPlasma,
+		Difficulty:        block.Difficulty,
+	}
+	if block.TokenStandard != types.ZeroTokenStandard {
+		exported.TokenStandard = block.TokenStandard.String()
+	}
+	if block.Amount != nil {
+		exported.Amount = block.Amount.String()
+	}
+	if block.IsSendBlock() && types.IsEmbeddedAddress(block.ToAddress) && len(block.Data) != 0 {
+		if call, err := embeddedabi.Decode(block.ToAddress, block.Data); err == nil {
+			if arguments, err := json.Marshal(call.Inputs); err == nil {
+				exported.Method = call.Method
+				exported.Arguments = string(arguments)
+			}
+		}
+	}
+
+	blocks = append(blocks, exported)
+	for _, descendant := range block.DescendantBlocks {
+		blocks = appendLedgerBlocks(blocks, momentum, descendant, block.Hash.String())
+	}
+	return blocks
+}
@@ -0,0 +1,299 @@
+package export
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	// the rows are buffered and written in row groups of parquetRowGroupSize rows
+	parquetRowGroupSize = 10000
+	parquetMagic        = "PAR1"
+	parquetCreatedBy    = "znnd"
+)
+
+// parquet.thrift enums
+const (
+	parquetTypeInt64     = 2
+	parquetTypeByteArray = 6
+
+	parquetRequired = 0
+
+	parquetConvertedUTF8 = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+
+	parquetCodecUncompressed = 0
+
+	parquetPageData = 0
+)
+
+// parquetWriter writes the rows as a parquet file with required columns, each column chunk is a single
+// uncompressed data page in the plain encoding, which every parquet reader supports.
+type parquetWriter struct {
+	table *table
+	w     io.Writer
+
+	offset    int64
+	rows      [][]interface{}
+	rowGroups []*parquetRowGroup
+	numRows   int64
+}
+
+type parquetColumnChunk struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+type parquetRowGroup struct {
+	columns []*parquetColumnChunk
+	size    int64
+	rows    int64
+}
+
+func newParquetWriter(t *table, w io.Writer) *parquetWriter {
+	return &parquetWriter{
+		table: t,
+		w:     w,
+		rows:  make([][]interface{}, 0, parquetRowGroupSize),
+	}
+}
+
+func (w *parquetWriter) writeBytes(data []byte) error {
+	if w.offset == 0 {
+		if _, err := io.WriteString(w.w, parquetMagic); err != nil {
+			return err
+		}
+		w.offset = int64(len(parquetMagic))
+	}
+	n, err := w.w.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+func (w *parquetWriter) write(row []interface{}) error {
+	w.rows = append(w.rows, row)
+	if len(w.rows) == parquetRowGroupSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group.
+func (w *parquetWriter) flush() error {
+	if len(w.rows) == 0 {
+		return nil
+	}
+	group := &parquetRowGroup{
+		columns: make([]*parquetColumnChunk, len(w.table.columns)),
+		rows:    int64(len(w.rows)),
+	}
+	for i, column := range w.table.columns {
+		// required columns have no repetition and definition levels, the page is just the values
+		page := make([]byte, 0)
+		for _, row := range w.rows {
+			switch column.typ {
+			case int64Column:
+				page = binary.LittleEndian.AppendUint64(page, row[i].(uint64))
+			case stringColumn:
+				value := row[i].(string)
+				page = binary.LittleEndian.AppendUint32(page, uint32(len(value)))
+				page = append(page, value...)
+			}
+		}
+
+		header := new(thriftWriter)
+		header.i32Field(1, parquetPageData)
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(page)))
+		header.structField(5)
+		header.i32Field(1, int32(len(w.rows)))
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunk := &parquetColumnChunk{
+			offset: w.offset,
+			size:   int64(len(header.buf) + len(page)),
+			values: int64(len(w.rows)),
+		}
+		if chunk.offset == 0 {
+			chunk.offset = int64(len(parquetMagic))
+		}
+		if err := w.writeBytes(header.buf); err != nil {
+			return err
+		}
+		if err := w.writeBytes(page); err != nil {
+			return err
+		}
+		group.columns[i] = chunk
+		group.size += chunk.size
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += group.rows
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// end writes the remaining rows and the footer, the file metadata.
+func (w *parquetWriter) end() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	metadata := new(thriftWriter)
+	metadata.i32Field(1, 1)
+	metadata.listField(2, thriftStruct, len(w.table.columns)+1)
+	// the root of the schema
+	metadata.beginStruct()
+	metadata.binaryField(4, w.table.name)
+	metadata.i32Field(5, int32(len(w.table.columns)))
+	metadata.endStruct()
+	for _, column := range w.table.columns {
+		metadata.beginStruct()
+		metadata.i32Field(1, column.parquetType())
+		metadata.i32Field(3, parquetRequired)
+		metadata.binaryField(4, column.name)
+		if column.typ == stringColumn {
+			metadata.i32Field(6, parquetConvertedUTF8)
+		}
+		metadata.endStruct()
+	}
+	metadata.i64Field(3, w.numRows)
+	metadata.listField(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		metadata.beginStruct()
+		metadata.listField(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			column := w.table.columns[i]
+			metadata.beginStruct()
+			metadata.i64Field(2, chunk.offset)
+			metadata.structField(3)
+			metadata.i32Field(1, column.parquetType())
+			metadata.listField(2, thriftI32, 2)
+			metadata.i32(parquetEncodingPlain)
+			metadata.i32(parquetEncodingRLE)
+			metadata.listField(3, thriftBinary, 1)
+			metadata.binary(column.name)
+			metadata.i32Field(4, parquetCodecUncompressed)
+			metadata.i64Field(5, chunk.values)
+			metadata.i64Field(6, chunk.size)
+			metadata.i64Field(7, chunk.size)
+			metadata.i64Field(9, chunk.offset)
+			metadata.endStruct()
+			metadata.endStruct()
+		}
+		metadata.i64Field(2, group.size)
+		metadata.i64Field(3, group.rows)
+		metadata.endStruct()
+	}
+	metadata.binaryField(6, parquetCreatedBy)
+	metadata.endStruct()
+
+	if err := w.writeBytes(metadata.buf); err != nil {
+		return err
+	}
+	if err := w.writeBytes(binary.LittleEndian.AppendUint32(nil, uint32(len(metadata.buf)))); err != nil {
+		return err
+	}
+	return w.writeBytes([]byte(parquetMagic))
+}
+
+func (c column) parquetType() int32 {
+	if c.typ == stringColumn {
+		return parquetTypeByteArray
+	}
+	return parquetTypeInt64
+}
+
+// thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the parquet metadata in the thrift compact protocol. The writer starts inside the
+// top-level struct, which is closed by the last endStruct.
+type thriftWriter struct {
+	buf []byte
+	// the id of the last field of the current struct and the ones of the enclosing structs
+	lastField int16
+	stack     []int16
+}
+
+func (t *thriftWriter) varint(value uint64) {
+	t.buf = binary.AppendUvarint(t.buf, value)
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.lastField; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(uint64(uint16((id << 1) ^ (id >> 15))))
+	}
+	t.lastField = id
+}
+
+func (t *thriftWriter) i32(value int32) {
+	t.varint(uint64(uint32((value << 1) ^ (value >> 31))))
+}
+func (t *thriftWriter) i64(value int64) {
+	t.varint(uint64((value << 1) ^ (value >> 63)))
+}
+func (t *thriftWriter) binary(value string) {
+	t.varint(uint64(len(value)))
+	t.buf = append(t.buf, value...)
+}
+
+func (t *thriftWriter) i32Field(id int16, value int32) {
+	t.field(id, thriftI32)
+	t.i32(value)
+}
+func (t *thriftWriter) i64Field(id int16, value int64) {
+	t.field(id, thriftI64)
+	t.i64(value)
+}
+func (t *thriftWriter) binaryField(id int16, value string) {
+	t.field(id, thriftBinary)
+	t.binary(value)
+}
+
+// listField starts a list of size elements of the type, the elements are written right after it.
+func (t *thriftWriter) listField(id int16, elementType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elementType)
+	} else {
+		t.buf = append(t.buf, 0xf0|elementType)
+		t.varint(uint64(size))
+	}
+}
+
+// structField starts a struct field, its fields are written until endStruct.
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.beginStruct()
+}
+
+// beginStruct starts a struct which isn't a field, an element of a list.
+func (t *thriftWriter) beginStruct() {
+	t.stack = append(t.stack, t.lastField)
+	t.lastField = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	if len(t.stack) != 0 {
+		t.lastField = t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+}
@@ -0,0 +1,238 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+// thriftReader decodes the thrift compact protocol independently of thriftWriter. The structs are decoded as
+// maps of their field ids, the integers as int64 and the binaries as strings.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		panic("unexpected end of the thrift data")
+	}
+	r.pos += 1
+	return r.data[r.pos-1]
+}
+
+func (r *thriftReader) varint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		panic("invalid varint")
+	}
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) zigzag() int64 {
+	value := r.varint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case 3:
+		return int64(int8(r.byte()))
+	case 4, 5, 6:
+		return r.zigzag()
+	case 8:
+		size := int(r.varint())
+		value := string(r.data[r.pos : r.pos+size])
+		r.pos += size
+		return value
+	case 9:
+		header := r.byte()
+		size, elementType := int(header>>4), header&0xf
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elementType)
+		}
+		return list
+	case 12:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %v", typ))
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0xf)
+		last = id
+	}
+}
+
+// readThrift decodes the struct at offset and returns it with the offset after it.
+func readThrift(t *testing.T, data []byte, offset int64) (result map[int16]interface{}, end int64) {
+	defer func() {
+		if err := recover(); err != nil {
+			t.Fatalf("invalid thrift struct at %v: %v", offset, err)
+		}
+	}()
+	r := &thriftReader{data: data, pos: int(offset)}
+	result = r.structure()
+	return result, int64(r.pos)
+}
+
+func expectField(t *testing.T, name string, s map[int16]interface{}, id int16, expected interface{}) {
+	if fmt.Sprint(s[id]) != fmt.Sprint(expected) {
+		t.Fatalf("%v (field %v) is %v, expected %v", name, id, s[id], expected)
+	}
+}
+
+// readPlainValues decodes the values of a page of a required column in the plain encoding.
+func readPlainValues(t *testing.T, page []byte, c column, count int) []interface{} {
+	values := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		switch c.typ {
+		case int64Column:
+			if len(page) < 8 {
+				t.Fatalf("page of %v ends at value %v", c.name, i)
+			}
+			values = append(values, binary.LittleEndian.Uint64(page))
+			page = page[8:]
+		case stringColumn:
+			if len(page) < 4 {
+				t.Fatalf("page of %v ends at value %v", c.name, i)
+			}
+			size := int(binary.LittleEndian.Uint32(page))
+			values = append(values, string(page[4:4+size]))
+			page = page[4+size:]
+		}
+	}
+	if len(page) != 0 {
+		t.Fatalf("page of %v has %v bytes after its values", c.name, len(page))
+	}
+	return values
+}
+
+// Test the parquet files
+//   - test the footer decodes to the schema, the row groups and the column chunks written
+//   - test each column chunk is a page whose header and values decode to the rows written
+//   - test the chunks, the footer and its length are laid out as the footer describes
+func TestParquetWriter(t *testing.T) {
+	testTable := &table{name: "test", columns: []column{{"height", int64Column}, {"hash", stringColumn}}}
+	rows := make([][]interface{}, parquetRowGroupSize+3)
+	for i := range rows {
+		rows[i] = []interface{}{uint64(i) << 33, fmt.Sprintf("row-%v", i)}
+	}
+	rows[1][1] = ""
+
+	buf := new(bytes.Buffer)
+	w := newParquetWriter(testTable, buf)
+	for _, row := range rows {
+		if err := w.write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.end(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte(parquetMagic)) || !bytes.HasSuffix(data, []byte(parquetMagic)) {
+		t.Fatal("missing parquet magic")
+	}
+	footerSize := int64(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerOffset := int64(len(data)) - 8 - footerSize
+	metadata, end := readThrift(t, data, footerOffset)
+	if end != footerOffset+footerSize {
+		t.Fatalf("footer ends at %v, expected %v", end, footerOffset+footerSize)
+	}
+
+	expectField(t, "version", metadata, 1, 1)
+	expectField(t, "num_rows", metadata, 3, len(rows))
+	expectField(t, "created_by", metadata, 6, parquetCreatedBy)
+	schema := metadata[2].([]interface{})
+	if len(schema) != len(testTable.columns)+1 {
+		t.Fatalf("schema has %v elements", len(schema))
+	}
+	root := schema[0].(map[int16]interface{})
+	expectField(t, "root name", root, 4, testTable.name)
+	expectField(t, "root num_children", root, 5, len(testTable.columns))
+	for i, c := range testTable.columns {
+		element := schema[i+1].(map[int16]interface{})
+		expectField(t, "column name", element, 4, c.name)
+		expectField(t, "column type", element, 1, c.parquetType())
+		expectField(t, "column repetition", element, 3, parquetRequired)
+		if c.typ == stringColumn {
+			expectField(t, "column converted type", element, 6, parquetConvertedUTF8)
+		} else if _, ok := element[6]; ok {
+			t.Fatalf("column %v has a converted type", c.name)
+		}
+	}
+
+	groups := metadata[4].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("%v row groups, expected 2", len(groups))
+	}
+	offset := int64(len(parquetMagic))
+	read := make([][]interface{}, 0, len(rows))
+	for _, g := range groups {
+		group := g.(map[int16]interface{})
+		groupRows := int(group[3].(int64))
+		columns := make([][]interface{}, len(testTable.columns))
+		var groupSize int64
+		for i, ch := range group[1].([]interface{}) {
+			c := testTable.columns[i]
+			chunk := ch.(map[int16]interface{})
+			meta := chunk[3].(map[int16]interface{})
+			expectField(t, "file_offset", chunk, 2, offset)
+			expectField(t, "data_page_offset", meta, 9, offset)
+			expectField(t, "type", meta, 1, c.parquetType())
+			expectField(t, "encodings", meta, 2, []interface{}{int64(parquetEncodingPlain), int64(parquetEncodingRLE)})
+			expectField(t, "path_in_schema", meta, 3, []interface{}{c.name})
+			expectField(t, "codec", meta, 4, parquetCodecUncompressed)
+			expectField(t, "num_values", meta, 5, groupRows)
+
+			header, pageOffset := readThrift(t, data, offset)
+			expectField(t, "page type", header, 1, parquetPageData)
+			expectField(t, "compressed_page_size", header, 3, header[2])
+			dataHeader := header[5].(map[int16]interface{})
+			expectField(t, "page num_values", dataHeader, 1, groupRows)
+			expectField(t, "page encoding", dataHeader, 2, parquetEncodingPlain)
+			pageEnd := pageOffset + header[2].(int64)
+			expectField(t, "total_compressed_size", meta, 7, pageEnd-offset)
+			expectField(t, "total_uncompressed_size", meta, 6, pageEnd-offset)
+
+			columns[i] = readPlainValues(t, data[pageOffset:pageEnd], c, groupRows)
+			groupSize += pageEnd - offset
+			offset = pageEnd
+		}
+		expectField(t, "total_byte_size", group, 2, groupSize)
+		for j := 0; j < groupRows; j++ {
+			row := make([]interface{}, len(columns))
+			for i := range columns {
+				row[i] = columns[i][j]
+			}
+			read = append(read, row)
+		}
+	}
+	if offset != footerOffset {
+		t.Fatalf("column chunks end at %v, the footer starts at %v", offset, footerOffset)
+	}
+	if fmt.Sprint(read) != fmt.Sprint(rows) {
+		t.Fatal("rows read don't match the rows written")
+	}
+}
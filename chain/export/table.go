@@ -0,0 +1,80 @@
+package export
+
+import (
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type columnType int
+
+const (
+	int64Column columnType = iota
+	stringColumn
+)
+
+type column struct {
+	name string
+	typ  columnType
+}
+
+// table is the schema of the rows of an export, the values of a row are uint64 for the int64 columns
+// and strings for the string columns, in the order of the columns.
+type table struct {
+	name    string
+	columns []column
+}
+
+type tableWriter interface {
+	write(row []interface{}) error
+	end() error
+}
+
+func newTableWriter(format string) func(*table, io.Writer) tableWriter {
+	switch format {
+	case FormatParquet:
+		return func(t *table, w io.Writer) tableWriter { return newParquetWriter(t, w) }
+	case FormatProtobuf:
+		return func(t *table, w io.Writer) tableWriter { return &protobufWriter{table: t, w: w} }
+	}
+	return nil
+}
+
+// protobufWriter writes the rows as a stream of protobuf messages, each prefixed by its size as a varint,
+// the framing of writeDelimitedTo and protodelim. The messages are described by ledger.proto, the field
+// numbers follow the order of the columns.
+type protobufWriter struct {
+	table *table
+	w     io.Writer
+	buf   []byte
+}
+
+func (w *protobufWriter) write(row []interface{}) error {
+	message := w.buf[:0]
+	for i, column := range w.table.columns {
+		number := protowire.Number(i + 1)
+		// proto3 doesn't encode the default values
+		switch column.typ {
+		case int64Column:
+			if value := row[i].(uint64); value != 0 {
+				message = protowire.AppendTag(message, number, protowire.VarintType)
+				message = protowire.AppendVarint(message, value)
+			}
+		case stringColumn:
+			if value := row[i].(string); value != "" {
+				message = protowire.AppendTag(message, number, protowire.BytesType)
+				message = protowire.AppendString(message, value)
+			}
+		}
+	}
+	w.buf = message
+
+	if _, err := w.w.Write(protowire.AppendVarint(nil, uint64(len(message)))); err != nil {
+		return err
+	}
+	_, err := w.w.Write(message)
+	return err
+}
+func (w *protobufWriter) end() error {
+	return nil
+}
@@ -0,0 +1,236 @@
+package genesis
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+)
+
+const (
+	// MainnetChainIdentifier is the chain identifier of the embedded genesis, private networks must use another one
+	// so their nodes and account-blocks can't be mixed up with the mainnet ones.
+	MainnetChainIdentifier = 1
+
+	genesisTokenMaxSupply = 9007199254740991
+)
+
+// DevnetPillar is a pillar registered at genesis. Its address produces the momentums, stakes the pillar amount,
+// which is locked in the pillar contract, withdraws the rewards and delegates to the pillar.
+type DevnetPillar struct {
+	Name    string
+	Address types.Address
+}
+
+// DevnetBalance is the initial balance of an account.
+type DevnetBalance struct {
+	Address types.Address
+	Znn     *big.Int
+	Qsr     *big.Int
+}
+
+// DevnetFusion is QSR fused at genesis for the plasma of an address, the amount is locked in the plasma contract.
+type DevnetFusion struct {
+	Address types.Address
+	Qsr     *big.Int
+}
+
+// DevnetConfig describes the genesis of a private network, see GenerateGenesis.
+type DevnetConfig struct {
+	ChainIdentifier     uint64
+	ExtraData           string
+	GenesisTimestampSec int64
+	SporkAddress        types.Address
+
+	Pillars  []*DevnetPillar
+	Balances []*DevnetBalance
+	Fusions  []*DevnetFusion
+	// ActiveSporks are the ids of the implemented sporks enforced from the first momentum
+	ActiveSporks []types.Hash
+}
+
+// GenerateGenesis builds the genesis config of a private network with the ZNN and QSR tokens, whose total supplies
+// are the sum of all the balances, including the amounts locked in the embedded contracts.
+func GenerateGenesis(cfg *DevnetConfig) (*GenesisConfig, error) {
+	if cfg.ChainIdentifier == 0 {
+		return nil, errors.Errorf("the chain identifier must be strictly greater than zero")
+	}
+	if cfg.ChainIdentifier == MainnetChainIdentifier {
+		return nil, errors.Errorf("the chain identifier %v is used by the mainnet", MainnetChainIdentifier)
+	}
+	if len(cfg.Pillars) == 0 {
+		return nil, errors.Errorf("at least one pillar is required to produce momentums")
+	}
+
+	sporkAddress := cfg.SporkAddress
+	config := &GenesisConfig{
+		ChainIdentifier:     cfg.ChainIdentifier,
+		ExtraData:           cfg.ExtraData,
+		GenesisTimestampSec: cfg.GenesisTimestampSec,
+		SporkAddress:        &sporkAddress,
+		PillarConfig: &PillarContractConfig{
+			Pillars:       make([]*definition.PillarInfo, 0, len(cfg.Pillars)),
+			Delegations:   make([]*definition.DelegationInfo, 0, len(cfg.Pillars)),
+			LegacyEntries: []*definition.LegacyPillarEntry{},
+		},
+		PlasmaConfig: &PlasmaContractConfig{
+			Fusions: make([]*definition.FusionInfo, 0, len(cfg.Fusions)),
+		},
+		SwapConfig: &SwapContractConfig{
+			Entries: []*definition.SwapAssets{},
+		},
+		SporkConfig: &SporkConfig{
+			Sporks: make([]*definition.Spork, 0, len(cfg.ActiveSporks)),
+		},
+		GenesisBlocks: &GenesisBlocksConfig{
+			Blocks: []*GenesisBlockConfig{},
+		},
+	}
+
+	balances := make(map[types.Address]map[types.ZenonTokenStandard]*big.Int)
+	credit := func(address types.Address, zts types.ZenonTokenStandard, amount *big.Int) {
+		if amount == nil || amount.Sign() == 0 {
+			return
+		}
+		balance, ok := balances[address]
+		if !ok {
+			balance = make(map[types.ZenonTokenStandard]*big.Int)
+			balances[address] = balance
+		}
+		if _, ok := balance[zts]; !ok {
+			balance[zts] = new(big.Int)
+		}
+		balance[zts].Add(balance[zts], amount)
+	}
+
+	names := make(map[string]bool)
+	for _, pillar := range cfg.Pillars {
+		if err := implementation.CheckPillarNameStatic(pillar.Name); err != nil {
+			return nil, errors.Errorf("invalid pillar name %q", pillar.Name)
+		}
+		if names[pillar.Name] {
+			return nil, errors.Errorf("duplicate pillar name %q", pillar.Name)
+		}
+		names[pillar.Name] = true
+		config.PillarConfig.Pillars = append(config.PillarConfig.Pillars, &definition.PillarInfo{
+			Name:                         pillar.Name,
+			BlockProducingAddress:        pillar.Address,
+			StakeAddress:                 pillar.Address,
+			RewardWithdrawAddress:        pillar.Address,
+			Amount:                       new(big.Int).Set(constants.PillarStakeAmount),
+			RegistrationTime:             cfg.GenesisTimestampSec,
+			GiveBlockRewardPercentage:    0,
+			GiveDelegateRewardPercentage: 100,
+			PillarType:                   definition.NormalPillarType,
+		})
+		config.PillarConfig.Delegations = append(config.PillarConfig.Delegations, &definition.DelegationInfo{
+			Name:   pillar.Name,
+			Backer: pillar.Address,
+		})
+		credit(types.PillarContract, types.ZnnTokenStandard, constants.PillarStakeAmount)
+	}
+
+	for index, fusion := range cfg.Fusions {
+		if fusion.Qsr == nil || fusion.Qsr.Cmp(constants.FuseMinAmount) < 0 {
+			return nil, errors.Errorf("the fusion for %v is below the minimum of %v QSR", fusion.Address, new(big.Int).Div(constants.FuseMinAmount, big.NewInt(constants.Decimals)))
+		}
+		config.PlasmaConfig.Fusions = append(config.PlasmaConfig.Fusions, &definition.FusionInfo{
+			Owner:            fusion.Address,
+			Id:               types.NewHash(common.JoinBytes(fusion.Address.Bytes(), common.Uint64ToBytes(uint64(index)))),
+			Amount:           new(big.Int).Set(fusion.Qsr),
+			ExpirationHeight: 0,
+			Beneficiary:      fusion.Address,
+		})
+		credit(types.PlasmaContract, types.QsrTokenStandard, fusion.Qsr)
+	}
+
+	for _, balance := range cfg.Balances {
+		if types.IsEmbeddedAddress(balance.Address) {
+			return nil, errors.Errorf("can't set the balance of the embedded contract %v", balance.Address)
+		}
+		credit(balance.Address, types.ZnnTokenStandard, balance.Znn)
+		credit(balance.Address, types.QsrTokenStandard, balance.Qsr)
+	}
+
+	for _, id := range cfg.ActiveSporks {
+		if !types.ImplementedSporksMap[id] {
+			return nil, errors.Errorf("%v isn't an implemented spork", id)
+		}
+		config.SporkConfig.Sporks = append(config.SporkConfig.Sporks, &definition.Spork{
+			Id:                id,
+			Name:              "genesis-" + id.String()[:8],
+			Description:       "activated at genesis",
+			Activated:         true,
+			EnforcementHeight: 1,
+		})
+	}
+
+	supply := map[types.ZenonTokenStandard]*big.Int{
+		types.ZnnTokenStandard: new(big.Int),
+		types.QsrTokenStandard: new(big.Int),
+	}
+	for address, balance := range balances {
+		for zts, amount := range balance {
+			supply[zts].Add(supply[zts], amount)
+		}
+		config.GenesisBlocks.Blocks = append(config.GenesisBlocks.Blocks, &GenesisBlockConfig{
+			Address:     address,
+			BalanceList: balance,
+		})
+	}
+	// map iteration is random, keep the file stable
+	sort.Slice(config.GenesisBlocks.Blocks, func(i, j int) bool {
+		return config.GenesisBlocks.Blocks[i].Address.String() < config.GenesisBlocks.Blocks[j].Address.String()
+	})
+
+	config.TokenConfig = &TokenContractConfig{
+		Tokens: []*definition.TokenInfo{
+			newGenesisToken("ZNN", types.ZnnTokenStandard, supply[types.ZnnTokenStandard]),
+			newGenesisToken("QSR", types.QsrTokenStandard, supply[types.QsrTokenStandard]),
+		},
+	}
+	// a token must be given to someone to be declared
+	for i := len(config.TokenConfig.Tokens) - 1; i >= 0; i -= 1 {
+		if config.TokenConfig.Tokens[i].TotalSupply.Sign() == 0 {
+			config.TokenConfig.Tokens = append(config.TokenConfig.Tokens[:i], config.TokenConfig.Tokens[i+1:]...)
+		}
+	}
+
+	if err := CheckGenesis(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func newGenesisToken(symbol string, zts types.ZenonTokenStandard, totalSupply *big.Int) *definition.TokenInfo {
+	return &definition.TokenInfo{
+		Owner:         types.TokenContract,
+		TokenName:     symbol,
+		TokenSymbol:   symbol,
+		TokenDomain:   "zenon.network",
+		TotalSupply:   totalSupply,
+		MaxSupply:     big.NewInt(genesisTokenMaxSupply),
+		Decimals:      8,
+		IsMintable:    true,
+		IsBurnable:    true,
+		IsUtility:     true,
+		TokenStandard: zts,
+	}
+}
+
+// WriteGenesisConfigToFile writes the genesis config in the format read by ReadGenesisConfigFromFile.
+func WriteGenesisConfigToFile(config *GenesisConfig, genesisFile string) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(genesisFile, data, 0644)
+}
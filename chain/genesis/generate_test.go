@@ -0,0 +1,63 @@
+package genesis
+
+import (
+	"math/big"
+	"path"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+func TestGenerateGenesis(t *testing.T) {
+	pillar := types.ParseAddressPanic("z1qqjnwjjpnue8xmmpanz6csze6tcmtzzdtfsww7")
+	user := types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+	cfg := &DevnetConfig{
+		ChainIdentifier:     321,
+		GenesisTimestampSec: 1000000000,
+		SporkAddress:        pillar,
+		Pillars:             []*DevnetPillar{{Name: "devnet-pillar", Address: pillar}},
+		Balances: []*DevnetBalance{
+			{Address: pillar, Znn: big.NewInt(100 * constants.Decimals), Qsr: big.NewInt(1000 * constants.Decimals)},
+			{Address: user, Znn: big.NewInt(5 * constants.Decimals), Qsr: big.NewInt(0)},
+		},
+		Fusions:      []*DevnetFusion{{Address: pillar, Qsr: big.NewInt(100 * constants.Decimals)}},
+		ActiveSporks: []types.Hash{types.HtlcSpork.SporkId},
+	}
+	config, err := GenerateGenesis(cfg)
+	common.FailIfErr(t, err)
+
+	genesisFile := path.Join(t.TempDir(), "genesis.json")
+	common.FailIfErr(t, WriteGenesisConfigToFile(config, genesisFile))
+	read, err := ReadGenesisConfigFromFile(genesisFile)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, read.GetGenesisMomentum().Hash.String(), NewGenesis(config).GetGenesisMomentum().Hash.String())
+	common.ExpectUint64(t, read.GetGenesisMomentum().ChainIdentifier, 321)
+	common.ExpectAmount(t, config.TokenConfig.Tokens[0].TotalSupply, new(big.Int).Add(constants.PillarStakeAmount, big.NewInt(105*constants.Decimals)))
+	common.ExpectAmount(t, config.TokenConfig.Tokens[1].TotalSupply, big.NewInt(1100*constants.Decimals))
+
+	for _, invalid := range []func(cfg DevnetConfig) DevnetConfig{
+		func(cfg DevnetConfig) DevnetConfig { cfg.ChainIdentifier = MainnetChainIdentifier; return cfg },
+		func(cfg DevnetConfig) DevnetConfig { cfg.ChainIdentifier = 0; return cfg },
+		func(cfg DevnetConfig) DevnetConfig { cfg.Pillars = nil; return cfg },
+		func(cfg DevnetConfig) DevnetConfig {
+			cfg.Pillars = []*DevnetPillar{{Name: "-invalid", Address: pillar}}
+			return cfg
+		},
+		func(cfg DevnetConfig) DevnetConfig {
+			cfg.Fusions = []*DevnetFusion{{Address: pillar, Qsr: big.NewInt(1)}}
+			return cfg
+		},
+		func(cfg DevnetConfig) DevnetConfig {
+			cfg.Balances = []*DevnetBalance{{Address: types.PillarContract, Znn: big.NewInt(1)}}
+			return cfg
+		},
+		func(cfg DevnetConfig) DevnetConfig { cfg.ActiveSporks = []types.Hash{{1}}; return cfg },
+	} {
+		invalidCfg := invalid(*cfg)
+		if _, err := GenerateGenesis(&invalidCfg); err == nil {
+			t.Fatalf("expected an error for %+v", invalidCfg)
+		}
+	}
+}
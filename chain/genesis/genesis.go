@@ -4,6 +4,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
 type genesis struct {
@@ -36,3 +37,9 @@ func (g *genesis) GetGenesisTransaction() *nom.MomentumTransaction {
 func (g *genesis) GetSporkAddress() *types.Address {
 	return g.config.SporkAddress
 }
+func (g *genesis) GetGenesisTokens() []*definition.TokenInfo {
+	if g.config.TokenConfig == nil {
+		return nil
+	}
+	return g.config.TokenConfig.Tokens
+}
@@ -3,6 +3,7 @@ package chain
 import (
 	"sync"
 
+	"github.com/zenon-network/go-zenon/chain/momentum"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/db"
@@ -19,8 +20,19 @@ type Chain interface {
 	// does not enforce in any way the validity, only the fact that is non-nil.
 	AcquireInsert(reason string) sync.Locker
 
+	// GetNewMomentumContent returns the uncommitted account-blocks which fit in the next momentum, see PayloadLimits.
+	GetNewMomentumContent() []*nom.AccountBlock
+
+	// GetAccountBlockLogs returns the logs emitted by the embedded contract which received the block,
+	// nil if there are none or the block isn't confirmed yet.
+	GetAccountBlockLogs(hash types.Hash) ([]*nom.Log, error)
+	// GetAccountBlockFailure returns why the embedded contract which received the block rejected the call,
+	// nil if it succeeded, for other blocks or if the block isn't confirmed yet.
+	GetAccountBlockFailure(hash types.Hash) (*nom.ReceiveFailure, error)
+
 	store.Genesis
 	AccountPool
+	AccountChainConflicts
 	MomentumPool
 	MomentumEventManager
 }
@@ -30,9 +42,17 @@ type MomentumEventListener interface {
 	DeleteMomentum(*nom.DetailedMomentum)
 }
 
+// RollbackListener can be implemented by a MomentumEventListener to be notified about reorgs.
+// Rollback is called once the rollback is committed, after DeleteMomentum for each deleted momentum.
+type RollbackListener interface {
+	Rollback(*RollbackEvent)
+}
+
 type MomentumEventManager interface {
 	Register(MomentumEventListener)
 	UnRegister(MomentumEventListener)
+
+	GetRollbackInfo() *RollbackInfo
 }
 
 type MomentumPool interface {
@@ -41,6 +61,15 @@ type MomentumPool interface {
 
 	GetFrontierMomentumStore() store.Momentum
 	GetMomentumStore(identifier types.HashHeight) store.Momentum
+	// GetRecentMomentumStore is like GetMomentumStore for the momentums at most MaxRecentStateDepth below
+	// the frontier one, it returns ErrStateTooOld for the older ones. It must be used to serve the state
+	// to the RPC clients and the peers.
+	GetRecentMomentumStore(identifier types.HashHeight) (store.Momentum, error)
+	// GetMomentumPatch returns the changes applied by the momentum, their hash is the ChangesHash of the momentum.
+	// Returns nil if the momentum isn't inserted.
+	GetMomentumPatch(identifier types.HashHeight) db.Patch
+	// GetCacheStats returns the hits and misses of the cache of the momentum stores, nil if it's disabled.
+	GetCacheStats() *momentum.CacheStats
 }
 
 type AccountPool interface {
@@ -59,7 +88,6 @@ type AccountPool interface {
 	GetAccountStore(address types.Address, identifier types.HashHeight) store.Account
 	GetFrontierAccountStore(address types.Address) store.Account
 
-	GetNewMomentumContent() []*nom.AccountBlock
 	GetAllUncommittedAccountBlocks() []*nom.AccountBlock
 	GetUncommittedAccountBlocksByAddress(address types.Address) []*nom.AccountBlock
 }
@@ -16,7 +16,12 @@ func (ms *momentumStore) GetFrontierAccountBlock(address types.Address) (*nom.Ac
 	return ms.GetAccountStore(address).Frontier()
 }
 func (ms *momentumStore) GetAccountBlock(header types.AccountHeader) (*nom.AccountBlock, error) {
-	return ms.GetAccountStore(header.Address).ByHeight(header.Height)
+	if ms.cache != nil {
+		if block := ms.cache.getBlock(ms.generation, header.Hash, ms.frontier.Height); block != nil && block.Address == header.Address && block.Height == header.Height {
+			return block, nil
+		}
+	}
+	return ms.cacheAccountBlock(ms.GetAccountStore(header.Address).ByHeight(header.Height))
 }
 func (ms *momentumStore) GetAccountBlockByHeight(address types.Address, height uint64) (*nom.AccountBlock, error) {
 	return ms.GetAccountStore(address).ByHeight(height)
@@ -33,6 +38,11 @@ func (ms *momentumStore) addAccountBlockHeader(header types.AccountHeader) error
 	return ms.DB.Put(getAccountHeaderByHashKey(header.Hash), data)
 }
 func (ms *momentumStore) GetAccountBlockByHash(hash types.Hash) (*nom.AccountBlock, error) {
+	if ms.cache != nil {
+		if block := ms.cache.getBlock(ms.generation, hash, ms.frontier.Height); block != nil {
+			return block, nil
+		}
+	}
 	data, err := ms.DB.Get(getAccountHeaderByHashKey(hash))
 
 	if err == leveldb.ErrNotFound {
@@ -45,6 +55,21 @@ func (ms *momentumStore) GetAccountBlockByHash(hash types.Hash) (*nom.AccountBlo
 	if header, err := types.DeserializeAccountHeader(data); err != nil {
 		return nil, err
 	} else {
-		return ms.GetAccountStore(header.Address).ByHeight(header.Height)
+		return ms.GetAccountBlock(*header)
+	}
+}
+
+// cacheAccountBlock adds the block read from the store to the cache, if any.
+func (ms *momentumStore) cacheAccountBlock(block *nom.AccountBlock, err error) (*nom.AccountBlock, error) {
+	if ms.cache == nil || err != nil || block == nil {
+		return block, err
+	}
+	confirmed, err := ms.GetBlockConfirmationHeight(block.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if confirmed != 0 {
+		ms.cache.addBlock(ms.generation, block, confirmed)
 	}
+	return block, nil
 }
@@ -0,0 +1,175 @@
+package momentum
+
+import (
+	"sync"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// Cache keeps the recently read momentums, account-blocks and token infos of the stores created by NewCachedStore,
+// shared between all of them. The cached values are shared with the callers, which must not modify them.
+//
+// Momentums and account-blocks don't change once inserted, they are served to every store whose frontier
+// is above them. Token infos change with the momentums and are only served to stores of the same frontier.
+// Rollbacks must Purge the cache, the stores created before don't use it anymore.
+type Cache struct {
+	// the atomically accessed counters come first to be 64-bit aligned
+	generation  uint64
+	momentumHit cacheCounter
+	blockHit    cacheCounter
+	tokenHit    cacheCounter
+
+	momentums *lru.Cache
+	blocks    *lru.Cache
+	tokens    *lru.Cache
+	purge     sync.Mutex
+}
+
+// cachedBlock is an account-block along with the height of the momentum which confirmed it.
+type cachedBlock struct {
+	block      *nom.AccountBlock
+	confirmed  uint64
+	generation uint64
+}
+
+type cachedMomentum struct {
+	momentum   *nom.Momentum
+	generation uint64
+}
+
+type cachedToken struct {
+	token      *definition.TokenInfo
+	generation uint64
+}
+
+type tokenKey struct {
+	ts       types.ZenonTokenStandard
+	frontier types.Hash
+}
+
+type cacheCounter struct {
+	hits, misses uint64
+}
+
+func (c *cacheCounter) count(hit bool) {
+	if hit {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+func (c *cacheCounter) stats() CacheCounterStats {
+	return CacheCounterStats{Hits: atomic.LoadUint64(&c.hits), Misses: atomic.LoadUint64(&c.misses)}
+}
+
+type CacheCounterStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// CacheStats are the hits and misses of the Cache since the node started.
+type CacheStats struct {
+	Entries       int               `json:"entries"`
+	Momentums     CacheCounterStats `json:"momentums"`
+	AccountBlocks CacheCounterStats `json:"accountBlocks"`
+	TokenInfos    CacheCounterStats `json:"tokenInfos"`
+}
+
+// NewCache creates a Cache which keeps up to size momentums, account-blocks and token infos each.
+func NewCache(size int) *Cache {
+	momentums, err := lru.New(size)
+	common.DealWithErr(err)
+	blocks, err := lru.New(size)
+	common.DealWithErr(err)
+	tokens, err := lru.New(size)
+	common.DealWithErr(err)
+	return &Cache{
+		momentums: momentums,
+		blocks:    blocks,
+		tokens:    tokens,
+	}
+}
+
+func (c *Cache) Stats() *CacheStats {
+	return &CacheStats{
+		Entries:       c.momentums.Len() + c.blocks.Len() + c.tokens.Len(),
+		Momentums:     c.momentumHit.stats(),
+		AccountBlocks: c.blockHit.stats(),
+		TokenInfos:    c.tokenHit.stats(),
+	}
+}
+
+// Purge drops all the entries. The stores created before the purge, which may see momentums rolled back since,
+// neither read nor fill the cache anymore.
+func (c *Cache) Purge() {
+	c.purge.Lock()
+	defer c.purge.Unlock()
+	atomic.AddUint64(&c.generation, 1)
+	c.momentums.Purge()
+	c.blocks.Purge()
+	c.tokens.Purge()
+}
+
+func (c *Cache) currentGeneration() uint64 {
+	return atomic.LoadUint64(&c.generation)
+}
+
+func (c *Cache) getMomentum(generation, height uint64) *nom.Momentum {
+	value, ok := c.momentums.Get(height)
+	if ok && value.(*cachedMomentum).generation == generation {
+		c.momentumHit.count(true)
+		return value.(*cachedMomentum).momentum
+	}
+	c.momentumHit.count(false)
+	return nil
+}
+func (c *Cache) addMomentum(generation uint64, momentum *nom.Momentum) {
+	c.purge.Lock()
+	defer c.purge.Unlock()
+	if generation == c.currentGeneration() {
+		c.momentums.Add(momentum.Height, &cachedMomentum{momentum: momentum, generation: generation})
+	}
+}
+
+func (c *Cache) getBlock(generation uint64, hash types.Hash, frontierHeight uint64) *nom.AccountBlock {
+	value, ok := c.blocks.Get(hash)
+	if ok {
+		cached := value.(*cachedBlock)
+		if cached.generation == generation && cached.confirmed <= frontierHeight {
+			c.blockHit.count(true)
+			return cached.block
+		}
+	}
+	c.blockHit.count(false)
+	return nil
+}
+func (c *Cache) addBlock(generation uint64, block *nom.AccountBlock, confirmed uint64) {
+	c.purge.Lock()
+	defer c.purge.Unlock()
+	if generation == c.currentGeneration() {
+		c.blocks.Add(block.Hash, &cachedBlock{block: block, confirmed: confirmed, generation: generation})
+	}
+}
+
+func (c *Cache) getToken(generation uint64, key tokenKey) *definition.TokenInfo {
+	value, ok := c.tokens.Get(key)
+	if ok && value.(*cachedToken).generation == generation {
+		c.tokenHit.count(true)
+		return value.(*cachedToken).token
+	}
+	c.tokenHit.count(false)
+	return nil
+}
+func (c *Cache) addToken(generation uint64, key tokenKey, token *definition.TokenInfo) {
+	c.purge.Lock()
+	defer c.purge.Unlock()
+	if generation == c.currentGeneration() {
+		c.tokens.Add(key, &cachedToken{token: token, generation: generation})
+	}
+}
@@ -116,12 +116,22 @@ func (ms *momentumStore) GetStakeBeneficialAmount(addr types.Address) (*big.Int,
 	return fused.Amount, nil
 }
 func (ms *momentumStore) GetTokenInfoByTs(ts types.ZenonTokenStandard) (*definition.TokenInfo, error) {
+	key := tokenKey{ts: ts, frontier: ms.frontier.Hash}
+	if ms.cache != nil {
+		if token := ms.cache.getToken(ms.generation, key); token != nil {
+			return token, nil
+		}
+	}
 	sd, err := ms.getEmbeddedStore(types.TokenContract)
 	if err != nil {
 		return nil, fmt.Errorf("getEmbeddedStore failed: %w", err)
 	}
 
-	return definition.GetTokenInfo(sd.Storage(), ts)
+	token, err := definition.GetTokenInfo(sd.Storage(), ts)
+	if ms.cache != nil && err == nil && token != nil {
+		ms.cache.addToken(ms.generation, key, token)
+	}
+	return token, err
 }
 func (ms *momentumStore) GetAllDefinedSporks() ([]*definition.Spork, error) {
 	sd, err := ms.getEmbeddedStore(types.SporkContract)
@@ -1,5 +1,12 @@
 package momentum
 
+import (
+	"bytes"
+
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
 // generic actions
 
 var (
@@ -9,3 +16,12 @@ var (
 	accountZNNBalancePrefix       = []byte{8}
 	accountHeaderByHashPrefix     = []byte{9}
 )
+
+// IsAncientKey reports whether key holds a momentum or an account-block, which never change once confirmed.
+func IsAncientKey(key []byte) bool {
+	if db.IsEntryByHeightKey(key) {
+		return true
+	}
+	prefixLength := len(accountStorePrefix) + types.AddressSize
+	return len(key) > prefixLength && bytes.HasPrefix(key, accountStorePrefix) && db.IsEntryByHeightKey(key[prefixLength:])
+}
@@ -16,6 +16,11 @@ import (
 type momentumStore struct {
 	store.Genesis
 	db.DB
+
+	// cache is nil if the store isn't created by NewCachedStore
+	cache      *Cache
+	generation uint64
+	frontier   types.HashHeight
 }
 
 func getAccountStorePrefix(address types.Address) []byte {
@@ -26,6 +31,15 @@ func getAccountMailboxPrefix(address types.Address) []byte {
 }
 
 func (ms *momentumStore) Snapshot() store.Momentum {
+	if ms.cache != nil {
+		return &momentumStore{
+			Genesis:    ms.Genesis,
+			DB:         ms.DB.Snapshot(),
+			cache:      ms.cache,
+			generation: ms.generation,
+			frontier:   ms.frontier,
+		}
+	}
 	return NewStore(ms.Genesis, ms.DB.Snapshot())
 }
 
@@ -135,6 +149,21 @@ func NewStore(genesis store.Genesis, db db.DB) store.Momentum {
 		DB:      db,
 	}
 }
+
+// NewCachedStore creates a store which reads the momentums, account-blocks and token infos through cache.
+// momentumDB must not change, like the snapshots of the chain manager.
+func NewCachedStore(genesis store.Genesis, momentumDB db.DB, cache *Cache) store.Momentum {
+	if momentumDB == nil {
+		panic("momentum store can't operate with nil db")
+	}
+	return &momentumStore{
+		Genesis:    genesis,
+		DB:         momentumDB,
+		cache:      cache,
+		generation: cache.currentGeneration(),
+		frontier:   db.GetFrontierIdentifier(momentumDB),
+	}
+}
 func NewGenesisStore() store.Momentum {
 	return &momentumStore{
 		Genesis: nil,
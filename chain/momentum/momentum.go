@@ -30,9 +30,22 @@ func parseMomentum(data []byte, err error) (*nom.Momentum, error) {
 }
 
 func (ms *momentumStore) GetFrontierMomentum() (*nom.Momentum, error) {
+	if ms.cache != nil {
+		return ms.GetMomentumByHeight(ms.frontier.Height)
+	}
 	return parseMomentum(db.GetEntryByHeight(ms.DB, db.GetFrontierIdentifier(ms.DB).Height))
 }
 func (ms *momentumStore) GetMomentumByHash(hash types.Hash) (*nom.Momentum, error) {
+	if ms.cache != nil {
+		identifier, err := db.GetIdentifierByHash(ms.DB, hash)
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ms.GetMomentumByHeight(identifier.Height)
+	}
 	return parseMomentum(db.GetEntryByHash(ms.DB, hash))
 }
 func (ms *momentumStore) GetMomentumsByHash(blockHash types.Hash, higher bool, count uint64) ([]*nom.Momentum, error) {
@@ -43,7 +56,17 @@ func (ms *momentumStore) GetMomentumsByHash(blockHash types.Hash, higher bool, c
 	return ms.GetMomentumsByHeight(momentum.Height, higher, count)
 }
 func (ms *momentumStore) GetMomentumByHeight(height uint64) (*nom.Momentum, error) {
-	return parseMomentum(db.GetEntryByHeight(ms.DB, height))
+	if ms.cache == nil || height > ms.frontier.Height {
+		return parseMomentum(db.GetEntryByHeight(ms.DB, height))
+	}
+	if momentum := ms.cache.getMomentum(ms.generation, height); momentum != nil {
+		return momentum, nil
+	}
+	momentum, err := parseMomentum(db.GetEntryByHeight(ms.DB, height))
+	if err == nil && momentum != nil {
+		ms.cache.addMomentum(ms.generation, momentum)
+	}
+	return momentum, err
 }
 func (ms *momentumStore) GetMomentumsByHeight(height uint64, higher bool, count uint64) ([]*nom.Momentum, error) {
 	var to, from uint64
@@ -4,11 +4,30 @@ import (
 	"sync"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
 )
 
+// RollbackEvent describes a reorg of the momentum chain. It is sent once the Depth momentums above
+// CommonAncestor are deleted, CommonAncestor is the frontier momentum until the next insert.
+type RollbackEvent struct {
+	OldFrontier    types.HashHeight `json:"oldFrontier"`
+	CommonAncestor types.HashHeight `json:"commonAncestor"`
+	Depth          uint64           `json:"depth"`
+}
+
+// RollbackInfo holds the rollback statistics since the node started.
+type RollbackInfo struct {
+	Count    uint64         `json:"count"`
+	MaxDepth uint64         `json:"maxDepth"`
+	Last     *RollbackEvent `json:"last"`
+}
+
 type momentumEventManager struct {
 	listeners []MomentumEventListener
 	changes   sync.Mutex
+
+	rollbacks RollbackInfo
+	infoLock  sync.Mutex
 }
 
 func newMomentumEventManager() *momentumEventManager {
@@ -33,6 +52,35 @@ func (em *momentumEventManager) broadcastDeleteMomentum(detailed *nom.DetailedMo
 		listener.DeleteMomentum(detailed)
 	}
 }
+func (em *momentumEventManager) broadcastRollback(event *RollbackEvent) {
+	em.changes.Lock()
+	defer em.changes.Unlock()
+
+	em.infoLock.Lock()
+	em.rollbacks.Count += 1
+	if event.Depth > em.rollbacks.MaxDepth {
+		em.rollbacks.MaxDepth = event.Depth
+	}
+	em.rollbacks.Last = event
+	em.infoLock.Unlock()
+
+	for _, listener := range em.listeners {
+		if rollbackListener, ok := listener.(RollbackListener); ok {
+			rollbackListener.Rollback(event)
+		}
+	}
+}
+
+func (em *momentumEventManager) broadcastAccountChainConflict(conflict *AccountChainConflict) {
+	em.changes.Lock()
+	defer em.changes.Unlock()
+
+	for _, listener := range em.listeners {
+		if conflictListener, ok := listener.(AccountChainConflictListener); ok {
+			conflictListener.AccountChainConflict(conflict)
+		}
+	}
+}
 
 func (em *momentumEventManager) Register(listener MomentumEventListener) {
 	em.changes.Lock()
@@ -51,3 +99,15 @@ func (em *momentumEventManager) UnRegister(listener MomentumEventListener) {
 		}
 	}
 }
+
+func (em *momentumEventManager) GetRollbackInfo() *RollbackInfo {
+	em.infoLock.Lock()
+	defer em.infoLock.Unlock()
+
+	info := em.rollbacks
+	if info.Last != nil {
+		last := *info.Last
+		info.Last = &last
+	}
+	return &info
+}
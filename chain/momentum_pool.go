@@ -17,12 +17,25 @@ import (
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
+// MaxRecentStateDepth is the number of momentums below the frontier one whose state is served by
+// GetRecentMomentumStore, about an hour. The state of a momentum is rebuilt by applying the rollback of
+// each momentum down from the frontier one while holding the chain lock, so older states would let a
+// single request stall the node.
+const MaxRecentStateDepth = 360
+
+var (
+	ErrStateTooOld = errors.Errorf("state not available, only the state of the last %v momentums is served", MaxRecentStateDepth)
+)
+
 type momentumPool struct {
 	*momentumEventManager
 	chainManager db.Manager
 	genesis      store.Genesis
 	log          log15.Logger
 	changes      sync.Mutex
+
+	// cache is shared by the momentum stores, nil if disabled
+	cache *momentum.Cache
 }
 
 func (c *momentumPool) AddMomentumTransaction(insertLocker sync.Locker, transaction *nom.MomentumTransaction) error {
@@ -92,6 +105,7 @@ func (c *momentumPool) RollbackTo(insertLocker sync.Locker, identifier types.Has
 		return errors.Errorf("can't rollback momentums. Expected %v but got %v instead", momentum.Identifier(), identifier)
 	}
 
+	event := &RollbackEvent{CommonAncestor: identifier}
 	for {
 		store := c.getFrontierStore()
 		frontier, err := store.GetFrontierMomentum()
@@ -102,6 +116,9 @@ func (c *momentumPool) RollbackTo(insertLocker sync.Locker, identifier types.Has
 		if frontier.Height == identifier.Height {
 			break
 		}
+		if event.Depth == 0 {
+			event.OldFrontier = frontier.Identifier()
+		}
 		c.log.Info("rollbacking", "momentum-identifier", frontier.Identifier())
 		detailed, err := store.PrefetchMomentum(frontier)
 		if err != nil {
@@ -110,12 +127,20 @@ func (c *momentumPool) RollbackTo(insertLocker sync.Locker, identifier types.Has
 		if err := c.chainManager.Pop(); err != nil {
 			return err
 		}
+		c.purgeCache()
+
+		event.Depth += 1
 
 		c.changes.Unlock()
 		c.broadcastDeleteMomentum(detailed)
 		c.changes.Lock()
 	}
 
+	if event.Depth != 0 {
+		c.changes.Unlock()
+		c.broadcastRollback(event)
+		c.changes.Lock()
+	}
 	return nil
 }
 
@@ -151,11 +176,22 @@ func GotAllActiveSporksImplemented(store store.Momentum) (justNow *definition.Sp
 	return justNow, unimplemented, nil
 }
 
+func (c *momentumPool) newStore(momentumDB db.DB) store.Momentum {
+	if c.cache != nil {
+		return momentum.NewCachedStore(c.genesis, momentumDB, c.cache)
+	}
+	return momentum.NewStore(c.genesis, momentumDB)
+}
+func (c *momentumPool) purgeCache() {
+	if c.cache != nil {
+		c.cache.Purge()
+	}
+}
 func (c *momentumPool) getFrontierStore() store.Momentum {
 	if momentumDB := c.chainManager.Frontier(); momentumDB == nil {
 		return nil
 	} else {
-		return momentum.NewStore(c.genesis, momentumDB)
+		return c.newStore(momentumDB)
 	}
 }
 func (c *momentumPool) GetFrontierMomentumStore() store.Momentum {
@@ -171,7 +207,22 @@ func (c *momentumPool) GetMomentumStore(identifier types.HashHeight) store.Momen
 		return nil
 	}
 
-	return momentum.NewStore(c.genesis, momentumDB)
+	return c.newStore(momentumDB)
+}
+func (c *momentumPool) GetRecentMomentumStore(identifier types.HashHeight) (store.Momentum, error) {
+	frontier, err := c.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	if identifier.Height+MaxRecentStateDepth < frontier.Height {
+		return nil, ErrStateTooOld
+	}
+	return c.GetMomentumStore(identifier), nil
+}
+func (c *momentumPool) GetMomentumPatch(identifier types.HashHeight) db.Patch {
+	c.changes.Lock()
+	defer c.changes.Unlock()
+	return c.chainManager.GetPatch(identifier)
 }
 func (c *momentumPool) GetStableAccountDB(address types.Address) db.DB {
 	c.changes.Lock()
@@ -179,8 +230,16 @@ func (c *momentumPool) GetStableAccountDB(address types.Address) db.DB {
 	return c.getFrontierStore().GetAccountDB(address)
 }
 
-func NewMomentumPool(chainManager db.Manager, genesis store.Genesis) *momentumPool {
+func (c *momentumPool) GetCacheStats() *momentum.CacheStats {
+	if c.cache == nil {
+		return nil
+	}
+	return c.cache.Stats()
+}
+
+func NewMomentumPool(chainManager db.Manager, genesis store.Genesis, cache *momentum.Cache) *momentumPool {
 	return &momentumPool{
+		cache:                cache,
 		momentumEventManager: newMomentumEventManager(),
 		chainManager:         chainManager,
 		genesis:              genesis,
@@ -28,6 +28,10 @@ const (
 type AccountBlockTransaction struct {
 	Block   *AccountBlock
 	Changes db.Patch
+	// Logs emitted by the embedded contract which received the block, nil for other blocks
+	Logs []*Log
+	// Failure of the call of the embedded contract which received the block, nil if it succeeded or for other blocks
+	Failure *ReceiveFailure
 }
 
 func (t *AccountBlockTransaction) GetCommits() []db.Commit {
@@ -44,6 +48,15 @@ func (t *AccountBlockTransaction) StealChanges() db.Patch {
 	return changes
 }
 
+// ReceiveFailure is the reason why an embedded contract rejected the call of a contract receive-block.
+// Code identifies the error, see constants.FailureCode, and is 0 for the errors only Reason describes.
+//
+// Like logs, failures are not part of the changes of the receive-block.
+type ReceiveFailure struct {
+	Code   uint32 `json:"code"`
+	Reason string `json:"reason"`
+}
+
 type Nonce struct {
 	Data [8]byte
 }
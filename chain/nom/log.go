@@ -0,0 +1,28 @@
+package nom
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// Log is emitted by an embedded contract while executing a receive-block, to describe what the call did.
+// The first topic identifies the event, see abi.ABIContract.PackEvent.
+//
+// Logs are not part of the changes of the receive-block, so they don't affect its hash.
+type Log struct {
+	Address types.Address `json:"address"`
+	Topics  []types.Hash  `json:"topics"`
+	Data    []byte        `json:"data"`
+}
+
+func SerializeLogs(logs []*Log) ([]byte, error) {
+	return rlp.EncodeToBytes(logs)
+}
+func DeserializeLogs(data []byte) ([]*Log, error) {
+	logs := make([]*Log, 0)
+	if err := rlp.DecodeBytes(data, &logs); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -0,0 +1,35 @@
+package nom
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// MaxMemoSize is the maximum size in bytes of the data of a block to be read as a memo.
+const MaxMemoSize = 512
+
+// ParseMemo reads data as a memo, the convention for tagging transfers between users, like the deposits to an
+// exchange. A memo is a non-empty UTF-8 text of at most MaxMemoSize bytes made of printable characters and spaces,
+// so it can be displayed as is. Anything else, like an embedded contract call, isn't a memo.
+func ParseMemo(data []byte) (string, bool) {
+	if len(data) == 0 || len(data) > MaxMemoSize || !utf8.Valid(data) {
+		return "", false
+	}
+	for _, r := range string(data) {
+		if r != ' ' && !unicode.IsPrint(r) {
+			return "", false
+		}
+	}
+	return string(data), true
+}
+
+// Memo returns the memo of a user send block to a user address, see ParseMemo.
+// The memo of a receive block is the one of its send block.
+func (ab *AccountBlock) Memo() (string, bool) {
+	if ab.BlockType != BlockTypeUserSend || types.IsEmbeddedAddress(ab.ToAddress) {
+		return "", false
+	}
+	return ParseMemo(ab.Data)
+}
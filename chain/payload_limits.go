@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+// PayloadLimits bounds the size of account-blocks and momentums. The limits are raised by sporks,
+// so all nodes switch to the new limits at the same momentum instead of requiring a hard fork.
+type PayloadLimits struct {
+	// MaxDataLength bounds the data of the blocks returned by IsDataLimited
+	MaxDataLength int
+	// MaxAccountBlocksInMomentum bounds the content of a momentum
+	MaxAccountBlocksInMomentum int
+}
+
+// GetPayloadLimits returns the limits enforced on top of the frontier momentum of momentumStore.
+func GetPayloadLimits(momentumStore store.Momentum) (*PayloadLimits, error) {
+	active, err := momentumStore.IsSporkActive(types.PayloadLimitsSpork)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return &PayloadLimits{
+			MaxDataLength:              constants.MaxDataLengthAfterPayloadSpork,
+			MaxAccountBlocksInMomentum: MaxAccountBlocksInMomentumAfterPayloadSpork,
+		}, nil
+	}
+	return &PayloadLimits{
+		MaxDataLength:              constants.MaxDataLength,
+		MaxAccountBlocksInMomentum: MaxAccountBlocksInMomentum,
+	}, nil
+}
+
+// MaxPayloadLimits returns the largest limits known by the node, regardless of the enforced sporks.
+// It's used to drop oversized payloads before they reach the verifier, which checks the enforced limits.
+func MaxPayloadLimits() *PayloadLimits {
+	limits := &PayloadLimits{
+		MaxDataLength:              constants.MaxDataLengthAfterPayloadSpork,
+		MaxAccountBlocksInMomentum: MaxAccountBlocksInMomentumAfterPayloadSpork,
+	}
+	if limits.MaxDataLength < constants.MaxDataLength {
+		limits.MaxDataLength = constants.MaxDataLength
+	}
+	if limits.MaxAccountBlocksInMomentum < MaxAccountBlocksInMomentum {
+		limits.MaxAccountBlocksInMomentum = MaxAccountBlocksInMomentum
+	}
+	return limits
+}
+
+// IsDataLimited returns true if the data of block is bounded by MaxDataLength.
+// Calls to embedded contracts are bounded by the ABI of the called method instead.
+func IsDataLimited(block *nom.AccountBlock) bool {
+	return block.BlockType == nom.BlockTypeUserSend && !types.IsEmbeddedAddress(block.ToAddress)
+}
+
+func (c *chain) GetNewMomentumContent() []*nom.AccountBlock {
+	limits, err := GetPayloadLimits(c.GetFrontierMomentumStore())
+	common.DealWithErr(err)
+	return c.filterBlocksToCommit(c.GetAllUncommittedAccountBlocks(), limits.MaxAccountBlocksInMomentum)
+}
@@ -0,0 +1,140 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/inconshreveable/log15"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	logsKeyPrefix    = []byte{1}
+	failureKeyPrefix = []byte{2}
+)
+
+func getLogsKey(hash types.Hash) []byte {
+	return common.JoinBytes(logsKeyPrefix, hash.Bytes())
+}
+func getFailureKey(hash types.Hash) []byte {
+	return common.JoinBytes(failureKeyPrefix, hash.Bytes())
+}
+
+// receipt is what the embedded contract which received a block left besides its changes.
+type receipt struct {
+	logs    []*nom.Log
+	failure *nom.ReceiveFailure
+}
+
+// receiptStore keeps the logs emitted by embedded contracts and the failures of their calls, keyed by the hash
+// of the receive-block. They aren't part of the changes of the account-blocks, so they are kept in memory while
+// the block is unconfirmed and written to a separate database once a momentum confirms it.
+type receiptStore struct {
+	log     log15.Logger
+	db      db.DB
+	changes sync.Mutex
+	pending map[types.Hash]*receipt
+}
+
+func newReceiptStore(db db.DB) *receiptStore {
+	return &receiptStore{
+		log:     common.ChainLogger.New("submodule", "receipts"),
+		db:      db,
+		pending: make(map[types.Hash]*receipt),
+	}
+}
+
+func (rs *receiptStore) addPending(transaction *nom.AccountBlockTransaction) {
+	if len(transaction.Logs) == 0 && transaction.Failure == nil {
+		return
+	}
+	rs.changes.Lock()
+	defer rs.changes.Unlock()
+	rs.pending[transaction.Block.Hash] = &receipt{
+		logs:    transaction.Logs,
+		failure: transaction.Failure,
+	}
+}
+
+func (rs *receiptStore) InsertMomentum(detailed *nom.DetailedMomentum) {
+	rs.changes.Lock()
+	defer rs.changes.Unlock()
+
+	for _, block := range detailed.AccountBlocks {
+		pending, ok := rs.pending[block.Hash]
+		if !ok {
+			continue
+		}
+		delete(rs.pending, block.Hash)
+		if len(pending.logs) != 0 {
+			data, err := nom.SerializeLogs(pending.logs)
+			if err == nil {
+				err = rs.db.Put(getLogsKey(block.Hash), data)
+			}
+			if err != nil {
+				rs.log.Error("failed to save logs", "block-hash", block.Hash, "reason", err)
+			}
+		}
+		if pending.failure != nil {
+			data, err := rlp.EncodeToBytes(pending.failure)
+			if err == nil {
+				err = rs.db.Put(getFailureKey(block.Hash), data)
+			}
+			if err != nil {
+				rs.log.Error("failed to save failure", "block-hash", block.Hash, "reason", err)
+			}
+		}
+	}
+}
+func (rs *receiptStore) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	rs.changes.Lock()
+	defer rs.changes.Unlock()
+
+	for _, block := range detailed.AccountBlocks {
+		if err := rs.db.Delete(getLogsKey(block.Hash)); err != nil {
+			rs.log.Error("failed to delete logs", "block-hash", block.Hash, "reason", err)
+		}
+		if err := rs.db.Delete(getFailureKey(block.Hash)); err != nil {
+			rs.log.Error("failed to delete failure", "block-hash", block.Hash, "reason", err)
+		}
+	}
+	// the account-pool drops the uncommitted blocks on rollback, they are applied again afterwards
+	rs.pending = make(map[types.Hash]*receipt)
+}
+
+func (rs *receiptStore) GetAccountBlockLogs(hash types.Hash) ([]*nom.Log, error) {
+	rs.changes.Lock()
+	defer rs.changes.Unlock()
+
+	data, err := rs.db.Get(getLogsKey(hash))
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nom.DeserializeLogs(data)
+}
+
+func (rs *receiptStore) GetAccountBlockFailure(hash types.Hash) (*nom.ReceiveFailure, error) {
+	rs.changes.Lock()
+	defer rs.changes.Unlock()
+
+	data, err := rs.db.Get(getFailureKey(hash))
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	failure := new(nom.ReceiveFailure)
+	if err := rlp.DecodeBytes(data, failure); err != nil {
+		return nil, err
+	}
+	return failure, nil
+}
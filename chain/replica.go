@@ -0,0 +1,112 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+)
+
+// ReplicaChain is a read-only Chain over the databases of another znnd, see db.ReplicaManager.
+// Momentums are only added by Refresh, which notifies the listeners of the new ones like an insert would.
+type ReplicaChain interface {
+	Chain
+	Refresh() error
+}
+
+type replicaChain struct {
+	*chain
+	manager  *db.ReplicaManager
+	receipts *db.ReplicaDB
+}
+
+func NewReplicaChain(manager *db.ReplicaManager, receipts *db.ReplicaDB, genesis store.Genesis, cacheSize int) ReplicaChain {
+	return &replicaChain{
+		chain:    NewChainWithCache(manager, receipts.DB(), genesis, cacheSize),
+		manager:  manager,
+		receipts: receipts,
+	}
+}
+
+func (c *replicaChain) Stop() error {
+	if err := c.chain.Stop(); err != nil {
+		return err
+	}
+	return c.receipts.Close()
+}
+
+func (c *replicaChain) AddAccountBlockTransaction(sync.Locker, *nom.AccountBlockTransaction) error {
+	return db.ErrReadOnlyReplica
+}
+func (c *replicaChain) ForceAddAccountBlockTransaction(sync.Locker, *nom.AccountBlockTransaction) error {
+	return db.ErrReadOnlyReplica
+}
+
+func (c *replicaChain) Refresh() error {
+	insert := c.AcquireInsert("refresh replica")
+	defer insert.Unlock()
+
+	previous, err := c.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return err
+	}
+	// the primary saves the logs once the momentum is inserted, refreshing
+	// the receipts last gets the logs of all the momentums of the copy
+	if err := c.refreshManager(previous); err != nil {
+		return err
+	}
+	if err := c.receipts.Refresh(); err != nil {
+		return err
+	}
+
+	store := c.GetFrontierMomentumStore()
+	frontier, err := store.GetFrontierMomentum()
+	if err != nil {
+		return err
+	}
+	if frontier.Height < previous.Height {
+		c.log.Warn("replica frontier went back, the primary rolled back", "previous", previous.Identifier(), "frontier", frontier.Identifier())
+		return nil
+	}
+	if same, err := store.GetMomentumByHeight(previous.Height); err != nil {
+		return err
+	} else if same.Hash != previous.Hash {
+		c.log.Warn("replica frontier forked, the primary rolled back", "previous", previous.Identifier(), "frontier", frontier.Identifier())
+	}
+
+	for height := previous.Height + 1; height <= frontier.Height; height += 1 {
+		momentum, err := store.GetMomentumByHeight(height)
+		if err != nil {
+			return err
+		}
+		detailed, err := store.PrefetchMomentum(momentum)
+		if err != nil {
+			return err
+		}
+		c.broadcastInsertMomentum(detailed)
+	}
+	return nil
+}
+
+// refreshManager refreshes the copy of the momentums, the cache is purged if the primary rolled back
+// past previous. The stores can't be created meanwhile.
+func (c *replicaChain) refreshManager(previous *nom.Momentum) error {
+	c.momentumPool.changes.Lock()
+	defer c.momentumPool.changes.Unlock()
+	if err := c.manager.Refresh(); err != nil {
+		return err
+	}
+	if c.cache == nil {
+		return nil
+	}
+	same, err := momentum.NewStore(c.genesis, c.manager.Frontier()).GetMomentumByHeight(previous.Height)
+	if err != nil {
+		return err
+	}
+	if same == nil || same.Hash != previous.Hash {
+		c.cache.Purge()
+	}
+	return nil
+}
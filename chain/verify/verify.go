@@ -0,0 +1,155 @@
+// Package verify re-validates the ledger of a stopped node, to find the momentums corrupted on disk.
+package verify
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+// Corruption is the first momentum of a range which failed the verification.
+type Corruption struct {
+	Height uint64
+	Reason string
+}
+
+func (c *Corruption) Error() string {
+	return fmt.Sprintf("momentum at height %v is corrupt: %v", c.Height, c.Reason)
+}
+
+// Momentums verifies the momentums from..to, both included, of the frontier of ch:
+//   - the hash of each momentum and its link to the previous one
+//   - the signature of each momentum, except the genesis
+//   - the hash of the changes applied by each momentum, which are the state of the embedded contracts and accounts
+//   - the hash, signature and link to the previous block of each account-block confirmed by the momentums
+//
+// It returns the first corrupt momentum, nil if all of them are valid. progress, if not nil, is called
+// after each momentum.
+func Momentums(ch chain.Chain, from, to uint64, progress func(height uint64)) (*Corruption, error) {
+	momentumStore := ch.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 || to > frontier.Height {
+		to = frontier.Height
+	}
+	if from > to {
+		return nil, errors.Errorf("invalid range %v to %v, the frontier momentum is at height %v", from, to, frontier.Height)
+	}
+
+	var previous *nom.Momentum
+	if from > 1 {
+		// the link of the first momentum is checked against the previous one, which isn't verified itself
+		if previous, err = momentumStore.GetMomentumByHeight(from - 1); err != nil || previous == nil {
+			return &Corruption{Height: from - 1, Reason: fmt.Sprintf("can't read the momentum, %v", err)}, nil
+		}
+	}
+	for height := from; height <= to; height += 1 {
+		momentum, corruption := verifyMomentum(ch, momentumStore, height, previous)
+		if corruption != nil {
+			return corruption, nil
+		}
+		previous = momentum
+		if progress != nil {
+			progress(height)
+		}
+	}
+	return nil, nil
+}
+
+func verifyMomentum(ch chain.Chain, momentumStore store.Momentum, height uint64, previous *nom.Momentum) (momentum *nom.Momentum, corruption *Corruption) {
+	corrupt := func(format string, args ...interface{}) (*nom.Momentum, *Corruption) {
+		return nil, &Corruption{Height: height, Reason: fmt.Sprintf(format, args...)}
+	}
+	// corrupt entries can fail the decoders with panics, they are reported like the other failures
+	defer func() {
+		if r := recover(); r != nil {
+			momentum, corruption = corrupt("failed to decode, %v", r)
+		}
+	}()
+
+	momentum, err := momentumStore.GetMomentumByHeight(height)
+	switch {
+	case err != nil:
+		return corrupt("can't read the momentum, %v", err)
+	case momentum == nil:
+		return corrupt("momentum is missing")
+	case momentum.Height != height:
+		return corrupt("momentum has height %v", momentum.Height)
+	case momentum.ComputeHash() != momentum.Hash:
+		return corrupt("momentum hash is %v, computed %v", momentum.Hash, momentum.ComputeHash())
+	case previous != nil && momentum.PreviousHash != previous.Hash:
+		return corrupt("previous hash is %v, the momentum at height %v has hash %v", momentum.PreviousHash, previous.Height, previous.Hash)
+	}
+
+	genesis := ch.IsGenesisMomentum(momentum.Hash)
+	if !genesis {
+		if ok, err := wallet.VerifySignature(momentum.PublicKey, momentum.Hash.Bytes(), momentum.Signature); err != nil || !ok {
+			return corrupt("invalid momentum signature")
+		}
+	}
+
+	patch := ch.GetMomentumPatch(momentum.Identifier())
+	if patch == nil {
+		return corrupt("changes are missing")
+	}
+	changes, err := db.StripFrontier(patch)
+	if err != nil {
+		return corrupt("can't read the changes, %v", err)
+	}
+	if changesHash := db.PatchHash(changes); changesHash != momentum.ChangesHash {
+		return corrupt("changes hash is %v, computed %v", momentum.ChangesHash, changesHash)
+	}
+
+	for _, header := range momentum.Content {
+		if reason := verifyAccountBlock(momentumStore, header, genesis); reason != "" {
+			return corrupt("account-block %v of %v at height %v: %v", header.Hash, header.Address, header.Height, reason)
+		}
+	}
+	return momentum, nil
+}
+
+func verifyAccountBlock(momentumStore store.Momentum, header *types.AccountHeader, genesis bool) string {
+	block, err := momentumStore.GetAccountBlock(*header)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("can't read the block, %v", err)
+	case block == nil:
+		return "block is missing"
+	case block.Hash != header.Hash || block.Address != header.Address || block.Height != header.Height:
+		return fmt.Sprintf("stored block is %v of %v at height %v", block.Hash, block.Address, block.Height)
+	case block.ComputeHash() != block.Hash:
+		return fmt.Sprintf("computed hash %v", block.ComputeHash())
+	}
+
+	// the blocks of the embedded contracts and the ones of the genesis aren't signed
+	if !genesis && !types.IsEmbeddedAddress(block.Address) {
+		if ok, err := wallet.VerifySignature(block.PublicKey, block.Hash.Bytes(), block.Signature); err != nil || !ok {
+			return "invalid signature"
+		}
+	}
+
+	if block.Height > 1 {
+		previous, err := momentumStore.GetAccountBlockByHeight(block.Address, block.Height-1)
+		switch {
+		case err != nil:
+			return fmt.Sprintf("can't read the previous block, %v", err)
+		case previous == nil:
+			return "previous block is missing"
+		case previous.Hash != block.PreviousHash:
+			return fmt.Sprintf("previous hash is %v, the block at height %v has hash %v", block.PreviousHash, previous.Height, previous.Hash)
+		}
+	}
+	return ""
+}
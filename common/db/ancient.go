@@ -0,0 +1,231 @@
+package db
+
+import (
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+const (
+	// MinAncientThreshold keeps the momentums which can be rolled back and the ones cached for
+	// historical reads on the fast storage.
+	MinAncientThreshold = maximumCacheHeightDifference
+	// number of momentums moved to the ancient store in one batch
+	freezeBatchSize = 100
+)
+
+var (
+	// ancientMarkerKey is set in the main database once momentums are moved to the ancient store,
+	// it holds the location of the ancient store.
+	ancientMarkerKey = []byte{97}
+	// ancientHeadKey holds the height of the last momentum moved to the ancient store.
+	ancientHeadKey = []byte{104}
+
+	ancientLog = common.ChainLogger.New("submodule", "db-ancient")
+)
+
+// IsEntryByHeightKey reports whether key holds an entry of a versioned DB, see SetFrontier.
+func IsEntryByHeightKey(key []byte) bool {
+	return len(key) == len(entryByHeightPrefix)+8 && key[0] == entryByHeightPrefix[0]
+}
+
+// AncientConfig moves the immutable data of the momentums older than Threshold momentums from the
+// main database to the one at Dir, which can be on slower storage. Reads are served by both.
+type AncientConfig struct {
+	Dir       string
+	Threshold uint64
+	// IsAncient reports whether a key set by a momentum holds data which never changes afterwards,
+	// like the momentum itself. Keys are relative to the frontier.
+	IsAncient func(key []byte) bool
+}
+
+type ancientStore struct {
+	*AncientConfig
+	ldb *leveldb.DB
+	// height of the last momentum in the ancient store
+	head uint64
+}
+
+func openAncientStore(config *AncientConfig, main *leveldb.DB) (*ancientStore, error) {
+	if config.Threshold < 1 {
+		return nil, errors.Errorf("ancient threshold must be strictly greater than zero")
+	}
+	opts := &opt.Options{OpenFilesCacheCapacity: getOpenFilesCacheCapacity()}
+	ldb, err := leveldb.OpenFile(config.Dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	a := &ancientStore{
+		AncientConfig: config,
+		ldb:           ldb,
+	}
+	if data, err := ldb.Get(ancientHeadKey, nil); err == nil {
+		a.head = common.BytesToUint64(data)
+	} else if err != leveldb.ErrNotFound {
+		_ = ldb.Close()
+		return nil, err
+	}
+
+	if location, err := main.Get(ancientMarkerKey, nil); err == nil && a.head == 0 {
+		_ = ldb.Close()
+		return nil, errors.Errorf("ancient store at %v is empty, but the database keeps its old momentums in the one at %v", config.Dir, string(location))
+	} else if err != nil && err != leveldb.ErrNotFound {
+		_ = ldb.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// checkNoAncientStore fails for databases which keep their old momentums in an ancient store.
+func checkNoAncientStore(main *leveldb.DB) error {
+	location, err := main.Get(ancientMarkerKey, nil)
+	if err == leveldb.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Errorf("the database keeps its old momentums in the ancient store at %v, which must be configured", string(location))
+}
+
+// get returns the raw value of key, with a fallback on the ancient store if it isn't in main.
+func (a *ancientStore) get(main LevelDBLikeRO, key []byte) ([]byte, error) {
+	value, err := main.Get(key, nil)
+	if err == leveldb.ErrNotFound && a != nil {
+		return a.ldb.Get(key, nil)
+	}
+	return value, err
+}
+
+// reader serves the reads of main with a fallback on the ancient store.
+func (a *ancientStore) reader(main LevelDBLikeRO) db {
+	if a == nil {
+		return &levelDBROWrapper{db: main}
+	}
+	return newMergedDb([]db{
+		&levelDBROWrapper{db: main},
+		&levelDBROWrapper{db: a.ldb},
+	})
+}
+
+// freezeLoop moves the data of old momentums to the ancient store after each momentum insert.
+func (m *ldbManager) freezeLoop() {
+	defer m.freezeWg.Done()
+	for {
+		select {
+		case <-m.closed:
+			return
+		case <-m.inserted:
+		}
+		for {
+			select {
+			case <-m.closed:
+				return
+			default:
+			}
+			done, err := m.freeze()
+			if err != nil {
+				ancientLog.Error("failed to move momentums to the ancient store", "reason", err)
+				break
+			}
+			if done {
+				break
+			}
+		}
+	}
+}
+
+// freeze moves a batch of momentums older than the threshold to the ancient store, done is true once there are none left.
+// The data is written to the ancient store before being deleted from the main database, so it can always be read from either.
+func (m *ldbManager) freeze() (bool, error) {
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	if m.stopped {
+		return true, nil
+	}
+
+	a := m.ancient
+	frontier := GetFrontierIdentifier(NewLevelDBWrapper(m.ldb).Subset(frontierByte))
+	if frontier.Height <= a.Threshold || a.head >= frontier.Height-a.Threshold {
+		return true, nil
+	}
+	to := frontier.Height - a.Threshold
+	if to > a.head+freezeBatchSize {
+		to = a.head + freezeBatchSize
+	}
+
+	ancientBatch := new(leveldb.Batch)
+	mainBatch := new(leveldb.Batch)
+	for height := a.head + 1; height <= to; height += 1 {
+		if err := m.freezeMomentum(height, ancientBatch, mainBatch); err != nil {
+			return false, err
+		}
+	}
+	ancientBatch.Put(ancientHeadKey, common.Uint64ToBytes(to))
+	if err := a.ldb.Write(ancientBatch, syncWrite); err != nil {
+		return false, err
+	}
+	mainBatch.Put(ancientMarkerKey, []byte(a.Dir))
+	if err := m.ldb.Write(mainBatch, syncWrite); err != nil {
+		return false, err
+	}
+	a.head = to
+	ancientLog.Debug("moved momentums to the ancient store", "head", to)
+	return to == frontier.Height-a.Threshold, nil
+}
+
+// ancientCollector gathers the ancient keys set by a patch.
+type ancientCollector struct {
+	isAncient func(key []byte) bool
+	keys      [][]byte
+}
+
+func (c *ancientCollector) Put(key []byte, value []byte) {
+	if c.isAncient(key) {
+		c.keys = append(c.keys, common.JoinBytes(frontierByte, key))
+	}
+}
+func (c *ancientCollector) Delete([]byte) {
+}
+
+func (m *ldbManager) freezeMomentum(height uint64, ancientBatch, mainBatch *leveldb.Batch) error {
+	rawPatch, err := m.ldb.Get(getPatchKey(height), nil)
+	if err != nil {
+		return errors.Errorf("can't find patch for height %v. Reason: %v", height, err)
+	}
+	rawRollback, err := m.ldb.Get(getRollbackKey(height), nil)
+	if err != nil {
+		return errors.Errorf("can't find rollback patch for height %v. Reason: %v", height, err)
+	}
+	patch, err := NewPatchFromDump(rawPatch)
+	if err != nil {
+		return err
+	}
+
+	collector := &ancientCollector{isAncient: m.ancient.IsAncient}
+	if err := patch.Replay(collector); err != nil {
+		return err
+	}
+	for _, key := range collector.keys {
+		value, err := m.ldb.Get(key, nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		// deleted keys stay in the main database so they keep hiding older values
+		if len(value) == 0 {
+			continue
+		}
+		ancientBatch.Put(key, value)
+		mainBatch.Delete(key)
+	}
+
+	ancientBatch.Put(getPatchKey(height), rawPatch)
+	ancientBatch.Put(getRollbackKey(height), rawRollback)
+	mainBatch.Delete(getPatchKey(height))
+	mainBatch.Delete(getRollbackKey(height))
+	return nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Checkpoint copies the LevelDB at src, which must be closed, to dst, which must not exist.
+// The table files are never modified by LevelDB, they are hard-linked when src and dst are on
+// the same file system. The others, like the manifest and the journal, are appended to and copied.
+func Checkpoint(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(src, "CURRENT")); err != nil {
+		return errors.Errorf("%v is not a LevelDB", src)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return errors.Errorf("%v already exists", dst)
+	}
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == "LOCK" {
+			continue
+		}
+		from, to := filepath.Join(src, name), filepath.Join(dst, name)
+		if strings.HasSuffix(name, ".ldb") || strings.HasSuffix(name, ".sst") {
+			if err := os.Link(from, to); err == nil {
+				continue
+			}
+		}
+		if err := copyFile(from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+
+	ldb, err := leveldb.OpenFile(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i += 1 {
+		if err := ldb.Put([]byte(fmt.Sprintf("key-%v", i)), []byte(fmt.Sprintf("value-%v", i)), nil); err != nil {
+			t.Fatal(err)
+		}
+		// flush some of the keys to table files, the rest stays in the journal
+		if i == 500 {
+			if err := ldb.CompactRange(util.Range{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := ldb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Checkpoint(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := Checkpoint(src, dst); err == nil {
+		t.Fatal("expected an error for an existing destination")
+	}
+	if err := Checkpoint(dir, filepath.Join(dir, "other")); err == nil {
+		t.Fatal("expected an error for a directory which isn't a LevelDB")
+	}
+
+	// writes to the source after the checkpoint must not be visible in the copy
+	ldb, err = leveldb.OpenFile(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ldb.Put([]byte("key-new"), []byte("value-new"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ldb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, err := leveldb.OpenFile(dst, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checkpoint.Close()
+	for i := 0; i < 1000; i += 1 {
+		value, err := checkpoint.Get([]byte(fmt.Sprintf("key-%v", i)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(value) != fmt.Sprintf("value-%v", i) {
+			t.Fatalf("unexpected value %s for key-%v", value, i)
+		}
+	}
+	if _, err := checkpoint.Get([]byte("key-new"), nil); err != leveldb.ErrNotFound {
+		t.Fatalf("expected the key written after the checkpoint to be missing, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Compacter is implemented by the Managers which can compact their databases while open.
+type Compacter interface {
+	Compact() error
+}
+
+// CompactLevelDB compacts the whole key range of ldb, merging its levels and dropping the deleted
+// and overwritten values. Reads and writes go on meanwhile, slowed down by the disk usage.
+func CompactLevelDB(ldb *leveldb.DB) error {
+	return ldb.CompactRange(util.Range{})
+}
+
+// CompactDir compacts the LevelDB in dir, which must not be open.
+func CompactDir(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "CURRENT")); err != nil {
+		return errors.Errorf("%v is not a LevelDB", dir)
+	}
+	ldb, err := leveldb.OpenFile(dir, &opt.Options{OpenFilesCacheCapacity: getOpenFilesCacheCapacity(), ErrorIfMissing: true})
+	if err != nil {
+		return err
+	}
+	if err := CompactLevelDB(ldb); err != nil {
+		ldb.Close()
+		return err
+	}
+	return ldb.Close()
+}
+
+// Compact compacts the database of the manager and its ancient store, if any.
+func (m *ldbManager) Compact() error {
+	m.changes.Lock()
+	if m.stopped {
+		m.changes.Unlock()
+		return errors.Errorf("the database is closed")
+	}
+	ldb := m.ldb
+	var ancient *leveldb.DB
+	if m.ancient != nil {
+		ancient = m.ancient.ldb
+	}
+	m.changes.Unlock()
+
+	if err := CompactLevelDB(ldb); err != nil {
+		return err
+	}
+	if ancient != nil {
+		return CompactLevelDB(ancient)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestCompactDir(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+
+	ldb, err := leveldb.OpenFile(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i += 1 {
+		if err := ldb.Put([]byte(fmt.Sprintf("key-%v", i)), []byte(fmt.Sprintf("value-%v", i)), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 1000; i += 2 {
+		if err := ldb.Delete([]byte(fmt.Sprintf("key-%v", i)), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ldb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CompactDir(src); err != nil {
+		t.Fatal(err)
+	}
+	if err := CompactDir(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected an error for a directory which isn't a LevelDB")
+	}
+
+	ldb, err = leveldb.OpenFile(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ldb.Close()
+	for i := 0; i < 1000; i += 1 {
+		value, err := ldb.Get([]byte(fmt.Sprintf("key-%v", i)), nil)
+		if i%2 == 0 {
+			if err != leveldb.ErrNotFound {
+				t.Fatalf("key-%v was deleted, got %v %v", i, string(value), err)
+			}
+		} else if err != nil || string(value) != fmt.Sprintf("value-%v", i) {
+			t.Fatalf("key-%v has value %v %v", i, string(value), err)
+		}
+	}
+	// the compaction moved the flushed journal out of level 0
+	files, err := ldb.GetProperty("leveldb.num-files-at-level0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != "0" {
+		t.Fatalf("level 0 has %v files after the compaction", files)
+	}
+}
+
+func TestLevelDBManager_Compact(t *testing.T) {
+	m, err := openLevelDBManager(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Compact(); err == nil {
+		t.Fatal("expected an error for a stopped manager")
+	}
+}
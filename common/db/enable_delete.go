@@ -60,6 +60,9 @@ func (d *enableDeleteDB) Delete(key []byte) error {
 func (d *enableDeleteDB) NewIterator(prefix []byte) StorageIterator {
 	return newEnableDeleteIterator(d.db.NewIterator(prefix))
 }
+func (d *enableDeleteDB) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	return newEnableDeleteIterator(d.db.NewIteratorFrom(prefix, start))
+}
 
 func (d *enableDeleteDB) Changes() (Patch, error) {
 	p, err := d.db.changesInternal([]byte{})
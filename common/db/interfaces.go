@@ -42,6 +42,8 @@ type DB interface {
 	Delete(key []byte) error
 
 	NewIterator(prefix []byte) StorageIterator
+	// NewIteratorFrom is like NewIterator but skips the keys before start, without reading them.
+	NewIteratorFrom(prefix, start []byte) StorageIterator
 	Subset(prefix []byte) DB
 
 	Apply(Patch) error
@@ -55,6 +57,7 @@ type db interface {
 	Put(key, value []byte) error
 
 	NewIterator(prefix []byte) StorageIterator
+	NewIteratorFrom(prefix, start []byte) StorageIterator
 
 	changesInternal(prefix []byte) (Patch, error)
 }
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"runtime"
 
 	"github.com/syndtr/goleveldb/leveldb"
@@ -47,6 +48,9 @@ func (ro *levelDBROWrapper) changesInternal(prefix []byte) (Patch, error) {
 func (ro *levelDBROWrapper) NewIterator(prefix []byte) StorageIterator {
 	return ro.db.NewIterator(util.BytesPrefix(prefix), nil)
 }
+func (ro *levelDBROWrapper) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	return ro.db.NewIterator(rangeFrom(prefix, start), nil)
+}
 
 type LevelDBLike interface {
 	LevelDBLikeRO
@@ -69,6 +73,9 @@ func (ldbw *levelDBWrapper) Put(key, value []byte) error {
 func (ldbw *levelDBWrapper) NewIterator(prefix []byte) StorageIterator {
 	return ldbw.db.NewIterator(util.BytesPrefix(prefix), nil)
 }
+func (ldbw *levelDBWrapper) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	return ldbw.db.NewIterator(rangeFrom(prefix, start), nil)
+}
 
 func (ldbw *levelDBWrapper) changesInternal(prefix []byte) (Patch, error) {
 	panic("unimplemented")
@@ -105,3 +112,12 @@ func NewLevelDB(dirname string) (DB, *leveldb.DB) {
 	common.DealWithErr(err)
 	return NewLevelDBWrapper(db), db
 }
+
+// rangeFrom returns the range of the keys with prefix which aren't before start.
+func rangeFrom(prefix, start []byte) *util.Range {
+	r := util.BytesPrefix(prefix)
+	if bytes.Compare(start, r.Start) > 0 {
+		r.Start = start
+	}
+	return r
+}
@@ -16,6 +16,9 @@ func (mdbw *memDBWrapper) Has(key []byte) (bool, error) {
 func (mdbw *memDBWrapper) NewIterator(prefix []byte) StorageIterator {
 	return mdbw.DB.NewIterator(util.BytesPrefix(prefix))
 }
+func (mdbw *memDBWrapper) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	return mdbw.DB.NewIterator(rangeFrom(prefix, start))
+}
 func (mdbw *memDBWrapper) changesInternal(prefix []byte) (Patch, error) {
 	p := NewPatch()
 	iterator := mdbw.NewIterator(prefix)
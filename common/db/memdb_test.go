@@ -1,6 +1,8 @@
 package db
 
 import (
+	"encoding/hex"
+	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
@@ -129,3 +131,43 @@ func stressTestConcurrentUse(t *testing.T, db DB, numInserts int, numThreads int
 
 	wg.Wait()
 }
+
+func debugIteratorFrom(db DB, prefix, start []byte) string {
+	iterator := db.NewIteratorFrom(prefix, start)
+	defer iterator.Release()
+
+	s := ""
+	for iterator.Next() {
+		if iterator.Value() == nil {
+			continue
+		}
+		s = s + fmt.Sprintf("%v ", hex.EncodeToString(iterator.Key()))
+	}
+	common.DealWithErr(iterator.Error())
+	return s
+}
+
+// Test NewIteratorFrom
+//   - test the keys before start are skipped and the keys after the prefix aren't read
+//   - test a start before the prefix iterates the whole prefix and one after it nothing
+//   - test the subsets, the merged and the deleted keys
+func TestIteratorFrom(t *testing.T) {
+	db := NewMemDB()
+	for _, key := range [][]byte{{0, 1}, {1, 1}, {1, 2}, {1, 2, 0}, {1, 3}, {2, 1}} {
+		common.FailIfErr(t, db.Put(key, []byte{1}))
+	}
+
+	common.ExpectString(t, debugIteratorFrom(db, []byte{1}, []byte{1, 2}), `0102 010200 0103 `)
+	common.ExpectString(t, debugIteratorFrom(db, []byte{1}, []byte{1, 2, 0, 0}), `0103 `)
+	common.ExpectString(t, debugIteratorFrom(db, []byte{1}, []byte{0, 5}), `0101 0102 010200 0103 `)
+	common.ExpectString(t, debugIteratorFrom(db, []byte{1}, []byte{2}), ``)
+	common.ExpectString(t, debugIteratorFrom(db, []byte{1}, nil), `0101 0102 010200 0103 `)
+	common.ExpectString(t, debugIteratorFrom(db.Subset([]byte{1}), []byte{2}, []byte{2, 0}), `0200 `)
+
+	common.FailIfErr(t, db.Delete([]byte{1, 2, 0}))
+	snapshot := db.Snapshot()
+	common.FailIfErr(t, snapshot.Put([]byte{1, 2, 5}, []byte{1}))
+	common.FailIfErr(t, snapshot.Delete([]byte{1, 3}))
+	common.ExpectString(t, debugIteratorFrom(snapshot, []byte{1}, []byte{1, 2, 0}), `010205 `)
+	common.ExpectString(t, debugIteratorFrom(db, []byte{1}, []byte{1, 2, 0}), `0103 `)
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 
@@ -80,6 +81,18 @@ func (pa *patchApplier) Delete(key []byte) {
 	pa.err = pa.db.Delete(key)
 }
 
+// patchCollector keeps the final value of each key, nil for deleted keys.
+type patchCollector struct {
+	values map[string][]byte
+}
+
+func (pc *patchCollector) Put(key []byte, value []byte) {
+	pc.values[string(key)] = append([]byte{}, value...)
+}
+func (pc *patchCollector) Delete(key []byte) {
+	pc.values[string(key)] = nil
+}
+
 type patchValuePrefixer struct {
 	prefix []byte
 	Patch
@@ -195,6 +208,31 @@ func ApplyWithoutOverride(db db, patch Patch) error {
 	}
 	return pa.err
 }
+
+// IsPatchApplied checks that the db contains the final value of every key written by the patch.
+func IsPatchApplied(db DB, patch Patch) (bool, error) {
+	pc := &patchCollector{
+		values: make(map[string][]byte),
+	}
+	if err := patch.Replay(pc); err != nil {
+		return false, err
+	}
+	for key, value := range pc.values {
+		stored, err := db.Get([]byte(key))
+		if err == leveldb.ErrNotFound {
+			if value != nil {
+				return false, nil
+			}
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if value == nil || !bytes.Equal(stored, value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 func RollbackPatch(db DB, patch Patch) Patch {
 	pr := &patchRollback{
 		db: db,
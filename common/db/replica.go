@@ -0,0 +1,261 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// replicaAttempts bounds the copies of a refresh, a copy is inconsistent if the source
+	// compacted its files while they were copied
+	replicaAttempts = 5
+)
+
+var (
+	ErrReadOnlyReplica = errors.New("the database is a read-only replica")
+)
+
+// replicaSnapshots copies a LevelDB written by another process to new directories of dir. The source can't be
+// opened while the other process holds its lock, but its table files are never modified, so a copy made like
+// Checkpoint is a consistent state of the source unless the source was compacted in between. LevelDB refuses
+// to open such a copy since its manifest refers to missing table files.
+type replicaSnapshots struct {
+	source     string
+	dir        string
+	generation int
+}
+
+// next copies the source to a new directory and opens it with open, it returns the directory of the copy.
+func (r *replicaSnapshots) next(open func(dir string) error) (string, error) {
+	var err error
+	for attempt := 0; attempt < replicaAttempts; attempt += 1 {
+		r.generation += 1
+		dir := filepath.Join(r.dir, fmt.Sprintf("%v-%v", filepath.Base(r.source), r.generation))
+		if err = os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+		if err = Checkpoint(r.source, dir); err == nil {
+			if err = open(dir); err == nil {
+				return dir, nil
+			}
+		}
+		os.RemoveAll(dir)
+	}
+	return "", errors.Errorf("failed to copy %v. Reason: %v", r.source, err)
+}
+
+// ReplicaManager is a read-only Manager of the momentums written to source by another znnd.
+// It serves a copy of the source, which Refresh replaces with a newer one.
+// The previous copy stays open until the next Refresh, for the readers which still use it.
+type ReplicaManager struct {
+	snapshots replicaSnapshots
+
+	lock        sync.RWMutex
+	current     *ldbManager
+	currentDir  string
+	previous    *ldbManager
+	previousDir string
+	stopped     bool
+}
+
+// NewReplicaManager copies source to dir, which holds the copies of the replica.
+func NewReplicaManager(source, dir string) (*ReplicaManager, error) {
+	r := &ReplicaManager{snapshots: replicaSnapshots{source: source, dir: dir}}
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Refresh replaces the copy with the current state of the source.
+func (r *ReplicaManager) Refresh() error {
+	var next *ldbManager
+	dir, err := r.snapshots.next(func(dir string) error {
+		m, err := openLevelDBManager(dir, nil)
+		if err != nil {
+			return err
+		}
+		next = m
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.stopped {
+		next.Stop()
+		return os.RemoveAll(dir)
+	}
+	if err := r.closePrevious(); err != nil {
+		return err
+	}
+	r.previous, r.previousDir = r.current, r.currentDir
+	r.current, r.currentDir = next, dir
+	return nil
+}
+func (r *ReplicaManager) closePrevious() error {
+	if r.previous == nil {
+		return nil
+	}
+	if err := r.previous.Stop(); err != nil {
+		return err
+	}
+	r.previous = nil
+	return os.RemoveAll(r.previousDir)
+}
+
+func (r *ReplicaManager) Frontier() DB {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.current.Frontier()
+}
+func (r *ReplicaManager) Get(identifier types.HashHeight) DB {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.current.Get(identifier)
+}
+func (r *ReplicaManager) GetPatch(identifier types.HashHeight) Patch {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.current.GetPatch(identifier)
+}
+func (r *ReplicaManager) Add(Transaction) error {
+	return ErrReadOnlyReplica
+}
+func (r *ReplicaManager) Pop() error {
+	return ErrReadOnlyReplica
+}
+func (r *ReplicaManager) Stop() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stopped = true
+	if err := r.closePrevious(); err != nil {
+		return err
+	}
+	if err := r.current.Stop(); err != nil {
+		return err
+	}
+	return os.RemoveAll(r.currentDir)
+}
+func (r *ReplicaManager) Location() string {
+	return r.snapshots.source
+}
+
+// ReplicaDB is a read-only copy of a LevelDB written by another znnd, like ReplicaManager.
+type ReplicaDB struct {
+	snapshots replicaSnapshots
+
+	lock        sync.RWMutex
+	current     *leveldb.DB
+	currentDir  string
+	previous    *leveldb.DB
+	previousDir string
+	stopped     bool
+}
+
+// NewReplicaDB copies source to dir, which holds the copies of the replica.
+func NewReplicaDB(source, dir string) (*ReplicaDB, error) {
+	r := &ReplicaDB{snapshots: replicaSnapshots{source: source, dir: dir}}
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DB returns the replica as a DB whose reads go to the copy of the last Refresh.
+func (r *ReplicaDB) DB() DB {
+	return enableDelete(&replicaDBWrapper{replica: r})
+}
+
+// Refresh replaces the copy with the current state of the source.
+func (r *ReplicaDB) Refresh() error {
+	var next *leveldb.DB
+	dir, err := r.snapshots.next(func(dir string) error {
+		ldb, err := leveldb.OpenFile(dir, &opt.Options{OpenFilesCacheCapacity: getConsensusOpenFilesCacheCapacity()})
+		if err != nil {
+			return err
+		}
+		next = ldb
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.stopped {
+		next.Close()
+		return os.RemoveAll(dir)
+	}
+	if err := r.closePrevious(); err != nil {
+		return err
+	}
+	r.previous, r.previousDir = r.current, r.currentDir
+	r.current, r.currentDir = next, dir
+	return nil
+}
+func (r *ReplicaDB) closePrevious() error {
+	if r.previous == nil {
+		return nil
+	}
+	if err := r.previous.Close(); err != nil {
+		return err
+	}
+	r.previous = nil
+	return os.RemoveAll(r.previousDir)
+}
+
+func (r *ReplicaDB) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stopped = true
+	if err := r.closePrevious(); err != nil {
+		return err
+	}
+	if err := r.current.Close(); err != nil {
+		return err
+	}
+	return os.RemoveAll(r.currentDir)
+}
+
+type replicaDBWrapper struct {
+	replica *ReplicaDB
+}
+
+func (w *replicaDBWrapper) Get(key []byte) ([]byte, error) {
+	w.replica.lock.RLock()
+	defer w.replica.lock.RUnlock()
+	return w.replica.current.Get(key, nil)
+}
+func (w *replicaDBWrapper) Has(key []byte) (bool, error) {
+	w.replica.lock.RLock()
+	defer w.replica.lock.RUnlock()
+	return w.replica.current.Has(key, nil)
+}
+func (w *replicaDBWrapper) Put(key, value []byte) error {
+	return ErrReadOnlyReplica
+}
+func (w *replicaDBWrapper) NewIterator(prefix []byte) StorageIterator {
+	w.replica.lock.RLock()
+	defer w.replica.lock.RUnlock()
+	return (&levelDBROWrapper{db: w.replica.current}).NewIterator(prefix)
+}
+func (w *replicaDBWrapper) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	w.replica.lock.RLock()
+	defer w.replica.lock.RUnlock()
+	return (&levelDBROWrapper{db: w.replica.current}).NewIteratorFrom(prefix, start)
+}
+func (w *replicaDBWrapper) changesInternal(prefix []byte) (Patch, error) {
+	return nil, ErrReadOnlyReplica
+}
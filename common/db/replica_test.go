@@ -0,0 +1,72 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+func TestReplicaManager(t *testing.T) {
+	dir := t.TempDir()
+	source, replicaDir := filepath.Join(dir, "nom"), filepath.Join(dir, "replica")
+
+	// the primary stays open, the replica can't lock its files
+	primary := NewLevelDBManager(source)
+	defer primary.Stop()
+	common.DealWithErr(primary.Add(newMockTransaction(1, primary.Frontier())))
+	f1 := GetFrontierIdentifier(primary.Frontier())
+
+	replica, err := NewReplicaManager(source, replicaDir)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(replica.Frontier()) == f1), `true`)
+
+	common.DealWithErr(primary.Add(newMockTransaction(2, primary.Frontier())))
+	common.DealWithErr(primary.Add(newMockTransaction(3, primary.Frontier())))
+	f3 := GetFrontierIdentifier(primary.Frontier())
+	old := replica.Frontier()
+
+	// the replica serves the previous copy until it's refreshed
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(replica.Frontier()) == f1), `true`)
+	common.FailIfErr(t, replica.Refresh())
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(replica.Frontier()) == f3), `true`)
+	common.ExpectString(t, DebugDB(replica.Frontier()), DebugDB(primary.Frontier()))
+	common.ExpectString(t, fmt.Sprintf("%v", replica.GetPatch(f3) != nil), `true`)
+
+	// readers of the previous copy still work after a refresh
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(old) == f1), `true`)
+
+	common.ExpectString(t, fmt.Sprintf("%v", replica.Add(newMockTransaction(4, replica.Frontier()))), ErrReadOnlyReplica.Error())
+	common.ExpectString(t, fmt.Sprintf("%v", replica.Pop()), ErrReadOnlyReplica.Error())
+
+	common.FailIfErr(t, replica.Stop())
+	entries, err := os.ReadDir(replicaDir)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, fmt.Sprintf("%v", len(entries)), `0`)
+}
+
+func TestReplicaDB(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "receipts")
+
+	db, ldb := NewLevelDB(source)
+	defer ldb.Close()
+	common.FailIfErr(t, db.Put([]byte("key-1"), []byte("value-1")))
+
+	replica, err := NewReplicaDB(source, filepath.Join(dir, "replica"))
+	common.FailIfErr(t, err)
+	replicaDB := replica.DB()
+	common.FailIfErr(t, db.Put([]byte("key-2"), []byte("value-2")))
+
+	_, err = replicaDB.Get([]byte("key-2"))
+	common.ExpectString(t, fmt.Sprintf("%v", err), `leveldb: not found`)
+	common.FailIfErr(t, replica.Refresh())
+	value, err := replicaDB.Get([]byte("key-2"))
+	common.FailIfErr(t, err)
+	common.ExpectString(t, string(value), `value-2`)
+	common.ExpectString(t, fmt.Sprintf("%v", replicaDB.Put([]byte("key-3"), nil)), ErrReadOnlyReplica.Error())
+
+	common.FailIfErr(t, replica.Close())
+}
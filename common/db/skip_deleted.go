@@ -7,6 +7,9 @@ type skipDeletedDb struct {
 func (db *skipDeletedDb) NewIterator(prefix []byte) StorageIterator {
 	return newSkipDeletedIterator(db.db.NewIterator(prefix))
 }
+func (db *skipDeletedDb) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	return newSkipDeletedIterator(db.db.NewIteratorFrom(prefix, start))
+}
 
 type skipDeletedIterator struct {
 	StorageIterator
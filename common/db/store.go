@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bytes"
+
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/zenon-network/go-zenon/common"
@@ -30,6 +32,34 @@ func SetFrontier(db DB, version types.HashHeight, data []byte) error {
 	return nil
 }
 
+// StripFrontier returns the changes of a patch returned by Manager.GetPatch without the entries SetFrontier
+// adds to them when the commit is added, their hash is the changes hash of the commit.
+func StripFrontier(patch Patch) (Patch, error) {
+	stripped := &frontierFilter{patch: NewPatch()}
+	if err := patch.Replay(stripped); err != nil {
+		return nil, err
+	}
+	return stripped.patch, nil
+}
+
+type frontierFilter struct {
+	patch Patch
+}
+
+func (f *frontierFilter) Put(key []byte, value []byte) {
+	if !isFrontierKey(key) {
+		f.patch.Put(key, value)
+	}
+}
+func (f *frontierFilter) Delete(key []byte) {
+	if !isFrontierKey(key) {
+		f.patch.Delete(key)
+	}
+}
+func isFrontierKey(key []byte) bool {
+	return bytes.Equal(key, frontierIdentifierKey) || bytes.HasPrefix(key, heightByHashPrefix) || bytes.HasPrefix(key, entryByHeightPrefix)
+}
+
 func GetFrontierIdentifier(db DB) types.HashHeight {
 	data, err := db.Get(getFrontierIdentifierKey())
 	if err == leveldb.ErrNotFound {
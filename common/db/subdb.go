@@ -37,6 +37,9 @@ func (u *subDB) Put(key, value []byte) error {
 func (u *subDB) NewIterator(prefix []byte) StorageIterator {
 	return newSubIterator(len(u.prefix), u.db.NewIterator(common.JoinBytes(u.prefix, prefix)))
 }
+func (u *subDB) NewIteratorFrom(prefix, start []byte) StorageIterator {
+	return newSubIterator(len(u.prefix), u.db.NewIteratorFrom(common.JoinBytes(u.prefix, prefix), common.JoinBytes(u.prefix, start)))
+}
 
 func (u *subDB) changesInternal(prefix []byte) (Patch, error) {
 	changes, err := u.db.changesInternal(common.JoinBytes(u.prefix, prefix))
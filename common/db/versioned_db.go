@@ -23,8 +23,20 @@ var (
 	frontierByte = []byte{85}
 	patchByte    = []byte{102}
 	rollbackByte = []byte{119}
+
+	// syncWrite flushes the journal of leveldb to disk, so committed momentums survive a power loss.
+	syncWrite = &opt.WriteOptions{Sync: true}
+
+	recoverLog = common.ChainLogger.New("submodule", "db-recover")
 )
 
+func getPatchKey(height uint64) []byte {
+	return common.JoinBytes(patchByte, common.Uint64ToBytes(height))
+}
+func getRollbackKey(height uint64) []byte {
+	return common.JoinBytes(rollbackByte, common.Uint64ToBytes(height))
+}
+
 func absDiff(x, y uint64) uint64 {
 	if x < y {
 		return y - x
@@ -184,6 +196,17 @@ type rollbackCache struct {
 	raw      db
 }
 
+// batchWriter collects the writes of a levelDBWrapper in a batch, reads are served by the DB.
+type batchWriter struct {
+	*leveldb.DB
+	batch *leveldb.Batch
+}
+
+func (w *batchWriter) Put(key, value []byte, _ *opt.WriteOptions) error {
+	w.batch.Put(key, value)
+	return nil
+}
+
 type ldbManager struct {
 	location string
 	l1Cache  *lru.Cache
@@ -191,22 +214,72 @@ type ldbManager struct {
 	ldb      *leveldb.DB
 	changes  sync.Mutex
 	stopped  bool
+
+	// ancient is nil if the old momentums are kept in ldb
+	ancient  *ancientStore
+	inserted chan struct{}
+	closed   chan struct{}
+	freezeWg sync.WaitGroup
 }
 
 func NewLevelDBManager(dir string) Manager {
+	return NewLevelDBManagerWithAncient(dir, nil)
+}
+
+// NewLevelDBManagerWithAncient moves the old momentums to the ancient store described by ancient, if not nil.
+func NewLevelDBManagerWithAncient(dir string, ancient *AncientConfig) Manager {
+	m, err := openLevelDBManager(dir, ancient)
+	common.DealWithErr(err)
+	return m
+}
+
+func openLevelDBManager(dir string, ancient *AncientConfig) (*ldbManager, error) {
 	opts := &opt.Options{OpenFilesCacheCapacity: getOpenFilesCacheCapacity()}
 	ldb, err := leveldb.OpenFile(dir, opts)
-	common.DealWithErr(err)
+	if err != nil {
+		return nil, err
+	}
 	l1Cache, err := lru.New(l1CacheSize)
 	common.DealWithErr(err)
 	l2Cache, err := lru.New(l2CacheSize)
 	common.DealWithErr(err)
-	return &ldbManager{
+	m := &ldbManager{
 		location: dir,
 		l1Cache:  l1Cache,
 		l2Cache:  l2Cache,
 		ldb:      ldb,
 	}
+	if ancient != nil {
+		m.ancient, err = openAncientStore(ancient, ldb)
+	} else {
+		err = checkNoAncientStore(ldb)
+	}
+	if err == nil {
+		err = m.recover()
+	}
+	if err != nil {
+		if m.ancient != nil {
+			m.ancient.ldb.Close()
+		}
+		ldb.Close()
+		return nil, err
+	}
+
+	if m.ancient != nil {
+		m.inserted = make(chan struct{}, 1)
+		m.closed = make(chan struct{})
+		m.freezeWg.Add(1)
+		go m.freezeLoop()
+		m.notifyInserted()
+	}
+	return m, nil
+}
+
+func (m *ldbManager) notifyInserted() {
+	select {
+	case m.inserted <- struct{}{}:
+	default:
+	}
 }
 
 func (m *ldbManager) Frontier() DB {
@@ -216,7 +289,15 @@ func (m *ldbManager) Frontier() DB {
 		return nil
 	}
 	snapshot, _ := m.ldb.GetSnapshot()
-	return NewLevelDBSnapshotWrapper(snapshot).Subset(frontierByte)
+	return m.snapshotWrapper(snapshot).Subset(frontierByte)
+}
+
+// snapshotWrapper is like NewLevelDBSnapshotWrapper, with a fallback on the ancient store.
+func (m *ldbManager) snapshotWrapper(snapshot *leveldb.Snapshot) DB {
+	return enableDelete(newMergedDb([]db{
+		newMemDBInternal(),
+		m.ancient.reader(snapshot),
+	}))
 }
 func (m *ldbManager) Get(identifier types.HashHeight) DB {
 	m.changes.Lock()
@@ -226,7 +307,7 @@ func (m *ldbManager) Get(identifier types.HashHeight) DB {
 	}
 	snapshot, _ := m.ldb.GetSnapshot()
 	// check if has snapshot
-	frontier := NewLevelDBSnapshotWrapper(snapshot).Subset(frontierByte)
+	frontier := m.snapshotWrapper(snapshot).Subset(frontierByte)
 	frontierIdentifier := GetFrontierIdentifier(frontier)
 
 	if identifier.IsZero() {
@@ -283,7 +364,7 @@ func (m *ldbManager) Get(identifier types.HashHeight) DB {
 		newSkipDelete(
 			newMergedDb([]db{
 				rawChanges,
-				newSubDB(frontierByte, newLevelDBSnapshotWrapper(snapshot)),
+				newSubDB(frontierByte, newMergedDb([]db{newMemDBInternal(), m.ancient.reader(snapshot)})),
 			})),
 	})
 	return enableDelete(u)
@@ -298,7 +379,7 @@ func (m *ldbManager) GetPatch(identifier types.HashHeight) Patch {
 }
 func (m *ldbManager) getPatch(identifier types.HashHeight) Patch {
 	snapshot, _ := m.ldb.GetSnapshot()
-	value, err := snapshot.Get(common.JoinBytes(patchByte, common.Uint64ToBytes(identifier.Height)), nil)
+	value, err := m.ancient.get(snapshot, getPatchKey(identifier.Height))
 	if err == leveldb.ErrNotFound {
 		return nil
 	}
@@ -310,7 +391,7 @@ func (m *ldbManager) getPatch(identifier types.HashHeight) Patch {
 }
 func (m *ldbManager) getRollback(height uint64) Patch {
 	snapshot, _ := m.ldb.GetSnapshot()
-	value, err := snapshot.Get(common.JoinBytes(rollbackByte, common.Uint64ToBytes(height)), nil)
+	value, err := m.ancient.get(snapshot, getRollbackKey(height))
 	if err == leveldb.ErrNotFound {
 		return nil
 	}
@@ -361,37 +442,115 @@ func (m *ldbManager) Add(transaction Transaction) error {
 	frontierIdentifier := GetFrontierIdentifier(db)
 
 	if previous == frontierIdentifier {
-		if err := m.ldb.Put(common.JoinBytes(patchByte, common.Uint64ToBytes(identifier.Height)), patch.Dump(), nil); err != nil {
+		// The momentum, its patches and all of its changes are written in a single batch,
+		// which goes through the journal of leveldb and is synced to disk before returning.
+		batch := new(leveldb.Batch)
+		batch.Put(getPatchKey(identifier.Height), patch.Dump())
+		batch.Put(getRollbackKey(identifier.Height), rollbackPatch.Dump())
+		if err := ApplyPatch(m.batchDB(batch).Subset(frontierByte), patch); err != nil {
 			return err
 		}
-		if err := m.ldb.Put(common.JoinBytes(rollbackByte, common.Uint64ToBytes(identifier.Height)), rollbackPatch.Dump(), nil); err != nil {
+		if err := m.ldb.Write(batch, syncWrite); err != nil {
 			return err
 		}
-		if err := ApplyPatch(NewLevelDBWrapper(m.ldb).Subset(frontierByte), patch); err != nil {
-			return err
+		if m.ancient != nil {
+			m.notifyInserted()
 		}
 	}
 	return nil
 }
 func (m *ldbManager) Pop() error {
 	frontierIdentifier := GetFrontierIdentifier(m.Frontier())
-	rollbackPatch := m.getRollback(frontierIdentifier.Height)
+	return m.rollback(frontierIdentifier.Height)
+}
 
-	if err := ApplyPatch(NewLevelDBWrapper(m.ldb).Subset(frontierByte), rollbackPatch); err != nil {
-		return err
+// rollback atomically undoes the momentum at the given height and deletes its patches.
+func (m *ldbManager) rollback(height uint64) error {
+	if m.isFrozen(height) {
+		return errors.Errorf("can't rollback momentum at height %v, it's already in the ancient store", height)
 	}
-	if err := m.ldb.Delete(common.JoinBytes(patchByte, common.Uint64ToBytes(frontierIdentifier.Height)), nil); err != nil {
-		return err
+	rollbackPatch := m.getRollback(height)
+	if rollbackPatch == nil {
+		return errors.Errorf("can't find rollback patch for height %v", height)
 	}
-	if err := m.ldb.Delete(common.JoinBytes(rollbackByte, common.Uint64ToBytes(frontierIdentifier.Height)), nil); err != nil {
+
+	batch := new(leveldb.Batch)
+	if err := ApplyPatch(m.batchDB(batch).Subset(frontierByte), rollbackPatch); err != nil {
 		return err
 	}
+	batch.Delete(getPatchKey(height))
+	batch.Delete(getRollbackKey(height))
+	return m.ldb.Write(batch, syncWrite)
+}
+
+// isFrozen reports whether the momentum at height was moved to the ancient store.
+func (m *ldbManager) isFrozen(height uint64) bool {
+	if m.ancient == nil {
+		return false
+	}
+	m.changes.Lock()
+	defer m.changes.Unlock()
+	return height <= m.ancient.head
+}
+
+// batchDB returns a DB which reads from leveldb and collects the writes in the batch.
+func (m *ldbManager) batchDB(batch *leveldb.Batch) DB {
+	return enableDelete(&levelDBWrapper{db: &batchWriter{DB: m.ldb, batch: batch}})
+}
 
+// recover verifies the integrity of the frontier on startup. Databases written by
+// versions which did not apply momentums atomically may contain partially applied
+// momentums after a crash, these are rolled back to the last consistent momentum.
+func (m *ldbManager) recover() error {
+	frontier := enableDelete(m.ancient.reader(m.ldb)).Subset(frontierByte)
+	identifier := GetFrontierIdentifier(frontier)
+
+	// patches of the next momentum are written before its changes,
+	// a crash in between leaves them behind along with some of the changes
+	next := identifier.Height + 1
+	if m.getRollback(next) != nil {
+		recoverLog.Warn("rolling back partially applied momentum", "height", next)
+		if err := m.rollback(next); err != nil {
+			return err
+		}
+	} else if m.getPatch(types.HashHeight{Height: next}) != nil {
+		if err := m.ldb.Delete(getPatchKey(next), syncWrite); err != nil {
+			return err
+		}
+	}
+
+	for identifier.Height != 0 {
+		patch := m.getPatch(identifier)
+		if patch == nil {
+			return errors.Errorf("can't find patch for frontier %v", identifier)
+		}
+		applied, err := IsPatchApplied(frontier, patch)
+		if err != nil {
+			return err
+		}
+		if applied {
+			break
+		}
+		recoverLog.Warn("rolling back inconsistent momentum", "identifier", identifier)
+		if err := m.rollback(identifier.Height); err != nil {
+			return err
+		}
+		identifier = GetFrontierIdentifier(frontier)
+	}
 	return nil
 }
 func (m *ldbManager) Stop() error {
+	if m.ancient != nil {
+		close(m.closed)
+		m.freezeWg.Wait()
+	}
 	m.changes.Lock()
 	defer m.changes.Unlock()
+	if m.ancient != nil {
+		if err := m.ancient.ldb.Close(); err != nil {
+			return err
+		}
+	}
 	if err := m.ldb.Close(); err != nil {
 		return err
 	}
@@ -3,8 +3,11 @@ package db
 import (
 	"fmt"
 	"math/rand"
+	"path/filepath"
 	"testing"
 
+	"github.com/syndtr/goleveldb/leveldb"
+
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 )
@@ -260,3 +263,133 @@ d5104dc76695721d - b80704bb7b4d7c03
 dc2864602be7fb85 - d38967f931a50490
 f25f4b21eef64b43 - 9c0a8a2bfc0914df`)
 }
+
+// partialApplier applies only the first entries of a patch, like a write interrupted by a crash.
+type partialApplier struct {
+	db   DB
+	left int
+}
+
+func (pa *partialApplier) Put(key []byte, value []byte) {
+	if pa.left > 0 {
+		pa.left -= 1
+		common.DealWithErr(pa.db.Put(key, value))
+	}
+}
+func (pa *partialApplier) Delete(key []byte) {
+	if pa.left > 0 {
+		pa.left -= 1
+		common.DealWithErr(pa.db.Delete(key))
+	}
+}
+
+func TestVersionedDBRecover(t *testing.T) {
+	dir := t.TempDir()
+	m := NewLevelDBManager(dir)
+
+	common.DealWithErr(m.Add(newMockTransaction(1, m.Frontier())))
+	common.DealWithErr(m.Add(newMockTransaction(2, m.Frontier())))
+	f2 := GetFrontierIdentifier(m.Frontier())
+	expected := DebugDB(m.Frontier())
+
+	// crash while rolling back the frontier
+	common.DealWithErr(m.Add(newMockTransaction(3, m.Frontier())))
+	f3 := GetFrontierIdentifier(m.Frontier())
+	ldb := m.(*ldbManager).ldb
+	rollback := m.(*ldbManager).getRollback(f3.Height)
+	common.DealWithErr(rollback.Replay(&partialApplier{db: NewLevelDBWrapper(ldb).Subset(frontierByte), left: 2}))
+	common.FailIfErr(t, m.Stop())
+
+	m = NewLevelDBManager(dir)
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(m.Frontier()) == f2), `true`)
+	common.ExpectString(t, DebugDB(m.Frontier()), expected)
+	common.ExpectString(t, fmt.Sprintf("%v", m.GetPatch(f3) == nil), `true`)
+
+	// crash after writing the patches but while applying the changes of the next momentum
+	t3 := newMockTransaction(3, m.Frontier())
+	patch := t3.patch
+	ldb = m.(*ldbManager).ldb
+	common.FailIfErr(t, ldb.Put(getPatchKey(f3.Height), patch.Dump(), nil))
+	common.FailIfErr(t, ldb.Put(getRollbackKey(f3.Height), RollbackPatch(m.Frontier(), patch).Dump(), nil))
+	common.DealWithErr(patch.Replay(&partialApplier{db: NewLevelDBWrapper(ldb).Subset(frontierByte), left: 3}))
+	common.FailIfErr(t, m.Stop())
+
+	m = NewLevelDBManager(dir)
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(m.Frontier()) == f2), `true`)
+	common.ExpectString(t, DebugDB(m.Frontier()), expected)
+
+	// the recovered db keeps accepting momentums
+	common.DealWithErr(m.Add(newMockTransaction(3, m.Frontier())))
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(m.Frontier()) == f3), `true`)
+	common.FailIfErr(t, m.Stop())
+}
+
+func TestVersionedDBStripFrontier(t *testing.T) {
+	m := NewLevelDBManager(t.TempDir())
+
+	t1 := newMockTransaction(1, m.Frontier())
+	commit := t1.commit.(*mockCommit)
+	common.DealWithErr(m.Add(t1))
+
+	// the stored patch also sets the frontier to the commit
+	patch := m.GetPatch(commit.Identifier())
+	common.ExpectString(t, fmt.Sprintf("%v", PatchHash(patch) == commit.changesHash), `false`)
+	changes, err := StripFrontier(patch)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, fmt.Sprintf("%v", PatchHash(changes) == commit.changesHash), `true`)
+}
+
+func TestVersionedDBAncient(t *testing.T) {
+	dir := t.TempDir()
+	ancient := &AncientConfig{
+		Dir:       filepath.Join(dir, "ancient"),
+		Threshold: 2,
+		IsAncient: IsEntryByHeightKey,
+	}
+	m := NewLevelDBManagerWithAncient(filepath.Join(dir, "main"), ancient)
+	common.DealWithErr(m.Add(newMockTransaction(1, m.Frontier())))
+	f1 := GetFrontierIdentifier(m.Frontier())
+	expected := DebugDB(m.Frontier())
+	for i := int64(2); i <= 4; i += 1 {
+		common.DealWithErr(m.Add(newMockTransaction(i, m.Frontier())))
+	}
+	f4 := GetFrontierIdentifier(m.Frontier())
+	frontierDB := DebugDB(m.Frontier())
+
+	// momentums up to height 2 are moved to the ancient store
+	for {
+		done, err := m.(*ldbManager).freeze()
+		common.FailIfErr(t, err)
+		if done {
+			break
+		}
+	}
+	ldb := m.(*ldbManager).ldb
+	main := NewLevelDBWrapper(ldb).Subset(frontierByte)
+	for height := uint64(1); height <= 4; height += 1 {
+		_, err := main.Get(getEntryByHeightKey(height))
+		common.ExpectString(t, fmt.Sprintf("%v %v", height, err == nil), fmt.Sprintf("%v %v", height, height > 2))
+	}
+	common.ExpectString(t, DebugDB(m.Frontier()), frontierDB)
+	common.ExpectString(t, DebugDB(m.Get(f1)), expected)
+	common.ExpectString(t, fmt.Sprintf("%v", m.GetPatch(f1) != nil), `true`)
+
+	// momentums in the ancient store can't be rolled back
+	common.FailIfErr(t, m.Pop())
+	common.FailIfErr(t, m.Pop())
+	common.ExpectString(t, fmt.Sprintf("%v", m.Pop()), `can't rollback momentum at height 2, it's already in the ancient store`)
+	common.FailIfErr(t, m.Stop())
+
+	// the ancient store is required once used
+	ldb, err := leveldb.OpenFile(filepath.Join(dir, "main"), nil)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, fmt.Sprintf("%v", checkNoAncientStore(ldb)), fmt.Sprintf("the database keeps its old momentums in the ancient store at %v, which must be configured", ancient.Dir))
+	common.FailIfErr(t, ldb.Close())
+
+	m = NewLevelDBManagerWithAncient(filepath.Join(dir, "main"), ancient)
+	common.DealWithErr(m.Add(newMockTransaction(3, m.Frontier())))
+	common.DealWithErr(m.Add(newMockTransaction(4, m.Frontier())))
+	common.ExpectString(t, fmt.Sprintf("%v", GetFrontierIdentifier(m.Frontier()) == f4), `true`)
+	common.ExpectString(t, DebugDB(m.Frontier()), frontierDB)
+	common.FailIfErr(t, m.Stop())
+}
@@ -0,0 +1,193 @@
+// Package debug captures CPU, heap and block profiles of the running node.
+package debug
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	_ "net/http/pprof" // registers the pprof handlers on http.DefaultServeMux
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+var (
+	ErrCPUProfileRunning    = errors.New("CPU profiling already in progress")
+	ErrCPUProfileNotRunning = errors.New("CPU profiling not in progress")
+	ErrInvalidProfileName   = errors.New("profile name must be a plain file name")
+
+	log = common.NodeLogger.New("submodule", "debug")
+)
+
+// Handler is the global profiler, shared by the debug RPC namespace and the signal handler.
+var Handler = new(Profiler)
+
+// Profiler writes the profiles of the process in its directory.
+type Profiler struct {
+	dir string
+
+	mu      sync.Mutex
+	cpuW    *os.File
+	cpuFile string
+}
+
+// SetDir sets the directory in which the profiles are written.
+func (p *Profiler) SetDir(dir string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dir = dir
+}
+
+// path returns the location of the profile, names are not allowed to escape the directory.
+func (p *Profiler) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", ErrInvalidProfileName
+	}
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(p.dir, name), nil
+}
+
+// defaultName returns a timestamped profile name.
+func defaultName(kind string) string {
+	return fmt.Sprintf("%v-%v.pprof", kind, time.Now().Format("20060102-150405"))
+}
+
+// StartCPUProfile turns on CPU profiling, writing to the named file.
+func (p *Profiler) StartCPUProfile(name string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cpuW != nil {
+		return "", ErrCPUProfileRunning
+	}
+	if name == "" {
+		name = defaultName("cpu")
+	}
+	file, err := p.path(name)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	p.cpuW = f
+	p.cpuFile = file
+	log.Info("CPU profiling started", "file", file)
+	return file, nil
+}
+
+// StopCPUProfile stops an ongoing CPU profile and returns the file it was written to.
+func (p *Profiler) StopCPUProfile() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cpuW == nil {
+		return "", ErrCPUProfileNotRunning
+	}
+	pprof.StopCPUProfile()
+	err := p.cpuW.Close()
+	file := p.cpuFile
+	p.cpuW = nil
+	p.cpuFile = ""
+	log.Info("CPU profiling stopped", "file", file)
+	return file, err
+}
+
+// IsCPUProfiling reports whether a CPU profile is in progress.
+func (p *Profiler) IsCPUProfiling() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cpuW != nil
+}
+
+// WriteHeapProfile writes a heap profile to the named file.
+func (p *Profiler) WriteHeapProfile(name string) (string, error) {
+	if name == "" {
+		name = defaultName("heap")
+	}
+	runtime.GC()
+	return p.writeProfile("heap", name)
+}
+
+// BlockProfile turns on goroutine blocking profiling for the given duration
+// and writes the profile to the named file.
+func (p *Profiler) BlockProfile(name string, duration time.Duration) (string, error) {
+	if name == "" {
+		name = defaultName("block")
+	}
+	runtime.SetBlockProfileRate(1)
+	time.Sleep(duration)
+	defer runtime.SetBlockProfileRate(0)
+	return p.writeProfile("block", name)
+}
+
+func (p *Profiler) writeProfile(kind, name string) (string, error) {
+	p.mu.Lock()
+	file, err := p.path(name)
+	p.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := pprof.Lookup(kind).WriteTo(f, 0); err != nil {
+		return "", err
+	}
+	log.Info("wrote profile", "kind", kind, "file", file)
+	return file, nil
+}
+
+// WriteStackDump writes the header followed by the stacks of all the goroutines to the named file,
+// so a process which hangs can be debugged after it is killed.
+func (p *Profiler) WriteStackDump(name string, header func(w io.Writer) error) (string, error) {
+	if name == "" {
+		name = fmt.Sprintf("stacks-%v.dump", time.Now().Format("20060102-150405"))
+	}
+	p.mu.Lock()
+	file, err := p.path(name)
+	p.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if header != nil {
+		if err := header(f); err != nil {
+			return "", err
+		}
+	}
+	if _, err := fmt.Fprintf(f, "\n=== goroutines (%d) ===\n", runtime.NumGoroutine()); err != nil {
+		return "", err
+	}
+	if err := pprof.Lookup("goroutine").WriteTo(f, 2); err != nil {
+		return "", err
+	}
+	return file, f.Sync()
+}
+
+// StartPProf starts the pprof HTTP server on the address.
+func StartPProf(address string) {
+	log.Info("starting pprof server", "addr", fmt.Sprintf("http://%s/debug/pprof", address))
+	go func() {
+		if err := http.ListenAndServe(address, nil); err != nil {
+			log.Error("failure in running pprof server", "reason", err)
+		}
+	}()
+}
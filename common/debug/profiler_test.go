@@ -0,0 +1,78 @@
+package debug
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileNames(t *testing.T) {
+	p := new(Profiler)
+	p.SetDir(t.TempDir())
+
+	for _, name := range []string{"../heap.pprof", "/tmp/heap.pprof", "dir/heap.pprof", ".."} {
+		if _, err := p.WriteHeapProfile(name); err != ErrInvalidProfileName {
+			t.Errorf("expected %v for %q, got %v", ErrInvalidProfileName, name, err)
+		}
+	}
+
+	file, err := p.WriteHeapProfile("heap.pprof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(file) != p.dir {
+		t.Errorf("profile written outside of the profiles directory: %v", file)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCPUProfile(t *testing.T) {
+	p := new(Profiler)
+	p.SetDir(t.TempDir())
+
+	if _, err := p.StopCPUProfile(); err != ErrCPUProfileNotRunning {
+		t.Fatalf("expected %v, got %v", ErrCPUProfileNotRunning, err)
+	}
+	file, err := p.StartCPUProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.StartCPUProfile(""); err != ErrCPUProfileRunning {
+		t.Fatalf("expected %v, got %v", ErrCPUProfileRunning, err)
+	}
+	stopped, err := p.StopCPUProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped != file {
+		t.Errorf("expected %v, got %v", file, stopped)
+	}
+}
+
+func TestStackDump(t *testing.T) {
+	p := new(Profiler)
+	p.SetDir(t.TempDir())
+
+	file, err := p.WriteStackDump("", func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, "state")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "state\n") {
+		t.Errorf("expected the header first, got %q", string(data[:16]))
+	}
+	if !strings.Contains(string(data), "TestStackDump") {
+		t.Error("expected the stack of the test goroutine")
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package debug
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ListenSignals toggles CPU profiling on SIGUSR1 and writes a heap profile on SIGUSR2 until quit is closed.
+func ListenSignals(quit <-chan struct{}) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-quit:
+				return
+			case sig := <-c:
+				handleSignal(sig)
+			}
+		}
+	}()
+}
+
+func handleSignal(sig os.Signal) {
+	var err error
+	switch sig {
+	case syscall.SIGUSR1:
+		if Handler.IsCPUProfiling() {
+			_, err = Handler.StopCPUProfile()
+		} else {
+			_, err = Handler.StartCPUProfile("")
+		}
+	case syscall.SIGUSR2:
+		_, err = Handler.WriteHeapProfile("")
+	}
+	if err != nil {
+		log.Error("failed to handle profiling signal", "signal", sig, "reason", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package debug
+
+// ListenSignals is a no-op, profiling signals are not supported on Windows.
+func ListenSignals(quit <-chan struct{}) {
+}
@@ -2,7 +2,10 @@ package common
 
 import (
 	"bytes"
+	"fmt"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/inconshreveable/log15"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -28,54 +31,139 @@ var (
 	SupervisorLogger = log15.New("module", "supervisor")
 	EmbeddedLogger   = log15.New("module", "embedded")
 	WalletLogger     = log15.New("module", "wallet")
+	IndexerLogger    = log15.New("module", "indexer")
+	TokenMetaLogger  = log15.New("module", "tokenmeta")
 )
 
-func InitLogging(dataPath, logLevelStr string) {
-	var logHandle []log15.Handler
+// LogConfig configures the log files. They are rotated once they reach MaxSize megabytes and,
+// if RotateInterval is set, on a timer. Rotated files are removed after MaxAge days or once
+// there are more than MaxBackups of them.
+type LogConfig struct {
+	Level        string
+	ModuleLevels map[string]string // ModuleLevels overrides Level for the given modules
+	JSON         bool              // JSON writes the records as JSON objects instead of logfmt
 
-	logDir := runLogDir(dataPath)
-	logLevel, err := log15.LvlFromString(logLevelStr)
+	MaxSize        int
+	MaxBackups     int
+	MaxAge         int
+	Compress       bool
+	RotateInterval time.Duration
+}
+
+// logLevels holds the maximum level of the records written to the main log, per module.
+type logLevels struct {
+	mu      sync.RWMutex
+	root    log15.Lvl
+	modules map[string]log15.Lvl
+}
+
+var levels = &logLevels{
+	root:    log15.LvlInfo,
+	modules: make(map[string]log15.Lvl),
+}
+
+// allows checks the record against the level of its module. Records of nested loggers carry
+// several modules, the override of the innermost one wins.
+func (l *logLevels) allows(r *log15.Record) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	maxLvl := l.root
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		if key, ok := r.Ctx[i].(string); !ok || (key != "module" && key != "submodule") {
+			continue
+		}
+		if module, ok := r.Ctx[i+1].(string); ok {
+			if lvl, ok := l.modules[module]; ok {
+				maxLvl = lvl
+			}
+		}
+	}
+	return r.Lvl <= maxLvl
+}
+
+// SetLogLevel sets the level of the module, or of all the modules without an override if
+// module is empty. An empty level removes the override of the module.
+func SetLogLevel(module, level string) error {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if module != "" && level == "" {
+		delete(levels.modules, module)
+		return nil
+	}
+	lvl, err := log15.LvlFromString(level)
 	if err != nil {
-		logLevel = log15.LvlInfo
+		return err
+	}
+	if module == "" {
+		levels.root = lvl
+	} else {
+		levels.modules[module] = lvl
 	}
+	return nil
+}
 
-	logHandle = append(logHandle, errorExcludeLvlFilterHandler(logLevel, runLogHandler(logDir)))
-	logHandle = append(logHandle, log15.LvlFilterHandler(log15.LvlError, runErrorLogHandler(logDir)))
+func InitLogging(dataPath string, config LogConfig) {
+	var logHandle []log15.Handler
+
+	if err := SetLogLevel("", config.Level); err != nil {
+		_ = SetLogLevel("", "info")
+	}
+	for module, level := range config.ModuleLevels {
+		if err := SetLogLevel(module, level); err != nil {
+			fmt.Printf("Ignoring log level '%v' of module '%v'. Reason: %v\n", level, module, err)
+		}
+	}
+
+	format := log15.LogfmtFormat()
+	if config.JSON {
+		format = log15.JsonFormat()
+	}
+
+	logDir := runLogDir(dataPath)
+	runLogger := newLogger(filepath.Join(logDir, "zenon.log"), config)
+	errorLogger := newLogger(filepath.Join(logDir, "error", "zenon.error.log"), config)
+
+	logHandle = append(logHandle, log15.FilterHandler(levels.allows, log15.StreamHandler(runLogger, format)))
+	logHandle = append(logHandle, log15.LvlFilterHandler(log15.LvlError, log15.StreamHandler(errorLogger, format)))
 
 	log15.Root().SetHandler(log15.MultiHandler(
 		logHandle...,
 	))
+
+	if config.RotateInterval > 0 {
+		go rotateLogs(config.RotateInterval, runLogger, errorLogger)
+	}
 }
 
 func runLogDir(dataPath string) string {
 	return filepath.Join(dataPath, "log")
 }
-func runLogHandler(logDir string) log15.Handler {
-	filename := "zenon.log"
-	logger := defaultLogger(filepath.Join(logDir, filename))
-	return log15.StreamHandler(logger, log15.LogfmtFormat())
-}
-func runErrorLogHandler(logDir string) log15.Handler {
-	filename := "zenon.error.log"
-	logger := defaultLogger(filepath.Join(logDir, "error", filename))
-	return log15.StreamHandler(logger, log15.LogfmtFormat())
-}
-func errorExcludeLvlFilterHandler(maxLvl log15.Lvl, h log15.Handler) log15.Handler {
-	return log15.FilterHandler(func(r *log15.Record) (ss bool) {
-		return r.Lvl <= maxLvl
-	}, h)
-}
-func defaultLogger(absFilePath string) *lumberjack.Logger {
+func newLogger(absFilePath string, config LogConfig) *lumberjack.Logger {
 	return &lumberjack.Logger{
 		Filename:   absFilePath,
-		MaxSize:    100,
-		MaxBackups: 14,
-		MaxAge:     14,
-		Compress:   false,
+		MaxSize:    config.MaxSize,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAge,
+		Compress:   config.Compress,
 		LocalTime:  false,
 	}
 }
 
+// rotateLogs rotates the log files every interval, for the lifetime of the process.
+func rotateLogs(interval time.Duration, loggers ...*lumberjack.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, logger := range loggers {
+			if err := logger.Rotate(); err != nil {
+				fmt.Printf("Failed to rotate log file '%v'. Reason: %v\n", logger.Filename, err)
+			}
+		}
+	}
+}
+
 type LogSaver struct {
 	format log15.Format
 	buffer *bytes.Buffer
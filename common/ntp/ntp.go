@@ -0,0 +1,147 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ntp measures the drift of the local clock against an SNTP server.
+package ntp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultServer is the pool queried if none is configured.
+	DefaultServer = "pool.ntp.org"
+	// Measurements is the number of queries whose median drift is kept, see Drift.
+	Measurements = 3
+	// MaxDisagreement is the largest difference between the drifts kept by Drift.
+	MaxDisagreement = 250 * time.Millisecond
+
+	requestTimeout = 5 * time.Second
+
+	modeServer         = 4
+	leapUnsynchronized = 3
+	maxStratum         = 15
+)
+
+var (
+	ErrInvalidReply   = errors.New("invalid SNTP reply")
+	ErrKissOfDeath    = errors.New("the SNTP server refused the query")
+	ErrUnsynced       = errors.New("the SNTP server isn't synchronized")
+	ErrOriginMismatch = errors.New("the SNTP reply doesn't answer the query")
+	ErrDisagreement   = errors.New("the drifts measured with the SNTP server disagree")
+)
+
+// ntpEpoch is the start of the NTP timestamps.
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Drift queries the server measurements+2 times and returns the median drift of the local clock, after the
+// smallest and the largest one are dropped. The kept drifts must be within MaxDisagreement of each other.
+// The drift is positive if the local clock is ahead of the server.
+// The server is a host or host:port, port 123 by default.
+func Drift(server string, measurements int) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	addr, err := net.ResolveUDPAddr("udp", server)
+	if err != nil {
+		return 0, err
+	}
+
+	drifts := make([]time.Duration, 0, measurements+2)
+	for i := 0; i < measurements+2; i++ {
+		drift, err := query(addr)
+		if err != nil {
+			return 0, err
+		}
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i] < drifts[j] })
+	kept := drifts[1 : len(drifts)-1]
+	if kept[len(kept)-1]-kept[0] > MaxDisagreement {
+		return 0, ErrDisagreement
+	}
+	return kept[len(kept)/2], nil
+}
+
+func query(addr *net.UDPAddr) (time.Duration, error) {
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// version 3, client mode, the transmit timestamp is random and must be echoed as the originate timestamp
+	request := make([]byte, 48)
+	request[0] = 3<<3 | 3
+	if _, err := rand.Read(request[40:]); err != nil {
+		return 0, err
+	}
+
+	sent := time.Now()
+	if err := conn.SetDeadline(sent.Add(requestTimeout)); err != nil {
+		return 0, err
+	}
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+	reply := make([]byte, 48)
+	n, err := conn.Read(reply)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(sent)
+	if err := checkReply(request, reply[:n]); err != nil {
+		return 0, err
+	}
+
+	// the transmit timestamp of the server
+	sec := uint64(reply[43]) | uint64(reply[42])<<8 | uint64(reply[41])<<16 | uint64(reply[40])<<24
+	frac := uint64(reply[47]) | uint64(reply[46])<<8 | uint64(reply[45])<<16 | uint64(reply[44])<<24
+	nanosec := sec*1e9 + (frac*1e9)>>32
+	t := ntpEpoch.Add(time.Duration(nanosec))
+
+	// the server answered half way through the round trip
+	return sent.Add(elapsed / 2).Sub(t), nil
+}
+
+// checkReply returns an error unless reply is a server reply to request from a synchronized server.
+func checkReply(request, reply []byte) error {
+	if len(reply) < 48 {
+		return ErrInvalidReply
+	}
+	if reply[0]&0x7 != modeServer {
+		return ErrInvalidReply
+	}
+	if reply[1] == 0 {
+		return ErrKissOfDeath
+	}
+	if reply[0]>>6 == leapUnsynchronized || reply[1] > maxStratum {
+		return ErrUnsynced
+	}
+	if !bytes.Equal(reply[24:32], request[40:48]) {
+		return ErrOriginMismatch
+	}
+	if bytes.Equal(reply[40:48], make([]byte, 8)) {
+		return ErrInvalidReply
+	}
+	return nil
+}
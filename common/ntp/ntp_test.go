@@ -0,0 +1,115 @@
+package ntp
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// serve answers the SNTP requests with the local time shifted by offset.
+func serve(t *testing.T, offset time.Duration) string {
+	return serveWith(t, func(int) time.Duration { return offset }, nil)
+}
+
+// serveWith answers the i-th SNTP request with the local time shifted by offset(i), the reply is
+// changed by modify if set.
+func serveWith(t *testing.T, offset func(i int) time.Duration, modify func(reply []byte)) string {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 48)
+		for i := 0; ; i++ {
+			_, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			nanosec := uint64(time.Now().Add(offset(i)).Sub(ntpEpoch))
+			reply := make([]byte, 48)
+			// version 3, server mode, stratum 2
+			reply[0] = 3<<3 | modeServer
+			reply[1] = 2
+			copy(reply[24:32], buf[40:48])
+			binary.BigEndian.PutUint32(reply[40:], uint32(nanosec/1e9))
+			binary.BigEndian.PutUint32(reply[44:], uint32((nanosec%1e9)<<32/1e9))
+			if modify != nil {
+				modify(reply)
+			}
+			if _, err := conn.WriteToUDP(reply, addr); err != nil {
+				return
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestDrift(t *testing.T) {
+	for _, offset := range []time.Duration{0, 3 * time.Second, -2 * time.Second} {
+		drift, err := Drift(serve(t, offset), Measurements)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the local clock is ahead of the server by -offset
+		if diff := drift + offset; diff > 50*time.Millisecond || diff < -50*time.Millisecond {
+			t.Errorf("expected a drift of %v, got %v", -offset, drift)
+		}
+	}
+}
+
+func TestDriftUnreachable(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := conn.LocalAddr().String()
+	conn.Close()
+	if _, err := Drift(server, Measurements); err == nil {
+		t.Fatal("expected an error for a closed port")
+	}
+}
+
+// Test the replies which aren't a valid answer of a synchronized server are refused
+func TestDriftInvalidReply(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(reply []byte)
+		err    error
+	}{
+		{"client mode", func(reply []byte) { reply[0] = 3<<3 | 3 }, ErrInvalidReply},
+		{"kiss-o'-death", func(reply []byte) { reply[1] = 0; copy(reply[12:16], "RATE") }, ErrKissOfDeath},
+		{"unsynchronized", func(reply []byte) { reply[0] |= leapUnsynchronized << 6 }, ErrUnsynced},
+		{"stratum 16", func(reply []byte) { reply[1] = 16 }, ErrUnsynced},
+		{"origin", func(reply []byte) { reply[24] ^= 0xff }, ErrOriginMismatch},
+		{"zero transmit timestamp", func(reply []byte) { copy(reply[40:48], make([]byte, 8)) }, ErrInvalidReply},
+	} {
+		server := serveWith(t, func(int) time.Duration { return 0 }, test.modify)
+		if _, err := Drift(server, Measurements); err != test.err {
+			t.Errorf("%v: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+// Test the drift is the median of the measurements without the outliers, and the measurements must agree
+func TestDriftAgreement(t *testing.T) {
+	outlier := func(i int) time.Duration {
+		if i == 2 {
+			return time.Hour
+		}
+		return 2 * time.Second
+	}
+	drift, err := Drift(serveWith(t, outlier, nil), Measurements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := drift + 2*time.Second; diff > 50*time.Millisecond || diff < -50*time.Millisecond {
+		t.Errorf("expected a drift of -2s without the outlier, got %v", drift)
+	}
+
+	spread := func(i int) time.Duration { return time.Duration(i) * time.Second }
+	if _, err := Drift(serveWith(t, spread, nil), Measurements); err != ErrDisagreement {
+		t.Fatalf("expected %v, got %v", ErrDisagreement, err)
+	}
+}
@@ -0,0 +1,219 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+)
+
+const (
+	otlpQueueSize     = 4096
+	otlpBatchSize     = 512
+	otlpFlushInterval = 5 * time.Second
+	otlpTimeout       = 10 * time.Second
+)
+
+// same as common.NodeLogger, common can't be imported since the rpc server depends on this package
+var log = log15.New("module", "node", "submodule", "tracing")
+
+// OTLPExporter sends the spans in batches to an OpenTelemetry collector, using OTLP over HTTP with the JSON encoding.
+// Spans are dropped if the queue is full, tracing never slows the node down.
+type OTLPExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+
+	queue   chan *Span
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	lock    sync.Mutex
+	dropped uint64
+}
+
+// NewOTLPExporter exports to the collector at endpoint, like http://localhost:4318, and starts the export loop.
+func NewOTLPExporter(endpoint, serviceName string) *OTLPExporter {
+	e := &OTLPExporter{
+		url:         strings.TrimRight(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: otlpTimeout},
+		queue:       make(chan *Span, otlpQueueSize),
+		stop:        make(chan struct{}),
+	}
+	e.wg.Add(1)
+	go e.loop()
+	return e
+}
+
+func (e *OTLPExporter) Export(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		e.lock.Lock()
+		e.dropped += 1
+		e.lock.Unlock()
+	}
+}
+
+// Stop flushes the queued spans and stops the export loop.
+func (e *OTLPExporter) Stop() {
+	close(e.stop)
+	e.wg.Wait()
+}
+
+func (e *OTLPExporter) loop() {
+	defer e.wg.Done()
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, otlpBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			log.Warn("failed to export spans", "reason", err, "count", len(batch))
+		}
+		batch = batch[:0]
+
+		e.lock.Lock()
+		dropped := e.dropped
+		e.dropped = 0
+		e.lock.Unlock()
+		if dropped != 0 {
+			log.Warn("dropped spans, the export queue was full", "count", dropped)
+		}
+	}
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) == otlpBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+					if len(batch) == otlpBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *OTLPExporter) send(batch []*Span) error {
+	body, err := json.Marshal(e.encode(batch))
+	if err != nil {
+		return err
+	}
+	response, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("collector replied %v", response.Status)
+	}
+	return nil
+}
+
+// OTLP JSON encoding, see opentelemetry-proto/opentelemetry/proto/trace/v1/trace.proto
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+type otlpSpan struct {
+	TraceID      string          `json:"traceId"`
+	SpanID       string          `json:"spanId"`
+	ParentSpanID string          `json:"parentSpanId,omitempty"`
+	Name         string          `json:"name"`
+	Kind         SpanKind        `json:"kind"`
+	Start        string          `json:"startTimeUnixNano"`
+	End          string          `json:"endTimeUnixNano"`
+	Attributes   []otlpAttribute `json:"attributes"`
+	Status       otlpStatus      `json:"status"`
+}
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func newOTLPAttribute(key string, value interface{}) otlpAttribute {
+	attribute := otlpAttribute{Key: key}
+	switch v := value.(type) {
+	case bool:
+		attribute.Value.BoolValue = &v
+	case int, int64, uint64, uint32, int32:
+		s := fmt.Sprintf("%d", v)
+		attribute.Value.IntValue = &s
+	default:
+		s := fmt.Sprintf("%v", v)
+		attribute.Value.StringValue = &s
+	}
+	return attribute
+}
+
+func (e *OTLPExporter) encode(batch []*Span) *otlpRequest {
+	scope := otlpScopeSpans{Spans: make([]otlpSpan, len(batch))}
+	scope.Scope.Name = "github.com/zenon-network/go-zenon"
+	for index, span := range batch {
+		s := otlpSpan{
+			TraceID:    span.Context.TraceID.String(),
+			SpanID:     span.Context.SpanID.String(),
+			Name:       span.Name,
+			Kind:       span.Kind,
+			Start:      fmt.Sprintf("%d", span.Start.UnixNano()),
+			End:        fmt.Sprintf("%d", span.End.UnixNano()),
+			Attributes: make([]otlpAttribute, 0, len(span.Attributes)),
+			Status:     otlpStatus{Code: 1},
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		for key, value := range span.Attributes {
+			s.Attributes = append(s.Attributes, newOTLPAttribute(key, value))
+		}
+		if span.Error != "" {
+			s.Status = otlpStatus{Code: 2, Message: span.Error}
+		}
+		scope.Spans[index] = s
+	}
+
+	resource := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{scope}}
+	resource.Resource.Attributes = []otlpAttribute{newOTLPAttribute("service.name", e.serviceName)}
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{resource}}
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := ParseTraceparent(header)
+	if !ok {
+		t.Fatalf("failed to parse %q", header)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("unexpected span context %+v", sc)
+	}
+	if sc.Traceparent() != header {
+		t.Errorf("expected %q, got %q", header, sc.Traceparent())
+	}
+
+	for _, header := range []string{
+		"",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceparent(header); ok {
+			t.Errorf("expected %q to be invalid", header)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	if span := SpanFromContext(context.Background()); span != nil {
+		t.Fatal("unexpected span")
+	}
+	if _, span := StartSpan(context.Background(), "disabled", SpanKindServer); span != nil {
+		t.Fatal("spans must not be recorded without an exporter")
+	}
+
+	requests := make(chan *otlpRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("unexpected path %v", r.URL.Path)
+		}
+		request := new(otlpRequest)
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			t.Error(err)
+		}
+		requests <- request
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL, "znnd")
+	SetExporter(exporter)
+	defer SetExporter(nil)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, span := StartSpan(ContextWithRemoteParent(context.Background(), remote), "rpc ledger.publishRawTransaction", SpanKindServer)
+	span.SetAttribute("rpc.method", "ledger.publishRawTransaction")
+	_, child := StartChildSpan(ctx, "vm.applyBlock")
+	child.Finish(errors.New("insufficient balance"))
+	span.Finish(nil)
+	exporter.Stop()
+
+	spans := (<-requests).ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %v", len(spans))
+	}
+	if spans[0].Name != "vm.applyBlock" || spans[0].ParentSpanID != span.Context.SpanID.String() || spans[0].Status.Code != 2 {
+		t.Errorf("unexpected child span %+v", spans[0])
+	}
+	if spans[1].TraceID != remote.TraceID.String() || spans[1].ParentSpanID != remote.SpanID.String() || spans[1].Status.Code != 1 {
+		t.Errorf("unexpected span %+v", spans[1])
+	}
+	if len(spans[1].Attributes) != 1 || *spans[1].Attributes[0].Value.StringValue != "ledger.publishRawTransaction" {
+		t.Errorf("unexpected attributes %+v", spans[1].Attributes)
+	}
+}
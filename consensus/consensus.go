@@ -82,6 +82,13 @@ func (cs *consensus) GetMomentumProducer(timestamp time.Time) (*types.Address, e
 	}
 	return nil, errors.Errorf("couldn't find producer for timestamp")
 }
+func (cs *consensus) GetProducerEvents(timestamp time.Time) ([]*ProducerEvent, error) {
+	election, err := cs.electionManager.ElectionByTime(timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return election.Producers, nil
+}
 func (cs *consensus) VerifyMomentumProducer(momentum *nom.Momentum) (bool, error) {
 	expected, err := cs.GetMomentumProducer(*momentum.Timestamp)
 	if err != nil {
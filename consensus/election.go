@@ -55,6 +55,17 @@ func generateProducers(info *Context, tick uint64, producerAddresses []types.Add
 
 	return producers
 }
+
+// ProducerAt returns the producer of the slot which starts at t, given the producers elected for the
+// tick of t in the order of their slots. It's nil if no slot starts at t.
+func ProducerAt(info *Context, t time.Time, producerAddresses []types.Address) *types.Address {
+	for _, plan := range generateProducers(info, info.ToTick(t), producerAddresses) {
+		if plan.StartTime.Equal(t) {
+			return &plan.Producer
+		}
+	}
+	return nil
+}
 func genElectionResult(info *Context, tick uint64, data *storage.ElectionData) *electionResult {
 	result := &electionResult{
 		Tick:        tick,
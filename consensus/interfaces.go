@@ -39,6 +39,8 @@ type Consensus interface {
 	Stop() error
 
 	GetMomentumProducer(timestamp time.Time) (*types.Address, error)
+	// GetProducerEvents returns the momentum production slots of the election tick which contains timestamp.
+	GetProducerEvents(timestamp time.Time) ([]*ProducerEvent, error)
 
 	FrontierPillarReader() api.PillarReader
 	FixedPillarReader(types.HashHeight) api.PillarReader
@@ -0,0 +1,237 @@
+package indexer
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+type BridgeConfigEventType uint8
+
+const (
+	BridgeNetworkSet BridgeConfigEventType = iota
+	BridgeNetworkRemoved
+	BridgeNetworkMetadataSet
+	BridgeTokenPairSet
+	BridgeTokenPairRemoved
+)
+
+var bridgeConfigEventNames = map[BridgeConfigEventType]string{
+	BridgeNetworkSet:         "setNetwork",
+	BridgeNetworkRemoved:     "removeNetwork",
+	BridgeNetworkMetadataSet: "setNetworkMetadata",
+	BridgeTokenPairSet:       "setTokenPair",
+	BridgeTokenPairRemoved:   "removeTokenPair",
+}
+
+func (t BridgeConfigEventType) String() string {
+	return bridgeConfigEventNames[t]
+}
+
+// BridgeConfigEvent is an administrative change of a network of the bridge or of one of its token pairs, decoded
+// from the logs of the bridge contract. Hash is the receive-block of the bridge contract and Administrator the
+// address which sent the change. Only the fields of the change are set, the name and the contract address for
+// a network, the metadata for a network or a token pair and the token pair fields for a token pair.
+type BridgeConfigEvent struct {
+	NetworkClass      uint32
+	ChainId           uint32
+	Type              BridgeConfigEventType
+	Hash              types.Hash
+	Administrator     types.Address
+	Name              string
+	ContractAddress   string
+	Metadata          string
+	TokenStandard     types.ZenonTokenStandard
+	TokenAddress      string
+	Bridgeable        bool
+	Redeemable        bool
+	Owned             bool
+	MinAmount         *big.Int
+	FeePercentage     uint32
+	RedeemDelay       uint32
+	MomentumHeight    uint64
+	MomentumTimestamp uint64
+}
+
+// parseBridgeConfigLog decodes the configuration change of a log of the bridge contract, nil for the other events.
+func parseBridgeConfigLog(log *nom.Log) (*BridgeConfigEvent, error) {
+	if log.Address != types.BridgeContract || len(log.Topics) != 4 {
+		return nil, nil
+	}
+	abiEvent, err := definition.ABIBridge.EventById(log.Topics[0])
+	if err != nil {
+		return nil, nil
+	}
+
+	event := new(BridgeConfigEvent)
+	switch abiEvent.Name {
+	case definition.NetworkSetEventName:
+		event.Type = BridgeNetworkSet
+		args := new(struct {
+			Name            string
+			ContractAddress string
+			Metadata        string
+		})
+		err = definition.ABIBridge.UnpackEvent(args, abiEvent.Name, log.Data)
+		event.Name, event.ContractAddress, event.Metadata = args.Name, args.ContractAddress, args.Metadata
+	case definition.NetworkRemovedEventName:
+		event.Type = BridgeNetworkRemoved
+	case definition.NetworkMetadataSetEventName:
+		event.Type = BridgeNetworkMetadataSet
+		args := new(struct{ Metadata string })
+		err = definition.ABIBridge.UnpackEvent(args, abiEvent.Name, log.Data)
+		event.Metadata = args.Metadata
+	case definition.TokenPairSetEventName:
+		event.Type = BridgeTokenPairSet
+		args := new(definition.TokenPair)
+		err = definition.ABIBridge.UnpackEvent(args, abiEvent.Name, log.Data)
+		event.TokenStandard, event.TokenAddress = args.TokenStandard, args.TokenAddress
+		event.Bridgeable, event.Redeemable, event.Owned = args.Bridgeable, args.Redeemable, args.Owned
+		event.MinAmount, event.FeePercentage, event.RedeemDelay = args.MinAmount, args.FeePercentage, args.RedeemDelay
+		event.Metadata = args.Metadata
+	case definition.TokenPairRemovedEventName:
+		event.Type = BridgeTokenPairRemoved
+		args := new(struct {
+			TokenStandard types.ZenonTokenStandard
+			TokenAddress  string
+		})
+		err = definition.ABIBridge.UnpackEvent(args, abiEvent.Name, log.Data)
+		event.TokenStandard, event.TokenAddress = args.TokenStandard, args.TokenAddress
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// indexed arguments are left padded to a topic
+	event.NetworkClass = binary.BigEndian.Uint32(log.Topics[1].Bytes()[types.HashSize-4:])
+	event.ChainId = binary.BigEndian.Uint32(log.Topics[2].Bytes()[types.HashSize-4:])
+	if event.Administrator, err = types.BytesToAddress(log.Topics[3].Bytes()[types.HashSize-types.AddressSize:]); err != nil {
+		return nil, err
+	}
+	if event.MinAmount == nil {
+		event.MinAmount = big.NewInt(0)
+	}
+	return event, nil
+}
+
+// bridgeConfigEvents returns the configuration changes of the bridge confirmed by the momentum, in confirmation order.
+func (ix *indexer) bridgeConfigEvents(detailed *nom.DetailedMomentum) ([]*BridgeConfigEvent, error) {
+	events := make([]*BridgeConfigEvent, 0)
+	for _, block := range detailed.AccountBlocks {
+		if block.Address != types.BridgeContract || !block.IsReceiveBlock() {
+			continue
+		}
+		logs, err := ix.chain.GetAccountBlockLogs(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			event, err := parseBridgeConfigLog(log)
+			if err != nil {
+				return nil, err
+			}
+			if event != nil {
+				event.Hash = block.Hash
+				event.MomentumHeight = detailed.Momentum.Height
+				event.MomentumTimestamp = uint64(detailed.Momentum.Timestamp.Unix())
+				events = append(events, event)
+			}
+		}
+	}
+	return events, nil
+}
+
+// indexBridgeConfig writes the bridge configuration events of the momentum.
+// The bridge momentum entry is written last, a momentum which has it is already indexed.
+func (ix *indexer) indexBridgeConfig(detailed *nom.DetailedMomentum) error {
+	key := getBridgeMomentumKey(detailed.Momentum.Height)
+	if data, err := ix.db.Get(key); err == nil && len(data) != 0 {
+		return nil
+	} else if err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+
+	events, err := ix.bridgeConfigEvents(detailed)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	for _, event := range events {
+		data, err := rlp.EncodeToBytes(event)
+		if err != nil {
+			return err
+		}
+		if err := ix.db.Put(getBridgeConfigEventKey(event.NetworkClass, event.ChainId, event.MomentumHeight, event.Hash), data); err != nil {
+			return err
+		}
+	}
+	data, err := rlp.EncodeToBytes(events)
+	if err != nil {
+		return err
+	}
+	return ix.db.Put(key, data)
+}
+
+// unindexBridgeConfig deletes the bridge configuration events of the momentum, read from the bridge momentum
+// entry since the logs of the momentum are deleted first on rollback.
+func (ix *indexer) unindexBridgeConfig(detailed *nom.DetailedMomentum) error {
+	key := getBridgeMomentumKey(detailed.Momentum.Height)
+	data, err := ix.db.Get(key)
+	if err == leveldb.ErrNotFound || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	events := make([]*BridgeConfigEvent, 0)
+	if err := rlp.DecodeBytes(data, &events); err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := ix.db.Delete(getBridgeConfigEventKey(event.NetworkClass, event.ChainId, event.MomentumHeight, event.Hash)); err != nil {
+			return err
+		}
+	}
+	return ix.db.Delete(key)
+}
+
+func (ix *indexer) GetBridgeConfigHistory(networkClass, chainId uint32, pageIndex, pageSize uint32) ([]*BridgeConfigEvent, bool, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	iterator := ix.db.NewIterator(getBridgeConfigEventPrefix(networkClass, chainId))
+	defer iterator.Release()
+
+	skip := uint64(pageIndex) * uint64(pageSize)
+	events := make([]*BridgeConfigEvent, 0, pageSize)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, false, iterator.Error()
+			}
+			return events, false, nil
+		}
+		// skip deleted entries
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		if skip > 0 {
+			skip -= 1
+			continue
+		}
+		if uint32(len(events)) == pageSize {
+			return events, true, nil
+		}
+		event := new(BridgeConfigEvent)
+		if err := rlp.DecodeBytes(iterator.Value(), event); err != nil {
+			return nil, false, err
+		}
+		events = append(events, event)
+	}
+}
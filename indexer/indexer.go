@@ -0,0 +1,577 @@
+package indexer
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// selectorSize is the size of the abi method id which prefixes the data of embedded calls.
+	selectorSize = 4
+	// momentumTimeBucket is the resolution in seconds of the momentum time index
+	momentumTimeBucket = 60
+)
+
+// TokenHolder is the balance of an address for a token standard.
+type TokenHolder struct {
+	Address types.Address
+	Balance *big.Int
+}
+
+// Indexer maintains secondary indexes over the send-blocks of the chain, the token holders, the token supplies
+// and the configuration changes of the bridge.
+// Momentums are indexed in order, in the background, and un-indexed on rollback.
+type Indexer interface {
+	chain.MomentumEventListener
+
+	Init() error
+	Start() error
+	Stop() error
+
+	// Frontier returns the last indexed momentum.
+	Frontier() types.HashHeight
+
+	// All getters return the block hashes ordered by confirmation height, then by hash,
+	// and true if there are more entries after the requested page.
+	GetBlocksByTokenStandard(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) ([]types.Hash, bool, error)
+	GetBlocksByAddressPair(from, to types.Address, pageIndex, pageSize uint32) ([]types.Hash, bool, error)
+	GetBlocksByEmbeddedMethod(contract types.Address, selector []byte, pageIndex, pageSize uint32) ([]types.Hash, bool, error)
+
+	// GetTokenHolders returns the addresses with a non-zero balance of zts, ordered by address,
+	// and true if there are more holders after the requested page.
+	GetTokenHolders(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) ([]*TokenHolder, bool, error)
+
+	// GetMomentumHeightByTime returns the height of the first momentum with a timestamp greater than or equal
+	// to timestamp, in seconds, and false if the indexer didn't reach timestamp yet.
+	GetMomentumHeightByTime(timestamp int64) (uint64, bool, error)
+
+	// GetSupplyHistory returns the changes of the total supply of zts confirmed by momentums with a timestamp,
+	// in seconds, in [fromTime, toTime), ordered by confirmation height.
+	// Only the blocks confirmed since the node records the logs of the embedded contracts are taken into account,
+	// the total supply after each change is exact regardless. It returns ErrSupplyNotSynced while catching up.
+	GetSupplyHistory(zts types.ZenonTokenStandard, fromTime, toTime int64) ([]*SupplyEvent, error)
+
+	// GetBridgeConfigHistory returns the changes of the bridge network and of its token pairs ordered by
+	// confirmation height, and true if there are more changes after the requested page.
+	// Only the blocks confirmed since the bridge contract logs its configuration changes are taken into account.
+	GetBridgeConfigHistory(networkClass, chainId uint32, pageIndex, pageSize uint32) ([]*BridgeConfigEvent, bool, error)
+}
+
+type indexer struct {
+	log   common.Logger
+	chain chain.Chain
+	db    db.DB
+
+	changes sync.Mutex
+	wake    chan struct{}
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+func NewIndexer(db db.DB, chain chain.Chain) Indexer {
+	return &indexer{
+		log:    common.IndexerLogger,
+		chain:  chain,
+		db:     db,
+		wake:   make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+}
+
+func (ix *indexer) Init() error {
+	return nil
+}
+func (ix *indexer) Start() error {
+	ix.log.Info("starting indexer", "frontier-identifier", ix.Frontier())
+	ix.chain.Register(ix)
+
+	ix.wg.Add(1)
+	go func() {
+		defer ix.wg.Done()
+		ix.loop()
+	}()
+	ix.notify()
+	return nil
+}
+func (ix *indexer) Stop() error {
+	ix.chain.UnRegister(ix)
+	close(ix.closed)
+	ix.wg.Wait()
+	return nil
+}
+
+func (ix *indexer) InsertMomentum(*nom.DetailedMomentum) {
+	ix.notify()
+}
+func (ix *indexer) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	// holders are refreshed even if the momentum wasn't indexed yet, since
+	// they may have been written with balances which included it
+	if err := ix.updateHolders(detailed, ix.chain.GetFrontierMomentumStore()); err != nil {
+		ix.log.Error("failed to update token holders", "identifier", detailed.Momentum.Identifier(), "reason", err)
+	}
+
+	// nothing else to do if the momentum wasn't indexed yet
+	if ix.frontier().Height < detailed.Momentum.Height {
+		return
+	}
+	if err := ix.apply(detailed, func(key, _ []byte) error {
+		return ix.db.Delete(key)
+	}); err != nil {
+		ix.log.Error("failed to un-index momentum", "identifier", detailed.Momentum.Identifier(), "reason", err)
+		return
+	}
+	if err := ix.applyMomentumTime(detailed.Momentum, ix.chain.GetFrontierMomentumStore(), func(key, _ []byte) error {
+		return ix.db.Delete(key)
+	}); err != nil {
+		ix.log.Error("failed to un-index momentum", "identifier", detailed.Momentum.Identifier(), "reason", err)
+		return
+	}
+	for _, index := range ix.logIndexes() {
+		if ix.getFrontier(index.frontierKey).Height < detailed.Momentum.Height {
+			continue
+		}
+		if err := index.unindex(detailed); err != nil {
+			ix.log.Error("failed to un-index momentum", "identifier", detailed.Momentum.Identifier(), "reason", err)
+			return
+		}
+		if err := ix.putFrontier(index.frontierKey, detailed.Momentum.Previous()); err != nil {
+			ix.log.Error("failed to set indexer frontier", "identifier", detailed.Momentum.Previous(), "reason", err)
+			return
+		}
+	}
+	if err := ix.setFrontier(detailed.Momentum.Previous()); err != nil {
+		ix.log.Error("failed to set indexer frontier", "identifier", detailed.Momentum.Previous(), "reason", err)
+	}
+}
+
+func (ix *indexer) notify() {
+	select {
+	case ix.wake <- struct{}{}:
+	default:
+	}
+}
+func (ix *indexer) loop() {
+	for {
+		select {
+		case <-ix.closed:
+			return
+		case <-ix.wake:
+			if err := ix.sync(); err != nil {
+				ix.log.Error("failed to index momentums", "reason", err)
+			}
+		}
+	}
+}
+
+// sync indexes all momentums between the indexer frontier and the chain frontier.
+func (ix *indexer) sync() error {
+	for {
+		select {
+		case <-ix.closed:
+			return nil
+		default:
+		}
+
+		done, err := ix.indexNext()
+		if err != nil {
+			return err
+		}
+		logsDone, err := ix.indexLogsNext()
+		if err != nil || (done && logsDone) {
+			return err
+		}
+	}
+}
+
+// indexNext indexes the momentum following the indexer frontier.
+// The lock is held for a single momentum so queries are served while catching up.
+func (ix *indexer) indexNext() (bool, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	frontier := ix.frontier()
+	store := ix.chain.GetFrontierMomentumStore()
+	momentum, err := store.GetMomentumByHeight(frontier.Height + 1)
+	if err != nil {
+		return false, err
+	}
+	if momentum == nil {
+		return true, nil
+	}
+	if frontier.Height != 0 && momentum.Previous() != frontier {
+		return false, errors.Errorf("can't link momentum %v to indexer frontier %v", momentum.Identifier(), frontier)
+	}
+
+	detailed, err := store.PrefetchMomentum(momentum)
+	if err != nil {
+		return false, err
+	}
+	if err := ix.apply(detailed, func(key, value []byte) error {
+		return ix.db.Put(key, value)
+	}); err != nil {
+		return false, err
+	}
+	if err := ix.applyMomentumTime(momentum, store, func(key, value []byte) error {
+		return ix.db.Put(key, value)
+	}); err != nil {
+		return false, err
+	}
+	// the log indexes which caught up are indexed along, the others by indexLogsNext
+	for _, index := range ix.logIndexes() {
+		if ix.getFrontier(index.frontierKey) != frontier {
+			continue
+		}
+		if err := index.index(detailed); err != nil {
+			return false, err
+		}
+		if err := ix.putFrontier(index.frontierKey, momentum.Identifier()); err != nil {
+			return false, err
+		}
+	}
+	if err := ix.updateHolders(detailed, store); err != nil {
+		return false, err
+	}
+	if err := ix.setFrontier(momentum.Identifier()); err != nil {
+		return false, err
+	}
+	if momentum.Height%1000 == 0 {
+		ix.log.Info("indexed momentums", "frontier-identifier", momentum.Identifier())
+	}
+	return false, nil
+}
+
+// logIndex is an index of the events logged by the embedded contracts. Each one has its own frontier, which
+// follows the indexer frontier, so the momentums indexed before it was added are indexed for it alone.
+type logIndex struct {
+	frontierKey []byte
+	index       func(*nom.DetailedMomentum) error
+	unindex     func(*nom.DetailedMomentum) error
+}
+
+func (ix *indexer) logIndexes() []logIndex {
+	return []logIndex{
+		{supplyFrontierKey, ix.indexSupply, ix.unindexSupply},
+		{bridgeFrontierKey, ix.indexBridgeConfig, ix.unindexBridgeConfig},
+	}
+}
+
+// indexLogsNext indexes the momentum following the frontier of each log index which is behind the indexer frontier.
+func (ix *indexer) indexLogsNext() (bool, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	frontier := ix.frontier()
+	var store store.Momentum
+	done := true
+	for _, index := range ix.logIndexes() {
+		logFrontier := ix.getFrontier(index.frontierKey)
+		if logFrontier.Height >= frontier.Height {
+			continue
+		}
+		if store == nil {
+			store = ix.chain.GetFrontierMomentumStore()
+		}
+		momentum, err := store.GetMomentumByHeight(logFrontier.Height + 1)
+		if err != nil {
+			return false, err
+		}
+		if momentum == nil {
+			continue
+		}
+		if logFrontier.Height != 0 && momentum.Previous() != logFrontier {
+			return false, errors.Errorf("can't link momentum %v to indexer frontier %v", momentum.Identifier(), logFrontier)
+		}
+		detailed, err := store.PrefetchMomentum(momentum)
+		if err != nil {
+			return false, err
+		}
+		if err := index.index(detailed); err != nil {
+			return false, err
+		}
+		if err := ix.putFrontier(index.frontierKey, momentum.Identifier()); err != nil {
+			return false, err
+		}
+		done = false
+	}
+	return done, nil
+}
+
+// apply calls write with all index entries of the momentum.
+// Entries are idempotent, so a partially indexed momentum is fixed by indexing it again.
+func (ix *indexer) apply(detailed *nom.DetailedMomentum, write func(key, value []byte) error) error {
+	height := detailed.Momentum.Height
+	for _, block := range detailed.AccountBlocks {
+		blocks := []*nom.AccountBlock{block}
+		blocks = append(blocks, block.DescendantBlocks...)
+
+		for _, block := range blocks {
+			if !block.IsSendBlock() {
+				continue
+			}
+			value := block.Hash.Bytes()
+			if block.Amount != nil && block.Amount.Sign() > 0 {
+				if err := write(getTokenStandardKey(block.TokenStandard, height, block.Hash), value); err != nil {
+					return err
+				}
+			}
+			if err := write(getAddressPairKey(block.Address, block.ToAddress, height, block.Hash), value); err != nil {
+				return err
+			}
+			if types.IsEmbeddedAddress(block.ToAddress) && len(block.Data) >= selectorSize {
+				if err := write(getEmbeddedMethodKey(block.ToAddress, block.Data[:selectorSize], height, block.Hash), value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyMomentumTime calls write with the momentum time entries of the buckets which start after the previous
+// momentum, up to and including the one of the momentum. The genesis momentum has the entry of its bucket.
+func (ix *indexer) applyMomentumTime(momentum *nom.Momentum, store store.Momentum, write func(key, value []byte) error) error {
+	first := momentum.Timestamp.Unix() / momentumTimeBucket
+	if momentum.Height > 1 {
+		previous, err := store.GetMomentumByHeight(momentum.Height - 1)
+		if err != nil {
+			return err
+		}
+		if previous == nil {
+			return errors.Errorf("can't find momentum at height %v", momentum.Height-1)
+		}
+		first = previous.Timestamp.Unix()/momentumTimeBucket + 1
+	}
+	value := common.Uint64ToBytes(momentum.Height)
+	for bucket := first; bucket <= momentum.Timestamp.Unix()/momentumTimeBucket; bucket += 1 {
+		if err := write(getMomentumTimeKey(bucket*momentumTimeBucket), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateHolders writes the holder entries of all accounts which have blocks in the momentum.
+// Balances are read from the chain frontier, so the entries of an account are exact once the
+// indexer caught up, without having to restore the state of past momentums.
+func (ix *indexer) updateHolders(detailed *nom.DetailedMomentum, store store.Momentum) error {
+	addresses := make(map[types.Address]struct{})
+	for _, block := range detailed.AccountBlocks {
+		addresses[block.Address] = struct{}{}
+		for _, descendant := range block.DescendantBlocks {
+			addresses[descendant.Address] = struct{}{}
+		}
+	}
+
+	for address := range addresses {
+		balances, err := store.GetAccountStore(address).GetBalanceMap()
+		if err != nil {
+			return err
+		}
+		held, err := ix.heldTokens(address)
+		if err != nil {
+			return err
+		}
+		for zts, balance := range balances {
+			if balance.Sign() > 0 {
+				if err := ix.db.Put(getTokenHolderKey(zts, address), common.BigIntToBytes(balance)); err != nil {
+					return err
+				}
+				if err := ix.db.Put(getHolderTokenKey(address, zts), []byte{1}); err != nil {
+					return err
+				}
+				delete(held, zts)
+			}
+		}
+		// tokens which are no longer held
+		for zts := range held {
+			if err := ix.db.Delete(getTokenHolderKey(zts, address)); err != nil {
+				return err
+			}
+			if err := ix.db.Delete(getHolderTokenKey(address, zts)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// heldTokens returns the token standards for which address has holder entries.
+func (ix *indexer) heldTokens(address types.Address) (map[types.ZenonTokenStandard]struct{}, error) {
+	prefix := getHolderTokenPrefix(address)
+	iterator := ix.db.NewIterator(prefix)
+	defer iterator.Release()
+
+	held := make(map[types.ZenonTokenStandard]struct{})
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			return held, nil
+		}
+		// skip deleted entries
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		zts, err := types.BytesToZTS(iterator.Key()[len(prefix):])
+		if err != nil {
+			return nil, err
+		}
+		held[zts] = struct{}{}
+	}
+}
+
+func (ix *indexer) frontier() types.HashHeight {
+	return ix.getFrontier(frontierKey)
+}
+func (ix *indexer) setFrontier(identifier types.HashHeight) error {
+	return ix.putFrontier(frontierKey, identifier)
+}
+
+// getFrontier and putFrontier read and write the frontier of the indexer or of a log index.
+func (ix *indexer) putFrontier(key []byte, identifier types.HashHeight) error {
+	return ix.db.Put(key, identifier.Serialize())
+}
+func (ix *indexer) getFrontier(key []byte) types.HashHeight {
+	data, err := ix.db.Get(key)
+	if err == leveldb.ErrNotFound {
+		return types.HashHeight{}
+	}
+	common.DealWithErr(err)
+	frontier, err := types.DeserializeHashHeight(data)
+	common.DealWithErr(err)
+	return *frontier
+}
+
+func (ix *indexer) Frontier() types.HashHeight {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+	return ix.frontier()
+}
+
+func (ix *indexer) GetBlocksByTokenStandard(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) ([]types.Hash, bool, error) {
+	return ix.getPage(getTokenStandardPrefix(zts), pageIndex, pageSize)
+}
+func (ix *indexer) GetBlocksByAddressPair(from, to types.Address, pageIndex, pageSize uint32) ([]types.Hash, bool, error) {
+	return ix.getPage(getAddressPairPrefix(from, to), pageIndex, pageSize)
+}
+func (ix *indexer) GetBlocksByEmbeddedMethod(contract types.Address, selector []byte, pageIndex, pageSize uint32) ([]types.Hash, bool, error) {
+	if len(selector) != selectorSize {
+		return nil, false, errors.Errorf("invalid method selector size %v", len(selector))
+	}
+	return ix.getPage(getEmbeddedMethodPrefix(contract, selector), pageIndex, pageSize)
+}
+
+func (ix *indexer) getPage(prefix []byte, pageIndex, pageSize uint32) ([]types.Hash, bool, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	iterator := ix.db.NewIterator(prefix)
+	defer iterator.Release()
+
+	skip := uint64(pageIndex) * uint64(pageSize)
+	hashes := make([]types.Hash, 0, pageSize)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, false, iterator.Error()
+			}
+			return hashes, false, nil
+		}
+		// skip deleted entries
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		if skip > 0 {
+			skip -= 1
+			continue
+		}
+		if uint32(len(hashes)) == pageSize {
+			return hashes, true, nil
+		}
+		hash, err := types.BytesToHash(iterator.Value())
+		if err != nil {
+			return nil, false, err
+		}
+		hashes = append(hashes, hash)
+	}
+}
+
+func (ix *indexer) GetTokenHolders(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) ([]*TokenHolder, bool, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	prefix := getTokenHolderPrefix(zts)
+	iterator := ix.db.NewIterator(prefix)
+	defer iterator.Release()
+
+	skip := uint64(pageIndex) * uint64(pageSize)
+	holders := make([]*TokenHolder, 0, pageSize)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, false, iterator.Error()
+			}
+			return holders, false, nil
+		}
+		// skip deleted entries
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		if skip > 0 {
+			skip -= 1
+			continue
+		}
+		if uint32(len(holders)) == pageSize {
+			return holders, true, nil
+		}
+		address, err := types.BytesToAddress(iterator.Key()[len(prefix):])
+		if err != nil {
+			return nil, false, err
+		}
+		holders = append(holders, &TokenHolder{
+			Address: address,
+			Balance: common.BytesToBigInt(iterator.Value()),
+		})
+	}
+}
+
+func (ix *indexer) GetMomentumHeightByTime(timestamp int64) (uint64, bool, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	data, err := ix.db.Get(getMomentumTimeKey(timestamp / momentumTimeBucket * momentumTimeBucket))
+	if err == leveldb.ErrNotFound || (err == nil && len(data) == 0) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	// momentums of the bucket before timestamp
+	store := ix.chain.GetFrontierMomentumStore()
+	height := common.BytesToUint64(data)
+	for {
+		momentum, err := store.GetMomentumByHeight(height)
+		if err != nil {
+			return 0, false, err
+		}
+		if momentum == nil || momentum.Timestamp.Unix() >= timestamp {
+			return height, true, nil
+		}
+		height += 1
+	}
+}
@@ -0,0 +1,100 @@
+package indexer
+
+import (
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	frontierKey          = []byte{0}
+	tokenStandardPrefix  = []byte{1}
+	addressPairPrefix    = []byte{2}
+	embeddedMethodPrefix = []byte{3}
+	tokenHolderPrefix    = []byte{4}
+	holderTokenPrefix    = []byte{5}
+	momentumTimePrefix   = []byte{6}
+	supplyEventPrefix    = []byte{7}
+	supplyMomentumPrefix = []byte{8}
+	supplyFrontierKey    = []byte{9}
+	bridgeFrontierKey    = []byte{10}
+	bridgeEventPrefix    = []byte{11}
+	bridgeMomentumPrefix = []byte{12}
+)
+
+// All index keys end with the height of the momentum which confirmed the block,
+// followed by the block hash, so entries are iterated in confirmation order.
+
+func getTokenStandardPrefix(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(tokenStandardPrefix, zts.Bytes())
+}
+func getTokenStandardKey(zts types.ZenonTokenStandard, momentumHeight uint64, hash types.Hash) []byte {
+	return common.JoinBytes(getTokenStandardPrefix(zts), common.Uint64ToBytes(momentumHeight), hash.Bytes())
+}
+
+func getAddressPairPrefix(from, to types.Address) []byte {
+	return common.JoinBytes(addressPairPrefix, from.Bytes(), to.Bytes())
+}
+func getAddressPairKey(from, to types.Address, momentumHeight uint64, hash types.Hash) []byte {
+	return common.JoinBytes(getAddressPairPrefix(from, to), common.Uint64ToBytes(momentumHeight), hash.Bytes())
+}
+
+func getEmbeddedMethodPrefix(contract types.Address, selector []byte) []byte {
+	return common.JoinBytes(embeddedMethodPrefix, contract.Bytes(), selector)
+}
+func getEmbeddedMethodKey(contract types.Address, selector []byte, momentumHeight uint64, hash types.Hash) []byte {
+	return common.JoinBytes(getEmbeddedMethodPrefix(contract, selector), common.Uint64ToBytes(momentumHeight), hash.Bytes())
+}
+
+// Holder entries are keyed by token standard, then by address, and store the balance.
+// The reverse entries list the token standards held by an address.
+
+func getTokenHolderPrefix(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(tokenHolderPrefix, zts.Bytes())
+}
+func getTokenHolderKey(zts types.ZenonTokenStandard, address types.Address) []byte {
+	return common.JoinBytes(getTokenHolderPrefix(zts), address.Bytes())
+}
+
+func getHolderTokenPrefix(address types.Address) []byte {
+	return common.JoinBytes(holderTokenPrefix, address.Bytes())
+}
+func getHolderTokenKey(address types.Address, zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(getHolderTokenPrefix(address), zts.Bytes())
+}
+
+// Momentum time entries are keyed by the start of a momentumTimeBucket, in seconds, and store the height
+// of the first momentum with a timestamp greater than or equal to it.
+
+func getMomentumTimeKey(bucket int64) []byte {
+	return common.JoinBytes(momentumTimePrefix, common.Uint64ToBytes(uint64(bucket)))
+}
+
+// Supply entries are keyed by token standard, then by the height of the momentum and the hash of the
+// receive-block of the token contract, and store a SupplyEvent. The supply momentum entries list the
+// events of a momentum, so they can be un-indexed once the logs are gone.
+
+func getSupplyEventPrefix(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(supplyEventPrefix, zts.Bytes())
+}
+func getSupplyEventKey(zts types.ZenonTokenStandard, momentumHeight uint64, hash types.Hash) []byte {
+	return common.JoinBytes(getSupplyEventPrefix(zts), common.Uint64ToBytes(momentumHeight), hash.Bytes())
+}
+
+func getSupplyMomentumKey(momentumHeight uint64) []byte {
+	return common.JoinBytes(supplyMomentumPrefix, common.Uint64ToBytes(momentumHeight))
+}
+
+// Bridge entries are keyed by network class and chain id, then by the height of the momentum and the hash of
+// the receive-block of the bridge contract, and store a BridgeConfigEvent. The bridge momentum entries list the
+// events of a momentum, so they can be un-indexed once the logs are gone.
+
+func getBridgeConfigEventPrefix(networkClass, chainId uint32) []byte {
+	return common.JoinBytes(bridgeEventPrefix, common.Uint32ToBytes(networkClass), common.Uint32ToBytes(chainId))
+}
+func getBridgeConfigEventKey(networkClass, chainId uint32, momentumHeight uint64, hash types.Hash) []byte {
+	return common.JoinBytes(getBridgeConfigEventPrefix(networkClass, chainId), common.Uint64ToBytes(momentumHeight), hash.Bytes())
+}
+
+func getBridgeMomentumKey(momentumHeight uint64) []byte {
+	return common.JoinBytes(bridgeMomentumPrefix, common.Uint64ToBytes(momentumHeight))
+}
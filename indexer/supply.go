@@ -0,0 +1,274 @@
+package indexer
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+var (
+	// ErrSupplyNotSynced is returned while the supply events of old momentums are indexed, since the supply
+	// of a token is only read from the state of the last momentums.
+	ErrSupplyNotSynced = errors.New("the supply history is being indexed")
+)
+
+type SupplyEventType uint8
+
+const (
+	// SupplyGenesis is the supply of the tokens created by the genesis, indexed with the genesis momentum.
+	SupplyGenesis SupplyEventType = iota
+	SupplyIssue
+	SupplyMint
+	SupplyBurn
+)
+
+var supplyEventNames = map[SupplyEventType]string{
+	SupplyGenesis: "genesis",
+	SupplyIssue:   "issue",
+	SupplyMint:    "mint",
+	SupplyBurn:    "burn",
+}
+
+func (t SupplyEventType) String() string {
+	return supplyEventNames[t]
+}
+
+// SupplyEvent is a change of the total supply of a token standard, decoded from the logs of the token contract.
+// Hash is the receive-block of the token contract, zero for the genesis, and Address is the owner of an issued
+// token, the receiver of a mint or the burner. TotalSupply is the supply once the event is applied, it isn't
+// stored but derived from the supply of the token in the state of the contract when the history is read.
+type SupplyEvent struct {
+	TokenStandard     types.ZenonTokenStandard
+	Type              SupplyEventType
+	Hash              types.Hash
+	Address           types.Address
+	Amount            *big.Int
+	TotalSupply       *big.Int `rlp:"-"`
+	MomentumHeight    uint64
+	MomentumTimestamp uint64
+}
+
+// parseSupplyLog decodes the supply change of a log of the token contract, nil for the other events.
+func parseSupplyLog(log *nom.Log) (*SupplyEvent, error) {
+	if log.Address != types.TokenContract || len(log.Topics) != 3 {
+		return nil, nil
+	}
+	event, err := definition.ABIToken.EventById(log.Topics[0])
+	if err != nil {
+		return nil, nil
+	}
+
+	supply := new(SupplyEvent)
+	switch event.Name {
+	case definition.TokenIssuedEventName:
+		supply.Type = SupplyIssue
+		args := new(struct {
+			TotalSupply *big.Int
+			MaxSupply   *big.Int
+		})
+		err = definition.ABIToken.UnpackEvent(args, event.Name, log.Data)
+		supply.Amount = args.TotalSupply
+	case definition.TokenMintedEventName, definition.TokenBurnedEventName:
+		supply.Type = SupplyMint
+		if event.Name == definition.TokenBurnedEventName {
+			supply.Type = SupplyBurn
+		}
+		args := new(struct{ Amount *big.Int })
+		err = definition.ABIToken.UnpackEvent(args, event.Name, log.Data)
+		supply.Amount = args.Amount
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// indexed arguments are left padded to a topic
+	if supply.TokenStandard, err = types.BytesToZTS(log.Topics[1].Bytes()[types.HashSize-types.ZenonTokenStandardSize:]); err != nil {
+		return nil, err
+	}
+	if supply.Address, err = types.BytesToAddress(log.Topics[2].Bytes()[types.HashSize-types.AddressSize:]); err != nil {
+		return nil, err
+	}
+	return supply, nil
+}
+
+// supplyEvents returns the supply changes confirmed by the momentum, in confirmation order.
+// The genesis momentum has the supply of all tokens created by the genesis.
+func (ix *indexer) supplyEvents(detailed *nom.DetailedMomentum) ([]*SupplyEvent, error) {
+	momentum := detailed.Momentum
+	events := make([]*SupplyEvent, 0)
+	if momentum.Height == 1 {
+		for _, token := range ix.chain.GetGenesisTokens() {
+			events = append(events, &SupplyEvent{
+				TokenStandard: token.TokenStandard,
+				Type:          SupplyGenesis,
+				Address:       token.Owner,
+				Amount:        new(big.Int).Set(token.TotalSupply),
+			})
+		}
+	}
+
+	for _, block := range detailed.AccountBlocks {
+		if block.Address != types.TokenContract || !block.IsReceiveBlock() {
+			continue
+		}
+		logs, err := ix.chain.GetAccountBlockLogs(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			event, err := parseSupplyLog(log)
+			if err != nil {
+				return nil, err
+			}
+			if event != nil {
+				event.Hash = block.Hash
+				events = append(events, event)
+			}
+		}
+	}
+
+	for _, event := range events {
+		event.MomentumHeight = momentum.Height
+		event.MomentumTimestamp = uint64(momentum.Timestamp.Unix())
+	}
+	return events, nil
+}
+
+// indexSupply writes the supply events of the momentum.
+// The supply momentum entry is written last, a momentum which has it is already indexed.
+func (ix *indexer) indexSupply(detailed *nom.DetailedMomentum) error {
+	key := getSupplyMomentumKey(detailed.Momentum.Height)
+	if data, err := ix.db.Get(key); err == nil && len(data) != 0 {
+		return nil
+	} else if err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+
+	events, err := ix.supplyEvents(detailed)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	for _, event := range events {
+		data, err := rlp.EncodeToBytes(event)
+		if err != nil {
+			return err
+		}
+		if err := ix.db.Put(getSupplyEventKey(event.TokenStandard, event.MomentumHeight, event.Hash), data); err != nil {
+			return err
+		}
+	}
+	data, err := rlp.EncodeToBytes(events)
+	if err != nil {
+		return err
+	}
+	return ix.db.Put(key, data)
+}
+
+// unindexSupply deletes the supply events of the momentum, read from the supply momentum entry
+// since the logs of the momentum are deleted first on rollback.
+func (ix *indexer) unindexSupply(detailed *nom.DetailedMomentum) error {
+	key := getSupplyMomentumKey(detailed.Momentum.Height)
+	data, err := ix.db.Get(key)
+	if err == leveldb.ErrNotFound || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	events := make([]*SupplyEvent, 0)
+	if err := rlp.DecodeBytes(data, &events); err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := ix.db.Delete(getSupplyEventKey(event.TokenStandard, event.MomentumHeight, event.Hash)); err != nil {
+			return err
+		}
+	}
+	return ix.db.Delete(key)
+}
+
+// frontierSupply returns the supply of zts in the state of the supply frontier, zero if the token doesn't exist.
+func (ix *indexer) frontierSupply(zts types.ZenonTokenStandard) (*big.Int, error) {
+	frontier := ix.getFrontier(supplyFrontierKey)
+	if frontier.Height == 0 {
+		return big.NewInt(0), nil
+	}
+	store, err := ix.chain.GetRecentMomentumStore(frontier)
+	if err == chain.ErrStateTooOld {
+		return nil, ErrSupplyNotSynced
+	} else if err != nil {
+		return nil, err
+	} else if store == nil {
+		return nil, errors.Errorf("can't find the state of momentum %v", frontier)
+	}
+	token, err := definition.GetTokenInfo(store.GetAccountStore(types.TokenContract).Storage(), zts)
+	if err == constants.ErrDataNonExistent {
+		return big.NewInt(0), nil
+	} else if err != nil {
+		return nil, err
+	}
+	return token.TotalSupply, nil
+}
+
+// GetSupplyHistory reads all events of zts, since the TotalSupply of an event is the supply at the supply
+// frontier minus the changes made by the events after it. The supply comes from the state of the token contract,
+// so it's exact even if the events confirmed before the node recorded the logs of the embedded contracts are missing.
+func (ix *indexer) GetSupplyHistory(zts types.ZenonTokenStandard, fromTime, toTime int64) ([]*SupplyEvent, error) {
+	ix.changes.Lock()
+	defer ix.changes.Unlock()
+
+	supply, err := ix.frontierSupply(zts)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator := ix.db.NewIterator(getSupplyEventPrefix(zts))
+	defer iterator.Release()
+
+	events := make([]*SupplyEvent, 0)
+	for iterator.Next() {
+		// skip deleted entries
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		event := new(SupplyEvent)
+		if err := rlp.DecodeBytes(iterator.Value(), event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if iterator.Error() != nil {
+		return nil, iterator.Error()
+	}
+
+	for i := len(events) - 1; i >= 0; i -= 1 {
+		event := events[i]
+		event.TotalSupply = supply
+		switch event.Type {
+		case SupplyGenesis, SupplyIssue:
+			supply = big.NewInt(0)
+		case SupplyMint:
+			supply = new(big.Int).Sub(supply, event.Amount)
+		case SupplyBurn:
+			supply = new(big.Int).Add(supply, event.Amount)
+		}
+	}
+
+	// entries are ordered by height, so by timestamp
+	from := sort.Search(len(events), func(i int) bool { return int64(events[i].MomentumTimestamp) >= fromTime })
+	to := sort.Search(len(events), func(i int) bool { return int64(events[i].MomentumTimestamp) >= toTime })
+	if to < from {
+		to = from
+	}
+	return events[from:to], nil
+}
@@ -0,0 +1,208 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/metadata"
+)
+
+const (
+	// DefaultBackupsDir is relative to DataPath, it holds a directory per backup.
+	DefaultBackupsDir = "backups"
+
+	// versionFileName records the version of the last binary which opened the DataPath.
+	versionFileName = "VERSION"
+	unknownVersion  = "unknown"
+)
+
+// databaseDirs returns the LevelDB directories of the node by name, whether they exist or not. They include
+// the optional indexer, token metadata and tracer databases, and the ancient store of the momentums, which
+// is kept in a nom directory of the AncientPath.
+func (c *Config) databaseDirs() map[string]string {
+	dirs := map[string]string{
+		"nom":       filepath.Join(c.DataPath, "nom"),
+		"receipts":  filepath.Join(c.DataPath, "receipts"),
+		"consensus": filepath.Join(c.DataPath, "consensus"),
+		"light":     filepath.Join(c.DataPath, "light"),
+		"indexer":   filepath.Join(c.DataPath, "indexer"),
+		"tokenmeta": filepath.Join(c.DataPath, "tokenmeta"),
+		"tracer":    filepath.Join(c.DataPath, "tracer"),
+	}
+	if c.Database.AncientPath != "" {
+		dirs["ancient"] = filepath.Join(c.resolvePath(c.Database.AncientPath), "nom")
+	}
+	return dirs
+}
+
+func (c *Config) backupsDir() string {
+	return filepath.Join(c.DataPath, DefaultBackupsDir)
+}
+
+// readDataVersion returns the version of the last binary which opened the DataPath, empty if none did.
+// DataPaths created before the version was recorded are reported with an unknown version.
+func (c *Config) readDataVersion() (string, error) {
+	data, err := os.ReadFile(filepath.Join(c.DataPath, versionFileName))
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	for _, dir := range c.databaseDirs() {
+		if _, err := os.Stat(dir); err == nil {
+			return unknownVersion, nil
+		}
+	}
+	return "", nil
+}
+
+func (c *Config) writeDataVersion(version string) error {
+	return os.WriteFile(filepath.Join(c.DataPath, versionFileName), []byte(version+"\n"), 0600)
+}
+
+// backupOnUpgrade checkpoints the databases when the DataPath was last opened by another version
+// and records the running version. The databases must be closed.
+func (c *Config) backupOnUpgrade() error {
+	version, err := c.readDataVersion()
+	if err != nil {
+		return err
+	}
+	if version == metadata.Version {
+		return nil
+	}
+	if version != "" && c.Database.BackupOnUpgrade {
+		log.Info("backing up the databases before the upgrade", "from", version, "to", metadata.Version)
+		name, err := c.Backup(version)
+		if err != nil {
+			return errors.Errorf("failed to back up the databases of %v: %v", version, err)
+		}
+		log.Info("backed up the databases", "backup", name)
+		if err := c.pruneBackups(); err != nil {
+			return err
+		}
+	}
+	return c.writeDataVersion(metadata.Version)
+}
+
+// Backup checkpoints the databases of version into a new directory of the backups dir and returns its name.
+// The databases must be closed.
+func (c *Config) Backup(version string) (string, error) {
+	name := time.Now().UTC().Format("20060102T150405") + "-" + version
+	dir := filepath.Join(c.backupsDir(), name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	for database, path := range c.databaseDirs() {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err := db.Checkpoint(path, filepath.Join(dir, database)); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, versionFileName), []byte(version+"\n"), 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return name, nil
+}
+
+// ListBackups returns the names of the backups, oldest first.
+func (c *Config) ListBackups() ([]string, error) {
+	entries, err := os.ReadDir(c.backupsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(c.backupsDir(), entry.Name(), versionFileName)); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	// names start with the timestamp
+	sort.Strings(names)
+	return names, nil
+}
+
+func (c *Config) pruneBackups() error {
+	if c.Database.MaxBackups <= 0 {
+		return nil
+	}
+	names, err := c.ListBackups()
+	if err != nil {
+		return err
+	}
+	for len(names) > c.Database.MaxBackups {
+		log.Info("removing old backup", "backup", names[0])
+		if err := os.RemoveAll(filepath.Join(c.backupsDir(), names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// RollbackToBackup replaces the databases with the ones of the backup name and returns the version which made it.
+// The databases absent from the backup are removed, the backup itself is left untouched.
+// The node must be stopped, the DataPath is locked during the rollback.
+func (c *Config) RollbackToBackup(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", errors.Errorf("invalid backup name %q", name)
+	}
+	dir := filepath.Join(c.backupsDir(), name)
+	data, err := os.ReadFile(filepath.Join(dir, versionFileName))
+	if err != nil {
+		return "", errors.Errorf("backup %v not found in %v", name, c.backupsDir())
+	}
+	version := strings.TrimSpace(string(data))
+
+	lock, err := lockDataDir(c.DataPath)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	// restore next to the current databases first, so they are left untouched if it fails
+	dirs := c.databaseDirs()
+	restored := make(map[string]string)
+	for database, path := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, database)); os.IsNotExist(err) {
+			continue
+		}
+		restored[database] = path + ".restore"
+		err := os.RemoveAll(restored[database])
+		if err == nil {
+			err = db.Checkpoint(filepath.Join(dir, database), restored[database])
+		}
+		if err != nil {
+			for _, restore := range restored {
+				_ = os.RemoveAll(restore)
+			}
+			return "", errors.Errorf("failed to restore %v: %v", database, err)
+		}
+	}
+	for database, path := range dirs {
+		if err := os.RemoveAll(path); err != nil {
+			return "", err
+		}
+		if restore, ok := restored[database]; ok {
+			if err := os.Rename(restore, path); err != nil {
+				return "", err
+			}
+		}
+	}
+	return version, c.writeDataVersion(version)
+}
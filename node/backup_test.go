@@ -0,0 +1,89 @@
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func writeTestDB(t *testing.T, path string, value string) {
+	ldb, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ldb.Close()
+	if err := ldb.Put([]byte("key"), []byte(value), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestDB(t *testing.T, path string) string {
+	ldb, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ldb.Close()
+	value, err := ldb.Get([]byte("key"), nil)
+	if err != nil {
+		t.Fatalf("%v: %v", path, err)
+	}
+	return string(value)
+}
+
+// Test backup and rollback
+//   - test every database is checkpointed, including the ancient momentums kept in AncientPath/nom
+//   - test the rollback restores them and returns the version of the backup
+//   - test the databases created after the backup are removed by the rollback
+func TestConfig_BackupRollback(t *testing.T) {
+	c := &Config{DataPath: t.TempDir()}
+	c.Database.AncientPath = "ancient"
+	dirs := c.databaseDirs()
+	if ancient := filepath.Join(c.DataPath, "ancient", "nom"); dirs["ancient"] != ancient {
+		t.Fatalf("ancient database at %v, expected %v", dirs["ancient"], ancient)
+	}
+
+	for database, path := range dirs {
+		if database == "tracer" {
+			continue
+		}
+		writeTestDB(t, path, database+"-v1")
+	}
+	name, err := c.Backup("v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for database, path := range dirs {
+		writeTestDB(t, path, database+"-v2")
+	}
+
+	backups, err := c.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0] != name {
+		t.Fatalf("listed backups %v, expected %v", backups, name)
+	}
+	version, err := c.RollbackToBackup(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1" {
+		t.Fatalf("rolled back to version %v, expected v1", version)
+	}
+	for database, path := range dirs {
+		if database == "tracer" {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Fatalf("tracer database absent from the backup wasn't removed: %v", err)
+			}
+			continue
+		}
+		if value := readTestDB(t, path); value != database+"-v1" {
+			t.Fatalf("%v has %v after the rollback, expected %v-v1", database, value, database)
+		}
+	}
+	if data, err := c.readDataVersion(); err != nil || data != "v1" {
+		t.Fatalf("data version %v after the rollback: %v", data, err)
+	}
+}
@@ -0,0 +1,48 @@
+package node
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/db"
+)
+
+// CompactDatabases compacts the existing databases of names, all of them if names is empty, calling
+// compacted after each one. The databases are the ones backed up, see databaseDirs. The node must be
+// stopped, the DataPath is locked during the compaction.
+func (c *Config) CompactDatabases(names []string, compacted func(name string, elapsed time.Duration)) error {
+	dirs := c.databaseDirs()
+	if len(names) == 0 {
+		for name := range dirs {
+			if _, err := os.Stat(dirs[name]); err == nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if _, ok := dirs[name]; !ok {
+			return errors.Errorf("unknown database %q", name)
+		}
+	}
+
+	lock, err := lockDataDir(c.DataPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	for _, name := range names {
+		start := time.Now()
+		if err := db.CompactDir(dirs[name]); err != nil {
+			return errors.Errorf("failed to compact %v: %v", name, err)
+		}
+		if compacted != nil {
+			compacted(name, time.Since(start))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test CompactDatabases
+//   - test every existing database is compacted, including the ancient momentums kept in AncientPath/nom
+//   - test the databases which don't exist are skipped and their data is kept
+//   - test an unknown database is refused
+func TestConfig_CompactDatabases(t *testing.T) {
+	c := &Config{DataPath: t.TempDir()}
+	c.Database.AncientPath = "ancient"
+	dirs := c.databaseDirs()
+	for database, path := range dirs {
+		if database == "tracer" {
+			continue
+		}
+		writeTestDB(t, path, database)
+	}
+
+	var compacted []string
+	if err := c.CompactDatabases(nil, func(name string, elapsed time.Duration) {
+		compacted = append(compacted, name)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var expected []string
+	for database := range dirs {
+		if database != "tracer" {
+			expected = append(expected, database)
+		}
+	}
+	sort.Strings(expected)
+	if strings.Join(compacted, ",") != strings.Join(expected, ",") {
+		t.Fatalf("compacted %v, expected %v", compacted, expected)
+	}
+	if value := readTestDB(t, filepath.Join(c.DataPath, "ancient", "nom")); value != "ancient" {
+		t.Fatalf("ancient database has %v after the compaction", value)
+	}
+
+	compacted = nil
+	if err := c.CompactDatabases([]string{"ancient"}, func(name string, elapsed time.Duration) {
+		compacted = append(compacted, name)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(compacted) != 1 || compacted[0] != "ancient" {
+		t.Fatalf("compacted %v, expected ancient", compacted)
+	}
+
+	if err := c.CompactDatabases([]string{"unknown"}, nil); err == nil || !strings.Contains(err.Error(), "unknown database") {
+		t.Fatalf("unknown database accepted: %v", err)
+	}
+}
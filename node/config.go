@@ -1,17 +1,28 @@
 package node
 
 import (
+	"crypto/rand"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/genesis"
 	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/pillar"
+	rpcapi "github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/embedded/bridge"
 	"github.com/zenon-network/go-zenon/wallet"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -21,7 +32,54 @@ type ProducerConfig struct {
 	Index       uint32
 	KeyFilePath string
 	Password    string
+
+	// SignerAddress points to a remote signer which holds the key of Address, the key file isn't used then.
+	// It's either unix:<path> for a local socket or host:port, see the signer command.
+	SignerAddress string
+	// SignerSecretFile holds the secret the node authenticates to the remote signer with, on its first line.
+	SignerSecretFile string
+
+	// LeaseAddress points to the lease server shared by the nodes which produce for Address, a node only
+	// produces while it holds the lease, see the lease command. It's either unix:<path> or host:port.
+	LeaseAddress string
+	// LeaseSecretFile holds the secret the node authenticates to the lease server with, on its first line.
+	LeaseSecretFile string
+	// Backup makes the node stand by while the primary, which shares LeaseAddress and doesn't set Backup,
+	// renews the lease. It takes over once the lease expires, see pillar.Manager.SetLease.
+	Backup bool
+}
+type BridgeConfig struct {
+	// The account which publishes the signatures of the wrap requests, it needs fused plasma.
+	Address     string
+	Index       uint32
+	KeyFilePath string
+	Password    string
+
+	// TssKeyFile holds the hex encoded TSS key of a local signer, which is meant for test networks.
+	// SignerURL points to a remote signer (HSM or TSS coordinator) instead. If neither is set the
+	// orchestrator must register its signer with the node.
+	TssKeyFile string
+	SignerURL  string
+}
+
+// RPCMethodFilter entries are method names (e.g. "ledger.getFrontierMomentum") or wildcards matching
+// all methods of a namespace and of the namespaces nested under it (e.g. "ledger.*"). Subscriptions are
+// named by their namespace and name (e.g. "ledger.momentums"). All methods are allowed if Allowed is
+// empty, Denied methods are never served.
+type RPCMethodFilter struct {
+	Allowed []string
+	Denied  []string
 }
+
+// APIKeyConfig is a key of the clients of the HTTP and WS endpoints, sent in the X-API-Key header. Methods
+// restricts the methods it can call and RateLimit its calls per second, zero is unlimited.
+type APIKeyConfig struct {
+	Name      string
+	Key       string
+	Methods   RPCMethodFilter
+	RateLimit int
+}
+
 type RPCConfig struct {
 	EnableHTTP bool
 	EnableWS   bool
@@ -31,11 +89,162 @@ type RPCConfig struct {
 	WSHost   string
 	WSPort   int
 
-	Endpoints []string
+	// Endpoints whitelists the exposed namespaces, all public ones are exposed if empty.
+	// DisabledEndpoints are never exposed, regardless of the whitelist.
+	Endpoints         []string
+	DisabledEndpoints []string
+
+	// HTTPMethods, WSMethods and IPCMethods restrict the methods served by each transport,
+	// on top of the exposed namespaces.
+	HTTPMethods RPCMethodFilter
+	WSMethods   RPCMethodFilter
+	IPCMethods  RPCMethodFilter
 
 	HTTPVirtualHosts []string
 	HTTPCors         []string
 	WSOrigins        []string
+
+	// EnableWallet exposes the wallet namespace on the IPC endpoint only, so EnableIPC is needed. It is never
+	// served over HTTP or WS, which any web page open in a browser of the host can call.
+	EnableWallet bool
+
+	// TLSCertFile and TLSKeyFile enable TLS on the HTTP and WS endpoints.
+	// Paths are relative to DataPath if not absolute.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// AuthTokens are the bearer tokens accepted by the HTTP and WS endpoints. JWTSecretFile holds
+	// a hex encoded secret, bearer JWTs signed with it using HS256 are accepted as well. Requests
+	// are not authenticated if neither is set.
+	AuthTokens    []string
+	JWTSecretFile string
+
+	// APIKeys are the keys of the tenants of a public node, more of them can be added with admin.addApiKey.
+	// Once there are keys the calls without one are rejected, unless AllowAnonymous is set. They are limited
+	// to AnonymousRateLimit calls per second then, shared by all the clients without a key.
+	APIKeys            []APIKeyConfig
+	AllowAnonymous     bool
+	AnonymousRateLimit int
+
+	// HealthMinPeers and HealthMaxMomentumAge (in seconds) are the thresholds of the /health endpoint
+	// and of stats.health, nodes below them are reported as unhealthy. Zero disables the check.
+	HealthMinPeers       int
+	HealthMaxMomentumAge int
+
+	// SlowQueryThreshold (in milliseconds) is the duration above which the RPC calls are logged as slow
+	// queries and kept for stats.rpcMetrics. Zero disables the slow-query log.
+	SlowQueryThreshold int
+
+	// IPCPath is relative to DataPath if not absolute, on Windows it names a pipe instead.
+	EnableIPC bool
+	IPCPath   string
+}
+type PoWConfig struct {
+	// Enabled serves utilities.generatePoW, which generates PoW for the rpc clients. The other utilities
+	// are always served.
+	Enabled bool
+
+	// MaxJobs caps the concurrent jobs, each of them busies one CPU core.
+	// MaxQueuedJobs wait for a free worker, further jobs are rejected.
+	MaxJobs       int
+	MaxQueuedJobs int
+}
+type DebugConfig struct {
+	// EnablePprof starts the pprof HTTP server, it should be bound to a loopback host.
+	EnablePprof bool
+	PprofHost   string
+	PprofPort   int
+
+	// ProfilesPath is relative to DataPath if not absolute. Profiles captured over
+	// the debug namespace or on SIGUSR1 (CPU) and SIGUSR2 (heap) are written there.
+	ProfilesPath string
+
+	// EnableTracer records the embedded contract calls of the applied account-blocks, see vm.Tracer.
+	EnableTracer bool
+
+	// TracingEndpoint is the OTLP/HTTP collector, like http://localhost:4318, the spans of the RPC calls and of
+	// the chain and vm work they trigger are exported to. The W3C traceparent header of HTTP requests is honoured.
+	TracingEndpoint string
+
+	// ShutdownTimeout (in seconds) is the time the node has to stop. Past it, the goroutine stacks and the state
+	// of the p2p loop are written to a dump in ProfilesPath and the process exits. Zero waits forever.
+	ShutdownTimeout int
+}
+type DatabaseConfig struct {
+	// AncientPath keeps the momentums and account-blocks older than AncientThreshold momentums,
+	// so it can be on slower storage than DataPath. It's relative to DataPath if not absolute,
+	// empty keeps everything in DataPath. Once set, the node can't start without it.
+	AncientPath      string
+	AncientThreshold uint64
+
+	// CacheSize is the number of recently read momentums, account-blocks and token infos each kept in memory,
+	// zero disables the cache.
+	CacheSize int
+
+	// CompactionWindow is a daily time range in UTC, like "02:00-04:00", during which the running node
+	// compacts its databases once, so LevelDB doesn't compact them while momentums are inserted at busy
+	// times. Empty disables it, see also the db compact command.
+	CompactionWindow string
+
+	// BackupOnUpgrade checkpoints the databases in DataPath/backups when the node is started by another
+	// version, before they are opened, see RollbackToBackup. Only the MaxBackups most recent are kept,
+	// all of them if zero. The table files are hard-linked, a backup costs little disk space until
+	// they are compacted away.
+	BackupOnUpgrade bool
+	MaxBackups      int
+}
+type RewardsConfig struct {
+	// AutoCollect submits CollectReward transactions for the producer address once its uncollected
+	// pillar, sentinel, stake or liquidity rewards reach ZnnThreshold or QsrThreshold (in base units,
+	// zero ignores the token). They are checked every Interval seconds.
+	AutoCollect  bool
+	ZnnThreshold uint64
+	QsrThreshold uint64
+	Interval     int
+}
+type AutoReceiveConfig struct {
+	// Enabled receives the blocks sent to Addresses while they are derived from a key store unlocked over the
+	// wallet namespace, so RPC.EnableWallet is needed. The unreceived blocks are checked every Interval seconds
+	// and at most MaxBlocks receive blocks are published each time. PoW makes up for the missing fused plasma,
+	// the PoW runs on the pool of PoW.MaxJobs if PoW.Enabled, otherwise the blocks wait for plasma.
+	Enabled   bool
+	Addresses []string
+	Interval  int
+	MaxBlocks int
+	PoW       bool
+}
+type ReplicaConfig struct {
+	// PrimaryDataPath is the DataPath of another znnd on the same host. If set, the node serves the RPC from copies
+	// of its databases, refreshed every RefreshInterval seconds, instead of syncing from the network.
+	// Replicas don't connect to peers and can't produce, run a bridge or publish account-blocks.
+	PrimaryDataPath string
+	RefreshInterval int
+}
+type ClockConfig struct {
+	// NTPServer is queried every 10 minutes for the drift of the local clock, empty disables the check.
+	NTPServer string
+	// DriftWarning (in milliseconds) is the drift of the local clock, measured with NTPServer or against the
+	// timestamps of the momentums, past which warnings are logged. Zero disables the warnings.
+	DriftWarning int
+	// MaxProducerDrift (in milliseconds) is the NTP drift past which the pillar stops producing momentums,
+	// which would be rejected or too late otherwise. Zero disables the check.
+	MaxProducerDrift int
+}
+type LogConfig struct {
+	// ModuleLevels overrides LogLevel for the given modules, for example {"p2p": "debug"}.
+	// They can be changed at runtime over the debug namespace.
+	ModuleLevels map[string]string
+
+	// JSON writes the records as JSON objects instead of logfmt.
+	JSON bool
+
+	// Files are rotated once they reach MaxSize megabytes or every RotateHours, if set.
+	// Rotated files are removed after MaxAge days or once there are more than MaxBackups.
+	MaxSize     int
+	MaxBackups  int
+	MaxAge      int
+	RotateHours int
+	Compress    bool
 }
 type NetConfig struct {
 	ListenHost string
@@ -45,8 +254,49 @@ type NetConfig struct {
 	MinConnectedPeers int
 	MaxPeers          int
 	MaxPendingPeers   int
+	MaxInboundPeers   int
+
+	// MaxPeersPerSubnet limits the peers of a /24 (/64 for IPv6) subnet and MaxInboundPerIP
+	// the inbound connections of an IP, so a single operator can't eclipse the node.
+	MaxPeersPerSubnet int
+	MaxInboundPerIP   int
+
+	// HandshakeTimeout (in seconds) is the time budget of a new connection to complete its handshakes.
+	HandshakeTimeout int
+
+	// MaxUploadRate and MaxDownloadRate (in KiB per second) cap the bandwidth of all the peers together,
+	// MaxPeerUploadRate and MaxPeerDownloadRate the one of each peer. Zero means no limit.
+	MaxUploadRate       int
+	MaxDownloadRate     int
+	MaxPeerUploadRate   int
+	MaxPeerDownloadRate int
 
 	Seeders []string
+
+	// Capabilities are advertised in the discovery DHT, see p2p.CapabilityArchival and co.
+	Capabilities []string
+
+	// PropagationDiversity is the percentage of the momentum propagation slots which go to peers
+	// from distinct networks, the others go to the peers with the lowest latency.
+	PropagationDiversity int
+
+	// Proxy is a SOCKS5 proxy for the outbound connections, like socks5://127.0.0.1:9050 for Tor.
+	// Discovery and the inbound connections are disabled when set and peers are dialed from the seeders.
+	Proxy string
+
+	// Sentries turn on the sentry mode of a producing node, which then only peers with these relay nodes.
+	Sentries []string
+
+	// PrivatePeers are set on the sentry nodes to the producing node they relay for.
+	PrivatePeers []string
+
+	// AEADFrames seals the peer connections with ChaCha20-Poly1305 instead of AES-CTR and MACs,
+	// with the peers which enable it as well.
+	AEADFrames bool
+
+	// Telemetry advertises the client version, the OS and architecture and whether the node is archival
+	// in the protocol handshake, so the network crawlers can measure the adoption of the releases. Opt-in.
+	Telemetry bool
 }
 
 type Config struct {
@@ -57,10 +307,33 @@ type Config struct {
 	Name string
 
 	LogLevel string // "debug", "dbug" | "info" | "warn" | "error", "error" | "crit"
+	Log      LogConfig
 
 	Producer *ProducerConfig
+	Bridge   *BridgeConfig
 	RPC      RPCConfig
 	Net      NetConfig
+	PoW      PoWConfig
+	Debug    DebugConfig
+	Database DatabaseConfig
+	Rewards  RewardsConfig
+	Replica  ReplicaConfig
+	Clock    ClockConfig
+
+	AutoReceive AutoReceiveConfig
+
+	EnableIndexer bool // EnableIndexer builds the secondary indexes served by the indexer RPC namespace
+
+	// EnableTokenMetadata fetches the logos and websites published by the domains of the tokens, once verified
+	// they are returned along with the tokens by the RPC.
+	EnableTokenMetadata bool
+
+	// Light syncs and verifies only the momentum headers, the account states are fetched from the peers on demand.
+	// Light nodes serve a subset of the ledger and stats RPC namespaces and can't produce or index.
+	Light bool
+
+	// Dev runs a single node developer network, see SetupDev.
+	Dev DevConfig
 }
 
 func (c *Config) MakePathsAbsolute() error {
@@ -98,17 +371,61 @@ func (c *Config) MakePathsAbsolute() error {
 }
 
 func (c *Config) makeZenonConfig(walletManager *wallet.Manager) (*zenon.Config, error) {
-	pillarCoinbase, err := c.parseProducer(walletManager)
+	if err := c.checkDatabase(); err != nil {
+		return nil, err
+	}
+	producerSigner, err := c.parseProducer(walletManager)
+	if err != nil {
+		return nil, err
+	}
+	bridgeKeyPair, bridgeSigner, err := c.parseBridge(walletManager)
+	if err != nil {
+		return nil, err
+	}
+	rewardCollection, err := c.parseRewards(producerSigner)
+	if err != nil {
+		return nil, err
+	}
+	producerLease, backupProducer, err := c.parseLease()
+	if err != nil {
+		return nil, err
+	}
+	replicaSource, replicaInterval, err := c.parseReplica()
+	if err != nil {
+		return nil, err
+	}
+	compactionWindow, err := c.parseCompactionWindow()
 	if err != nil {
 		return nil, err
 	}
 
 	return &zenon.Config{
-		MinPeers:          c.Net.MinPeers,
-		MinConnectedPeers: c.Net.MinConnectedPeers,
-		ProducingKeyPair:  pillarCoinbase,
-		GenesisConfig:     c.makeGenesisConfig(),
-		DataDir:           c.DataPath,
+		MinPeers:             c.Net.MinPeers,
+		MinConnectedPeers:    c.Net.MinConnectedPeers,
+		PropagationDiversity: c.Net.PropagationDiversity,
+		HealthMinPeers:       c.RPC.HealthMinPeers,
+		HealthMaxMomentumAge: time.Duration(c.RPC.HealthMaxMomentumAge) * time.Second,
+		ProducerSigner:       producerSigner,
+		ProducerLease:        producerLease,
+		BackupProducer:       backupProducer,
+		SkipEmptyMomentums:   c.Dev.Enabled && c.Dev.Period == 0,
+		RewardCollection:     rewardCollection,
+		GenesisConfig:        c.makeGenesisConfig(),
+		DataDir:              c.DataPath,
+		EnableIndexer:        c.EnableIndexer,
+		EnableTokenMetadata:  c.EnableTokenMetadata,
+		EnableTracer:         c.Debug.EnableTracer,
+		AncientDir:           c.resolvePath(c.Database.AncientPath),
+		AncientThreshold:     c.Database.AncientThreshold,
+		ChainCacheSize:       c.Database.CacheSize,
+		CompactionWindow:     compactionWindow,
+		BridgeKeyPair:        bridgeKeyPair,
+		BridgeSigner:         bridgeSigner,
+		ReplicaSource:        replicaSource,
+		ReplicaInterval:      replicaInterval,
+		NTPServer:            c.Clock.NTPServer,
+		ClockDriftWarning:    time.Duration(c.Clock.DriftWarning) * time.Millisecond,
+		MaxProducerDrift:     time.Duration(c.Clock.MaxProducerDrift) * time.Millisecond,
 	}, nil
 }
 func (c *Config) makeGenesisConfig() (genesisConfig store.Genesis) {
@@ -140,50 +457,294 @@ func (c *Config) makeGenesisConfig() (genesisConfig store.Genesis) {
 		return
 	}
 }
-func (c *Config) parseProducer(walletManager *wallet.Manager) (*wallet.KeyPair, error) {
+
+// OpenChain opens the chain stored in the data directory, for commands which run without a node.
+// The node must not be running since the database can be opened by a single process.
+func (c *Config) OpenChain() (chain.Chain, error) {
+	if err := c.checkDatabase(); err != nil {
+		return nil, err
+	}
+	zenonConfig := &zenon.Config{
+		DataDir:          c.DataPath,
+		AncientDir:       c.resolvePath(c.Database.AncientPath),
+		AncientThreshold: c.Database.AncientThreshold,
+	}
+	// commands run without a node only read the chain, logs are never inserted
+	ch := chain.NewChain(zenonConfig.NewDBManager("nom"), db.NewMemDB(), c.makeGenesisConfig())
+	if err := ch.Init(); err != nil {
+		_ = ch.Stop()
+		return nil, err
+	}
+	return ch, nil
+}
+func (c *Config) checkDatabase() error {
+	if c.Database.AncientPath != "" && c.Database.AncientThreshold < db.MinAncientThreshold {
+		return errors.Errorf("ancient threshold must be at least %v momentums", db.MinAncientThreshold)
+	}
+	if c.Database.CacheSize < 0 {
+		return errors.Errorf("cache size can't be negative")
+	}
+	_, err := c.parseCompactionWindow()
+	return err
+}
+func (c *Config) parseCompactionWindow() (*zenon.CompactionWindow, error) {
+	if c.Database.CompactionWindow == "" {
+		return nil, nil
+	}
+	return zenon.ParseCompactionWindow(c.Database.CompactionWindow)
+}
+func (c *Config) parseProducer(walletManager *wallet.Manager) (pillar.Signer, error) {
+	if c.Dev.Enabled {
+		return pillar.NewLocalSigner(DevKeyPairs()[0]), nil
+	}
+	if c.Producer == nil {
+		return nil, nil
+	}
+	if c.Producer.SignerAddress != "" {
+		if c.Producer.KeyFilePath != "" {
+			return nil, errors.Errorf("only one of the producer KeyFilePath and SignerAddress can be set")
+		}
+		address, err := types.ParseAddress(c.Producer.Address)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse producer address. Reason:%w", err)
+		}
+		if c.Producer.SignerSecretFile == "" {
+			return nil, errors.Errorf("the producer SignerSecretFile must be set with SignerAddress")
+		}
+		secret, err := pillar.ReadSecretFile(c.resolvePath(c.Producer.SignerSecretFile))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the producer signer secret. Reason:%w", err)
+		}
+		return pillar.NewRemoteSigner(c.Producer.SignerAddress, address, secret), nil
+	}
+	keyPair, err := unlockKeyPair(walletManager, "producer", c.Producer.KeyFilePath, c.Producer.Password, c.Producer.Address, c.Producer.Index)
+	if err != nil {
+		return nil, err
+	}
+	return pillar.NewLocalSigner(keyPair), nil
+}
+func (c *Config) parseLease() (pillar.Lease, bool, error) {
 	if c.Producer == nil {
+		return nil, false, nil
+	}
+	if c.Producer.LeaseAddress == "" {
+		if c.Producer.Backup {
+			return nil, false, errors.Errorf("a backup producer needs the LeaseAddress of the lease server")
+		}
+		return nil, false, nil
+	}
+	if c.Dev.Enabled {
+		return nil, false, errors.Errorf("the developer mode can't share the producer lease")
+	}
+	if c.Producer.LeaseSecretFile == "" {
+		return nil, false, errors.Errorf("the producer LeaseSecretFile must be set with LeaseAddress")
+	}
+	secret, err := pillar.ReadSecretFile(c.resolvePath(c.Producer.LeaseSecretFile))
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to read the producer lease secret. Reason:%w", err)
+	}
+	// the holder only needs to tell the nodes apart, the random suffix covers nodes sharing a hostname
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, false, err
+	}
+	holder := fmt.Sprintf("%v-%x", hostname, suffix)
+	return pillar.NewRemoteLease(c.Producer.LeaseAddress, holder, secret), c.Producer.Backup, nil
+}
+func (c *Config) parseRewards(producer pillar.Signer) (*pillar.RewardCollection, error) {
+	if !c.Rewards.AutoCollect {
+		return nil, nil
+	}
+	if producer == nil {
+		return nil, errors.Errorf("automatic reward collection needs a producer")
+	}
+	if c.Rewards.Interval <= 0 {
+		return nil, errors.Errorf("reward collection interval must be positive")
+	}
+	if c.Rewards.ZnnThreshold == 0 && c.Rewards.QsrThreshold == 0 {
+		return nil, errors.Errorf("at least one reward collection threshold must be set")
+	}
+	return &pillar.RewardCollection{
+		ZnnThreshold: new(big.Int).SetUint64(c.Rewards.ZnnThreshold),
+		QsrThreshold: new(big.Int).SetUint64(c.Rewards.QsrThreshold),
+		Interval:     time.Duration(c.Rewards.Interval) * time.Second,
+	}, nil
+}
+func (c *Config) parseReplica() (string, time.Duration, error) {
+	if c.Replica.PrimaryDataPath == "" {
+		return "", 0, nil
+	}
+	source, err := filepath.Abs(c.Replica.PrimaryDataPath)
+	if err != nil {
+		return "", 0, err
+	}
+	switch {
+	case source == c.DataPath:
+		return "", 0, errors.Errorf("replica data path must differ from the primary data path")
+	case c.Producer != nil || c.Dev.Enabled:
+		return "", 0, errors.Errorf("replicas can't produce momentums")
+	case c.Bridge != nil:
+		return "", 0, errors.Errorf("replicas can't run a bridge orchestrator")
+	case c.Database.AncientPath != "":
+		return "", 0, errors.Errorf("replicas don't support an ancient store")
+	case c.Replica.RefreshInterval <= 0:
+		return "", 0, errors.Errorf("replica refresh interval must be positive")
+	}
+	return source, time.Duration(c.Replica.RefreshInterval) * time.Second, nil
+}
+func (c *Config) parseAutoReceive() (*rpcapi.AutoReceiveConfig, error) {
+	if !c.AutoReceive.Enabled {
 		return nil, nil
 	}
+	switch {
+	case c.Light:
+		return nil, errors.Errorf("light nodes can't publish account-blocks")
+	case c.Replica.PrimaryDataPath != "":
+		return nil, errors.Errorf("replicas can't publish account-blocks")
+	case !c.RPC.EnableWallet:
+		return nil, errors.Errorf("the addresses are unlocked over the wallet apis, which aren't enabled")
+	case len(c.AutoReceive.Addresses) == 0:
+		return nil, errors.Errorf("no address to receive the blocks of")
+	case c.AutoReceive.Interval <= 0:
+		return nil, errors.Errorf("auto-receive interval must be positive")
+	case c.AutoReceive.MaxBlocks <= 0:
+		return nil, errors.Errorf("auto-receive max blocks must be positive")
+	}
+	addresses := make([]types.Address, 0, len(c.AutoReceive.Addresses))
+	for _, addressStr := range c.AutoReceive.Addresses {
+		address, err := types.ParseAddress(addressStr)
+		if err != nil {
+			return nil, errors.Errorf("invalid address %q: %v", addressStr, err)
+		}
+		addresses = append(addresses, address)
+	}
+	return &rpcapi.AutoReceiveConfig{
+		Addresses: addresses,
+		Interval:  time.Duration(c.AutoReceive.Interval) * time.Second,
+		MaxBlocks: c.AutoReceive.MaxBlocks,
+		PoW:       c.AutoReceive.PoW,
+	}, nil
+}
+func (c *Config) parseBridge(walletManager *wallet.Manager) (*wallet.KeyPair, bridge.Signer, error) {
+	if c.Bridge == nil {
+		return nil, nil, nil
+	}
+	keyPair, err := unlockKeyPair(walletManager, "bridge", c.Bridge.KeyFilePath, c.Bridge.Password, c.Bridge.Address, c.Bridge.Index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case c.Bridge.TssKeyFile != "" && c.Bridge.SignerURL != "":
+		return nil, nil, errors.Errorf("only one of the bridge TssKeyFile and SignerURL can be set")
+	case c.Bridge.TssKeyFile != "":
+		signer, err := bridge.NewLocalSignerFromFile(c.resolvePath(c.Bridge.TssKeyFile))
+		if err != nil {
+			return nil, nil, err
+		}
+		return keyPair, signer, nil
+	case c.Bridge.SignerURL != "":
+		return keyPair, bridge.NewRemoteSigner(c.Bridge.SignerURL), nil
+	default:
+		return keyPair, nil, nil
+	}
+}
 
+// unlockKeyPair unlocks the key file and derives the key pair of the address at index, role names it in the errors.
+func unlockKeyPair(walletManager *wallet.Manager, role, keyFilePath, password, addressStr string, index uint32) (*wallet.KeyPair, error) {
 	// Unlock in wallet
-	if _, err := walletManager.GetKeyFile(c.Producer.KeyFilePath); err != nil {
-		log.Error("unable to get keyFile", "keyFilePath", c.Producer.KeyFilePath, "reason", err)
+	if _, err := walletManager.GetKeyFile(keyFilePath); err != nil {
+		log.Error("unable to get keyFile", "keyFilePath", keyFilePath, "reason", err)
 		return nil, err
 	}
-	if err := walletManager.Unlock(c.Producer.KeyFilePath, c.Producer.Password); err != nil {
-		log.Error("unable to unlock keyFile", "keyFilePath", c.Producer.KeyFilePath, "reason", err)
+	if err := walletManager.Unlock(keyFilePath, password); err != nil {
+		log.Error("unable to unlock keyFile", "keyFilePath", keyFilePath, "reason", err)
 		return nil, err
 	}
 
 	// check address field is set & parse it
-	if c.Producer.Address == "" {
-		return nil, fmt.Errorf("unable to parse producer address. Reason:missing")
+	if addressStr == "" {
+		return nil, fmt.Errorf("unable to parse %v address. Reason:missing", role)
 	}
-	address, err := types.ParseAddress(c.Producer.Address)
+	address, err := types.ParseAddress(addressStr)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse producer address. Reason:%w", err)
+		return nil, fmt.Errorf("unable to parse %v address. Reason:%w", role, err)
 	}
 
 	// get keyStore which should already be unlocked
-	keyStore, err := walletManager.GetKeyStore(c.Producer.KeyFilePath)
+	keyStore, err := walletManager.GetKeyStore(keyFilePath)
 	if err != nil {
 		return nil, err
 	}
 
-	// derive coinbase
-	_, keyPair, err := keyStore.DeriveForIndexPath(c.Producer.Index)
+	// derive key pair
+	_, keyPair, err := keyStore.DeriveForIndexPath(index)
 	if err != nil {
 		return nil, err
 	}
 
 	// make sure address matches
 	if keyPair.Address != address {
-		return nil, errors.Errorf("producer address doesn't match. Expected %v but got %v", address, keyPair.Address)
+		return nil, errors.Errorf("%v address doesn't match. Expected %v but got %v", role, address, keyPair.Address)
 	}
 
 	return keyPair, nil
 }
 
+// IPCEndpoint resolves the IPC endpoint based on the configured IPC path.
+func (c *Config) IPCEndpoint() string {
+	if c.RPC.IPCPath == "" {
+		return ""
+	}
+	// On Windows we can only use plain top-level pipes
+	if runtime.GOOS == "windows" {
+		if strings.HasPrefix(c.RPC.IPCPath, `\\.\pipe\`) {
+			return c.RPC.IPCPath
+		}
+		return `\\.\pipe\` + c.RPC.IPCPath
+	}
+	// Resolve names into the data directory
+	if filepath.Base(c.RPC.IPCPath) == c.RPC.IPCPath {
+		return filepath.Join(c.DataPath, c.RPC.IPCPath)
+	}
+	return ReplaceHomeVariable(c.RPC.IPCPath)
+}
+
+// ProfilesDir resolves the directory in which the profiles are written.
+func (c *Config) ProfilesDir() string {
+	path := ReplaceHomeVariable(c.Debug.ProfilesPath)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.DataPath, path)
+}
+
+// resolvePath resolves the path relative to DataPath if not absolute. Empty paths stay empty.
+func (c *Config) resolvePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	path = ReplaceHomeVariable(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.DataPath, path)
+}
+
+// MakeLogConfig returns the configuration of the log files.
+func (c *Config) MakeLogConfig() common.LogConfig {
+	return common.LogConfig{
+		Level:          c.LogLevel,
+		ModuleLevels:   c.Log.ModuleLevels,
+		JSON:           c.Log.JSON,
+		MaxSize:        c.Log.MaxSize,
+		MaxBackups:     c.Log.MaxBackups,
+		MaxAge:         c.Log.MaxAge,
+		Compress:       c.Log.Compress,
+		RotateInterval: time.Duration(c.Log.RotateHours) * time.Hour,
+	}
+}
 func (c *Config) makeWalletConfig() *wallet.Config {
 	return &wallet.Config{WalletDir: c.WalletPath}
 }
@@ -196,11 +757,25 @@ func (c *Config) makeNetConfig() *p2p.Net {
 		MaxPeers:          c.Net.MaxPeers,
 		MaxPendingPeers:   c.Net.MaxPendingPeers,
 		MinConnectedPeers: c.Net.MinConnectedPeers,
+		MaxInboundPeers:   c.Net.MaxInboundPeers,
+		MaxPeersPerSubnet: c.Net.MaxPeersPerSubnet,
+		MaxInboundPerIP:   c.Net.MaxInboundPerIP,
+		HandshakeTimeout:  time.Duration(c.Net.HandshakeTimeout) * time.Second,
+		UploadRate:        c.Net.MaxUploadRate * 1024,
+		DownloadRate:      c.Net.MaxDownloadRate * 1024,
+		PeerUploadRate:    c.Net.MaxPeerUploadRate * 1024,
+		PeerDownloadRate:  c.Net.MaxPeerDownloadRate * 1024,
 		Name:              fmt.Sprintf("%v %v", metadata.Version, c.Name),
 		Seeders:           c.Net.Seeders,
+		Capabilities:      c.Net.Capabilities,
 		NodeDatabase:      networkDataDir,
 		ListenAddr:        c.Net.ListenHost,
 		ListenPort:        c.Net.ListenPort,
+		Proxy:             c.Net.Proxy,
+		Sentries:          c.Net.Sentries,
+		PrivatePeers:      c.Net.PrivatePeers,
+		AEADFrames:        c.Net.AEADFrames,
+		Telemetry:         c.Net.Telemetry,
 	}
 }
 func (c *Config) HTTPEndpoint() string {
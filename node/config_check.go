@@ -0,0 +1,337 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// DecodeConfig decodes a config file into cfg, on top of the values it already holds. Unlike json.Unmarshal,
+// it rejects the keys which aren't config fields, matched case-insensitively, and reports where the errors are.
+func DecodeConfig(data []byte, cfg *Config) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return describeJSONError(data, err)
+	}
+	if unknown := unknownKeys(raw, reflect.TypeOf(cfg).Elem(), ""); len(unknown) != 0 {
+		return errors.Errorf("unknown config keys:\n  %v", strings.Join(unknown, "\n  "))
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return describeJSONError(data, err)
+	}
+	return nil
+}
+
+func describeJSONError(data []byte, err error) error {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		// the offset is after the invalid character
+		line, column := position(data, e.Offset-1)
+		return errors.Errorf("invalid JSON at line %v, column %v: %v", line, column, e)
+	case *json.UnmarshalTypeError:
+		line, column := position(data, e.Offset)
+		return errors.Errorf("invalid value for %v at line %v, column %v: expected %v but got %v", e.Field, line, column, e.Type, e.Value)
+	}
+	return err
+}
+
+// position returns the line and column of the byte at offset, both starting at 1.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// unknownKeys returns the paths of the keys of value which aren't fields of t, with the closest field name if
+// there's one.
+func unknownKeys(value interface{}, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// the types which decode themselves have no fields to check
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+	unknown := make([]string, 0)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				field, ok := findField(fields, key)
+				if !ok {
+					message := joinPath(path, key)
+					if suggestion := closestField(fields, key); suggestion != "" {
+						message += fmt.Sprintf(" (did you mean %v?)", joinPath(path, suggestion))
+					}
+					unknown = append(unknown, message)
+					continue
+				}
+				unknown = append(unknown, unknownKeys(v[key], field.Type, joinPath(path, field.Name))...)
+			}
+		case reflect.Map:
+			for key, item := range v {
+				unknown = append(unknown, unknownKeys(item, t.Elem(), joinPath(path, key))...)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range v {
+				unknown = append(unknown, unknownKeys(item, t.Elem(), fmt.Sprintf("%v[%v]", path, i))...)
+			}
+		}
+	}
+	return unknown
+}
+
+type jsonField struct {
+	Name string
+	Type reflect.Type
+}
+
+// jsonFields returns the fields of the struct t decoded by encoding/json, named by their tag if they have one.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := make([]jsonField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+		fields = append(fields, jsonField{Name: name, Type: field.Type})
+	}
+	return fields
+}
+
+// findField matches the key like encoding/json does, the exact name first.
+func findField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.Name == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// closestField returns the field at most 2 edits away from key, if any.
+func closestField(fields []jsonField, key string) string {
+	best, bestDistance := "", 3
+	for _, field := range fields {
+		if distance := editDistance(strings.ToLower(field.Name), strings.ToLower(key)); distance < bestDistance {
+			best, bestDistance = field.Name, distance
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// redacted replaces the secrets of the config when it's printed or logged.
+const redacted = "[redacted]"
+
+// Redacted returns a copy of the config without its secrets, the passwords of the key files, the auth tokens
+// and the API keys, so it can be printed or logged. The paths of the secret files are kept.
+func (c *Config) Redacted() *Config {
+	result := *c
+	if c.Producer != nil {
+		producer := *c.Producer
+		producer.Password = redactString(producer.Password)
+		result.Producer = &producer
+	}
+	if c.Bridge != nil {
+		bridge := *c.Bridge
+		bridge.Password = redactString(bridge.Password)
+		result.Bridge = &bridge
+	}
+	if c.RPC.AuthTokens != nil {
+		result.RPC.AuthTokens = make([]string, len(c.RPC.AuthTokens))
+		for i, token := range c.RPC.AuthTokens {
+			result.RPC.AuthTokens[i] = redactString(token)
+		}
+	}
+	if c.RPC.APIKeys != nil {
+		result.RPC.APIKeys = make([]APIKeyConfig, len(c.RPC.APIKeys))
+		for i, key := range c.RPC.APIKeys {
+			key.Key = redactString(key.Key)
+			result.RPC.APIKeys[i] = key
+		}
+	}
+	return &result
+}
+
+// redactString keeps the empty values, so the missing secrets can still be told apart.
+func redactString(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Validate checks the values of the config and returns all the problems found, so they can be fixed at once.
+// It's meant to run once the flags are applied and the paths are absolute.
+func (c *Config) Validate() error {
+	problems := make([]string, 0)
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if _, err := log15.LvlFromString(c.LogLevel); err != nil {
+		problem("LogLevel: %v", err)
+	}
+	for module, level := range c.Log.ModuleLevels {
+		if _, err := log15.LvlFromString(level); err != nil {
+			problem("Log.ModuleLevels.%v: %v", module, err)
+		}
+	}
+
+	// listeners which would fail to bind, HTTP and WS can share a port
+	type listener struct {
+		name string
+		port int
+	}
+	listeners := []listener{{"Net.ListenPort", c.Net.ListenPort}}
+	if c.RPC.EnableHTTP {
+		listeners = append(listeners, listener{"RPC.HTTPPort", c.RPC.HTTPPort})
+	}
+	if c.RPC.EnableWS && !(c.RPC.EnableHTTP && c.RPC.WSPort == c.RPC.HTTPPort) {
+		listeners = append(listeners, listener{"RPC.WSPort", c.RPC.WSPort})
+	}
+	if c.Debug.EnablePprof {
+		listeners = append(listeners, listener{"Debug.PprofPort", c.Debug.PprofPort})
+	}
+	for i, l := range listeners {
+		if l.port < 0 || l.port > 65535 {
+			problem("%v: port %v is out of range", l.name, l.port)
+			continue
+		}
+		for _, other := range listeners[:i] {
+			if l.port != 0 && l.port == other.port {
+				problem("%v: port %v is already used by %v", l.name, l.port, other.name)
+			}
+		}
+	}
+
+	if c.Producer != nil && !c.Dev.Enabled {
+		if _, err := types.ParseAddress(c.Producer.Address); err != nil {
+			problem("Producer.Address: invalid address %q: %v", c.Producer.Address, err)
+		}
+	}
+	if c.Bridge != nil {
+		if _, err := types.ParseAddress(c.Bridge.Address); err != nil {
+			problem("Bridge.Address: invalid address %q: %v", c.Bridge.Address, err)
+		}
+	}
+	for name, urls := range map[string][]string{"Net.Seeders": c.Net.Seeders, "Net.Sentries": c.Net.Sentries, "Net.PrivatePeers": c.Net.PrivatePeers} {
+		for i, url := range urls {
+			if _, err := discover.ParseNode(url); err != nil {
+				problem("%v[%v]: invalid node URL %q: %v", name, i, url, err)
+			}
+		}
+	}
+	for i, key := range c.RPC.APIKeys {
+		if key.Name == "" || key.Key == "" || key.RateLimit < 0 {
+			problem("RPC.APIKeys[%v]: api keys need a name, a key and a non-negative rate limit", i)
+		}
+	}
+	if c.RPC.AnonymousRateLimit < 0 {
+		problem("RPC.AnonymousRateLimit: the rate limit can't be negative")
+	}
+	if c.RPC.EnableWallet && !c.RPC.EnableIPC {
+		problem("RPC.EnableWallet: the wallet namespace is only served over IPC, which isn't enabled")
+	}
+
+	if _, err := c.parseCompactionWindow(); err != nil {
+		problem("Database.CompactionWindow: %v", err)
+	}
+	if _, _, err := c.parseLease(); err != nil {
+		problem("Producer: %v", err)
+	}
+	if _, _, err := c.parseReplica(); err != nil {
+		problem("Replica: %v", err)
+	}
+	if _, err := c.parseAutoReceive(); err != nil {
+		problem("AutoReceive: %v", err)
+	}
+	if c.Dev.Period < 0 {
+		problem("Dev.Period: invalid developer mode period %v", c.Dev.Period)
+	}
+
+	sort.Strings(problems)
+	if len(problems) != 0 {
+		return errors.Errorf("invalid config:\n  %v", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
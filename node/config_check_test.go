@@ -0,0 +1,106 @@
+package node
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Test DecodeConfig
+//   - test the keys are matched case-insensitively and decoded on top of the defaults
+//   - test the unknown keys are reported with the closest field
+//   - test the syntax and type errors are reported with their position
+func TestDecodeConfig(t *testing.T) {
+	cfg := DefaultNodeConfig
+	if err := DecodeConfig([]byte(`{"rpc": {"httpPort": 1234}, "Producer": {"Address": "z1"}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RPC.HTTPPort != 1234 || cfg.RPC.WSPort != DefaultNodeConfig.RPC.WSPort || cfg.Producer == nil || cfg.Producer.Address != "z1" {
+		t.Fatalf("config not decoded on top of the defaults: %+v", cfg.RPC)
+	}
+
+	for data, expected := range map[string][]string{
+		`{"RPC": {"HTTPPrt": 1}, "Unknown": 1}`:            {"RPC.HTTPPrt (did you mean RPC.HTTPPort?)", "Unknown"},
+		`{"Net": {"Seeders": [1]}, "Producer": {}}`:        {"Net.Seeders", "expected string but got number"},
+		"{\n  \"RPC\": {\n    \"HTTPPort\": \"1\"\n  }\n}": {"RPC.HTTPPort", "line 3"},
+		"{\n  \"RPC\": {,\n}":                              {"line 2, column 11"},
+		"":                                                 {"line 1, column 1"},
+	} {
+		cfg := DefaultNodeConfig
+		err := DecodeConfig([]byte(data), &cfg)
+		if err == nil {
+			t.Errorf("config %v decoded", data)
+			continue
+		}
+		for _, part := range expected {
+			if !strings.Contains(err.Error(), part) {
+				t.Errorf("error of %v doesn't report %q: %v", data, part, err)
+			}
+		}
+	}
+}
+
+// Test Validate
+//   - test the default config is valid
+//   - test all the problems are reported at once
+func TestConfig_Validate(t *testing.T) {
+	cfg := DefaultNodeConfig
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+
+	cfg.LogLevel = "loud"
+	cfg.RPC.WSPort = cfg.Net.ListenPort
+	cfg.Producer = &ProducerConfig{Address: "z1", Backup: true}
+	cfg.RPC.APIKeys = []APIKeyConfig{{Name: "tenant"}}
+	cfg.RPC.AnonymousRateLimit = -1
+	cfg.RPC.EnableWallet = true
+	cfg.Dev.Period = -1
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid config accepted")
+	}
+	for _, part := range []string{
+		"LogLevel:",
+		"RPC.WSPort: port 35995 is already used by Net.ListenPort",
+		"Producer.Address: invalid address",
+		"Producer: a backup producer needs the LeaseAddress",
+		"RPC.APIKeys[0]:",
+		"RPC.AnonymousRateLimit:",
+		"RPC.EnableWallet:",
+		"Dev.Period:",
+	} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("%q not reported: %v", part, err)
+		}
+	}
+}
+
+// Test Redacted
+//   - test the passwords, auth tokens and API keys aren't printed
+//   - test the config itself keeps its secrets
+func TestConfig_Redacted(t *testing.T) {
+	cfg := DefaultNodeConfig
+	cfg.Producer = &ProducerConfig{Address: "z1", Password: "producer-password", SignerSecretFile: "signer.secret"}
+	cfg.Bridge = &BridgeConfig{Address: "z1", Password: "bridge-password"}
+	cfg.RPC.AuthTokens = []string{"auth-token"}
+	cfg.RPC.APIKeys = []APIKeyConfig{{Name: "tenant", Key: "api-key", RateLimit: 5}}
+
+	data, err := json.Marshal(cfg.Redacted())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"producer-password", "bridge-password", "auth-token", "api-key"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("%v printed: %s", secret, data)
+		}
+	}
+	for _, kept := range []string{"signer.secret", `"Name":"tenant"`, `"RateLimit":5`, redacted} {
+		if !strings.Contains(string(data), kept) {
+			t.Errorf("%v not printed: %s", kept, data)
+		}
+	}
+	if cfg.Producer.Password != "producer-password" || cfg.Bridge.Password != "bridge-password" || cfg.RPC.AuthTokens[0] != "auth-token" || cfg.RPC.APIKeys[0].Key != "api-key" {
+		t.Fatal("secrets of the config redacted")
+	}
+}
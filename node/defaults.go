@@ -6,11 +6,48 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/zenon-network/go-zenon/common/ntp"
 	"github.com/zenon-network/go-zenon/p2p"
 )
 
 const (
 	DefaultWalletDir = "wallet"
+	DefaultIPCPath   = "znnd.ipc"
+
+	DefaultPprofHost    = "127.0.0.1"
+	DefaultPprofPort    = 6060
+	DefaultProfilesPath = "profiles"
+
+	DefaultShutdownTimeout = 60 // seconds
+
+	DefaultPoWMaxJobs       = 1
+	DefaultPoWMaxQueuedJobs = 16
+
+	DefaultHealthMinPeers       = 3
+	DefaultHealthMaxMomentumAge = 60 // seconds
+
+	DefaultRPCSlowQueryThreshold = 1000 // milliseconds
+	DefaultAnonymousRateLimit    = 10   // calls per second
+
+	DefaultLogMaxSize    = 100 // megabytes
+	DefaultLogMaxBackups = 14
+	DefaultLogMaxAge     = 14 // days
+
+	DefaultAncientThreshold = 8640 // momentums, about one day
+	DefaultMaxBackups       = 3
+	DefaultCacheSize        = 4096
+
+	DefaultRewardsZnnThreshold = 10 * 100000000  // 10 ZNN
+	DefaultRewardsQsrThreshold = 100 * 100000000 // 100 QSR
+	DefaultRewardsInterval     = 3600            // seconds
+
+	DefaultReplicaRefreshInterval = 10 // seconds
+
+	DefaultAutoReceiveInterval  = 10 // seconds
+	DefaultAutoReceiveMaxBlocks = 50
+
+	DefaultClockDriftWarning = 1000 // milliseconds
+	DefaultMaxProducerDrift  = 0    // milliseconds, the production isn't stopped by default
 )
 
 var DefaultNodeConfig = Config{
@@ -19,6 +56,11 @@ var DefaultNodeConfig = Config{
 	Name: p2p.DefaultNodeName,
 
 	LogLevel: "info",
+	Log: LogConfig{
+		MaxSize:    DefaultLogMaxSize,
+		MaxBackups: DefaultLogMaxBackups,
+		MaxAge:     DefaultLogMaxAge,
+	},
 
 	RPC: RPCConfig{
 		HTTPPort:   p2p.DefaultHTTPPort,
@@ -28,17 +70,63 @@ var DefaultNodeConfig = Config{
 		WSHost:     "0.0.0.0",
 		EnableWS:   true,
 
-		HTTPCors:  []string{"*"},
-		WSOrigins: []string{"*"},
+		HTTPVirtualHosts: []string{"localhost"},
+		HTTPCors:         []string{"*"},
+		WSOrigins:        []string{"*"},
+
+		HealthMinPeers:       DefaultHealthMinPeers,
+		HealthMaxMomentumAge: DefaultHealthMaxMomentumAge,
+
+		SlowQueryThreshold: DefaultRPCSlowQueryThreshold,
+
+		AnonymousRateLimit: DefaultAnonymousRateLimit,
+
+		IPCPath: DefaultIPCPath,
 	},
 	Net: NetConfig{
-		ListenHost:        p2p.DefaultListenHost,
-		ListenPort:        p2p.DefaultListenPort,
-		MinPeers:          p2p.DefaultMinPeers,
-		MinConnectedPeers: p2p.DefaultMinConnectedPeers,
-		MaxPeers:          p2p.DefaultMaxPeers,
-		MaxPendingPeers:   p2p.DefaultMaxPendingPeers,
-		Seeders:           p2p.DefaultSeeders,
+		ListenHost:           p2p.DefaultListenHost,
+		ListenPort:           p2p.DefaultListenPort,
+		MinPeers:             p2p.DefaultMinPeers,
+		MinConnectedPeers:    p2p.DefaultMinConnectedPeers,
+		MaxPeers:             p2p.DefaultMaxPeers,
+		MaxPendingPeers:      p2p.DefaultMaxPendingPeers,
+		MaxPeersPerSubnet:    p2p.DefaultMaxPeersPerSubnet,
+		MaxInboundPerIP:      p2p.DefaultMaxInboundPerIP,
+		HandshakeTimeout:     p2p.DefaultHandshakeTimeout,
+		PropagationDiversity: p2p.DefaultPropagationDiversity,
+		Seeders:              p2p.DefaultSeeders,
+	},
+	PoW: PoWConfig{
+		MaxJobs:       DefaultPoWMaxJobs,
+		MaxQueuedJobs: DefaultPoWMaxQueuedJobs,
+	},
+	Debug: DebugConfig{
+		PprofHost:       DefaultPprofHost,
+		PprofPort:       DefaultPprofPort,
+		ProfilesPath:    DefaultProfilesPath,
+		ShutdownTimeout: DefaultShutdownTimeout,
+	},
+	Database: DatabaseConfig{
+		AncientThreshold: DefaultAncientThreshold,
+		MaxBackups:       DefaultMaxBackups,
+		CacheSize:        DefaultCacheSize,
+	},
+	Rewards: RewardsConfig{
+		ZnnThreshold: DefaultRewardsZnnThreshold,
+		QsrThreshold: DefaultRewardsQsrThreshold,
+		Interval:     DefaultRewardsInterval,
+	},
+	Replica: ReplicaConfig{
+		RefreshInterval: DefaultReplicaRefreshInterval,
+	},
+	Clock: ClockConfig{
+		NTPServer:        ntp.DefaultServer,
+		DriftWarning:     DefaultClockDriftWarning,
+		MaxProducerDrift: DefaultMaxProducerDrift,
+	},
+	AutoReceive: AutoReceiveConfig{
+		Interval:  DefaultAutoReceiveInterval,
+		MaxBlocks: DefaultAutoReceiveMaxBlocks,
 	},
 }
 
@@ -0,0 +1,112 @@
+package node
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/zenon-network/go-zenon/chain/genesis"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const (
+	// DevMnemonic derives the developer accounts, it's public so never use it outside the developer mode.
+	DevMnemonic        = "test test test test test test test test test test test junk"
+	DevAccountCount    = 10
+	DevChainIdentifier = 1337
+	DevGenesisFileName = "dev-genesis.json"
+
+	devPillarName = "dev-pillar"
+)
+
+var (
+	devAccountZnn    = big.NewInt(1000000 * constants.Decimals)
+	devAccountQsr    = big.NewInt(10000000 * constants.Decimals)
+	devAccountFusion = big.NewInt(10000 * constants.Decimals)
+)
+
+// DevConfig runs a single node developer network, whose only pillar is the first developer account, see DevKeyPairs.
+type DevConfig struct {
+	Enabled bool
+	// Period is the interval in seconds between momentums. If zero, momentums are produced every second
+	// but only when there are account-blocks to confirm.
+	Period int64
+}
+
+// DevKeyPairs derives the developer accounts from DevMnemonic, they are funded at genesis and have fused plasma.
+func DevKeyPairs() []*wallet.KeyPair {
+	seed := bip39.NewSeed(DevMnemonic, "")
+	keyPairs := make([]*wallet.KeyPair, DevAccountCount)
+	for i := range keyPairs {
+		keyPair, err := wallet.DeriveWithIndex(uint32(i), seed)
+		if err != nil {
+			panic(err)
+		}
+		keyPairs[i] = keyPair
+	}
+	return keyPairs
+}
+
+// MakeDevGenesis returns the genesis of the developer network, with all the implemented sporks active.
+func MakeDevGenesis(timestamp int64) (*genesis.GenesisConfig, error) {
+	keyPairs := DevKeyPairs()
+	cfg := &genesis.DevnetConfig{
+		ChainIdentifier:     DevChainIdentifier,
+		ExtraData:           "developer network",
+		GenesisTimestampSec: timestamp,
+		SporkAddress:        keyPairs[0].Address,
+		Pillars:             []*genesis.DevnetPillar{{Name: devPillarName, Address: keyPairs[0].Address}},
+	}
+	for _, keyPair := range keyPairs {
+		cfg.Balances = append(cfg.Balances, &genesis.DevnetBalance{Address: keyPair.Address, Znn: devAccountZnn, Qsr: devAccountQsr})
+		cfg.Fusions = append(cfg.Fusions, &genesis.DevnetFusion{Address: keyPair.Address, Qsr: devAccountFusion})
+	}
+	cfg.ActiveSporks = []types.Hash{types.AcceleratorSpork.SporkId, types.HtlcSpork.SporkId,
+		types.BridgeAndLiquiditySpork.SporkId, types.PayloadLimitsSpork.SporkId}
+	return genesis.GenerateGenesis(cfg)
+}
+
+// SetupDev turns the config into the one of the developer network. The genesis is generated in the data directory
+// on the first run, the node doesn't look for peers and produces the momentums with the first developer account.
+// The paths must be absolute already.
+func (c *Config) SetupDev() error {
+	if c.Dev.Period < 0 {
+		return errors.Errorf("invalid developer mode period %v", c.Dev.Period)
+	}
+	c.GenesisFile = filepath.Join(c.DataPath, DevGenesisFileName)
+	if _, err := os.Stat(c.GenesisFile); os.IsNotExist(err) {
+		config, err := MakeDevGenesis(time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(c.DataPath, 0700); err != nil {
+			return err
+		}
+		if err := genesis.WriteGenesisConfigToFile(config, c.GenesisFile); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	c.Net.ListenHost = "127.0.0.1"
+	c.Net.MinPeers = 0
+	c.Net.MinConnectedPeers = 0
+	c.Net.Seeders = nil
+	c.RPC.HTTPHost = "127.0.0.1"
+	c.RPC.WSHost = "127.0.0.1"
+	c.Producer = nil
+
+	// the consensus reads the block time when it's created
+	constants.ConsensusConfig.BlockTime = 1
+	if c.Dev.Period > 0 {
+		constants.ConsensusConfig.BlockTime = c.Dev.Period
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+
+	rpcapi "github.com/zenon-network/go-zenon/rpc/api"
+)
+
+const healthPath = "/health"
+
+// newHealthHandler serves the health of the node with status 200 if healthy and 503 otherwise.
+// It's not authenticated, so load balancers can poll it.
+func newHealthHandler(node *Node) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health, err := rpcapi.NodeHealth(node.z, node.server)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !health.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(w).Encode(health); err != nil {
+			log.Debug("failed to write health", "reason", err)
+		}
+	})
+}
@@ -1,17 +1,28 @@
 package node
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/tsdb/fileutil"
+	"github.com/syndtr/goleveldb/leveldb"
 
+	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/debug"
+	"github.com/zenon-network/go-zenon/common/tracing"
+	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/protocol"
 	api "github.com/zenon-network/go-zenon/rpc"
+	rpcapi "github.com/zenon-network/go-zenon/rpc/api"
 	rpc "github.com/zenon-network/go-zenon/rpc/server"
 	"github.com/zenon-network/go-zenon/wallet"
 	"github.com/zenon-network/go-zenon/zenon"
@@ -27,12 +38,21 @@ type Node struct {
 
 	walletManager *wallet.Manager
 	server        *p2p.Server
+	powPool       *pow.Pool
+	tracing       *tracing.OTLPExporter
+	autoReceiver  *rpcapi.AutoReceiver
 
 	z zenon.Zenon
 
-	rpcAPIs []rpc.API   // List of APIs currently provided by the node
-	http    *httpServer //
-	ws      *httpServer //
+	// light replaces z on light nodes
+	light   *protocol.LightClient
+	lightDb *leveldb.DB
+
+	rpcAPIs    []rpc.API   // List of APIs currently provided by the node
+	walletAPIs []rpc.API   // List of APIs only provided on the IPC endpoint
+	http       *httpServer //
+	ws         *httpServer //
+	ipc        *ipcServer  //
 
 	// Channel to wait for termination notifications
 	stop        chan struct{}
@@ -49,6 +69,7 @@ func NewNode(conf *Config) (*Node, error) {
 		walletManager: wallet.New(conf.makeWalletConfig()),
 		http:          newHTTPServer(rpc.DefaultHTTPTimeouts),
 		ws:            newHTTPServer(rpc.DefaultHTTPTimeouts),
+		ipc:           newIPCServer(conf.IPCEndpoint()),
 	}
 
 	// prepare node
@@ -56,6 +77,12 @@ func NewNode(conf *Config) (*Node, error) {
 	if err = node.openDataDir(); err != nil {
 		return nil, err
 	}
+	if node.config.DataPath != "" {
+		if err = node.config.backupOnUpgrade(); err != nil {
+			log.Error("failed to back up the databases", "reason", err)
+			return nil, err
+		}
+	}
 
 	// start wallet
 	if err = node.startWallet(); err != nil {
@@ -63,15 +90,36 @@ func NewNode(conf *Config) (*Node, error) {
 		return nil, err
 	}
 
-	// Initialize the zenon rpc
-	zenonConfig, err := node.config.makeZenonConfig(node.walletManager)
-	if err != nil {
-		return nil, err
+	replicaMode := conf.Replica.PrimaryDataPath != ""
+	if replicaMode && conf.Light {
+		return nil, errors.Errorf("light nodes can't be replicas")
 	}
-	node.z, err = zenon.NewZenon(zenonConfig)
-	if err != nil {
-		log.Error("failed to create zenon", "reason", err)
-		return nil, err
+
+	var protocols []p2p.Protocol
+	var genesis *nom.Momentum
+	if conf.Light {
+		var lightDb db.DB
+		lightDb, node.lightDb = db.NewLevelDB(filepath.Join(conf.DataPath, "light"))
+		genesis = conf.makeGenesisConfig().GetGenesisMomentum()
+		node.light, err = protocol.NewLightClient(lightDb, genesis)
+		if err != nil {
+			log.Error("failed to create light client", "reason", err)
+			return nil, err
+		}
+		protocols = node.light.SubProtocols
+	} else {
+		// Initialize the zenon rpc
+		zenonConfig, err := node.config.makeZenonConfig(node.walletManager)
+		if err != nil {
+			return nil, err
+		}
+		node.z, err = zenon.NewZenon(zenonConfig)
+		if err != nil {
+			log.Error("failed to create zenon", "reason", err)
+			return nil, err
+		}
+		protocols = node.z.Protocol().SubProtocols
+		genesis = node.z.Chain().GetGenesisMomentum()
 	}
 
 	netConfig := conf.makeNetConfig()
@@ -79,6 +127,35 @@ func NewNode(conf *Config) (*Node, error) {
 	if err != nil {
 		return nil, errors.Errorf("Unable to parse seeders. Reason: %v", err)
 	}
+	dialer, err := netConfig.Dialer()
+	if err != nil {
+		return nil, errors.Errorf("Unable to parse proxy. Reason: %v", err)
+	}
+	if dialer != nil {
+		log.Info("dialing peers through proxy, discovery and inbound connections are disabled")
+	}
+	sentries, err := netConfig.SentryNodes()
+	if err != nil {
+		return nil, errors.Errorf("Unable to parse sentries. Reason: %v", err)
+	}
+	privatePeers, err := netConfig.PrivateNodes()
+	if err != nil {
+		return nil, errors.Errorf("Unable to parse private peers. Reason: %v", err)
+	}
+	sentryMode := len(sentries) != 0
+	if sentryMode {
+		log.Info("running in sentry mode, peering only with the sentry nodes", "sentries", len(sentries))
+		nodes = nil
+	}
+	privateNodes := append(sentries, privatePeers...)
+	if replicaMode {
+		log.Info("running as a replica, networking is disabled", "primary", conf.Replica.PrimaryDataPath)
+		nodes, privateNodes = nil, nil
+	}
+	listenAddr := fmt.Sprintf("%v:%v", netConfig.ListenAddr, netConfig.ListenPort)
+	if replicaMode {
+		listenAddr = ""
+	}
 
 	node.server = &p2p.Server{
 		PrivateKey:        netConfig.PrivateKey(),
@@ -86,55 +163,132 @@ func NewNode(conf *Config) (*Node, error) {
 		MaxPeers:          netConfig.MaxPeers,
 		MinConnectedPeers: netConfig.MinConnectedPeers,
 		MaxPendingPeers:   netConfig.MaxPendingPeers,
-		Discovery:         true,
-		NoDial:            false,
-		StaticNodes:       nil,
+		MaxInboundPeers:   netConfig.MaxInboundPeers,
+		MaxPeersPerSubnet: netConfig.MaxPeersPerSubnet,
+		MaxInboundPerIP:   netConfig.MaxInboundPerIP,
+		HandshakeTimeout:  netConfig.HandshakeTimeout,
+		UploadRate:        netConfig.UploadRate,
+		DownloadRate:      netConfig.DownloadRate,
+		PeerUploadRate:    netConfig.PeerUploadRate,
+		PeerDownloadRate:  netConfig.PeerDownloadRate,
+		Discovery:         dialer == nil && !sentryMode && !replicaMode,
+		Dialer:            dialer,
+		NoDial:            replicaMode,
+		StaticNodes:       privateNodes,
 		BootstrapNodes:    nodes,
-		TrustedNodes:      nil,
+		TrustedNodes:      privateNodes,
+		PrivatePeering:    sentryMode,
 		NodeDatabase:      netConfig.NodeDatabase,
-		ListenAddr:        fmt.Sprintf("%v:%v", netConfig.ListenAddr, netConfig.ListenPort),
-		Protocols:         node.z.Protocol().SubProtocols,
+		ListenAddr:        listenAddr,
+		Protocols:         protocols,
+		Capabilities:      netConfig.Capabilities,
+		AEADFrames:        netConfig.AEADFrames,
+		NetworkID:         networkID(genesis),
+		Metadata:          peerMetadata(netConfig),
 	}
 	return node, nil
 }
 
+// peerMetadata returns the telemetry advertised to the peers, nil unless the node opted in.
+func peerMetadata(netConfig *p2p.Net) *p2p.PeerMetadata {
+	if !netConfig.Telemetry {
+		return nil
+	}
+	archival := false
+	for _, capability := range netConfig.Capabilities {
+		archival = archival || capability == p2p.CapabilityArchival
+	}
+	return &p2p.PeerMetadata{
+		Client:   metadata.Version,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Archival: archival,
+	}
+}
+
+// networkID derives the network advertised to the peers from the hash of the genesis momentum,
+// so the nodes of other chains are disconnected before any protocol runs.
+func networkID(genesis *nom.Momentum) string {
+	return hex.EncodeToString(genesis.Hash.Bytes()[:8])
+}
+
 func (node *Node) Start() error {
 	node.lock.Lock()
 	defer node.lock.Unlock()
 
+	node.startDebug()
 	if err := node.startZenon(); err != nil {
 		return err
 	}
 	if err := node.server.Start(); err != nil {
 		return err
 	}
-	node.rpcAPIs = api.GetPublicApis(node.z, node.server)
+	if node.light != nil {
+		node.rpcAPIs = api.GetLightApis(node.light, node.server)
+	} else {
+		node.rpcAPIs = api.GetPublicApis(node.z, node.server)
+	}
+	if node.config.RPC.EnableWallet && node.light != nil {
+		log.Warn("the wallet apis aren't served by light nodes")
+	} else if node.config.RPC.EnableWallet {
+		node.walletAPIs = api.GetWalletApis(node.z, node.walletManager)
+	}
+	if node.config.PoW.Enabled {
+		node.powPool = pow.NewPool(node.config.PoW.MaxJobs, node.config.PoW.MaxQueuedJobs)
+	}
+	node.rpcAPIs = append(node.rpcAPIs, api.GetUtilitiesApis(node.powPool)...)
 	if err := node.startRPC(); err != nil {
 		log.Error("failed to start rpc", "reason", err)
 		return err
 	}
+	if err := node.startAutoReceiver(); err != nil {
+		log.Error("failed to start auto-receiver", "reason", err)
+		return err
+	}
 
 	return nil
 }
 func (node *Node) Stop() error {
+	watchdog := node.startShutdownWatchdog()
+	defer watchdog.stop()
+
 	node.lock.Lock()
 	defer node.lock.Unlock()
 	defer close(node.stop)
 
 	log.Info("stopping p2p server ...")
+	watchdog.enter("stopping the p2p server")
 	node.server.Stop()
+	// stop serving requests before the wallet is locked
+	watchdog.enter("stopping the rpc servers")
+	node.stopRPC()
+	if node.autoReceiver != nil {
+		watchdog.enter("stopping the auto-receiver")
+		node.autoReceiver.Stop()
+	}
+	if node.powPool != nil {
+		watchdog.enter("stopping the pow pool")
+		node.powPool.Stop()
+	}
+	if node.tracing != nil {
+		watchdog.enter("stopping the tracing exporter")
+		tracing.SetExporter(nil)
+		node.tracing.Stop()
+	}
 
+	watchdog.enter("stopping the wallet")
 	if err := node.stopWallet(); err != nil {
 		log.Error("failed to stop wallet", "reason", err)
 		return err
 	}
+	watchdog.enter("stopping zenon")
 	if err := node.stopZenon(); err != nil {
 		log.Error("failed to stop zenon", "reason", err)
 		return err
 	}
-	node.stopRPC()
 
 	// Release instance directory lock.
+	watchdog.enter("closing the data directory")
 	node.closeDataDir()
 
 	return nil
@@ -153,13 +307,37 @@ func (node *Node) WalletManager() *wallet.Manager {
 	return node.walletManager
 }
 
+func (node *Node) startDebug() {
+	debug.Handler.SetDir(node.config.ProfilesDir())
+	debug.ListenSignals(node.stop)
+	if node.config.Debug.EnablePprof {
+		debug.StartPProf(fmt.Sprintf("%v:%v", node.config.Debug.PprofHost, node.config.Debug.PprofPort))
+	}
+	if node.config.Debug.TracingEndpoint != "" {
+		node.tracing = tracing.NewOTLPExporter(node.config.Debug.TracingEndpoint, "znnd")
+		tracing.SetExporter(node.tracing)
+		log.Info("exporting spans", "endpoint", node.config.Debug.TracingEndpoint)
+	}
+}
 func (node *Node) startWallet() error {
 	if err := node.walletManager.Start(); err != nil {
 		return err
 	}
 	return nil
 }
+func (node *Node) startAutoReceiver() error {
+	config, err := node.config.parseAutoReceive()
+	if err != nil || config == nil {
+		return err
+	}
+	node.autoReceiver = api.NewAutoReceiver(node.z, node.walletAPIs, node.powPool, config)
+	return node.autoReceiver.Start()
+}
 func (node *Node) startZenon() error {
+	if node.light != nil {
+		node.light.Start()
+		return nil
+	}
 	if err := node.z.Init(); err != nil {
 		log.Error("failed to init zenon", "reason", err)
 		return err
@@ -179,6 +357,10 @@ func (node *Node) stopWallet() error {
 	return nil
 }
 func (node *Node) stopZenon() error {
+	if node.light != nil {
+		node.light.Stop()
+		return node.lightDb.Close()
+	}
 	if node.z == nil {
 		return ErrNodeStopped
 	}
@@ -195,11 +377,9 @@ func (node *Node) openDataDir() error {
 	}
 	log.Info("successfully ensured DataPath exists", "data-path", node.config.DataPath)
 
-	// Lock the instance directory to prevent concurrent use by another instance as well as
-	// accidental use of the instance directory as a database.
-	if fileLock, _, err := fileutil.Flock(filepath.Join(node.config.DataPath, ".lock")); err != nil {
+	if fileLock, err := lockDataDir(node.config.DataPath); err != nil {
 		log.Info("unable to acquire file-lock", "reason", err)
-		return convertFileLockError(err)
+		return err
 	} else {
 		node.dataDirLock = fileLock
 	}
@@ -207,6 +387,16 @@ func (node *Node) openDataDir() error {
 	log.Info("successfully locked dataDir")
 	return nil
 }
+
+// lockDataDir locks the instance directory to prevent concurrent use by another instance as well as
+// accidental use of the instance directory as a database.
+func lockDataDir(dataPath string) (fileutil.Releaser, error) {
+	fileLock, _, err := fileutil.Flock(filepath.Join(dataPath, ".lock"))
+	if err != nil {
+		return nil, convertFileLockError(err)
+	}
+	return fileLock, nil
+}
 func (node *Node) closeDataDir() {
 	log.Info("releasing dataDir lock ... ")
 	// Release instance directory lock.
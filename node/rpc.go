@@ -1,49 +1,119 @@
 package node
 
+import (
+	"time"
+
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+)
+
 // configureRPC is a helper method to configure all the various RPC endpoints during node
 // startup. It's not meant to be called at any time afterwards as it makes certain
 // assumptions about the state of the node.
 func (node *Node) startRPC() error {
+	rpc.SetSlowQueryThreshold(time.Duration(node.config.RPC.SlowQueryThreshold) * time.Millisecond)
+
+	tlsConfig, err := loadTLSConfig(node.config.resolvePath(node.config.RPC.TLSCertFile), node.config.resolvePath(node.config.RPC.TLSKeyFile))
+	if err != nil {
+		return err
+	}
+	auth, err := newRPCAuth(node.config.RPC.AuthTokens, node.config.resolvePath(node.config.RPC.JWTSecretFile))
+	if err != nil {
+		return err
+	}
+	var anonymous *rpc.APIKey
+	if node.config.RPC.AllowAnonymous {
+		anonymous = &rpc.APIKey{RateLimit: node.config.RPC.AnonymousRateLimit}
+	}
+	if err := rpc.SetAPIKeys(apiKeys(node.config.RPC.APIKeys), anonymous); err != nil {
+		return err
+	}
+
 	// Configure HTTP.
-	if node.config.RPC.HTTPHost != "" {
+	if node.config.RPC.EnableHTTP && node.config.RPC.HTTPHost != "" {
 		config := httpConfig{
 			CorsAllowedOrigins: node.config.RPC.HTTPCors,
 			Vhosts:             node.config.RPC.HTTPVirtualHosts,
 			Modules:            node.config.RPC.Endpoints,
+			DisabledModules:    node.config.RPC.DisabledEndpoints,
+			Methods:            node.config.RPC.HTTPMethods,
+			auth:               auth,
 			prefix:             "",
 		}
 		if err := node.http.setListenAddr(node.config.RPC.HTTPHost, node.config.RPC.HTTPPort); err != nil {
 			return err
 		}
+		if err := node.http.setTLS(tlsConfig); err != nil {
+			return err
+		}
 		if err := node.http.enableRPC(node.rpcAPIs, config); err != nil {
 			return err
 		}
+		if node.z != nil {
+			node.http.mux.Handle(healthPath, newHealthHandler(node))
+			node.http.handlerNames[healthPath] = "health"
+		}
 	}
 
 	// Configure WebSocket.
-	if node.config.RPC.WSHost != "" {
+	if node.config.RPC.EnableWS && node.config.RPC.WSHost != "" {
 		server := node.wsServerForPort(node.config.RPC.WSPort)
 		config := wsConfig{
-			Modules: node.config.RPC.Endpoints,
-			Origins: node.config.RPC.WSOrigins,
-			prefix:  "",
+			Modules:         node.config.RPC.Endpoints,
+			DisabledModules: node.config.RPC.DisabledEndpoints,
+			Methods:         node.config.RPC.WSMethods,
+			Origins:         node.config.RPC.WSOrigins,
+			auth:            auth,
+			prefix:          "",
 		}
 		if err := server.setListenAddr(node.config.RPC.WSHost, node.config.RPC.WSPort); err != nil {
 			return err
 		}
+		if err := server.setTLS(tlsConfig); err != nil {
+			return err
+		}
 		if err := server.enableWS(node.rpcAPIs, config); err != nil {
 			return err
 		}
 	}
 
+	// Configure IPC, the only endpoint serving the wallet APIs. The HTTP and WS endpoints can be called by
+	// any web page open in a browser of the host, whatever the host they are bound to.
+	if node.config.RPC.EnableIPC && node.ipc.endpoint != "" {
+		var apis []rpc.API
+		for _, list := range [][]rpc.API{node.rpcAPIs, node.walletAPIs} {
+			for _, api := range list {
+				if !isModuleDisabled(api.Namespace, node.config.RPC.DisabledEndpoints) {
+					apis = append(apis, api)
+				}
+			}
+		}
+		if err := node.ipc.start(apis, node.config.RPC.IPCMethods); err != nil {
+			return err
+		}
+	}
+
 	if err := node.http.start(); err != nil {
 		return err
 	}
 	return node.ws.start()
 }
 
+func apiKeys(configs []APIKeyConfig) []*rpc.APIKey {
+	keys := make([]*rpc.APIKey, len(configs))
+	for i, config := range configs {
+		keys[i] = &rpc.APIKey{
+			Name:      config.Name,
+			Key:       config.Key,
+			Allowed:   config.Methods.Allowed,
+			Denied:    config.Methods.Denied,
+			RateLimit: config.RateLimit,
+		}
+	}
+	return keys
+}
+
 func (node *Node) wsServerForPort(port int) *httpServer {
-	if node.config.RPC.HTTPHost == "" || node.http.port == port {
+	if !node.config.RPC.EnableHTTP || node.config.RPC.HTTPHost == "" || node.http.port == port {
 		return node.http
 	}
 	return node.ws
@@ -52,4 +122,7 @@ func (node *Node) wsServerForPort(port int) *httpServer {
 func (node *Node) stopRPC() {
 	node.http.stop()
 	node.ws.stop()
+	if err := node.ipc.stop(); err != nil {
+		log.Error("failed to stop ipc", "reason", err)
+	}
 }
@@ -0,0 +1,58 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+)
+
+// Test the wallet namespace
+//   - test it isn't served over HTTP and WS, even on a loopback host
+//   - test it is served over IPC along with the other namespaces
+func TestStartRPC_WalletOnlyOnIPC(t *testing.T) {
+	cfg := DefaultNodeConfig
+	cfg.DataPath = t.TempDir()
+	cfg.RPC.HTTPHost, cfg.RPC.HTTPPort = "127.0.0.1", 0
+	cfg.RPC.WSHost, cfg.RPC.WSPort = "127.0.0.1", 0
+	cfg.RPC.EnableWallet, cfg.RPC.EnableIPC = true, true
+	node := &Node{
+		config:     &cfg,
+		http:       newHTTPServer(rpc.DefaultHTTPTimeouts),
+		ws:         newHTTPServer(rpc.DefaultHTTPTimeouts),
+		ipc:        newIPCServer(cfg.IPCEndpoint()),
+		rpcAPIs:    []rpc.API{{Namespace: "ledger", Service: new(testAuthService), Public: true}},
+		walletAPIs: []rpc.API{{Namespace: "wallet", Service: new(testAuthService), Public: true}},
+	}
+	if err := node.startRPC(); err != nil {
+		t.Fatal(err)
+	}
+	defer node.stopRPC()
+
+	call := func(client *rpc.Client, method string) error {
+		defer client.Close()
+		var result string
+		return client.Call(&result, method, "hello")
+	}
+	for name, server := range map[string]*rpc.Server{
+		"HTTP": node.http.httpHandler.Load().(*rpcHandler).server,
+		"WS":   node.wsServerForPort(cfg.RPC.WSPort).wsHandler.Load().(*rpcHandler).server,
+	} {
+		if err := call(rpc.DialInProc(server), "ledger.echo"); err != nil {
+			t.Fatalf("ledger not served over %v: %v", name, err)
+		}
+		if err := call(rpc.DialInProc(server), "wallet.echo"); err == nil {
+			t.Fatalf("wallet served over %v", name)
+		}
+	}
+
+	for _, method := range []string{"ledger.echo", "wallet.echo"} {
+		client, err := rpc.DialIPC(context.Background(), cfg.IPCEndpoint())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := call(client, method); err != nil {
+			t.Fatalf("%v not served over IPC: %v", method, err)
+		}
+	}
+}
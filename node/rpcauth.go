@@ -0,0 +1,172 @@
+package node
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// jwtMaxIatDiff is the maximum difference between the issued-at claim of a JWT
+	// without expiration and the local time.
+	jwtMaxIatDiff = 60 * time.Second
+)
+
+var (
+	errMissingToken    = errors.New("missing bearer token")
+	errInvalidToken    = errors.New("invalid bearer token")
+	errInvalidJWT      = errors.New("invalid JWT")
+	errUnsupportedAlg  = errors.New("unsupported JWT algorithm, expected HS256")
+	errInvalidJWTSign  = errors.New("invalid JWT signature")
+	errStaleJWT        = errors.New("JWT issued-at claim is too far from the local time")
+	errExpiredJWT      = errors.New("JWT is expired")
+	errJWTSecretLength = errors.New("JWT secret must be at least 32 bytes long")
+)
+
+// rpcAuth authenticates the requests of the HTTP and WS endpoints. Either one of
+// the static tokens or a JWT signed with the secret using HS256 must be sent as a
+// bearer token in the Authorization header.
+type rpcAuth struct {
+	tokens    []string
+	jwtSecret []byte
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+type jwtClaims struct {
+	Iat int64 `json:"iat"`
+	Exp int64 `json:"exp"`
+}
+
+// newRPCAuth returns nil if neither tokens nor a JWT secret are configured.
+func newRPCAuth(tokens []string, jwtSecretFile string) (*rpcAuth, error) {
+	auth := &rpcAuth{tokens: tokens}
+	if jwtSecretFile != "" {
+		data, err := os.ReadFile(jwtSecretFile)
+		if err != nil {
+			return nil, errors.Errorf("failed to read JWT secret. Reason: %v", err)
+		}
+		secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+		if err != nil {
+			return nil, errors.Errorf("failed to decode JWT secret. Reason: %v", err)
+		}
+		if len(secret) < 32 {
+			return nil, errJWTSecretLength
+		}
+		auth.jwtSecret = secret
+	}
+	if len(auth.tokens) == 0 && len(auth.jwtSecret) == 0 {
+		return nil, nil
+	}
+	return auth, nil
+}
+
+func (a *rpcAuth) authenticate(r *http.Request) error {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return errMissingToken
+	}
+	token := strings.TrimPrefix(header, "Bearer ")
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	if len(a.jwtSecret) == 0 {
+		return errInvalidToken
+	}
+	return a.verifyJWT(token, time.Now())
+}
+
+func (a *rpcAuth) verifyJWT(token string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errInvalidJWT
+	}
+	header := new(jwtHeader)
+	if err := decodeJWTPart(parts[0], header); err != nil {
+		return err
+	}
+	if header.Alg != "HS256" {
+		return errUnsupportedAlg
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return errInvalidJWT
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return errInvalidJWTSign
+	}
+
+	claims := new(jwtClaims)
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return err
+	}
+	if claims.Exp != 0 {
+		if !now.Before(time.Unix(claims.Exp, 0)) {
+			return errExpiredJWT
+		}
+		return nil
+	}
+	diff := now.Sub(time.Unix(claims.Iat, 0))
+	if diff > jwtMaxIatDiff || diff < -jwtMaxIatDiff {
+		return errStaleJWT
+	}
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return errInvalidJWT
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errInvalidJWT
+	}
+	return nil
+}
+
+// newAuthHandler rejects the requests which fail the authentication, auth may be nil.
+func newAuthHandler(auth *rpcAuth, next http.Handler) http.Handler {
+	if auth == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := auth.authenticate(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loadTLSConfig returns nil if no certificate is configured.
+func loadTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.Errorf("both the TLS certificate and key must be set")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Errorf("failed to load TLS certificate. Reason: %v", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
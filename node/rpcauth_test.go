@@ -0,0 +1,138 @@
+package node
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+)
+
+type testAuthService struct{}
+
+func (s *testAuthService) Echo(value string) string {
+	return value
+}
+
+// newTestAuthHandler serves the ledger and admin namespaces, only ledger is whitelisted.
+func newTestAuthHandler(t *testing.T, auth *rpcAuth) http.Handler {
+	h := newHTTPServer(rpc.DefaultHTTPTimeouts)
+	apis := []rpc.API{
+		{Namespace: "ledger", Service: new(testAuthService), Public: true},
+		{Namespace: "admin", Service: new(testAuthService), Public: false},
+	}
+	common.DealWithErr(h.enableRPC(apis, httpConfig{Modules: []string{"ledger"}, Vhosts: []string{"*"}, auth: auth}))
+	t.Cleanup(func() { h.disableRPC() })
+	return h.httpHandler.Load().(*rpcHandler)
+}
+
+func testAuthRequest(handler http.Handler, authorization string, method string) *httptest.ResponseRecorder {
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":["hello"]}`
+	request := httptest.NewRequest(http.MethodPost, "http://localhost/", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func testJWT(secret []byte, claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	unsigned := encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + encode(mac.Sum(nil))
+}
+
+// Test bearer tokens
+//   - test requests without authentication are served if no token is configured
+//   - test requests with a missing, malformed or wrong token are rejected
+//   - test requests with a configured token are served
+func TestRPCAuth_Tokens(t *testing.T) {
+	auth, err := newRPCAuth(nil, "")
+	common.DealWithErr(err)
+	if auth != nil {
+		t.Fatal("expected no authentication without tokens")
+	}
+	if recorder := testAuthRequest(newTestAuthHandler(t, auth), "", "ledger.echo"); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", recorder.Code)
+	}
+
+	auth, err = newRPCAuth([]string{"first-token", "second-token"}, "")
+	common.DealWithErr(err)
+	handler := newTestAuthHandler(t, auth)
+	for _, authorization := range []string{"", "second-token", "Basic second-token", "Bearer wrong-token", "Bearer second-token-suffix"} {
+		recorder := testAuthRequest(handler, authorization, "ledger.echo")
+		if recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status 401 for %q, got %v", authorization, recorder.Code)
+		}
+	}
+	recorder := testAuthRequest(handler, "Bearer second-token", "ledger.echo")
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"result":"hello"`) {
+		t.Fatalf("expected the request to be served, got %v %v", recorder.Code, recorder.Body.String())
+	}
+}
+
+// Test JWT
+//   - test secrets shorter than 32 bytes are refused
+//   - test JWTs with a valid signature and iat or exp are accepted
+//   - test stale, expired and wrongly signed JWTs are rejected
+func TestRPCAuth_JWT(t *testing.T) {
+	dir := t.TempDir()
+	short := filepath.Join(dir, "short")
+	common.DealWithErr(os.WriteFile(short, []byte(hex.EncodeToString(make([]byte, 16))), 0600))
+	_, err := newRPCAuth(nil, short)
+	common.ExpectError(t, err, errJWTSecretLength)
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	path := filepath.Join(dir, "jwt.hex")
+	common.DealWithErr(os.WriteFile(path, []byte("0x"+hex.EncodeToString(secret)+"\n"), 0600))
+	auth, err := newRPCAuth(nil, path)
+	common.DealWithErr(err)
+
+	now := time.Unix(1700000000, 0)
+	common.ExpectError(t, auth.verifyJWT(testJWT(secret, `{"iat":1700000010}`), now), nil)
+	common.ExpectError(t, auth.verifyJWT(testJWT(secret, `{"exp":1700000100}`), now), nil)
+	common.ExpectError(t, auth.verifyJWT(testJWT(secret, `{"iat":1699999000}`), now), errStaleJWT)
+	common.ExpectError(t, auth.verifyJWT(testJWT(secret, `{"exp":1700000000}`), now), errExpiredJWT)
+	common.ExpectError(t, auth.verifyJWT(testJWT([]byte("another secret of at least 32 bytes"), `{"iat":1700000000}`), now), errInvalidJWTSign)
+	common.ExpectError(t, auth.verifyJWT("not.a-jwt", now), errInvalidJWT)
+
+	handler := newTestAuthHandler(t, auth)
+	token := testJWT(secret, `{"iat":`+strconv.FormatInt(time.Now().Unix(), 10)+`}`)
+	if recorder := testAuthRequest(handler, "Bearer "+token, "ledger.echo"); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %v", recorder.Code)
+	}
+}
+
+// Test authenticated requests are scoped to the whitelisted namespaces
+//   - test a valid token can't call the methods of a namespace which isn't whitelisted
+func TestRPCAuth_Namespaces(t *testing.T) {
+	auth, err := newRPCAuth([]string{"token"}, "")
+	common.DealWithErr(err)
+	handler := newTestAuthHandler(t, auth)
+
+	recorder := testAuthRequest(handler, "Bearer token", "ledger.echo")
+	if !strings.Contains(recorder.Body.String(), `"result":"hello"`) {
+		t.Fatalf("expected ledger.echo to be served, got %v", recorder.Body.String())
+	}
+	recorder = testAuthRequest(handler, "Bearer token", "admin.echo")
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "does not exist") {
+		t.Fatalf("expected admin.echo to not exist, got %v %v", recorder.Code, recorder.Body.String())
+	}
+	if recorder := testAuthRequest(handler, "", "admin.echo"); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %v", recorder.Code)
+	}
+}
@@ -3,6 +3,7 @@ package node
 import (
 	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -22,16 +23,22 @@ import (
 // httpConfig is the JSON-RPC/HTTP configuration.
 type httpConfig struct {
 	Modules            []string
+	DisabledModules    []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
-	prefix             string // path prefix on which to mount http handler
+	Methods            RPCMethodFilter
+	auth               *rpcAuth // nil if requests are not authenticated
+	prefix             string   // path prefix on which to mount http handler
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins         []string
+	Modules         []string
+	DisabledModules []string
+	Methods         RPCMethodFilter
+	auth            *rpcAuth // nil if requests are not authenticated
+	prefix          string   // path prefix on which to mount ws handler
 }
 
 type rpcHandler struct {
@@ -62,6 +69,8 @@ type httpServer struct {
 	host     string
 	port     int
 
+	tlsConfig *tls.Config // set by setTLS, nil serves plain HTTP
+
 	handlerNames map[string]string
 }
 
@@ -92,6 +101,18 @@ func (h *httpServer) setListenAddr(host string, port int) error {
 	return nil
 }
 
+// setTLS enables TLS on the server, the config can only be set while the server isn't running.
+func (h *httpServer) setTLS(config *tls.Config) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.listener != nil {
+		return fmt.Errorf("HTTP server already running on %s", h.endpoint)
+	}
+	h.tlsConfig = config
+	return nil
+}
+
 // listenAddr returns the listening address of the server.
 func (h *httpServer) listenAddr() string {
 	h.mu.Lock()
@@ -130,11 +151,18 @@ func (h *httpServer) start() error {
 		h.disableWS()
 		return err
 	}
+	if h.tlsConfig != nil {
+		listener = tls.NewListener(listener, h.tlsConfig)
+	}
 	h.listener = listener
 	go h.server.Serve(listener)
 
+	scheme, wsScheme := "http", "ws"
+	if h.tlsConfig != nil {
+		scheme, wsScheme = "https", "wss"
+	}
 	if h.wsAllowed() {
-		url := fmt.Sprintf("ws://%v", listener.Addr())
+		url := fmt.Sprintf("%v://%v", wsScheme, listener.Addr())
 		if h.wsConfig.prefix != "" {
 			url += h.wsConfig.prefix
 		}
@@ -150,6 +178,8 @@ func (h *httpServer) start() error {
 		"prefix", h.httpConfig.prefix,
 		"cors", strings.Join(h.httpConfig.CorsAllowedOrigins, ","),
 		"vhosts", strings.Join(h.httpConfig.Vhosts, ","),
+		"tls", h.tlsConfig != nil,
+		"auth", h.httpConfig.auth != nil,
 	)
 
 	// Log all handlers mounted on server.
@@ -162,7 +192,7 @@ func (h *httpServer) start() error {
 	for _, path := range paths {
 		name := h.handlerNames[path]
 		if !logged[name] {
-			log.Info(name+" enabled", "url", "http://"+listener.Addr().String()+path)
+			log.Info(name+" enabled", "url", scheme+"://"+listener.Addr().String()+path)
 			logged[name] = true
 		}
 	}
@@ -269,12 +299,14 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
-	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
+	if err := RegisterApisFromWhitelist(apis, config.Modules, config.DisabledModules, srv, false); err != nil {
 		return err
 	}
+	config.Methods.apply(srv)
+	srv.CheckAPIKeys()
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts),
+		Handler: NewHTTPHandlerStack(newAuthHandler(config.auth, srv), config.CorsAllowedOrigins, config.Vhosts),
 		server:  srv,
 	})
 	return nil
@@ -301,12 +333,14 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
-	if err := RegisterApisFromWhitelist(apis, config.Modules, srv, false); err != nil {
+	if err := RegisterApisFromWhitelist(apis, config.Modules, config.DisabledModules, srv, false); err != nil {
 		return err
 	}
+	config.Methods.apply(srv)
+	srv.CheckAPIKeys()
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
-		Handler: srv.WebsocketHandler(config.Origins),
+		Handler: newAuthHandler(config.auth, srv.WebsocketHandler(config.Origins)),
 		server:  srv,
 	})
 	return nil
@@ -459,9 +493,60 @@ func newGzipHandler(next http.Handler) http.Handler {
 	})
 }
 
-// RegisterApisFromWhitelist checks the given modules' availability, generates a whitelist based on the allowed modules,
-// and then registers all of the APIs exposed by the services.
-func RegisterApisFromWhitelist(apis []rpc.API, modules []string, srv *rpc.Server, exposeAll bool) error {
+// ipcServer serves JSON-RPC over a UNIX domain socket, or a named pipe on Windows.
+// Access is restricted by the file permissions of the socket.
+type ipcServer struct {
+	log      common.Logger
+	endpoint string
+
+	mu       sync.Mutex
+	listener net.Listener
+	srv      *rpc.Server
+}
+
+func newIPCServer(endpoint string) *ipcServer {
+	return &ipcServer{
+		log:      log,
+		endpoint: endpoint,
+	}
+}
+
+// start opens the IPC endpoint and serves the methods of apis allowed by methods.
+func (is *ipcServer) start(apis []rpc.API, methods RPCMethodFilter) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if is.listener != nil {
+		return nil // already running
+	}
+	listener, srv, err := rpc.StartIPCEndpoint(is.endpoint, apis, methods.allows)
+	if err != nil {
+		is.log.Warn("IPC opening failed", "endpoint", is.endpoint, "reason", err)
+		return err
+	}
+	is.log.Info("IPC endpoint opened", "endpoint", is.endpoint)
+	is.listener, is.srv = listener, srv
+	return nil
+}
+
+func (is *ipcServer) stop() error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	if is.listener == nil {
+		return nil // not running
+	}
+	err := is.listener.Close()
+	is.srv.Stop()
+	is.listener, is.srv = nil, nil
+	is.log.Info("IPC endpoint closed", "endpoint", is.endpoint)
+	return err
+}
+
+// RegisterApisFromWhitelist registers the APIs whose namespace is whitelisted in modules, or
+// all public APIs when no module is given. Namespaces listed in disabled are never registered,
+// an entry also disables all namespaces nested under it (e.g. "embedded" disables "embedded.token").
+func RegisterApisFromWhitelist(apis []rpc.API, modules []string, disabled []string, srv *rpc.Server, exposeAll bool) error {
 	if bad, available := checkModuleAvailability(modules, apis); len(bad) > 0 {
 		log.Error("Unavailable modules in HTTP API list", "unavailable", bad, "available", available)
 	}
@@ -472,6 +557,9 @@ func RegisterApisFromWhitelist(apis []rpc.API, modules []string, srv *rpc.Server
 	}
 	// Register all the APIs exposed by the services
 	for _, api := range apis {
+		if isModuleDisabled(api.Namespace, disabled) {
+			continue
+		}
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := srv.RegisterName(api.Namespace, api.Service); err != nil {
 				return err
@@ -481,6 +569,32 @@ func RegisterApisFromWhitelist(apis []rpc.API, modules []string, srv *rpc.Server
 	return nil
 }
 
+// allows reports whether method passes the filter.
+func (f RPCMethodFilter) allows(method string) bool {
+	if rpc.MatchesMethod(method, f.Denied) {
+		return false
+	}
+	return len(f.Allowed) == 0 || rpc.MatchesMethod(method, f.Allowed)
+}
+
+// apply unregisters the methods of srv which don't pass the filter.
+func (f RPCMethodFilter) apply(srv *rpc.Server) {
+	if len(f.Allowed) == 0 && len(f.Denied) == 0 {
+		return
+	}
+	srv.FilterMethods(f.allows)
+}
+
+// isModuleDisabled reports whether namespace matches, or is nested under, one of the disabled modules.
+func isModuleDisabled(namespace string, disabled []string) bool {
+	for _, module := range disabled {
+		if namespace == module || strings.HasPrefix(namespace, module+".") {
+			return true
+		}
+	}
+	return false
+}
+
 // checkModuleAvailability checks that all names given in modules are actually
 // available API services. It assumes that the MetadataApi module ("rpc") is always available;
 // the registration of this "rpc" module happens in NewServer() and is thus common to all endpoints.
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/common/debug"
+)
+
+// shutdownWatchdog exits the process if the node doesn't stop within the timeout, after writing the goroutine
+// stacks and the state of the p2p loop to a dump, so the shutdowns which hang can be debugged from the field.
+type shutdownWatchdog struct {
+	node    *Node
+	timeout time.Duration
+	timer   *time.Timer
+
+	lock  sync.Mutex
+	step  string
+	start time.Time
+}
+
+func (node *Node) startShutdownWatchdog() *shutdownWatchdog {
+	w := &shutdownWatchdog{
+		node:    node,
+		timeout: time.Duration(node.config.Debug.ShutdownTimeout) * time.Second,
+		step:    "waiting for the node lock",
+		start:   time.Now(),
+	}
+	if w.timeout > 0 {
+		w.timer = time.AfterFunc(w.timeout, w.expire)
+	}
+	return w
+}
+
+// enter records the step of the shutdown in progress.
+func (w *shutdownWatchdog) enter(step string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.step = step
+}
+
+func (w *shutdownWatchdog) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+func (w *shutdownWatchdog) expire() {
+	w.lock.Lock()
+	step := w.step
+	w.lock.Unlock()
+
+	log.Error("the node failed to stop in time", "timeout", w.timeout, "step", step)
+	debug.Handler.SetDir(w.node.config.ProfilesDir())
+	name := fmt.Sprintf("shutdown-%v.dump", time.Now().Format("20060102-150405"))
+	file, err := debug.Handler.WriteStackDump(name, func(out io.Writer) error {
+		return w.writeState(out, step)
+	})
+	if err != nil {
+		log.Error("failed to write the shutdown dump", "reason", err)
+	} else {
+		log.Error("wrote the shutdown dump", "file", file)
+	}
+	os.Exit(1)
+}
+
+func (w *shutdownWatchdog) writeState(out io.Writer, step string) error {
+	if _, err := fmt.Fprintf(out, "=== shutdown ===\nstarted: %v\ntimeout: %v\nstep: %v\n", w.start.Format(time.RFC3339), w.timeout, step); err != nil {
+		return err
+	}
+	if w.node.server == nil {
+		return nil
+	}
+	state, err := json.MarshalIndent(w.node.server.LoopState(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "\n=== p2p loop ===\n%s\n", state)
+	return err
+}
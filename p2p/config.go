@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"crypto/ecdsa"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	log "github.com/inconshreveable/log15"
@@ -22,8 +23,20 @@ const (
 	DefaultMaxPendingPeers   = 10
 	DefaultMinConnectedPeers = 16
 
+	DefaultMaxPeersPerSubnet = 4
+	DefaultMaxInboundPerIP   = 2
+
+	DefaultHandshakeTimeout = 5 // seconds
+
+	DefaultPropagationDiversity = 30 // percent
+
 	DefaultNetDirName        = "network"
 	DefaultNetPrivateKeyFile = "network-private-key"
+
+	// Well known capabilities advertised by the nodes in the discovery DHT.
+	CapabilityArchival  = "archival"
+	CapabilityBridge    = "bridge"
+	CapabilityPublicRPC = "public-rpc"
 )
 
 var (
@@ -163,16 +176,38 @@ type Net struct {
 	// MinConnectedPeers is the minimum number of peers that can be connected
 	MinConnectedPeers int
 
+	// MaxInboundPeers is the budget of the inbound connection slots, zero defaults to a preset value.
+	// The trusted and static peers don't take any slot.
+	MaxInboundPeers int
+
 	// MaxPendingPeers is the maximum number of peers that can be pending in the
 	// handshake phase, counted separately for inbound and outbound connections.
 	// Zero defaults to preset values.
 	MaxPendingPeers int
 
+	// MaxPeersPerSubnet and MaxInboundPerIP limit the peers of a /24 (/64 for IPv6) subnet
+	// and the inbound connections of an IP. Zero means no limit.
+	MaxPeersPerSubnet int
+	MaxInboundPerIP   int
+
+	// HandshakeTimeout is the time budget of a new connection for the encryption and the protocol handshakes.
+	HandshakeTimeout time.Duration
+
+	// UploadRate, DownloadRate, PeerUploadRate and PeerDownloadRate are the bandwidth limits
+	// of all the peers together and of each peer, in bytes per second. Zero means no limit.
+	UploadRate       int
+	DownloadRate     int
+	PeerUploadRate   int
+	PeerDownloadRate int
+
 	// Name sets the node name of this server.
 	Name string
 
 	Seeders []string
 
+	// Capabilities advertised to the network besides the protocol versions.
+	Capabilities []string
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string
@@ -185,6 +220,24 @@ type Net struct {
 	// the server is started.
 	ListenAddr string
 	ListenPort int
+
+	// Proxy routes the outbound connections through a SOCKS5 proxy, socks5://[user:password@]host:port.
+	// Discovery runs over UDP, which isn't proxied, so it's disabled if set.
+	Proxy string
+
+	// Sentries are the relay nodes of a producing node which runs in sentry mode. If set, the node
+	// only peers with them: discovery and the seeders are skipped and other inbound connections are rejected.
+	Sentries []string
+
+	// PrivatePeers are always connected and always get the propagated momentums, like the producing
+	// node behind a sentry node.
+	PrivatePeers []string
+
+	// AEADFrames seals the frames with ChaCha20-Poly1305 on the connections to the peers which support it.
+	AEADFrames bool
+
+	// Telemetry advertises the client version, the OS and architecture and whether the node is archival to the peers.
+	Telemetry bool
 }
 
 // PrivateKey retrieves the currently configured private key of the node, checking
@@ -220,10 +273,27 @@ func (c *Net) PrivateKey() *ecdsa.PrivateKey {
 	}
 	return key
 }
+
+// Dialer returns the dialer of the outbound connections, nil for direct connections.
+func (c *Net) Dialer() (NodeDialer, error) {
+	if c.Proxy == "" {
+		return nil, nil
+	}
+	return NewSOCKS5Dialer(c.Proxy)
+}
 func (c *Net) Nodes() ([]*discover.Node, error) {
+	return parseNodes(c.Seeders)
+}
+func (c *Net) SentryNodes() ([]*discover.Node, error) {
+	return parseNodes(c.Sentries)
+}
+func (c *Net) PrivateNodes() ([]*discover.Node, error) {
+	return parseNodes(c.PrivatePeers)
+}
+func parseNodes(urls []string) ([]*discover.Node, error) {
 	var err error
-	nodes := make([]*discover.Node, len(c.Seeders))
-	for index, nodeAddress := range c.Seeders {
+	nodes := make([]*discover.Node, len(urls))
+	for index, nodeAddress := range urls {
 		nodes[index], err = discover.ParseNode(nodeAddress)
 		if err != nil {
 			return nil, err
@@ -20,7 +20,9 @@ import (
 	"container/heap"
 	"crypto/rand"
 	"fmt"
+	mrand "math/rand"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -30,29 +32,56 @@ import (
 
 const (
 	// This is the amount of time spent waiting in between
-	// redialing a certain node.
-	dialHistoryExpiration = 30 * time.Second
+	// redialing a node after the first failed attempt. Every
+	// following failure doubles the delay, up to maxDialBackoff.
+	initialDialBackoff = 5 * time.Second
+	maxDialBackoff     = 10 * time.Minute
+
+	// Backoff state of a node is forgotten if it wasn't dialed
+	// for this long.
+	dialBackoffReset = 2 * maxDialBackoff
+
+	// A dial that didn't report back in this interval is considered
+	// lost and the node can be dialed again.
+	dialingExpiration = 5 * time.Minute
 
 	// Discovery lookups are throttled and can only run
 	// once every few seconds.
 	lookupInterval = 4 * time.Second
+
+	// Maximum number of nodes advertising the primary protocol
+	// returned by the topic lookup of a discovery task.
+	lookupTopicNodes = 16
 )
 
 // dialstate schedules dials and discovery lookups.
 // it get's a chance to compute new tasks on every iteration
 // of the main loop in Server.run.
 type dialstate struct {
-	maxDynDials int
-	ntab        discoverTable
+	maxDynDials    int
+	maxSubnetPeers int
+	ntab           discoverTable
 
 	lookupRunning bool
 	bootstrapped  bool
 
 	lookupBuf   []*discover.Node // current discovery lookup results
 	randomNodes []*discover.Node // filled from Table
+	previous    []*discover.Node // previous peers, dialed once before the others
+	bootnodes   []*discover.Node // candidates of the dynamic dials if there is no table
 	static      map[discover.NodeID]*discover.Node
+	backoff     map[discover.NodeID]*dialBackoff
 	hist        *dialHistory
 	dialing     *dialHistory
+
+	// subnets of the running dynamic dials, so a subnet isn't filled by dials in flight
+	dialingSubnets map[discover.NodeID]string
+}
+
+// dialBackoff tracks the failed dial attempts of a node.
+type dialBackoff struct {
+	failures uint
+	last     time.Time
 }
 
 type discoverTable interface {
@@ -61,6 +90,10 @@ type discoverTable interface {
 	Bootstrap([]*discover.Node)
 	Lookup(target discover.NodeID, wg *sync.WaitGroup, forceSeed bool) []*discover.Node
 	ReadRandomNodes([]*discover.Node) int
+	SetTopics([]discover.Topic)
+	LookupTopic(topic discover.Topic, max int) []*discover.Node
+	PreviousPeers(n int, protocols []string) []*discover.Node
+	AddPeerConnection(id discover.NodeID, uptime time.Duration, bytesRead uint64, protocols []string)
 }
 
 // the dial history remembers recent dials.
@@ -80,6 +113,7 @@ type task interface {
 type dialTask struct {
 	flags connFlag
 	dest  *discover.Node
+	err   error // set by Do if the node couldn't be added as a peer
 }
 
 // discoverTask runs discovery table operations.
@@ -87,7 +121,8 @@ type dialTask struct {
 //
 // If bootstrap is true, the task runs Table.Bootstrap,
 // otherwise it performs a random lookup and leaves the
-// results in the task.
+// results in the task, after the nodes which advertise
+// the primary protocol of the server.
 type discoverTask struct {
 	bootstrap bool
 	results   []*discover.Node
@@ -99,18 +134,28 @@ type waitExpireTask struct {
 	time.Duration
 }
 
-func newDialState(static []*discover.Node, ntab discoverTable, maxdyn int) *dialstate {
+func newDialState(static, previous, bootnodes []*discover.Node, ntab discoverTable, maxdyn, maxSubnetPeers int) *dialstate {
 	s := &dialstate{
-		maxDynDials: maxdyn,
-		ntab:        ntab,
-		static:      make(map[discover.NodeID]*discover.Node),
-		dialing:     new(dialHistory),
-		randomNodes: make([]*discover.Node, maxdyn/2),
-		hist:        new(dialHistory),
+		maxDynDials:    maxdyn,
+		maxSubnetPeers: maxSubnetPeers,
+		ntab:           ntab,
+		previous:       previous,
+		static:         make(map[discover.NodeID]*discover.Node),
+		backoff:        make(map[discover.NodeID]*dialBackoff),
+		dialing:        new(dialHistory),
+		randomNodes:    make([]*discover.Node, maxdyn/2),
+		hist:           new(dialHistory),
+		dialingSubnets: make(map[discover.NodeID]string),
 	}
 	for _, n := range static {
 		s.static[n.ID] = n
 	}
+	if ntab == nil {
+		s.bootnodes = make([]*discover.Node, len(bootnodes))
+		for i, j := range mrand.Perm(len(bootnodes)) {
+			s.bootnodes[i] = bootnodes[j]
+		}
+	}
 	return s
 }
 
@@ -120,11 +165,21 @@ func (s *dialstate) addStatic(n *discover.Node) {
 
 func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now time.Time) []task {
 	var newtasks []task
+	subnets := s.subnetCounts(peers)
 	addDial := func(flag connFlag, n *discover.Node) bool {
 		if s.dialing.contains(n.ID) || peers[n.ID] != nil || s.hist.contains(n.ID) {
 			return false
 		}
-		s.dialing.add(n.ID, now.Add(dialHistoryExpiration*10))
+		// dynamic dials skip the candidates of the subnets which reached their limit,
+		// the inbound peers of these subnets are rejected by the server the same way
+		if flag == dynDialedConn && s.maxSubnetPeers > 0 && limitedIP(n.IP) {
+			if subnets[subnet(n.IP)] >= s.maxSubnetPeers {
+				return false
+			}
+			subnets[subnet(n.IP)] += 1
+			s.dialingSubnets[n.ID] = subnet(n.IP)
+		}
+		s.dialing.add(n.ID, now.Add(dialingExpiration))
 		newtasks = append(newtasks, &dialTask{flags: flag, dest: n})
 		return true
 	}
@@ -142,17 +197,43 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 	// Expire the dial history on every invocation.
 	s.hist.expire(now)
 	s.dialing.expire(now)
+	for id, b := range s.backoff {
+		if now.Sub(b.last) > dialBackoffReset {
+			delete(s.backoff, id)
+		}
+	}
 
 	// Create dials for static nodes if they are not connected.
 	for _, n := range s.static {
 		addDial(staticDialedConn, n)
 	}
 
+	// Redial the previous peers first, after a restart it takes
+	// a while until discovery finds peers as good as them.
+	for len(s.previous) > 0 && needDynDials > 0 {
+		if addDial(dynDialedConn, s.previous[0]) {
+			needDynDials--
+		}
+		s.previous = s.previous[1:]
+	}
+
+	// Without a table, the bootstrap nodes are dialed instead of the
+	// nodes found by discovery.
+	if s.ntab == nil {
+		s.freshFirst(s.bootnodes)
+		for i := 0; i < len(s.bootnodes) && needDynDials > 0; i++ {
+			if addDial(dynDialedConn, s.bootnodes[i]) {
+				needDynDials--
+			}
+		}
+	}
+
 	// Use random nodes from the table for half of the necessary
 	// dynamic dials.
 	randomCandidates := needDynDials / 2
 	if randomCandidates > 0 && s.bootstrapped {
 		n := s.ntab.ReadRandomNodes(s.randomNodes)
+		s.freshFirst(s.randomNodes[:n])
 		for i := 0; i < randomCandidates && i < n; i++ {
 			if addDial(dynDialedConn, s.randomNodes[i]) {
 				needDynDials--
@@ -161,6 +242,7 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 	}
 	// Create dynamic dials from random lookup results, removing tried
 	// items from the result buffer.
+	s.freshFirst(s.lookupBuf)
 	i := 0
 	for ; i < len(s.lookupBuf) && needDynDials > 0; i++ {
 		if addDial(dynDialedConn, s.lookupBuf[i]) {
@@ -171,7 +253,7 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 	// Launch a discovery lookup if more candidates are needed. The
 	// first discoverTask bootstraps the table and won't return any
 	// results.
-	if len(s.lookupBuf) < needDynDials && !s.lookupRunning {
+	if s.ntab != nil && len(s.lookupBuf) < needDynDials && !s.lookupRunning {
 		s.lookupRunning = true
 		newtasks = append(newtasks, &discoverTask{bootstrap: !s.bootstrapped})
 	}
@@ -187,11 +269,75 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 	return newtasks
 }
 
+// subnetCounts returns the number of peers and running dynamic dials of each subnet.
+func (s *dialstate) subnetCounts(peers map[discover.NodeID]*Peer) map[string]int {
+	if s.maxSubnetPeers <= 0 {
+		return nil
+	}
+	counts := subnetPeers(peers)
+	for id, subnet := range s.dialingSubnets {
+		// the dials which expired were lost
+		if !s.dialing.contains(id) {
+			delete(s.dialingSubnets, id)
+			continue
+		}
+		counts[subnet] += 1
+	}
+	return counts
+}
+
+// freshFirst orders the candidates so nodes without failed dials
+// come before the ones that are backing off.
+func (s *dialstate) freshFirst(nodes []*discover.Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return s.failures(nodes[i].ID) < s.failures(nodes[j].ID)
+	})
+}
+
+func (s *dialstate) failures(id discover.NodeID) uint {
+	if b, ok := s.backoff[id]; ok {
+		return b.failures
+	}
+	return 0
+}
+
+// backoffDelay returns the time to wait before dialing a node which
+// failed the given number of consecutive times. Up to a quarter of
+// the delay is added as jitter so that nodes which failed at the same
+// time are not redialed in lockstep.
+func backoffDelay(failures uint) time.Duration {
+	delay := maxDialBackoff
+	if failures < 16 {
+		if d := initialDialBackoff << (failures - 1); d < maxDialBackoff {
+			delay = d
+		}
+	}
+	return delay + time.Duration(mrand.Int63n(int64(delay/4)+1))
+}
+
 func (s *dialstate) taskDone(t task, now time.Time) {
 	switch t := t.(type) {
 	case *dialTask:
-		s.hist.add(t.dest.ID, now.Add(dialHistoryExpiration))
+		if t.err == nil {
+			delete(s.backoff, t.dest.ID)
+			s.hist.add(t.dest.ID, now.Add(initialDialBackoff))
+		} else {
+			b, ok := s.backoff[t.dest.ID]
+			if !ok {
+				b = new(dialBackoff)
+				s.backoff[t.dest.ID] = b
+			}
+			b.failures++
+			b.last = now
+			delay := backoffDelay(b.failures)
+			if t.err == DiscNetworkMismatch {
+				// the node runs another chain, it won't become useful soon
+				delay = maxDialBackoff
+			}
+			s.hist.add(t.dest.ID, now.Add(delay))
+		}
 		s.dialing.remove(t.dest.ID)
+		delete(s.dialingSubnets, t.dest.ID)
 	case *discoverTask:
 		if t.bootstrap {
 			s.bootstrapped = true
@@ -207,11 +353,15 @@ func (t *dialTask) Do(srv *Server) {
 	fd, err := srv.Dialer.Dial("tcp", addr.String())
 	if err != nil {
 		common.P2PLogger.Debug(fmt.Sprintf("dial error: %v", err))
+		t.err = err
 		return
 	}
-	mfd := newMeteredConn(fd, false)
+	mfd := srv.newMeteredConn(fd, false)
 
-	srv.setupConn(mfd, t.flags, t.dest)
+	// A node we're already connected to is reachable, don't back off.
+	if err := srv.setupConn(mfd, t.flags, t.dest); err != DiscAlreadyConnected {
+		t.err = err
+	}
 }
 func (t *dialTask) String() string {
 	return fmt.Sprintf("%v %x %v:%d", t.flags, t.dest.ID[:8], t.dest.IP, t.dest.TCP)
@@ -233,6 +383,11 @@ func (t *discoverTask) Do(srv *Server) {
 	var target discover.NodeID
 	rand.Read(target[:])
 	t.results = srv.ntab.Lookup(target, &srv.loopWG, false)
+	// the nodes which run the protocol are dialed first, they are likely to become peers
+	if len(srv.Protocols) > 0 {
+		topic := discover.Topic(srv.Protocols[0].cap().String())
+		t.results = append(srv.ntab.LookupTopic(topic, lookupTopicNodes), t.results...)
+	}
 }
 
 func (t *discoverTask) String() (s string) {
@@ -0,0 +1,94 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Number of disconnects remembered by the server.
+const maxRecentDisconnects = 128
+
+// Disconnect describes the end of a peer session.
+// Remote is true if the peer requested the disconnect, Error is the error which ended the session.
+type Disconnect struct {
+	PublicKey string   `json:"publicKey"`
+	IP        string   `json:"ip"`
+	Name      string   `json:"name"`
+	Inbound   bool     `json:"inbound"`
+	Version   uint64   `json:"version"`   // base protocol version of the peer
+	Protocols []string `json:"protocols"` // protocols which ran with the peer, in their Cap notation
+	Reason    string   `json:"reason"`
+	Remote    bool     `json:"remote"`
+	Error     string   `json:"error,omitempty"`
+	Duration  int64    `json:"duration"` // session duration in seconds
+	Timestamp int64    `json:"timestamp"`
+}
+
+func newDisconnect(p *Peer, reason DiscReason) *Disconnect {
+	ip := p.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	disconnect := &Disconnect{
+		PublicKey: p.ID().String(),
+		IP:        ip,
+		Name:      p.Name(),
+		Inbound:   p.Inbound(),
+		Version:   p.Version(),
+		Protocols: p.Protocols(),
+		Reason:    reason.String(),
+		Remote:    p.discRemote,
+		Duration:  int64(p.Uptime() / time.Second),
+		Timestamp: p.ended.Unix(),
+	}
+	// the reason already describes disconnects requested without an underlying error
+	if p.discErr != nil && p.discErr != reason {
+		disconnect.Error = p.discErr.Error()
+	}
+	return disconnect
+}
+
+// disconnectLog is a ring buffer of the most recent disconnects.
+type disconnectLog struct {
+	lock    sync.Mutex
+	entries []*Disconnect
+	next    int
+}
+
+func (l *disconnectLog) add(disconnect *Disconnect) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if len(l.entries) < maxRecentDisconnects {
+		l.entries = append(l.entries, disconnect)
+		return
+	}
+	l.entries[l.next] = disconnect
+	l.next = (l.next + 1) % maxRecentDisconnects
+}
+
+// recent returns the disconnects, the most recent first.
+func (l *disconnectLog) recent() []*Disconnect {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	result := make([]*Disconnect, 0, len(l.entries))
+	for i := 1; i <= len(l.entries); i += 1 {
+		result = append(result, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+	return result
+}
+
+// RecentDisconnects returns the last disconnects of the server, the most recent first.
+func (srv *Server) RecentDisconnects() []*Disconnect {
+	return srv.disconnects.recent()
+}
+
+// LastDisconnect returns the most recent disconnect of the peer with the public key, nil if it's not remembered.
+func (srv *Server) LastDisconnect(publicKey string) *Disconnect {
+	for _, disconnect := range srv.disconnects.recent() {
+		if disconnect.PublicKey == publicKey {
+			return disconnect
+		}
+	}
+	return nil
+}
@@ -24,6 +24,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -41,9 +43,10 @@ import (
 )
 
 var (
-	nodeDBNilNodeID      = NodeID{}       // Special node ID to use as a nil element.
-	nodeDBNodeExpiration = 24 * time.Hour // Time after which an unseen node should be dropped.
-	nodeDBCleanupCycle   = time.Hour      // Time period for running the expiration task.
+	nodeDBNilNodeID      = NodeID{}           // Special node ID to use as a nil element.
+	nodeDBNodeExpiration = 24 * time.Hour     // Time after which an unseen node should be dropped.
+	nodeDBPeerExpiration = 7 * 24 * time.Hour // Time after which a node which was a peer should be dropped.
+	nodeDBCleanupCycle   = time.Hour          // Time period for running the expiration task.
 )
 
 // nodeDB stores all nodes we know about.
@@ -66,8 +69,30 @@ var (
 	nodeDBDiscoverPing      = nodeDBDiscoverRoot + ":lastping"
 	nodeDBDiscoverPong      = nodeDBDiscoverRoot + ":lastpong"
 	nodeDBDiscoverFindFails = nodeDBDiscoverRoot + ":findfail"
+
+	nodeDBPeerRoot      = ":peer"
+	nodeDBPeerUptime    = nodeDBPeerRoot + ":uptime"
+	nodeDBPeerBytesRead = nodeDBPeerRoot + ":read"
+	nodeDBPeerLastConn  = nodeDBPeerRoot + ":lastconn"
+	nodeDBPeerProtocols = nodeDBPeerRoot + ":protocols"
 )
 
+// PeerStats is the connection history of a node which was a peer.
+type PeerStats struct {
+	Uptime        time.Duration // Total time connected, over all the connections
+	BytesRead     uint64        // Total bytes received, over all the connections
+	LastConnected time.Time     // Time the last connection ended at
+	Protocols     []string      // Protocols which ran during the last connection
+}
+
+// Throughput returns the average number of bytes received per second while connected.
+func (s *PeerStats) Throughput() uint64 {
+	if seconds := uint64(s.Uptime / time.Second); seconds > 0 {
+		return s.BytesRead / seconds
+	}
+	return 0
+}
+
 // newNodeDB creates a new node database for storing and retrieving infos about
 // known peers in the network. If no path is given, an in-memory, temporary
 // database is constructed.
@@ -250,6 +275,7 @@ func (db *nodeDB) expirer() {
 // been seen (i.e. received a pong from) for some alloted time.
 func (db *nodeDB) expireNodes() error {
 	threshold := time.Now().Add(-nodeDBNodeExpiration)
+	peerThreshold := time.Now().Add(-nodeDBPeerExpiration)
 
 	// Find discovered nodes that are older than the allowance
 	it := db.lvl.NewIterator(nil, nil)
@@ -261,11 +287,14 @@ func (db *nodeDB) expireNodes() error {
 		if field != nodeDBDiscoverRoot {
 			continue
 		}
-		// Skip the node if not expired yet (and not self)
+		// Skip the node if not expired yet (and not self), former peers are kept longer
 		if bytes.Compare(id[:], db.self[:]) != 0 {
 			if seen := db.lastPong(id); seen.After(threshold) {
 				continue
 			}
+			if connected := db.peerStats(id).LastConnected; connected.After(peerThreshold) {
+				continue
+			}
 		}
 		// Otherwise delete all associated information
 		db.deleteNode(id)
@@ -304,6 +333,92 @@ func (db *nodeDB) updateFindFails(id NodeID, fails int) error {
 	return db.storeInt64(makeKey(id, nodeDBDiscoverFindFails), int64(fails))
 }
 
+// peerStats retrieves the connection history of a node, it's empty if the node was never a peer.
+func (db *nodeDB) peerStats(id NodeID) *PeerStats {
+	stats := &PeerStats{
+		Uptime:        time.Duration(db.fetchInt64(makeKey(id, nodeDBPeerUptime))) * time.Second,
+		BytesRead:     uint64(db.fetchInt64(makeKey(id, nodeDBPeerBytesRead))),
+		LastConnected: time.Unix(db.fetchInt64(makeKey(id, nodeDBPeerLastConn)), 0),
+	}
+	if blob, err := db.lvl.Get(makeKey(id, nodeDBPeerProtocols), nil); err == nil && len(blob) > 0 {
+		stats.Protocols = strings.Split(string(blob), ",")
+	}
+	return stats
+}
+
+// addPeerConnection adds a finished connection to the history of a node.
+func (db *nodeDB) addPeerConnection(id NodeID, uptime time.Duration, bytesRead uint64, protocols []string, end time.Time) error {
+	stats := db.peerStats(id)
+	if err := db.storeInt64(makeKey(id, nodeDBPeerUptime), int64((stats.Uptime+uptime)/time.Second)); err != nil {
+		return err
+	}
+	if err := db.storeInt64(makeKey(id, nodeDBPeerBytesRead), int64(stats.BytesRead+bytesRead)); err != nil {
+		return err
+	}
+	if err := db.storeInt64(makeKey(id, nodeDBPeerLastConn), end.Unix()); err != nil {
+		return err
+	}
+	return db.lvl.Put(makeKey(id, nodeDBPeerProtocols), []byte(strings.Join(protocols, ",")), nil)
+}
+
+// queryPeers retrieves up to n nodes which were peers since the peer expiration and ran one of
+// the protocols during their last connection, any protocol if none are given. The ones with
+// the longest uptime come first and the ones with the highest throughput among equals.
+func (db *nodeDB) queryPeers(n int, protocols []string) []*Node {
+	threshold := time.Now().Add(-nodeDBPeerExpiration)
+
+	type candidate struct {
+		node  *Node
+		stats *PeerStats
+	}
+	var candidates []candidate
+	it := db.lvl.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		id, field := splitKey(it.Key())
+		if field != nodeDBPeerLastConn || bytes.Equal(id[:], db.self[:]) {
+			continue
+		}
+		stats := db.peerStats(id)
+		if !stats.LastConnected.After(threshold) || !ranAnyOf(stats.Protocols, protocols) {
+			continue
+		}
+		// Inbound peers whose endpoint was never discovered can't be dialed
+		if node := db.node(id); node != nil && node.TCP != 0 {
+			candidates = append(candidates, candidate{node, stats})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].stats, candidates[j].stats
+		if a.Uptime != b.Uptime {
+			return a.Uptime > b.Uptime
+		}
+		return a.Throughput() > b.Throughput()
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	nodes := make([]*Node, 0, len(candidates))
+	for _, c := range candidates {
+		nodes = append(nodes, c.node)
+	}
+	return nodes
+}
+
+func ranAnyOf(ran, protocols []string) bool {
+	if len(protocols) == 0 {
+		return true
+	}
+	for _, r := range ran {
+		for _, p := range protocols {
+			if r == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // querySeeds retrieves a batch of nodes to be used as potential seed servers
 // during bootstrapping the node into the network.
 //
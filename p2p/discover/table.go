@@ -64,6 +64,13 @@ type Table struct {
 	net  transport
 	self *Node // metadata of the local node
 
+	selfMu sync.Mutex
+	ext    *Node // local node as advertised, its IP follows the external IP changes
+
+	topics    *topicTable // registrations of the remote nodes
+	topicMu   sync.Mutex  // protects ownTopics
+	ownTopics []Topic     // topics advertised by the local node
+
 	wg sync.WaitGroup
 }
 
@@ -80,6 +87,8 @@ type transport interface {
 	ping(NodeID, *net.UDPAddr) error
 	waitping(NodeID) error
 	findnode(toid NodeID, addr *net.UDPAddr, target NodeID) ([]*Node, error)
+	topicRegister(toid NodeID, addr *net.UDPAddr, topics []Topic) error
+	topicQuery(toid NodeID, addr *net.UDPAddr, topic Topic) ([]*Node, error)
 	close()
 }
 
@@ -105,7 +114,9 @@ func newTable(t transport, ourID NodeID, ourAddr *net.UDPAddr, nodeDBPath string
 		closing:   make(chan struct{}),
 		bonding:   make(map[NodeID]*bondproc),
 		bondslots: make(chan struct{}, maxBondingPingPongs),
+		topics:    newTopicTable(),
 	}
+	tab.ext = tab.self
 	for i := 0; i < cap(tab.bondslots); i++ {
 		tab.bondslots <- struct{}{}
 	}
@@ -118,7 +129,16 @@ func newTable(t transport, ourID NodeID, ourAddr *net.UDPAddr, nodeDBPath string
 // Self returns the local node.
 // The returned node should not be modified by the caller.
 func (tab *Table) Self() *Node {
-	return tab.self
+	tab.selfMu.Lock()
+	defer tab.selfMu.Unlock()
+	return tab.ext
+}
+
+// setSelfIP changes the IP of the local node returned by Self.
+func (tab *Table) setSelfIP(ip net.IP) {
+	tab.selfMu.Lock()
+	defer tab.selfMu.Unlock()
+	tab.ext = newNode(tab.self.ID, ip, tab.self.UDP, tab.self.TCP)
 }
 
 // ReadRandomNodes fills the given slice with random nodes from the
@@ -159,6 +179,24 @@ func (tab *Table) ReadRandomNodes(buf []*Node) (n int) {
 	return i + 1
 }
 
+// PreviousPeers returns up to n nodes which were peers recently and ran one of the protocols,
+// the ones with the best connection history first. They can be dialed before the table is filled.
+func (tab *Table) PreviousPeers(n int, protocols []string) []*Node {
+	return tab.db.queryPeers(n, protocols)
+}
+
+// PeerStats returns the connection history of the node.
+func (tab *Table) PeerStats(id NodeID) *PeerStats {
+	return tab.db.peerStats(id)
+}
+
+// AddPeerConnection records a finished connection in the history of the node.
+func (tab *Table) AddPeerConnection(id NodeID, uptime time.Duration, bytesRead uint64, protocols []string) {
+	if err := tab.db.addPeerConnection(id, uptime, bytesRead, protocols, time.Now()); err != nil {
+		common.P2PLogger.Warn("failed to store peer connection", "id", id, "reason", err)
+	}
+}
+
 func randUint(max uint32) uint32 {
 	if max == 0 {
 		return 0
@@ -0,0 +1,189 @@
+package discover
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+const (
+	maxTopics      = 8   // Maximum number of topics registered in a single packet
+	maxTopicLength = 64  // Maximum length of a topic name
+	maxTopicNodes  = 64  // Maximum number of nodes stored per topic
+	maxTableTopics = 256 // Maximum number of topics stored, the registrations of new topics are dropped past it
+	topicRegistrar = 8   // Number of nodes closest to the topic the registrations are sent to
+
+	topicTTL             = 30 * time.Minute
+	topicRefreshInterval = 10 * time.Minute
+)
+
+// Topic names a capability which nodes advertise in the DHT, for example
+// a protocol version ("eth/62") or a service ("archival", "public-rpc").
+type Topic string
+
+// target returns the DHT location of the topic. Registrations are stored by the
+// nodes closest to it, which are the ones asked by the lookups.
+func (t Topic) target() (id NodeID) {
+	copy(id[:], crypto.Keccak512([]byte(t)))
+	return id
+}
+
+type topicEntry struct {
+	node    *Node
+	expires time.Time
+}
+
+// topicTable stores the topic registrations received from the remote nodes.
+type topicTable struct {
+	mu      sync.Mutex
+	entries map[Topic]map[NodeID]*topicEntry
+}
+
+func newTopicTable() *topicTable {
+	return &topicTable{entries: make(map[Topic]map[NodeID]*topicEntry)}
+}
+
+// add registers the node for the topic. If the topic is full, the registration
+// which expires first is evicted. The registrations of a new topic are dropped
+// while the table is full, even once the expired registrations are removed.
+func (tt *topicTable) add(topic Topic, n *Node, now time.Time) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	nodes := tt.entries[topic]
+	if nodes == nil {
+		if len(tt.entries) >= maxTableTopics {
+			tt.expire(now)
+		}
+		if len(tt.entries) >= maxTableTopics {
+			return
+		}
+		nodes = make(map[NodeID]*topicEntry)
+		tt.entries[topic] = nodes
+	}
+	if _, ok := nodes[n.ID]; !ok && len(nodes) >= maxTopicNodes {
+		var oldest *topicEntry
+		for _, e := range nodes {
+			if oldest == nil || e.expires.Before(oldest.expires) {
+				oldest = e
+			}
+		}
+		delete(nodes, oldest.node.ID)
+	}
+	nodes[n.ID] = &topicEntry{node: n, expires: now.Add(topicTTL)}
+}
+
+// get returns up to max nodes registered for the topic, dropping the expired registrations.
+func (tt *topicTable) get(topic Topic, max int, now time.Time) []*Node {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	nodes := tt.entries[topic]
+	result := make([]*Node, 0, len(nodes))
+	for id, e := range nodes {
+		if now.After(e.expires) {
+			delete(nodes, id)
+			continue
+		}
+		if len(result) < max {
+			result = append(result, e.node)
+		}
+	}
+	if len(nodes) == 0 {
+		delete(tt.entries, topic)
+	}
+	return result
+}
+
+// expire drops the expired registrations and the topics left without any, tt.mu must be held.
+func (tt *topicTable) expire(now time.Time) {
+	for topic, nodes := range tt.entries {
+		for id, e := range nodes {
+			if now.After(e.expires) {
+				delete(nodes, id)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(tt.entries, topic)
+		}
+	}
+}
+
+// SetTopics sets the topics the local node advertises. They are registered
+// with the nodes closest to each topic right away and then periodically.
+func (tab *Table) SetTopics(topics []Topic) {
+	if len(topics) > maxTopics {
+		topics = topics[:maxTopics]
+	}
+	tab.topicMu.Lock()
+	tab.ownTopics = append([]Topic{}, topics...)
+	tab.topicMu.Unlock()
+
+	tab.wg.Add(1)
+	go func() {
+		tab.advertiseTopics()
+		tab.wg.Done()
+	}()
+}
+
+// advertiseTopics registers the topics of the local node with the nodes closest to each topic.
+func (tab *Table) advertiseTopics() {
+	tab.topicMu.Lock()
+	topics := tab.ownTopics
+	tab.topicMu.Unlock()
+
+	for _, topic := range topics {
+		select {
+		case <-tab.closing:
+			return
+		default:
+		}
+		registrars := tab.Lookup(topic.target(), &tab.wg, false)
+		if len(registrars) > topicRegistrar {
+			registrars = registrars[:topicRegistrar]
+		}
+		for _, n := range registrars {
+			if err := tab.net.topicRegister(n.ID, n.addr(), []Topic{topic}); err != nil {
+				common.P2PLogger.Debug(fmt.Sprintf("Failed to register topic %v with %x: %v", topic, n.ID[:8], err))
+			}
+		}
+	}
+}
+
+// LookupTopic searches the network for up to max nodes which advertise the topic.
+// The local node is never part of the result.
+func (tab *Table) LookupTopic(topic Topic, max int) []*Node {
+	var (
+		seen   = map[NodeID]bool{tab.self.ID: true}
+		result = make([]*Node, 0, max)
+	)
+	push := func(nodes []*Node) {
+		for _, n := range nodes {
+			if len(result) < max && !seen[n.ID] {
+				seen[n.ID] = true
+				result = append(result, n)
+			}
+		}
+	}
+	push(tab.topics.get(topic, max, time.Now()))
+
+	registrars := tab.Lookup(topic.target(), &tab.wg, false)
+	if len(registrars) > topicRegistrar {
+		registrars = registrars[:topicRegistrar]
+	}
+	reply := make(chan []*Node, len(registrars))
+	for _, n := range registrars {
+		go func(n *Node) {
+			nodes, _ := tab.net.topicQuery(n.ID, n.addr(), topic)
+			reply <- nodes
+		}(n)
+	}
+	for range registrars {
+		push(<-reply)
+	}
+	return result
+}
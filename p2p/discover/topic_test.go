@@ -0,0 +1,78 @@
+package discover
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func testTopicNode(i int) *Node {
+	var id NodeID
+	id[0], id[1] = byte(i>>8), byte(i)
+	return newNode(id, net.IPv4(127, 0, 0, 1), 30303, 30303)
+}
+
+// Test topicTable
+//   - test the registration which expires first is evicted from a full topic
+//   - test the registrations of a new topic are dropped while the table is full
+//   - test the expired registrations make room for a new topic
+func TestTopicTable_Bounds(t *testing.T) {
+	tt := newTopicTable()
+	now := time.Now()
+
+	for i := 0; i <= maxTopicNodes; i += 1 {
+		tt.add("zenon/1", testTopicNode(i), now.Add(time.Duration(i)*time.Second))
+	}
+	nodes := tt.get("zenon/1", maxTopicNodes+1, now)
+	if len(nodes) != maxTopicNodes {
+		t.Fatalf("expected %v nodes, got %v", maxTopicNodes, len(nodes))
+	}
+	for _, n := range nodes {
+		if n.ID == testTopicNode(0).ID {
+			t.Fatal("the oldest registration wasn't evicted")
+		}
+	}
+
+	for i := 1; i < maxTableTopics; i += 1 {
+		tt.add(Topic(fmt.Sprintf("topic/%v", i)), testTopicNode(i), now)
+	}
+	tt.add("topic/new", testTopicNode(0), now)
+	if len(tt.entries) != maxTableTopics {
+		t.Fatalf("expected %v topics, got %v", maxTableTopics, len(tt.entries))
+	}
+	if nodes := tt.get("topic/new", 1, now); len(nodes) != 0 {
+		t.Fatal("registered a new topic in a full table")
+	}
+
+	// everything but the latest registrations of zenon/1 expired
+	later := now.Add(topicTTL + 10*time.Second)
+	tt.add("topic/new", testTopicNode(0), later)
+	if nodes := tt.get("topic/new", 1, later); len(nodes) != 1 {
+		t.Fatal("expired registrations didn't make room for a new topic")
+	}
+	if len(tt.entries) != 2 {
+		t.Fatalf("expected 2 topics, got %v", len(tt.entries))
+	}
+}
+
+// Test topicTable expire
+//   - test the expired registrations and the empty topics are dropped
+func TestTopicTable_Expire(t *testing.T) {
+	tt := newTopicTable()
+	now := time.Now()
+	tt.add("zenon/1", testTopicNode(1), now)
+	tt.add("zenon/1", testTopicNode(2), now.Add(time.Minute))
+	tt.add("archival", testTopicNode(3), now)
+
+	tt.mu.Lock()
+	tt.expire(now.Add(topicTTL + time.Second))
+	tt.mu.Unlock()
+
+	if len(tt.entries) != 1 || len(tt.entries["zenon/1"]) != 1 {
+		t.Fatalf("unexpected entries after expire %v", tt.entries)
+	}
+	if _, ok := tt.entries["zenon/1"][testTopicNode(2).ID]; !ok {
+		t.Fatal("dropped a registration which didn't expire")
+	}
+}
@@ -46,6 +46,8 @@ var (
 	errUnknownNode      = errors.New("unknown node")
 	errTimeout          = errors.New("RPC timeout")
 	errClosed           = errors.New("socket closed")
+	errTooManyTopics    = errors.New("too many topics")
+	errTopicTooLong     = errors.New("topic too long")
 )
 
 // Timeouts
@@ -63,6 +65,9 @@ const (
 	pongPacket
 	findnodePacket
 	neighborsPacket
+	topicRegisterPacket
+	topicQueryPacket
+	topicNodesPacket
 )
 
 // RPC request structures
@@ -96,6 +101,26 @@ type (
 		Expiration uint64
 	}
 
+	// topicRegister advertises that the sender supports the topics.
+	topicRegister struct {
+		Topics     []Topic
+		TCP        uint16 // for RLPx protocol
+		Expiration uint64
+	}
+
+	// topicQuery is a query for the nodes registered for the topic.
+	topicQuery struct {
+		Topic      Topic
+		Expiration uint64
+	}
+
+	// reply to topicQuery
+	topicNodes struct {
+		Topic      Topic
+		Nodes      []rpcNode
+		Expiration uint64
+	}
+
 	rpcNode struct {
 		IP  net.IP // len 4 for IPv4 or 16 for IPv6
 		UDP uint16 // for discovery protocol
@@ -147,6 +172,7 @@ type udp struct {
 	conn        conn
 	priv        *ecdsa.PrivateKey
 	ourEndpoint rpcEndpoint
+	endpointMu  sync.Mutex
 
 	addpending chan *pending
 	gotreply   chan reply
@@ -228,10 +254,18 @@ func newUDP(priv *ecdsa.PrivateKey, c conn, natm nat.Interface, nodeDBPath strin
 				udp.wg.Done()
 			}()
 		}
-		// TODO: react to external IP changes over time.
+		var known net.IP
 		if ext, err := natm.ExternalIP(); err == nil {
 			realaddr = &net.UDPAddr{IP: ext, Port: realaddr.Port}
+			known = ext
 		}
+		defer func() {
+			udp.wg.Add(1)
+			go func() {
+				nat.WatchExternalIP(natm, udp.closing, known, udp.setExternalIP)
+				udp.wg.Done()
+			}()
+		}()
 	}
 	// TODO: separate TCP port
 	udp.ourEndpoint = makeEndpoint(realaddr, uint16(realaddr.Port))
@@ -258,13 +292,26 @@ func (t *udp) close() {
 	// TODO: wait for the loops to end.
 }
 
+// setExternalIP updates the endpoint advertised to other nodes.
+func (t *udp) setExternalIP(ip net.IP) {
+	t.endpointMu.Lock()
+	t.ourEndpoint = makeEndpoint(&net.UDPAddr{IP: ip, Port: int(t.ourEndpoint.UDP)}, t.ourEndpoint.TCP)
+	t.endpointMu.Unlock()
+	t.Table.setSelfIP(ip)
+}
+func (t *udp) endpoint() rpcEndpoint {
+	t.endpointMu.Lock()
+	defer t.endpointMu.Unlock()
+	return t.ourEndpoint
+}
+
 // ping sends a ping message to the given node and waits for a reply.
 func (t *udp) ping(toid NodeID, toaddr *net.UDPAddr) error {
 	// TODO: maybe check for ReplyTo field in callback to measure RTT
 	errc := t.pending(toid, pongPacket, func(interface{}) bool { return true })
 	t.send(toaddr, pingPacket, ping{
 		Version:    Version,
-		From:       t.ourEndpoint,
+		From:       t.endpoint(),
 		To:         makeEndpoint(toaddr, 0), // TODO: maybe use known TCP port from DB
 		Expiration: uint64(time.Now().Add(expiration).Unix()),
 	})
@@ -298,6 +345,39 @@ func (t *udp) findnode(toid NodeID, toaddr *net.UDPAddr, target NodeID) ([]*Node
 	return nodes, err
 }
 
+// topicRegister advertises the topics to the given node. No reply is expected,
+// the registrations are renewed periodically.
+func (t *udp) topicRegister(toid NodeID, toaddr *net.UDPAddr, topics []Topic) error {
+	return t.send(toaddr, topicRegisterPacket, topicRegister{
+		Topics:     topics,
+		TCP:        t.endpoint().TCP,
+		Expiration: uint64(time.Now().Add(expiration).Unix()),
+	})
+}
+
+// topicQuery asks the given node for the nodes registered for the topic.
+func (t *udp) topicQuery(toid NodeID, toaddr *net.UDPAddr, topic Topic) ([]*Node, error) {
+	var nodes []*Node
+	errc := t.pending(toid, topicNodesPacket, func(r interface{}) bool {
+		reply := r.(*topicNodes)
+		if reply.Topic != topic {
+			return false
+		}
+		for _, rn := range reply.Nodes {
+			if n, valid := nodeFromRPC(rn); valid {
+				nodes = append(nodes, n)
+			}
+		}
+		return true
+	})
+	t.send(toaddr, topicQueryPacket, topicQuery{
+		Topic:      topic,
+		Expiration: uint64(time.Now().Add(expiration).Unix()),
+	})
+	err := <-errc
+	return nodes, err
+}
+
 // pending adds a reply callback to the pending reply queue.
 // see the documentation of type pending for a detailed explanation.
 func (t *udp) pending(id NodeID, ptype byte, callback func(interface{}) bool) <-chan error {
@@ -331,8 +411,10 @@ func (t *udp) loop() {
 		nextDeadline time.Time
 		timeout      = time.NewTimer(0)
 		refresh      = time.NewTicker(refreshInterval)
+		topics       = time.NewTicker(topicRefreshInterval)
 	)
 	<-timeout.C // ignore first timeout
+	defer topics.Stop()
 	defer refresh.Stop()
 	defer timeout.Stop()
 
@@ -354,6 +436,16 @@ func (t *udp) loop() {
 				t.wg.Done()
 			}()
 
+		case <-topics.C:
+			t.topics.mu.Lock()
+			t.topics.expire(time.Now())
+			t.topics.mu.Unlock()
+			t.wg.Add(1)
+			go func() {
+				t.advertiseTopics()
+				t.wg.Done()
+			}()
+
 		case <-t.closing:
 			for _, p := range pending {
 				p.errc <- errClosed
@@ -516,6 +608,12 @@ func decodePacket(buf []byte) (packet, NodeID, []byte, error) {
 		req = new(findnode)
 	case neighborsPacket:
 		req = new(neighbors)
+	case topicRegisterPacket:
+		req = new(topicRegister)
+	case topicQueryPacket:
+		req = new(topicQuery)
+	case topicNodesPacket:
+		req = new(topicNodes)
 	default:
 		return nil, fromID, hash, fmt.Errorf("unknown type: %d", ptype)
 	}
@@ -598,6 +696,57 @@ func (req *neighbors) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byt
 	return nil
 }
 
+func (req *topicRegister) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byte) error {
+	if expired(req.Expiration) {
+		return errExpired
+	}
+	if t.db.node(fromID) == nil {
+		// Only bonded nodes can register, the endpoint of the registration
+		// is the one the bond was established with.
+		return errUnknownNode
+	}
+	if len(req.Topics) > maxTopics {
+		return errTooManyTopics
+	}
+	for _, topic := range req.Topics {
+		if len(topic) > maxTopicLength {
+			return errTopicTooLong
+		}
+	}
+	n := newNode(fromID, from.IP, uint16(from.Port), req.TCP)
+	now := time.Now()
+	for _, topic := range req.Topics {
+		t.topics.add(topic, n, now)
+	}
+	return nil
+}
+
+func (req *topicQuery) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byte) error {
+	if expired(req.Expiration) {
+		return errExpired
+	}
+	if t.db.node(fromID) == nil {
+		// No bond exists, see findnode.
+		return errUnknownNode
+	}
+	p := topicNodes{Topic: req.Topic, Expiration: uint64(time.Now().Add(expiration).Unix())}
+	for _, n := range t.topics.get(req.Topic, maxNeighbors, time.Now()) {
+		p.Nodes = append(p.Nodes, nodeToRPC(n))
+	}
+	t.send(from, topicNodesPacket, p)
+	return nil
+}
+
+func (req *topicNodes) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byte) error {
+	if expired(req.Expiration) {
+		return errExpired
+	}
+	if !t.handleReply(fromID, topicNodesPacket, req) {
+		return errUnsolicitedReply
+	}
+	return nil
+}
+
 func expired(ts uint64) bool {
 	return time.Unix(int64(ts), 0).Before(time.Now())
 }
@@ -0,0 +1,132 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+// Stages of the run loop, reported by LoopState.
+const (
+	loopStageRunning    = "running"
+	loopStageSpindown   = "waiting for the peers to disconnect"
+	loopStageDiscovery  = "closing the discovery table"
+	loopStageStopped    = "stopped"
+	loopStageNotStarted = "not started"
+)
+
+// loopState mirrors the state of the run loop so it can be read while the loop is stuck.
+type loopState struct {
+	lock         sync.Mutex
+	stage        string
+	peers        map[discover.NodeID]*Peer
+	runningTasks int
+	queuedTasks  int
+	taskdone     chan task
+}
+
+func (s *loopState) setStage(stage string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stage = stage
+}
+func (s *loopState) setTasks(running, queued int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.runningTasks, s.queuedTasks = running, queued
+}
+func (s *loopState) addPeer(p *Peer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.peers == nil {
+		s.peers = make(map[discover.NodeID]*Peer)
+	}
+	s.peers[p.ID()] = p
+}
+func (s *loopState) delPeer(p *Peer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.peers, p.ID())
+}
+
+// ChannelDepth is the number of queued values of a channel and its capacity.
+type ChannelDepth struct {
+	Len int `json:"len"`
+	Cap int `json:"cap"`
+}
+
+func depthOf[T any](c chan T) ChannelDepth {
+	return ChannelDepth{Len: len(c), Cap: cap(c)}
+}
+
+// PeerLoopState is the state of the goroutines of a peer, its message queues per protocol included.
+type PeerLoopState struct {
+	ID            string                  `json:"id"`
+	RemoteAddress string                  `json:"remoteAddress"`
+	Closed        bool                    `json:"closed"`
+	ProtoErr      ChannelDepth            `json:"protoErr"`
+	Disc          ChannelDepth            `json:"disc"`
+	Protocols     map[string]ChannelDepth `json:"protocols"`
+}
+
+// LoopState describes the run loop of the server and the queues of its channels.
+type LoopState struct {
+	Running       bool             `json:"running"`
+	Stage         string           `json:"stage"`
+	RunningTasks  int              `json:"runningTasks"`
+	QueuedTasks   int              `json:"queuedTasks"`
+	TaskDone      ChannelDepth     `json:"taskDone"`
+	AddPeer       ChannelDepth     `json:"addPeer"`
+	DelPeer       ChannelDepth     `json:"delPeer"`
+	PostHandshake ChannelDepth     `json:"postHandshake"`
+	Peers         []*PeerLoopState `json:"peers"`
+}
+
+// LoopState returns the state of the run loop. Unlike Peers, it doesn't go through the
+// loop, so it answers while the loop is stuck, like on a shutdown which doesn't end.
+func (srv *Server) LoopState() *LoopState {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+
+	s := &srv.loop
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	state := &LoopState{
+		Running:       running,
+		Stage:         s.stage,
+		RunningTasks:  s.runningTasks,
+		QueuedTasks:   s.queuedTasks,
+		TaskDone:      depthOf(s.taskdone),
+		AddPeer:       depthOf(srv.addpeer),
+		DelPeer:       depthOf(srv.delpeer),
+		PostHandshake: depthOf(srv.posthandshake),
+		Peers:         make([]*PeerLoopState, 0, len(s.peers)),
+	}
+	if state.Stage == "" {
+		state.Stage = loopStageNotStarted
+	}
+	for _, p := range s.peers {
+		peer := &PeerLoopState{
+			ID:            p.ID().String(),
+			RemoteAddress: p.RemoteAddr().String(),
+			ProtoErr:      depthOf(p.protoErr),
+			Disc:          depthOf(p.disc),
+			Protocols:     make(map[string]ChannelDepth, len(p.running)),
+		}
+		select {
+		case <-p.closed:
+			peer.Closed = true
+		default:
+		}
+		for name, rw := range p.running {
+			peer.Protocols[name] = depthOf(rw.in)
+		}
+		state.Peers = append(state.Peers, peer)
+	}
+	sort.Slice(state.Peers, func(i, j int) bool {
+		return state.Peers[i].ID < state.Peers[j].ID
+	})
+	return state
+}
@@ -20,6 +20,9 @@ package p2p
 
 import (
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/metrics"
 )
@@ -31,35 +34,187 @@ var (
 	egressTrafficMeter  = metrics.NewMeter()
 )
 
-// meteredConn is a wrapper around a network TCP connection that meters both the
-// inbound and outbound network traffic.
+// meteredConn is a wrapper around a network connection that meters both the
+// inbound and outbound network traffic and throttles it to the configured rates.
+// The time spent throttling moves the read and write deadlines, so slow limits
+// don't make the peers time out.
 type meteredConn struct {
-	*net.TCPConn // Network connection to wrap with metering
+	read     uint64 // Bytes read from the connection, accessed atomically
+	net.Conn        // Network connection to wrap with metering
+
+	upload   []*rateLimiter // Global and per-connection upload limits, nil if unlimited
+	download []*rateLimiter // Global and per-connection download limits, nil if unlimited
+
+	lock          sync.Mutex    // Protects the deadlines and the delays
+	readDeadline  time.Time     // Read deadline set by the caller, zero if none
+	writeDeadline time.Time     // Write deadline set by the caller, zero if none
+	readDelay     time.Duration // Time spent throttling the reads since the read deadline was set
+	writeDelay    time.Duration // Time spent throttling the writes since the write deadline was set
 }
 
 // newMeteredConn creates a new metered connection, also bumping the ingress or
 // egress connection meter.
-func newMeteredConn(conn net.Conn, ingress bool) net.Conn {
+func (srv *Server) newMeteredConn(conn net.Conn, ingress bool) net.Conn {
 	if ingress {
 		ingressConnectMeter.Mark(1)
 	} else {
 		egressConnectMeter.Mark(1)
 	}
-	return &meteredConn{conn.(*net.TCPConn)}
+	c := &meteredConn{Conn: conn}
+	if upload := []*rateLimiter{srv.uploadLimiter, newRateLimiter(srv.PeerUploadRate)}; limited(upload) {
+		c.upload = upload
+	}
+	if download := []*rateLimiter{srv.downloadLimiter, newRateLimiter(srv.PeerDownloadRate)}; limited(download) {
+		c.download = download
+	}
+	return c
 }
 
 // Read delegates a network read to the underlying connection, bumping the ingress
 // traffic meter along the way.
 func (c *meteredConn) Read(b []byte) (n int, err error) {
-	n, err = c.TCPConn.Read(b)
+	if c.download != nil && len(b) > throttleChunkSize {
+		b = b[:throttleChunkSize]
+	}
+	n, err = c.Conn.Read(b)
 	ingressTrafficMeter.Mark(int64(n))
+	atomic.AddUint64(&c.read, uint64(n))
+	if c.download != nil {
+		c.throttleRead(delay(n, c.download))
+	}
 	return
 }
 
 // Write delegates a network write to the underlying connection, bumping the
 // egress traffic meter along the way.
 func (c *meteredConn) Write(b []byte) (n int, err error) {
-	n, err = c.TCPConn.Write(b)
-	egressTrafficMeter.Mark(int64(n))
+	if c.upload == nil {
+		n, err = c.Conn.Write(b)
+		egressTrafficMeter.Mark(int64(n))
+		return
+	}
+	for len(b) > 0 && err == nil {
+		chunk := b
+		if len(chunk) > throttleChunkSize {
+			chunk = chunk[:throttleChunkSize]
+		}
+		c.throttleWrite(delay(len(chunk), c.upload))
+		var written int
+		written, err = c.Conn.Write(chunk)
+		egressTrafficMeter.Mark(int64(written))
+		n += written
+		b = b[written:]
+	}
 	return
 }
+
+// throttleRead waits for wait, after moving the read deadline set by the caller by the time spent throttling.
+func (c *meteredConn) throttleRead(wait time.Duration) {
+	if wait <= 0 {
+		return
+	}
+	c.lock.Lock()
+	c.readDelay += wait
+	if !c.readDeadline.IsZero() {
+		c.Conn.SetReadDeadline(c.readDeadline.Add(c.readDelay))
+	}
+	c.lock.Unlock()
+	time.Sleep(wait)
+}
+
+// throttleWrite waits for wait, after moving the write deadline set by the caller by the time spent throttling.
+func (c *meteredConn) throttleWrite(wait time.Duration) {
+	if wait <= 0 {
+		return
+	}
+	c.lock.Lock()
+	c.writeDelay += wait
+	if !c.writeDeadline.IsZero() {
+		c.Conn.SetWriteDeadline(c.writeDeadline.Add(c.writeDelay))
+	}
+	c.lock.Unlock()
+	time.Sleep(wait)
+}
+
+// SetDeadline sets the read and write deadlines, see SetReadDeadline and SetWriteDeadline.
+func (c *meteredConn) SetDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.readDeadline, c.readDelay = t, 0
+	c.writeDeadline, c.writeDelay = t, 0
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the underlying connection, it's moved by the time spent throttling the reads.
+func (c *meteredConn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.readDeadline, c.readDelay = t, 0
+	return c.Conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the write deadline of the underlying connection, it's moved by the time spent throttling the writes.
+func (c *meteredConn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writeDeadline, c.writeDelay = t, 0
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// bytesRead returns the number of bytes read from the connection.
+func (c *meteredConn) bytesRead() uint64 {
+	return atomic.LoadUint64(&c.read)
+}
+
+// HandshakeStats counts the outcomes of the handshakes of new connections. Handshakes which take more
+// than half of the timeout are slow, they hold pending slots and are typical of overloaded or stalling peers.
+type HandshakeStats struct {
+	Timeout       int64  `json:"timeoutMs"`
+	Completed     uint64 `json:"completed"`
+	Failed        uint64 `json:"failed"`
+	TimedOut      uint64 `json:"timedOut"`
+	Slow          uint64 `json:"slow"`
+	MaxDuration   int64  `json:"maxDurationMs"`
+	TotalDuration int64  `json:"totalDurationMs"`
+}
+
+type handshakeMeter struct {
+	lock  sync.Mutex
+	stats HandshakeStats
+}
+
+func (m *handshakeMeter) mark(timeout, duration time.Duration, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	switch {
+	case err == nil:
+		m.stats.Completed += 1
+	case isTimeout(err):
+		m.stats.TimedOut += 1
+	default:
+		m.stats.Failed += 1
+	}
+	if duration > timeout/2 {
+		m.stats.Slow += 1
+	}
+	if ms := duration.Milliseconds(); ms > m.stats.MaxDuration {
+		m.stats.MaxDuration = ms
+	}
+	m.stats.TotalDuration += duration.Milliseconds()
+}
+
+func (m *handshakeMeter) get(timeout time.Duration) *HandshakeStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stats := m.stats
+	stats.Timeout = timeout.Milliseconds()
+	return &stats
+}
+
+func isTimeout(err error) bool {
+	if err == errHandshakeTimeout {
+		return true
+	}
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
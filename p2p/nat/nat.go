@@ -56,7 +56,7 @@ type Interface interface {
 //
 //     "" or "none"         return nil
 //     "extip:77.12.33.4"   will assume the local machine is reachable on the given IP
-//     "any"                uses the first auto-detected mechanism
+//     "any"                uses UPnP if detected, NAT-PMP otherwise
 //     "upnp"               uses the Universal Plug and Play protocol
 //     "pmp"                uses NAT-PMP with an auto-detected gateway address
 //     "pmp:192.168.0.1"    uses NAT-PMP with the given gateway address
@@ -94,22 +94,39 @@ func Parse(spec string) (Interface, error) {
 const (
 	mapTimeout        = 20 * time.Minute
 	mapUpdateInterval = 15 * time.Minute
+	mapRetryInterval  = 1 * time.Minute
+	extIPInterval     = 5 * time.Minute
 )
 
+// leaser is implemented by mechanisms which report the lifetime granted by the
+// gateway, which can be shorter than the requested one.
+type leaser interface {
+	lifetime(protocol string, extport int) time.Duration
+}
+
+// refreshInterval returns the time after which the mapping has to be renewed
+// so it doesn't lapse, taking into account the lifetime granted by the gateway.
+func refreshInterval(m Interface, protocol string, extport int) time.Duration {
+	if l, ok := m.(leaser); ok {
+		if granted := l.lifetime(protocol, extport); granted > 0 && granted*3/4 < mapUpdateInterval {
+			return granted * 3 / 4
+		}
+	}
+	return mapUpdateInterval
+}
+
 // Map adds a port mapping on m and keeps it alive until c is closed.
+// The mapping is renewed before its lease expires. If a mapping fails, it is retried
+// shortly after and auto-discovered mechanisms probe the network again.
 // This function is typically invoked in its own goroutine.
 func Map(m Interface, c chan struct{}, protocol string, extport, intport int, name string) {
-	refresh := time.NewTimer(mapUpdateInterval)
+	refresh := time.NewTimer(0)
 	defer func() {
 		refresh.Stop()
 		common.P2PLogger.Debug(fmt.Sprintf("deleting port mapping: %s %d -> %d (%s) using %s\n", protocol, extport, intport, name, m))
 		m.DeleteMapping(protocol, extport, intport)
 	}()
-	if err := m.AddMapping(protocol, intport, extport, name, mapTimeout); err != nil {
-		common.P2PLogger.Debug(fmt.Sprintf("network port %s:%d could not be mapped: %v\n", protocol, intport, err))
-	} else {
-		common.P2PLogger.Info(fmt.Sprintf("mapped network port %s:%d -> %d (%s) using %s\n", protocol, extport, intport, name, m))
-	}
+	mapped := false
 	for {
 		select {
 		case _, ok := <-c:
@@ -117,11 +134,51 @@ func Map(m Interface, c chan struct{}, protocol string, extport, intport int, na
 				return
 			}
 		case <-refresh.C:
-			common.P2PLogger.Debug(fmt.Sprintf("refresh port mapping %s:%d -> %d (%s) using %s\n", protocol, extport, intport, name, m))
 			if err := m.AddMapping(protocol, intport, extport, name, mapTimeout); err != nil {
 				common.P2PLogger.Debug(fmt.Sprintf("network port %s:%d could not be mapped: %v\n", protocol, intport, err))
+				if ad, ok := m.(*autodisc); ok {
+					ad.reset()
+				}
+				mapped = false
+				refresh.Reset(mapRetryInterval)
+				continue
+			}
+			if !mapped {
+				common.P2PLogger.Info(fmt.Sprintf("mapped network port %s:%d -> %d (%s) using %s\n", protocol, extport, intport, name, m))
+			} else {
+				common.P2PLogger.Debug(fmt.Sprintf("refreshed port mapping %s:%d -> %d (%s) using %s\n", protocol, extport, intport, name, m))
+			}
+			mapped = true
+			refresh.Reset(refreshInterval(m, protocol, extport))
+		}
+	}
+}
+
+// WatchExternalIP polls the external IP of m until c is closed and calls
+// changed with the new IP every time it differs from the previous one.
+// known is the IP which is currently in use, nil if there is none.
+// This function is typically invoked in its own goroutine.
+func WatchExternalIP(m Interface, c chan struct{}, known net.IP, changed func(net.IP)) {
+	check := time.NewTicker(extIPInterval)
+	defer check.Stop()
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+		case <-check.C:
+			ip, err := m.ExternalIP()
+			if err != nil {
+				common.P2PLogger.Debug(fmt.Sprintf("external IP could not be retrieved using %s: %v\n", m, err))
+				continue
+			}
+			if ip.Equal(known) {
+				continue
 			}
-			refresh.Reset(mapUpdateInterval)
+			common.P2PLogger.Info(fmt.Sprintf("external IP changed from %v to %v using %s\n", known, ip, m))
+			known = ip
+			changed(ip)
 		}
 	}
 }
@@ -146,20 +203,16 @@ func (extIP) AddMapping(string, int, int, string, time.Duration) error { return
 func (extIP) DeleteMapping(string, int, int) error                     { return nil }
 
 // Any returns a port mapper that tries to discover any supported
-// mechanism on the local network.
+// mechanism on the local network. UPnP is probed first, with NAT-PMP
+// as fallback if no UPnP gateway responds.
 func Any() Interface {
 	// TODO: attempt to discover whether the local machine has an
 	// Internet-class address. Return ExtIP in this case.
 	return startautodisc("UPnP or NAT-PMP", func() Interface {
-		found := make(chan Interface, 2)
-		go func() { found <- discoverUPnP() }()
-		go func() { found <- discoverPMP() }()
-		for i := 0; i < cap(found); i++ {
-			if c := <-found; c != nil {
-				return c
-			}
+		if c := discoverUPnP(); c != nil {
+			return c
 		}
-		return nil
+		return discoverPMP()
 	})
 }
 
@@ -186,17 +239,20 @@ func PMP(gateway net.IP) Interface {
 //
 // This type is useful because discovery can take a while but we
 // want return an Interface value from UPnP, PMP and Auto immediately.
+// The discovery runs again after a reset, e.g. when the gateway stops
+// answering or the node moved to another network.
 type autodisc struct {
 	what string // type of interface being autodiscovered
-	once sync.Once
 	doit func() Interface
 
+	discovery sync.Mutex // serializes discovery runs
+
 	mu    sync.Mutex
+	done  bool
 	found Interface
 }
 
 func startautodisc(what string, doit func() Interface) Interface {
-	// TODO: monitor network configuration and rerun doit when it changes.
 	ad := &autodisc{what: what, doit: doit}
 	// Start the auto discovery as early as possible so it is already
 	// in progress when the rest of the stack calls the methods.
@@ -205,24 +261,27 @@ func startautodisc(what string, doit func() Interface) Interface {
 }
 
 func (n *autodisc) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
-	if err := n.wait(); err != nil {
+	found, err := n.wait()
+	if err != nil {
 		return err
 	}
-	return n.found.AddMapping(protocol, extport, intport, name, lifetime)
+	return found.AddMapping(protocol, extport, intport, name, lifetime)
 }
 
 func (n *autodisc) DeleteMapping(protocol string, extport, intport int) error {
-	if err := n.wait(); err != nil {
+	found, err := n.wait()
+	if err != nil {
 		return err
 	}
-	return n.found.DeleteMapping(protocol, extport, intport)
+	return found.DeleteMapping(protocol, extport, intport)
 }
 
 func (n *autodisc) ExternalIP() (net.IP, error) {
-	if err := n.wait(); err != nil {
+	found, err := n.wait()
+	if err != nil {
 		return nil, err
 	}
-	return n.found.ExternalIP()
+	return found.ExternalIP()
 }
 
 func (n *autodisc) String() string {
@@ -235,15 +294,38 @@ func (n *autodisc) String() string {
 	}
 }
 
-// wait blocks until auto-discovery has been performed.
-func (n *autodisc) wait() error {
-	n.once.Do(func() {
+func (n *autodisc) lifetime(protocol string, extport int) time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if l, ok := n.found.(leaser); ok {
+		return l.lifetime(protocol, extport)
+	}
+	return 0
+}
+
+// reset makes the next call probe the network again.
+func (n *autodisc) reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.done = false
+}
+
+// wait blocks until auto-discovery has been performed and returns the discovered mechanism.
+func (n *autodisc) wait() (Interface, error) {
+	n.discovery.Lock()
+	defer n.discovery.Unlock()
+
+	n.mu.Lock()
+	done, found := n.done, n.found
+	n.mu.Unlock()
+	if !done {
+		found = n.doit()
 		n.mu.Lock()
-		n.found = n.doit()
+		n.done, n.found = true, found
 		n.mu.Unlock()
-	})
-	if n.found == nil {
-		return fmt.Errorf("no %s router discovered", n.what)
 	}
-	return nil
+	if found == nil {
+		return nil, fmt.Errorf("no %s router discovered", n.what)
+	}
+	return found, nil
 }
@@ -62,3 +62,58 @@ func TestAutoDiscRace(t *testing.T) {
 		}
 	}
 }
+
+// This test checks that autodisc probes the network again after a reset.
+func TestAutoDiscReset(t *testing.T) {
+	runs := 0
+	ad := startautodisc("thing", func() Interface {
+		runs++
+		if runs == 1 {
+			return nil
+		}
+		return extIP{33, 44, 55, 66}
+	}).(*autodisc)
+
+	if _, err := ad.ExternalIP(); err == nil {
+		t.Fatal("expected error before the reset")
+	}
+	if _, err := ad.ExternalIP(); err == nil {
+		t.Fatal("expected discovery to run only once without a reset")
+	}
+	ad.reset()
+	ip, err := ad.ExternalIP()
+	if err != nil {
+		t.Fatalf("unexpected error after the reset: %v", err)
+	}
+	if !ip.Equal(net.IP{33, 44, 55, 66}) {
+		t.Errorf("got IP %v, want %v", ip, net.IP{33, 44, 55, 66})
+	}
+	if runs != 2 {
+		t.Errorf("got %d discovery runs, want 2", runs)
+	}
+}
+
+type leasedIP struct {
+	extIP
+	granted time.Duration
+}
+
+func (n leasedIP) lifetime(string, int) time.Duration { return n.granted }
+
+// This test checks that mappings are renewed before the lease granted by the gateway expires.
+func TestRefreshInterval(t *testing.T) {
+	tests := []struct {
+		m    Interface
+		want time.Duration
+	}{
+		{extIP{33, 44, 55, 66}, mapUpdateInterval},
+		{leasedIP{extIP{33, 44, 55, 66}, 0}, mapUpdateInterval},
+		{leasedIP{extIP{33, 44, 55, 66}, time.Hour}, mapUpdateInterval},
+		{leasedIP{extIP{33, 44, 55, 66}, 2 * time.Minute}, 90 * time.Second},
+	}
+	for i, test := range tests {
+		if got := refreshInterval(test.m, "tcp", 30303); got != test.want {
+			t.Errorf("test %d: got %v, want %v", i, got, test.want)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackpal/go-nat-pmp"
@@ -30,6 +31,13 @@ import (
 type pmp struct {
 	gw net.IP
 	c  *natpmp.Client
+
+	mu     sync.Mutex
+	leases map[string]time.Duration // lifetimes granted by the gateway, by protocol and external port
+}
+
+func leaseKey(protocol string, extport int) string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(protocol), extport)
 }
 
 func (n *pmp) String() string {
@@ -50,8 +58,23 @@ func (n *pmp) AddMapping(protocol string, extport, intport int, name string, lif
 	}
 	// Note order of port arguments is switched between our
 	// AddMapping and the client's AddPortMapping.
-	_, err := n.c.AddPortMapping(strings.ToLower(protocol), intport, extport, int(lifetime/time.Second))
-	return err
+	res, err := n.c.AddPortMapping(strings.ToLower(protocol), intport, extport, int(lifetime/time.Second))
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.leases == nil {
+		n.leases = make(map[string]time.Duration)
+	}
+	n.leases[leaseKey(protocol, extport)] = time.Duration(res.PortMappingLifetimeInSeconds) * time.Second
+	return nil
+}
+
+func (n *pmp) lifetime(protocol string, extport int) time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.leases[leaseKey(protocol, extport)]
 }
 
 func (n *pmp) DeleteMapping(protocol string, extport, intport int) (err error) {
@@ -73,7 +96,7 @@ func discoverPMP() Interface {
 			if _, err := c.GetExternalAddress(); err != nil {
 				found <- nil
 			} else {
-				found <- &pmp{gw, c}
+				found <- &pmp{gw: gw, c: c}
 			}
 		}()
 	}
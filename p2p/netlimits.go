@@ -0,0 +1,190 @@
+package p2p
+
+import (
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+// subnet returns the /24 of an IPv4 address or the /64 of an IPv6 one,
+// the range of addresses which a single operator usually controls.
+func subnet(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}).String()
+}
+
+// limitedIP reports whether the subnet and IP limits apply to ip.
+// Loopback addresses are exempt, so nodes can run side by side on a host.
+func limitedIP(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback()
+}
+
+// remoteIP returns the IP of the remote end of fd, nil if it's not a TCP connection.
+func remoteIP(fd net.Conn) net.IP {
+	if addr, ok := fd.RemoteAddr().(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return nil
+}
+
+// subnetPeers counts the connected peers of each subnet.
+func subnetPeers(peers map[discover.NodeID]*Peer) map[string]int {
+	counts := make(map[string]int)
+	for _, p := range peers {
+		if ip := remoteIP(p.rw.fd); limitedIP(ip) {
+			counts[subnet(ip)] += 1
+		}
+	}
+	return counts
+}
+
+// subnetFull reports whether the subnet of c already has MaxPeersPerSubnet peers.
+// Trusted and static connections don't count against the limit.
+func (srv *Server) subnetFull(peers map[discover.NodeID]*Peer, c *conn) bool {
+	if srv.MaxPeersPerSubnet <= 0 || c.is(trustedConn|staticDialedConn) {
+		return false
+	}
+	ip := remoteIP(c.fd)
+	if !limitedIP(ip) {
+		return false
+	}
+	return subnetPeers(peers)[subnet(ip)] >= srv.MaxPeersPerSubnet
+}
+
+// inboundIPs counts the inbound connections of each IP, from their acceptance until they are
+// closed, so the ones which are still in the handshakes count against MaxInboundPerIP too.
+type inboundIPs struct {
+	lock   sync.Mutex
+	counts map[string]int
+}
+
+// acquire counts a new connection of ip, false if ip already has max connections.
+func (c *inboundIPs) acquire(ip string, max int) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	if max > 0 && c.counts[ip] >= max {
+		return false
+	}
+	c.counts[ip] += 1
+	return true
+}
+func (c *inboundIPs) release(ip string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.counts[ip] -= 1; c.counts[ip] <= 0 {
+		delete(c.counts, ip)
+	}
+}
+func (c *inboundIPs) get() map[string]int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	counts := make(map[string]int, len(c.counts))
+	for ip, count := range c.counts {
+		counts[ip] = count
+	}
+	return counts
+}
+
+// countedConn releases its IP from the inbound counts once closed.
+type countedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
+
+// acceptInbound counts the inbound connection fd, it returns false if its IP already has
+// MaxInboundPerIP connections. The IPs of the trusted and static nodes are exempt.
+func (srv *Server) acceptInbound(fd net.Conn) (net.Conn, bool) {
+	ip := remoteIP(fd)
+	if !limitedIP(ip) {
+		return fd, true
+	}
+	max := srv.MaxInboundPerIP
+	if srv.exemptIPs[ip.String()] {
+		max = 0
+	}
+	if !srv.inbound.acquire(ip.String(), max) {
+		return nil, false
+	}
+	return &countedConn{Conn: fd, release: func() { srv.inbound.release(ip.String()) }}, true
+}
+
+// SubnetPeers is the number of connected peers of a subnet.
+type SubnetPeers struct {
+	Subnet  string `json:"subnet"`
+	Peers   int    `json:"peers"`
+	Inbound int    `json:"inbound"`
+}
+
+// IPConnections is the number of inbound connections of an IP, including the ones in the handshakes.
+type IPConnections struct {
+	IP          string `json:"ip"`
+	Connections int    `json:"connections"`
+}
+
+// PeerDistribution describes how the peers spread across subnets and the inbound connections across IPs,
+// the most used first, along with the limits of the server. Zero limits are disabled.
+type PeerDistribution struct {
+	MaxPeersPerSubnet int              `json:"maxPeersPerSubnet"`
+	MaxInboundPerIP   int              `json:"maxInboundPerIP"`
+	Subnets           []*SubnetPeers   `json:"subnets"`
+	InboundIPs        []*IPConnections `json:"inboundIPs"`
+}
+
+// PeerDistribution returns the current distribution of the peers across subnets and IPs.
+func (srv *Server) PeerDistribution() *PeerDistribution {
+	distribution := &PeerDistribution{
+		MaxPeersPerSubnet: srv.MaxPeersPerSubnet,
+		MaxInboundPerIP:   srv.MaxInboundPerIP,
+		Subnets:           make([]*SubnetPeers, 0),
+		InboundIPs:        make([]*IPConnections, 0),
+	}
+	select {
+	case srv.peerOp <- func(ps map[discover.NodeID]*Peer) {
+		subnets := make(map[string]*SubnetPeers)
+		for _, p := range ps {
+			ip := remoteIP(p.rw.fd)
+			if ip == nil {
+				continue
+			}
+			s, ok := subnets[subnet(ip)]
+			if !ok {
+				s = &SubnetPeers{Subnet: subnet(ip)}
+				subnets[s.Subnet] = s
+				distribution.Subnets = append(distribution.Subnets, s)
+			}
+			s.Peers += 1
+			if p.rw.is(inboundConn) {
+				s.Inbound += 1
+			}
+		}
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+	}
+	for ip, count := range srv.inbound.get() {
+		distribution.InboundIPs = append(distribution.InboundIPs, &IPConnections{IP: ip, Connections: count})
+	}
+
+	sort.Slice(distribution.Subnets, func(i, j int) bool {
+		a, b := distribution.Subnets[i], distribution.Subnets[j]
+		return a.Peers > b.Peers || (a.Peers == b.Peers && a.Subnet < b.Subnet)
+	})
+	sort.Slice(distribution.InboundIPs, func(i, j int) bool {
+		a, b := distribution.InboundIPs[i], distribution.InboundIPs[j]
+		return a.Connections > b.Connections || (a.Connections == b.Connections && a.IP < b.IP)
+	})
+	return distribution
+}
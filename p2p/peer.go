@@ -23,6 +23,7 @@ import (
 	"net"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/rlp"
@@ -60,13 +61,26 @@ type protoHandshake struct {
 
 // Peer represents a connected remote node.
 type Peer struct {
+	// pingSent is the time the last ping was sent at, rtt the smoothed round trip
+	// time measured with the pongs, both in nanoseconds and accessed atomically.
+	// They are kept first for 64-bit alignment.
+	pingSent int64
+	rtt      int64
+
 	rw      *conn
 	running map[string]*protoRW
+	created time.Time
 
 	wg       sync.WaitGroup
 	protoErr chan error
 	closed   chan struct{}
 	disc     chan DiscReason
+
+	// discErr is the error which ended the session and discRemote whether the remote peer requested
+	// the disconnect, both are set before closed is.
+	discErr    error
+	discRemote bool
+	ended      time.Time
 }
 
 // NewPeer returns a peer for testing purposes.
@@ -74,6 +88,7 @@ func NewPeer(id discover.NodeID, name string, caps []Cap) *Peer {
 	pipe, _ := net.Pipe()
 	conn := &conn{fd: pipe, transport: nil, id: id, caps: caps, name: name}
 	peer := newPeer(conn, nil)
+	peer.ended = peer.created
 	close(peer.closed) // ensures Disconnect doesn't block
 	return peer
 }
@@ -104,6 +119,64 @@ func (p *Peer) LocalAddr() net.Addr {
 	return p.rw.fd.LocalAddr()
 }
 
+// Latency returns the smoothed round trip time to the peer, zero until the first pong is received.
+func (p *Peer) Latency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.rtt))
+}
+
+// Uptime returns the time since the peer was added, the session duration once it's disconnected.
+func (p *Peer) Uptime() time.Duration {
+	select {
+	case <-p.closed:
+		return p.ended.Sub(p.created)
+	default:
+		return time.Since(p.created)
+	}
+}
+
+// Inbound returns true if the remote peer dialed the connection.
+func (p *Peer) Inbound() bool {
+	return p.rw.is(inboundConn)
+}
+
+// Version returns the base protocol version the remote peer advertised.
+func (p *Peer) Version() uint64 {
+	return p.rw.version
+}
+
+// Protocols returns the protocols which run with the peer, in their Cap notation.
+func (p *Peer) Protocols() []string {
+	return p.runningProtocols()
+}
+
+// Metadata returns the telemetry the remote peer advertised, nil if it didn't opt in.
+func (p *Peer) Metadata() *PeerMetadata {
+	return capsMetadata(p.rw.caps)
+}
+
+// Trusted returns true if the peer is one of the static or trusted nodes.
+func (p *Peer) Trusted() bool {
+	return p.rw.is(trustedConn | staticDialedConn)
+}
+
+// BytesRead returns the number of bytes received from the peer.
+func (p *Peer) BytesRead() uint64 {
+	if fd, ok := p.rw.fd.(*meteredConn); ok {
+		return fd.bytesRead()
+	}
+	return 0
+}
+
+// runningProtocols returns the protocols which run with the peer, in their Cap notation.
+func (p *Peer) runningProtocols() []string {
+	protocols := make([]string, 0, len(p.running))
+	for _, proto := range p.running {
+		protocols = append(protocols, proto.cap().String())
+	}
+	sort.Strings(protocols)
+	return protocols
+}
+
 // Disconnect terminates the peer connection with the given reason.
 // It returns immediately and does not wait until the connection is closed.
 func (p *Peer) Disconnect(reason DiscReason) {
@@ -124,6 +197,7 @@ func newPeer(conn *conn, protocols []Protocol) *Peer {
 	p := &Peer{
 		rw:       conn,
 		running:  protomap,
+		created:  time.Now(),
 		disc:     make(chan DiscReason),
 		protoErr: make(chan error, len(protomap)+1), // protocols + pingLoop
 		closed:   make(chan struct{}),
@@ -133,11 +207,13 @@ func newPeer(conn *conn, protocols []Protocol) *Peer {
 
 func (p *Peer) run() DiscReason {
 	var (
-		writeStart = make(chan struct{}, 1)
-		writeErr   = make(chan error, 1)
-		readErr    = make(chan error, 1)
-		reason     DiscReason
-		requested  bool
+		writeStart     = make(chan struct{}, 1)
+		writeStartHigh = make(chan struct{})
+		writeErr       = make(chan error, 1)
+		readErr        = make(chan error, 1)
+		reason         DiscReason
+		requested      bool
+		err            error
 	)
 
 	p.wg.Add(1)
@@ -154,13 +230,13 @@ func (p *Peer) run() DiscReason {
 
 	// Start all protocol handlers.
 	writeStart <- struct{}{}
-	p.startProtocols(writeStart, writeErr)
+	p.startProtocols(writeStart, writeStartHigh, writeErr)
 
 	// Wait for an error or disconnect.
 loop:
 	for {
 		select {
-		case err := <-writeErr:
+		case err = <-writeErr:
 			// A write finished. Allow the next write to start if
 			// there was no error.
 			if err != nil {
@@ -168,8 +244,13 @@ loop:
 				reason = DiscNetworkError
 				break loop
 			}
-			writeStart <- struct{}{}
-		case err := <-readErr:
+			// Hand the next write to a waiting high priority message, if any.
+			select {
+			case writeStartHigh <- struct{}{}:
+			default:
+				writeStart <- struct{}{}
+			}
+		case err = <-readErr:
 			if r, ok := err.(DiscReason); ok {
 				common.P2PLogger.Debug(fmt.Sprintf("%v: remote requested disconnect: %v\n", p, r))
 				requested = true
@@ -179,7 +260,7 @@ loop:
 				reason = DiscNetworkError
 			}
 			break loop
-		case err := <-p.protoErr:
+		case err = <-p.protoErr:
 			reason = discReasonForError(err)
 			common.P2PLogger.Debug(fmt.Sprintf("%v: protocol error: %v (%v)\n", p, err, reason))
 			break loop
@@ -190,6 +271,7 @@ loop:
 	}
 
 	p.rw.close(reason)
+	p.discErr, p.discRemote, p.ended = err, requested, time.Now()
 	close(p.closed)
 	common.P2PLogger.Debug("wg.Wait() peer.run()")
 	// p.wg.Wait()
@@ -206,6 +288,7 @@ func (p *Peer) pingLoop() {
 	for {
 		select {
 		case <-ping.C:
+			atomic.StoreInt64(&p.pingSent, time.Now().UnixNano())
 			if err := SendItems(p.rw, pingMsg); err != nil {
 				p.protoErr <- err
 				return
@@ -240,6 +323,9 @@ func (p *Peer) handle(msg Msg) error {
 			SendItems(p.rw, pongMsg)
 			p.wg.Done()
 		}()
+	case msg.Code == pongMsg:
+		msg.Discard()
+		p.updateLatency(msg.ReceivedAt)
 	case msg.Code == discMsg:
 		var reason [1]DiscReason
 		// This is the last message. We don't need to discard or
@@ -265,6 +351,19 @@ func (p *Peer) handle(msg Msg) error {
 	return nil
 }
 
+// updateLatency measures the round trip time of the last ping, smoothed over the previous measurements.
+func (p *Peer) updateLatency(receivedAt time.Time) {
+	sent := atomic.SwapInt64(&p.pingSent, 0)
+	if sent == 0 {
+		return
+	}
+	sample := receivedAt.UnixNano() - sent
+	if old := atomic.LoadInt64(&p.rtt); old != 0 {
+		sample = (old*7 + sample) / 8
+	}
+	atomic.StoreInt64(&p.rtt, sample)
+}
+
 func countMatchingProtocols(protocols []Protocol, caps []Cap) int {
 	n := 0
 	for _, cap := range caps {
@@ -302,12 +401,13 @@ outer:
 	return result
 }
 
-func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error) {
+func (p *Peer) startProtocols(writeStart, writeStartHigh <-chan struct{}, writeErr chan<- error) {
 	p.wg.Add(len(p.running))
 	for _, proto := range p.running {
 		proto := proto
 		proto.closed = p.closed
 		proto.wstart = writeStart
+		proto.wstartHigh = writeStartHigh
 		proto.werr = writeErr
 		common.P2PLogger.Debug(fmt.Sprintf("%v: Starting protocol %s/%d\n", p, proto.Name, proto.Version))
 		go func() {
@@ -337,30 +437,38 @@ func (p *Peer) getProto(code uint64) (*protoRW, error) {
 
 type protoRW struct {
 	Protocol
-	in     chan Msg        // receices read messages
-	closed <-chan struct{} // receives when peer is shutting down
-	wstart <-chan struct{} // receives when write may start
-	werr   chan<- error    // for write results
-	offset uint64
-	w      MsgWriter
+	in         chan Msg        // receices read messages
+	closed     <-chan struct{} // receives when peer is shutting down
+	wstart     <-chan struct{} // receives when write may start
+	wstartHigh <-chan struct{} // receives when a high priority write may start
+	werr       chan<- error    // for write results
+	offset     uint64
+	w          MsgWriter
 }
 
 func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 	if msg.Code >= rw.Length {
 		return newPeerError(errInvalidMsgCode, "not handled")
 	}
+	// High priority messages can also start on the handover reserved for them.
+	// The channel stays nil for normal priority messages, which never receives.
+	var wstartHigh <-chan struct{}
+	if rw.Priority != nil && rw.Priority(msg.Code) == PriorityHigh {
+		wstartHigh = rw.wstartHigh
+	}
 	msg.Code += rw.offset
 	select {
 	case <-rw.wstart:
-		err = rw.w.WriteMsg(msg)
-		// Report write status back to Peer.run. It will initiate
-		// shutdown if the error is non-nil and unblock the next write
-		// otherwise. The calling protocol code should exit for errors
-		// as well but we don't want to rely on that.
-		rw.werr <- err
+	case <-wstartHigh:
 	case <-rw.closed:
-		err = fmt.Errorf("shutting down")
+		return fmt.Errorf("shutting down")
 	}
+	err = rw.w.WriteMsg(msg)
+	// Report write status back to Peer.run. It will initiate
+	// shutdown if the error is non-nil and unblock the next write
+	// otherwise. The calling protocol code should exit for errors
+	// as well but we don't want to rely on that.
+	rw.werr <- err
 	return err
 }
 
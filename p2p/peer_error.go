@@ -67,6 +67,8 @@ const (
 	DiscSelf
 	DiscReadTimeout
 	DiscSubprotocolError
+	DiscNetworkMismatch
+	DiscSubnetLimit
 )
 
 var discReasonToString = [...]string{
@@ -83,6 +85,8 @@ var discReasonToString = [...]string{
 	DiscSelf:                "Connected to self",
 	DiscReadTimeout:         "Read timeout",
 	DiscSubprotocolError:    "Subprotocol error",
+	DiscNetworkMismatch:     "Network mismatch",
+	DiscSubnetLimit:         "Too many peers from the subnet",
 }
 
 func (d DiscReason) String() string {
@@ -0,0 +1,84 @@
+package p2p
+
+import (
+	"time"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+// PeerEventType is the type of peer lifecycle event emitted by the server.
+type PeerEventType string
+
+const (
+	// PeerEventTypeAdd is emitted when a peer is added to the server.
+	PeerEventTypeAdd PeerEventType = "add"
+	// PeerEventTypeDrop is emitted when a peer is dropped from the server.
+	PeerEventTypeDrop PeerEventType = "drop"
+	// PeerEventTypeHandshakeFailed is emitted when a connection fails the
+	// handshakes or the checks performed after them.
+	PeerEventTypeHandshakeFailed PeerEventType = "handshakeFailed"
+)
+
+// Number of events buffered for each subscriber of the peer event feed.
+const PeerEventChanSize = 64
+
+// PeerEvent is a peer lifecycle event.
+// PublicKey is empty if the connection failed before the remote identity was known.
+type PeerEvent struct {
+	Type      PeerEventType `json:"type"`
+	PublicKey string        `json:"publicKey"`
+	IP        string        `json:"ip"`
+	Name      string        `json:"name"`
+	Inbound   bool          `json:"inbound"`
+	Error     string        `json:"error,omitempty"`
+	Timestamp int64         `json:"timestamp"`
+}
+
+func newPeerEvent(eventType PeerEventType, c *conn, err error) *PeerEvent {
+	event := &PeerEvent{
+		Type:      eventType,
+		IP:        c.fd.RemoteAddr().String(),
+		Name:      c.name,
+		Inbound:   c.is(inboundConn),
+		Timestamp: time.Now().Unix(),
+	}
+	if (c.id != discover.NodeID{}) {
+		event.PublicKey = c.id.String()
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// SubscribeEvents registers ch to receive the peer lifecycle events of the server.
+// Events are dropped if ch is full. The returned function removes the subscription.
+func (srv *Server) SubscribeEvents(ch chan *PeerEvent) func() {
+	srv.eventsLock.Lock()
+	defer srv.eventsLock.Unlock()
+
+	if srv.eventSubs == nil {
+		srv.eventSubs = make(map[chan *PeerEvent]struct{})
+	}
+	srv.eventSubs[ch] = struct{}{}
+	return func() {
+		srv.eventsLock.Lock()
+		defer srv.eventsLock.Unlock()
+		delete(srv.eventSubs, ch)
+	}
+}
+
+// postPeerEvent sends the event to all subscribers without blocking the caller.
+func (srv *Server) postPeerEvent(event *PeerEvent) {
+	srv.eventsLock.Lock()
+	defer srv.eventsLock.Unlock()
+
+	for ch := range srv.eventSubs {
+		select {
+		case ch <- event:
+		default:
+			common.P2PLogger.Debug("dropping peer event", "reason", "channel is full", "type", event.Type, "peer", event.PublicKey)
+		}
+	}
+}
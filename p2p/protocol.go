@@ -16,7 +16,10 @@
 
 package p2p
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Protocol represents a P2P subprotocol implementation.
 type Protocol struct {
@@ -39,8 +42,22 @@ type Protocol struct {
 	// any protocol-level error (such as an I/O error) that is
 	// encountered.
 	Run func(peer *Peer, rw MsgReadWriter) error
+
+	// Priority returns the write priority of a message code of the protocol.
+	// When writes are queued on a congested connection, messages with
+	// PriorityHigh are written before the ones with PriorityNormal.
+	// All messages have PriorityNormal if Priority is nil.
+	Priority func(code uint64) MsgPriority
 }
 
+// MsgPriority is the write priority of a protocol message.
+type MsgPriority int
+
+const (
+	PriorityNormal MsgPriority = iota
+	PriorityHigh
+)
+
 func (p Protocol) cap() Cap {
 	return Cap{p.Name, p.Version}
 }
@@ -66,3 +83,94 @@ func (cs capsByNameAndVersion) Swap(i, j int) { cs[i], cs[j] = cs[j], cs[i] }
 func (cs capsByNameAndVersion) Less(i, j int) bool {
 	return cs[i].Name < cs[j].Name || (cs[i].Name == cs[j].Name && cs[i].Version < cs[j].Version)
 }
+
+// networkCapPrefix prefixes the name of the capability advertising the NetworkID of a server.
+const networkCapPrefix = "net-"
+
+func networkCap(network string) Cap {
+	return Cap{Name: networkCapPrefix + network, Version: 1}
+}
+
+// capsNetwork returns the network advertised in the caps, empty if there is none.
+func capsNetwork(caps []Cap) string {
+	for _, cap := range caps {
+		if strings.HasPrefix(cap.Name, networkCapPrefix) {
+			return strings.TrimPrefix(cap.Name, networkCapPrefix)
+		}
+	}
+	return ""
+}
+
+// PeerMetadata is the optional telemetry of a node, advertised in the protocol handshake so the network
+// crawlers can measure the distribution of the client versions, see Server.Metadata.
+type PeerMetadata struct {
+	Client   string `json:"client"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Archival bool   `json:"archival"`
+}
+
+const (
+	// metadataCapPrefix prefixes the names of the capabilities advertising the fields of the PeerMetadata of a
+	// server. The nodes which predate it ignore the unknown capabilities, while they would reject a handshake
+	// with unknown fields.
+	metadataCapPrefix = "meta-"
+	// maxMetadataValueLen caps the length of the advertised values, the handshake is a small message
+	maxMetadataValueLen = 32
+)
+
+func metadataCap(key, value string) Cap {
+	return Cap{Name: metadataCapPrefix + key + "=" + sanitizeMetadataValue(value), Version: 1}
+}
+
+// sanitizeMetadataValue keeps the printable ASCII characters of value, except the spaces, up to maxMetadataValueLen.
+func sanitizeMetadataValue(value string) string {
+	result := make([]byte, 0, len(value))
+	for i := 0; i < len(value) && len(result) < maxMetadataValueLen; i++ {
+		if value[i] > ' ' && value[i] <= '~' {
+			result = append(result, value[i])
+		}
+	}
+	return string(result)
+}
+
+func metadataCaps(metadata *PeerMetadata) []Cap {
+	if metadata == nil {
+		return nil
+	}
+	caps := []Cap{
+		metadataCap("client", metadata.Client),
+		metadataCap("os", metadata.OS),
+		metadataCap("arch", metadata.Arch),
+	}
+	if metadata.Archival {
+		caps = append(caps, metadataCap("archival", "true"))
+	}
+	return caps
+}
+
+// capsMetadata returns the metadata advertised in the caps, nil if there is none.
+func capsMetadata(caps []Cap) *PeerMetadata {
+	var metadata *PeerMetadata
+	for _, cap := range caps {
+		if !strings.HasPrefix(cap.Name, metadataCapPrefix) {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimPrefix(cap.Name, metadataCapPrefix), "=")
+		value = sanitizeMetadataValue(value)
+		if metadata == nil {
+			metadata = new(PeerMetadata)
+		}
+		switch key {
+		case "client":
+			metadata.Client = value
+		case "os":
+			metadata.OS = value
+		case "arch":
+			metadata.Arch = value
+		case "archival":
+			metadata.Archival = value == "true"
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,71 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+)
+
+// throttleChunkSize bounds the bytes read or written at once by a throttled connection,
+// so a large message is spread over time instead of passing in a single burst.
+const throttleChunkSize = 16 * 1024
+
+// rateLimiter is a token bucket of bytes, it lets rate bytes per second through with bursts of up to one second.
+type rateLimiter struct {
+	rate   float64
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns nil, which never throttles, if rate isn't positive.
+func newRateLimiter(rate int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:   float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes n bytes from the bucket and returns how long the caller has to wait before using them.
+func (l *rateLimiter) reserve(n int) time.Duration {
+	if l == nil || n <= 0 {
+		return 0
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// delay takes n bytes from all the limiters and returns how long the caller has to wait before using them.
+func delay(n int, limiters []*rateLimiter) time.Duration {
+	var wait time.Duration
+	for _, l := range limiters {
+		if d := l.reserve(n); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+func limited(limiters []*rateLimiter) bool {
+	for _, l := range limiters {
+		if l != nil {
+			return true
+		}
+	}
+	return false
+}
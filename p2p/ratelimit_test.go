@@ -0,0 +1,101 @@
+package p2p
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	if newRateLimiter(0) != nil {
+		t.Fatal("expected no limiter without a rate")
+	}
+	var unlimited *rateLimiter
+	if wait := unlimited.reserve(1 << 20); wait != 0 {
+		t.Fatalf("unlimited limiter waits %v", wait)
+	}
+
+	l := newRateLimiter(1000)
+	if wait := l.reserve(1000); wait != 0 {
+		t.Fatalf("expected a burst of one second, waits %v", wait)
+	}
+	if wait := l.reserve(500); wait < 400*time.Millisecond || wait > 500*time.Millisecond {
+		t.Fatalf("expected to wait about 500ms, waits %v", wait)
+	}
+	if wait := delay(100, []*rateLimiter{nil, newRateLimiter(1000), l}); wait < 500*time.Millisecond {
+		t.Fatalf("expected to wait for the slowest limiter, waits %v", wait)
+	}
+}
+
+// throttledPayload takes about one second past the burst of throttledRate.
+const (
+	throttledRate    = 2 * throttleChunkSize
+	throttledPayload = 4 * throttleChunkSize
+)
+
+// Test the throttling of a metered connection
+//   - test the writes wait for the upload limit without exceeding the write deadline
+//   - test the deadline still applies once the writes aren't throttled
+func TestMeteredConn_WriteDeadline(t *testing.T) {
+	srv := &Server{}
+	srv.PeerUploadRate = throttledRate
+	local, remote := net.Pipe()
+	defer remote.Close()
+	conn := srv.newMeteredConn(local, false)
+	defer conn.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		data, _ := io.ReadAll(io.LimitReader(remote, throttledPayload))
+		received <- data
+	}()
+	payload := bytes.Repeat([]byte{1}, throttledPayload)
+	start := time.Now()
+	if err := conn.SetWriteDeadline(time.Now().Add(300 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := conn.Write(payload); err != nil || n != len(payload) {
+		t.Fatalf("throttled write failed after %v bytes: %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Fatalf("write of %v bytes wasn't throttled, took %v", len(payload), elapsed)
+	}
+	if data := <-received; !bytes.Equal(data, payload) {
+		t.Fatalf("received %v bytes, expected %v", len(data), len(payload))
+	}
+
+	// nobody reads anymore
+	conn.SetWriteDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := conn.Write([]byte{1}); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+// Test the reads wait for the download limit without exceeding the read deadline
+func TestMeteredConn_ReadDeadline(t *testing.T) {
+	srv := &Server{}
+	srv.PeerDownloadRate = throttledRate
+	local, remote := net.Pipe()
+	defer remote.Close()
+	conn := srv.newMeteredConn(local, true)
+	defer conn.Close()
+
+	payload := bytes.Repeat([]byte{1}, throttledPayload)
+	go remote.Write(payload)
+	start := time.Now()
+	if err := conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, len(payload))
+	if n, err := io.ReadFull(conn, data); err != nil {
+		t.Fatalf("throttled read failed after %v bytes: %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Fatalf("read of %v bytes wasn't throttled, took %v", len(payload), elapsed)
+	}
+	if !bytes.Equal(data, payload) {
+		t.Fatal("unexpected data")
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"crypto/cipher"
 	"crypto/ecdsa"
 	"crypto/elliptic"
-	"crypto/hmac"
 	"crypto/rand"
 	"errors"
 	"fmt"
@@ -32,6 +31,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/snappy"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -45,6 +45,10 @@ import (
 const (
 	maxUint24 = ^uint32(0) >> 8
 
+	// frames with a payload above this size are snappy compressed,
+	// smaller ones rarely shrink enough to be worth the CPU time
+	snappyThreshold = 512
+
 	sskLen = 16 // ecies.MaxSharedKeyLength(pubKey) / 2
 	sigLen = 65 // elliptic S256
 	pubLen = 64 // 512 bit pubkey in uncompressed representation without format byte
@@ -57,10 +61,6 @@ const (
 	encAuthMsgLen  = authMsgLen + eciesBytes  // size of the final ECIES payload sent as initiator's handshake
 	encAuthRespLen = authRespLen + eciesBytes // size of the final ECIES payload sent as receiver's handshake
 
-	// total timeout for encryption handshake and protocol
-	// handshake in both directions.
-	handshakeTimeout = 5 * time.Second
-
 	// This is the timeout for sending the disconnect reason.
 	// This is shorter than the usual timeout because we don't want
 	// to wait if the connection is known to be bad anyway.
@@ -87,10 +87,10 @@ type rlpx struct {
 
 	rmu, wmu sync.Mutex
 	rw       *rlpxFrameRW
+	sec      secrets
 }
 
 func newRLPX(fd net.Conn) transport {
-	fd.SetDeadline(time.Now().Add(handshakeTimeout))
 	return &rlpx{fd: fd}
 }
 
@@ -139,6 +139,16 @@ func (t *rlpx) doProtoHandshake(our *protoHandshake) (their *protoHandshake, err
 	if err := <-werr; err != nil {
 		return nil, fmt.Errorf("write error: %v", err)
 	}
+	// all frames following the handshake may be compressed if both sides advertise it
+	t.rw.snappy = hasCap(our.Caps, snappyCap) && hasCap(their.Caps, snappyCap)
+	// and sealed with ChaCha20-Poly1305 if both sides advertise it
+	if hasCap(our.Caps, aeadCap) && hasCap(their.Caps, aeadCap) {
+		aead, err := newAEADFrameCipher(t.sec)
+		if err != nil {
+			return nil, err
+		}
+		t.rw.cipher = aead
+	}
 	return their, nil
 }
 
@@ -191,6 +201,7 @@ func (t *rlpx) doEncHandshake(prv *ecdsa.PrivateKey, dial *discover.Node) (disco
 	}
 	t.wmu.Lock()
 	t.rw = newRLPXFrameRW(t.fd, sec)
+	t.sec = sec
 	t.wmu.Unlock()
 	return sec.RemoteID, nil
 }
@@ -212,6 +223,7 @@ type secrets struct {
 	RemoteID              discover.NodeID
 	AES, MAC              []byte
 	EgressMAC, IngressMAC hash.Hash
+	EgressKey, IngressKey []byte // keys of the ChaCha20-Poly1305 frames
 	Token                 []byte
 }
 
@@ -246,6 +258,15 @@ func (h *encHandshake) secrets(auth, authResp []byte) (secrets, error) {
 		s.EgressMAC, s.IngressMAC = mac2, mac1
 	}
 
+	// a key for each direction, so the nonces of the two sides never seal with the same key
+	initiatorKey := crypto.Keccak256(ecdheSecret, aesSecret, []byte("rlpx-aead-initiator"))
+	receiverKey := crypto.Keccak256(ecdheSecret, aesSecret, []byte("rlpx-aead-receiver"))
+	if h.initiator {
+		s.EgressKey, s.IngressKey = initiatorKey, receiverKey
+	} else {
+		s.EgressKey, s.IngressKey = receiverKey, initiatorKey
+	}
+
 	return s, nil
 }
 
@@ -487,135 +508,105 @@ var (
 	// this is used in place of actual frame header data.
 	// TODO: replace this when Msg contains the protocol type code.
 	zeroHeader = []byte{0xC2, 0x80, 0x80}
+	// header data of frames with a snappy compressed payload
+	snappyHeader = []byte{0xC2, 0x80, 0x01}
+	// snappyCap is advertised in the protocol handshake by the servers which can read snappyHeader
+	// frames, see Server.NoCompression. The base protocol version is unchanged, so the peers which
+	// don't know it ignore it and keep the uncompressed frames.
+	snappyCap = Cap{Name: "rlpx-snappy", Version: 1}
 	// sixteen zero bytes
 	zero16 = make([]byte, 16)
 )
 
 // rlpxFrameRW implements a simplified version of RLPx framing.
 // chunked messages are not supported and all headers are equal to
-// zeroHeader.
+// zeroHeader, or snappyHeader if the payload is compressed.
 //
 // rlpxFrameRW is not safe for concurrent use from multiple goroutines.
 type rlpxFrameRW struct {
-	conn io.ReadWriter
-	enc  cipher.Stream
-	dec  cipher.Stream
-
-	macCipher  cipher.Block
-	egressMAC  hash.Hash
-	ingressMAC hash.Hash
+	conn   io.ReadWriter
+	cipher frameCipher
+	snappy bool
 }
 
 func newRLPXFrameRW(conn io.ReadWriter, s secrets) *rlpxFrameRW {
-	macc, err := aes.NewCipher(s.MAC)
-	if err != nil {
-		panic("invalid MAC secret: " + err.Error())
-	}
-	encc, err := aes.NewCipher(s.AES)
-	if err != nil {
-		panic("invalid AES secret: " + err.Error())
-	}
-	// we use an all-zeroes IV for AES because the key used
-	// for encryption is ephemeral.
-	iv := make([]byte, encc.BlockSize())
 	return &rlpxFrameRW{
-		conn:       conn,
-		enc:        cipher.NewCTR(encc, iv),
-		dec:        cipher.NewCTR(encc, iv),
-		macCipher:  macc,
-		egressMAC:  s.EgressMAC,
-		ingressMAC: s.IngressMAC,
+		conn:   conn,
+		cipher: newLegacyFrameCipher(s),
 	}
 }
 
 func (rw *rlpxFrameRW) WriteMsg(msg Msg) error {
 	ptype, _ := rlp.EncodeToBytes(msg.Code)
 
-	// write header
-	headbuf := make([]byte, 32)
-	fsize := uint32(len(ptype)) + msg.Size
-	if fsize > maxUint24 {
-		return errors.New("message size overflows uint24")
-	}
-	putInt24(fsize, headbuf) // TODO: check overflow
-	copy(headbuf[3:], zeroHeader)
-	rw.enc.XORKeyStream(headbuf[:16], headbuf[:16]) // first half is now encrypted
-
-	// write header MAC
-	copy(headbuf[16:], updateMAC(rw.egressMAC, rw.macCipher, headbuf[:16]))
-	if _, err := rw.conn.Write(headbuf); err != nil {
-		return err
-	}
-
-	// write encrypted frame, updating the egress MAC hash with
-	// the data written to conn.
-	tee := cipher.StreamWriter{S: rw.enc, W: io.MultiWriter(rw.conn, rw.egressMAC)}
-	if _, err := tee.Write(ptype); err != nil {
-		return err
-	}
-	if _, err := io.Copy(tee, msg.Payload); err != nil {
+	// compress the payload if it's worth it
+	header := zeroHeader
+	payload, err := io.ReadAll(msg.Payload)
+	if err != nil {
 		return err
 	}
-	if padding := fsize % 16; padding > 0 {
-		if _, err := tee.Write(zero16[:16-padding]); err != nil {
-			return err
+	if rw.snappy && len(payload) > snappyThreshold {
+		if compressed := snappy.Encode(nil, payload); len(compressed) < len(payload) {
+			header = snappyHeader
+			payload = compressed
 		}
 	}
 
-	// write frame MAC. egress MAC hash is up to date because
-	// frame content was written to it as well.
-	fmacseed := rw.egressMAC.Sum(nil)
-	mac := updateMAC(rw.egressMAC, rw.macCipher, fmacseed)
-	_, err := rw.conn.Write(mac)
-	return err
+	fsize := uint32(len(ptype)) + uint32(len(payload))
+	if fsize > maxUint24 {
+		return errors.New("message size overflows uint24")
+	}
+	headbuf := make([]byte, frameHeaderLen)
+	putInt24(fsize, headbuf)
+	copy(headbuf[3:], header)
+	return rw.cipher.writeFrame(rw.conn, headbuf, append(ptype, payload...))
 }
 
 func (rw *rlpxFrameRW) ReadMsg() (msg Msg, err error) {
-	// read the header
-	headbuf := make([]byte, 32)
-	if _, err := io.ReadFull(rw.conn, headbuf); err != nil {
+	headbuf, err := rw.cipher.readHeader(rw.conn)
+	if err != nil {
 		return msg, err
 	}
-	// verify header mac
-	shouldMAC := updateMAC(rw.ingressMAC, rw.macCipher, headbuf[:16])
-	if !hmac.Equal(shouldMAC, headbuf[16:]) {
-		return msg, errors.New("bad header MAC")
-	}
-	rw.dec.XORKeyStream(headbuf[:16], headbuf[:16]) // first half is now decrypted
 	fsize := readInt24(headbuf)
-	// ignore protocol type for now
-
-	// read the frame content
-	var rsize = fsize // frame size rounded up to 16 byte boundary
-	if padding := fsize % 16; padding > 0 {
-		rsize += 16 - padding
-	}
-	framebuf := make([]byte, rsize)
-	if _, err := io.ReadFull(rw.conn, framebuf); err != nil {
-		return msg, err
+	// ignore protocol type for now, only check for compression
+	compressed := bytes.Equal(headbuf[3:3+len(snappyHeader)], snappyHeader)
+	if compressed && !rw.snappy {
+		return msg, errors.New("unexpected compressed frame")
 	}
 
-	// read and validate frame MAC. we can re-use headbuf for that.
-	rw.ingressMAC.Write(framebuf)
-	fmacseed := rw.ingressMAC.Sum(nil)
-	if _, err := io.ReadFull(rw.conn, headbuf[:16]); err != nil {
+	// read the frame content
+	framebuf, err := rw.cipher.readContent(rw.conn, fsize)
+	if err != nil {
 		return msg, err
 	}
-	shouldMAC = updateMAC(rw.ingressMAC, rw.macCipher, fmacseed)
-	if !hmac.Equal(shouldMAC, headbuf[:16]) {
-		return msg, errors.New("bad frame MAC")
-	}
-
-	// decrypt frame content
-	rw.dec.XORKeyStream(framebuf, framebuf)
 
 	// decode message code
-	content := bytes.NewReader(framebuf[:fsize])
+	content := bytes.NewReader(framebuf)
 	if err := rlp.Decode(content, &msg.Code); err != nil {
 		return msg, err
 	}
 	msg.Size = uint32(content.Len())
 	msg.Payload = content
+
+	// decompress the payload if needed
+	if compressed {
+		payload, err := io.ReadAll(content)
+		if err != nil {
+			return msg, err
+		}
+		size, err := snappy.DecodedLen(payload)
+		if err != nil {
+			return msg, err
+		}
+		if size > int(maxUint24) {
+			return msg, errors.New("message size overflows uint24")
+		}
+		payload, err = snappy.Decode(nil, payload)
+		if err != nil {
+			return msg, err
+		}
+		msg.Size, msg.Payload = uint32(size), bytes.NewReader(payload)
+	}
 	return msg, nil
 }
 
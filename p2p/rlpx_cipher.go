@@ -0,0 +1,215 @@
+package p2p
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// frameHeaderLen is the size of the plain frame header: the frame size and the header data, zero padded.
+	frameHeaderLen = 16
+	// sealedHeaderLen is the size of a header on the wire, followed by the sealed frame content.
+	sealedHeaderLen = 32
+)
+
+var (
+	// aeadCap is advertised in the protocol handshake by the servers which can seal the frames with
+	// ChaCha20-Poly1305, see Server.AEADFrames. The frames following the handshake switch to it once
+	// both sides advertise it, peers which don't keep the legacy AES-CTR and MAC construction.
+	aeadCap = Cap{Name: "rlpx-aead", Version: 1}
+)
+
+func hasCap(caps []Cap, cap Cap) bool {
+	for _, c := range caps {
+		if c == cap {
+			return true
+		}
+	}
+	return false
+}
+
+// frameCipher seals and opens the frames of a connection, once the encryption handshake is done.
+//
+// Frames are a header of frameHeaderLen bytes followed by their content, the code and the payload of a message.
+type frameCipher interface {
+	writeFrame(w io.Writer, header, content []byte) error
+	readHeader(r io.Reader) ([]byte, error)
+	readContent(r io.Reader, size uint32) ([]byte, error)
+}
+
+// legacyFrameCipher is the RLPx construction: AES-CTR encrypted frames, authenticated by MACs
+// which are running Keccak hashes of all the data sent in each direction.
+type legacyFrameCipher struct {
+	enc cipher.Stream
+	dec cipher.Stream
+
+	macCipher  cipher.Block
+	egressMAC  hash.Hash
+	ingressMAC hash.Hash
+}
+
+func newLegacyFrameCipher(s secrets) *legacyFrameCipher {
+	macc, err := aes.NewCipher(s.MAC)
+	if err != nil {
+		panic("invalid MAC secret: " + err.Error())
+	}
+	encc, err := aes.NewCipher(s.AES)
+	if err != nil {
+		panic("invalid AES secret: " + err.Error())
+	}
+	// we use an all-zeroes IV for AES because the key used
+	// for encryption is ephemeral.
+	iv := make([]byte, encc.BlockSize())
+	return &legacyFrameCipher{
+		enc:        cipher.NewCTR(encc, iv),
+		dec:        cipher.NewCTR(encc, iv),
+		macCipher:  macc,
+		egressMAC:  s.EgressMAC,
+		ingressMAC: s.IngressMAC,
+	}
+}
+
+func (c *legacyFrameCipher) writeFrame(w io.Writer, header, content []byte) error {
+	headbuf := make([]byte, sealedHeaderLen)
+	copy(headbuf, header)
+	c.enc.XORKeyStream(headbuf[:16], headbuf[:16]) // first half is now encrypted
+
+	// write header MAC
+	copy(headbuf[16:], updateMAC(c.egressMAC, c.macCipher, headbuf[:16]))
+	if _, err := w.Write(headbuf); err != nil {
+		return err
+	}
+
+	// write encrypted frame, updating the egress MAC hash with
+	// the data written to conn.
+	tee := cipher.StreamWriter{S: c.enc, W: io.MultiWriter(w, c.egressMAC)}
+	if _, err := tee.Write(content); err != nil {
+		return err
+	}
+	if padding := len(content) % 16; padding > 0 {
+		if _, err := tee.Write(zero16[:16-padding]); err != nil {
+			return err
+		}
+	}
+
+	// write frame MAC. egress MAC hash is up to date because
+	// frame content was written to it as well.
+	fmacseed := c.egressMAC.Sum(nil)
+	mac := updateMAC(c.egressMAC, c.macCipher, fmacseed)
+	_, err := w.Write(mac)
+	return err
+}
+func (c *legacyFrameCipher) readHeader(r io.Reader) ([]byte, error) {
+	headbuf := make([]byte, sealedHeaderLen)
+	if _, err := io.ReadFull(r, headbuf); err != nil {
+		return nil, err
+	}
+	// verify header mac
+	shouldMAC := updateMAC(c.ingressMAC, c.macCipher, headbuf[:16])
+	if !hmac.Equal(shouldMAC, headbuf[16:]) {
+		return nil, errors.New("bad header MAC")
+	}
+	c.dec.XORKeyStream(headbuf[:16], headbuf[:16]) // first half is now decrypted
+	return headbuf[:16], nil
+}
+func (c *legacyFrameCipher) readContent(r io.Reader, size uint32) ([]byte, error) {
+	var rsize = size // frame size rounded up to 16 byte boundary
+	if padding := size % 16; padding > 0 {
+		rsize += 16 - padding
+	}
+	framebuf := make([]byte, rsize)
+	if _, err := io.ReadFull(r, framebuf); err != nil {
+		return nil, err
+	}
+
+	// read and validate frame MAC
+	c.ingressMAC.Write(framebuf)
+	fmacseed := c.ingressMAC.Sum(nil)
+	macbuf := make([]byte, 16)
+	if _, err := io.ReadFull(r, macbuf); err != nil {
+		return nil, err
+	}
+	shouldMAC := updateMAC(c.ingressMAC, c.macCipher, fmacseed)
+	if !hmac.Equal(shouldMAC, macbuf) {
+		return nil, errors.New("bad frame MAC")
+	}
+
+	// decrypt frame content
+	c.dec.XORKeyStream(framebuf, framebuf)
+	return framebuf[:size], nil
+}
+
+// aeadFrameCipher seals the headers and the contents of the frames with ChaCha20-Poly1305. Each direction
+// has its own key and the nonces count the sealed messages, so a reordered or replayed frame fails to open.
+// A sealed header is as long as a legacy one.
+type aeadFrameCipher struct {
+	egress, ingress           cipher.AEAD
+	egressNonce, ingressNonce uint64
+}
+
+func newAEADFrameCipher(s secrets) (*aeadFrameCipher, error) {
+	egress, err := chacha20poly1305.New(s.EgressKey)
+	if err != nil {
+		return nil, err
+	}
+	ingress, err := chacha20poly1305.New(s.IngressKey)
+	if err != nil {
+		return nil, err
+	}
+	return &aeadFrameCipher{egress: egress, ingress: ingress}, nil
+}
+
+func (c *aeadFrameCipher) seal(data []byte) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[4:], c.egressNonce)
+	c.egressNonce += 1
+	return c.egress.Seal(nil, nonce, data, nil)
+}
+
+// open reads the next sealed message, whose plain content is size bytes.
+func (c *aeadFrameCipher) open(r io.Reader, size int) ([]byte, bool, error) {
+	sealed := make([]byte, size+chacha20poly1305.Overhead)
+	if _, err := io.ReadFull(r, sealed); err != nil {
+		return nil, false, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[4:], c.ingressNonce)
+	c.ingressNonce += 1
+	data, err := c.ingress.Open(sealed[:0], nonce, sealed, nil)
+	return data, err == nil, nil
+}
+
+func (c *aeadFrameCipher) writeFrame(w io.Writer, header, content []byte) error {
+	if _, err := w.Write(c.seal(header)); err != nil {
+		return err
+	}
+	_, err := w.Write(c.seal(content))
+	return err
+}
+func (c *aeadFrameCipher) readHeader(r io.Reader) ([]byte, error) {
+	header, ok, err := c.open(r, frameHeaderLen)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("bad header seal")
+	}
+	return header, nil
+}
+func (c *aeadFrameCipher) readContent(r io.Reader, size uint32) ([]byte, error) {
+	content, ok, err := c.open(r, int(size))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("bad frame seal")
+	}
+	return content, nil
+}
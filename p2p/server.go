@@ -49,7 +49,10 @@ const (
 	frameWriteTimeout = 20 * time.Second
 )
 
-var errServerStopped = errors.New("server stopped")
+var (
+	errServerStopped    = errors.New("server stopped")
+	errHandshakeTimeout = errors.New("handshake timeout")
+)
 
 // Server manages all peer connections.
 //
@@ -62,18 +65,46 @@ type Server struct {
 
 	// MaxPeers is the maximum number of peers that can be
 	// connected. It must be greater than zero.
+	// Trusted and static peers are not counted against it.
 	MaxPeers int
 
 	// MinConnectedPeers is the minimum number of peers that can be connected.
+	// It is also the number of outbound peers the dialer aims for.
 	MinConnectedPeers int
 
+	// MaxInboundPeers is the maximum number of inbound peers. Zero reserves
+	// MinConnectedPeers of the MaxPeers slots for outbound connections.
+	MaxInboundPeers int
+
 	// MaxPendingPeers is the maximum number of peers that can be pending in the
 	// handshake phase, counted separately for inbound and outbound connections.
 	// Zero defaults to preset values.
 	MaxPendingPeers int
 
+	// MaxPeersPerSubnet is the maximum number of peers from the same /24 IPv4
+	// or /64 IPv6 subnet, so a single operator can't take all the slots and
+	// eclipse the node. MaxInboundPerIP is the maximum number of inbound
+	// connections from the same IP, including the ones in the handshakes.
+	// Trusted and static nodes and loopback addresses are exempt. Zero means no limit.
+	MaxPeersPerSubnet int
+	MaxInboundPerIP   int
+
+	// HandshakeTimeout is the time budget of a new connection to complete both the
+	// encryption and the protocol handshakes. Zero defaults to 5 seconds.
+	HandshakeTimeout time.Duration
+
+	// UploadRate and DownloadRate cap the bandwidth of all the peer connections together,
+	// PeerUploadRate and PeerDownloadRate the one of each connection, in bytes per second.
+	// Zero means no limit.
+	UploadRate       int
+	DownloadRate     int
+	PeerUploadRate   int
+	PeerDownloadRate int
+
 	// Discovery specifies whether the peer discovery mechanism should be started
-	// or not. Disabling is usually useful for protocol debugging (manual topology).
+	// or not. Disabling is usually useful for protocol debugging (manual topology)
+	// or when dialing through a proxy, since discovery runs over UDP. Without
+	// discovery, the bootstrap nodes are the only candidates of the dynamic dials.
 	Discovery bool
 
 	// Name sets the node name of this server.
@@ -92,6 +123,12 @@ type Server struct {
 	// allowed to connect, even above the peer limit.
 	TrustedNodes []*discover.Node
 
+	// PrivatePeering restricts the peers to the static and trusted nodes, like a
+	// producing node which only peers with the sentry nodes it operates. Other
+	// nodes are neither discovered nor dialed and their inbound connections are
+	// rejected.
+	PrivatePeering bool
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string
@@ -101,6 +138,10 @@ type Server struct {
 	// each peer.
 	Protocols []Protocol
 
+	// Capabilities are advertised in the discovery DHT next to the versions of
+	// the Protocols, so other nodes can look up the nodes which serve them.
+	Capabilities []string
+
 	// If ListenAddr is set to a non-nil address, the server
 	// will listen for incoming connections.
 	//
@@ -115,12 +156,37 @@ type Server struct {
 	NAT nat.Interface
 
 	// If Dialer is set to a non-nil value, the given Dialer
-	// is used to dial outbound peer connections.
-	Dialer *net.Dialer
+	// is used to dial outbound peer connections, see NewSOCKS5Dialer.
+	// The SOCKS5 dialer disables the discovery and the listener.
+	Dialer NodeDialer
+
+	// If ListenFunc is set to a non-nil value, it is used instead of
+	// net.Listen to accept inbound peer connections, like the virtual
+	// networks of p2p/simulations. Its listener must report a *net.TCPAddr.
+	ListenFunc func(network, addr string) (net.Listener, error)
 
 	// If NoDial is true, the server will not dial any peers.
 	NoDial bool
 
+	// AEADFrames advertises the ChaCha20-Poly1305 sealing of the frames in the protocol
+	// handshake. The connections to peers which advertise it too switch to it after the
+	// handshake, the other ones keep the legacy AES-CTR and MAC frames.
+	AEADFrames bool
+
+	// NoCompression stops advertising the snappy compression of the frames in the protocol
+	// handshake, so the connections never compress them, like with the peers which don't
+	// support it.
+	NoCompression bool
+
+	// NetworkID identifies the chain of the server, like a hash of its genesis. It's
+	// advertised in the protocol handshake and the peers which advertise another one
+	// are disconnected with DiscNetworkMismatch before any protocol runs. Peers which
+	// don't advertise a network are accepted, the protocols check them.
+	NetworkID string
+
+	// Metadata is the opt-in telemetry of the node, advertised in the protocol handshake if set.
+	Metadata *PeerMetadata
+
 	// Hooks for testing. These are useful because we can inhibit
 	// the whole protocol stack.
 	newTransport func(net.Conn) transport
@@ -129,10 +195,23 @@ type Server struct {
 	lock    sync.Mutex // protects running
 	running bool
 
+	eventsLock sync.Mutex // protects eventSubs
+	eventSubs  map[chan *PeerEvent]struct{}
+
+	disconnects disconnectLog
+
 	ntab         discoverTable
 	listener     net.Listener
 	ourHandshake *protoHandshake
 	lastLookup   time.Time
+	dynPeers     int
+	handshakes   handshakeMeter
+	inbound      inboundIPs
+	exemptIPs    map[string]bool
+	loop         loopState
+
+	uploadLimiter   *rateLimiter
+	downloadLimiter *rateLimiter
 
 	// These are for Peers, PeerCount (and nothing else).
 	peerOp     chan peerOpFunc
@@ -162,11 +241,12 @@ const (
 type conn struct {
 	fd net.Conn
 	transport
-	flags connFlag
-	cont  chan error      // The run loop uses cont to signal errors to setupConn.
-	id    discover.NodeID // valid after the encryption handshake
-	caps  []Cap           // valid after the protocol handshake
-	name  string          // valid after the protocol handshake
+	flags   connFlag
+	cont    chan error      // The run loop uses cont to signal errors to setupConn.
+	id      discover.NodeID // valid after the encryption handshake
+	caps    []Cap           // valid after the protocol handshake
+	name    string          // valid after the protocol handshake
+	version uint64          // valid after the protocol handshake
 }
 
 type transport interface {
@@ -245,6 +325,61 @@ func (srv *Server) PeerCount() int {
 	return count
 }
 
+// HandshakeStats returns the statistics of the handshakes of new connections since the server started.
+func (srv *Server) HandshakeStats() *HandshakeStats {
+	return srv.handshakes.get(srv.handshakeTimeout())
+}
+
+func (srv *Server) handshakeTimeout() time.Duration {
+	if srv.HandshakeTimeout > 0 {
+		return srv.HandshakeTimeout
+	}
+	return DefaultHandshakeTimeout * time.Second
+}
+
+// PeerSlots describes the connection slot budgets of the server and their usage.
+type PeerSlots struct {
+	MaxPeers        int `json:"maxPeers"`
+	MaxInboundPeers int `json:"maxInboundPeers"`
+
+	Inbound  int `json:"inbound"`
+	Outbound int `json:"outbound"`
+	Trusted  int `json:"trusted"`
+}
+
+// PeerSlots returns the connection slot budgets and how many of them are used.
+func (srv *Server) PeerSlots() *PeerSlots {
+	slots := new(PeerSlots)
+	select {
+	case srv.peerOp <- func(ps map[discover.NodeID]*Peer) {
+		slots.MaxPeers = srv.MaxPeers
+		slots.MaxInboundPeers = srv.maxInboundPeers()
+		slots.Inbound, slots.Outbound, slots.Trusted = countSlots(ps)
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+	}
+	return slots
+}
+
+// SetMaxPeers changes the peer limit of the running server.
+// Connected peers are kept, even if they exceed the new limit.
+func (srv *Server) SetMaxPeers(maxPeers int) error {
+	if maxPeers <= 0 {
+		return errors.New("MaxPeers must be greater than zero")
+	}
+	select {
+	case srv.peerOp <- func(map[discover.NodeID]*Peer) {
+		common.P2PLogger.Info("changing max peers", "old", srv.MaxPeers, "new", maxPeers)
+		srv.MaxPeers = maxPeers
+	}:
+		<-srv.peerOpDone
+	case <-srv.quit:
+		return errors.New("server stopped")
+	}
+	return nil
+}
+
 // AddPeer connects to the given node and maintains the connection until the
 // server is shut down. If the connection fails for any reason, the server will
 // attempt to reconnect the peer.
@@ -282,6 +417,18 @@ func (srv *Server) Self() *discover.Node {
 	return srv.ntab.Self()
 }
 
+// topics returns the discovery topics advertised by the local node.
+func (srv *Server) topics() []discover.Topic {
+	topics := make([]discover.Topic, 0, len(srv.ourHandshake.Caps)+len(srv.Capabilities))
+	for _, cap := range srv.ourHandshake.Caps {
+		topics = append(topics, discover.Topic(cap.String()))
+	}
+	for _, capability := range srv.Capabilities {
+		topics = append(topics, discover.Topic(capability))
+	}
+	return topics
+}
+
 // Stop terminates the server and all active peer connections.
 // It blocks until all active connections have been closed.
 func (srv *Server) Stop() {
@@ -321,6 +468,8 @@ func (srv *Server) Start() (err error) {
 	if srv.Dialer == nil {
 		srv.Dialer = &net.Dialer{Timeout: defaultDialTimeout}
 	}
+	srv.uploadLimiter = newRateLimiter(srv.UploadRate)
+	srv.downloadLimiter = newRateLimiter(srv.DownloadRate)
 	srv.quit = make(chan struct{})
 	srv.addpeer = make(chan *conn)
 	srv.delpeer = make(chan *Peer)
@@ -328,9 +477,13 @@ func (srv *Server) Start() (err error) {
 	srv.addstatic = make(chan *discover.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
+	srv.exemptIPs = make(map[string]bool)
+	for _, n := range append(srv.StaticNodes, srv.TrustedNodes...) {
+		srv.exemptIPs[n.IP.String()] = true
+	}
 
 	// node table
-	if srv.Discovery {
+	if srv.Discovery && !srv.PrivatePeering && !srv.proxied() {
 		ntab, err := discover.ListenUDP(srv.PrivateKey, srv.ListenAddr, srv.NAT, srv.NodeDatabase)
 		if err != nil {
 			return err
@@ -338,18 +491,43 @@ func (srv *Server) Start() (err error) {
 		srv.ntab = ntab
 	}
 
-	dynPeers := srv.MinConnectedPeers
-	if !srv.Discovery {
-		dynPeers = 0
+	srv.dynPeers = srv.MinConnectedPeers
+	if srv.ntab == nil && (srv.PrivatePeering || len(srv.BootstrapNodes) == 0) {
+		srv.dynPeers = 0
 	}
-	dialer := newDialState(srv.StaticNodes, srv.ntab, dynPeers)
+	var previous []*discover.Node
+	if srv.ntab != nil {
+		protocols := make([]string, 0, len(srv.Protocols))
+		for _, p := range srv.Protocols {
+			protocols = append(protocols, p.cap().String())
+		}
+		previous = srv.ntab.PreviousPeers(srv.dynPeers, protocols)
+		common.P2PLogger.Info("loaded previous peers", "count", len(previous))
+	}
+	dialer := newDialState(srv.StaticNodes, previous, srv.BootstrapNodes, srv.ntab, srv.dynPeers, srv.MaxPeersPerSubnet)
 
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
 	for _, p := range srv.Protocols {
 		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, p.cap())
 	}
-	// listen/dial
+	if !srv.NoCompression {
+		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, snappyCap)
+	}
+	if srv.AEADFrames {
+		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, aeadCap)
+	}
+	if srv.NetworkID != "" {
+		srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, networkCap(srv.NetworkID))
+	}
+	srv.ourHandshake.Caps = append(srv.ourHandshake.Caps, metadataCaps(srv.Metadata)...)
+	if srv.ntab != nil {
+		srv.ntab.SetTopics(srv.topics())
+	}
+	// listen/dial, a node which dials through a proxy doesn't reveal its address
+	if srv.proxied() {
+		srv.ListenAddr = ""
+	}
 	if srv.ListenAddr != "" {
 		if err := srv.startListening(); err != nil {
 			return err
@@ -368,9 +546,20 @@ func (srv *Server) Start() (err error) {
 	return nil
 }
 
+// proxied returns whether the outbound connections go through a SOCKS5 proxy. Neither the discovery,
+// which runs over UDP, nor the inbound connections and their NAT mapping are enabled then.
+func (srv *Server) proxied() bool {
+	_, ok := srv.Dialer.(*socks5Dialer)
+	return ok
+}
+
 func (srv *Server) startListening() error {
 	// Launch the TCP listener.
-	listener, err := net.Listen("tcp", srv.ListenAddr)
+	listen := net.Listen
+	if srv.ListenFunc != nil {
+		listen = srv.ListenFunc
+	}
+	listener, err := listen("tcp", srv.ListenAddr)
 	if err != nil {
 		return err
 	}
@@ -413,6 +602,9 @@ func (srv *Server) run(dialstate dialer) {
 	for _, n := range srv.TrustedNodes {
 		trusted[n.ID] = true
 	}
+	srv.loop.lock.Lock()
+	srv.loop.stage, srv.loop.peers, srv.loop.taskdone = loopStageRunning, nil, taskdone
+	srv.loop.lock.Unlock()
 
 	// removes t from runningTasks
 	delTask := func(t task) {
@@ -453,6 +645,7 @@ func (srv *Server) run(dialstate dialer) {
 			nt := dialstate.newTasks(len(runningTasks)+len(queuedTasks), peers, time.Now())
 			queuedTasks = append(queuedTasks, startTasks(nt)...)
 		}
+		srv.loop.setTasks(len(runningTasks), len(queuedTasks))
 	}
 
 running:
@@ -483,6 +676,7 @@ running:
 			common.P2PLogger.Debug("<-taskdone:", "task", t)
 			dialstate.taskDone(t, now)
 			delTask(t)
+			srv.loop.setTasks(len(runningTasks), len(queuedTasks))
 		case c := <-srv.posthandshake:
 			// A connection has passed the encryption handshake so
 			// the remote identity is known (but hasn't been verified yet).
@@ -504,6 +698,7 @@ running:
 				// The handshakes are done and it passed all checks.
 				p := newPeer(c, srv.Protocols)
 				peers[c.id] = p
+				srv.loop.addPeer(p)
 				srv.loopWG.Add(1)
 				go func() {
 					srv.runPeer(p)
@@ -519,17 +714,15 @@ running:
 			// A peer disconnected.
 			common.P2PLogger.Debug("<-delpeer:", "peer", p)
 			delete(peers, p.ID())
+			srv.loop.delPeer(p)
 		}
 	}
 	// Disconnect all peers.
+	srv.loop.setStage(loopStageSpindown)
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
 	}
 
-	// Terminate discovery. If there is a running lookup it will terminate soon.
-	if srv.ntab != nil {
-		srv.ntab.Close()
-	}
 	// Wait for peers to shut down. Pending connections and tasks are
 	// not handled here and will terminate soon-ish because srv.quit
 	// is closed.
@@ -538,7 +731,16 @@ running:
 		p := <-srv.delpeer
 		common.P2PLogger.Debug("<-delpeer (spindown):", "peer", p)
 		delete(peers, p.ID())
+		srv.loop.delPeer(p)
+	}
+
+	// Terminate discovery once the peers stored their connection history.
+	// If there is a running lookup it will terminate soon.
+	srv.loop.setStage(loopStageDiscovery)
+	if srv.ntab != nil {
+		srv.ntab.Close()
 	}
+	srv.loop.setStage(loopStageStopped)
 }
 
 func (srv *Server) protoHandshakeChecks(peers map[discover.NodeID]*Peer, c *conn) error {
@@ -546,6 +748,9 @@ func (srv *Server) protoHandshakeChecks(peers map[discover.NodeID]*Peer, c *conn
 	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
 		return DiscUselessPeer
 	}
+	if network := capsNetwork(c.caps); srv.NetworkID != "" && network != "" && network != srv.NetworkID {
+		return DiscNetworkMismatch
+	}
 	// Repeat the encryption handshake checks because the
 	// peer set might have changed between the handshakes.
 	return srv.encHandshakeChecks(peers, c)
@@ -553,17 +758,57 @@ func (srv *Server) protoHandshakeChecks(peers map[discover.NodeID]*Peer, c *conn
 
 func (srv *Server) encHandshakeChecks(peers map[discover.NodeID]*Peer, c *conn) error {
 	switch {
-	case !c.is(trustedConn|staticDialedConn) && len(peers) >= srv.MaxPeers:
-		return DiscTooManyPeers
 	case peers[c.id] != nil:
 		return DiscAlreadyConnected
 	case c.id == srv.Self().ID:
 		return DiscSelf
+	case c.is(trustedConn | staticDialedConn):
+		// the trusted and static peers are always accepted, they don't take any slot
+		return nil
+	case srv.PrivatePeering:
+		return DiscUselessPeer
+	}
+
+	inbound, outbound, _ := countSlots(peers)
+	switch {
+	case inbound+outbound >= srv.MaxPeers:
+		return DiscTooManyPeers
+	case c.is(inboundConn) && inbound >= srv.maxInboundPeers():
+		return DiscTooManyPeers
+	case srv.subnetFull(peers, c):
+		return DiscSubnetLimit
 	default:
 		return nil
 	}
 }
 
+// maxInboundPeers returns the inbound budget, by default the
+// slots the dialer doesn't aim to fill are given to inbound peers.
+func (srv *Server) maxInboundPeers() int {
+	if srv.MaxInboundPeers > 0 {
+		return srv.MaxInboundPeers
+	}
+	if inbound := srv.MaxPeers - srv.dynPeers; inbound > 0 {
+		return inbound
+	}
+	return 0
+}
+
+// countSlots returns the number of connected peers in each slot class.
+func countSlots(peers map[discover.NodeID]*Peer) (inbound, outbound, trusted int) {
+	for _, p := range peers {
+		switch {
+		case p.rw.is(trustedConn | staticDialedConn):
+			trusted += 1
+		case p.rw.is(inboundConn):
+			inbound += 1
+		default:
+			outbound += 1
+		}
+	}
+	return
+}
+
 // listenLoop runs in its own goroutine and accepts
 // inbound connections.
 func (srv *Server) listenLoop() {
@@ -587,7 +832,14 @@ func (srv *Server) listenLoop() {
 		if err != nil {
 			return
 		}
-		mfd := newMeteredConn(fd, true)
+		counted, ok := srv.acceptInbound(fd)
+		if !ok {
+			common.P2PLogger.Debug("rejected inbound conn, too many connections from the IP", "remote", fd.RemoteAddr())
+			fd.Close()
+			slots <- struct{}{}
+			continue
+		}
+		mfd := srv.newMeteredConn(counted, true)
 
 		common.P2PLogger.Debug(fmt.Sprintf("Accepted conn %v\n", mfd.RemoteAddr()))
 		srv.loopWG.Add(1)
@@ -603,8 +855,8 @@ func (srv *Server) listenLoop() {
 
 // setupConn runs the handshakes and attempts to add the connection
 // as a peer. It returns when the connection has been added as a peer
-// or the handshakes have failed.
-func (srv *Server) setupConn(fd net.Conn, flags connFlag, dialDest *discover.Node) {
+// or the handshakes have failed, in which case the reason is returned.
+func (srv *Server) setupConn(fd net.Conn, flags connFlag, dialDest *discover.Node) (err error) {
 	// Prevent leftover pending conns from entering the handshake.
 	srv.lock.Lock()
 	running := srv.running
@@ -612,54 +864,82 @@ func (srv *Server) setupConn(fd net.Conn, flags connFlag, dialDest *discover.Nod
 	c := &conn{fd: fd, transport: srv.newTransport(fd), flags: flags, cont: make(chan error)}
 	if !running {
 		c.close(errServerStopped)
-		return
+		return errServerStopped
+	}
+
+	// Both handshakes and the checks in between share a single deadline,
+	// so peers which stall on purpose can't hold a pending slot for longer.
+	start := time.Now()
+	timeout := srv.handshakeTimeout()
+	deadline := start.Add(timeout)
+	defer func() {
+		if err != errServerStopped {
+			srv.handshakes.mark(timeout, time.Since(start), err)
+		}
+		if err != nil && err != errServerStopped {
+			srv.postPeerEvent(newPeerEvent(PeerEventTypeHandshakeFailed, c, err))
+		}
+	}()
+	if err := fd.SetDeadline(deadline); err != nil {
+		c.close(err)
+		return err
 	}
 
 	// Run the encryption handshake.
-	var err error
 	if c.id, err = c.doEncHandshake(srv.PrivateKey, dialDest); err != nil {
 		common.P2PLogger.Debug(fmt.Sprintf("%v faild enc handshake: %v", c, err))
 		c.close(err)
-		return
+		return err
 	}
 	// For dialed connections, check that the remote public key matches.
 	if dialDest != nil && c.id != dialDest.ID {
 		c.close(DiscUnexpectedIdentity)
 		common.P2PLogger.Debug(fmt.Sprintf("%v dialed identity mismatch, want %x", c, dialDest.ID[:8]))
-		return
+		return DiscUnexpectedIdentity
 	}
-	if err := srv.checkpoint(c, srv.posthandshake); err != nil {
+	if err := srv.checkpoint(c, srv.posthandshake, deadline); err != nil {
 		common.P2PLogger.Debug(fmt.Sprintf("%v failed checkpoint posthandshake: %v", c, err))
 		c.close(err)
-		return
+		return err
 	}
 	// Run the protocol handshake
 	phs, err := c.doProtoHandshake(srv.ourHandshake)
 	if err != nil {
 		common.P2PLogger.Debug(fmt.Sprintf("%v failed proto handshake: %v", c, err))
 		c.close(err)
-		return
+		return err
 	}
 	if phs.ID != c.id {
 		common.P2PLogger.Debug(fmt.Sprintf("%v wrong proto handshake identity: %x", c, phs.ID[:8]))
 		c.close(DiscUnexpectedIdentity)
-		return
+		return DiscUnexpectedIdentity
 	}
-	c.caps, c.name = phs.Caps, phs.Name
-	if err := srv.checkpoint(c, srv.addpeer); err != nil {
+	c.caps, c.name, c.version = phs.Caps, phs.Name, phs.Version
+	// From now on the transport sets the deadline of each message.
+	if err := fd.SetDeadline(time.Time{}); err != nil {
+		c.close(err)
+		return err
+	}
+	if err := srv.checkpoint(c, srv.addpeer, deadline); err != nil {
 		common.P2PLogger.Debug(fmt.Sprintf("%v failed checkpoint addpeer: %v", c, err))
 		c.close(err)
-		return
+		return err
 	}
 	// If the checks completed successfully, runPeer has now been
 	// launched by run.
+	return nil
 }
 
 // checkpoint sends the conn to run, which performs the
 // post-handshake checks for the stage (posthandshake, addpeer).
-func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
+// The conn is dropped if run doesn't pick it up before the deadline.
+func (srv *Server) checkpoint(c *conn, stage chan<- *conn, deadline time.Time) error {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
 	select {
 	case stage <- c:
+	case <-timer.C:
+		return errHandshakeTimeout
 	case <-srv.quit:
 		return errServerStopped
 	}
@@ -680,10 +960,18 @@ func (srv *Server) runPeer(p *Peer) {
 	if srv.newPeerHook != nil {
 		srv.newPeerHook(p)
 	}
+	srv.postPeerEvent(newPeerEvent(PeerEventTypeAdd, p.rw, nil))
 	discreason := p.run()
+	if srv.ntab != nil {
+		srv.ntab.AddPeerConnection(p.ID(), p.Uptime(), p.BytesRead(), p.runningProtocols())
+	}
 	// Note: run waits for existing peers to be sent on srv.delpeer
 	// before returning, so this send should not select on srv.quit.
 	srv.delpeer <- p
+	srv.postPeerEvent(newPeerEvent(PeerEventTypeDrop, p.rw, discreason))
 
-	common.P2PLogger.Debug(fmt.Sprintf("Removed %v (%v)\n", p, discreason))
+	disconnect := newDisconnect(p, discreason)
+	srv.disconnects.add(disconnect)
+	common.P2PLogger.Info("peer disconnected", "peer", p, "name", disconnect.Name, "inbound", disconnect.Inbound,
+		"reason", disconnect.Reason, "remote", disconnect.Remote, "error", disconnect.Error, "duration", p.Uptime())
 }
@@ -0,0 +1,55 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+func testCheckedConn(id byte, flags connFlag) *conn {
+	c := &conn{flags: flags}
+	c.id[0] = id
+	return c
+}
+
+// Test the slot checks after the encryption handshake
+//   - test the inbound and dialed peers are refused once the slots are full
+//   - test the trusted and static peers are accepted with full slots
+//   - test the duplicate connections are refused for all the peers
+//   - test only the trusted and static peers are accepted with private peering
+func TestServer_EncHandshakeChecks(t *testing.T) {
+	srv := &Server{MaxPeers: 2, MaxInboundPeers: 1}
+	peers := make(map[discover.NodeID]*Peer)
+	for _, c := range []*conn{testCheckedConn(1, inboundConn), testCheckedConn(2, dynDialedConn), testCheckedConn(3, trustedConn|inboundConn)} {
+		peers[c.id] = newPeer(c, nil)
+	}
+
+	for _, test := range []struct {
+		name     string
+		c        *conn
+		expected error
+	}{
+		{"inbound", testCheckedConn(10, inboundConn), DiscTooManyPeers},
+		{"dialed", testCheckedConn(11, dynDialedConn), DiscTooManyPeers},
+		{"trusted inbound", testCheckedConn(12, trustedConn|inboundConn), nil},
+		{"static", testCheckedConn(13, staticDialedConn), nil},
+		{"connected inbound", testCheckedConn(1, inboundConn), DiscAlreadyConnected},
+		{"connected trusted", testCheckedConn(3, trustedConn|inboundConn), DiscAlreadyConnected},
+	} {
+		if err := srv.encHandshakeChecks(peers, test.c); err != test.expected {
+			t.Errorf("%v peer: expected %v, got %v", test.name, test.expected, err)
+		}
+	}
+
+	delete(peers, testCheckedConn(2, 0).id)
+	if err := srv.encHandshakeChecks(peers, testCheckedConn(11, dynDialedConn)); err != nil {
+		t.Fatalf("dialed peer refused with a free slot: %v", err)
+	}
+	srv.PrivatePeering = true
+	if err := srv.encHandshakeChecks(peers, testCheckedConn(11, dynDialedConn)); err != DiscUselessPeer {
+		t.Fatalf("expected %v with private peering, got %v", DiscUselessPeer, err)
+	}
+	if err := srv.encHandshakeChecks(peers, testCheckedConn(13, staticDialedConn)); err != nil {
+		t.Fatalf("static peer refused with private peering: %v", err)
+	}
+}
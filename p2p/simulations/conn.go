@@ -0,0 +1,236 @@
+package simulations
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// segment is a write in flight, it can be read once the link delivered it.
+type segment struct {
+	data []byte
+	at   time.Time
+}
+
+// stream is one direction of a virtual connection. Segments are delivered in order, like with TCP,
+// so a delayed segment holds back the ones written after it.
+type stream struct {
+	lock     sync.Mutex
+	queue    []*segment
+	last     time.Time // delivery time of the last segment
+	closed   bool      // the writer closed the connection, the queued segments are still delivered
+	aborted  bool      // the reader closed the connection
+	deadline time.Time
+	wake     chan struct{}
+}
+
+func newStream() *stream {
+	return &stream{wake: make(chan struct{}, 1)}
+}
+
+func (s *stream) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *stream) write(data []byte, at time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed || s.aborted {
+		return io.ErrClosedPipe
+	}
+	if at.Before(s.last) {
+		at = s.last
+	}
+	s.last = at
+	s.queue = append(s.queue, &segment{data: append([]byte{}, data...), at: at})
+	s.notify()
+	return nil
+}
+
+func (s *stream) read(b []byte) (int, error) {
+	for {
+		s.lock.Lock()
+		now := time.Now()
+		if s.aborted {
+			s.lock.Unlock()
+			return 0, net.ErrClosed
+		}
+		if len(s.queue) > 0 && !s.queue[0].at.After(now) {
+			n := copy(b, s.queue[0].data)
+			if s.queue[0].data = s.queue[0].data[n:]; len(s.queue[0].data) == 0 {
+				s.queue = s.queue[1:]
+			}
+			s.lock.Unlock()
+			return n, nil
+		}
+		if s.closed && len(s.queue) == 0 {
+			s.lock.Unlock()
+			return 0, io.EOF
+		}
+		if !s.deadline.IsZero() && !now.Before(s.deadline) {
+			s.lock.Unlock()
+			return 0, os.ErrDeadlineExceeded
+		}
+
+		// sleep until the next segment is delivered, the deadline or a change of the stream
+		wait := time.Duration(-1)
+		if len(s.queue) > 0 {
+			wait = s.queue[0].at.Sub(now)
+		}
+		if !s.deadline.IsZero() && (wait < 0 || s.deadline.Sub(now) < wait) {
+			wait = s.deadline.Sub(now)
+		}
+		s.lock.Unlock()
+
+		if wait < 0 {
+			<-s.wake
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (s *stream) setDeadline(deadline time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.deadline = deadline
+	s.notify()
+}
+
+// close ends the stream once the queued segments are delivered.
+func (s *stream) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	s.notify()
+}
+
+// abort ends the stream right away and drops the queued segments.
+func (s *stream) abort() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.aborted = true
+	s.queue = nil
+	s.notify()
+}
+
+// conn is an end of a virtual connection. Writes never block, they are delayed according
+// to the link between the two nodes when they are written.
+type conn struct {
+	network *Network
+	local   *net.TCPAddr
+	remote  *net.TCPAddr
+	in      *stream
+	out     *stream
+
+	lock          sync.Mutex
+	writeDeadline time.Time
+	closeOnce     sync.Once
+}
+
+// newConnPair returns the two ends of a connection dialed from local to remote.
+func newConnPair(network *Network, local, remote *net.TCPAddr) (*conn, *conn) {
+	forward, backward := newStream(), newStream()
+	dialer := &conn{network: network, local: local, remote: remote, in: backward, out: forward}
+	listener := &conn{network: network, local: remote, remote: local, in: forward, out: backward}
+	return dialer, listener
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	return c.in.read(b)
+}
+
+func (c *conn) Write(b []byte) (int, error) {
+	c.lock.Lock()
+	deadline := c.writeDeadline
+	c.lock.Unlock()
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, os.ErrDeadlineExceeded
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	delay, up := c.network.delay(c.local, c.remote)
+	if !up {
+		c.Close()
+		return 0, net.ErrClosed
+	}
+	if err := c.out.write(b, time.Now().Add(delay)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close resets the connection, the remote end reads the data written before it.
+func (c *conn) Close() error {
+	c.closeOnce.Do(func() {
+		c.in.abort()
+		c.out.close()
+		c.network.removeConn(c)
+	})
+	return nil
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return c.local
+}
+func (c *conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.in.setDeadline(t)
+	return nil
+}
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writeDeadline = t
+	return nil
+}
+
+// listener accepts the connections dialed to an address of the network.
+type listener struct {
+	network *Network
+	addr    *net.TCPAddr
+	accept  chan *conn
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+		l.network.removeListener(l)
+	})
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	return l.addr
+}
@@ -0,0 +1,363 @@
+// Package simulations runs p2p servers over an in-memory network, so protocols can be tested
+// with many nodes, slow or lossy links and partitions in a single process, without sockets.
+//
+// Nodes are regular p2p.Servers whose connections go through virtual links. Discovery is
+// disabled, the topology is set up with static peers, see Network.Connect.
+package simulations
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+const (
+	// listenPort is the port of all nodes, each node has its own IP
+	listenPort = 35995
+	// defaultRetransmitTimeout is the delay of a lost write if LinkConfig.RetransmitTimeout is zero
+	defaultRetransmitTimeout = 200 * time.Millisecond
+	// maxRetransmits bounds the delay of a write on a link with a high PacketLoss
+	maxRetransmits = 8
+)
+
+var (
+	ErrConnectionRefused = errors.New("connection refused")
+)
+
+// LinkConfig describes the links between the nodes of a network.
+type LinkConfig struct {
+	// Latency is the one way delay of a write, Jitter is the maximum random delay added to it.
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// PacketLoss is the probability, between 0 and 1, that a write is lost. Connections are reliable
+	// streams, like TCP, so lost writes are retransmitted after RetransmitTimeout rather than dropped.
+	PacketLoss        float64
+	RetransmitTimeout time.Duration
+
+	// Down links refuse the dials and reset the connections, which partitions the network.
+	Down bool
+}
+
+// linkKey identifies the link between two IPs, the smallest one first.
+type linkKey struct {
+	a, b string
+}
+
+func newLinkKey(a, b net.IP) linkKey {
+	if a.String() < b.String() {
+		return linkKey{a.String(), b.String()}
+	}
+	return linkKey{b.String(), a.String()}
+}
+
+// Network is an in-memory network of p2p servers.
+// The random delays and losses of the links are drawn from a seeded source, so a simulation
+// which performs the same writes in the same order sees the same links.
+type Network struct {
+	lock      sync.Mutex
+	random    *rand.Rand
+	config    LinkConfig
+	links     map[linkKey]LinkConfig
+	listeners map[string]*listener
+	conns     map[*conn]struct{}
+	nodes     []*Node
+	nextPort  int
+}
+
+// NewNetwork returns an empty network whose links have the default config.
+func NewNetwork(config LinkConfig, seed int64) *Network {
+	return &Network{
+		random:    rand.New(rand.NewSource(seed)),
+		config:    config,
+		links:     make(map[linkKey]LinkConfig),
+		listeners: make(map[string]*listener),
+		conns:     make(map[*conn]struct{}),
+		nextPort:  40000,
+	}
+}
+
+// Node is a p2p server of a network.
+type Node struct {
+	Server  *p2p.Server
+	network *Network
+	ip      net.IP
+}
+
+// ID returns the public key of the node.
+func (n *Node) ID() discover.NodeID {
+	return discover.PubkeyID(&n.Server.PrivateKey.PublicKey)
+}
+
+// Node returns the address which the other nodes dial.
+func (n *Node) Node() *discover.Node {
+	return &discover.Node{IP: n.ip.To4(), UDP: listenPort, TCP: listenPort, ID: n.ID()}
+}
+
+// WaitPeers waits until the node has at least count peers.
+func (n *Node) WaitPeers(count int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for n.Server.PeerCount() < count {
+		if time.Now().After(deadline) {
+			return errors.Errorf("node %v has %v peers after %v, expected %v", n.ip, n.Server.PeerCount(), timeout, count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+// AddNode starts a node running protocols.
+func (n *Network) AddNode(protocols ...p2p.Protocol) (*Node, error) {
+	return n.AddServer(&p2p.Server{
+		MaxPeers:  50,
+		Protocols: protocols,
+	})
+}
+
+// AddServer starts srv as a node of the network. A private key is generated if srv has none,
+// the listen address, the dialer and the discovery are set by the network.
+func (n *Network) AddServer(srv *p2p.Server) (*Node, error) {
+	return n.AddServerAt(nil, srv)
+}
+
+// AddServerAt starts srv as a node of the network with the given IP, like nodes which share
+// a subnet, see AddServer. A nil ip picks the next free one.
+func (n *Network) AddServerAt(ip net.IP, srv *p2p.Server) (*Node, error) {
+	if srv.PrivateKey == nil {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		srv.PrivateKey = key
+	}
+
+	n.lock.Lock()
+	index := len(n.nodes) + 1
+	if ip == nil {
+		// each node has its own /16, the protocol spreads some messages across networks
+		ip = net.IPv4(byte(10+index/256), byte(index%256), 0, 1)
+	}
+	node := &Node{
+		Server:  srv,
+		network: n,
+		ip:      ip,
+	}
+	n.nodes = append(n.nodes, node)
+	n.lock.Unlock()
+
+	if srv.Name == "" {
+		srv.Name = fmt.Sprintf("simulation/node-%v", index)
+	}
+	srv.Discovery = false
+	srv.ListenAddr = (&net.TCPAddr{IP: node.ip, Port: listenPort}).String()
+	srv.ListenFunc = n.listen
+	srv.Dialer = &dialer{network: n, ip: node.ip}
+	if err := srv.Start(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Nodes returns the nodes of the network, in the order they were added.
+func (n *Network) Nodes() []*Node {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return append([]*Node{}, n.nodes...)
+}
+
+// Shutdown stops all nodes.
+func (n *Network) Shutdown() {
+	for _, node := range n.Nodes() {
+		node.Server.Stop()
+	}
+}
+
+// SetLink overrides the default config for the link between a and b.
+// Connections between them are reset if the link goes down.
+func (n *Network) SetLink(a, b *Node, config LinkConfig) {
+	n.lock.Lock()
+	n.links[newLinkKey(a.ip, b.ip)] = config
+	reset := make([]*conn, 0)
+	if config.Down {
+		key := newLinkKey(a.ip, b.ip)
+		for c := range n.conns {
+			if newLinkKey(c.local.IP, c.remote.IP) == key {
+				reset = append(reset, c)
+			}
+		}
+	}
+	n.lock.Unlock()
+
+	for _, c := range reset {
+		c.Close()
+	}
+}
+
+// ResetLink restores the default config for the link between a and b.
+func (n *Network) ResetLink(a, b *Node) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	delete(n.links, newLinkKey(a.ip, b.ip))
+}
+
+// Partition takes down the links between the two groups of nodes.
+func (n *Network) Partition(group, other []*Node) {
+	for _, a := range group {
+		for _, b := range other {
+			n.lock.Lock()
+			config := n.link(a.ip, b.ip)
+			n.lock.Unlock()
+			config.Down = true
+			n.SetLink(a, b, config)
+		}
+	}
+}
+
+// Connect makes a keep a connection to b, it is dialed again if it drops.
+func (n *Network) Connect(a, b *Node) {
+	a.Server.AddPeer(b.Node())
+}
+
+// ConnectChain connects each node to the next one.
+func (n *Network) ConnectChain(nodes []*Node) {
+	for i := 0; i+1 < len(nodes); i += 1 {
+		n.Connect(nodes[i], nodes[i+1])
+	}
+}
+
+// ConnectRing connects each node to the next one and the last one to the first one.
+func (n *Network) ConnectRing(nodes []*Node) {
+	n.ConnectChain(nodes)
+	if len(nodes) > 2 {
+		n.Connect(nodes[len(nodes)-1], nodes[0])
+	}
+}
+
+// ConnectStar connects all nodes to center.
+func (n *Network) ConnectStar(center *Node, nodes []*Node) {
+	for _, node := range nodes {
+		if node != center {
+			n.Connect(node, center)
+		}
+	}
+}
+
+// ConnectFull connects each pair of nodes.
+func (n *Network) ConnectFull(nodes []*Node) {
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j += 1 {
+			n.Connect(nodes[i], nodes[j])
+		}
+	}
+}
+
+func (n *Network) link(a, b net.IP) LinkConfig {
+	if config, ok := n.links[newLinkKey(a, b)]; ok {
+		return config
+	}
+	return n.config
+}
+
+// delay returns the time a write from a to b takes to be delivered and false if the link is down.
+func (n *Network) delay(a, b *net.TCPAddr) (time.Duration, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	config := n.link(a.IP, b.IP)
+	if config.Down {
+		return 0, false
+	}
+	delay := config.Latency
+	if config.Jitter > 0 {
+		delay += time.Duration(n.random.Int63n(int64(config.Jitter) + 1))
+	}
+	retransmit := config.RetransmitTimeout
+	if retransmit == 0 {
+		retransmit = defaultRetransmitTimeout
+	}
+	for i := 0; i < maxRetransmits && config.PacketLoss > 0 && n.random.Float64() < config.PacketLoss; i += 1 {
+		delay += retransmit
+	}
+	return delay, true
+}
+
+func (n *Network) listen(network, addr string) (net.Listener, error) {
+	tcpAddr, err := net.ResolveTCPAddr(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if _, ok := n.listeners[tcpAddr.String()]; ok {
+		return nil, errors.Errorf("address %v already in use", tcpAddr)
+	}
+	l := &listener{
+		network: n,
+		addr:    tcpAddr,
+		accept:  make(chan *conn),
+		closed:  make(chan struct{}),
+	}
+	n.listeners[tcpAddr.String()] = l
+	return l, nil
+}
+
+func (n *Network) removeListener(l *listener) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.listeners[l.addr.String()] == l {
+		delete(n.listeners, l.addr.String())
+	}
+}
+
+func (n *Network) dial(ip net.IP, addr string) (net.Conn, error) {
+	remote, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	n.lock.Lock()
+	l, ok := n.listeners[remote.String()]
+	if !ok || n.link(ip, remote.IP).Down {
+		n.lock.Unlock()
+		return nil, ErrConnectionRefused
+	}
+	local := &net.TCPAddr{IP: ip, Port: n.nextPort}
+	n.nextPort += 1
+	dialed, accepted := newConnPair(n, local, remote)
+	n.conns[dialed] = struct{}{}
+	n.conns[accepted] = struct{}{}
+	n.lock.Unlock()
+
+	select {
+	case l.accept <- accepted:
+		return dialed, nil
+	case <-l.closed:
+		dialed.Close()
+		accepted.Close()
+		return nil, ErrConnectionRefused
+	}
+}
+
+func (n *Network) removeConn(c *conn) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	delete(n.conns, c)
+}
+
+// dialer dials the connections of a node.
+type dialer struct {
+	network *Network
+	ip      net.IP
+}
+
+func (d *dialer) Dial(_, addr string) (net.Conn, error) {
+	return d.network.dial(d.ip, addr)
+}
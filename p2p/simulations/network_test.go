@@ -0,0 +1,613 @@
+package simulations
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+// flood is a protocol which relays each new message to all peers.
+type flood struct {
+	lock     sync.Mutex
+	peers    map[*p2p.Peer]p2p.MsgReadWriter
+	received map[uint64]time.Time
+	// padding is appended to the messages published by the node, to make them bigger
+	padding []byte
+}
+
+type floodMsg struct {
+	ID      uint64
+	Padding []byte
+}
+
+func newFlood() *flood {
+	return &flood{
+		peers:    make(map[*p2p.Peer]p2p.MsgReadWriter),
+		received: make(map[uint64]time.Time),
+	}
+}
+
+func (f *flood) protocol() p2p.Protocol {
+	return p2p.Protocol{
+		Name:    "flood",
+		Version: 1,
+		Length:  1,
+		Run: func(peer *p2p.Peer, rw p2p.MsgReadWriter) error {
+			f.lock.Lock()
+			f.peers[peer] = rw
+			f.lock.Unlock()
+			defer func() {
+				f.lock.Lock()
+				delete(f.peers, peer)
+				f.lock.Unlock()
+			}()
+			for {
+				msg, err := rw.ReadMsg()
+				if err != nil {
+					return err
+				}
+				var data floodMsg
+				if err := msg.Decode(&data); err != nil {
+					return err
+				}
+				f.relay(data)
+			}
+		},
+	}
+}
+
+func (f *flood) publish(id uint64) {
+	f.relay(floodMsg{ID: id, Padding: f.padding})
+}
+
+func (f *flood) relay(data floodMsg) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if _, ok := f.received[data.ID]; ok {
+		return
+	}
+	f.received[data.ID] = time.Now()
+	for _, rw := range f.peers {
+		go p2p.Send(rw, 0, data)
+	}
+}
+
+func (f *flood) receivedAt(id uint64) (time.Time, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	at, ok := f.received[id]
+	return at, ok
+}
+
+func startFloodNetwork(t *testing.T, config LinkConfig, count int) (*Network, []*Node, []*flood) {
+	network := NewNetwork(config, 1)
+	t.Cleanup(network.Shutdown)
+	nodes := make([]*Node, count)
+	floods := make([]*flood, count)
+	for i := range nodes {
+		floods[i] = newFlood()
+		node, err := network.AddNode(floods[i].protocol())
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	return network, nodes, floods
+}
+
+func waitReceived(t *testing.T, floods []*flood, id uint64, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for i := range floods {
+		for {
+			if _, ok := floods[i].receivedAt(id); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("node %v did not receive message %v", i, id)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestNetwork_Chain(t *testing.T) {
+	latency := 50 * time.Millisecond
+	network, nodes, floods := startFloodNetwork(t, LinkConfig{Latency: latency}, 4)
+	network.ConnectChain(nodes)
+	for i, node := range nodes {
+		expected := 2
+		if i == 0 || i == len(nodes)-1 {
+			expected = 1
+		}
+		if err := node.WaitPeers(expected, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	floods[0].publish(1)
+	waitReceived(t, floods, 1, 10*time.Second)
+	sent, _ := floods[0].receivedAt(1)
+	received, _ := floods[3].receivedAt(1)
+	if elapsed := received.Sub(sent); elapsed < 3*latency {
+		t.Fatalf("message crossed 3 links in %v, expected at least %v", elapsed, 3*latency)
+	}
+}
+
+func TestNetwork_LossyRing(t *testing.T) {
+	network, nodes, floods := startFloodNetwork(t, LinkConfig{
+		Latency:           10 * time.Millisecond,
+		Jitter:            10 * time.Millisecond,
+		PacketLoss:        0.1,
+		RetransmitTimeout: 50 * time.Millisecond,
+	}, 8)
+	network.ConnectRing(nodes)
+	for _, node := range nodes {
+		if err := node.WaitPeers(2, 20*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id := uint64(1); id <= 10; id += 1 {
+		floods[int(id)%len(floods)].publish(id)
+	}
+	for id := uint64(1); id <= 10; id += 1 {
+		waitReceived(t, floods, id, 20*time.Second)
+	}
+}
+
+func TestNetwork_Partition(t *testing.T) {
+	network, nodes, floods := startFloodNetwork(t, LinkConfig{}, 4)
+	network.ConnectFull(nodes)
+	for _, node := range nodes {
+		if err := node.WaitPeers(3, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	network.Partition(nodes[:2], nodes[2:])
+	for _, node := range nodes {
+		for node.Server.PeerCount() != 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	floods[0].publish(1)
+	waitReceived(t, floods[:2], 1, 10*time.Second)
+	time.Sleep(100 * time.Millisecond)
+	for i := 2; i < len(floods); i += 1 {
+		if _, ok := floods[i].receivedAt(1); ok {
+			t.Fatalf("node %v received a message across the partition", i)
+		}
+	}
+}
+
+func TestNetwork_Disconnects(t *testing.T) {
+	network, nodes, _ := startFloodNetwork(t, LinkConfig{}, 2)
+	network.Connect(nodes[0], nodes[1])
+	for _, node := range nodes {
+		if err := node.WaitPeers(1, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	network.Partition(nodes[:1], nodes[1:])
+	for i, node := range nodes {
+		deadline := time.Now().Add(10 * time.Second)
+		for len(node.Server.RecentDisconnects()) == 0 {
+			if time.Now().After(deadline) {
+				t.Fatalf("node %v has no disconnects", i)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		other := nodes[1-i]
+		disconnect := node.Server.LastDisconnect(other.ID().String())
+		if disconnect == nil {
+			t.Fatalf("node %v has no disconnect of node %v", i, 1-i)
+		}
+		if disconnect.Inbound != (i == 1) {
+			t.Fatalf("node %v disconnect has inbound %v", i, disconnect.Inbound)
+		}
+		if len(disconnect.Protocols) != 1 || disconnect.Protocols[0] != "flood/1" {
+			t.Fatalf("node %v disconnect has protocols %v", i, disconnect.Protocols)
+		}
+		if disconnect.Reason == "" || disconnect.Version == 0 {
+			t.Fatalf("node %v disconnect is incomplete %+v", i, disconnect)
+		}
+	}
+}
+
+func TestNetwork_MixedFrameCiphers(t *testing.T) {
+	network := NewNetwork(LinkConfig{}, 1)
+	t.Cleanup(network.Shutdown)
+	// the chain has sealed links between AEAD nodes and legacy links with the node in the middle
+	aead := []bool{true, true, false, true}
+	nodes := make([]*Node, len(aead))
+	floods := make([]*flood, len(aead))
+	for i := range nodes {
+		floods[i] = newFlood()
+		node, err := network.AddServer(&p2p.Server{
+			MaxPeers:   50,
+			Protocols:  []p2p.Protocol{floods[i].protocol()},
+			AEADFrames: aead[i],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	network.ConnectChain(nodes)
+	for i, node := range nodes {
+		expected := 2
+		if i == 0 || i == len(nodes)-1 {
+			expected = 1
+		}
+		if err := node.WaitPeers(expected, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, peer := range nodes[1].Server.Peers() {
+		sealed := false
+		for _, cap := range peer.Caps() {
+			sealed = sealed || cap.Name == "rlpx-aead"
+		}
+		if expected := peer.ID() == nodes[0].ID(); sealed != expected {
+			t.Fatalf("peer %v advertises the AEAD frames: %v, expected %v", peer.ID(), sealed, expected)
+		}
+	}
+
+	floods[0].publish(1)
+	waitReceived(t, floods, 1, 10*time.Second)
+	floods[3].publish(2)
+	waitReceived(t, floods, 2, 10*time.Second)
+}
+
+func TestNetwork_MixedCompression(t *testing.T) {
+	network := NewNetwork(LinkConfig{}, 1)
+	t.Cleanup(network.Shutdown)
+	// the node in the middle doesn't advertise the compression, like the nodes which predate it
+	compression := []bool{true, true, false, true}
+	nodes := make([]*Node, len(compression))
+	floods := make([]*flood, len(compression))
+	for i := range nodes {
+		floods[i] = newFlood()
+		floods[i].padding = bytes.Repeat([]byte("momentum"), 8*1024)
+		node, err := network.AddServer(&p2p.Server{
+			MaxPeers:      50,
+			Protocols:     []p2p.Protocol{floods[i].protocol()},
+			NoCompression: !compression[i],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	network.ConnectChain(nodes)
+	for i, node := range nodes {
+		expected := 2
+		if i == 0 || i == len(nodes)-1 {
+			expected = 1
+		}
+		if err := node.WaitPeers(expected, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, peer := range nodes[1].Server.Peers() {
+		if peer.Version() != 4 {
+			t.Fatalf("peer %v speaks base protocol version %v", peer.ID(), peer.Version())
+		}
+		compressed := false
+		for _, cap := range peer.Caps() {
+			compressed = compressed || cap.Name == "rlpx-snappy"
+		}
+		if expected := peer.ID() == nodes[0].ID(); compressed != expected {
+			t.Fatalf("peer %v advertises the compression: %v, expected %v", peer.ID(), compressed, expected)
+		}
+	}
+
+	// the message crosses the chain and each node relays it back, compressed only between the first two nodes
+	floods[0].publish(1)
+	waitReceived(t, floods, 1, 10*time.Second)
+	size := uint64(len(floods[0].padding))
+	deadline := time.Now().Add(10 * time.Second)
+	for _, peer := range nodes[1].Server.Peers() {
+		if peer.ID() == nodes[0].ID() {
+			continue
+		}
+		for peer.BytesRead() < size {
+			if time.Now().After(deadline) {
+				t.Fatalf("read %v bytes of an uncompressed %v bytes message", peer.BytesRead(), size)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	for _, peer := range nodes[1].Server.Peers() {
+		if peer.ID() == nodes[0].ID() && peer.BytesRead() >= size/2 {
+			t.Fatalf("read %v bytes of a compressed %v bytes message", peer.BytesRead(), size)
+		}
+	}
+	floods[3].publish(2)
+	waitReceived(t, floods, 2, 10*time.Second)
+}
+
+func TestNetwork_MixedMetadata(t *testing.T) {
+	network := NewNetwork(LinkConfig{}, 1)
+	t.Cleanup(network.Shutdown)
+	// the node in the middle doesn't advertise any metadata, like the nodes which predate it
+	metadata := []*p2p.PeerMetadata{
+		{Client: "znnd/v0.0.7", OS: "linux", Arch: "amd64", Archival: true},
+		nil,
+		{Client: "znnd/v0.0.7 " + strings.Repeat("x", 64), OS: "windows\n", Arch: "arm64"},
+	}
+	nodes := make([]*Node, len(metadata))
+	floods := make([]*flood, len(metadata))
+	for i := range nodes {
+		floods[i] = newFlood()
+		node, err := network.AddServer(&p2p.Server{
+			MaxPeers:  50,
+			Protocols: []p2p.Protocol{floods[i].protocol()},
+			Metadata:  metadata[i],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	network.ConnectChain(nodes)
+	for i, node := range nodes {
+		expected := 2
+		if i == 0 || i == len(nodes)-1 {
+			expected = 1
+		}
+		if err := node.WaitPeers(expected, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the values are sanitized and capped by the advertising node
+	expected := map[discover.NodeID]string{
+		nodes[0].ID(): `{"client":"znnd/v0.0.7","os":"linux","arch":"amd64","archival":true}`,
+		nodes[2].ID(): `{"client":"znnd/v0.0.7xxxxxxxxxxxxxxxxxxxxx","os":"windows","arch":"arm64","archival":false}`,
+	}
+	for _, peer := range nodes[1].Server.Peers() {
+		advertised := 0
+		for _, cap := range peer.Caps() {
+			if strings.HasPrefix(cap.Name, "meta-") {
+				advertised += 1
+			}
+		}
+		data, err := json.Marshal(peer.Metadata())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected[peer.ID()] || advertised < 3 {
+			t.Fatalf("peer %v advertises %v caps with the metadata %s, expected %v", peer.ID(), advertised, data, expected[peer.ID()])
+		}
+	}
+	for _, i := range []int{0, 2} {
+		for _, peer := range nodes[i].Server.Peers() {
+			if peer.Metadata() != nil {
+				t.Fatalf("node without metadata advertises %+v", peer.Metadata())
+			}
+		}
+	}
+
+	floods[0].publish(1)
+	waitReceived(t, floods, 1, 10*time.Second)
+	floods[2].publish(2)
+	waitReceived(t, floods, 2, 10*time.Second)
+}
+
+func TestNetwork_NetworkMismatch(t *testing.T) {
+	network := NewNetwork(LinkConfig{}, 1)
+	t.Cleanup(network.Shutdown)
+	// the node without a network id peers with both networks
+	networks := []string{"a", "a", "b", ""}
+	nodes := make([]*Node, len(networks))
+	for i := range nodes {
+		node, err := network.AddServer(&p2p.Server{
+			MaxPeers:  50,
+			Protocols: []p2p.Protocol{newFlood().protocol()},
+			NetworkID: networks[i],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	events := make(chan *p2p.PeerEvent, p2p.PeerEventChanSize)
+	unsubscribe := nodes[2].Server.SubscribeEvents(events)
+	defer unsubscribe()
+
+	network.ConnectFull(nodes)
+	for i, expected := range []int{2, 2, 1, 3} {
+		if err := nodes[i].WaitPeers(expected, 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == p2p.PeerEventTypeHandshakeFailed && event.Error == p2p.DiscNetworkMismatch.Error() {
+				for _, peer := range nodes[2].Server.Peers() {
+					if peer.ID() != nodes[3].ID() {
+						t.Fatalf("node of network b peers with %v", peer.ID())
+					}
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no handshake failed with a network mismatch")
+		}
+	}
+}
+
+func TestNetwork_SubnetLimit(t *testing.T) {
+	network := NewNetwork(LinkConfig{}, 1)
+	t.Cleanup(network.Shutdown)
+	center, err := network.AddServer(&p2p.Server{
+		MaxPeers:          50,
+		MaxPeersPerSubnet: 2,
+		Protocols:         []p2p.Protocol{newFlood().protocol()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan *p2p.PeerEvent, p2p.PeerEventChanSize)
+	unsubscribe := center.Server.SubscribeEvents(events)
+	defer unsubscribe()
+
+	// three nodes of the same /24 and one of another subnet dial the center
+	nodes := make([]*Node, 0)
+	for _, ip := range []string{"192.168.1.1", "192.168.1.2", "192.168.1.3", "192.168.2.1"} {
+		node, err := network.AddServerAt(net.ParseIP(ip), &p2p.Server{
+			MaxPeers:  50,
+			Protocols: []p2p.Protocol{newFlood().protocol()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes = append(nodes, node)
+	}
+	network.ConnectStar(center, nodes)
+	if err := center.WaitPeers(3, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == p2p.PeerEventTypeHandshakeFailed && event.Error == p2p.DiscSubnetLimit.Error() {
+				distribution := center.Server.PeerDistribution()
+				if len(distribution.Subnets) != 2 || distribution.Subnets[0].Subnet != "192.168.1.0/24" ||
+					distribution.Subnets[0].Peers != 2 || distribution.Subnets[0].Inbound != 2 || distribution.Subnets[1].Peers != 1 {
+					t.Fatalf("unexpected subnets %+v %+v", distribution.Subnets[0], distribution.Subnets[1])
+				}
+				if center.Server.PeerCount() != 3 {
+					t.Fatalf("center has %v peers", center.Server.PeerCount())
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no handshake failed with a subnet limit")
+		}
+	}
+}
+
+func TestNetwork_LoopState(t *testing.T) {
+	network, nodes, _ := startFloodNetwork(t, LinkConfig{}, 3)
+	network.ConnectStar(nodes[0], nodes[1:])
+	if err := nodes[0].WaitPeers(2, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	state := nodes[0].Server.LoopState()
+	if !state.Running || state.Stage != "running" {
+		t.Fatalf("expected a running loop, got %v %q", state.Running, state.Stage)
+	}
+	if len(state.Peers) != 2 {
+		t.Fatalf("expected 2 peers, got %v", len(state.Peers))
+	}
+	for _, peer := range state.Peers {
+		if _, ok := peer.Protocols["flood"]; !ok || peer.Closed {
+			t.Fatalf("expected an open peer running flood, got %+v", peer)
+		}
+	}
+
+	nodes[0].Server.Stop()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		state = nodes[0].Server.LoopState()
+		if state.Stage == "stopped" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("loop still %q after the stop", state.Stage)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if state.Running || len(state.Peers) != 0 {
+		t.Fatalf("expected a stopped loop without peers, got %v and %v peers", state.Running, len(state.Peers))
+	}
+}
+
+// Test the sentry mode, a producing node which only peers with its sentry node
+//   - test the inbound connections of other nodes are rejected by the producing node
+//   - test the messages of the producing node reach the other nodes through the sentry node
+//   - test the sentry node sees the producing node as a trusted peer
+func TestNetwork_PrivatePeering(t *testing.T) {
+	network := NewNetwork(LinkConfig{}, 1)
+	t.Cleanup(network.Shutdown)
+	floods := []*flood{newFlood(), newFlood(), newFlood()}
+	producerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the sentry node trusts the producing node, whichever side dials
+	sentry, err := network.AddServer(&p2p.Server{
+		MaxPeers:     50,
+		Protocols:    []p2p.Protocol{floods[0].protocol()},
+		TrustedNodes: []*discover.Node{{ID: discover.PubkeyID(&producerKey.PublicKey)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	producer, err := network.AddServer(&p2p.Server{
+		PrivateKey:     producerKey,
+		MaxPeers:       50,
+		Protocols:      []p2p.Protocol{floods[1].protocol()},
+		StaticNodes:    []*discover.Node{sentry.Node()},
+		TrustedNodes:   []*discover.Node{sentry.Node()},
+		PrivatePeering: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsider, err := network.AddServer(&p2p.Server{MaxPeers: 50, Protocols: []p2p.Protocol{floods[2].protocol()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan *p2p.PeerEvent, p2p.PeerEventChanSize)
+	unsubscribe := producer.Server.SubscribeEvents(events)
+	defer unsubscribe()
+
+	network.Connect(outsider, sentry)
+	network.Connect(outsider, producer)
+	if err := sentry.WaitPeers(2, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for rejected := false; !rejected; {
+		select {
+		case event := <-events:
+			rejected = event.Type == p2p.PeerEventTypeHandshakeFailed && event.Error == p2p.DiscUselessPeer.Error()
+		case <-timeout:
+			t.Fatal("the producing node didn't reject the outsider")
+		}
+	}
+	peers := producer.Server.Peers()
+	if len(peers) != 1 || peers[0].ID() != sentry.ID() {
+		t.Fatalf("the producing node peers with %v nodes", len(peers))
+	}
+	for _, peer := range sentry.Server.Peers() {
+		if peer.Trusted() != (peer.ID() == producer.ID()) {
+			t.Fatalf("peer %v trusted %v", peer.ID(), peer.Trusted())
+		}
+	}
+
+	floods[1].publish(1)
+	waitReceived(t, floods, 1, 10*time.Second)
+}
@@ -0,0 +1,209 @@
+package p2p
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 protocol constants, see RFC 1928 and RFC 1929.
+const (
+	socks5Version          = 5
+	socks5AuthNone         = 0
+	socks5AuthPassword     = 2
+	socks5AuthNoAcceptable = 0xff
+	socks5PasswordVersion  = 1
+	socks5Connect          = 1
+	socks5AddrIPv4         = 1
+	socks5AddrDomain       = 3
+	socks5AddrIPv6         = 4
+)
+
+var socks5Replies = map[byte]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// NodeDialer dials the outbound peer connections, see Server.Dialer.
+type NodeDialer interface {
+	Dial(network, addr string) (net.Conn, error)
+}
+
+// socks5Dialer tunnels the outbound connections through a SOCKS5 proxy, like Tor.
+type socks5Dialer struct {
+	proxyAddr string
+	username  string
+	password  string
+	forward   *net.Dialer
+}
+
+// NewSOCKS5Dialer returns a dialer which connects through the proxy at socks5://[user:password@]host:port.
+func NewSOCKS5Dialer(proxy string) (NodeDialer, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported proxy scheme %q, only socks5 is supported", u.Scheme)
+	}
+	if u.Host == "" || u.Port() == "" {
+		return nil, fmt.Errorf("proxy %q must have a host and a port", proxy)
+	}
+	d := &socks5Dialer{
+		proxyAddr: u.Host,
+		forward:   &net.Dialer{Timeout: defaultDialTimeout},
+	}
+	if u.User != nil {
+		d.username = u.User.Username()
+		d.password, _ = u.User.Password()
+		if len(d.username) > 255 || len(d.password) > 255 {
+			return nil, fmt.Errorf("proxy username and password must be at most 255 bytes")
+		}
+	}
+	return d, nil
+}
+
+func (d *socks5Dialer) Dial(network, addr string) (net.Conn, error) {
+	if network != "tcp" {
+		return nil, fmt.Errorf("SOCKS5 proxy can't dial %v", network)
+	}
+	conn, err := d.forward.Dial("tcp", d.proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	// the handshake with the proxy counts against the dial timeout
+	if err := conn.SetDeadline(time.Now().Add(d.forward.Timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := d.connect(conn, addr); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SOCKS5 proxy %v: %v", d.proxyAddr, err)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// connect negotiates the authentication method and asks the proxy to connect to addr.
+func (d *socks5Dialer) connect(conn net.Conn, addr string) error {
+	host, portString, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", portString)
+	}
+
+	methods := []byte{socks5AuthNone}
+	if d.username != "" {
+		methods = append(methods, socks5AuthPassword)
+	}
+	if _, err := conn.Write(append([]byte{socks5Version, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("unexpected protocol version %v", reply[0])
+	}
+	switch reply[1] {
+	case socks5AuthNone:
+	case socks5AuthPassword:
+		if d.username == "" {
+			return fmt.Errorf("proxy requires a username and a password")
+		}
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	case socks5AuthNoAcceptable:
+		return fmt.Errorf("no acceptable authentication method")
+	default:
+		return fmt.Errorf("unsupported authentication method %v", reply[1])
+	}
+
+	request := []byte{socks5Version, socks5Connect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("host name %q is too long", host)
+		}
+		request = append(request, socks5AddrDomain, byte(len(host)))
+		request = append(request, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		request = append(request, socks5AddrIPv4)
+		request = append(request, ip4...)
+	} else {
+		request = append(request, socks5AddrIPv6)
+		request = append(request, ip.To16()...)
+	}
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	// the reply ends with the address bound by the proxy, which isn't needed
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("unexpected protocol version %v", header[0])
+	}
+	if header[1] != 0 {
+		if reason, ok := socks5Replies[header[1]]; ok {
+			return fmt.Errorf("can't connect to %v: %v", addr, reason)
+		}
+		return fmt.Errorf("can't connect to %v: reply code %v", addr, header[1])
+	}
+	var bound int
+	switch header[3] {
+	case socks5AddrIPv4:
+		bound = net.IPv4len
+	case socks5AddrIPv6:
+		bound = net.IPv6len
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return err
+		}
+		bound = int(length[0])
+	default:
+		return fmt.Errorf("unsupported bound address type %v", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, bound+2))
+	return err
+}
+
+// authenticate sends the username and the password, see RFC 1929.
+func (d *socks5Dialer) authenticate(conn net.Conn) error {
+	request := []byte{socks5PasswordVersion, byte(len(d.username))}
+	request = append(request, d.username...)
+	request = append(request, byte(len(d.password)))
+	request = append(request, d.password...)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0 {
+		return fmt.Errorf("proxy rejected the username and the password")
+	}
+	return nil
+}
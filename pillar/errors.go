@@ -8,4 +8,6 @@ var (
 	ErrNotOurEvent        = errors.Errorf("not our event")
 	ErrEventHasNotStarted = errors.Errorf("current time is before start time")
 	ErrEventEnded         = errors.Errorf("current time is after the event's finish time time")
+	ErrNothingToConfirm   = errors.Errorf("there are no account-blocks to confirm")
+	ErrClockDrift         = errors.Errorf("the local clock drifts too much from NTP")
 )
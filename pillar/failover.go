@@ -0,0 +1,217 @@
+package pillar
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/protocol"
+)
+
+const (
+	// number of the momentums produced by the node remembered to tell them apart from the ones of other nodes
+	localMomentumsKept = 64
+
+	// DefaultLeaseTerm is the term of the lease renewed by the primary, a backup takes over at most this
+	// long after the primary stops renewing it.
+	DefaultLeaseTerm = 30 * time.Second
+	// leaseMargin is the time the lease must still be held for after the end of an event
+	leaseMargin = time.Second
+)
+
+// failover coordinates the nodes which share the producer address of a pillar, so only one of them produces.
+// A node produces only while it holds the lease, which is granted to one node at a time, see Lease.
+//
+// The primary renews the lease continuously, DefaultLeaseTerm at a time. A backup only acquires the lease
+// for the events it produces once the primary stopped renewing it, so the primary gets it back after them.
+// Without a lease the node always produces and warns when another node produces for the same address.
+type failover struct {
+	log         common.Logger
+	broadcaster protocol.Broadcaster
+
+	lock     sync.Mutex
+	producer types.Address
+	lease    Lease
+	backup   bool
+	// the lease is held until then, by the local clock
+	until time.Time
+	// timestamps of the momentums produced by the node, which are the start of their events
+	local []int64
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newFailover(broadcaster protocol.Broadcaster) *failover {
+	return &failover{
+		log:         common.PillarLogger.New("submodule", "failover"),
+		broadcaster: broadcaster,
+		closed:      make(chan struct{}),
+	}
+}
+
+func (f *failover) setProducer(producer types.Address) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.producer = producer
+}
+func (f *failover) setLease(lease Lease, backup bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.lease = lease
+	f.backup = lease != nil && backup
+	f.until = time.Time{}
+}
+
+// start renews the lease of the primary until stop is called.
+func (f *failover) start() {
+	f.lock.Lock()
+	renew := f.lease != nil && !f.backup
+	f.lock.Unlock()
+	if !renew {
+		return
+	}
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		defer common.RecoverStack()
+		ticker := time.NewTicker(DefaultLeaseTerm / 3)
+		defer ticker.Stop()
+		for {
+			if err := f.acquire(DefaultLeaseTerm); err != nil && err != ErrLeaseHeld {
+				f.log.Warn("failed to renew the producer lease", "reason", err)
+			}
+			select {
+			case <-f.closed:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stop releases the lease, so a backup can take over right away.
+func (f *failover) stop() {
+	close(f.closed)
+	f.wg.Wait()
+
+	f.lock.Lock()
+	lease, held := f.lease, f.until.After(time.Now())
+	f.until = time.Time{}
+	f.lock.Unlock()
+	if held {
+		if err := lease.Release(); err != nil {
+			f.log.Warn("failed to release the producer lease", "reason", err)
+		}
+	}
+}
+
+// acquire acquires or renews the lease for term.
+func (f *failover) acquire(term time.Duration) error {
+	f.lock.Lock()
+	lease := f.lease
+	f.lock.Unlock()
+
+	sent := time.Now()
+	held, err := lease.Acquire(term)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	active := f.until.After(time.Now())
+	if err != nil {
+		if err == ErrLeaseHeld && active {
+			f.log.Warn("another node holds the producer lease, standing by", "producer", f.producer)
+			f.until = time.Time{}
+		}
+		return err
+	}
+	// the lease is counted from the request, which is before the server granted it
+	f.until = sent.Add(held)
+	if !active {
+		f.log.Info("acquired the producer lease", "producer", f.producer, "backup", f.backup, "held", held)
+	}
+	return nil
+}
+
+func (f *failover) isBackup() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.backup
+}
+func (f *failover) isActive() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.lease == nil || f.until.After(time.Now())
+}
+
+// check is called for each event of the producer, it returns ErrLeaseHeld while another node holds the lease.
+// The lease must be held until after the end of the event.
+func (f *failover) check(e consensus.ProducerEvent) error {
+	f.lock.Lock()
+	lease, backup, until := f.lease, f.backup, f.until
+	f.lock.Unlock()
+	if lease == nil {
+		return nil
+	}
+	required := e.EndTime.Add(leaseMargin)
+	if until.After(required) {
+		return nil
+	}
+
+	term := DefaultLeaseTerm
+	if backup {
+		term = time.Until(required) + leaseMargin
+	}
+	if err := f.acquire(term); err != nil {
+		if err == ErrLeaseHeld {
+			return err
+		}
+		return errors.Errorf("failed to acquire the producer lease. Reason: %v", err)
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !f.until.After(required) {
+		return ErrLeaseHeld
+	}
+	return nil
+}
+
+// produced records the event as produced by the node, before its momentum is generated.
+func (f *failover) produced(e consensus.ProducerEvent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.local = append(f.local, e.StartTime.Unix())
+	if len(f.local) > localMomentumsKept {
+		f.local = f.local[len(f.local)-localMomentumsKept:]
+	}
+}
+
+func (f *failover) isLocal(timestamp int64) bool {
+	for _, local := range f.local {
+		if local == timestamp {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *failover) InsertMomentum(detailed *nom.DetailedMomentum) {
+	momentum := detailed.Momentum
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if momentum.Producer() != f.producer || f.isLocal(momentum.Timestamp.Unix()) {
+		return
+	}
+	// momentums inserted while syncing may have been produced by the node before it restarted, and the ones
+	// of a backup are expected while the node doesn't hold the lease
+	if f.broadcaster.SyncInfo().State == protocol.SyncDone && (f.lease == nil || f.until.After(time.Now())) {
+		f.log.Warn("another node produces momentums for the producer address", "producer", f.producer, "identifier", momentum.Identifier())
+	}
+}
+func (f *failover) DeleteMomentum(*nom.DetailedMomentum) {
+}
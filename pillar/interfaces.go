@@ -21,5 +21,22 @@ type Manager interface {
 	Process(e consensus.ProducerEvent) common.Task
 
 	SetCoinBase(coinbase *wallet.KeyPair)
+	// SetSigner sets the coinbase to the address of signer, which signs the momentums and the account-blocks
+	// of the pillar. SetCoinBase is SetSigner with a local signer.
+	SetSigner(signer Signer)
+	// SetSkipEmpty makes the producer skip its events while there are no account-blocks to confirm,
+	// so the momentums are produced on demand. Used by the developer mode.
+	SetSkipEmpty(skipEmpty bool)
+	// SetLease makes the node produce only while it holds lease, which is shared by the nodes with the same
+	// coinbase. A backup only acquires it once the primary stopped renewing it. Nil makes the node always produce.
+	SetLease(lease Lease, backup bool)
+	// SetRewardCollection makes the node collect the rewards of the coinbase once they reach
+	// the thresholds of config, nil disables it.
+	SetRewardCollection(config *RewardCollection)
+	// SetClockGuard makes the producer skip its events while guard returns an error, like ErrClockDrift
+	// when the local clock is too far off for the momentums to be accepted. Nil disables it.
+	SetClockGuard(guard func() error)
 	GetCoinBase() *types.Address
+	// GetStats reports the momentum production of the coinbase, ErrPillarNotDefined if there is none.
+	GetStats() (*Stats, error)
 }
@@ -0,0 +1,258 @@
+package pillar
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+)
+
+const (
+	// DefaultLeaseAddress is the address the lease command listens on.
+	DefaultLeaseAddress = "127.0.0.1:36000"
+	// MaxLeaseTerm is the longest term granted by a LeaseServer.
+	MaxLeaseTerm = 5 * time.Minute
+
+	leaseTimeout = time.Second
+
+	leaseMethodHello   = "hello"
+	leaseMethodAuth    = "auth"
+	leaseMethodAcquire = "acquire"
+	leaseMethodRelease = "release"
+)
+
+var (
+	ErrLeaseHeld = errors.New("the producer lease is held by another node")
+)
+
+// Lease is the right to produce the momentums of a pillar, held by at most one of the nodes sharing its
+// producer address at any time, see NewRemoteLease.
+type Lease interface {
+	// Acquire acquires or renews the lease for term and returns for how long it's held, counted from the call.
+	// It returns ErrLeaseHeld while another node holds it.
+	Acquire(term time.Duration) (time.Duration, error)
+	// Release gives up the lease, if it's held.
+	Release() error
+}
+
+// leaseRequest and leaseResponse are the messages of the lease protocol, one JSON object per line like the
+// remote signer protocol. A connection starts with a hello, answered with a nonce, and an auth request whose
+// Auth is the HMAC-SHA256 of the nonce with the shared secret.
+type leaseRequest struct {
+	Method string `json:"method"`
+	Auth   string `json:"auth,omitempty"`
+	Holder string `json:"holder,omitempty"`
+	// Term is in milliseconds
+	Term int64 `json:"term,omitempty"`
+}
+type leaseResponse struct {
+	Nonce  string `json:"nonce,omitempty"`
+	Holder string `json:"holder,omitempty"`
+	// Remaining is the time the lease is held for after the request, in milliseconds
+	Remaining int64  `json:"remaining,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type remoteLease struct {
+	address string
+	holder  string
+	secret  []byte
+}
+
+// NewRemoteLease acquires the lease from the LeaseServer at address as holder, which must be unique among the
+// nodes sharing the producer address. The server is either unix:<path> or host:port, see SplitSignerAddress.
+func NewRemoteLease(address, holder string, secret []byte) Lease {
+	return &remoteLease{
+		address: address,
+		holder:  holder,
+		secret:  secret,
+	}
+}
+
+func (l *remoteLease) Acquire(term time.Duration) (time.Duration, error) {
+	response, err := l.request(&leaseRequest{Method: leaseMethodAcquire, Holder: l.holder, Term: term.Milliseconds()})
+	if err != nil {
+		return 0, err
+	}
+	if response.Holder != l.holder {
+		return 0, ErrLeaseHeld
+	}
+	return time.Duration(response.Remaining) * time.Millisecond, nil
+}
+func (l *remoteLease) Release() error {
+	_, err := l.request(&leaseRequest{Method: leaseMethodRelease, Holder: l.holder})
+	return err
+}
+
+// request sends request over a new authenticated connection, the lease is renewed every few seconds at most.
+func (l *remoteLease) request(request *leaseRequest) (*leaseResponse, error) {
+	network, address := SplitSignerAddress(l.address)
+	conn, err := net.DialTimeout(network, address, leaseTimeout)
+	if err != nil {
+		return nil, errors.Errorf("failed to connect to the lease server. Reason: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(leaseTimeout)); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	encoder := json.NewEncoder(conn)
+	send := func(request *leaseRequest) (*leaseResponse, error) {
+		if err := encoder.Encode(request); err != nil {
+			return nil, errors.Errorf("failed to send the request to the lease server. Reason: %v", err)
+		}
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return nil, errors.Errorf("failed to read the response of the lease server. Reason: %v", err)
+		}
+		response := new(leaseResponse)
+		if err := json.Unmarshal(line, response); err != nil {
+			return nil, errors.Errorf("failed to decode the response of the lease server. Reason: %v", err)
+		}
+		if response.Error != "" {
+			return nil, errors.Errorf("lease server failed. Reason: %v", response.Error)
+		}
+		return response, nil
+	}
+
+	response, err := send(&leaseRequest{Method: leaseMethodHello})
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(response.Nonce)
+	if err != nil {
+		return nil, errors.Errorf("invalid nonce of the lease server")
+	}
+	if _, err := send(&leaseRequest{Method: leaseMethodAuth, Auth: hex.EncodeToString(secretAuth(l.secret, nonce))}); err != nil {
+		return nil, err
+	}
+	return send(request)
+}
+
+// LeaseServer grants the producer lease of a pillar to one of the nodes which know its secret at a time.
+// It should run on a host other than the ones of the nodes, so a node which can't reach the others can't
+// produce unless it holds the lease. The lease is only kept in memory, a restarted server waits MaxLeaseTerm
+// before granting it, so the lease granted before the restart expires first.
+type LeaseServer struct {
+	log    common.Logger
+	secret []byte
+
+	// protects holder and expires
+	lock    sync.Mutex
+	holder  string
+	expires time.Time
+}
+
+// NewLeaseServer grants the lease to the nodes authenticated with secret.
+func NewLeaseServer(secret []byte) (*LeaseServer, error) {
+	if len(secret) < MinSecretSize {
+		return nil, ErrSecretTooShort
+	}
+	return &LeaseServer{
+		log:     common.PillarLogger.New("submodule", "lease-server"),
+		secret:  secret,
+		expires: time.Now().Add(MaxLeaseTerm),
+	}, nil
+}
+
+// Serve answers the requests of the nodes connected to listener, until the listener is closed.
+func (s *LeaseServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer common.RecoverStack()
+			defer conn.Close()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+func (s *LeaseServer) serveConn(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(leaseTimeout)); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(conn)
+	encoder := json.NewEncoder(conn)
+	var nonce []byte
+	authenticated := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return err
+		}
+		request := new(leaseRequest)
+		response := new(leaseResponse)
+		if err := json.Unmarshal(line, request); err != nil {
+			return err
+		}
+
+		switch {
+		case request.Method == leaseMethodHello:
+			nonce = make([]byte, attestationChallengeSize)
+			if _, err := rand.Read(nonce); err != nil {
+				return err
+			}
+			response.Nonce = hex.EncodeToString(nonce)
+		case request.Method == leaseMethodAuth:
+			auth, err := hex.DecodeString(request.Auth)
+			if err != nil || nonce == nil || !hmac.Equal(auth, secretAuth(s.secret, nonce)) {
+				_ = encoder.Encode(&leaseResponse{Error: "authentication failed"})
+				return errors.New("authentication failed")
+			}
+			authenticated = true
+		case !authenticated:
+			response.Error = "not authenticated"
+		case request.Holder == "":
+			response.Error = "missing holder"
+		case request.Method == leaseMethodAcquire:
+			response.Holder, response.Remaining = s.acquire(request.Holder, time.Duration(request.Term)*time.Millisecond)
+		case request.Method == leaseMethodRelease:
+			s.release(request.Holder)
+		default:
+			response.Error = "unknown method"
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return err
+		}
+	}
+}
+
+// acquire grants or renews the lease for holder unless another one holds it, it returns the holder of the
+// lease and, in milliseconds, for how long it's held.
+func (s *LeaseServer) acquire(holder string, term time.Duration) (string, int64) {
+	if term > MaxLeaseTerm {
+		term = MaxLeaseTerm
+	}
+	now := time.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.holder != holder && now.Before(s.expires) {
+		return s.holder, s.expires.Sub(now).Milliseconds()
+	}
+	if s.holder != holder {
+		s.log.Info("granted the lease", "holder", holder, "previous", s.holder)
+	}
+	s.holder = holder
+	s.expires = now.Add(term)
+	return holder, term.Milliseconds()
+}
+func (s *LeaseServer) release(holder string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.holder == holder {
+		s.log.Info("released the lease", "holder", holder)
+		s.expires = time.Now()
+	}
+}
@@ -0,0 +1,144 @@
+package pillar
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/consensus"
+)
+
+// startLeaseServer serves a lease server on a loopback port until the test ends, returning the address to dial.
+// The server grants the lease right away, unlike a restarted one.
+func startLeaseServer(t *testing.T) string {
+	server, err := NewLeaseServer(testSignerSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.expires = time.Now()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Serve(listener)
+	return listener.Addr().String()
+}
+
+// Test the remote lease
+//   - test the lease is granted to one holder at a time and renewed by it
+//   - test the lease is granted to another holder once released or expired
+//   - test the term is capped by MaxLeaseTerm
+func TestRemoteLease_Acquire(t *testing.T) {
+	address := startLeaseServer(t)
+	first := NewRemoteLease(address, "first", testSignerSecret)
+	second := NewRemoteLease(address, "second", testSignerSecret)
+
+	if held, err := first.Acquire(time.Minute); err != nil || held != time.Minute {
+		t.Fatalf("lease not granted, held %v: %v", held, err)
+	}
+	if _, err := second.Acquire(time.Minute); err != ErrLeaseHeld {
+		t.Fatalf("expected %v, got %v", ErrLeaseHeld, err)
+	}
+	if held, err := first.Acquire(time.Hour); err != nil || held != MaxLeaseTerm {
+		t.Fatalf("lease not renewed for %v, held %v: %v", MaxLeaseTerm, held, err)
+	}
+
+	if err := second.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Acquire(time.Minute); err != ErrLeaseHeld {
+		t.Fatalf("lease released by another holder, got %v", err)
+	}
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Acquire(100 * time.Millisecond); err != nil {
+		t.Fatalf("released lease not granted: %v", err)
+	}
+	if _, err := first.Acquire(time.Minute); err != ErrLeaseHeld {
+		t.Fatalf("expected %v, got %v", ErrLeaseHeld, err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, err := first.Acquire(time.Minute); err != nil {
+		t.Fatalf("expired lease not granted: %v", err)
+	}
+}
+
+// Test the authentication of the lease server
+//   - test a node with another secret is refused
+//   - test a restarted server doesn't grant the lease before MaxLeaseTerm
+//   - test a short secret is refused
+func TestLeaseServer_Authentication(t *testing.T) {
+	address := startLeaseServer(t)
+	lease := NewRemoteLease(address, "node", []byte("another secret of the nodes"))
+	if _, err := lease.Acquire(time.Minute); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("node with another secret accepted: %v", err)
+	}
+
+	server, err := NewLeaseServer(testSignerSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder, remaining := server.acquire("node", time.Minute); holder == "node" || remaining <= 0 {
+		t.Fatalf("restarted server granted the lease")
+	}
+
+	if _, err := NewLeaseServer([]byte("short")); err != ErrSecretTooShort {
+		t.Fatalf("expected %v, got %v", ErrSecretTooShort, err)
+	}
+}
+
+func testLeaseEvent(duration time.Duration) consensus.ProducerEvent {
+	now := time.Now()
+	return consensus.ProducerEvent{StartTime: now, EndTime: now.Add(duration)}
+}
+
+// Test the failover between a primary and a backup
+//   - test the node always produces without a lease
+//   - test the backup stands by while the primary renews the lease
+//   - test the backup takes over once the primary stops, only for its events
+//   - test the primary takes the lease back once the events of the backup end
+func TestFailover_Lease(t *testing.T) {
+	broadcaster := new(testBroadcaster)
+	alone := newFailover(broadcaster)
+	if err := alone.check(testLeaseEvent(time.Second)); err != nil || !alone.isActive() {
+		t.Fatalf("node without a lease doesn't produce: %v", err)
+	}
+
+	address := startLeaseServer(t)
+	primary := newFailover(broadcaster)
+	primary.setLease(NewRemoteLease(address, "primary", testSignerSecret), false)
+	backup := newFailover(broadcaster)
+	backup.setLease(NewRemoteLease(address, "backup", testSignerSecret), true)
+
+	primary.start()
+	if err := primary.check(testLeaseEvent(time.Second)); err != nil || !primary.isActive() {
+		t.Fatalf("primary doesn't produce: %v", err)
+	}
+	if err := backup.check(testLeaseEvent(time.Second)); err != ErrLeaseHeld || backup.isActive() {
+		t.Fatalf("backup produces while the primary holds the lease: %v", err)
+	}
+
+	primary.stop()
+	if err := backup.check(testLeaseEvent(100 * time.Millisecond)); err != nil || !backup.isActive() {
+		t.Fatalf("backup doesn't take over: %v", err)
+	}
+	restarted := newFailover(broadcaster)
+	restarted.setLease(NewRemoteLease(address, "primary", testSignerSecret), false)
+	if err := restarted.check(testLeaseEvent(time.Second)); err != ErrLeaseHeld {
+		t.Fatalf("primary produces while the backup holds the lease: %v", err)
+	}
+	if backup.until.After(time.Now().Add(3 * leaseMargin)) {
+		t.Fatalf("backup holds the lease after its event, until %v", backup.until)
+	}
+
+	time.Sleep(time.Until(backup.until) + 100*time.Millisecond)
+	if err := restarted.check(testLeaseEvent(time.Second)); err != nil {
+		t.Fatalf("primary doesn't take the lease back: %v", err)
+	}
+	if err := backup.check(testLeaseEvent(time.Second)); err != ErrLeaseHeld {
+		t.Fatalf("backup produces while the primary holds the lease: %v", err)
+	}
+}
@@ -16,11 +16,17 @@ import (
 )
 
 type manager struct {
-	log      log15.Logger
-	coinbase *wallet.KeyPair
+	log        log15.Logger
+	coinbase   Signer
+	skipEmpty  bool
+	clockGuard func() error
 
-	worker *worker
+	worker   *worker
+	tracker  *productionTracker
+	rewards  *rewardCollector
+	failover *failover
 
+	chain       chain.Chain
 	consensus   consensus.Consensus
 	broadcaster protocol.Broadcaster
 }
@@ -28,9 +34,13 @@ type manager struct {
 func NewPillar(chain chain.Chain, consensus consensus.Consensus, broadcaster protocol.Broadcaster) Manager {
 	supervisor := vm.NewSupervisor(chain, consensus)
 	return &manager{
+		chain:       chain,
 		consensus:   consensus,
 		broadcaster: broadcaster,
 		worker:      newWorker(chain, supervisor, broadcaster),
+		tracker:     newProductionTracker(chain, broadcaster),
+		rewards:     newRewardCollector(chain, supervisor, broadcaster),
+		failover:    newFailover(broadcaster),
 		log:         common.PillarLogger.New("submodule", "manager"),
 	}
 }
@@ -50,9 +60,15 @@ func (m *manager) Start() error {
 	defer m.log.Info("started")
 
 	m.consensus.Register(m)
+	m.chain.Register(m.tracker)
+	m.chain.Register(m.failover)
+	m.failover.start()
 	if err := m.worker.Start(); err != nil {
 		m.log.Error("failed to produce contracts", "reason", err)
 	}
+	if err := m.rewards.Start(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -61,6 +77,12 @@ func (m *manager) Stop() error {
 	defer m.log.Info("stopped")
 
 	m.consensus.UnRegister(m)
+	m.chain.UnRegister(m.tracker)
+	m.chain.UnRegister(m.failover)
+	m.failover.stop()
+	if err := m.rewards.Stop(); err != nil {
+		return err
+	}
 	if err := m.worker.Stop(); err != nil {
 		return err
 	}
@@ -80,15 +102,26 @@ func (m *manager) shouldProcess(e consensus.ProducerEvent) error {
 	if m.coinbase == nil {
 		return ErrPillarNotDefined
 	}
-	if m.coinbase.Address != e.Producer {
+	if m.coinbase.Address() != e.Producer {
 		return ErrNotOurEvent
 	}
+	if err := m.failover.check(e); err != nil {
+		return err
+	}
+	if m.clockGuard != nil {
+		if err := m.clockGuard(); err != nil {
+			return err
+		}
+	}
 	if common.Clock.Now().Before(e.StartTime) {
 		return ErrEventHasNotStarted
 	}
 	if common.Clock.Now().After(e.EndTime) {
 		return ErrEventEnded
 	}
+	if m.skipEmpty && len(m.chain.GetNewMomentumContent()) == 0 {
+		return ErrNothingToConfirm
+	}
 	return nil
 }
 func (m *manager) processSupervised(e consensus.ProducerEvent) {
@@ -101,6 +134,7 @@ func (m *manager) processSupervised(e consensus.ProducerEvent) {
 	m.log.Info("momentum producer triggered", "event", e)
 	defer m.log.Info("momentum producer trigger finished", "event", e)
 
+	m.failover.produced(e)
 	endTime := e.EndTime.Add(time.Millisecond * -250)
 	task := m.worker.Process(e)
 	for {
@@ -126,16 +160,60 @@ func (m *manager) Process(e consensus.ProducerEvent) common.Task {
 	//	m.log.Error("do not process current event", "event", e, "reason", err)
 	//	return nil
 	//}
+	m.failover.produced(e)
 	return m.worker.Process(e)
 }
 
 func (m *manager) SetCoinBase(coinbase *wallet.KeyPair) {
-	m.coinbase = coinbase
-	m.worker.coinbase = coinbase
+	if coinbase == nil {
+		m.SetSigner(nil)
+		return
+	}
+	m.SetSigner(NewLocalSigner(coinbase))
+}
+func (m *manager) SetSigner(signer Signer) {
+	m.coinbase = signer
+	m.worker.coinbase = signer
+	m.rewards.setCoinBase(signer)
+	if signer != nil {
+		m.tracker.setProducer(signer.Address())
+		m.failover.setProducer(signer.Address())
+	}
+}
+func (m *manager) SetSkipEmpty(skipEmpty bool) {
+	m.skipEmpty = skipEmpty
+}
+func (m *manager) SetClockGuard(guard func() error) {
+	m.clockGuard = guard
+}
+func (m *manager) SetLease(lease Lease, backup bool) {
+	if lease != nil {
+		m.log.Info("producer lease enabled", "backup", backup)
+	}
+	m.failover.setLease(lease, backup)
+}
+func (m *manager) SetRewardCollection(config *RewardCollection) {
+	if config != nil {
+		m.log.Info("automatic reward collection enabled", "znn-threshold", config.ZnnThreshold, "qsr-threshold", config.QsrThreshold, "interval", config.Interval)
+	}
+	m.rewards.setConfig(config)
 }
 func (m *manager) GetCoinBase() *types.Address {
 	if m.coinbase == nil {
 		return nil
 	}
-	return &m.coinbase.Address
+	address := m.coinbase.Address()
+	return &address
+}
+func (m *manager) GetStats() (*Stats, error) {
+	if m.coinbase == nil {
+		return nil, ErrPillarNotDefined
+	}
+	stats, err := getStats(m.chain, m.consensus, m.tracker, m.coinbase.Address())
+	if err != nil {
+		return nil, err
+	}
+	stats.Backup = m.failover.isBackup()
+	stats.Producing = m.failover.isActive()
+	return stats, nil
 }
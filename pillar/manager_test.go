@@ -0,0 +1,51 @@
+package pillar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/protocol"
+)
+
+// testBroadcaster is a synced node which drops the produced blocks.
+type testBroadcaster struct{}
+
+func (b *testBroadcaster) SyncInfo() *protocol.SyncInfo {
+	return &protocol.SyncInfo{State: protocol.SyncDone}
+}
+func (b *testBroadcaster) CreateMomentum(*nom.MomentumTransaction)         {}
+func (b *testBroadcaster) CreateAccountBlock(*nom.AccountBlockTransaction) {}
+
+// Test SetClockGuard
+//   - test the events of the pillar are skipped while the guard returns an error
+//   - test they are processed again once the guard passes
+func TestManager_ClockGuard(t *testing.T) {
+	broadcaster := new(testBroadcaster)
+	m := &manager{
+		log:         common.PillarLogger.New("submodule", "manager"),
+		broadcaster: broadcaster,
+		failover:    newFailover(broadcaster),
+	}
+	m.coinbase = NewLocalSigner(testSignerKeyPair(t, 0))
+	event := consensus.ProducerEvent{
+		StartTime: common.Clock.Now().Add(-time.Second),
+		EndTime:   common.Clock.Now().Add(time.Minute),
+		Producer:  m.coinbase.Address(),
+	}
+
+	if err := m.shouldProcess(event); err != nil {
+		t.Fatalf("event skipped without a guard: %v", err)
+	}
+	var guard error = ErrClockDrift
+	m.SetClockGuard(func() error { return guard })
+	if err := m.shouldProcess(event); err != ErrClockDrift {
+		t.Fatalf("expected %v, got %v", ErrClockDrift, err)
+	}
+	guard = nil
+	if err := m.shouldProcess(event); err != nil {
+		t.Fatalf("event skipped once the guard passes: %v", err)
+	}
+}
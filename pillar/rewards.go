@@ -0,0 +1,195 @@
+package pillar
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+const (
+	// Momentums after which a reward is collected again if the previous CollectReward wasn't received.
+	recollectDelay = 30
+)
+
+var (
+	// rewardContracts are the embedded contracts which pay rewards through CollectReward
+	rewardContracts = []types.Address{
+		types.PillarContract,
+		types.SentinelContract,
+		types.StakeContract,
+		types.LiquidityContract,
+	}
+)
+
+// RewardCollection configures the automatic collection of the rewards of the coinbase.
+// The uncollected rewards of each contract are checked every Interval and collected once
+// the ZNN reaches ZnnThreshold or the QSR reaches QsrThreshold. A zero threshold ignores the token.
+type RewardCollection struct {
+	ZnnThreshold *big.Int
+	QsrThreshold *big.Int
+	Interval     time.Duration
+}
+
+// reached reports whether the deposit is worth collecting.
+func (c *RewardCollection) reached(deposit *definition.RewardDeposit) bool {
+	if c.ZnnThreshold != nil && c.ZnnThreshold.Sign() > 0 && deposit.Znn.Cmp(c.ZnnThreshold) >= 0 {
+		return true
+	}
+	if c.QsrThreshold != nil && c.QsrThreshold.Sign() > 0 && deposit.Qsr.Cmp(c.QsrThreshold) >= 0 {
+		return true
+	}
+	return false
+}
+
+// rewardCollector periodically submits CollectReward transactions for the coinbase.
+type rewardCollector struct {
+	log common.Logger
+
+	chain       chain.Chain
+	supervisor  *vm.Supervisor
+	broadcaster protocol.Broadcaster
+
+	// protects coinbase and config
+	lock     sync.Mutex
+	coinbase Signer
+	config   *RewardCollection
+
+	// contracts with a submitted CollectReward, by the height until which they aren't collected again
+	pending map[types.Address]uint64
+
+	changes chan struct{}
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newRewardCollector(chain chain.Chain, supervisor *vm.Supervisor, broadcaster protocol.Broadcaster) *rewardCollector {
+	return &rewardCollector{
+		log:         common.PillarLogger.New("submodule", "rewards"),
+		chain:       chain,
+		supervisor:  supervisor,
+		broadcaster: broadcaster,
+		pending:     make(map[types.Address]uint64),
+		changes:     make(chan struct{}, 1),
+		closed:      make(chan struct{}),
+	}
+}
+
+func (r *rewardCollector) Start() error {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.loop()
+	}()
+	return nil
+}
+func (r *rewardCollector) Stop() error {
+	close(r.closed)
+	r.wg.Wait()
+	return nil
+}
+
+func (r *rewardCollector) setCoinBase(coinbase Signer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.coinbase = coinbase
+}
+func (r *rewardCollector) setConfig(config *RewardCollection) {
+	r.lock.Lock()
+	r.config = config
+	r.lock.Unlock()
+
+	// restart the schedule with the new interval
+	select {
+	case r.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (r *rewardCollector) loop() {
+	defer common.RecoverStack()
+	for {
+		r.lock.Lock()
+		config := r.config
+		r.lock.Unlock()
+
+		// collection is disabled until a config is set
+		var tick <-chan time.Time
+		if config != nil && config.Interval > 0 {
+			tick = time.After(config.Interval)
+		}
+
+		select {
+		case <-r.closed:
+			return
+		case <-r.changes:
+		case <-tick:
+			r.process()
+		}
+	}
+}
+
+func (r *rewardCollector) process() {
+	r.lock.Lock()
+	coinbase, config := r.coinbase, r.config
+	r.lock.Unlock()
+	if coinbase == nil || config == nil {
+		return
+	}
+	if r.broadcaster.SyncInfo().State != protocol.SyncDone {
+		r.log.Info("skip reward collection", "reason", ErrSyncNotDone)
+		return
+	}
+
+	momentumStore := r.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		r.log.Error("failed to get frontier momentum", "reason", err)
+		return
+	}
+	address := coinbase.Address()
+	for contract, height := range r.pending {
+		if height < frontier.Height {
+			delete(r.pending, contract)
+		}
+	}
+
+	for _, contract := range rewardContracts {
+		if r.pending[contract] != 0 {
+			continue
+		}
+		context := vm_context.NewAccountContext(momentumStore, r.chain.GetFrontierAccountStore(contract), nil)
+		deposit, err := definition.GetRewardDeposit(context.Storage(), &address)
+		if err != nil {
+			r.log.Error("failed to get uncollected reward", "contract", contract, "reason", err)
+			continue
+		}
+		if !config.reached(deposit) {
+			r.log.Debug("uncollected reward below threshold", "contract", contract, "znn", deposit.Znn, "qsr", deposit.Qsr)
+			continue
+		}
+
+		template := &nom.AccountBlock{
+			BlockType: nom.BlockTypeUserSend,
+			Address:   address,
+			ToAddress: contract,
+			Data:      definition.ABICommon.PackMethodPanic(definition.CollectRewardMethodName),
+		}
+		transaction, err := r.supervisor.GenerateFromTemplate(template, coinbase.SignAccountBlock(template))
+		if err != nil {
+			r.log.Error("failed to collect reward", "contract", contract, "reason", err)
+			continue
+		}
+		r.broadcaster.CreateAccountBlock(transaction)
+		r.pending[contract] = frontier.Height + recollectDelay
+		r.log.Info("collected reward", "contract", contract, "znn", deposit.Znn, "qsr", deposit.Qsr, "hash", transaction.Block.Hash)
+	}
+}
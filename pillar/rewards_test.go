@@ -0,0 +1,54 @@
+package pillar
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+func TestRewardCollection_Reached(t *testing.T) {
+	for _, test := range []struct {
+		config   RewardCollection
+		znn, qsr int64
+		reached  bool
+	}{
+		{RewardCollection{}, 100, 100, false},
+		{RewardCollection{ZnnThreshold: big.NewInt(10)}, 9, 100, false},
+		{RewardCollection{ZnnThreshold: big.NewInt(10)}, 10, 0, true},
+		{RewardCollection{QsrThreshold: big.NewInt(10)}, 100, 9, false},
+		{RewardCollection{QsrThreshold: big.NewInt(10)}, 0, 10, true},
+		{RewardCollection{ZnnThreshold: big.NewInt(10), QsrThreshold: big.NewInt(10)}, 9, 10, true},
+		{RewardCollection{ZnnThreshold: big.NewInt(0), QsrThreshold: big.NewInt(10)}, 100, 9, false},
+	} {
+		deposit := &definition.RewardDeposit{Znn: big.NewInt(test.znn), Qsr: big.NewInt(test.qsr)}
+		if reached := test.config.reached(deposit); reached != test.reached {
+			t.Errorf("%+v reached %v for %v ZNN and %v QSR, expected %v", test.config, reached, test.znn, test.qsr, test.reached)
+		}
+	}
+}
+
+// Test the collector can be stopped without being started, and after a config is set
+func TestRewardCollector_Stop(t *testing.T) {
+	r := newRewardCollector(nil, nil, nil)
+	if err := r.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	r = newRewardCollector(nil, nil, nil)
+	if err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	r.setConfig(&RewardCollection{ZnnThreshold: big.NewInt(1), Interval: time.Hour})
+	stopped := make(chan error)
+	go func() { stopped <- r.Stop() }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("collector didn't stop")
+	}
+}
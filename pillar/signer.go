@@ -0,0 +1,486 @@
+package pillar
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const (
+	// DefaultSignerAddress is the address the signer command listens on, it's only reachable from the same host.
+	DefaultSignerAddress = "127.0.0.1:35999"
+
+	// remoteSignerTimeout bounds each request to the remote signer, a momentum is broadcast only
+	// if it's produced within 3 seconds of the start of its event.
+	remoteSignerTimeout = time.Second
+	// attestationChallengeSize is the number of random bytes the remote signer must sign to prove it holds the key,
+	// it's also the size of the nonce the node authenticates with.
+	attestationChallengeSize = 32
+	// MinSecretSize is the minimum size of the secrets shared with the remote signer and the lease server.
+	MinSecretSize = 16
+
+	signerMethodHello        = "hello"
+	signerMethodAttest       = "attest"
+	signerMethodMomentum     = "signMomentum"
+	signerMethodAccountBlock = "signAccountBlock"
+)
+
+var (
+	// attestationPrefix separates the attestation challenges from the hashes of momentums and account-blocks,
+	// so a signed challenge can't be replayed as a block signature.
+	attestationPrefix = []byte("zenon-remote-signer-attestation:")
+
+	ErrSignerAttestation = errors.New("remote signer failed the attestation")
+	ErrSignerSignature   = errors.New("remote signer returned an invalid signature")
+	ErrSignerData        = errors.New("signed data doesn't match the hash of the block")
+	ErrSecretTooShort    = errors.Errorf("the secret must have at least %v bytes", MinSecretSize)
+)
+
+// Signer signs the momentums and the account-blocks produced for the coinbase.
+// The key is either held by the node, see NewLocalSigner, or by a separate process, see NewRemoteSigner,
+// so the host which produces the momentums never holds the pillar private key.
+type Signer interface {
+	// Address is the producer address, the coinbase of the pillar.
+	Address() types.Address
+	// SignMomentum and SignAccountBlock return the vm.SignFunc which signs the hash of momentum, or of block,
+	// once the supervisor set the rest of its fields. The remote signer checks the block itself, not only its hash.
+	SignMomentum(momentum *nom.Momentum) vm.SignFunc
+	SignAccountBlock(block *nom.AccountBlock) vm.SignFunc
+}
+
+type localSigner struct {
+	keyPair *wallet.KeyPair
+}
+
+// NewLocalSigner signs with a key pair unlocked by the node.
+func NewLocalSigner(keyPair *wallet.KeyPair) Signer {
+	return &localSigner{keyPair: keyPair}
+}
+
+func (s *localSigner) Address() types.Address {
+	return s.keyPair.Address
+}
+func (s *localSigner) SignMomentum(*nom.Momentum) vm.SignFunc {
+	return s.keyPair.Signer
+}
+func (s *localSigner) SignAccountBlock(*nom.AccountBlock) vm.SignFunc {
+	return s.keyPair.Signer
+}
+
+// signerRequest and signerResponse are the messages of the remote signer protocol: one JSON object per line,
+// each request is answered before the next one is sent. Binary fields are hex encoded.
+//
+// A connection starts with a hello, answered with a nonce, and an attestation whose Auth is the HMAC-SHA256
+// of the nonce with the shared secret. The signer only signs for authenticated connections, and only momentums
+// and account-blocks, serialized in Data, whose hash it computes itself.
+type signerRequest struct {
+	Method    string `json:"method"`
+	Challenge string `json:"challenge,omitempty"`
+	Auth      string `json:"auth,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+type signerResponse struct {
+	Nonce     string `json:"nonce,omitempty"`
+	PublicKey string `json:"publicKey,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SplitSignerAddress returns the network and the address to dial or listen on for a remote signer address,
+// which is either unix:<path> for a local socket or host:port for TCP.
+func SplitSignerAddress(address string) (string, string) {
+	if path := strings.TrimPrefix(address, "unix:"); path != address {
+		return "unix", path
+	}
+	return "tcp", address
+}
+
+// ReadSecretFile reads the secret shared with a remote signer or a lease server from the first line of file.
+func ReadSecretFile(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	secret := []byte(strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0]))
+	if len(secret) < MinSecretSize {
+		return nil, ErrSecretTooShort
+	}
+	return secret, nil
+}
+
+// secretAuth proves the knowledge of secret for the nonce of a connection.
+func secretAuth(secret, nonce []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(nonce)
+	return mac.Sum(nil)
+}
+
+type remoteSigner struct {
+	log      common.Logger
+	address  string
+	coinbase types.Address
+	secret   []byte
+
+	// protects conn, connections are opened on demand and dropped on the first error
+	lock      sync.Mutex
+	conn      net.Conn
+	reader    *bufio.Reader
+	publicKey ed25519.PublicKey
+}
+
+// NewRemoteSigner signs through the signer process listening at address, see SignerServer. Each connection starts
+// with an authentication with secret and an attestation: the signer must sign a random challenge with the key of
+// coinbase. Every signature is also verified against the attested key before it's used.
+func NewRemoteSigner(address string, coinbase types.Address, secret []byte) Signer {
+	return &remoteSigner{
+		log:      common.PillarLogger.New("submodule", "remote-signer"),
+		address:  address,
+		coinbase: coinbase,
+		secret:   secret,
+	}
+}
+
+func (s *remoteSigner) Address() types.Address {
+	return s.coinbase
+}
+func (s *remoteSigner) SignMomentum(momentum *nom.Momentum) vm.SignFunc {
+	return func(data []byte) ([]byte, *types.Address, []byte, error) {
+		if momentum.ComputeHash() != types.BytesToHashPanic(data) {
+			return nil, nil, nil, ErrSignerData
+		}
+		serialized, err := momentum.Serialize()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return s.sign(signerMethodMomentum, serialized, data)
+	}
+}
+func (s *remoteSigner) SignAccountBlock(block *nom.AccountBlock) vm.SignFunc {
+	return func(data []byte) ([]byte, *types.Address, []byte, error) {
+		if block.ComputeHash() != types.BytesToHashPanic(data) {
+			return nil, nil, nil, ErrSignerData
+		}
+		serialized, err := block.Serialize()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return s.sign(signerMethodAccountBlock, serialized, data)
+	}
+}
+
+// sign has the signer sign the serialized block whose hash is data.
+func (s *remoteSigner) sign(method string, serialized []byte, data []byte) ([]byte, *types.Address, []byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	response, err := s.request(&signerRequest{Method: method, Data: hex.EncodeToString(serialized)})
+	if err != nil {
+		s.disconnect()
+		return nil, nil, nil, err
+	}
+	signature, err := hex.DecodeString(response.Signature)
+	if err != nil || !ed25519.Verify(s.publicKey, data, signature) {
+		s.disconnect()
+		return nil, nil, nil, ErrSignerSignature
+	}
+	address := s.coinbase
+	return signature, &address, s.publicKey, nil
+}
+
+// connect dials the signer, authenticates and checks that the signer holds the key of the coinbase.
+func (s *remoteSigner) connect() error {
+	network, address := SplitSignerAddress(s.address)
+	conn, err := net.DialTimeout(network, address, remoteSignerTimeout)
+	if err != nil {
+		return errors.Errorf("failed to connect to the remote signer. Reason: %v", err)
+	}
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+
+	response, err := s.request(&signerRequest{Method: signerMethodHello})
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+	nonce, err := hex.DecodeString(response.Nonce)
+	if err != nil || len(nonce) != attestationChallengeSize {
+		s.disconnect()
+		return ErrSignerAttestation
+	}
+	challenge := make([]byte, attestationChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		s.disconnect()
+		return err
+	}
+	response, err = s.request(&signerRequest{
+		Method:    signerMethodAttest,
+		Challenge: hex.EncodeToString(challenge),
+		Auth:      hex.EncodeToString(secretAuth(s.secret, nonce)),
+	})
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+	publicKey, err := hex.DecodeString(response.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize || types.PubKeyToAddress(publicKey) != s.coinbase {
+		s.disconnect()
+		return ErrSignerAttestation
+	}
+	signature, err := hex.DecodeString(response.Signature)
+	if err != nil || !ed25519.Verify(publicKey, append(append([]byte{}, attestationPrefix...), challenge...), signature) {
+		s.disconnect()
+		return ErrSignerAttestation
+	}
+	s.publicKey = publicKey
+	s.log.Info("connected to remote signer", "address", s.address, "coinbase", s.coinbase)
+	return nil
+}
+func (s *remoteSigner) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = nil
+	s.reader = nil
+	s.publicKey = nil
+}
+func (s *remoteSigner) request(request *signerRequest) (*signerResponse, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(remoteSignerTimeout)); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.conn.Write(append(data, '\n')); err != nil {
+		return nil, errors.Errorf("failed to send the request to the remote signer. Reason: %v", err)
+	}
+	line, err := s.reader.ReadBytes('\n')
+	if err != nil {
+		return nil, errors.Errorf("failed to read the response of the remote signer. Reason: %v", err)
+	}
+	response := new(signerResponse)
+	if err := json.Unmarshal(line, response); err != nil {
+		return nil, errors.Errorf("failed to decode the response of the remote signer. Reason: %v", err)
+	}
+	if response.Error != "" {
+		return nil, errors.Errorf("remote signer failed. Reason: %v", response.Error)
+	}
+	return response, nil
+}
+
+// signerState is the last momentum signed by a SignerServer.
+type signerState struct {
+	Height    uint64     `json:"height"`
+	Timestamp uint64     `json:"timestamp"`
+	Hash      types.Hash `json:"hash"`
+}
+
+// SignerServer is the signer process of NewRemoteSigner, it signs with the key of the pillar for the nodes
+// which know its secret. It never signs two momentums at the same height or in the same slot, nor a momentum
+// below the last one it signed, so two nodes using it can't produce conflicting momentums. The last signed
+// momentum is kept in a state file, so it's still known after a restart.
+// Account-blocks are only signed for the pillar address, the same block is signed again once its changes are known.
+type SignerServer struct {
+	log       common.Logger
+	keyPair   *wallet.KeyPair
+	secret    []byte
+	statePath string
+
+	// protects last, momentums are signed one at a time
+	lock sync.Mutex
+	last signerState
+}
+
+// NewSignerServer signs with keyPair for the nodes authenticated with secret. The last signed momentum is
+// recorded in statePath, it's only kept in memory if statePath is empty.
+func NewSignerServer(keyPair *wallet.KeyPair, secret []byte, statePath string) (*SignerServer, error) {
+	if len(secret) < MinSecretSize {
+		return nil, ErrSecretTooShort
+	}
+	server := &SignerServer{
+		log:       common.PillarLogger.New("submodule", "signer-server"),
+		keyPair:   keyPair,
+		secret:    secret,
+		statePath: statePath,
+	}
+	if statePath != "" {
+		data, err := os.ReadFile(statePath)
+		if err == nil {
+			if err := json.Unmarshal(data, &server.last); err != nil {
+				return nil, errors.Errorf("invalid signer state %v. Reason: %v", statePath, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return server, nil
+}
+
+// Serve answers the requests of the nodes connected to listener, until the listener is closed.
+func (s *SignerServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer common.RecoverStack()
+			defer conn.Close()
+			s.log.Info("node connected", "remote", conn.RemoteAddr())
+			err := s.serveConn(conn)
+			s.log.Info("node disconnected", "remote", conn.RemoteAddr(), "reason", err)
+		}()
+	}
+}
+
+func (s *SignerServer) serveConn(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	encoder := json.NewEncoder(conn)
+	var nonce []byte
+	authenticated := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return err
+		}
+		request := new(signerRequest)
+		response := new(signerResponse)
+		if err := json.Unmarshal(line, request); err != nil {
+			return err
+		}
+
+		switch {
+		case request.Method == signerMethodHello:
+			nonce = make([]byte, attestationChallengeSize)
+			if _, err := rand.Read(nonce); err != nil {
+				return err
+			}
+			response.Nonce = hex.EncodeToString(nonce)
+		case request.Method == signerMethodAttest:
+			auth, err := hex.DecodeString(request.Auth)
+			if err != nil || nonce == nil || !hmac.Equal(auth, secretAuth(s.secret, nonce)) {
+				// the connection is dropped, so the secret can't be guessed with the same nonce
+				_ = encoder.Encode(&signerResponse{Error: "authentication failed"})
+				return errors.New("authentication failed")
+			}
+			authenticated = true
+			challenge, err := hex.DecodeString(request.Challenge)
+			if err != nil || len(challenge) != attestationChallengeSize {
+				response.Error = "invalid challenge"
+				break
+			}
+			response.PublicKey = hex.EncodeToString(s.keyPair.Public)
+			response.Signature = hex.EncodeToString(s.keyPair.Sign(append(append([]byte{}, attestationPrefix...), challenge...)))
+		case !authenticated:
+			response.Error = "not authenticated"
+		case request.Method == signerMethodMomentum:
+			signature, err := s.signMomentum(request.Data)
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response.Signature = hex.EncodeToString(signature)
+		case request.Method == signerMethodAccountBlock:
+			signature, err := s.signAccountBlock(request.Data)
+			if err != nil {
+				response.Error = err.Error()
+				break
+			}
+			response.Signature = hex.EncodeToString(signature)
+		default:
+			response.Error = "unknown method"
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return err
+		}
+	}
+}
+
+// signMomentum signs the serialized momentum, unless it conflicts with the last signed one.
+// The new last momentum is recorded before the signature is returned.
+func (s *SignerServer) signMomentum(data string) ([]byte, error) {
+	serialized, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, errors.New("invalid data")
+	}
+	momentum, err := nom.DeserializeMomentum(serialized)
+	if err != nil {
+		return nil, errors.New("invalid momentum")
+	}
+	hash := momentum.ComputeHash()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if hash != s.last.Hash {
+		if momentum.Height <= s.last.Height {
+			return nil, errors.Errorf("already signed momentum %v at height %v", s.last.Hash, s.last.Height)
+		}
+		if momentum.TimestampUnix <= s.last.Timestamp {
+			return nil, errors.Errorf("already signed momentum %v in the slot at %v", s.last.Hash, s.last.Timestamp)
+		}
+		last := signerState{Height: momentum.Height, Timestamp: momentum.TimestampUnix, Hash: hash}
+		if err := s.saveState(&last); err != nil {
+			s.log.Error("failed to save the signer state", "reason", err)
+			return nil, errors.New("failed to save the signer state")
+		}
+		s.last = last
+	}
+	s.log.Info("signed momentum", "height", momentum.Height, "hash", hash)
+	return s.keyPair.Sign(hash.Bytes()), nil
+}
+
+// signAccountBlock signs the serialized account-block of the pillar address.
+func (s *SignerServer) signAccountBlock(data string) ([]byte, error) {
+	serialized, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, errors.New("invalid data")
+	}
+	block, err := nom.DeserializeAccountBlock(serialized)
+	if err != nil {
+		return nil, errors.New("invalid account-block")
+	}
+	if block.Address != s.keyPair.Address {
+		return nil, errors.Errorf("account-block of %v isn't signed for %v", block.Address, s.keyPair.Address)
+	}
+	hash := block.ComputeHash()
+	s.log.Info("signed account-block", "height", block.Height, "to", block.ToAddress, "hash", hash)
+	return s.keyPair.Sign(hash.Bytes()), nil
+}
+
+// saveState writes the state to a temporary file first, so the state file is never left half written.
+func (s *SignerServer) saveState(state *signerState) error {
+	if s.statePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.statePath+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(s.statePath+".tmp", s.statePath)
+}
@@ -0,0 +1,187 @@
+package pillar
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+var testSignerSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func testSignerKeyPair(t *testing.T, index uint32) *wallet.KeyPair {
+	keyPair, err := wallet.DeriveWithIndex(index, make([]byte, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair
+}
+
+// startSignerServer serves server on a loopback port until the test ends, returning the address to dial.
+func startSignerServer(t *testing.T, server *SignerServer) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Serve(listener)
+	return listener.Addr().String()
+}
+
+func newTestSignerServer(t *testing.T, keyPair *wallet.KeyPair, statePath string) string {
+	server, err := NewSignerServer(keyPair, testSignerSecret, statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return startSignerServer(t, server)
+}
+
+func testMomentum(height, timestamp uint64, data string) *nom.Momentum {
+	m := &nom.Momentum{
+		ChainIdentifier: 1,
+		Height:          height,
+		TimestampUnix:   timestamp,
+		Data:            []byte(data),
+		Content:         nom.NewMomentumContent(nil),
+		Version:         1,
+	}
+	m.Hash = m.ComputeHash()
+	return m
+}
+
+func signMomentum(signer Signer, m *nom.Momentum) ([]byte, error) {
+	signature, _, _, err := signer.SignMomentum(m)(m.Hash.Bytes())
+	return signature, err
+}
+
+// Test the remote signer
+//   - test the momentums and account-blocks are signed with the key of the coinbase
+//   - test a second momentum at the same height, in the same slot or below the last one is refused
+//   - test the same momentum can be signed again
+//   - test only account-blocks of the coinbase are signed
+//   - test data which isn't the hash of the block isn't sent to the signer
+func TestRemoteSigner_Sign(t *testing.T) {
+	keyPair := testSignerKeyPair(t, 0)
+	address := newTestSignerServer(t, keyPair, "")
+	signer := NewRemoteSigner(address, keyPair.Address, testSignerSecret)
+
+	m := testMomentum(10, 100, "first")
+	signature, err := signMomentum(signer, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(keyPair.Public, m.Hash.Bytes(), signature) {
+		t.Fatal("invalid momentum signature")
+	}
+	if _, err := signMomentum(signer, m); err != nil {
+		t.Fatalf("same momentum wasn't signed again: %v", err)
+	}
+	for _, conflicting := range []*nom.Momentum{
+		testMomentum(10, 110, "same height"),
+		testMomentum(11, 100, "same slot"),
+		testMomentum(9, 90, "lower"),
+	} {
+		if _, err := signMomentum(signer, conflicting); err == nil {
+			t.Fatalf("signed conflicting momentum at height %v and timestamp %v", conflicting.Height, conflicting.TimestampUnix)
+		}
+	}
+	if _, err := signMomentum(signer, testMomentum(11, 110, "next")); err != nil {
+		t.Fatalf("next momentum wasn't signed: %v", err)
+	}
+
+	block := &nom.AccountBlock{BlockType: nom.BlockTypeUserSend, Address: keyPair.Address, Height: 2}
+	block.Hash = block.ComputeHash()
+	signature, _, publicKey, err := signer.SignAccountBlock(block)(block.Hash.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(publicKey, block.Hash.Bytes(), signature) {
+		t.Fatal("invalid account-block signature")
+	}
+	other := &nom.AccountBlock{BlockType: nom.BlockTypeUserSend, Address: testSignerKeyPair(t, 1).Address, Height: 2}
+	other.Hash = other.ComputeHash()
+	if _, _, _, err := signer.SignAccountBlock(other)(other.Hash.Bytes()); err == nil {
+		t.Fatal("signed an account-block of another address")
+	}
+
+	if _, _, _, err := signer.SignAccountBlock(block)(types.NewHash([]byte("other")).Bytes()); err != ErrSignerData {
+		t.Fatalf("signed data which isn't the hash of the block: %v", err)
+	}
+}
+
+// Test the authentication
+//   - test a node with another secret isn't served
+//   - test nothing is signed before the connection is authenticated
+//   - test the secret is required to have at least MinSecretSize bytes
+func TestRemoteSigner_Authentication(t *testing.T) {
+	keyPair := testSignerKeyPair(t, 0)
+	address := newTestSignerServer(t, keyPair, "")
+
+	signer := NewRemoteSigner(address, keyPair.Address, []byte("another secret of the node"))
+	if _, err := signMomentum(signer, testMomentum(1, 10, "")); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("signed with a wrong secret: %v", err)
+	}
+
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	serialized, _ := testMomentum(1, 10, "").Serialize()
+	request, _ := json.Marshal(&signerRequest{Method: signerMethodMomentum, Data: hex.EncodeToString(serialized)})
+	if _, err := conn.Write(append(request, '\n')); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := new(signerResponse)
+	if err := json.Unmarshal(line, response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Signature != "" || response.Error != "not authenticated" {
+		t.Fatalf("unauthenticated request answered with %+v", response)
+	}
+
+	if _, err := NewSignerServer(keyPair, []byte("short"), ""); err != ErrSecretTooShort {
+		t.Fatalf("short secret accepted: %v", err)
+	}
+}
+
+// Test the remote signer fails the attestation when the signer holds the key of another address
+func TestRemoteSigner_Attestation(t *testing.T) {
+	address := newTestSignerServer(t, testSignerKeyPair(t, 1), "")
+	signer := NewRemoteSigner(address, testSignerKeyPair(t, 0).Address, testSignerSecret)
+	if _, err := signMomentum(signer, testMomentum(1, 10, "")); err != ErrSignerAttestation {
+		t.Fatalf("expected %v, got %v", ErrSignerAttestation, err)
+	}
+}
+
+// Test the last signed momentum is kept after a restart of the signer, so a conflicting one is still refused
+func TestSignerServer_State(t *testing.T) {
+	keyPair := testSignerKeyPair(t, 0)
+	statePath := filepath.Join(t.TempDir(), "signer-state.json")
+
+	signer := NewRemoteSigner(newTestSignerServer(t, keyPair, statePath), keyPair.Address, testSignerSecret)
+	m := testMomentum(10, 100, "first")
+	if _, err := signMomentum(signer, m); err != nil {
+		t.Fatal(err)
+	}
+
+	signer = NewRemoteSigner(newTestSignerServer(t, keyPair, statePath), keyPair.Address, testSignerSecret)
+	if _, err := signMomentum(signer, testMomentum(10, 100, "second")); err == nil {
+		t.Fatal("signed a conflicting momentum after a restart")
+	}
+	if _, err := signMomentum(signer, m); err != nil {
+		t.Fatalf("same momentum wasn't signed after a restart: %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package pillar
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/protocol"
+)
+
+const (
+	// number of momentums received from other pillars over which the time drift is averaged
+	driftSamples = 30
+	// number of momentums searched for the last one produced by the pillar, if none was produced since start
+	lastProducedLookback = 360
+)
+
+// Stats describes the momentum production of the pillar whose producer address is the coinbase.
+type Stats struct {
+	Name            string        `json:"name"`
+	ProducerAddress types.Address `json:"producerAddress"`
+
+	// Production in the current epoch, up to the frontier momentum
+	Epoch             uint64 `json:"epoch"`
+	ExpectedMomentums uint64 `json:"expectedMomentums"`
+	ProducedMomentums uint64 `json:"producedMomentums"`
+	MissedMomentums   uint64 `json:"missedMomentums"`
+
+	// NextSlots lists the upcoming production slots in the current and the next election tick
+	NextSlots []*Slot `json:"nextSlots"`
+	// TimeDrift is the average delay in milliseconds between the timestamps of the momentums produced
+	// by other pillars and their arrival, by the local clock. Momentums propagate in well under a second,
+	// so a large or negative drift means the local clock is off.
+	TimeDrift int64 `json:"timeDrift"`
+	// LastProduced is the last momentum produced by the pillar, nil if none in the recent momentums
+	LastProduced *ProducedMomentum `json:"lastProduced"`
+
+	// Backup is true if the node is a backup producer, Producing is false while the node doesn't hold the producer lease
+	Backup    bool `json:"backup"`
+	Producing bool `json:"producing"`
+}
+
+type Slot struct {
+	StartTime int64 `json:"startTime"`
+	EndTime   int64 `json:"endTime"`
+}
+
+type ProducedMomentum struct {
+	Hash      types.Hash `json:"hash"`
+	Height    uint64     `json:"height"`
+	Timestamp int64      `json:"timestamp"`
+}
+
+// productionTracker follows the inserted momentums for the time drift and the last produced momentum.
+type productionTracker struct {
+	chain       chain.Chain
+	broadcaster protocol.Broadcaster
+
+	lock         sync.Mutex
+	producer     types.Address
+	drifts       []time.Duration
+	lastProduced *ProducedMomentum
+}
+
+func newProductionTracker(chain chain.Chain, broadcaster protocol.Broadcaster) *productionTracker {
+	return &productionTracker{
+		chain:       chain,
+		broadcaster: broadcaster,
+	}
+}
+
+func (t *productionTracker) setProducer(producer types.Address) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.producer != producer {
+		t.producer = producer
+		t.lastProduced = nil
+	}
+}
+
+func (t *productionTracker) InsertMomentum(detailed *nom.DetailedMomentum) {
+	momentum := detailed.Momentum
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if momentum.Producer() == t.producer {
+		t.lastProduced = &ProducedMomentum{
+			Hash:      momentum.Hash,
+			Height:    momentum.Height,
+			Timestamp: momentum.Timestamp.Unix(),
+		}
+		return
+	}
+	// momentums inserted while syncing arrive long after their timestamp
+	if t.broadcaster.SyncInfo().State != protocol.SyncDone {
+		return
+	}
+	t.drifts = append(t.drifts, common.Clock.Now().Sub(*momentum.Timestamp))
+	if len(t.drifts) > driftSamples {
+		t.drifts = t.drifts[len(t.drifts)-driftSamples:]
+	}
+}
+func (t *productionTracker) DeleteMomentum(detailed *nom.DetailedMomentum) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.lastProduced != nil && t.lastProduced.Hash == detailed.Momentum.Hash {
+		t.lastProduced = nil
+	}
+}
+
+func (t *productionTracker) timeDrift() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.drifts) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, drift := range t.drifts {
+		total += drift
+	}
+	return total / time.Duration(len(t.drifts))
+}
+
+func (t *productionTracker) getLastProduced(frontier *nom.Momentum) (*ProducedMomentum, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.lastProduced != nil {
+		return t.lastProduced, nil
+	}
+
+	momentums, err := t.chain.GetFrontierMomentumStore().GetMomentumsByHeight(frontier.Height, false, lastProducedLookback)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(momentums) - 1; i >= 0; i-- {
+		if momentum := momentums[i]; momentum.Producer() == t.producer {
+			t.lastProduced = &ProducedMomentum{
+				Hash:      momentum.Hash,
+				Height:    momentum.Height,
+				Timestamp: momentum.Timestamp.Unix(),
+			}
+			break
+		}
+	}
+	return t.lastProduced, nil
+}
+
+func getStats(chain chain.Chain, cs consensus.Consensus, tracker *productionTracker, producer types.Address) (*Stats, error) {
+	store := chain.GetFrontierMomentumStore()
+	frontier, err := store.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	stats := &Stats{
+		ProducerAddress: producer,
+		NextSlots:       make([]*Slot, 0),
+		TimeDrift:       tracker.timeDrift().Milliseconds(),
+	}
+
+	pillars, err := store.GetActivePillars()
+	if err != nil {
+		return nil, err
+	}
+	for _, pillar := range pillars {
+		if pillar.BlockProducingAddress == producer {
+			stats.Name = pillar.Name
+		}
+	}
+
+	// upcoming slots, the election of the next tick is already decided
+	now := common.Clock.Now()
+	events, err := cs.GetProducerEvents(now)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) != 0 {
+		next, err := cs.GetProducerEvents(events[len(events)-1].EndTime)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, next...)
+	}
+	for _, event := range events {
+		if event.Producer == producer && event.EndTime.After(now) && event.StartTime.After(*frontier.Timestamp) {
+			stats.NextSlots = append(stats.NextSlots, &Slot{
+				StartTime: event.StartTime.Unix(),
+				EndTime:   event.EndTime.Unix(),
+			})
+		}
+	}
+
+	// the epoch points count all the slots of the current election tick as expected
+	reader := cs.FrontierPillarReader()
+	stats.Epoch = reader.EpochTicker().ToTick(*frontier.Timestamp)
+	epochStats, err := reader.EpochStats(stats.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	if epochStats != nil && stats.Name != "" {
+		if pillarStats, ok := epochStats.Pillars[stats.Name]; ok {
+			stats.ExpectedMomentums = pillarStats.ExceptedBlockNum
+			stats.ProducedMomentums = pillarStats.BlockNum
+		}
+		events, err := cs.GetProducerEvents(*frontier.Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if event.Producer == producer && event.StartTime.After(*frontier.Timestamp) && stats.ExpectedMomentums > 0 {
+				stats.ExpectedMomentums -= 1
+			}
+		}
+		if stats.ExpectedMomentums > stats.ProducedMomentums {
+			stats.MissedMomentums = stats.ExpectedMomentums - stats.ProducedMomentums
+		}
+	}
+
+	stats.LastProduced, err = tracker.getLastProduced(frontier)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/vm"
-	"github.com/zenon-network/go-zenon/wallet"
 )
 
 // worker takes care of generating receive blocks for contracts.
@@ -24,7 +23,7 @@ type worker struct {
 	children sync.WaitGroup
 
 	contracts []types.Address
-	coinbase  *wallet.KeyPair
+	coinbase  Signer
 
 	// modules
 	chain       chain.Chain
@@ -29,5 +29,5 @@ func (w *worker) generateMomentum(e consensus.ProducerEvent) (*nom.MomentumTrans
 	return w.supervisor.GenerateMomentum(&nom.DetailedMomentum{
 		Momentum:      m,
 		AccountBlocks: blocks,
-	}, w.coinbase.Signer)
+	}, w.coinbase.SignMomentum(m))
 }
@@ -21,12 +21,13 @@ func (w *worker) updateContracts(momentumStore store.Momentum) error {
 	for _, address := range types.EmbeddedWUpdate {
 		if err := canPerformEmbeddedUpdate(momentumStore, w.chain, address); err == nil {
 			w.log.Info("producing block to update embedded-contract", "contract-address", address)
-			if block, err := w.supervisor.GenerateFromTemplate(&nom.AccountBlock{
+			template := &nom.AccountBlock{
 				BlockType: nom.BlockTypeUserSend,
-				Address:   w.coinbase.Address,
+				Address:   w.coinbase.Address(),
 				ToAddress: address,
 				Data:      definition.ABICommon.PackMethodPanic(definition.UpdateMethodName),
-			}, w.coinbase.Signer); err != nil {
+			}
+			if block, err := w.supervisor.GenerateFromTemplate(template, w.coinbase.SignAccountBlock(template)); err != nil {
 				return err
 			} else {
 				w.broadcaster.CreateAccountBlock(block)
@@ -0,0 +1,143 @@
+package pow
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	ErrPoolFull    = errors.New("too many PoW jobs are waiting for a worker")
+	ErrPoolStopped = errors.New("PoW pool is stopped")
+)
+
+// PoolStats is a snapshot of the jobs handled by a Pool.
+type PoolStats struct {
+	MaxJobs       int    `json:"maxJobs"`
+	MaxQueuedJobs int    `json:"maxQueuedJobs"`
+	Running       int32  `json:"running"`
+	Queued        int32  `json:"queued"`
+	Completed     uint64 `json:"completed"`
+	Cancelled     uint64 `json:"cancelled"`
+	Rejected      uint64 `json:"rejected"`
+}
+
+// Pool computes PoW nonces, running at most maxJobs at a time, each of them busying one CPU core.
+// Up to maxQueuedJobs wait for a free worker, further jobs are rejected.
+type Pool struct {
+	maxJobs       int
+	maxQueuedJobs int32
+	workers       chan struct{}
+
+	running   int32
+	queued    int32
+	completed uint64
+	cancelled uint64
+	rejected  uint64
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewPool(maxJobs, maxQueuedJobs int) *Pool {
+	if maxJobs <= 0 {
+		maxJobs = 1
+	}
+	if maxQueuedJobs < 0 {
+		maxQueuedJobs = 0
+	}
+	return &Pool{
+		maxJobs:       maxJobs,
+		maxQueuedJobs: int32(maxQueuedJobs),
+		workers:       make(chan struct{}, maxJobs),
+		quit:          make(chan struct{}),
+	}
+}
+
+// Generate computes the nonce for dataHash, see GetAccountBlockHash.
+// It returns early if ctx is done or if the pool is stopped.
+func (p *Pool) Generate(ctx context.Context, dataHash types.Hash, difficulty uint64) (*nom.Nonce, error) {
+	select {
+	case <-p.quit:
+		return nil, ErrPoolStopped
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := p.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer p.release()
+
+	data, err := GetPoWNonceWithContext(ctx, new(big.Int).SetUint64(difficulty), dataHash)
+	if err != nil {
+		atomic.AddUint64(&p.cancelled, 1)
+		return nil, err
+	}
+	atomic.AddUint64(&p.completed, 1)
+	nonce := nom.DeSerializeNonce(data)
+	return &nonce, nil
+}
+
+// acquire reserves a worker, waiting in the queue if all of them are busy.
+func (p *Pool) acquire(ctx context.Context) error {
+	select {
+	case p.workers <- struct{}{}:
+		atomic.AddInt32(&p.running, 1)
+		return nil
+	default:
+	}
+
+	if atomic.AddInt32(&p.queued, 1) > p.maxQueuedJobs {
+		atomic.AddInt32(&p.queued, -1)
+		atomic.AddUint64(&p.rejected, 1)
+		return ErrPoolFull
+	}
+	defer atomic.AddInt32(&p.queued, -1)
+
+	select {
+	case p.workers <- struct{}{}:
+		atomic.AddInt32(&p.running, 1)
+		return nil
+	case <-ctx.Done():
+		atomic.AddUint64(&p.cancelled, 1)
+		return ctx.Err()
+	}
+}
+func (p *Pool) release() {
+	atomic.AddInt32(&p.running, -1)
+	<-p.workers
+}
+
+func (p *Pool) Stats() *PoolStats {
+	return &PoolStats{
+		MaxJobs:       p.maxJobs,
+		MaxQueuedJobs: int(p.maxQueuedJobs),
+		Running:       atomic.LoadInt32(&p.running),
+		Queued:        atomic.LoadInt32(&p.queued),
+		Completed:     atomic.LoadUint64(&p.completed),
+		Cancelled:     atomic.LoadUint64(&p.cancelled),
+		Rejected:      atomic.LoadUint64(&p.rejected),
+	}
+}
+
+// Stop cancels all running and queued jobs and rejects new ones.
+func (p *Pool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.quit)
+	})
+}
@@ -1,6 +1,7 @@
 package pow
 
 import (
+	"context"
 	"encoding/binary"
 	"math/big"
 
@@ -11,6 +12,11 @@ import (
 	"github.com/zenon-network/go-zenon/wallet"
 )
 
+const (
+	// cancelCheckInterval is the number of hashes computed between two checks of the context.
+	cancelCheckInterval = 1 << 14
+)
+
 func GetAccountBlockHash(block *nom.AccountBlock) types.Hash {
 	return types.NewHash(append(block.Address.Bytes(), block.PreviousHash.Bytes()...))
 }
@@ -23,9 +29,22 @@ func CheckPoWNonce(block *nom.AccountBlock) bool {
 }
 
 func GetPoWNonce(difficulty *big.Int, dataHash types.Hash) []byte {
+	nonce, _ := GetPoWNonceWithContext(context.Background(), difficulty, dataHash)
+	return nonce
+}
+
+// GetPoWNonceWithContext is GetPoWNonce which gives up as soon as ctx is done.
+func GetPoWNonceWithContext(ctx context.Context, difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
 	rng := wallet.GetEntropyCSPRNG(8)
 	calc, target := getTarget(difficulty, dataHash, rng)
-	for {
+	for i := 0; ; i++ {
+		if i%cancelCheckInterval == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+		}
 		if greaterDifficulty(crypto.Hash(calc), target[:]) {
 			break
 		}
@@ -33,7 +52,7 @@ func GetPoWNonce(difficulty *big.Int, dataHash types.Hash) []byte {
 	}
 	var arr [8]byte
 	copy(arr[:], calc[:8])
-	return arr[:]
+	return arr[:], nil
 }
 
 func getTarget(difficulty *big.Int, data types.Hash, nonce []byte) ([]byte, [8]byte) {
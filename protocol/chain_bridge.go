@@ -1,12 +1,15 @@
 package protocol
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
@@ -40,6 +43,7 @@ func (c chainBridge) AddAccountBlocks(blocks []*nom.AccountBlock) error {
 		if block.BlockType == nom.BlockTypeContractSend {
 			continue
 		}
+		c.checkConfirmedConflict(block)
 		transaction, err := c.supervisor.ApplyBlock(block)
 		if err != nil {
 			log.Error("error while applying account-block", "reason", err, "account-block-header", block.Header())
@@ -53,6 +57,23 @@ func (c chainBridge) AddAccountBlocks(blocks []*nom.AccountBlock) error {
 	}
 	return nil
 }
+
+// checkConfirmedConflict reports block if another account-block is confirmed at its height. The verifier rejects
+// such blocks, so only the ones signed by their address are reported, the others can't be evidence.
+// Conflicts with the uncommitted blocks are found by the account pool.
+func (c chainBridge) checkConfirmedConflict(block *nom.AccountBlock) {
+	if types.IsEmbeddedAddress(block.Address) {
+		return
+	}
+	confirmed, err := c.chain.GetFrontierMomentumStore().GetAccountStore(block.Address).ByHeight(block.Height)
+	if err != nil || confirmed == nil || confirmed.Hash == block.Hash {
+		return
+	}
+	if err := c.supervisor.PrecheckAccountBlocks([]*nom.AccountBlock{block}); err != nil {
+		return
+	}
+	c.chain.ReportAccountChainConflict(confirmed, block, true)
+}
 func (c chainBridge) GetTransactions() []*nom.AccountBlock {
 	blocks := c.chain.GetAllUncommittedAccountBlocks()
 	return blocks
@@ -110,6 +131,96 @@ func (c chainBridge) Status() (td uint64, currentBlock types.Hash, genesisBlock
 	return frontier.Height, frontier.Hash, c.chain.GetGenesisMomentum().Hash
 }
 
+// GetAccountProofs returns the proofs of the addresses at the momentum, nil if the momentum is unknown
+// or older than chain.MaxRecentStateDepth.
+func (c chainBridge) GetAccountProofs(momentum types.Hash, addresses []types.Address) ([]*AccountProof, error) {
+	m, err := c.chain.GetFrontierMomentumStore().GetMomentumByHash(momentum)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	store, err := c.chain.GetRecentMomentumStore(m.Identifier())
+	if err == chain.ErrStateTooOld || store == nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*AccountProof, 0, len(addresses))
+	for _, address := range addresses {
+		account := store.GetAccountStore(address)
+		balances, err := getSortedBalances(account)
+		if err != nil {
+			return nil, err
+		}
+		proof := &AccountProof{
+			Address:  address,
+			Balances: balances,
+		}
+		if proof.FrontierBlock, err = account.Frontier(); err != nil {
+			return nil, err
+		}
+		if proof.FrontierBlock != nil {
+			if proof.ConfirmationHeight, err = store.GetBlockConfirmationHeight(proof.FrontierBlock.Hash); err != nil {
+				return nil, err
+			}
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs, nil
+}
+
+// GetProducers returns the producers elected for the tick in the order of their slots, nil if the tick
+// starts after the frontier momentum. The elections are cached by the consensus for every inserted tick.
+func (c chainBridge) GetProducers(tick uint64) ([]types.Address, error) {
+	frontier, err := c.chain.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	start, _ := consensus.NewConsensusContext(*c.chain.GetGenesisMomentum().Timestamp).ToTime(tick)
+	if start.After(*frontier.Timestamp) {
+		return nil, nil
+	}
+	events, err := c.consensus.GetProducerEvents(start)
+	if err != nil {
+		return nil, err
+	}
+	producers := make([]types.Address, 0, len(events))
+	for _, event := range events {
+		producers = append(producers, event.Producer)
+	}
+	return producers, nil
+}
+
+func getSortedBalances(account store.Account) ([]*AccountStateBalance, error) {
+	balanceMap, err := account.GetBalanceMap()
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]*AccountStateBalance, 0, len(balanceMap))
+	for zts, amount := range balanceMap {
+		balances = append(balances, &AccountStateBalance{
+			TokenStandard: zts,
+			Amount:        amount,
+		})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return bytes.Compare(balances[i].TokenStandard.Bytes(), balances[j].TokenStandard.Bytes()) < 0
+	})
+	return balances, nil
+}
+
+// VerifyChain verifies the signatures and PoW of all the account-blocks in parallel, they don't depend
+// on the chain state. Verified blocks aren't checked again by InsertChain, so the sync verifies the next
+// momentums while the previous ones are inserted.
+func (c chainBridge) VerifyChain(momentums []*nom.DetailedMomentum) error {
+	blocks := make([]*nom.AccountBlock, 0, len(momentums))
+	for _, detailed := range momentums {
+		blocks = append(blocks, detailed.AccountBlocks...)
+	}
+	return c.supervisor.PrecheckAccountBlocks(blocks)
+}
+
 func (c chainBridge) InsertChain(momentums []*nom.DetailedMomentum) (int, error) {
 	a := momentums[0]
 	b := momentums[len(momentums)-1]
@@ -178,6 +289,11 @@ func (c chainBridge) InsertChain(momentums []*nom.DetailedMomentum) (int, error)
 		}
 	}
 
+	// On failure, the blocks are verified serially below, which finds the momentum to report.
+	if err := c.VerifyChain(momentums); err != nil {
+		log.Info("failed to precheck account-blocks", "reason", err)
+	}
+
 	// Insert momentum now
 	for index, detailed := range momentums {
 		for _, block := range detailed.AccountBlocks {
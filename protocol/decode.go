@@ -0,0 +1,170 @@
+package protocol
+
+import (
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/protocol/downloader"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+const (
+	// maxRLPDepth is the deepest nesting of lists accepted in a payload. The decoder recurses once per
+	// level, so deeper payloads are rejected before they are decoded. Momentums need 7 levels.
+	maxRLPDepth = 16
+	// maxStatusVersions is the largest number of versions advertised in a status message.
+	maxStatusVersions = 16
+	// maxTxsPerMsg is the largest number of account-blocks in a transaction message, the node sends
+	// them in packs of txsyncPackSize.
+	maxTxsPerMsg = 4096
+)
+
+var (
+	errTooDeep       = errors.Errorf("lists nested deeper than %v", maxRLPDepth)
+	errZeroAmount    = errors.New("amount is zero")
+	errRangeOverflow = errors.New("range overflows")
+)
+
+// DecodeMessage decodes the payload of a message of the eth protocol and returns a pointer to the decoded
+// value. The payload must hold a single value, nested no deeper than maxRLPDepth, with lists no longer than
+// the ones the node requests or sends and with ranges which don't overflow. Messages without a payload and
+// unknown codes are rejected.
+// It doesn't need a peer or a chain, so it can be fuzzed with any code and payload.
+func DecodeMessage(code uint64, payload []byte) (interface{}, error) {
+	val, err := newMessage(code)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodePayload(code, payload, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// newMessage returns a pointer to the type of the payload of the message code.
+func newMessage(code uint64) (interface{}, error) {
+	switch code {
+	case StatusMsg:
+		return new(statusData), nil
+	case NewBlockHashesMsg, BlockHashesMsg, GetBlocksMsg:
+		return new([]types.Hash), nil
+	case TxMsg:
+		return new([]*nom.AccountBlock), nil
+	case GetBlockHashesMsg:
+		return new(getBlockHashesData), nil
+	case BlocksMsg:
+		return new([]*nom.DetailedMomentum), nil
+	case NewBlockMsg:
+		return new(nom.DetailedMomentum), nil
+	case GetBlockHashesFromNumberMsg:
+		return new(getBlockHashesFromNumberData), nil
+	case MomentumHeadersMsg:
+		return new([]*nom.Momentum), nil
+	case GetMomentumHeadersMsg:
+		return new(getMomentumHeadersData), nil
+	case GetAccountProofsMsg:
+		return new(getAccountProofsData), nil
+	case AccountProofsMsg:
+		return new([]*AccountProof), nil
+	case NewMomentumHashesMsg:
+		return new([]types.HashHeight), nil
+	case GetProducersMsg:
+		return new(getProducersData), nil
+	case ProducersMsg:
+		return new(producersData), nil
+	default:
+		return nil, errors.Errorf("unknown message code %v", code)
+	}
+}
+
+// decodeMsg reads the payload of msg and decodes it into val, see DecodeMessage.
+func decodeMsg(msg p2p.Msg, val interface{}) error {
+	payload, err := io.ReadAll(io.LimitReader(msg.Payload, ProtocolMaxMsgSize))
+	if err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	if err := decodePayload(msg.Code, payload, val); err != nil {
+		return errResp(ErrDecode, "%v: %v", msg, err)
+	}
+	return nil
+}
+
+func decodePayload(code uint64, payload []byte, val interface{}) error {
+	if err := checkDepth(payload, maxRLPDepth); err != nil {
+		return err
+	}
+	// unlike a stream, DecodeBytes rejects the data after the value
+	if err := rlp.DecodeBytes(payload, val); err != nil {
+		return err
+	}
+	return validateMessage(code, val)
+}
+
+// checkDepth returns an error if the payload isn't made of well formed values, or if their lists are nested
+// deeper than depth.
+func checkDepth(payload []byte, depth int) error {
+	for len(payload) > 0 {
+		kind, content, rest, err := rlp.Split(payload)
+		if err != nil {
+			return err
+		}
+		if kind == rlp.List {
+			if depth == 0 {
+				return errTooDeep
+			}
+			if err := checkDepth(content, depth-1); err != nil {
+				return err
+			}
+		}
+		payload = rest
+	}
+	return nil
+}
+
+// validateMessage checks the lengths of the lists and the ranges of the decoded message code.
+func validateMessage(code uint64, val interface{}) error {
+	switch v := val.(type) {
+	case *statusData:
+		return checkCount("versions", len(v.Versions), maxStatusVersions)
+	case *[]types.Hash:
+		return checkCount("hashes", len(*v), downloader.MaxHashFetch)
+	case *[]types.HashHeight:
+		return checkCount("momentum identifiers", len(*v), downloader.MaxHashFetch)
+	case *[]*nom.AccountBlock:
+		return checkCount("account-blocks", len(*v), maxTxsPerMsg)
+	case *[]*nom.DetailedMomentum:
+		return checkCount("momentums", len(*v), downloader.MaxBlockFetch)
+	case *[]*nom.Momentum:
+		return checkCount("momentums", len(*v), MaxHeaderFetch)
+	case *getBlockHashesFromNumberData:
+		if v.Amount == 0 {
+			return errZeroAmount
+		}
+		if v.Number+v.Amount < v.Number {
+			return errRangeOverflow
+		}
+	case *getMomentumHeadersData:
+		if v.Height+v.Amount < v.Height {
+			return errRangeOverflow
+		}
+	case *getAccountProofsData:
+		return checkCount("addresses", len(v.Addresses), MaxAccountStateFetch)
+	case *[]*AccountProof:
+		return checkCount("account proofs", len(*v), MaxAccountStateFetch)
+	case *producersData:
+		return checkCount("producers", len(v.Producers), int(constants.ConsensusConfig.NodeCount))
+	}
+	return nil
+}
+
+func checkCount(name string, count, max int) error {
+	if count > max {
+		return errors.Errorf("%v %v > %v", count, name, max)
+	}
+	return nil
+}
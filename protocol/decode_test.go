@@ -0,0 +1,146 @@
+package protocol
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/protocol/downloader"
+)
+
+func encode(t testing.TB, val interface{}) []byte {
+	data, err := rlp.EncodeToBytes(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// detailedMomentum has an account-block with a descendant, the deepest nesting sent by the node.
+func detailedMomentum() *nom.DetailedMomentum {
+	return &nom.DetailedMomentum{
+		Momentum: &nom.Momentum{
+			Height:  2,
+			Content: nom.MomentumContent{{Address: types.PillarContract, HashHeight: types.HashHeight{Height: 1}}},
+		},
+		AccountBlocks: []*nom.AccountBlock{{
+			Address: types.PillarContract,
+			Amount:  big.NewInt(0),
+			DescendantBlocks: []*nom.AccountBlock{{
+				Address: types.PillarContract,
+				Amount:  big.NewInt(1),
+			}},
+		}},
+	}
+}
+
+// nested returns a list nested depth times.
+func nested(depth int) []byte {
+	// the headers from the innermost list outwards
+	headers := [][]byte{{0xC0}}
+	size := 1
+	for i := 1; i < depth; i++ {
+		header := encodeListHeader(size)
+		headers = append(headers, header)
+		size += len(header)
+	}
+	payload := make([]byte, 0, size)
+	for i := len(headers) - 1; i >= 0; i-- {
+		payload = append(payload, headers[i]...)
+	}
+	return payload
+}
+func encodeListHeader(size int) []byte {
+	if size < 56 {
+		return []byte{0xC0 + byte(size)}
+	}
+	var length []byte
+	for ; size > 0; size >>= 8 {
+		length = append([]byte{byte(size)}, length...)
+	}
+	return append([]byte{0xF7 + byte(len(length))}, length...)
+}
+
+func TestDecodeMessage(t *testing.T) {
+	hashes := make([]types.Hash, downloader.MaxHashFetch+1)
+	cases := []struct {
+		name    string
+		code    uint64
+		payload []byte
+		err     string
+	}{
+		{"blocks", BlocksMsg, encode(t, []*nom.DetailedMomentum{detailedMomentum()}), ""},
+		{"new block", NewBlockMsg, encode(t, detailedMomentum()), ""},
+		{"hashes", NewBlockHashesMsg, encode(t, hashes[:downloader.MaxHashFetch]), ""},
+		{"status", StatusMsg, encode(t, &statusData{ProtocolVersion: eth65, Versions: []uint32{eth65, eth64}}), ""},
+		{"headers", GetMomentumHeadersMsg, encode(t, &getMomentumHeadersData{Height: 1, Amount: MaxHeaderFetch}), ""},
+
+		{"unknown code", ProducersMsg + 1, encode(t, hashes[:1]), "unknown message code 20"},
+		{"empty", TxMsg, nil, "EOF"},
+		{"trailing data", GetMomentumHeadersMsg, append(encode(t, &getMomentumHeadersData{Height: 1, Amount: 1}), 0x80), "input contains more than one value"},
+		{"truncated", BlocksMsg, encode(t, []*nom.DetailedMomentum{detailedMomentum()})[:40], "value size exceeds available input length"},
+		{"too deep", GetBlocksMsg, nested(maxRLPDepth + 1), "lists nested deeper than 16"},
+		{"too many hashes", BlockHashesMsg, encode(t, hashes), "513 hashes > 512"},
+		{"too many producers", ProducersMsg, encode(t, &producersData{Producers: make([]types.Address, 31)}), "31 producers > 30"},
+		{"too many addresses", GetAccountProofsMsg, encode(t, &getAccountProofsData{Addresses: make([]types.Address, MaxAccountStateFetch+1)}), "65 addresses > 64"},
+		{"reserved code", GetBlockHashesFromNumberMsg + 1, encode(t, hashes[:1]), "unknown message code 9"},
+		{"zero amount", GetBlockHashesFromNumberMsg, encode(t, &getBlockHashesFromNumberData{Number: 1}), "amount is zero"},
+		{"overflow", GetBlockHashesFromNumberMsg, encode(t, &getBlockHashesFromNumberData{Number: math.MaxUint64, Amount: 2}), "range overflows"},
+		{"headers overflow", GetMomentumHeadersMsg, encode(t, &getMomentumHeadersData{Height: math.MaxUint64, Amount: 1}), "range overflows"},
+		{"short hash", NewBlockHashesMsg, encode(t, [][]byte{{1, 2, 3}}), "input string too short"},
+	}
+	for _, c := range cases {
+		val, err := DecodeMessage(c.code, c.payload)
+		if c.err == "" {
+			if err != nil {
+				t.Errorf("%v: unexpected error %v", c.name, err)
+			} else if !bytes.Equal(encode(t, val), c.payload) {
+				t.Errorf("%v: decoded value doesn't encode to the payload", c.name)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%v: expected error %q, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestCheckDepth(t *testing.T) {
+	if err := checkDepth(encode(t, []*nom.DetailedMomentum{detailedMomentum()}), 7); err != nil {
+		t.Fatalf("expected momentums to need 7 levels, got %v", err)
+	}
+	if err := checkDepth(nested(maxRLPDepth), maxRLPDepth); err != nil {
+		t.Fatal(err)
+	}
+	// deep enough to exhaust the stack of the decoder, cheap for checkDepth
+	if err := checkDepth(nested(1<<20), maxRLPDepth); err != errTooDeep {
+		t.Fatalf("expected %v, got %v", errTooDeep, err)
+	}
+}
+
+func FuzzDecodeMessage(f *testing.F) {
+	f.Add(uint64(BlocksMsg), encode(f, []*nom.DetailedMomentum{detailedMomentum()}))
+	f.Add(uint64(StatusMsg), encode(f, &statusData{ProtocolVersion: eth65, Versions: []uint32{eth65}}))
+	f.Add(uint64(GetAccountProofsMsg), encode(f, &getAccountProofsData{Addresses: make([]types.Address, 2)}))
+	f.Add(uint64(AccountProofsMsg), encode(f, []*AccountProof{{Balances: []*AccountStateBalance{{Amount: big.NewInt(1)}}}}))
+	f.Add(uint64(GetBlocksMsg), nested(maxRLPDepth+1))
+	f.Fuzz(func(t *testing.T, code uint64, payload []byte) {
+		val, err := DecodeMessage(code, payload)
+		if err != nil {
+			return
+		}
+		// accepted payloads hold a single value within the limits
+		if err := validateMessage(code, val); err != nil {
+			t.Fatalf("accepted message %v fails validation: %v", code, err)
+		}
+		if err := checkDepth(encode(t, val), maxRLPDepth); err != nil {
+			t.Fatalf("accepted message %v re-encodes too deep: %v", code, err)
+		}
+	})
+}
@@ -50,6 +50,7 @@ var (
 	maxQueuedHashes = 256 * 1024 // Maximum number of hashes to queue for import (DOS protection)
 	maxBannedHashes = 4096       // Number of bannable hashes before phasing old ones out
 	maxBlockProcess = 256        // Number of blocks to import at once into the chain
+	maxVerifiedSets = 2          // Number of verified block sets waiting for their import (memory protection)
 )
 
 var (
@@ -80,12 +81,18 @@ type blockRetrievalFn func(types.Hash) *nom.DetailedMomentum
 // headRetrievalFn is a callback type for retrieving the head block from the local chain.
 type headRetrievalFn func() *nom.Momentum
 
+// chainVerifyFn is a callback type to verify a batch of blocks ahead of their insertion, without the chain state.
+type chainVerifyFn func([]*nom.DetailedMomentum) error
+
 // chainInsertFn is a callback type to insert a batch of blocks into the local chain.
 type chainInsertFn func([]*nom.DetailedMomentum) (int, error)
 
 // peerDropFn is a callback type for dropping a peer detected as malicious.
 type peerDropFn func(id string)
 
+// peerReportFn is a callback type for reporting a peer whose blocks failed to import.
+type peerReportFn func(id string, err error)
+
 type blockPack struct {
 	peerId string
 	blocks []*nom.DetailedMomentum
@@ -119,8 +126,10 @@ type Downloader struct {
 	hasBlock    hashCheckFn      // Checks if a block is present in the chain
 	getBlock    blockRetrievalFn // Retrieves a block from the chain
 	headBlock   headRetrievalFn  // Retrieves the head block from the chain
+	verifyChain chainVerifyFn    // Verifies a batch of blocks before it's injected
 	insertChain chainInsertFn    // Injects a batch of blocks into the chain
 	dropPeer    peerDropFn       // Drops a peer for misbehaving
+	reportPeer  peerReportFn     // Reports a peer whose blocks failed to import
 
 	// Status
 	synchroniseMock func(id string, hash types.Hash) error // Replacement for synchronise during testing
@@ -147,7 +156,7 @@ type Block struct {
 }
 
 // New creates a new downloader to fetch hashes and blocks from remote peers.
-func New(hasBlock hashCheckFn, getBlock blockRetrievalFn, headBlock headRetrievalFn, insertChain chainInsertFn, dropPeer peerDropFn) *Downloader {
+func New(hasBlock hashCheckFn, getBlock blockRetrievalFn, headBlock headRetrievalFn, verifyChain chainVerifyFn, insertChain chainInsertFn, dropPeer peerDropFn, reportPeer peerReportFn) *Downloader {
 	// Create the base downloader
 	downloader := &Downloader{
 		queue:       newQueue(),
@@ -155,8 +164,10 @@ func New(hasBlock hashCheckFn, getBlock blockRetrievalFn, headBlock headRetrieva
 		hasBlock:    hasBlock,
 		getBlock:    getBlock,
 		headBlock:   headBlock,
+		verifyChain: verifyChain,
 		insertChain: insertChain,
 		dropPeer:    dropPeer,
+		reportPeer:  reportPeer,
 		newPeerCh:   make(chan *peer, 1),
 		hashCh:      make(chan hashPack, 1),
 		blockCh:     make(chan blockPack, 1),
@@ -191,6 +202,29 @@ func (d *Downloader) Stats() (pending int, cached int, importing int, estimate t
 	return
 }
 
+// PeerStats is a snapshot of the download statistics of a single peer.
+// The counters are kept for the whole lifetime of the peer, across sync cycles.
+type PeerStats struct {
+	Id           string
+	Reputation   int
+	Capacity     int
+	Idle         bool
+	Delivered    uint64
+	Timeouts     uint64
+	Failures     uint64
+	LastDelivery time.Time // Zero if the peer never delivered any blocks
+}
+
+// PeerStats retrieves the download statistics of all registered peers.
+func (d *Downloader) PeerStats() []PeerStats {
+	peers := d.peers.AllPeers()
+	stats := make([]PeerStats, 0, len(peers))
+	for _, p := range peers {
+		stats = append(stats, p.Stats())
+	}
+	return stats
+}
+
 // Synchronising returns whether the downloader is currently retrieving blocks.
 func (d *Downloader) Synchronising() bool {
 	return atomic.LoadInt32(&d.synchronising) > 0
@@ -310,9 +344,9 @@ func (d *Downloader) syncWithPeer(p *peer, hash types.Hash, td uint64) (err erro
 	}()
 
 	log.Info("Synchronizing with the zenon network", "peer-id", p.id, "version", p.version)
-	switch p.version {
-	case eth61:
-		// New eth/61, use forward, concurrent hash and block retrieval algorithm
+	switch {
+	case p.version >= eth61:
+		// Since eth/61, use forward, concurrent hash and block retrieval algorithm
 		number, err := d.findAncestor(p)
 		if err != nil {
 			return err
@@ -585,12 +619,14 @@ func (d *Downloader) fetchBlocks(from uint64) error {
 				case nil:
 					// If no blocks were delivered, demote the peer (need the delivery above)
 					if len(blockPack.blocks) == 0 {
+						peer.MarkFailure()
 						peer.Demote()
 						peer.SetIdle()
 						log.Debug("no blocks delivered", "peer", peer)
 						break
 					}
 					// All was successful, promote the peer and potentially start processing
+					peer.MarkDelivered(len(blockPack.blocks))
 					peer.Promote()
 					peer.SetIdle()
 					log.Debug("delivered blocks", "peer", peer, "num-blocks", len(blockPack.blocks))
@@ -607,6 +643,7 @@ func (d *Downloader) fetchBlocks(from uint64) error {
 				case errNoFetchesPending:
 					// Peer probably timed out with its delivery but came through
 					// in the end, demote, but allow to to pull from this peer.
+					peer.MarkFailure()
 					peer.Demote()
 					peer.SetIdle()
 					log.Debug("out of bound delivery", "peer", peer)
@@ -616,11 +653,13 @@ func (d *Downloader) fetchBlocks(from uint64) error {
 					// caused by a timeout and delivery during a new sync cycle.
 					// Don't set it to idle as the original request should still be
 					// in flight.
+					peer.MarkFailure()
 					peer.Demote()
 					log.Debug("stale delivery", "peer", "peer", peer)
 
 				default:
 					// Peer did something semi-useful, demote but keep it around
+					peer.MarkFailure()
 					peer.Demote()
 					peer.SetIdle()
 					log.Debug("delivery partially failed", "peer", peer, "reason", err)
@@ -663,6 +702,7 @@ func (d *Downloader) fetchBlocks(from uint64) error {
 			// Check for block request timeouts and demote the responsible peers
 			for _, pid := range d.queue.Expire(blockHardTTL) {
 				if peer := d.peers.Peer(pid); peer != nil {
+					peer.MarkTimeout()
 					peer.Demote()
 					log.Debug("Block delivery timeout", "peer", peer)
 				}
@@ -707,17 +747,18 @@ func (d *Downloader) fetchBlocks(from uint64) error {
 // process takes blocks from the queue and tries to import them into the chain.
 //
 // The algorithmic flow is as follows:
-//  - The `processing` flag is swapped to 1 to ensure singleton access
-//  - The current `cancel` channel is retrieved to detect sync abortions
-//  - Blocks are iteratively taken from the cache and inserted into the chain
-//  - When the cache becomes empty, insertion stops
-//  - The `processing` flag is swapped back to 0
-//  - A post-exit check is made whether new blocks became available
-//     - This step is important: it handles a potential race condition between
-//       checking for no more work, and releasing the processing "mutex". In
-//       between these state changes, a block may have arrived, but a processing
-//       attempt denied, so we need to re-enter to ensure the block isn't left
-//       to idle in the cache.
+//   - The `processing` flag is swapped to 1 to ensure singleton access
+//   - The current `cancel` channel is retrieved to detect sync abortions
+//   - Blocks are iteratively taken from the cache, verified and inserted into the chain.
+//     Verification runs ahead of the insertion by up to maxVerifiedSets sets, see verify
+//   - When the cache becomes empty, insertion stops
+//   - The `processing` flag is swapped back to 0
+//   - A post-exit check is made whether new blocks became available
+//   - This step is important: it handles a potential race condition between
+//     checking for no more work, and releasing the processing "mutex". In
+//     between these state changes, a block may have arrived, but a processing
+//     attempt denied, so we need to re-enter to ensure the block isn't left
+//     to idle in the cache.
 func (d *Downloader) process() {
 	// Make sure only one goroutine is ever allowed to process blocks at once
 	if !atomic.CompareAndSwapInt32(&d.processing, 0, 1) {
@@ -742,39 +783,80 @@ func (d *Downloader) process() {
 
 		atomic.StoreInt32(&d.processing, 0)
 	}()
-	// Repeat the processing as long as there are blocks to import
+	// Verify the blocks in the background while the previous ones are inserted,
+	// the verifier has to stop before the processing flag is released
+	verified := make(chan []*Block, maxVerifiedSets)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		d.verify(verified, done)
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	// Insert the verified blocks in order
+	for blocks := range verified {
+		// Check for any termination requests
+		if atomic.LoadInt32(&d.interrupt) == 1 {
+			return
+		}
+		raw := make([]*nom.DetailedMomentum, 0, len(blocks))
+		for _, block := range blocks {
+			raw = append(raw, block.RawBlock)
+		}
+		// Try to inset the blocks, drop the originating peer if there's an error
+		index, err := d.insertChain(raw)
+		if err != nil {
+			log.Info("Block import failed", "momentum-height", raw[index].Momentum.Height, "reason", err)
+			d.reportPeer(blocks[index].OriginPeer, err)
+			d.dropPeer(blocks[index].OriginPeer)
+			d.cancel()
+			return
+		}
+	}
+}
+
+// verify takes blocks from the queue, verifies them in sets of maxBlockProcess blocks and passes the sets
+// to the insertion through verified, which it closes once the queue is empty. It stops early if done is closed.
+// Verification failures are only logged, the insertion verifies the blocks again and reports the faulty one.
+func (d *Downloader) verify(verified chan<- []*Block, done <-chan struct{}) {
+	defer close(verified)
 	for {
 		// Fetch the next batch of blocks
 		blocks := d.queue.TakeBlocks()
 		if len(blocks) == 0 {
 			return
 		}
-		// Reset the import statistics
+		// Update the import statistics, the verified blocks count as importing
 		d.importLock.Lock()
-		d.importStart = time.Now()
-		d.importQueue = blocks
-		d.importDone = 0
+		if len(d.importQueue) == 0 {
+			d.importStart = time.Now()
+			d.importDone = 0
+		}
+		d.importQueue = append(d.importQueue, blocks...)
 		d.importLock.Unlock()
 
-		// Actually import the blocks
 		log.Info("Inserting side-chain", "num-account-blocks", len(blocks), "start-height", blocks[0].RawBlock.Momentum.Height, "end-height", blocks[len(blocks)-1].RawBlock.Momentum.Height)
 		for len(blocks) != 0 {
 			// Check for any termination requests
 			if atomic.LoadInt32(&d.interrupt) == 1 {
 				return
 			}
-			// Retrieve the first batch of blocks to insert
+			// Retrieve the first batch of blocks to verify
 			max := int(math.Min(float64(len(blocks)), float64(maxBlockProcess)))
 			raw := make([]*nom.DetailedMomentum, 0, max)
 			for _, block := range blocks[:max] {
 				raw = append(raw, block.RawBlock)
 			}
-			// Try to inset the blocks, drop the originating peer if there's an error
-			index, err := d.insertChain(raw)
-			if err != nil {
-				log.Info("Block import failed", "momentum-height", raw[index].Momentum.Height, "reason", err)
-				d.dropPeer(blocks[index].OriginPeer)
-				d.cancel()
+			if err := d.verifyChain(raw); err != nil {
+				log.Info("Block verification failed", "start-height", raw[0].Momentum.Height, "reason", err)
+			}
+			select {
+			case verified <- blocks[:max]:
+			case <-done:
 				return
 			}
 			blocks = blocks[max:]
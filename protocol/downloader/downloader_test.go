@@ -0,0 +1,219 @@
+package downloader
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	errInvalidTestBlock = errors.New("invalid block")
+	errUnverifiedBlock  = errors.New("block wasn't verified")
+)
+
+// downloadTester records the verifications and the insertions of the downloader's import pipeline.
+type downloadTester struct {
+	downloader *Downloader
+
+	lock     sync.Mutex
+	verified map[types.Hash]bool
+	inserted []uint64
+	sets     int // verified sets
+	imports  int // inserted sets
+	maxAhead int // verified sets not inserted yet, at most
+	drops    []string
+	reports  []string
+
+	// insertHook is called before a set is inserted, returning the index of an invalid block or -1
+	insertHook func(blocks []*nom.DetailedMomentum) int
+	// verifyErr is returned by all verifications
+	verifyErr error
+}
+
+func newTester() *downloadTester {
+	tester := &downloadTester{
+		verified: make(map[types.Hash]bool),
+	}
+	tester.downloader = New(tester.hasBlock, tester.getBlock, tester.headBlock, tester.verifyChain, tester.insertChain, tester.dropPeer, tester.reportPeer)
+	return tester
+}
+
+func (dl *downloadTester) hasBlock(types.Hash) bool {
+	return false
+}
+func (dl *downloadTester) getBlock(types.Hash) *nom.DetailedMomentum {
+	return nil
+}
+func (dl *downloadTester) headBlock() *nom.Momentum {
+	return nil
+}
+func (dl *downloadTester) verifyChain(blocks []*nom.DetailedMomentum) error {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+	for _, block := range blocks {
+		dl.verified[block.Momentum.Hash] = true
+	}
+	dl.sets += 1
+	if ahead := dl.sets - dl.imports; ahead > dl.maxAhead {
+		dl.maxAhead = ahead
+	}
+	return dl.verifyErr
+}
+func (dl *downloadTester) insertChain(blocks []*nom.DetailedMomentum) (int, error) {
+	dl.lock.Lock()
+	hook := dl.insertHook
+	dl.lock.Unlock()
+	if hook != nil {
+		if index := hook(blocks); index >= 0 {
+			return index, errInvalidTestBlock
+		}
+	}
+
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+	for i, block := range blocks {
+		if !dl.verified[block.Momentum.Hash] {
+			return i, errUnverifiedBlock
+		}
+		dl.inserted = append(dl.inserted, block.Momentum.Height)
+	}
+	dl.imports += 1
+	return len(blocks), nil
+}
+func (dl *downloadTester) dropPeer(id string) {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+	dl.drops = append(dl.drops, id)
+}
+func (dl *downloadTester) reportPeer(id string, err error) {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+	dl.reports = append(dl.reports, id)
+}
+
+// queueBlocks places n downloaded blocks in the cache of the queue, the ones from height failing
+// onwards are delivered by a second peer.
+func (dl *downloadTester) queueBlocks(n int, failing uint64) {
+	q := dl.downloader.queue
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for i := 0; i < n; i += 1 {
+		momentum := &nom.Momentum{ChainIdentifier: 1, Height: uint64(i + 2)}
+		momentum.Hash = momentum.ComputeHash()
+		origin := "peer"
+		if failing != 0 && momentum.Height >= failing {
+			origin = "faulty"
+		}
+		q.blockCache[i] = &Block{RawBlock: &nom.DetailedMomentum{Momentum: momentum}, OriginPeer: origin}
+		q.blockPool[momentum.Hash] = uint64(i)
+	}
+}
+
+func (dl *downloadTester) insertedHeights() []uint64 {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+	return append([]uint64{}, dl.inserted...)
+}
+
+func checkInsertedInOrder(t *testing.T, inserted []uint64, count int) {
+	if len(inserted) != count {
+		t.Fatalf("inserted %v blocks, expected %v", len(inserted), count)
+	}
+	for i, height := range inserted {
+		if height != uint64(i+2) {
+			t.Fatalf("inserted height %v at position %v, expected %v", height, i, i+2)
+		}
+	}
+}
+
+// Test process
+//   - test all queued blocks are verified before they're inserted, in order
+//   - test the verification doesn't run more than maxVerifiedSets sets ahead of the insertion
+//   - test a failed verification is left for the insertion to report
+func TestDownloader_ProcessOrder(t *testing.T) {
+	tester := newTester()
+	count := 5*maxBlockProcess + 10
+	tester.queueBlocks(count, 0)
+	tester.downloader.process()
+
+	checkInsertedInOrder(t, tester.insertedHeights(), count)
+	// a set is sent once verified, the next one is verified while it waits to be sent
+	if tester.maxAhead > maxVerifiedSets+2 {
+		t.Fatalf("verified %v sets ahead of the insertion, expected at most %v", tester.maxAhead, maxVerifiedSets+2)
+	}
+	if len(tester.drops) != 0 {
+		t.Fatalf("dropped peers %v", tester.drops)
+	}
+	if tester.downloader.queue.GetHeadBlock() != nil {
+		t.Fatal("blocks left in the queue")
+	}
+
+	tester = newTester()
+	tester.verifyErr = errInvalidTestBlock
+	tester.queueBlocks(10, 0)
+	tester.downloader.process()
+	checkInsertedInOrder(t, tester.insertedHeights(), 10)
+}
+
+// Test the import stops at the end of the current set once interrupted, and the verifier stops with it
+func TestDownloader_ProcessInterrupt(t *testing.T) {
+	tester := newTester()
+	tester.queueBlocks(5*maxBlockProcess, 0)
+	tester.insertHook = func([]*nom.DetailedMomentum) int {
+		atomic.StoreInt32(&tester.downloader.interrupt, 1)
+		return -1
+	}
+	tester.downloader.process()
+
+	checkInsertedInOrder(t, tester.insertedHeights(), maxBlockProcess)
+	if processing := atomic.LoadInt32(&tester.downloader.processing); processing != 0 {
+		t.Fatal("processing flag wasn't released")
+	}
+	tester.lock.Lock()
+	defer tester.lock.Unlock()
+	if tester.sets > maxVerifiedSets+2 {
+		t.Fatalf("verified %v sets after the interrupt", tester.sets)
+	}
+}
+
+// Test a block failing the import
+//   - test its origin peer is reported and dropped
+//   - test the sync is canceled, so the blocks after it aren't inserted and the queue is reset
+func TestDownloader_ProcessPeerFailure(t *testing.T) {
+	tester := newTester()
+	failing := uint64(maxBlockProcess + 20)
+	tester.queueBlocks(3*maxBlockProcess, failing)
+	tester.insertHook = func(blocks []*nom.DetailedMomentum) int {
+		for i, block := range blocks {
+			if block.Momentum.Height == failing {
+				return i
+			}
+		}
+		return -1
+	}
+	cancel := make(chan struct{})
+	tester.downloader.cancelCh = cancel
+	tester.downloader.process()
+
+	checkInsertedInOrder(t, tester.insertedHeights(), maxBlockProcess)
+	tester.lock.Lock()
+	defer tester.lock.Unlock()
+	if len(tester.reports) != 1 || tester.reports[0] != "faulty" {
+		t.Fatalf("reported %v, expected the faulty peer", tester.reports)
+	}
+	if len(tester.drops) != 1 || tester.drops[0] != "faulty" {
+		t.Fatalf("dropped %v, expected the faulty peer", tester.drops)
+	}
+	select {
+	case <-cancel:
+	default:
+		t.Fatal("sync wasn't canceled")
+	}
+	if tester.downloader.queue.GetHeadBlock() != nil {
+		t.Fatal("queue wasn't reset")
+	}
+}
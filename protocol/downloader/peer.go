@@ -55,6 +55,11 @@ type peer struct {
 
 	ignored *set.Set // Set of hashes not to request (didn't have previously)
 
+	delivered    uint64 // Number of blocks successfully delivered by the peer
+	timeouts     uint64 // Number of block requests the peer failed to answer in time
+	failures     uint64 // Number of empty, stale or partially invalid deliveries
+	lastDelivery int64  // Unix nano time of the last successful delivery
+
 	getRelHashes relativeHashFetcherFn // Method to retrieve a batch of hashes from an origin hash
 	getAbsHashes absoluteHashFetcherFn // Method to retrieve a batch of hashes from an absolute position
 	getBlocks    blockFetcherFn        // Method to retrieve a batch of blocks
@@ -157,6 +162,39 @@ func (p *peer) Demote() {
 	}
 }
 
+// MarkDelivered records a successful delivery of the given number of blocks.
+func (p *peer) MarkDelivered(blocks int) {
+	atomic.AddUint64(&p.delivered, uint64(blocks))
+	atomic.StoreInt64(&p.lastDelivery, time.Now().UnixNano())
+}
+
+// MarkTimeout records a block request which expired before being delivered.
+func (p *peer) MarkTimeout() {
+	atomic.AddUint64(&p.timeouts, 1)
+}
+
+// MarkFailure records a delivery which couldn't be used.
+func (p *peer) MarkFailure() {
+	atomic.AddUint64(&p.failures, 1)
+}
+
+// Stats retrieves a snapshot of the download statistics of the peer.
+func (p *peer) Stats() PeerStats {
+	stats := PeerStats{
+		Id:         p.id,
+		Reputation: int(atomic.LoadInt32(&p.rep)),
+		Capacity:   int(atomic.LoadInt32(&p.capacity)),
+		Idle:       atomic.LoadInt32(&p.idle) == 0,
+		Delivered:  atomic.LoadUint64(&p.delivered),
+		Timeouts:   atomic.LoadUint64(&p.timeouts),
+		Failures:   atomic.LoadUint64(&p.failures),
+	}
+	if last := atomic.LoadInt64(&p.lastDelivery); last != 0 {
+		stats.LastDelivery = time.Unix(0, last)
+	}
+	return stats
+}
+
 // String implements fmt.Stringer.
 func (p *peer) String() string {
 	return fmt.Sprintf("Peer %s [%s]", p.id,
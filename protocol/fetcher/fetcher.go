@@ -33,6 +33,7 @@ import (
 
 const (
 	arriveTimeout = 500 * time.Millisecond // Time allowance before an announced block is explicitly requested
+	fetchDelay    = gatherSlack            // Time allowance before a block announced without being pushed is requested
 	gatherSlack   = 100 * time.Millisecond // Interval used to collate almost-expired announces with fetches
 	fetchTimeout  = 5 * time.Second        // Maximum alloted time to return an explicitly requested block
 	maxUncleDist  = 7                      // Maximum allowed backward distance from the chain head
@@ -67,11 +68,15 @@ type chainInsertFn func([]*nom.DetailedMomentum) (int, error)
 // peerDropFn is a callback type for dropping a peer detected as malicious.
 type peerDropFn func(id string)
 
+// peerReportFn is a callback type for reporting a peer whose block failed to import.
+type peerReportFn func(id string, err error)
+
 // announce is the hash notification of the availability of a new block in the
 // network.
 type announce struct {
-	hash types.Hash // Hash of the block being announced
-	time time.Time  // Timestamp of the announcement
+	hash types.Hash    // Hash of the block being announced
+	time time.Time     // Timestamp of the announcement
+	wait time.Duration // Time allowance before the block is explicitly requested
 
 	origin string           // Header of the peer originating the notification
 	fetch  blockRequesterFn // Fetcher function to retrieve
@@ -110,6 +115,7 @@ type Fetcher struct {
 	chainHeight    chainHeightFn      // Retrieves the current chain's height
 	insertChain    chainInsertFn      // Injects a batch of blocks into the chain
 	dropPeer       peerDropFn         // Drops a peer for misbehaving
+	reportPeer     peerReportFn       // Reports a peer whose block failed to import
 
 	// Testing hooks
 	fetchingHook func([]types.Hash)  // Method to call upon starting a block fetch
@@ -119,7 +125,7 @@ type Fetcher struct {
 }
 
 // New creates a block fetcher to retrieve blocks based on hash announcements.
-func New(getBlock blockRetrievalFn, validateBlock blockValidatorFn, broadcastBlock blockBroadcasterFn, chainHeight chainHeightFn, insertChain chainInsertFn, dropPeer peerDropFn) *Fetcher {
+func New(getBlock blockRetrievalFn, validateBlock blockValidatorFn, broadcastBlock blockBroadcasterFn, chainHeight chainHeightFn, insertChain chainInsertFn, dropPeer peerDropFn, reportPeer peerReportFn) *Fetcher {
 	return &Fetcher{
 		notify:         make(chan *announce),
 		inject:         make(chan *inject),
@@ -138,6 +144,7 @@ func New(getBlock blockRetrievalFn, validateBlock blockValidatorFn, broadcastBlo
 		chainHeight:    chainHeight,
 		insertChain:    insertChain,
 		dropPeer:       dropPeer,
+		reportPeer:     reportPeer,
 	}
 }
 
@@ -159,12 +166,28 @@ func (f *Fetcher) Stop() {
 // Notify announces the fetcher of the potential availability of a new block in
 // the network.
 func (f *Fetcher) Notify(peer string, hash types.Hash, time time.Time, fetcher blockRequesterFn) error {
-	block := &announce{
+	return f.notifyAnnounce(&announce{
 		hash:   hash,
 		time:   time,
+		wait:   arriveTimeout,
 		origin: peer,
 		fetch:  fetcher,
-	}
+	})
+}
+
+// Announce is like Notify for announcements which are not followed by the block
+// itself, so it is requested right away instead of waiting for it to arrive.
+func (f *Fetcher) Announce(peer string, hash types.Hash, time time.Time, fetcher blockRequesterFn) error {
+	return f.notifyAnnounce(&announce{
+		hash:   hash,
+		time:   time,
+		wait:   fetchDelay,
+		origin: peer,
+		fetch:  fetcher,
+	})
+}
+
+func (f *Fetcher) notifyAnnounce(block *announce) error {
 	select {
 	case f.notify <- block:
 		return nil
@@ -264,7 +287,8 @@ func (f *Fetcher) loop() {
 			}
 			f.announces[notification.origin] = count
 			f.announced[notification.hash] = append(f.announced[notification.hash], notification)
-			if len(f.announced) == 1 {
+			// the new announcement may expire before the pending ones
+			if len(f.announced[notification.hash]) == 1 {
 				f.reschedule(fetch)
 			}
 
@@ -282,7 +306,7 @@ func (f *Fetcher) loop() {
 			request := make(map[string][]types.Hash)
 
 			for hash, announces := range f.announced {
-				if time.Since(announces[0].time) > arriveTimeout-gatherSlack {
+				if time.Since(announces[0].time) > announces[0].wait-gatherSlack {
 					// Pick a random peer to retrieve from, reset all others
 					announce := announces[rand.Intn(len(announces))]
 					f.forgetHash(hash)
@@ -367,13 +391,13 @@ func (f *Fetcher) reschedule(fetch *time.Timer) {
 		return
 	}
 	// Otherwise find the earliest expiring announcement
-	earliest := time.Now()
+	var earliest time.Time
 	for _, announces := range f.announced {
-		if earliest.After(announces[0].time) {
-			earliest = announces[0].time
+		if expiry := announces[0].time.Add(announces[0].wait); earliest.IsZero() || earliest.After(expiry) {
+			earliest = expiry
 		}
 	}
-	fetch.Reset(arriveTimeout - time.Since(earliest))
+	fetch.Reset(time.Until(earliest))
 }
 
 // enqueue schedules a new future import operation, if the block to be imported
@@ -441,6 +465,7 @@ func (f *Fetcher) insert(peer string, detailed *nom.DetailedMomentum) {
 		f.wg.Add(1)
 		if _, err := f.insertChain([]*nom.DetailedMomentum{detailed}); err != nil {
 			log.Warn("momentum import failed", "peer", peer, "momentum", momentum.Height, "hash", hash[:4], "reason", err)
+			f.reportPeer(peer, err)
 			f.wg.Done()
 			return
 		} else {
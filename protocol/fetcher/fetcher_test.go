@@ -0,0 +1,310 @@
+package fetcher
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var errInvalidTestMomentum = errors.New("invalid momentum")
+
+// makeChain returns n momentums after parent, linked by their hashes.
+func makeChain(n int, parent *nom.DetailedMomentum) []*nom.DetailedMomentum {
+	chain := make([]*nom.DetailedMomentum, n)
+	previous := parent.Momentum
+	for i := range chain {
+		momentum := &nom.Momentum{
+			ChainIdentifier: 1,
+			PreviousHash:    previous.Hash,
+			Height:          previous.Height + 1,
+			TimestampUnix:   previous.TimestampUnix + 10,
+		}
+		momentum.Hash = momentum.ComputeHash()
+		chain[i] = &nom.DetailedMomentum{Momentum: momentum}
+		previous = momentum
+	}
+	return chain
+}
+
+// fetcherTester is a local chain and the peers which announce the momentums to its fetcher.
+type fetcherTester struct {
+	fetcher *Fetcher
+
+	lock    sync.Mutex
+	blocks  map[types.Hash]*nom.DetailedMomentum
+	height  uint64
+	invalid map[types.Hash]bool
+	drops   map[string]bool
+}
+
+func newTester(genesis *nom.DetailedMomentum) *fetcherTester {
+	tester := &fetcherTester{
+		blocks:  map[types.Hash]*nom.DetailedMomentum{genesis.Momentum.Hash: genesis},
+		height:  genesis.Momentum.Height,
+		invalid: make(map[types.Hash]bool),
+		drops:   make(map[string]bool),
+	}
+	tester.fetcher = New(tester.getBlock, tester.validateBlock, func(*nom.DetailedMomentum, bool) {}, tester.chainHeight, tester.insertChain, tester.dropPeer, func(string, error) {})
+	tester.fetcher.Start()
+	return tester
+}
+
+func (f *fetcherTester) getBlock(hash types.Hash) *nom.DetailedMomentum {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.blocks[hash]
+}
+func (f *fetcherTester) validateBlock(momentum *nom.Momentum, parent *nom.Momentum) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.invalid[momentum.Hash] {
+		return errInvalidTestMomentum
+	}
+	return nil
+}
+func (f *fetcherTester) chainHeight() uint64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.height
+}
+func (f *fetcherTester) insertChain(blocks []*nom.DetailedMomentum) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i, block := range blocks {
+		if _, ok := f.blocks[block.Momentum.PreviousHash]; !ok {
+			return i, errors.New("unknown parent")
+		}
+		f.blocks[block.Momentum.Hash] = block
+		f.height = block.Momentum.Height
+	}
+	return len(blocks), nil
+}
+func (f *fetcherTester) dropPeer(peer string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.drops[peer] = true
+}
+func (f *fetcherTester) dropped(peer string) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.drops[peer]
+}
+
+// makeFetcher returns a requester which delivers the momentums of blocks back to the fetcher.
+func (f *fetcherTester) makeFetcher(blocks []*nom.DetailedMomentum) blockRequesterFn {
+	known := make(map[types.Hash]*nom.DetailedMomentum, len(blocks))
+	for _, block := range blocks {
+		known[block.Momentum.Hash] = block
+	}
+	return func(hashes []types.Hash) error {
+		delivery := make([]*nom.DetailedMomentum, 0, len(hashes))
+		for _, hash := range hashes {
+			if block, ok := known[hash]; ok {
+				delivery = append(delivery, block)
+			}
+		}
+		go f.fetcher.Filter(delivery)
+		return nil
+	}
+}
+
+// hooks returns the channels on which the fetches and the imports are reported.
+func (f *fetcherTester) hooks() (chan []types.Hash, chan *nom.Momentum) {
+	fetching := make(chan []types.Hash, 16)
+	imported := make(chan *nom.Momentum, 16)
+	f.fetcher.fetchingHook = func(hashes []types.Hash) { fetching <- hashes }
+	f.fetcher.importedHook = func(momentum *nom.Momentum) { imported <- momentum }
+	return fetching, imported
+}
+
+func verifyImport(t *testing.T, imported chan *nom.Momentum, expected *nom.Momentum) {
+	select {
+	case momentum := <-imported:
+		if momentum.Hash != expected.Hash {
+			t.Fatalf("imported momentum %v, expected %v", momentum.Height, expected.Height)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("momentum %v wasn't imported", expected.Height)
+	}
+}
+
+func verifyNoImport(t *testing.T, imported chan *nom.Momentum) {
+	select {
+	case momentum := <-imported:
+		t.Fatalf("unexpected import of momentum %v", momentum.Height)
+	case <-time.After(2 * arriveTimeout):
+	}
+}
+
+func verifyFetch(t *testing.T, fetching chan []types.Hash, expected types.Hash, within time.Duration) {
+	select {
+	case hashes := <-fetching:
+		if len(hashes) != 1 || hashes[0] != expected {
+			t.Fatalf("fetched %v, expected %v", hashes, expected)
+		}
+	case <-time.After(within):
+		t.Fatalf("momentum %v wasn't fetched", expected)
+	}
+}
+
+func testGenesis() *nom.DetailedMomentum {
+	genesis := &nom.Momentum{ChainIdentifier: 1, Height: 1, TimestampUnix: 1600000000}
+	genesis.Hash = genesis.ComputeHash()
+	return &nom.DetailedMomentum{Momentum: genesis}
+}
+
+// Test Announce
+//   - test the momentums announced without their bodies are fetched and imported in order
+//   - test they are requested before the arrival timeout of the pushed momentums
+func TestFetcher_Announce(t *testing.T) {
+	genesis := testGenesis()
+	chain := makeChain(5, genesis)
+	tester := newTester(genesis)
+	defer tester.fetcher.Stop()
+	fetching, imported := tester.hooks()
+	requester := tester.makeFetcher(chain)
+
+	for _, block := range chain {
+		if err := tester.fetcher.Announce("peer", block.Momentum.Hash, time.Now(), requester); err != nil {
+			t.Fatal(err)
+		}
+		verifyFetch(t, fetching, block.Momentum.Hash, arriveTimeout)
+		verifyImport(t, imported, block.Momentum)
+	}
+	if height := tester.chainHeight(); height != chain[len(chain)-1].Momentum.Height {
+		t.Fatalf("chain height %v, expected %v", height, chain[len(chain)-1].Momentum.Height)
+	}
+}
+
+// Test Notify
+//   - test the momentums announced and pushed by a peer aren't fetched
+//   - test the ones announced by several peers are fetched once
+func TestFetcher_Notify(t *testing.T) {
+	genesis := testGenesis()
+	chain := makeChain(2, genesis)
+	tester := newTester(genesis)
+	defer tester.fetcher.Stop()
+	fetching, imported := tester.hooks()
+	requester := tester.makeFetcher(chain)
+
+	if err := tester.fetcher.Notify("peer", chain[0].Momentum.Hash, time.Now(), requester); err != nil {
+		t.Fatal(err)
+	}
+	tester.lock.Lock()
+	tester.blocks[chain[0].Momentum.Hash] = chain[0]
+	tester.height = chain[0].Momentum.Height
+	tester.lock.Unlock()
+	select {
+	case hashes := <-fetching:
+		t.Fatalf("fetched %v which was pushed", hashes)
+	case <-time.After(2 * arriveTimeout):
+	}
+
+	for _, peer := range []string{"first", "second", "third"} {
+		if err := tester.fetcher.Notify(peer, chain[1].Momentum.Hash, time.Now(), requester); err != nil {
+			t.Fatal(err)
+		}
+	}
+	verifyFetch(t, fetching, chain[1].Momentum.Hash, 2*arriveTimeout)
+	verifyImport(t, imported, chain[1].Momentum)
+	select {
+	case hashes := <-fetching:
+		t.Fatalf("fetched %v again", hashes)
+	case <-time.After(2 * arriveTimeout):
+	}
+}
+
+// Test fetch timeout
+//   - test a momentum which is requested but never delivered is forgotten after fetchTimeout
+//   - test it's fetched again once announced after the timeout
+func TestFetcher_Timeout(t *testing.T) {
+	genesis := testGenesis()
+	chain := makeChain(1, genesis)
+	tester := newTester(genesis)
+	defer tester.fetcher.Stop()
+	fetching, imported := tester.hooks()
+	hash := chain[0].Momentum.Hash
+
+	// the announcement is old enough for the fetch to expire right away
+	if err := tester.fetcher.Announce("silent", hash, time.Now().Add(-fetchTimeout), tester.makeFetcher(nil)); err != nil {
+		t.Fatal(err)
+	}
+	verifyFetch(t, fetching, hash, arriveTimeout)
+	verifyNoImport(t, imported)
+
+	if err := tester.fetcher.Announce("peer", hash, time.Now(), tester.makeFetcher(chain)); err != nil {
+		t.Fatal(err)
+	}
+	verifyFetch(t, fetching, hash, arriveTimeout)
+	verifyImport(t, imported, chain[0].Momentum)
+}
+
+// Test the momentums which are still being fetched aren't requested again before fetchTimeout
+func TestFetcher_Fetching(t *testing.T) {
+	genesis := testGenesis()
+	chain := makeChain(1, genesis)
+	tester := newTester(genesis)
+	defer tester.fetcher.Stop()
+	fetching, _ := tester.hooks()
+	hash := chain[0].Momentum.Hash
+
+	if err := tester.fetcher.Announce("silent", hash, time.Now(), tester.makeFetcher(nil)); err != nil {
+		t.Fatal(err)
+	}
+	verifyFetch(t, fetching, hash, arriveTimeout)
+	if err := tester.fetcher.Announce("peer", hash, time.Now(), tester.makeFetcher(chain)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case hashes := <-fetching:
+		t.Fatalf("fetched %v again before the timeout", hashes)
+	case <-time.After(2 * arriveTimeout):
+	}
+}
+
+// Test the peers delivering an invalid momentum are dropped and the momentum isn't imported
+func TestFetcher_InvalidMomentum(t *testing.T) {
+	genesis := testGenesis()
+	chain := makeChain(1, genesis)
+	tester := newTester(genesis)
+	defer tester.fetcher.Stop()
+	fetching, imported := tester.hooks()
+	tester.invalid[chain[0].Momentum.Hash] = true
+
+	if err := tester.fetcher.Announce("malicious", chain[0].Momentum.Hash, time.Now(), tester.makeFetcher(chain)); err != nil {
+		t.Fatal(err)
+	}
+	verifyFetch(t, fetching, chain[0].Momentum.Hash, arriveTimeout)
+	verifyNoImport(t, imported)
+	if !tester.dropped("malicious") {
+		t.Fatal("peer delivering an invalid momentum wasn't dropped")
+	}
+}
+
+// Test a peer can't have more than hashLimit announcements pending
+func TestFetcher_HashLimit(t *testing.T) {
+	genesis := testGenesis()
+	chain := makeChain(hashLimit+1, genesis)
+	tester := newTester(genesis)
+	defer tester.fetcher.Stop()
+
+	// the parents are never fetched, so the announcements stay pending
+	requester := func([]types.Hash) error { return nil }
+	for _, block := range chain {
+		if err := tester.fetcher.Announce("attacker", block.Momentum.Hash, time.Now().Add(time.Hour), requester); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the events are processed one at a time, so the announcements are handled once the filter returns
+	tester.fetcher.Filter(nil)
+	if count := tester.fetcher.announces["attacker"]; count != hashLimit {
+		t.Fatalf("attacker has %v pending announcements, expected %v", count, hashLimit)
+	}
+	if _, ok := tester.fetcher.announced[chain[hashLimit].Momentum.Hash]; ok {
+		t.Fatal("announcement past the limit was scheduled")
+	}
+}
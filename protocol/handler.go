@@ -22,13 +22,13 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/rlp"
-
+	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/p2p"
 	"github.com/zenon-network/go-zenon/protocol/downloader"
 	"github.com/zenon-network/go-zenon/protocol/fetcher"
+	"github.com/zenon-network/go-zenon/verifier"
 )
 
 func errResp(code errCode, format string, v ...interface{}) error {
@@ -39,12 +39,18 @@ type ProtocolManager struct {
 	minPeers       int
 	protVer, netId int
 
+	// diversityPercent of the momentum propagation slots go to peers from distinct networks
+	// instead of the ones with the lowest latency.
+	diversityPercent int
+
 	txpool   txPool
 	chainman chainManager
 
 	downloader *downloader.Downloader
 	fetcher    *fetcher.Fetcher
 	peers      *peerSet
+	progress   *syncProgress
+	penalties  *peerPenalties
 
 	SubProtocols []p2p.Protocol
 
@@ -61,17 +67,20 @@ type ProtocolManager struct {
 
 // NewProtocolManager returns a new ethereum sub protocol manager. The Ethereum sub protocol manages peers capable
 // with the ethereum network.
-func NewProtocolManager(minPeers int, networkId uint64, bridge ChainBridge) *ProtocolManager {
+func NewProtocolManager(minPeers, diversityPercent int, networkId uint64, bridge ChainBridge) *ProtocolManager {
 	// Create the protocol manager with the base fields
 	manager := &ProtocolManager{
-		minPeers:  minPeers,
-		txpool:    bridge,
-		chainman:  bridge,
-		peers:     newPeerSet(),
-		newPeerCh: make(chan *peer, 1),
-		txsyncCh:  make(chan *txsync),
-		quitSync:  make(chan struct{}),
-		netId:     int(networkId),
+		minPeers:         minPeers,
+		diversityPercent: diversityPercent,
+		txpool:           bridge,
+		chainman:         bridge,
+		peers:            newPeerSet(),
+		penalties:        newPeerPenalties(),
+		progress:         &syncProgress{},
+		newPeerCh:        make(chan *peer, 1),
+		txsyncCh:         make(chan *txsync),
+		quitSync:         make(chan struct{}),
+		netId:            int(networkId),
 	}
 	// Initiate a sub-protocol for every implemented version we can handle
 	manager.SubProtocols = make([]p2p.Protocol, len(ProtocolVersions))
@@ -87,6 +96,7 @@ func NewProtocolManager(minPeers int, networkId uint64, bridge ChainBridge) *Pro
 				manager.newPeerCh <- peer
 				return manager.handle(peer)
 			},
+			Priority: msgPriority,
 		}
 	}
 	// Construct the different synchronisation mechanisms
@@ -94,8 +104,10 @@ func NewProtocolManager(minPeers int, networkId uint64, bridge ChainBridge) *Pro
 		manager.chainman.HasBlock,
 		manager.chainman.GetBlock,
 		manager.chainman.CurrentBlock,
+		manager.chainman.VerifyChain,
 		manager.chainman.InsertChain,
-		manager.removePeer)
+		manager.removePeer,
+		manager.reportPeer)
 
 	validator := func(block *nom.Momentum, parent *nom.Momentum) error {
 		//return core.ValidateHeader(pow, block.Headerr(), parent, true)
@@ -111,7 +123,8 @@ func NewProtocolManager(minPeers int, networkId uint64, bridge ChainBridge) *Pro
 		manager.BroadcastMomentum,
 		heighter,
 		manager.chainman.InsertChain,
-		manager.removePeer)
+		manager.removePeer,
+		manager.reportPeer)
 
 	return manager
 }
@@ -135,6 +148,21 @@ func (pm *ProtocolManager) removePeer(id string) {
 	}
 }
 
+// reportPeer penalizes the peer id if err proves that it propagated an invalid momentum. Peers reaching
+// maxPeerPenalty are disconnected and refused for peerBanDuration.
+func (pm *ProtocolManager) reportPeer(id string, err error) {
+	if !verifier.IsInvalidMomentum(err) {
+		return
+	}
+	invalidMomentumCounter.Inc(1)
+	log.Info("peer propagated invalid momentum", "peer-id", id, "reason", err)
+	if pm.penalties.penalize(id, invalidMomentumPenalty) {
+		bannedPeerCounter.Inc(1)
+		log.Warn("banning peer for propagating invalid momentums", "peer-id", id, "duration", peerBanDuration)
+		pm.removePeer(id)
+	}
+}
+
 func (pm *ProtocolManager) Start() {
 	// start sync handlers
 	pm.wg.Add(1)
@@ -172,6 +200,11 @@ func (pm *ProtocolManager) newPeer(pv, nv int, p *p2p.Peer, rw p2p.MsgReadWriter
 // this function terminates, the peer is disconnected.
 func (pm *ProtocolManager) handle(p *peer) error {
 	log.Info("peer connected", "peer-id", p.id, "address", p.RemoteAddr().String(), "name", p.Name())
+	if pm.penalties.isBanned(p.id) {
+		refusedPeerCounter.Inc(1)
+		log.Info("refused banned peer", "peer-id", p.id)
+		return errResp(ErrSuspendedPeer, "peer %v is banned", p.id)
+	}
 
 	// Execute the Ethereum handshake
 	td, head, genesis := pm.chainman.Status()
@@ -215,6 +248,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, ProtocolMaxMsgSize)
 	}
 	defer msg.Discard()
+	if !p.supports(msg.Code) {
+		return errResp(ErrInvalidMsgCode, "%v not supported by eth/%d", msg.Code, p.version)
+	}
 
 	// Handle the message depending on its contents
 	switch msg.Code {
@@ -225,8 +261,8 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	case GetBlockHashesMsg:
 		// Retrieve the number of hashes to return and from which origin hash
 		var request getBlockHashesData
-		if err := msg.Decode(&request); err != nil {
-			return errResp(ErrDecode, "%v: %v", msg, err)
+		if err := pm.decode(p, msg, &request); err != nil {
+			return err
 		}
 		if request.Amount > uint64(downloader.MaxHashFetch) {
 			request.Amount = uint64(downloader.MaxHashFetch)
@@ -244,8 +280,8 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	case GetBlockHashesFromNumberMsg:
 		// Retrieve and decode the number of hashes to return and from which origin number
 		var request getBlockHashesFromNumberData
-		if err := msg.Decode(&request); err != nil {
-			return errResp(ErrDecode, "%v: %v", msg, err)
+		if err := pm.decode(p, msg, &request); err != nil {
+			return err
 		}
 
 		if request.Amount > uint64(downloader.MaxHashFetch) {
@@ -276,11 +312,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 	case BlockHashesMsg:
 		// A batch of hashes arrived to one of our previous requests
-		msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
-
 		var hashes []types.Hash
-		if err := msgStream.Decode(&hashes); err != nil {
-			break
+		if err := pm.decode(p, msg, &hashes); err != nil {
+			return err
 		}
 
 		// Deliver them all to the downloader for queuing
@@ -291,25 +325,13 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 	case GetBlocksMsg:
 		// Decode the retrieval message
-		msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
-		if _, err := msgStream.List(); err != nil {
+		var hashes []types.Hash
+		if err := pm.decode(p, msg, &hashes); err != nil {
 			return err
 		}
 		// Gather blocks until the fetch or network limits is reached
-		var (
-			hash   types.Hash
-			hashes []types.Hash
-			blocks []*nom.DetailedMomentum
-		)
-		for {
-			err := msgStream.Decode(&hash)
-			if err == rlp.EOL {
-				break
-			} else if err != nil {
-				return errResp(ErrDecode, "msg %v: %v", msg, err)
-			}
-			hashes = append(hashes, hash)
-
+		var blocks []*nom.DetailedMomentum
+		for _, hash := range hashes {
 			// Retrieve the requested block, stopping if enough was found
 			if block := pm.chainman.GetBlock(hash); block != nil {
 				blocks = append(blocks, block)
@@ -332,14 +354,15 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 	case BlocksMsg:
 		// Decode the arrived block message
-		msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
-
 		var blocks []*nom.DetailedMomentum
-		if err := msgStream.Decode(&blocks); err != nil {
-			log.Debug("failed to decode momentum", "reason", err)
-			blocks = nil
+		if err := pm.decode(p, msg, &blocks); err != nil {
 			return err
 		}
+		for _, block := range blocks {
+			if err := checkMomentumPayload(block); err != nil {
+				return err
+			}
+		}
 
 		hashes := make([]types.Hash, len(blocks))
 		for i, block := range blocks {
@@ -356,11 +379,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 	case NewBlockHashesMsg:
 		// Retrieve and deseralize the remote new block hashes notification
-		msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
-
 		var hashes []types.Hash
-		if err := msgStream.Decode(&hashes); err != nil {
-			break
+		if err := pm.decode(p, msg, &hashes); err != nil {
+			return err
 		}
 
 		// Mark the hashes as present at the remote node
@@ -379,11 +400,46 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			pm.fetcher.Notify(p.id, hash, time.Now(), p.RequestBlocks)
 		}
 
+	case NewMomentumHashesMsg:
+		var identifiers []types.HashHeight
+		if err := pm.decode(p, msg, &identifiers); err != nil {
+			return err
+		}
+		// Mark the momentums as present at the remote node
+		for _, identifier := range identifiers {
+			p.MarkBlock(identifier.Hash)
+			p.SetHead(identifier.Hash)
+		}
+
+		if pm.SyncInfo().State == SyncDone {
+			// The bodies are not pushed anymore, fetch the unknown momentums right away
+			for _, identifier := range identifiers {
+				if !pm.chainman.HasBlock(identifier.Hash) {
+					pm.fetcher.Announce(p.id, identifier.Hash, time.Now(), p.RequestBlocks)
+				}
+			}
+			// Only the next momentum is imported by the fetcher, download the gap otherwise
+			current := pm.chainman.CurrentBlock().Height
+			for _, identifier := range identifiers {
+				if identifier.Height > p.Td() {
+					p.SetTd(identifier.Height)
+				}
+			}
+			if p.Td() > current+1 {
+				go func() {
+					pm.synchronise(p)
+				}()
+			}
+		}
+
 	case NewBlockMsg:
 		// Retrieve and decode the propagated block
-		var detailed *nom.DetailedMomentum
-		if err := msg.Decode(&detailed); err != nil {
-			return errResp(ErrDecode, "%v: %v", msg, err)
+		detailed := new(nom.DetailedMomentum)
+		if err := pm.decode(p, msg, detailed); err != nil {
+			return err
+		}
+		if err := checkMomentumPayload(detailed); err != nil {
+			return err
 		}
 
 		detailed.Momentum.EnsureCache()
@@ -404,17 +460,66 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 		}
 
+	case GetMomentumHeadersMsg:
+		var request getMomentumHeadersData
+		if err := pm.decode(p, msg, &request); err != nil {
+			return err
+		}
+		if request.Amount > MaxHeaderFetch {
+			request.Amount = MaxHeaderFetch
+		}
+		momentums := make([]*nom.Momentum, 0, request.Amount)
+		for height := request.Height; height < request.Height+request.Amount; height += 1 {
+			momentum, err := pm.chainman.GetBlockByNumber(height)
+			if err != nil {
+				return err
+			}
+			if momentum == nil {
+				break
+			}
+			momentums = append(momentums, momentum)
+		}
+		return p.SendMomentumHeaders(momentums)
+
+	case GetAccountProofsMsg:
+		var request getAccountProofsData
+		if err := pm.decode(p, msg, &request); err != nil {
+			return err
+		}
+		proofs, err := pm.chainman.GetAccountProofs(request.Momentum, request.Addresses)
+		if err != nil {
+			return err
+		}
+		return p.SendAccountProofs(proofs)
+
+	case GetProducersMsg:
+		var request getProducersData
+		if err := pm.decode(p, msg, &request); err != nil {
+			return err
+		}
+		producers, err := pm.chainman.GetProducers(request.Tick)
+		if err != nil {
+			return err
+		}
+		return p.SendProducers(request.Tick, producers)
+
+	case MomentumHeadersMsg, AccountProofsMsg, ProducersMsg:
+		// only requested by light clients
+
 	case TxMsg:
 		// Transactions arrived, parse all of them and deliver to the pool
 		var txs []*nom.AccountBlock
-		if err := msg.Decode(&txs); err != nil {
-			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		if err := pm.decode(p, msg, &txs); err != nil {
+			return err
 		}
 		for i, tx := range txs {
 			// Validate and mark the remote transaction
 			if tx == nil {
 				return errResp(ErrDecode, "transaction %d is nil", i)
 			}
+			if err := checkAccountBlockPayload(tx); err != nil {
+				return err
+			}
 			p.MarkTransaction(tx.Hash)
 		}
 		pm.wg.Add(1)
@@ -426,6 +531,52 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 	return nil
 }
 
+// decode decodes the payload of msg into val, see DecodeMessage. Peers sending malformed messages
+// are penalized, so the ones reconnecting to send more of them get banned.
+func (pm *ProtocolManager) decode(p *peer, msg p2p.Msg, val interface{}) error {
+	err := decodeMsg(msg, val)
+	if err == nil {
+		return nil
+	}
+	rejectedMsgCounter.Inc(1)
+	log.Info("peer sent malformed message", "peer-id", p.id, "code", msg.Code, "reason", err)
+	if pm.penalties.reject(p.id) {
+		bannedPeerCounter.Inc(1)
+		log.Warn("banning peer for sending malformed messages", "peer-id", p.id, "duration", peerBanDuration)
+	}
+	return err
+}
+
+// checkMomentumPayload drops momentums which exceed the largest payload limits known by the node.
+// The limits enforced at the height of the momentum are checked by the verifier.
+func checkMomentumPayload(detailed *nom.DetailedMomentum) error {
+	if detailed == nil || detailed.Momentum == nil {
+		return errResp(ErrDecode, "momentum is nil")
+	}
+	limits := chain.MaxPayloadLimits()
+	if len(detailed.Momentum.Content) > limits.MaxAccountBlocksInMomentum {
+		return errResp(ErrPayloadTooLarge, "momentum content %v > %v", len(detailed.Momentum.Content), limits.MaxAccountBlocksInMomentum)
+	}
+	for _, block := range detailed.AccountBlocks {
+		if err := checkAccountBlockPayload(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAccountBlockPayload drops account-blocks which exceed the largest payload limits known by the node.
+func checkAccountBlockPayload(block *nom.AccountBlock) error {
+	if block == nil {
+		return errResp(ErrDecode, "account-block is nil")
+	}
+	limits := chain.MaxPayloadLimits()
+	if chain.IsDataLimited(block) && len(block.Data) > limits.MaxDataLength {
+		return errResp(ErrPayloadTooLarge, "account-block data %v > %v", len(block.Data), limits.MaxDataLength)
+	}
+	return nil
+}
+
 // BroadcastMomentum will  propagate a block to a subset of it's peers, or
 // will only announce it's availability (depending what's requested).
 func (pm *ProtocolManager) BroadcastMomentum(detailed *nom.DetailedMomentum, propagate bool) {
@@ -434,12 +585,16 @@ func (pm *ProtocolManager) BroadcastMomentum(detailed *nom.DetailedMomentum, pro
 
 	// If propagation is requested, send to a subset of the peer
 	if propagate {
-		numPeers := len(peers)
+		// Trusted peers, like the sentry nodes of a pillar, always get the momentum. The others
+		// only get it from peers before eth/65, from then on they fetch it once it's announced.
+		transfer, others := splitTrustedPeers(peers)
+		others = legacyPropagationPeers(others)
+		numPeers := len(others)
 		if numPeers > 10 {
 			numPeers = int(math.Sqrt(float64(numPeers-10))) + 10
 		}
-		// Send the block to a subset of our peers
-		transfer := peers[:numPeers]
+		// Send the block to a subset of our peers, preferring the low latency ones
+		transfer = append(transfer, selectPropagationPeers(others, numPeers, pm.diversityPercent)...)
 		for _, p := range transfer {
 			if err := p.SendNewMomentum(detailed); err != nil {
 				log.Debug("failed to propagated momentum", "peer-id", p.id, "reason", err)
@@ -450,8 +605,16 @@ func (pm *ProtocolManager) BroadcastMomentum(detailed *nom.DetailedMomentum, pro
 
 	// Otherwise if the block is indeed in out own chain, announce it
 	if pm.chainman.HasBlock(hash) {
+		// skip the peers which just got the momentum
+		peers = pm.peers.PeersWithoutBlock(hash)
 		for _, p := range peers {
-			if err := p.SendNewBlockHashes([]types.Hash{hash}); err != nil {
+			var err error
+			if p.announcesByHash() {
+				err = p.SendNewMomentumHashes([]types.HashHeight{detailed.Momentum.Identifier()})
+			} else {
+				err = p.SendNewBlockHashes([]types.Hash{hash})
+			}
+			if err != nil {
 				log.Debug("failed to announce momentum", "peer-id", p.id, "reason", err)
 			}
 		}
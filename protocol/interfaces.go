@@ -23,6 +23,30 @@ type SyncInfo struct {
 	State         SyncState `json:"state"`
 	CurrentHeight uint64    `json:"currentHeight"`
 	TargetHeight  uint64    `json:"targetHeight"`
+
+	// MomentumsPerSecond is the recent momentum import rate.
+	MomentumsPerSecond float64 `json:"momentumsPerSecond"`
+	// EstimatedCompletion is the unix timestamp at which the sync is expected to finish, 0 if unknown.
+	EstimatedCompletion int64           `json:"estimatedCompletion"`
+	Peers               []*SyncPeerInfo `json:"peers"`
+}
+
+type SyncPeerInfo struct {
+	PublicKey          string `json:"publicKey"`
+	Version            int    `json:"version"`
+	Height             uint64 `json:"height"`
+	DeliveredMomentums uint64 `json:"deliveredMomentums"`
+	Timeouts           uint64 `json:"timeouts"`
+	Failures           uint64 `json:"failures"`
+	Reputation         int    `json:"reputation"`
+	Capacity           int    `json:"capacity"`
+	Idle               bool   `json:"idle"`
+	// Penalty accumulates for invalid momentums propagated by the peer, it's banned once reaching 30.
+	Penalty int `json:"penalty"`
+	// RejectedMessages is the number of malformed messages sent by the peer, each one adds 10 to its penalty.
+	RejectedMessages uint64 `json:"rejectedMessages"`
+	// LastDelivery is the unix timestamp of the last successful delivery, 0 if none.
+	LastDelivery int64 `json:"lastDelivery"`
 }
 
 type txPool interface {
@@ -40,7 +64,10 @@ type chainManager interface {
 	GetBlockByNumber(num uint64) (*nom.Momentum, error)
 	CurrentBlock() *nom.Momentum
 	Status() (td uint64, currentBlock types.Hash, genesisBlock types.Hash)
+	GetAccountProofs(momentum types.Hash, addresses []types.Address) ([]*AccountProof, error)
+	GetProducers(tick uint64) ([]types.Address, error)
 
+	VerifyChain(chain []*nom.DetailedMomentum) error
 	InsertChain(chain []*nom.DetailedMomentum) (int, error)
 }
 
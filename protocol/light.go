@@ -0,0 +1,701 @@
+package protocol
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const (
+	MaxHeaderFetch = 192 // Amount of momentum headers to be fetched per retrieval request
+
+	lightRequestTimeout = 10 * time.Second // Maximum time to wait for a light client request to be answered
+	lightDeliverySize   = 16               // Number of deliveries buffered while no request is waiting
+	lightMaxRollback    = 30               // Maximum number of headers replaced by a fork, like the full sync
+	lightProofPeers     = 3                // Number of peers asked for an account proof before giving up
+	lightElectionPeers  = 3                // Number of peers asked for the elected producers of a tick
+	lightElectionCache  = 64               // Number of elections kept in memory
+)
+
+var (
+	errLightNoPeers         = errors.New("no peers can serve the light client")
+	errLightProofNotFound   = errors.New("no peer delivered a valid account proof")
+	errLightNoElection      = errors.New("no peer delivered the elected producers")
+	errLightRequestTimeout  = errors.New("light client request timed out")
+	errLightClientStopped   = errors.New("light client stopped")
+	errLightUnknownMomentum = errors.New("momentum header is not synced")
+)
+
+type headersDelivery struct {
+	peer    string
+	headers []*nom.Momentum
+}
+type proofsDelivery struct {
+	peer   string
+	proofs []*AccountProof
+}
+type producersDelivery struct {
+	peer      string
+	producers *producersData
+}
+
+// LightClient syncs and verifies only the momentum headers and fetches the state of the accounts
+// from full peers on demand. Headers must link to each other, hash to their own hash, carry the
+// chain identifier, have increasing timestamps and be signed by the producer elected for their slot.
+// The election needs the state, so the producers of each tick are fetched from up to lightElectionPeers
+// peers, which must all agree. The light client only replaces up to lightMaxRollback headers on forks.
+type LightClient struct {
+	genesis  *nom.Momentum
+	store    *lightStore
+	peers    *peerSet
+	election *consensus.Context
+
+	// elected producers by tick, only used by the sync loop
+	producers *lru.Cache
+
+	SubProtocols []p2p.Protocol
+
+	headersCh   chan *headersDelivery
+	proofsCh    chan *proofsDelivery
+	producersCh chan *producersDelivery
+	syncCh      chan struct{}
+	quit        chan struct{}
+	wg          sync.WaitGroup
+
+	// proofs are requested one at a time, so the deliveries can't be mixed
+	proofLock sync.Mutex
+}
+
+// NewLightClient returns a light client which keeps the headers in db, starting from the genesis momentum.
+func NewLightClient(db db.DB, genesis *nom.Momentum) (*LightClient, error) {
+	genesis.EnsureCache()
+	store, err := newLightStore(db, genesis)
+	if err != nil {
+		return nil, err
+	}
+	producers, err := lru.New(lightElectionCache)
+	if err != nil {
+		return nil, err
+	}
+	lc := &LightClient{
+		genesis:     genesis,
+		store:       store,
+		peers:       newPeerSet(),
+		election:    consensus.NewConsensusContext(*genesis.Timestamp),
+		producers:   producers,
+		headersCh:   make(chan *headersDelivery, lightDeliverySize),
+		proofsCh:    make(chan *proofsDelivery, lightDeliverySize),
+		producersCh: make(chan *producersDelivery, lightDeliverySize),
+		syncCh:      make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+	}
+	// Light clients need the eth/64 and eth/66 messages, so they only run the latest version
+	lc.SubProtocols = []p2p.Protocol{{
+		Name:    "eth",
+		Version: eth66,
+		Length:  protocolLength(eth66),
+		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+			return lc.handle(newPeer(eth66, int(genesis.ChainIdentifier), p, rw))
+		},
+		Priority: msgPriority,
+	}}
+	return lc, nil
+}
+
+func (lc *LightClient) Start() {
+	lc.wg.Add(1)
+	go lc.syncLoop()
+}
+func (lc *LightClient) Stop() {
+	log.Info("Stopping light client...")
+	close(lc.quit)
+	lc.wg.Wait()
+	log.Info("Light client stopped")
+}
+
+// ChainIdentifier returns the chain identifier of the genesis momentum.
+func (lc *LightClient) ChainIdentifier() uint64 {
+	return lc.genesis.ChainIdentifier
+}
+
+// GetFrontierMomentum returns the latest verified header.
+func (lc *LightClient) GetFrontierMomentum() *nom.Momentum {
+	return lc.store.Frontier()
+}
+
+// GetMomentumByHeight returns the verified header at height, nil if it isn't synced yet.
+func (lc *LightClient) GetMomentumByHeight(height uint64) (*nom.Momentum, error) {
+	return lc.store.GetByHeight(height)
+}
+
+// GetMomentumByHash returns the verified header with the hash, nil if unknown.
+func (lc *LightClient) GetMomentumByHash(hash types.Hash) (*nom.Momentum, error) {
+	return lc.store.GetByHash(hash)
+}
+
+// SyncInfo reports the progress of the header sync against the heights announced by the peers.
+func (lc *LightClient) SyncInfo() *SyncInfo {
+	frontier := lc.store.Frontier()
+	info := &SyncInfo{
+		CurrentHeight: frontier.Height,
+		TargetHeight:  frontier.Height,
+		Peers:         make([]*SyncPeerInfo, 0),
+	}
+	best := lc.peers.BestPeer()
+	switch {
+	case best == nil:
+		info.State = NotEnoughPeers
+	case best.Td() > frontier.Height:
+		info.State = Syncing
+		info.TargetHeight = best.Td()
+	default:
+		info.State = SyncDone
+	}
+	return info
+}
+
+// BroadcastAccountBlock sends the account-block to all the peers which don't know it yet,
+// the light client can't apply it, so the full peers verify it.
+func (lc *LightClient) BroadcastAccountBlock(block *nom.AccountBlock) {
+	peers := lc.peers.PeersWithoutTx(block.Hash)
+	for _, p := range peers {
+		if err := p.SendTransactions([]*nom.AccountBlock{block}); err != nil {
+			log.Debug("failed to propagated account-block", "peer-id", p.id, "reason", err)
+		}
+	}
+	log.Info("propagated account-block to peers", "num-peers", len(peers), "account-block-header", block.Header())
+}
+
+// handle is the callback invoked to manage the life cycle of a peer of the light client.
+func (lc *LightClient) handle(p *peer) error {
+	log.Info("light client peer connected", "peer-id", p.id, "address", p.RemoteAddr().String(), "name", p.Name())
+	frontier := lc.store.Frontier()
+	if err := p.Handshake(frontier.Height, frontier.Hash, lc.genesis.Hash); err != nil {
+		log.Info("handshake failed", "peer", p, "name", p.Name())
+		return err
+	}
+	if !p.supports(ProducersMsg) {
+		return errResp(ErrProtocolVersionMismatch, "light client needs eth/%d, peer runs eth/%d", eth66, p.version)
+	}
+	if err := lc.peers.Register(p); err != nil {
+		log.Error("peer addition failed", "peer-id", p.id, "reason", err)
+		return err
+	}
+	defer lc.peers.Unregister(p.id)
+	lc.requestSync()
+
+	for {
+		if err := lc.handleMsg(p); err != nil {
+			log.Info("message handling failed", "peer-id", p.id, "reason", err)
+			return err
+		}
+	}
+}
+
+// handleMsg answers the sync requests of the peers with empty responses, the light client only serves headers.
+func (lc *LightClient) handleMsg(p *peer) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > ProtocolMaxMsgSize {
+		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, ProtocolMaxMsgSize)
+	}
+	defer msg.Discard()
+	if !p.supports(msg.Code) {
+		return errResp(ErrInvalidMsgCode, "%v not supported by eth/%d", msg.Code, p.version)
+	}
+
+	switch msg.Code {
+	case StatusMsg:
+		return errResp(ErrExtraStatusMsg, "uncontrolled status message")
+
+	case GetBlockHashesMsg, GetBlockHashesFromNumberMsg:
+		return p.SendBlockHashes(nil)
+	case GetBlocksMsg:
+		return p.SendBlocks(nil)
+	case GetAccountProofsMsg:
+		return p.SendAccountProofs(nil)
+	case GetProducersMsg:
+		var request getProducersData
+		if err := decodeMsg(msg, &request); err != nil {
+			return err
+		}
+		return p.SendProducers(request.Tick, nil)
+
+	case GetMomentumHeadersMsg:
+		var request getMomentumHeadersData
+		if err := decodeMsg(msg, &request); err != nil {
+			return err
+		}
+		if request.Amount > MaxHeaderFetch {
+			request.Amount = MaxHeaderFetch
+		}
+		headers := make([]*nom.Momentum, 0, request.Amount)
+		for height := request.Height; height < request.Height+request.Amount; height += 1 {
+			header, err := lc.store.GetByHeight(height)
+			if err != nil {
+				return err
+			}
+			if header == nil {
+				break
+			}
+			headers = append(headers, header)
+		}
+		return p.SendMomentumHeaders(headers)
+
+	case NewBlockHashesMsg:
+		var hashes []types.Hash
+		if err := decodeMsg(msg, &hashes); err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			p.MarkBlock(hash)
+			p.SetHead(hash)
+		}
+		lc.requestSync()
+
+	case NewMomentumHashesMsg:
+		var identifiers []types.HashHeight
+		if err := decodeMsg(msg, &identifiers); err != nil {
+			return err
+		}
+		for _, identifier := range identifiers {
+			p.MarkBlock(identifier.Hash)
+			p.SetHead(identifier.Hash)
+			if identifier.Height > p.Td() {
+				p.SetTd(identifier.Height)
+			}
+		}
+		lc.requestSync()
+
+	case NewBlockMsg:
+		detailed := new(nom.DetailedMomentum)
+		if err := decodeMsg(msg, detailed); err != nil {
+			return err
+		}
+		if err := checkMomentumPayload(detailed); err != nil {
+			return err
+		}
+		detailed.Momentum.EnsureCache()
+		p.MarkBlock(detailed.Momentum.Hash)
+		p.SetHead(detailed.Momentum.Hash)
+		if detailed.Momentum.Height > p.Td() {
+			p.SetTd(detailed.Momentum.Height)
+		}
+		lc.requestSync()
+
+	case MomentumHeadersMsg:
+		var headers []*nom.Momentum
+		if err := decodeMsg(msg, &headers); err != nil {
+			return err
+		}
+		for _, header := range headers {
+			if err := checkMomentumPayload(&nom.DetailedMomentum{Momentum: header}); err != nil {
+				return err
+			}
+			header.EnsureCache()
+		}
+		select {
+		case lc.headersCh <- &headersDelivery{peer: p.id, headers: headers}:
+		default:
+			log.Debug("dropping momentum headers delivery", "peer-id", p.id, "reason", "channel is full")
+		}
+
+	case AccountProofsMsg:
+		var proofs []*AccountProof
+		if err := decodeMsg(msg, &proofs); err != nil {
+			return err
+		}
+		select {
+		case lc.proofsCh <- &proofsDelivery{peer: p.id, proofs: proofs}:
+		default:
+			log.Debug("dropping account proofs delivery", "peer-id", p.id, "reason", "channel is full")
+		}
+
+	case ProducersMsg:
+		producers := new(producersData)
+		if err := decodeMsg(msg, producers); err != nil {
+			return err
+		}
+		select {
+		case lc.producersCh <- &producersDelivery{peer: p.id, producers: producers}:
+		default:
+			log.Debug("dropping elected producers delivery", "peer-id", p.id, "reason", "channel is full")
+		}
+
+	case TxMsg, BlockHashesMsg, BlocksMsg:
+		// light clients don't keep account-blocks
+
+	default:
+		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
+	}
+	return nil
+}
+
+func (lc *LightClient) requestSync() {
+	select {
+	case lc.syncCh <- struct{}{}:
+	default:
+	}
+}
+
+// syncLoop polls the best peer for new headers periodically and whenever a momentum is announced.
+func (lc *LightClient) syncLoop() {
+	defer lc.wg.Done()
+	ticker := time.NewTicker(forceSyncCycle)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-lc.quit:
+			return
+		case <-ticker.C:
+		case <-lc.syncCh:
+		}
+		if p := lc.peers.BestPeer(); p != nil {
+			if err := lc.synchronise(p); err != nil {
+				log.Info("light sync failed", "peer-id", p.id, "reason", err)
+			}
+		}
+	}
+}
+
+// synchronise fetches the headers after the frontier from the peer until it has no more. If the peer
+// is on a different fork, the recent headers are fetched again to find where the chains split.
+func (lc *LightClient) synchronise(p *peer) error {
+	for {
+		frontier := lc.store.Frontier()
+		headers, err := lc.fetchHeaders(p, frontier.Height+1)
+		if err != nil || len(headers) == 0 {
+			return err
+		}
+		if headers[0].PreviousHash != frontier.Hash {
+			from := uint64(1)
+			if frontier.Height > lightMaxRollback {
+				from = frontier.Height - lightMaxRollback
+			}
+			if headers, err = lc.fetchHeaders(p, from); err != nil || len(headers) == 0 {
+				return err
+			}
+		}
+		if last := headers[len(headers)-1].Height; last > p.Td() {
+			p.SetTd(last)
+		}
+		inserted, err := lc.insertHeaders(p, headers)
+		if err != nil || !inserted || len(headers) < MaxHeaderFetch {
+			return err
+		}
+	}
+}
+
+func (lc *LightClient) fetchHeaders(p *peer, height uint64) ([]*nom.Momentum, error) {
+	// discard the deliveries of the previous requests
+	for len(lc.headersCh) > 0 {
+		<-lc.headersCh
+	}
+	if err := p.RequestMomentumHeaders(height, MaxHeaderFetch); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(lightRequestTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-lc.quit:
+			return nil, errLightClientStopped
+		case <-timeout.C:
+			return nil, errLightRequestTimeout
+		case delivery := <-lc.headersCh:
+			if delivery.peer == p.id {
+				return delivery.headers, nil
+			}
+		}
+	}
+}
+
+// insertHeaders verifies and stores the headers which aren't known yet, replacing the stored ones above
+// the fork point if the new chain is longer. The peer is dropped if it delivered an invalid header.
+func (lc *LightClient) insertHeaders(p *peer, headers []*nom.Momentum) (bool, error) {
+	start := 0
+	for ; start < len(headers); start += 1 {
+		our, err := lc.store.GetByHeight(headers[start].Height)
+		if err != nil {
+			return false, err
+		}
+		if our == nil || our.Hash != headers[start].Hash {
+			break
+		}
+	}
+	headers = headers[start:]
+	if len(headers) == 0 {
+		return false, nil
+	}
+
+	frontier := lc.store.Frontier()
+	head, tail := headers[0], headers[len(headers)-1]
+	previous, err := lc.store.GetByHeight(head.Height - 1)
+	if err != nil {
+		return false, err
+	}
+	if previous == nil || head.Height <= 1 {
+		return false, errors.Errorf("can't link header %v to the synced headers", head.Identifier())
+	}
+	if head.Height <= frontier.Height {
+		if frontier.Height-previous.Height > lightMaxRollback {
+			return false, errors.Errorf("can't rollback to %v. Too far. Frontier is %v", previous.Identifier(), frontier.Identifier())
+		}
+		if tail.Height <= frontier.Height {
+			return false, nil
+		}
+		log.Info("light client switches to a longer fork", "fork-point", previous.Identifier(), "frontier", frontier.Identifier())
+	}
+
+	for _, header := range headers {
+		producers, err := lc.electedProducers(lc.election.ToTick(*header.Timestamp))
+		if err != nil {
+			return false, err
+		}
+		if err := lc.verifyHeader(header, previous, producers); err != nil {
+			log.Info("peer delivered an invalid header", "peer-id", p.id, "reason", err)
+			p.Peer.Disconnect(p2p.DiscUselessPeer)
+			return false, err
+		}
+		previous = header
+	}
+	if err := lc.store.insert(headers); err != nil {
+		return false, err
+	}
+	log.Info("inserted momentum headers", "num-headers", len(headers), "frontier", tail.Identifier())
+	return true, nil
+}
+
+// verifyHeader checks the header against the previous one and the producers elected for its tick.
+func (lc *LightClient) verifyHeader(header, previous *nom.Momentum, producers []types.Address) error {
+	switch {
+	case header.ChainIdentifier != lc.genesis.ChainIdentifier:
+		return errors.Errorf("header %v has chain identifier %v, expected %v", header.Identifier(), header.ChainIdentifier, lc.genesis.ChainIdentifier)
+	case header.Height != previous.Height+1 || header.PreviousHash != previous.Hash:
+		return errors.Errorf("header %v doesn't link to %v", header.Identifier(), previous.Identifier())
+	case header.ComputeHash() != header.Hash:
+		return errors.Errorf("header %v has an invalid hash", header.Identifier())
+	case header.TimestampUnix <= previous.TimestampUnix:
+		return errors.Errorf("header %v has a timestamp which isn't increasing", header.Identifier())
+	case header.Timestamp.After(time.Now().Add(10 * time.Second)):
+		return errors.Errorf("header %v has a timestamp in the future", header.Identifier())
+	}
+	verified, err := wallet.VerifySignature(header.PublicKey, header.Hash.Bytes(), header.Signature)
+	if err != nil || !verified {
+		return errors.Errorf("header %v has an invalid signature", header.Identifier())
+	}
+	expected := consensus.ProducerAt(lc.election, *header.Timestamp, producers)
+	if expected == nil {
+		return errors.Errorf("header %v isn't produced at the start of a slot", header.Identifier())
+	}
+	if producer := header.Producer(); producer != *expected {
+		return errors.Errorf("header %v is produced by %v, expected the elected producer %v", header.Identifier(), producer, *expected)
+	}
+	return nil
+}
+
+// electedProducers returns the producers elected for the tick. They are fetched from the best peers,
+// which must all agree, the peers which don't know the election yet are skipped.
+func (lc *LightClient) electedProducers(tick uint64) ([]types.Address, error) {
+	if cached, ok := lc.producers.Get(tick); ok {
+		return cached.([]types.Address), nil
+	}
+	var producers []types.Address
+	for _, p := range lc.bestPeers(lightElectionPeers) {
+		delivered, err := lc.fetchProducers(p, tick)
+		if err == nil && len(delivered) != int(lc.election.NodeCount) {
+			err = errors.Errorf("%v producers, expected %v", len(delivered), lc.election.NodeCount)
+		}
+		if err != nil {
+			log.Info("failed to get elected producers", "peer-id", p.id, "tick", tick, "reason", err)
+			continue
+		}
+		if producers != nil && !equalAddresses(producers, delivered) {
+			return nil, errors.Errorf("peers disagree on the producers elected for tick %v", tick)
+		}
+		producers = delivered
+	}
+	if producers == nil {
+		return nil, errLightNoElection
+	}
+	lc.producers.Add(tick, producers)
+	return producers, nil
+}
+
+func (lc *LightClient) fetchProducers(p *peer, tick uint64) ([]types.Address, error) {
+	// discard the deliveries of the previous requests
+	for len(lc.producersCh) > 0 {
+		<-lc.producersCh
+	}
+	if err := p.RequestProducers(tick); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(lightRequestTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-lc.quit:
+			return nil, errLightClientStopped
+		case <-timeout.C:
+			return nil, errLightRequestTimeout
+		case delivery := <-lc.producersCh:
+			if delivery.peer == p.id && delivery.producers.Tick == tick {
+				return delivery.producers.Producers, nil
+			}
+		}
+	}
+}
+
+func equalAddresses(a, b []types.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// bestPeers returns up to limit peers, the highest ones first.
+func (lc *LightClient) bestPeers(limit int) []*peer {
+	peers := lc.peers.AllPeers()
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Td() > peers[j].Td()
+	})
+	if len(peers) > limit {
+		peers = peers[:limit]
+	}
+	return peers
+}
+
+// GetAccountProof fetches the state of the address at the synced momentum from up to lightProofPeers
+// peers, which must all deliver the same proof. The frontier account-block of the proof must be the
+// latest one of the address confirmed by the synced headers, the balances are only checked by the
+// agreement of the peers.
+func (lc *LightClient) GetAccountProof(address types.Address, momentum types.Hash) (*AccountProof, error) {
+	header, err := lc.store.GetByHash(momentum)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errLightUnknownMomentum
+	}
+
+	lc.proofLock.Lock()
+	defer lc.proofLock.Unlock()
+	for len(lc.proofsCh) > 0 {
+		<-lc.proofsCh
+	}
+
+	peers := lc.bestPeers(lightProofPeers)
+	if len(peers) == 0 {
+		return nil, errLightNoPeers
+	}
+	var proof *AccountProof
+	for _, p := range peers {
+		proofs, err := lc.fetchAccountProofs(p, momentum, address)
+		if err == nil {
+			err = lc.verifyAccountProof(address, header, proofs)
+		}
+		if err != nil {
+			log.Info("failed to get account proof", "peer-id", p.id, "address", address, "reason", err)
+			return nil, errLightProofNotFound
+		}
+		if proof != nil && !equalAccountProofs(proof, proofs[0]) {
+			return nil, errors.Errorf("peers disagree on the state of %v at %v", address, header.Identifier())
+		}
+		proof = proofs[0]
+	}
+	return proof, nil
+}
+
+func (lc *LightClient) fetchAccountProofs(p *peer, momentum types.Hash, address types.Address) ([]*AccountProof, error) {
+	if err := p.RequestAccountProofs(momentum, []types.Address{address}); err != nil {
+		return nil, err
+	}
+	timeout := time.NewTimer(lightRequestTimeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-lc.quit:
+			return nil, errLightClientStopped
+		case <-timeout.C:
+			return nil, errLightRequestTimeout
+		case delivery := <-lc.proofsCh:
+			if delivery.peer == p.id {
+				return delivery.proofs, nil
+			}
+		}
+	}
+}
+
+// verifyAccountProof checks that the frontier account-block of the proof is the latest one of the address
+// confirmed by the synced headers up to header, so peers can't hide the recent account-blocks.
+func (lc *LightClient) verifyAccountProof(address types.Address, header *nom.Momentum, proofs []*AccountProof) error {
+	if len(proofs) != 1 || proofs[0] == nil || proofs[0].Address != address {
+		return errors.Errorf("unexpected account proofs")
+	}
+	latest, confirmation, err := lc.store.GetAccountHeader(address, header.Height)
+	if err != nil {
+		return err
+	}
+	block := proofs[0].FrontierBlock
+	// without a frontier there is nothing to check the balances against, accounts without
+	// account-blocks don't have any
+	if block == nil {
+		if latest != nil {
+			return errors.Errorf("account proof without a frontier account-block, expected %v", *latest)
+		}
+		if len(proofs[0].Balances) != 0 || proofs[0].ConfirmationHeight != 0 {
+			return errors.Errorf("account proof without a frontier account-block has balances")
+		}
+		return nil
+	}
+	if block.Address != address || block.ComputeHash() != block.Hash {
+		return errors.Errorf("invalid frontier account-block %v", block.Header())
+	}
+	if latest == nil {
+		return errors.Errorf("frontier account-block %v isn't confirmed at %v", block.Header(), header.Identifier())
+	}
+	if latest.Identifier() != block.Identifier() {
+		return errors.Errorf("frontier account-block %v isn't the latest one confirmed at %v, expected %v", block.Header(), header.Identifier(), *latest)
+	}
+	if proofs[0].ConfirmationHeight != confirmation {
+		return errors.Errorf("frontier account-block %v is confirmed at %v, not %v", block.Header(), confirmation, proofs[0].ConfirmationHeight)
+	}
+	return nil
+}
+
+// equalAccountProofs compares the verified proofs of the same address.
+func equalAccountProofs(a, b *AccountProof) bool {
+	if a.ConfirmationHeight != b.ConfirmationHeight || (a.FrontierBlock == nil) != (b.FrontierBlock == nil) {
+		return false
+	}
+	if a.FrontierBlock != nil && a.FrontierBlock.Hash != b.FrontierBlock.Hash {
+		return false
+	}
+	if len(a.Balances) != len(b.Balances) {
+		return false
+	}
+	for i := range a.Balances {
+		if a.Balances[i].TokenStandard != b.Balances[i].TokenStandard || a.Balances[i].Amount.Cmp(b.Balances[i].Amount) != 0 {
+			return false
+		}
+	}
+	return true
+}
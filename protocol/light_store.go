@@ -0,0 +1,197 @@
+package protocol
+
+import (
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var (
+	lightHeaderKeyPrefix  = []byte{1}
+	lightHeightKeyPrefix  = []byte{2}
+	lightFrontierKey      = []byte{3}
+	lightAccountKeyPrefix = []byte{4}
+)
+
+func getLightHeaderKey(height uint64) []byte {
+	return common.JoinBytes(lightHeaderKeyPrefix, common.Uint64ToBytes(height))
+}
+func getLightHeightKey(hash types.Hash) []byte {
+	return common.JoinBytes(lightHeightKeyPrefix, hash.Bytes())
+}
+func getLightAccountPrefix(address types.Address) []byte {
+	return common.JoinBytes(lightAccountKeyPrefix, address.Bytes())
+}
+
+// getLightAccountKey inverts the momentum height, so iterating the keys of an address starts with the latest momentum.
+func getLightAccountKey(address types.Address, height uint64) []byte {
+	return common.JoinBytes(getLightAccountPrefix(address), common.Uint64ToBytes(^height))
+}
+
+// lightStore keeps the verified momentum headers of the light client, keyed by height, and indexes the
+// latest account-block of each address confirmed by every header. The frontier is written last, so
+// headers above it left over by an interrupted write are ignored.
+type lightStore struct {
+	db       db.DB
+	lock     sync.RWMutex
+	frontier *nom.Momentum
+}
+
+func newLightStore(db db.DB, genesis *nom.Momentum) (*lightStore, error) {
+	s := &lightStore{db: db}
+	data, err := db.Get(lightFrontierKey)
+	if err == leveldb.ErrNotFound {
+		if err := s.put(genesis); err != nil {
+			return nil, err
+		}
+		return s, s.setFrontier(genesis)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.frontier, err = s.getByHeight(common.BytesToUint64(data)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *lightStore) Frontier() *nom.Momentum {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.frontier
+}
+
+// GetByHeight returns the header at height, nil if it's above the frontier.
+func (s *lightStore) GetByHeight(height uint64) (*nom.Momentum, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if height > s.frontier.Height {
+		return nil, nil
+	}
+	return s.getByHeight(height)
+}
+
+// GetByHash returns the header with the hash, nil if unknown.
+func (s *lightStore) GetByHash(hash types.Hash) (*nom.Momentum, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	data, err := s.db.Get(getLightHeightKey(hash))
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	height := common.BytesToUint64(data)
+	if height > s.frontier.Height {
+		return nil, nil
+	}
+	momentum, err := s.getByHeight(height)
+	if err != nil || momentum == nil || momentum.Hash != hash {
+		return nil, err
+	}
+	return momentum, nil
+}
+
+// GetAccountHeader returns the latest account-block of the address confirmed by the headers up to height
+// and the height of the header confirming it, nil if the headers don't confirm any.
+func (s *lightStore) GetAccountHeader(address types.Address, height uint64) (*types.AccountHeader, uint64, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if height > s.frontier.Height {
+		height = s.frontier.Height
+	}
+	iterator := s.db.NewIteratorFrom(getLightAccountPrefix(address), getLightAccountKey(address, height))
+	defer iterator.Release()
+	for iterator.Next() {
+		// skip deleted entries
+		if len(iterator.Value()) == 0 {
+			continue
+		}
+		identifier, err := types.DeserializeHashHeight(iterator.Value())
+		if err != nil {
+			return nil, 0, err
+		}
+		confirmation := ^common.BytesToUint64(iterator.Key()[len(iterator.Key())-8:])
+		return &types.AccountHeader{Address: address, HashHeight: *identifier}, confirmation, nil
+	}
+	return nil, 0, iterator.Error()
+}
+
+// insert replaces the headers from the height of the first one on, they must be verified and linked.
+func (s *lightStore) insert(headers []*nom.Momentum) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, header := range headers {
+		if err := s.put(header); err != nil {
+			return err
+		}
+	}
+	return s.setFrontier(headers[len(headers)-1])
+}
+
+func (s *lightStore) getByHeight(height uint64) (*nom.Momentum, error) {
+	data, err := s.db.Get(getLightHeaderKey(height))
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	momentum, err := nom.DeserializeMomentum(data)
+	if err != nil {
+		return nil, err
+	}
+	momentum.EnsureCache()
+	return momentum, nil
+}
+
+// put replaces the header at its height along with the account-blocks it confirms. The header is written
+// before the index, so a retried write removes the index of a header left over by an interrupted one.
+func (s *lightStore) put(header *nom.Momentum) error {
+	replaced, err := s.getByHeight(header.Height)
+	if err != nil {
+		return err
+	}
+	data, err := header.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := s.db.Put(getLightHeaderKey(header.Height), data); err != nil {
+		return err
+	}
+	if err := s.db.Put(getLightHeightKey(header.Hash), common.Uint64ToBytes(header.Height)); err != nil {
+		return err
+	}
+	if replaced != nil {
+		for _, entry := range replaced.Content {
+			if err := s.db.Delete(getLightAccountKey(entry.Address, replaced.Height)); err != nil {
+				return err
+			}
+		}
+	}
+	latest := make(map[types.Address]types.HashHeight)
+	for _, entry := range header.Content {
+		if current, ok := latest[entry.Address]; !ok || entry.Height > current.Height {
+			latest[entry.Address] = entry.HashHeight
+		}
+	}
+	for address, identifier := range latest {
+		if err := s.db.Put(getLightAccountKey(address, header.Height), identifier.Serialize()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (s *lightStore) setFrontier(header *nom.Momentum) error {
+	if err := s.db.Put(lightFrontierKey, common.Uint64ToBytes(header.Height)); err != nil {
+		return err
+	}
+	s.frontier = header
+	return nil
+}
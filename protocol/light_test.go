@@ -0,0 +1,317 @@
+package protocol
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const testGenesisTimestamp = 1600000000
+
+func newTestLightClient(t *testing.T) *LightClient {
+	genesis := &nom.Momentum{ChainIdentifier: 1, Height: 1, TimestampUnix: testGenesisTimestamp}
+	genesis.Hash = genesis.ComputeHash()
+	lc, err := NewLightClient(db.NewMemDB(), genesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { close(lc.quit) })
+	return lc
+}
+
+// testElectedProducers returns the key pairs of the producers elected for every tick, in the order of their slots.
+func testElectedProducers(t *testing.T, lc *LightClient) ([]*wallet.KeyPair, []types.Address) {
+	keys := make([]*wallet.KeyPair, lc.election.NodeCount)
+	addresses := make([]types.Address, lc.election.NodeCount)
+	for i := range keys {
+		key, err := wallet.DeriveWithIndex(uint32(i), make([]byte, 32))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i], addresses[i] = key, key.Address
+	}
+	return keys, addresses
+}
+
+// testHeader returns the header after previous, produced seconds after the genesis, signed by key.
+func testHeader(previous *nom.Momentum, seconds uint64, key *wallet.KeyPair) *nom.Momentum {
+	header := &nom.Momentum{
+		ChainIdentifier: previous.ChainIdentifier,
+		PreviousHash:    previous.Hash,
+		Height:          previous.Height + 1,
+		TimestampUnix:   testGenesisTimestamp + seconds,
+		PublicKey:       key.Public,
+	}
+	header.Hash = header.ComputeHash()
+	header.Signature = key.Sign(header.Hash.Bytes())
+	header.EnsureCache()
+	return header
+}
+
+// Test verifyHeader
+//   - test headers signed by the producer elected for their slot are accepted
+//   - test headers signed by another producer, or produced outside the start of a slot, are rejected
+func TestLightClient_VerifyHeader(t *testing.T) {
+	lc := newTestLightClient(t)
+	keys, producers := testElectedProducers(t, lc)
+	blockTime := uint64(lc.election.BlockTime)
+
+	header := testHeader(lc.genesis, blockTime, keys[1])
+	if err := lc.verifyHeader(header, lc.genesis, producers); err != nil {
+		t.Fatal(err)
+	}
+	// the first slot of the next tick
+	next := testHeader(header, blockTime*uint64(lc.election.NodeCount), keys[0])
+	if err := lc.verifyHeader(next, header, producers); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		header *nom.Momentum
+		err    string
+	}{
+		{testHeader(lc.genesis, blockTime, keys[2]), "expected the elected producer " + producers[1].String()},
+		{testHeader(lc.genesis, blockTime+1, keys[1]), "isn't produced at the start of a slot"},
+	} {
+		err := lc.verifyHeader(test.header, lc.genesis, producers)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("expected error %q, got %v", test.err, err)
+		}
+	}
+
+	forged := testHeader(lc.genesis, blockTime, keys[2])
+	forged.PublicKey = keys[1].Public
+	if err := lc.verifyHeader(forged, lc.genesis, producers); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("expected an invalid signature, got %v", err)
+	}
+}
+
+// newTestPeer registers a peer which answers the requests of the light client with serve.
+func newTestPeer(t *testing.T, lc *LightClient, id byte, serve func(msg p2p.Msg, rw p2p.MsgReadWriter) error) {
+	local, remote := p2p.MsgPipe()
+	t.Cleanup(func() { local.Close() })
+	p := newPeer(eth66, 1, p2p.NewPeer(discover.NodeID{id}, "full", nil), local)
+	if err := lc.peers.Register(p); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for lc.handleMsg(p) == nil {
+		}
+	}()
+	go func() {
+		for {
+			msg, err := remote.ReadMsg()
+			if err != nil {
+				return
+			}
+			if err := serve(msg, remote); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// newTestElectionPeer registers a peer which answers the requests of the elected producers with answer.
+func newTestElectionPeer(t *testing.T, lc *LightClient, id byte, answer func(tick uint64) []types.Address) {
+	newTestPeer(t, lc, id, func(msg p2p.Msg, rw p2p.MsgReadWriter) error {
+		var request getProducersData
+		if err := msg.Decode(&request); err != nil {
+			return err
+		}
+		return p2p.Send(rw, ProducersMsg, producersData{request.Tick, answer(request.Tick)})
+	})
+}
+
+// newTestProofPeer registers a peer which answers the requests of account proofs with answer.
+func newTestProofPeer(t *testing.T, lc *LightClient, id byte, answer func(address types.Address) *AccountProof) {
+	newTestPeer(t, lc, id, func(msg p2p.Msg, rw p2p.MsgReadWriter) error {
+		var request getAccountProofsData
+		if err := msg.Decode(&request); err != nil {
+			return err
+		}
+		return p2p.Send(rw, AccountProofsMsg, []*AccountProof{answer(request.Addresses[0])})
+	})
+}
+
+// Test electedProducers
+//   - test the producers are fetched from the peers and cached
+//   - test the peers which don't know the election are skipped
+//   - test the producers are rejected if the peers disagree
+func TestLightClient_ElectedProducers(t *testing.T) {
+	lc := newTestLightClient(t)
+	_, producers := testElectedProducers(t, lc)
+	if _, err := lc.electedProducers(1); err != errLightNoElection {
+		t.Fatalf("expected %v without peers, got %v", errLightNoElection, err)
+	}
+
+	// disagrees on the producers of tick 3
+	other := append([]types.Address{producers[1], producers[0]}, producers[2:]...)
+	newTestElectionPeer(t, lc, 1, func(tick uint64) []types.Address { return producers })
+	newTestElectionPeer(t, lc, 2, func(tick uint64) []types.Address {
+		switch tick {
+		case 2:
+			return nil
+		case 3:
+			return other
+		}
+		return producers
+	})
+
+	for _, tick := range []uint64{1, 2} {
+		elected, err := lc.electedProducers(tick)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !equalAddresses(elected, producers) {
+			t.Fatalf("unexpected producers for tick %v", tick)
+		}
+		if _, ok := lc.producers.Get(tick); !ok {
+			t.Fatalf("producers of tick %v aren't cached", tick)
+		}
+	}
+	if _, err := lc.electedProducers(3); err == nil || !strings.Contains(err.Error(), "disagree") {
+		t.Fatalf("expected the peers to disagree, got %v", err)
+	}
+	if _, ok := lc.producers.Get(uint64(3)); ok {
+		t.Fatal("cached the producers the peers disagree on")
+	}
+}
+
+// testAccountBlock returns the account-block of address at height.
+func testAccountBlock(address types.Address, height uint64) *nom.AccountBlock {
+	block := &nom.AccountBlock{
+		Version:         1,
+		ChainIdentifier: 1,
+		BlockType:       nom.BlockTypeUserSend,
+		Height:          height,
+		Address:         address,
+		ToAddress:       types.PillarContract,
+		Amount:          big.NewInt(0),
+		TokenStandard:   types.ZnnTokenStandard,
+	}
+	block.Hash = block.ComputeHash()
+	return block
+}
+
+// testContentHeader returns the header after previous confirming blocks, the light store doesn't verify it.
+func testContentHeader(previous *nom.Momentum, blocks ...*nom.AccountBlock) *nom.Momentum {
+	header := &nom.Momentum{
+		ChainIdentifier: previous.ChainIdentifier,
+		PreviousHash:    previous.Hash,
+		Height:          previous.Height + 1,
+		TimestampUnix:   previous.TimestampUnix + 10,
+		Content:         nom.NewMomentumContent(blocks),
+	}
+	header.Hash = header.ComputeHash()
+	header.EnsureCache()
+	return header
+}
+
+// Test verifyAccountProof
+//   - test proofs without a frontier account-block are only accepted without balances
+//   - test the frontier account-block must be the latest one confirmed by the synced headers
+//   - test the account-blocks of replaced headers are forgotten
+func TestLightClient_VerifyAccountProof(t *testing.T) {
+	lc := newTestLightClient(t)
+	address := types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+
+	empty := []*AccountProof{{Address: address}}
+	if err := lc.verifyAccountProof(address, lc.genesis, empty); err != nil {
+		t.Fatal(err)
+	}
+	withBalances := []*AccountProof{{
+		Address:  address,
+		Balances: []*AccountStateBalance{{TokenStandard: types.ZnnTokenStandard, Amount: big.NewInt(1)}},
+	}}
+	if err := lc.verifyAccountProof(address, lc.genesis, withBalances); err == nil {
+		t.Fatal("accepted balances without a frontier account-block")
+	}
+	if err := lc.verifyAccountProof(address, lc.genesis, []*AccountProof{{Address: address, ConfirmationHeight: 1}}); err == nil {
+		t.Fatal("accepted a confirmation height without a frontier account-block")
+	}
+
+	first, second := testAccountBlock(address, 1), testAccountBlock(address, 2)
+	h2 := testContentHeader(lc.genesis, first)
+	h3 := testContentHeader(h2, second)
+	if err := lc.store.insert([]*nom.Momentum{h2, h3}); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		header *nom.Momentum
+		proof  *AccountProof
+		err    string
+	}{
+		{h2, &AccountProof{Address: address, FrontierBlock: first, ConfirmationHeight: 2}, ""},
+		{h2, &AccountProof{Address: address}, "without a frontier account-block"},
+		{h3, &AccountProof{Address: address, FrontierBlock: second, ConfirmationHeight: 3}, ""},
+		{h3, &AccountProof{Address: address, FrontierBlock: first, ConfirmationHeight: 2}, "isn't the latest one"},
+		{h3, &AccountProof{Address: address, FrontierBlock: second, ConfirmationHeight: 2}, "is confirmed at 3"},
+		{h3, &AccountProof{Address: address}, "without a frontier account-block"},
+	} {
+		err := lc.verifyAccountProof(address, test.header, []*AccountProof{test.proof})
+		if (test.err == "" && err != nil) || (test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err))) {
+			t.Fatalf("expected error %q at %v, got %v", test.err, test.header.Height, err)
+		}
+	}
+
+	fork := testContentHeader(h2)
+	if err := lc.store.insert([]*nom.Momentum{fork}); err != nil {
+		t.Fatal(err)
+	}
+	if err := lc.verifyAccountProof(address, fork, []*AccountProof{{Address: address, FrontierBlock: first, ConfirmationHeight: 2}}); err != nil {
+		t.Fatalf("account-block of the replaced header not forgotten: %v", err)
+	}
+}
+
+// Test GetAccountProof
+//   - test the proof is accepted if all the peers deliver it
+//   - test the proof is rejected if the peers disagree on the balances or one delivers a stale frontier
+func TestLightClient_GetAccountProof(t *testing.T) {
+	lc := newTestLightClient(t)
+	address := types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz")
+	first, second := testAccountBlock(address, 1), testAccountBlock(address, 2)
+	h2 := testContentHeader(lc.genesis, first)
+	h3 := testContentHeader(h2, second)
+	if err := lc.store.insert([]*nom.Momentum{h2, h3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lc.GetAccountProof(address, h3.Hash); err != errLightNoPeers {
+		t.Fatalf("expected %v without peers, got %v", errLightNoPeers, err)
+	}
+
+	proof := func(block *nom.AccountBlock, confirmation uint64, balance int64) *AccountProof {
+		return &AccountProof{
+			Address:            address,
+			Balances:           []*AccountStateBalance{{TokenStandard: types.ZnnTokenStandard, Amount: big.NewInt(balance)}},
+			FrontierBlock:      block,
+			ConfirmationHeight: confirmation,
+		}
+	}
+	var answer = proof(second, 3, 10)
+	newTestProofPeer(t, lc, 1, func(types.Address) *AccountProof { return proof(second, 3, 10) })
+	newTestProofPeer(t, lc, 2, func(types.Address) *AccountProof { return answer })
+
+	delivered, err := lc.GetAccountProof(address, h3.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered.FrontierBlock.Hash != second.Hash || delivered.Balances[0].Amount.Int64() != 10 {
+		t.Fatalf("unexpected proof %+v", delivered)
+	}
+
+	answer = proof(second, 3, 11)
+	if _, err := lc.GetAccountProof(address, h3.Hash); err == nil || !strings.Contains(err.Error(), "disagree") {
+		t.Fatalf("expected the peers to disagree, got %v", err)
+	}
+	answer = proof(first, 2, 10)
+	if _, err := lc.GetAccountProof(address, h3.Hash); err != errLightProofNotFound {
+		t.Fatalf("expected %v with a stale frontier, got %v", errLightProofNotFound, err)
+	}
+}
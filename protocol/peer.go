@@ -172,6 +172,15 @@ func (p *peer) SendNewBlockHashes(hashes []types.Hash) error {
 	return p2p.Send(p.rw, NewBlockHashesMsg, hashes)
 }
 
+// SendNewMomentumHashes announces the availability of momentums along with their heights, so the
+// peer can fetch the ones it misses and detect when it fell behind.
+func (p *peer) SendNewMomentumHashes(identifiers []types.HashHeight) error {
+	for _, identifier := range identifiers {
+		p.knownBlocks.Add(identifier.Hash, nil)
+	}
+	return p2p.Send(p.rw, NewMomentumHashesMsg, identifiers)
+}
+
 // SendNewMomentum propagates an entire block to a remote peer.
 func (p *peer) SendNewMomentum(detailed *nom.DetailedMomentum) error {
 	detailed.Momentum.EnsureCache()
@@ -199,19 +208,64 @@ func (p *peer) RequestBlocks(hashes []types.Hash) error {
 	return p2p.Send(p.rw, GetBlocksMsg, hashes)
 }
 
+// SendMomentumHeaders sends a batch of momentums, without their account-blocks, to the remote peer.
+func (p *peer) SendMomentumHeaders(momentums []*nom.Momentum) error {
+	for _, momentum := range momentums {
+		momentum.EnsureCache()
+	}
+	return p2p.Send(p.rw, MomentumHeadersMsg, momentums)
+}
+
+// SendAccountProofs sends a batch of account proofs to the remote peer.
+func (p *peer) SendAccountProofs(proofs []*AccountProof) error {
+	return p2p.Send(p.rw, AccountProofsMsg, proofs)
+}
+
+// RequestMomentumHeaders fetches a batch of momentums, without their account-blocks, starting at height.
+func (p *peer) RequestMomentumHeaders(height uint64, amount uint64) error {
+	log.Debug("fetching momentum headers", "peer-id", p.id, "height", height, "amount", amount)
+	return p2p.Send(p.rw, GetMomentumHeadersMsg, getMomentumHeadersData{height, amount})
+}
+
+// RequestAccountProofs fetches the proofs of the addresses at the momentum.
+func (p *peer) RequestAccountProofs(momentum types.Hash, addresses []types.Address) error {
+	log.Debug("fetching account proofs", "peer-id", p.id, "num-accounts", len(addresses), "momentum", momentum)
+	return p2p.Send(p.rw, GetAccountProofsMsg, getAccountProofsData{momentum, addresses})
+}
+
+// SendProducers sends the producers elected for the tick to the remote peer.
+func (p *peer) SendProducers(tick uint64, producers []types.Address) error {
+	return p2p.Send(p.rw, ProducersMsg, producersData{tick, producers})
+}
+
+// RequestProducers fetches the producers elected for the tick.
+func (p *peer) RequestProducers(tick uint64) error {
+	log.Debug("fetching elected producers", "peer-id", p.id, "tick", tick)
+	return p2p.Send(p.rw, GetProducersMsg, getProducersData{tick})
+}
+
 // Handshake executes the eth protocol handshake, negotiating version number,
 // network IDs, difficulties, head and genesis blocks.
 func (p *peer) Handshake(td uint64, head types.Hash, genesis types.Hash) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 1)
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &statusData{
+		status := &statusData{
 			ProtocolVersion: uint32(p.version),
 			NetworkId:       uint32(p.network),
 			TD:              td,
 			CurrentBlock:    head,
 			GenesisBlock:    genesis,
-		})
+		}
+		if p.version >= eth63 {
+			// the versions above the running one aren't spoken over this sub-protocol
+			for _, v := range ProtocolVersions {
+				if int(v) <= p.version {
+					status.Versions = append(status.Versions, uint32(v))
+				}
+			}
+		}
+		errc <- p2p.Send(p.rw, StatusMsg, status)
 	}()
 	// In the mean time retrieve the remote status message
 	msg, err := p.rw.ReadMsg()
@@ -226,8 +280,8 @@ func (p *peer) Handshake(td uint64, head types.Hash, genesis types.Hash) error {
 	}
 	// Decode the handshake and make sure everything matches
 	var status statusData
-	if err := msg.Decode(&status); err != nil {
-		return errResp(ErrDecode, "msg %v: %v", msg, err)
+	if err := decodeMsg(msg, &status); err != nil {
+		return err
 	}
 	if status.GenesisBlock != genesis {
 		return errResp(ErrGenesisBlockMismatch, "%x (!= %x)", status.GenesisBlock, genesis)
@@ -235,14 +289,31 @@ func (p *peer) Handshake(td uint64, head types.Hash, genesis types.Hash) error {
 	if int(status.NetworkId) != p.network {
 		return errResp(ErrNetworkIdMismatch, "%d (!= %d)", status.NetworkId, p.network)
 	}
-	if int(status.ProtocolVersion) != p.version {
-		return errResp(ErrProtocolVersionMismatch, "%d (!= %d)", status.ProtocolVersion, p.version)
+	// Run the highest version both sides support, the message codes of the older versions are a
+	// prefix of the newer ones, so they can be spoken over the already matched sub-protocol
+	version := negotiateVersion(p.version, &status)
+	if version == 0 {
+		return errResp(ErrProtocolVersionMismatch, "%d %v (!= %d)", status.ProtocolVersion, status.Versions, p.version)
+	}
+	if version != p.version {
+		log.Info("downgraded protocol version", "peer-id", p.id, "from", p.version, "to", version)
+		p.version = version
 	}
 	// Configure the remote peer, and sanity check out handshake too
 	p.td, p.head = status.TD, status.CurrentBlock
 	return <-errc
 }
 
+// supports returns whether the negotiated version of the peer implements the message code.
+func (p *peer) supports(code uint64) bool {
+	return code < protocolLength(p.version)
+}
+
+// announcesByHash returns whether the peer expects the new momentums to be announced instead of pushed.
+func (p *peer) announcesByHash() bool {
+	return p.version >= eth65
+}
+
 // String implements fmt.Stringer.
 func (p *peer) String() string {
 	return fmt.Sprintf("Peer %s [%s]", p.id,
@@ -336,6 +407,18 @@ func (ps *peerSet) PeersWithoutTx(hash types.Hash) []*peer {
 	return list
 }
 
+// AllPeers retrieves a flat list of all the peers within the set.
+func (ps *peerSet) AllPeers() []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		list = append(list, p)
+	}
+	return list
+}
+
 // BestPeer retrieves the known peer with the currently highest total difficulty.
 func (ps *peerSet) BestPeer() *peer {
 	ps.lock.RLock()
@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	tests := []struct {
+		running int
+		status  statusData
+		want    int
+	}{
+		{eth65, statusData{ProtocolVersion: eth65, Versions: []uint32{eth65, eth64, eth63, eth62, eth61}}, eth65},
+		// the remote runs an older version over the matched sub-protocol
+		{eth65, statusData{ProtocolVersion: eth63, Versions: []uint32{eth63, eth62, eth61}}, eth63},
+		// peers before eth/63 only advertise the version they are running
+		{eth65, statusData{ProtocolVersion: eth62}, eth62},
+		{eth62, statusData{ProtocolVersion: eth65, Versions: []uint32{eth65, eth64, eth63, eth62, eth61}}, eth62},
+		// unknown versions of newer peers are skipped
+		{eth65, statusData{ProtocolVersion: 70, Versions: []uint32{70, eth64}}, eth64},
+		{eth65, statusData{ProtocolVersion: 70, Versions: []uint32{70}}, 0},
+		{eth61, statusData{ProtocolVersion: eth62}, 0},
+	}
+	for i, test := range tests {
+		if got := negotiateVersion(test.running, &test.status); got != test.want {
+			t.Errorf("test %d: negotiated version %d, want %d", i, got, test.want)
+		}
+	}
+}
+
+func testHandshakePeers(local, remote int) (*peer, *peer) {
+	localRW, remoteRW := p2p.MsgPipe()
+	var localID, remoteID discover.NodeID
+	localID[0], remoteID[0] = 1, 2
+	return newPeer(local, 1, p2p.NewPeer(remoteID, "remote", nil), localRW),
+		newPeer(remote, 1, p2p.NewPeer(localID, "local", nil), remoteRW)
+}
+
+// Test Handshake
+//   - test both sides run the highest version they both support
+//   - test peers without a common version are rejected
+func TestPeer_Handshake(t *testing.T) {
+	genesis := types.HexToHashPanic("0123456789012345678901234567890123456789012345678901234567890123")
+	tests := []struct {
+		local, remote int
+		want          int
+	}{
+		{eth65, eth65, eth65},
+		{eth65, eth64, eth64},
+		{eth65, eth63, eth63},
+		{eth62, eth65, eth62},
+		{eth61, eth65, eth61},
+	}
+	for i, test := range tests {
+		local, remote := testHandshakePeers(test.local, test.remote)
+		errc := make(chan error, 1)
+		go func() { errc <- remote.Handshake(1, genesis, genesis) }()
+		if err := local.Handshake(1, genesis, genesis); err != nil {
+			t.Fatalf("test %d: local handshake failed: %v", i, err)
+		}
+		if err := <-errc; err != nil {
+			t.Fatalf("test %d: remote handshake failed: %v", i, err)
+		}
+		if local.version != test.want || remote.version != test.want {
+			t.Errorf("test %d: negotiated versions %d and %d, want %d", i, local.version, remote.version, test.want)
+		}
+	}
+
+	local, remote := testHandshakePeers(eth65, 60)
+	go func() { _ = remote.Handshake(1, genesis, genesis) }()
+	err := local.Handshake(1, genesis, genesis)
+	if err == nil || !strings.HasPrefix(err.Error(), errCode(ErrProtocolVersionMismatch).String()) {
+		t.Fatalf("expected a protocol version mismatch, got %v", err)
+	}
+}
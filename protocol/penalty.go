@@ -0,0 +1,131 @@
+package protocol
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	invalidMomentumPenalty = 10            // Penalty of a peer for each momentum rejected by the verifier
+	malformedMsgPenalty    = 10            // Penalty of a peer for each message rejected by the decoder
+	maxPeerPenalty         = 30            // Peers reaching this penalty are disconnected and banned
+	penaltyForgiveInterval = time.Minute   // Interval after which one penalty point is forgiven
+	peerBanDuration        = 6 * time.Hour // Duration for which banned peers are refused
+	maxTrackedPenalties    = 1024          // Number of tracked peers before expired entries are removed
+)
+
+var (
+	invalidMomentumCounter = metrics.NewRegisteredCounter("protocol/momentums/invalid", nil)
+	rejectedMsgCounter     = metrics.NewRegisteredCounter("protocol/messages/rejected", nil)
+	bannedPeerCounter      = metrics.NewRegisteredCounter("protocol/peers/banned", nil)
+	refusedPeerCounter     = metrics.NewRegisteredCounter("protocol/peers/refused", nil)
+)
+
+type penalty struct {
+	points   int
+	updated  time.Time
+	banned   time.Time // zero if the peer is not banned
+	rejected uint64    // number of malformed messages
+}
+
+// peerPenalties keeps the penalties of peers which propagated invalid momentums or sent malformed messages.
+// Penalties are forgiven over time, so only peers repeatedly misbehaving reach maxPeerPenalty and get banned.
+type peerPenalties struct {
+	lock      sync.Mutex
+	penalties map[string]*penalty
+}
+
+func newPeerPenalties() *peerPenalties {
+	return &peerPenalties{
+		penalties: make(map[string]*penalty),
+	}
+}
+
+// current forgives the points of p since its last update.
+func (pp *peerPenalties) current(p *penalty, now time.Time) int {
+	forgiven := int(now.Sub(p.updated) / penaltyForgiveInterval)
+	if forgiven >= p.points {
+		return 0
+	}
+	return p.points - forgiven
+}
+
+// penalize adds points to the penalty of peer id and returns true if the peer has been banned.
+func (pp *peerPenalties) penalize(id string, points int) bool {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+	return pp.add(id, points, time.Now())
+}
+
+// reject counts a malformed message of peer id and penalizes it, returns true if the peer has been banned.
+func (pp *peerPenalties) reject(id string) bool {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+	banned := pp.add(id, malformedMsgPenalty, time.Now())
+	pp.penalties[id].rejected += 1
+	return banned
+}
+
+// add adds points to the penalty of peer id. The caller must hold pp.lock.
+func (pp *peerPenalties) add(id string, points int, now time.Time) bool {
+	p, ok := pp.penalties[id]
+	if !ok {
+		if len(pp.penalties) >= maxTrackedPenalties {
+			pp.expire(now)
+		}
+		p = &penalty{}
+		pp.penalties[id] = p
+	}
+	p.points = pp.current(p, now) + points
+	p.updated = now
+	if p.points >= maxPeerPenalty {
+		p.points = 0
+		p.banned = now
+		return true
+	}
+	return false
+}
+
+// isBanned returns true if the peer id has been banned less than peerBanDuration ago.
+func (pp *peerPenalties) isBanned(id string) bool {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+
+	p, ok := pp.penalties[id]
+	return ok && !p.banned.IsZero() && time.Now().Sub(p.banned) < peerBanDuration
+}
+
+// get returns the current penalty of peer id.
+func (pp *peerPenalties) get(id string) int {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+
+	p, ok := pp.penalties[id]
+	if !ok {
+		return 0
+	}
+	return pp.current(p, time.Now())
+}
+
+// rejected returns the number of malformed messages of peer id since it's tracked.
+func (pp *peerPenalties) rejected(id string) uint64 {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+
+	p, ok := pp.penalties[id]
+	if !ok {
+		return 0
+	}
+	return p.rejected
+}
+
+// expire removes the peers which are neither penalized nor banned anymore. The caller must hold pp.lock.
+func (pp *peerPenalties) expire(now time.Time) {
+	for id, p := range pp.penalties {
+		if pp.current(p, now) == 0 && (p.banned.IsZero() || now.Sub(p.banned) >= peerBanDuration) {
+			delete(pp.penalties, id)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"math/rand"
+	"net"
+	"sort"
+)
+
+// selectPropagationPeers picks num of the peers to propagate a momentum to. Most slots go to the peers
+// with the lowest latency, diversityPercent of them to peers from networks which aren't picked yet.
+// Low latency peers tend to be close to each other, the network prefix is used as a proxy for their location.
+func selectPropagationPeers(peers []*peer, num int, diversityPercent int) []*peer {
+	if num >= len(peers) {
+		return peers
+	}
+	if diversityPercent < 0 {
+		diversityPercent = 0
+	} else if diversityPercent > 100 {
+		diversityPercent = 100
+	}
+	sorted := make([]*peer, len(peers))
+	copy(sorted, peers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Latency(), sorted[j].Latency()
+		// peers without measurement go last
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+
+	fast := num - num*diversityPercent/100
+	selected := make([]*peer, 0, num)
+	networks := make(map[string]bool)
+	for _, p := range sorted[:fast] {
+		selected = append(selected, p)
+		networks[peerNetwork(p)] = true
+	}
+
+	rest := sorted[fast:]
+	rand.Shuffle(len(rest), func(i, j int) { rest[i], rest[j] = rest[j], rest[i] })
+	var fallback []*peer
+	for _, p := range rest {
+		if len(selected) == num {
+			return selected
+		}
+		if network := peerNetwork(p); !networks[network] {
+			selected = append(selected, p)
+			networks[network] = true
+		} else {
+			fallback = append(fallback, p)
+		}
+	}
+	return append(selected, fallback[:num-len(selected)]...)
+}
+
+// splitTrustedPeers separates the static and trusted peers from the others.
+func splitTrustedPeers(peers []*peer) (trusted, others []*peer) {
+	for _, p := range peers {
+		if p.Trusted() {
+			trusted = append(trusted, p)
+		} else {
+			others = append(others, p)
+		}
+	}
+	return trusted, others
+}
+
+// legacyPropagationPeers returns the peers before eth/65, which expect the new momentums to be pushed.
+func legacyPropagationPeers(peers []*peer) []*peer {
+	legacy := make([]*peer, 0, len(peers))
+	for _, p := range peers {
+		if !p.announcesByHash() {
+			legacy = append(legacy, p)
+		}
+	}
+	return legacy
+}
+
+// peerNetwork returns the /16 prefix of IPv4 and the /32 prefix of IPv6 addresses.
+func peerNetwork(p *peer) string {
+	addr, ok := p.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(16, 32)).String()
+	}
+	return addr.IP.Mask(net.CIDRMask(32, 128)).String()
+}
@@ -17,17 +17,63 @@
 package protocol
 
 import (
+	"math/big"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/p2p"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	eth61 = 61
+	eth62 = 62 // adds no message, the codes of its warp sync messages are reserved
+	eth63 = 63 // advertises all the supported versions in the status message
+	eth64 = 64 // adds the light client messages
+	eth65 = 65 // announces the momentums by hash and height instead of pushing their bodies
+	eth66 = 66 // adds the election messages, so the light clients check the producers of the headers
 )
 
 // Supported versions of the eth protocol (first is primary).
-var ProtocolVersions = []uint{61}
+var ProtocolVersions = []uint{eth66, eth65, eth64, eth63, eth62, eth61}
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{9}
+var ProtocolLengths = []uint64{20, 18, 17, 13, 9, 9}
+
+// protocolLength returns the number of messages implemented by version, 0 if it isn't supported.
+func protocolLength(version int) uint64 {
+	for i, v := range ProtocolVersions {
+		if int(v) == version {
+			return ProtocolLengths[i]
+		}
+	}
+	return 0
+}
+
+// negotiateVersion returns the highest version supported by both sides which doesn't exceed the
+// version of the running sub-protocol, 0 if there is none. Peers before eth/63 only advertise the
+// version they are running.
+func negotiateVersion(running int, status *statusData) int {
+	remote := status.Versions
+	if len(remote) == 0 {
+		remote = []uint32{status.ProtocolVersion}
+	}
+	for _, v := range ProtocolVersions {
+		if int(v) > running {
+			continue
+		}
+		for _, r := range remote {
+			if uint(r) == v {
+				return int(v)
+			}
+		}
+	}
+	return 0
+}
 
 const (
-	ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
+	ProtocolMaxMsgSize   = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
+	MaxAccountStateFetch = 64               // Amount of account states to be fetched per retrieval request
 )
 
 // eth protocol message codes
@@ -41,8 +87,39 @@ const (
 	BlocksMsg
 	NewBlockMsg
 	GetBlockHashesFromNumberMsg
+
+	// The codes of the eth/62 warp sync messages, which were dropped, are reserved. Warp sync was declined:
+	// momentums don't commit to a state root, so the account states fetched from the peers can't be verified.
+	_
+	_
+	_
+	_
+
+	// Protocol messages belonging to eth/64
+	GetMomentumHeadersMsg
+	MomentumHeadersMsg
+	GetAccountProofsMsg
+	AccountProofsMsg
+
+	// Protocol messages belonging to eth/65
+	NewMomentumHashesMsg
+
+	// Protocol messages belonging to eth/66
+	GetProducersMsg
+	ProducersMsg
 )
 
+// msgPriority lets the momentum announcements preempt the queued sync payloads,
+// so new momentums propagate quickly even to peers which are still syncing from us.
+func msgPriority(code uint64) p2p.MsgPriority {
+	switch code {
+	case NewBlockHashesMsg, NewBlockMsg, NewMomentumHashesMsg:
+		return p2p.PriorityHigh
+	default:
+		return p2p.PriorityNormal
+	}
+}
+
 type errCode int
 
 const (
@@ -55,6 +132,7 @@ const (
 	ErrNoStatusMsg
 	ErrExtraStatusMsg
 	ErrSuspendedPeer
+	ErrPayloadTooLarge
 )
 
 func (e errCode) String() string {
@@ -72,6 +150,7 @@ var errorToString = map[int]string{
 	ErrNoStatusMsg:             "No status message",
 	ErrExtraStatusMsg:          "Extra status message",
 	ErrSuspendedPeer:           "Suspended peer",
+	ErrPayloadTooLarge:         "Payload too large",
 }
 
 // statusData is the network packet for the status message.
@@ -81,6 +160,10 @@ type statusData struct {
 	TD              uint64
 	CurrentBlock    types.Hash
 	GenesisBlock    types.Hash
+
+	// Versions lists the versions supported by the sender up to the running one, highest first. It's only sent
+	// from eth/63 on, omitting it keeps the encoding of the older versions.
+	Versions []uint32 `rlp:"optional"`
 }
 
 // getBlockHashesData is the network packet for the hash based block retrieval
@@ -96,3 +179,44 @@ type getBlockHashesFromNumberData struct {
 	Number uint64
 	Amount uint64
 }
+
+type AccountStateBalance struct {
+	TokenStandard types.ZenonTokenStandard
+	Amount        *big.Int
+}
+
+// getMomentumHeadersData is the network packet for the retrieval of the momentums,
+// without their account-blocks, starting at a height.
+type getMomentumHeadersData struct {
+	Height uint64
+	Amount uint64
+}
+
+// getAccountProofsData is the network packet for the retrieval of the account
+// proofs at a momentum.
+type getAccountProofsData struct {
+	Momentum  types.Hash
+	Addresses []types.Address
+}
+
+// getProducersData is the network packet for the retrieval of the producers elected for a tick.
+type getProducersData struct {
+	Tick uint64
+}
+
+// producersData is the network packet of the producers elected for a tick, in the order of their
+// slots. Producers is empty if the peer doesn't know the election yet.
+type producersData struct {
+	Tick      uint64
+	Producers []types.Address
+}
+
+// AccountProof is the state of an account at a momentum, along with the frontier account-block
+// and the height of the momentum which confirms it. The frontier can be checked against the
+// content of that momentum, the balances can't since momentums don't commit to the state.
+type AccountProof struct {
+	Address            types.Address
+	Balances           []*AccountStateBalance
+	FrontierBlock      *nom.AccountBlock `rlp:"nil"`
+	ConfirmationHeight uint64
+}
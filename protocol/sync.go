@@ -18,16 +18,54 @@ package protocol
 
 import (
 	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/p2p/discover"
+	"github.com/zenon-network/go-zenon/protocol/downloader"
 )
 
 const (
 	forceSyncCycle = 4 * time.Second // Time interval to force syncs, even if few peers are available
+	rateSmoothing  = 0.25            // Weight of the latest sample in the momentum import rate
+	txsyncPackSize = 256             // Number of account-blocks sent per transaction message, see maxTxsPerMsg
 )
 
+// syncProgress tracks the momentum import rate of the local chain.
+// It is sampled periodically by the syncer.
+type syncProgress struct {
+	height uint64    // Height of the local chain at the last sample
+	time   time.Time // Time instance of the last sample
+	rate   float64   // Smoothed number of momentums imported per second
+	lock   sync.Mutex
+}
+
+// sample updates the import rate with the height of the local chain.
+func (s *syncProgress) sample(height uint64, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// rollbacks invalidate the previous sample
+	if !s.time.IsZero() && height >= s.height {
+		if elapsed := now.Sub(s.time).Seconds(); elapsed > 0 {
+			current := float64(height-s.height) / elapsed
+			s.rate = rateSmoothing*current + (1-rateSmoothing)*s.rate
+		}
+	}
+	s.height = height
+	s.time = now
+}
+
+// Rate retrieves the smoothed number of momentums imported per second.
+func (s *syncProgress) Rate() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.rate
+}
+
 type txsync struct {
 	p   *peer
 	txs []*nom.AccountBlock
@@ -62,7 +100,7 @@ func (pm *ProtocolManager) txsyncLoop() {
 		// Fill pack with transactions up to the target size.
 		pack.p = s.p
 		pack.txs = pack.txs[:0]
-		for i := 0; i < len(s.txs); i++ {
+		for i := 0; i < len(s.txs) && len(pack.txs) < txsyncPackSize; i++ {
 			pack.txs = append(pack.txs, s.txs[i])
 		}
 		// Remove the transactions that will be sent.
@@ -138,6 +176,8 @@ func (pm *ProtocolManager) syncer() {
 			}()
 
 		case <-forceSync:
+			pm.progress.sample(pm.chainman.CurrentBlock().Height, time.Now())
+
 			// Force a sync even if not enough peers are present
 			if pm.peers.Len() < pm.minPeers {
 				break
@@ -173,11 +213,61 @@ func (pm *ProtocolManager) syncInfo() *SyncInfo {
 		state = NotEnoughPeers
 	}
 
+	// estimate the completion time based on the recent import rate
+	rate := pm.progress.Rate()
+	estimate := int64(0)
+	if state == Syncing && rate > 0 {
+		remaining := time.Duration(float64(targetHeight-currentHeight) / rate * float64(time.Second))
+		estimate = time.Now().Add(remaining).Unix()
+	}
+
 	return &SyncInfo{
-		State:         state,
-		CurrentHeight: currentHeight,
-		TargetHeight:  targetHeight,
+		State:               state,
+		CurrentHeight:       currentHeight,
+		TargetHeight:        targetHeight,
+		MomentumsPerSecond:  rate,
+		EstimatedCompletion: estimate,
+		Peers:               pm.syncPeers(),
+	}
+}
+
+// syncPeers retrieves the height and download statistics of all peers, highest first.
+func (pm *ProtocolManager) syncPeers() []*SyncPeerInfo {
+	stats := make(map[string]downloader.PeerStats)
+	for _, s := range pm.downloader.PeerStats() {
+		stats[s.Id] = s
 	}
+
+	peers := pm.peers.AllPeers()
+	list := make([]*SyncPeerInfo, 0, len(peers))
+	for _, p := range peers {
+		info := &SyncPeerInfo{
+			PublicKey:        p.Peer.ID().String(),
+			Version:          p.version,
+			Height:           p.Td(),
+			Penalty:          pm.penalties.get(p.id),
+			RejectedMessages: pm.penalties.rejected(p.id),
+		}
+		if s, ok := stats[p.id]; ok {
+			info.DeliveredMomentums = s.Delivered
+			info.Timeouts = s.Timeouts
+			info.Failures = s.Failures
+			info.Reputation = s.Reputation
+			info.Capacity = s.Capacity
+			info.Idle = s.Idle
+			if !s.LastDelivery.IsZero() {
+				info.LastDelivery = s.LastDelivery.Unix()
+			}
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Height != list[j].Height {
+			return list[i].Height > list[j].Height
+		}
+		return list[i].PublicKey < list[j].PublicKey
+	})
+	return list
 }
 
 // synchronise tries to sync up our local block chain with a remote peer.
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/p2p"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+)
+
+// AdminApi changes the node at runtime, it's not public and only served if whitelisted or over IPC.
+type AdminApi struct {
+	p2p *p2p.Server
+	log log15.Logger
+}
+
+func NewAdminApi(p2p *p2p.Server) *AdminApi {
+	return &AdminApi{
+		p2p: p2p,
+		log: common.RPCLogger.New("module", "admin_api"),
+	}
+}
+
+func (api *AdminApi) GetPeerSlots() *p2p.PeerSlots {
+	return api.p2p.PeerSlots()
+}
+func (api *AdminApi) GetHandshakeStats() *p2p.HandshakeStats {
+	return api.p2p.HandshakeStats()
+}
+
+// GetPeerDistribution returns how the peers spread across subnets and the inbound connections across IPs.
+func (api *AdminApi) GetPeerDistribution() *p2p.PeerDistribution {
+	return api.p2p.PeerDistribution()
+}
+
+// GetRecentDisconnects returns the last peer disconnects with their reason, the most recent first.
+func (api *AdminApi) GetRecentDisconnects() []*p2p.Disconnect {
+	return api.p2p.RecentDisconnects()
+}
+func (api *AdminApi) SetMaxPeers(maxPeers int) error {
+	api.log.Info("SetMaxPeers", "max-peers", maxPeers)
+	return api.p2p.SetMaxPeers(maxPeers)
+}
+
+// AddApiKey adds an API key for the HTTP and WS endpoints, it's kept until the node restarts.
+func (api *AdminApi) AddApiKey(key *rpc.APIKey) error {
+	if key == nil {
+		return ErrParamIsNull
+	}
+	api.log.Info("AddApiKey", "name", key.Name, "rate-limit", key.RateLimit)
+	return rpc.AddAPIKey(key)
+}
+func (api *AdminApi) RemoveApiKey(name string) error {
+	api.log.Info("RemoveApiKey", "name", name)
+	return rpc.RemoveAPIKey(name)
+}
+
+// GetApiKeys returns the API keys sorted by name, without their secret.
+func (api *AdminApi) GetApiKeys() []*rpc.APIKey {
+	return rpc.APIKeys()
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+// AutoReceiveConfig configures the AutoReceiver. The unreceived blocks of the Addresses are checked every
+// Interval and at most MaxBlocks receive blocks are published each time, over all the addresses. Without PoW,
+// the blocks of an address which doesn't have enough fused plasma wait until it has.
+type AutoReceiveConfig struct {
+	Addresses []types.Address
+	Interval  time.Duration
+	MaxBlocks int
+	PoW       bool
+}
+
+// AutoReceiver publishes the receive blocks of the blocks sent to the configured addresses, like the hot
+// wallets of the exchanges do. The blocks are signed with the key stores unlocked through the WalletApi,
+// an address is only received while one of them derives it.
+type AutoReceiver struct {
+	log    log15.Logger
+	z      zenon.Zenon
+	wallet *WalletApi
+	// pool computes the PoW if not nil, otherwise it's computed on the receiver goroutine
+	pool   *pow.Pool
+	config *AutoReceiveConfig
+
+	// key stores which derive the addresses, found ones are kept until they are locked
+	keys map[types.Address]*autoReceiveKey
+	// addresses none of the unlocked key stores derives, they are only looked for again once the key stores
+	// unlocked change, since looking for them derives many keys of each key store
+	missing map[types.Address]bool
+	// the unlocked key stores when missing was computed
+	searched string
+	// index in config.Addresses of the first address checked, so MaxBlocks doesn't starve the last ones
+	next int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type autoReceiveKey struct {
+	path    string
+	keyPair *wallet.KeyPair
+}
+
+func NewAutoReceiver(z zenon.Zenon, walletApi *WalletApi, pool *pow.Pool, config *AutoReceiveConfig) *AutoReceiver {
+	return &AutoReceiver{
+		log:     common.WalletLogger.New("submodule", "auto-receiver"),
+		z:       z,
+		wallet:  walletApi,
+		pool:    pool,
+		config:  config,
+		keys:    make(map[types.Address]*autoReceiveKey),
+		missing: make(map[types.Address]bool),
+	}
+}
+
+func (r *AutoReceiver) Start() error {
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.loop()
+	}()
+	r.log.Info("auto-receiving blocks", "addresses", len(r.config.Addresses), "interval", r.config.Interval, "max-blocks", r.config.MaxBlocks, "pow", r.config.PoW)
+	return nil
+}
+func (r *AutoReceiver) Stop() error {
+	// also aborts the PoW in progress
+	r.cancel()
+	r.wg.Wait()
+	return nil
+}
+
+func (r *AutoReceiver) loop() {
+	defer common.RecoverStack()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-time.After(r.config.Interval):
+			r.process()
+		}
+	}
+}
+
+func (r *AutoReceiver) process() {
+	if r.z.Broadcaster().SyncInfo().State != protocol.SyncDone {
+		r.log.Debug("skip auto-receive, sync not done")
+		return
+	}
+
+	if searched := strings.Join(r.wallet.unlockedPaths(), "\n"); searched != r.searched {
+		r.searched = searched
+		r.missing = make(map[types.Address]bool)
+	}
+
+	remaining := r.config.MaxBlocks
+	addresses := r.config.Addresses
+	for i := range addresses {
+		address := addresses[(r.next+i)%len(addresses)]
+		if remaining == 0 {
+			r.next = (r.next + i) % len(addresses)
+			r.log.Info("auto-receive limit reached, the rest is received next time", "max-blocks", r.config.MaxBlocks)
+			return
+		}
+		keyPair := r.keyPair(address)
+		if keyPair == nil {
+			continue
+		}
+		received, err := r.receive(keyPair, remaining)
+		remaining -= received
+		if err != nil {
+			r.log.Warn("failed to auto-receive", "address", address, "reason", err)
+		}
+		if r.ctx.Err() != nil {
+			return
+		}
+	}
+	r.next = 0
+}
+
+// keyPair returns the key pair of the address, nil if none of the unlocked key stores derives it.
+func (r *AutoReceiver) keyPair(address types.Address) *wallet.KeyPair {
+	if key, ok := r.keys[address]; ok {
+		if r.wallet.isUnlocked(key.path) {
+			return key.keyPair
+		}
+		delete(r.keys, address)
+		r.log.Info("stop auto-receiving, key store locked", "address", address, "path", key.path)
+	}
+
+	if r.missing[address] {
+		return nil
+	}
+	path, keyPair, err := r.wallet.findKeyPair(address)
+	if err != nil {
+		r.missing[address] = true
+		return nil
+	}
+	r.keys[address] = &autoReceiveKey{path: path, keyPair: keyPair}
+	r.log.Info("start auto-receiving", "address", address, "path", path)
+	return keyPair
+}
+
+// receive publishes the receive blocks of at most limit unreceived blocks of the address of keyPair,
+// it returns the number of published blocks.
+func (r *AutoReceiver) receive(keyPair *wallet.KeyPair, limit int) (int, error) {
+	address := keyPair.Address
+	chain := r.z.Chain()
+	hashes, err := chain.GetFrontierMomentumStore().GetAccountMailbox(address).GetUnreceivedAccountBlockHashes(unreceivedQuerySize)
+	if err != nil {
+		return 0, err
+	}
+
+	supervisor := vm.NewSupervisor(chain, r.z.Consensus())
+	received := 0
+	for _, hash := range hashes {
+		if received == limit {
+			break
+		}
+		// the receive blocks published before are in the account chain until a momentum confirms them
+		if chain.GetFrontierAccountStore(address).IsReceived(hash) {
+			continue
+		}
+
+		template := &nom.AccountBlock{
+			BlockType:     nom.BlockTypeUserReceive,
+			Address:       address,
+			FromBlockHash: hash,
+		}
+		if err := r.setPlasma(supervisor, template); err != nil {
+			return received, err
+		}
+		transaction, err := supervisor.GenerateFromTemplate(template, keyPair.Signer)
+		if err != nil {
+			return received, err
+		}
+		r.z.Broadcaster().CreateAccountBlock(transaction)
+		received += 1
+		r.log.Info("auto-received block", "address", address, "from-block-hash", hash, "hash", transaction.Block.Hash, "difficulty", transaction.Block.Difficulty)
+	}
+	return received, nil
+}
+
+// setPlasma makes up with PoW for the fused plasma the address lacks, if allowed.
+func (r *AutoReceiver) setPlasma(supervisor *vm.Supervisor, template *nom.AccountBlock) error {
+	// the PoW is bound to the previous hash
+	if err := supervisor.FillTemplate(template); err != nil {
+		return err
+	}
+	_, context, err := GetFrontierContext(r.z.Chain(), template.Address)
+	if err != nil {
+		return err
+	}
+	available, err := vm.AvailablePlasma(context.MomentumStore(), context)
+	if err != nil {
+		return err
+	}
+	base, err := vm.GetBasePlasmaForAccountBlock(context, template)
+	if err != nil {
+		return err
+	}
+	if available >= base {
+		return nil
+	}
+	if !r.config.PoW {
+		return constants.ErrNotEnoughPlasma
+	}
+
+	difficulty, err := vm.GetDifficultyForPlasma(base - available)
+	if err != nil {
+		return err
+	}
+	template.FusedPlasma = available
+	template.Difficulty = difficulty
+	dataHash := pow.GetAccountBlockHash(template)
+	if r.pool != nil {
+		nonce, err := r.pool.Generate(r.ctx, dataHash, difficulty)
+		if err != nil {
+			return errors.Wrap(err, "failed to compute the PoW")
+		}
+		template.Nonce = *nonce
+		return nil
+	}
+	nonce, err := pow.GetPoWNonceWithContext(r.ctx, new(big.Int).SetUint64(difficulty), dataHash)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute the PoW")
+	}
+	copy(template.Nonce.Data[:], nonce)
+	return nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// Kinds of cursors, a cursor returned by one list can't be used with another.
+const (
+	accountBlocksCursor byte = iota + 1
+	momentumsCursor
+	memoCursor
+)
+
+const cursorLen = 1 + 8 + types.HashSize
+
+// cursor is the position of a list of the chain, the height and hash of the last account-block or momentum
+// returned. The next page starts right below it, so pages don't move when new blocks are added to the top, and
+// the hash invalidates the cursor if its page is rolled back.
+// Clients receive it as an opaque continuation token, see encodeCursor.
+type cursor struct {
+	kind   byte
+	height uint64
+	hash   types.Hash
+}
+
+func encodeCursor(kind byte, height uint64, hash types.Hash) string {
+	data := make([]byte, cursorLen)
+	data[0] = kind
+	binary.BigEndian.PutUint64(data[1:9], height)
+	copy(data[9:], hash.Bytes())
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a continuation token of the kind, an empty token is the start of the list and returns nil.
+func decodeCursor(kind byte, token string) (*cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) != cursorLen || data[0] != kind {
+		return nil, ErrInvalidCursor
+	}
+	c := &cursor{
+		kind:   kind,
+		height: binary.BigEndian.Uint64(data[1:9]),
+	}
+	copy(c.hash[:], data[9:])
+	if c.height == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// check returns ErrCursorRolledBack unless the block at the height of the cursor still has its hash.
+func (c *cursor) check(hash *types.Hash) error {
+	if hash == nil || *hash != c.hash {
+		return ErrCursorRolledBack
+	}
+	return nil
+}
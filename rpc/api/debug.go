@@ -0,0 +1,240 @@
+package api
+
+import (
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/debug"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	maxBlockProfileSeconds = 300
+	maxContentCheckCount   = 10 * RpcMaxCountSize
+)
+
+// DebugApi captures profiles of the node, it's not public and only served if whitelisted or over IPC.
+// Profiles are written in the profiles directory of the node, file names can't escape it.
+type DebugApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewDebugApi(z zenon.Zenon) *DebugApi {
+	return &DebugApi{
+		chain: z.Chain(),
+		log:   common.RPCLogger.New("module", "debug_api"),
+	}
+}
+
+// StartCPUProfile turns on CPU profiling, an empty file name defaults to a timestamped one.
+func (api *DebugApi) StartCPUProfile(file string) (string, error) {
+	api.log.Info("StartCPUProfile", "file", file)
+	return debug.Handler.StartCPUProfile(file)
+}
+func (api *DebugApi) StopCPUProfile() (string, error) {
+	api.log.Info("StopCPUProfile")
+	return debug.Handler.StopCPUProfile()
+}
+func (api *DebugApi) WriteHeapProfile(file string) (string, error) {
+	api.log.Info("WriteHeapProfile", "file", file)
+	return debug.Handler.WriteHeapProfile(file)
+}
+
+// SetLogLevel sets the log level of the module, e.g. "p2p", "chain", "vm" or "rpc". An empty module
+// sets the level of all the modules without an override, an empty level removes the override of the module.
+func (api *DebugApi) SetLogLevel(module, level string) error {
+	api.log.Info("SetLogLevel", "module", module, "level", level)
+	return common.SetLogLevel(module, level)
+}
+
+// BlockProfile turns on goroutine blocking profiling for the given number of seconds.
+func (api *DebugApi) BlockProfile(file string, seconds uint64) (string, error) {
+	if seconds > maxBlockProfileSeconds {
+		return "", ErrCountParamTooBig
+	}
+	api.log.Info("BlockProfile", "file", file, "seconds", seconds)
+	return debug.Handler.BlockProfile(file, time.Duration(seconds)*time.Second)
+}
+
+// TraceAccountBlock returns what the embedded method called by the contract receive-block did, it requires the
+// node to run with the tracer on. Returns nil if the block wasn't applied since the tracer was turned on.
+func (api *DebugApi) TraceAccountBlock(hash types.Hash) (*vm.Trace, error) {
+	tracer := vm.GetTracer()
+	if tracer == nil {
+		return nil, ErrTracerDisabled
+	}
+	trace, err := tracer.GetTrace(hash)
+	if err != nil {
+		api.log.Error("TraceAccountBlock failed", "reason", err, "method-called", "GetTrace")
+		return nil, err
+	}
+	return trace, nil
+}
+
+// GetAccountChainConflicts returns the account-blocks seen by the node at the height of another block of their
+// account-chain since it started, the most recent first. Both blocks are kept as evidence.
+func (api *DebugApi) GetAccountChainConflicts() []*chain.AccountChainConflict {
+	return api.chain.GetAccountChainConflicts()
+}
+
+// Anomalies of the momentum content found by CheckMomentumContent.
+const (
+	// the account-block is included by more than one momentum
+	ContentAnomalyDuplicate = "duplicate"
+	// another account-block with the same address and height is included
+	ContentAnomalyConflictingHeight = "conflictingHeight"
+	// the included account-block isn't in the store
+	ContentAnomalyMissingBlock = "missingBlock"
+	// the stored confirmation height of the account-block isn't the momentum which includes it
+	ContentAnomalyConfirmationMismatch = "confirmationMismatch"
+)
+
+type ContentAnomaly struct {
+	Type    string        `json:"type"`
+	Hash    types.Hash    `json:"hash"`
+	Address types.Address `json:"address"`
+	Height  uint64        `json:"height"`
+	// MomentumHeights are the momentums in the range which include the account-block
+	MomentumHeights []uint64 `json:"momentumHeights"`
+	// ConflictingHash is set for conflictingHeight, the block included first at the same height
+	ConflictingHash *types.Hash `json:"conflictingHash,omitempty"`
+	// ConfirmationHeight is the stored confirmation height, set for confirmationMismatch
+	ConfirmationHeight uint64 `json:"confirmationHeight,omitempty"`
+}
+
+type ContentCheck struct {
+	FromHeight    uint64            `json:"fromHeight"`
+	ToHeight      uint64            `json:"toHeight"`
+	AccountBlocks int               `json:"accountBlocks"`
+	Anomalies     []*ContentAnomaly `json:"anomalies"`
+}
+
+// CheckMomentumContent verifies that the account-blocks included by the momentums in [fromHeight, toHeight]
+// are included only once, are stored and are confirmed by the momentum which includes them. It's an integrity
+// check of the database after crashes or disk errors, toHeight is capped at the frontier.
+func (api *DebugApi) CheckMomentumContent(fromHeight, toHeight uint64) (*ContentCheck, error) {
+	if fromHeight == 0 {
+		return nil, ErrHeightParamIsZero
+	}
+	if toHeight < fromHeight {
+		return nil, ErrInvalidHeightRange
+	}
+	if toHeight-fromHeight >= maxContentCheckCount {
+		return nil, ErrCountParamTooBig
+	}
+	api.log.Info("CheckMomentumContent", "from", fromHeight, "to", toHeight)
+
+	momentumStore := api.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		api.log.Error("CheckMomentumContent failed", "reason", err, "method-called", "momentumStore.GetFrontierMomentum")
+		return nil, err
+	}
+	if toHeight > frontier.Height {
+		toHeight = frontier.Height
+	}
+
+	type accountHeight struct {
+		address types.Address
+		height  uint64
+	}
+	check := &ContentCheck{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		Anomalies:  make([]*ContentAnomaly, 0),
+	}
+	included := make(map[types.Hash][]uint64)
+	duplicates := make(map[types.Hash]*ContentAnomaly)
+	byAccountHeight := make(map[accountHeight]types.Hash)
+
+	for height := fromHeight; height <= toHeight; height += RpcMaxCountSize {
+		count := toHeight - height + 1
+		if count > RpcMaxCountSize {
+			count = RpcMaxCountSize
+		}
+		momentums, err := momentumStore.GetMomentumsByHeight(height, true, count)
+		if err != nil {
+			api.log.Error("CheckMomentumContent failed", "reason", err, "method-called", "momentumStore.GetMomentumsByHeight")
+			return nil, err
+		}
+		for _, momentum := range momentums {
+			for _, header := range momentum.Content {
+				check.AccountBlocks += 1
+				heights, seen := included[header.Hash]
+				included[header.Hash] = append(heights, momentum.Height)
+				if seen {
+					if anomaly, ok := duplicates[header.Hash]; ok {
+						anomaly.MomentumHeights = included[header.Hash]
+						continue
+					}
+					anomaly := &ContentAnomaly{
+						Type:            ContentAnomalyDuplicate,
+						Hash:            header.Hash,
+						Address:         header.Address,
+						Height:          header.Height,
+						MomentumHeights: included[header.Hash],
+					}
+					duplicates[header.Hash] = anomaly
+					check.Anomalies = append(check.Anomalies, anomaly)
+					continue
+				}
+
+				key := accountHeight{address: header.Address, height: header.Height}
+				if other, ok := byAccountHeight[key]; ok {
+					conflicting := other
+					check.Anomalies = append(check.Anomalies, &ContentAnomaly{
+						Type:            ContentAnomalyConflictingHeight,
+						Hash:            header.Hash,
+						Address:         header.Address,
+						Height:          header.Height,
+						MomentumHeights: []uint64{momentum.Height},
+						ConflictingHash: &conflicting,
+					})
+				} else {
+					byAccountHeight[key] = header.Hash
+				}
+
+				block, err := momentumStore.GetAccountBlockByHash(header.Hash)
+				if err != nil {
+					api.log.Error("CheckMomentumContent failed", "reason", err, "method-called", "momentumStore.GetAccountBlockByHash")
+					return nil, err
+				}
+				if block == nil {
+					check.Anomalies = append(check.Anomalies, &ContentAnomaly{
+						Type:            ContentAnomalyMissingBlock,
+						Hash:            header.Hash,
+						Address:         header.Address,
+						Height:          header.Height,
+						MomentumHeights: []uint64{momentum.Height},
+					})
+				}
+
+				confirmationHeight, err := momentumStore.GetBlockConfirmationHeight(header.Hash)
+				if err != nil {
+					api.log.Error("CheckMomentumContent failed", "reason", err, "method-called", "momentumStore.GetBlockConfirmationHeight")
+					return nil, err
+				}
+				if confirmationHeight != momentum.Height {
+					check.Anomalies = append(check.Anomalies, &ContentAnomaly{
+						Type:               ContentAnomalyConfirmationMismatch,
+						Hash:               header.Hash,
+						Address:            header.Address,
+						Height:             header.Height,
+						MomentumHeights:    []uint64{momentum.Height},
+						ConfirmationHeight: confirmationHeight,
+					})
+				}
+			}
+		}
+	}
+	if len(check.Anomalies) != 0 {
+		api.log.Warn("found anomalies in the momentum content", "from", fromHeight, "to", toHeight, "count", len(check.Anomalies))
+	}
+	return check, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"math/big"
 	"sort"
+	"strings"
 
 	"github.com/inconshreveable/log15"
 
@@ -192,6 +193,108 @@ func (a *AcceleratorApi) GetAll(pageIndex, pageSize uint32) (*ProjectList, error
 
 	return result, nil
 }
+
+// ProjectFilter selects the projects returned by GetProjects. Unset fields match every project.
+// Search is matched case-insensitively against the name and the description of the project.
+type ProjectFilter struct {
+	Status *uint8         `json:"status"`
+	Owner  *types.Address `json:"owner"`
+	Search string         `json:"search"`
+	// SortBy is one of "lastUpdate" (default), "creation", "znnFunds" or "qsrFunds"
+	SortBy    string `json:"sortBy"`
+	Ascending bool   `json:"ascending"`
+}
+
+func (f *ProjectFilter) matches(project *definition.Project) bool {
+	if f.Status != nil && project.Status != *f.Status {
+		return false
+	}
+	if f.Owner != nil && project.Owner != *f.Owner {
+		return false
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(project.Name), search) && !strings.Contains(strings.ToLower(project.Description), search) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *ProjectFilter) less(a, b *definition.Project) bool {
+	var cmp int
+	switch f.SortBy {
+	case "creation":
+		cmp = compareInt64(a.CreationTimestamp, b.CreationTimestamp)
+	case "znnFunds":
+		cmp = a.ZnnFundsNeeded.Cmp(b.ZnnFundsNeeded)
+	case "qsrFunds":
+		cmp = a.QsrFundsNeeded.Cmp(b.QsrFundsNeeded)
+	default:
+		cmp = compareInt64(a.LastUpdateTimestamp, b.LastUpdateTimestamp)
+	}
+	if f.Ascending {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GetProjects returns the projects which match the filter, newest update first unless specified otherwise
+func (a *AcceleratorApi) GetProjects(filter *ProjectFilter, pageIndex, pageSize uint32) (*ProjectList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	if filter == nil {
+		filter = &ProjectFilter{}
+	}
+	switch filter.SortBy {
+	case "", "lastUpdate", "creation", "znnFunds", "qsrFunds":
+	default:
+		return nil, api.ErrUnknownSortField
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.AcceleratorContract)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := definition.GetProjectList(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*definition.Project, 0, len(projects))
+	for _, project := range projects {
+		if filter.matches(project) {
+			filtered = append(filtered, project)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filter.less(filtered[i], filtered[j])
+	})
+
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(filtered)))
+	result := &ProjectList{
+		Count: len(filtered),
+		List:  make([]*Project, 0, end-start),
+	}
+	for _, project := range filtered[start:end] {
+		result.List = append(result.List, a.toProject(context, project))
+	}
+
+	return result, nil
+}
 func (a *AcceleratorApi) GetProjectById(id types.Hash) (*Project, error) {
 	_, context, err := api.GetFrontierContext(a.chain, types.AcceleratorContract)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/vm/vm_context"
@@ -21,12 +22,14 @@ import (
 
 type BridgeApi struct {
 	chain chain.Chain
+	z     zenon.Zenon
 	log   log15.Logger
 }
 
 func NewBridgeApi(z zenon.Zenon) *BridgeApi {
 	return &BridgeApi{
 		chain: z.Chain(),
+		z:     z,
 		log:   common.RPCLogger.New("module", "embedded_bridge_api"),
 	}
 }
@@ -145,6 +148,98 @@ type NetworkInfoList struct {
 	List  []*definition.NetworkInfo `json:"list"`
 }
 
+// NetworkConfigChange is an administrative change of a network or of one of its token pairs. Name and
+// ContractAddress are set by setNetwork, Metadata by setNetwork and setNetworkMetadata, and TokenPair by
+// setTokenPair and removeTokenPair, the latter only with the token standard and the token address.
+type NetworkConfigChange struct {
+	Type              string                `json:"type"`
+	Hash              types.Hash            `json:"hash"`
+	Administrator     types.Address         `json:"administrator"`
+	Name              string                `json:"name"`
+	ContractAddress   string                `json:"contractAddress"`
+	Metadata          string                `json:"metadata"`
+	TokenPair         *definition.TokenPair `json:"tokenPair"`
+	MomentumHeight    uint64                `json:"momentumHeight"`
+	MomentumTimestamp int64                 `json:"momentumTimestamp"`
+}
+
+// NetworkConfigHistory is the current configuration of a network, null if it's not set, and a page of its changes.
+type NetworkConfigHistory struct {
+	Network *definition.NetworkInfo `json:"network"`
+	Count   int                     `json:"count"`
+	More    bool                    `json:"more"`
+	List    []*NetworkConfigChange  `json:"list"`
+}
+
+// GetNetworkConfigHistory returns the current configuration of the network along with the changes of the network and
+// of its token pairs made by the administrator, in confirmation order, including the ones of a removed network.
+// Requires the indexer, only the changes confirmed since the bridge contract logs them are listed.
+func (a *BridgeApi) GetNetworkConfigHistory(networkClass, chainId uint32, pageIndex, pageSize uint32) (*NetworkConfigHistory, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	ix := a.z.Indexer()
+	if ix == nil {
+		return nil, api.ErrIndexerDisabled
+	}
+	events, more, err := ix.GetBridgeConfigHistory(networkClass, chainId, pageIndex, pageSize)
+	if err != nil {
+		a.log.Error("GetNetworkConfigHistory failed", "reason", err, "method-called", "indexer.GetBridgeConfigHistory")
+		return nil, err
+	}
+	network, err := a.GetNetworkInfo(networkClass, chainId)
+	if err != nil {
+		return nil, err
+	}
+	if len(network.Name) == 0 {
+		network = nil
+	}
+
+	result := &NetworkConfigHistory{
+		Network: network,
+		Count:   len(events),
+		More:    more,
+		List:    make([]*NetworkConfigChange, len(events)),
+	}
+	for i, event := range events {
+		change := &NetworkConfigChange{
+			Type:              event.Type.String(),
+			Hash:              event.Hash,
+			Administrator:     event.Administrator,
+			MomentumHeight:    event.MomentumHeight,
+			MomentumTimestamp: int64(event.MomentumTimestamp),
+		}
+		switch event.Type {
+		case indexer.BridgeNetworkSet:
+			change.Name = event.Name
+			change.ContractAddress = event.ContractAddress
+			change.Metadata = event.Metadata
+		case indexer.BridgeNetworkMetadataSet:
+			change.Metadata = event.Metadata
+		case indexer.BridgeTokenPairSet:
+			change.TokenPair = &definition.TokenPair{
+				TokenStandard: event.TokenStandard,
+				TokenAddress:  event.TokenAddress,
+				Bridgeable:    event.Bridgeable,
+				Redeemable:    event.Redeemable,
+				Owned:         event.Owned,
+				MinAmount:     event.MinAmount,
+				FeePercentage: event.FeePercentage,
+				RedeemDelay:   event.RedeemDelay,
+				Metadata:      event.Metadata,
+			}
+		case indexer.BridgeTokenPairRemoved:
+			change.TokenPair = &definition.TokenPair{
+				TokenStandard: event.TokenStandard,
+				TokenAddress:  event.TokenAddress,
+				MinAmount:     event.MinAmount,
+			}
+		}
+		result.List[i] = change
+	}
+	return result, nil
+}
+
 func (a *BridgeApi) toRequest(context vm_context.AccountVmContext, abiRequest *definition.WrapTokenRequest) *definition.WrapTokenRequest {
 	if abiRequest == nil {
 		return nil
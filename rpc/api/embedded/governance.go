@@ -0,0 +1,185 @@
+package embedded
+
+import (
+	"sort"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	GovernanceVoteProject = "project"
+	GovernanceVotePhase   = "phase"
+)
+
+type GovernanceApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewGovernanceApi(z zenon.Zenon) *GovernanceApi {
+	return &GovernanceApi{
+		chain: z.Chain(),
+		log:   common.RPCLogger.New("module", "embedded_governance_api"),
+	}
+}
+
+// GovernanceVote is the vote of a pillar on an accelerator project or phase. Vote is one of
+// definition.VoteYes, VoteNo or VoteAbstain, null if the pillar didn't vote.
+type GovernanceVote struct {
+	Type              string     `json:"type"`
+	Id                types.Hash `json:"id"`
+	ProjectId         types.Hash `json:"projectId"`
+	Name              string     `json:"name"`
+	Status            uint8      `json:"status"`
+	CreationTimestamp int64      `json:"creationTimestamp"`
+	Vote              *uint8     `json:"vote"`
+}
+
+// GuardianVote is the administrator proposed by one of the addresses of a pillar which guards the bridge
+// or the liquidity contract, null if it didn't propose one since the last emergency.
+type GuardianVote struct {
+	Contract      types.Address  `json:"contract"`
+	Guardian      types.Address  `json:"guardian"`
+	Administrator *types.Address `json:"administrator"`
+}
+
+// GovernanceParticipation counts the votes of a pillar on all the projects and phases.
+type GovernanceParticipation struct {
+	Total    uint32 `json:"total"`
+	Yes      uint32 `json:"yes"`
+	No       uint32 `json:"no"`
+	Abstain  uint32 `json:"abstain"`
+	NotVoted uint32 `json:"notVoted"`
+}
+
+type GovernanceVoteList struct {
+	Count         int                      `json:"count"`
+	Participation *GovernanceParticipation `json:"participation"`
+	List          []*GovernanceVote        `json:"list"`
+	GuardianVotes []*GuardianVote          `json:"guardianVotes"`
+}
+
+// GetVotesByPillar returns the votes of the pillar on every accelerator project and phase, newest first,
+// including the ones it abstained from or didn't vote on, along with the administrators proposed by
+// its addresses if they are guardians. Revoked pillars keep their votes.
+func (a *GovernanceApi) GetVotesByPillar(name string, pageIndex, pageSize uint32) (*GovernanceVoteList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	_, pillarContext, err := api.GetFrontierContext(a.chain, types.PillarContract)
+	if err != nil {
+		return nil, err
+	}
+	pillar, err := definition.GetPillarInfo(pillarContext.Storage(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.AcceleratorContract)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := definition.GetProjectList(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+	votes := make([]*GovernanceVote, 0, len(projects))
+	for _, project := range projects {
+		votes = append(votes, &GovernanceVote{
+			Type:              GovernanceVoteProject,
+			Id:                project.Id,
+			ProjectId:         project.Id,
+			Name:              project.Name,
+			Status:            project.Status,
+			CreationTimestamp: project.CreationTimestamp,
+		})
+		for _, id := range project.PhaseIds {
+			phase, err := definition.GetPhaseEntry(context.Storage(), id)
+			if err != nil {
+				continue
+			}
+			votes = append(votes, &GovernanceVote{
+				Type:              GovernanceVotePhase,
+				Id:                phase.Id,
+				ProjectId:         project.Id,
+				Name:              phase.Name,
+				Status:            phase.Status,
+				CreationTimestamp: phase.CreationTimestamp,
+			})
+		}
+	}
+	sort.SliceStable(votes, func(i, j int) bool {
+		return votes[i].CreationTimestamp > votes[j].CreationTimestamp
+	})
+
+	participation := &GovernanceParticipation{Total: uint32(len(votes))}
+	for _, vote := range votes {
+		pillarVote, err := definition.GetPillarVote(context.Storage(), vote.Id, pillar.Name)
+		if err == constants.ErrDataNonExistent {
+			participation.NotVoted += 1
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		vote.Vote = &pillarVote.Vote
+		switch pillarVote.Vote {
+		case definition.VoteYes:
+			participation.Yes += 1
+		case definition.VoteNo:
+			participation.No += 1
+		case definition.VoteAbstain:
+			participation.Abstain += 1
+		}
+	}
+
+	guardianVotes, err := a.getGuardianVotes(pillar)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(votes)))
+	return &GovernanceVoteList{
+		Count:         len(votes),
+		Participation: participation,
+		List:          votes[start:end],
+		GuardianVotes: guardianVotes,
+	}, nil
+}
+
+func (a *GovernanceApi) getGuardianVotes(pillar *definition.PillarInfo) ([]*GuardianVote, error) {
+	addresses := []types.Address{pillar.StakeAddress, pillar.BlockProducingAddress, pillar.RewardWithdrawAddress}
+	votes := make([]*GuardianVote, 0)
+	for _, contract := range []types.Address{types.BridgeContract, types.LiquidityContract} {
+		_, context, err := api.GetFrontierContext(a.chain, contract)
+		if err != nil {
+			return nil, err
+		}
+		security, err := definition.GetSecurityInfoVariable(context.Storage())
+		if err != nil {
+			return nil, err
+		}
+		for index, guardian := range security.Guardians {
+			for _, address := range addresses {
+				if guardian != address {
+					continue
+				}
+				vote := &GuardianVote{Contract: contract, Guardian: guardian}
+				if index < len(security.GuardiansVotes) && !security.GuardiansVotes[index].IsZero() {
+					administrator := security.GuardiansVotes[index]
+					vote.Administrator = &administrator
+				}
+				votes = append(votes, vote)
+				break
+			}
+		}
+	}
+	return votes, nil
+}
@@ -6,8 +6,11 @@ import (
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
 	"github.com/zenon-network/go-zenon/zenon"
 	"math/big"
 	"sort"
@@ -15,12 +18,14 @@ import (
 
 type LiquidityApi struct {
 	chain chain.Chain
+	cs    consensus.Consensus
 	log   log15.Logger
 }
 
 func NewLiquidityApi(z zenon.Zenon) *LiquidityApi {
 	return &LiquidityApi{
 		chain: z.Chain(),
+		cs:    z.Consensus(),
 		log:   common.RPCLogger.New("module", "embedded_liquidity_api"),
 	}
 }
@@ -160,3 +165,183 @@ func (a *LiquidityApi) GetTimeChallengesInfo() (*TimeChallengesList, error) {
 		List:  ans,
 	}, nil
 }
+
+// LiquidityExpectedReward is the projected reward of a liquidity stake entry.
+type LiquidityExpectedReward struct {
+	Id            types.Hash               `json:"id"`
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Znn           *big.Int                 `json:"znnAmount"`
+	Qsr           *big.Int                 `json:"qsrAmount"`
+}
+
+type LiquidityExpectedRewardMarshal struct {
+	Id            types.Hash               `json:"id"`
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Znn           string                   `json:"znnAmount"`
+	Qsr           string                   `json:"qsrAmount"`
+}
+
+func (r *LiquidityExpectedReward) ToLiquidityExpectedRewardMarshal() *LiquidityExpectedRewardMarshal {
+	return &LiquidityExpectedRewardMarshal{
+		Id:            r.Id,
+		TokenStandard: r.TokenStandard,
+		Znn:           r.Znn.String(),
+		Qsr:           r.Qsr.String(),
+	}
+}
+
+func (r *LiquidityExpectedReward) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToLiquidityExpectedRewardMarshal())
+}
+
+func (r *LiquidityExpectedReward) UnmarshalJSON(data []byte) error {
+	aux := new(LiquidityExpectedRewardMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	r.Id = aux.Id
+	r.TokenStandard = aux.TokenStandard
+	r.Znn = common.StringToBigInt(aux.Znn)
+	r.Qsr = common.StringToBigInt(aux.Qsr)
+	return nil
+}
+
+// LiquidityExpectedRewards is the reward projected for the liquidity stake entries of an address in the epochs
+// [FromEpoch, ToEpoch], which weren't rewarded yet. The current epoch is projected as if the entries and the
+// parameters of the program didn't change until its end.
+type LiquidityExpectedRewards struct {
+	FromEpoch uint64                     `json:"fromEpoch"`
+	ToEpoch   uint64                     `json:"toEpoch"`
+	Znn       *big.Int                   `json:"znnAmount"`
+	Qsr       *big.Int                   `json:"qsrAmount"`
+	Entries   []*LiquidityExpectedReward `json:"list"`
+}
+
+type LiquidityExpectedRewardsMarshal struct {
+	FromEpoch uint64                     `json:"fromEpoch"`
+	ToEpoch   uint64                     `json:"toEpoch"`
+	Znn       string                     `json:"znnAmount"`
+	Qsr       string                     `json:"qsrAmount"`
+	Entries   []*LiquidityExpectedReward `json:"list"`
+}
+
+func (r *LiquidityExpectedRewards) ToLiquidityExpectedRewardsMarshal() *LiquidityExpectedRewardsMarshal {
+	return &LiquidityExpectedRewardsMarshal{
+		FromEpoch: r.FromEpoch,
+		ToEpoch:   r.ToEpoch,
+		Znn:       r.Znn.String(),
+		Qsr:       r.Qsr.String(),
+		Entries:   r.Entries,
+	}
+}
+
+func (r *LiquidityExpectedRewards) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToLiquidityExpectedRewardsMarshal())
+}
+
+func (r *LiquidityExpectedRewards) UnmarshalJSON(data []byte) error {
+	aux := new(LiquidityExpectedRewardsMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	r.FromEpoch = aux.FromEpoch
+	r.ToEpoch = aux.ToEpoch
+	r.Znn = common.StringToBigInt(aux.Znn)
+	r.Qsr = common.StringToBigInt(aux.Qsr)
+	r.Entries = aux.Entries
+	return nil
+}
+
+// GetExpectedRewards projects the rewards of the liquidity stake entries of the address for the epochs which weren't
+// rewarded yet, up to the current one, with the same split the contract uses when it updates the rewards.
+func (a *LiquidityApi) GetExpectedRewards(address types.Address) (*LiquidityExpectedRewards, error) {
+	frontier, context, err := api.GetFrontierContext(a.chain, types.LiquidityContract)
+	if err != nil {
+		return nil, err
+	}
+	liquidityInfo, err := definition.GetLiquidityInfo(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+	lastEpoch, err := definition.GetLastEpochUpdate(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+	znnBalance, err := context.GetBalance(types.ZnnTokenStandard)
+	if err != nil {
+		return nil, err
+	}
+	qsrBalance, err := context.GetBalance(types.QsrTokenStandard)
+	if err != nil {
+		return nil, err
+	}
+	entries := definition.GetAllLiquidityStakeEntries(context.Storage())
+
+	ticker := a.cs.FixedPillarReader(frontier.Identifier()).EpochTicker()
+	currentEpoch := int64(ticker.ToTick(*frontier.Timestamp))
+	expected := &LiquidityExpectedRewards{
+		FromEpoch: uint64(lastEpoch.LastEpoch + 1),
+		ToEpoch:   uint64(currentEpoch),
+		Znn:       big.NewInt(0),
+		Qsr:       big.NewInt(0),
+		Entries:   make([]*LiquidityExpectedReward, 0),
+	}
+	byId := make(map[types.Hash]*LiquidityExpectedReward)
+	for _, entry := range entries {
+		if entry.StakeAddress == address {
+			reward := &LiquidityExpectedReward{
+				Id:            entry.Id,
+				TokenStandard: entry.TokenStandard,
+				Znn:           big.NewInt(0),
+				Qsr:           big.NewInt(0),
+			}
+			byId[entry.Id] = reward
+			expected.Entries = append(expected.Entries, reward)
+		}
+	}
+
+	for epoch := lastEpoch.LastEpoch + 1; epoch <= currentEpoch; epoch += 1 {
+		totalZnnAmount, totalQsrAmount := constants.LiquidityRewardForEpoch(uint64(epoch))
+		// the rewards of a halted epoch are minted to the contract
+		if liquidityInfo.IsHalted {
+			znnBalance.Add(znnBalance, totalZnnAmount)
+			qsrBalance.Add(qsrBalance, totalQsrAmount)
+			continue
+		}
+		if implementation.LiquidityAdditionalRewardAvailable(liquidityInfo, znnBalance, qsrBalance) {
+			totalZnnAmount.Add(totalZnnAmount, liquidityInfo.ZnnReward)
+			totalQsrAmount.Add(totalQsrAmount, liquidityInfo.QsrReward)
+			znnBalance.Sub(znnBalance, liquidityInfo.ZnnReward)
+			qsrBalance.Sub(qsrBalance, liquidityInfo.QsrReward)
+		}
+
+		startTime, endTime := ticker.ToTime(uint64(epoch))
+		rewards := implementation.GetLiquidityStakeRewards(liquidityInfo.TokenTuples, entries, totalZnnAmount, totalQsrAmount, startTime.Unix(), endTime.Unix())
+		remaining := make([]*definition.LiquidityStakeEntry, 0, len(entries))
+		rewarded := make(map[types.Hash]bool)
+		for _, reward := range rewards {
+			rewarded[reward.Entry.Id] = true
+			// the contract mints what isn't distributed back to itself
+			totalZnnAmount.Sub(totalZnnAmount, reward.Znn)
+			totalQsrAmount.Sub(totalQsrAmount, reward.Qsr)
+			if expectedReward, ok := byId[reward.Entry.Id]; ok {
+				expectedReward.Znn.Add(expectedReward.Znn, reward.Znn)
+				expectedReward.Qsr.Add(expectedReward.Qsr, reward.Qsr)
+				expected.Znn.Add(expected.Znn, reward.Znn)
+				expected.Qsr.Add(expected.Qsr, reward.Qsr)
+			}
+		}
+		znnBalance.Add(znnBalance, totalZnnAmount)
+		qsrBalance.Add(qsrBalance, totalQsrAmount)
+
+		// revoked entries are deleted once rewarded for the last time
+		for _, entry := range entries {
+			if rewarded[entry.Id] && entry.RevokeTime != 0 && entry.RevokeTime < endTime.Unix() {
+				continue
+			}
+			remaining = append(remaining, entry)
+		}
+		entries = remaining
+	}
+	return expected, nil
+}
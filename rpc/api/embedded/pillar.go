@@ -8,6 +8,7 @@ import (
 	"github.com/inconshreveable/log15"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api"
@@ -20,6 +21,7 @@ import (
 type PillarApi struct {
 	log            log15.Logger
 	chain          chain.Chain
+	z              zenon.Zenon
 	consensusCache ConsensusCache
 }
 
@@ -27,6 +29,7 @@ func NewPillarApi(z zenon.Zenon, testing bool) *PillarApi {
 	return &PillarApi{
 		log:            common.RPCLogger.New("module", "embedded_pillar_api"),
 		chain:          z.Chain(),
+		z:              z,
 		consensusCache: NewConsensusCache(z, testing),
 	}
 }
@@ -371,6 +374,157 @@ func (a *PillarApi) GetDelegatedPillar(addr types.Address) (*GetDelegatedPillarR
 	return nil, nil
 }
 
+// Delegator is an address delegating to a pillar, the weight is its ZNN balance at the frontier momentum.
+type Delegator struct {
+	Address types.Address
+	Weight  *big.Int
+	// DelegationHeight and DelegationTimestamp belong to the momentum which confirmed the delegation.
+	// Both are 0 if the indexer is disabled or the delegation is part of the genesis.
+	DelegationHeight    uint64
+	DelegationTimestamp int64
+}
+
+type DelegatorMarshal struct {
+	Address             types.Address `json:"address"`
+	Weight              string        `json:"weight"`
+	DelegationHeight    uint64        `json:"delegationHeight"`
+	DelegationTimestamp int64         `json:"delegationTimestamp"`
+}
+
+func (d *Delegator) ToDelegatorMarshal() *DelegatorMarshal {
+	aux := &DelegatorMarshal{
+		Address:             d.Address,
+		Weight:              d.Weight.String(),
+		DelegationHeight:    d.DelegationHeight,
+		DelegationTimestamp: d.DelegationTimestamp,
+	}
+	return aux
+}
+
+func (d *Delegator) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.ToDelegatorMarshal())
+}
+
+func (d *Delegator) UnmarshalJSON(data []byte) error {
+	aux := new(DelegatorMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	d.Address = aux.Address
+	d.Weight = common.StringToBigInt(aux.Weight)
+	d.DelegationHeight = aux.DelegationHeight
+	d.DelegationTimestamp = aux.DelegationTimestamp
+	return nil
+}
+
+type DelegatorList struct {
+	Count int          `json:"count"`
+	List  []*Delegator `json:"list"`
+}
+
+// GetDelegators returns the addresses delegating to the pillar, ordered by weight, heaviest first.
+func (a *PillarApi) GetDelegators(pillarName string, pageIndex, pageSize uint32) (*DelegatorList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.PillarContract)
+	if err != nil {
+		return nil, err
+	}
+	delegations, err := definition.GetDelegationsList(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Delegator, 0)
+	for _, delegation := range delegations {
+		if delegation.Name != pillarName {
+			continue
+		}
+		balance, err := context.MomentumStore().GetAccountStore(delegation.Backer).GetBalance(types.ZnnTokenStandard)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &Delegator{
+			Address: delegation.Backer,
+			Weight:  balance,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if cmp := list[i].Weight.Cmp(list[j].Weight); cmp != 0 {
+			return cmp > 0
+		}
+		return list[i].Address.String() < list[j].Address.String()
+	})
+
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(list)))
+	for _, delegator := range list[start:end] {
+		if err := a.setDelegationMomentum(context.MomentumStore(), delegator, pillarName); err != nil {
+			a.log.Error("GetDelegators failed", "reason", err, "method-called", "setDelegationMomentum")
+			return nil, err
+		}
+	}
+	return &DelegatorList{
+		Count: len(list),
+		List:  list[start:end],
+	}, nil
+}
+
+// setDelegationMomentum looks up the last Delegate call of the delegator to pillarName in the indexer.
+func (a *PillarApi) setDelegationMomentum(momentumStore store.Momentum, delegator *Delegator, pillarName string) error {
+	indexer := a.z.Indexer()
+	if indexer == nil {
+		return nil
+	}
+
+	hashes := make([]types.Hash, 0)
+	for pageIndex := uint32(0); ; pageIndex += 1 {
+		page, more, err := indexer.GetBlocksByAddressPair(delegator.Address, types.PillarContract, pageIndex, api.RpcMaxPageSize)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, page...)
+		if !more {
+			break
+		}
+	}
+
+	// blocks are ordered by momentum height, the last matching delegation is the one in effect
+	for i := len(hashes) - 1; i >= 0; i -= 1 {
+		block, err := momentumStore.GetAccountBlockByHash(hashes[i])
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			continue
+		}
+		name := new(string)
+		if err := definition.ABIPillars.UnpackMethod(name, definition.DelegateMethodName, block.Data); err != nil || *name != pillarName {
+			continue
+		}
+		receiveBlock, err := momentumStore.GetBlockWhichReceives(block.Hash)
+		if err != nil {
+			return err
+		}
+		if receiveBlock == nil {
+			continue
+		}
+		height, err := momentumStore.GetBlockConfirmationHeight(receiveBlock.Hash)
+		if err != nil {
+			return err
+		}
+		momentum, err := momentumStore.GetMomentumByHeight(height)
+		if err != nil {
+			return err
+		}
+		delegator.DelegationHeight = momentum.Height
+		delegator.DelegationTimestamp = int64(momentum.TimestampUnix)
+		return nil
+	}
+	return nil
+}
+
 type PillarEpochHistoryList struct {
 	Count int64                            `json:"count"`
 	List  []*definition.PillarEpochHistory `json:"list"`
@@ -431,6 +585,111 @@ func (a *PillarApi) GetPillarEpochHistory(pillarName string, pageIndex, pageSize
 	return result, err
 }
 
+type PillarWeightEntry struct {
+	Epoch uint64
+	// Weight is 0 in the epochs without history, when the pillar didn't exist or was revoked
+	Weight *big.Int
+	// WeightChange is the difference to the weight of the previous epoch
+	WeightChange *big.Int
+}
+
+type PillarWeightEntryMarshal struct {
+	Epoch        uint64 `json:"epoch"`
+	Weight       string `json:"weight"`
+	WeightChange string `json:"weightChange"`
+}
+
+func (p *PillarWeightEntry) ToPillarWeightEntryMarshal() *PillarWeightEntryMarshal {
+	aux := &PillarWeightEntryMarshal{
+		Epoch:        p.Epoch,
+		Weight:       p.Weight.String(),
+		WeightChange: p.WeightChange.String(),
+	}
+	return aux
+}
+
+func (p *PillarWeightEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.ToPillarWeightEntryMarshal())
+}
+
+func (p *PillarWeightEntry) UnmarshalJSON(data []byte) error {
+	aux := new(PillarWeightEntryMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	p.Epoch = aux.Epoch
+	p.Weight = common.StringToBigInt(aux.Weight)
+	p.WeightChange = common.StringToBigInt(aux.WeightChange)
+	return nil
+}
+
+type PillarWeightHistoryList struct {
+	Count int64                `json:"count"`
+	List  []*PillarWeightEntry `json:"list"`
+}
+
+// GetWeightHistory returns the weight of the pillar in each epoch and the change to the previous epoch,
+// newest epoch first.
+func (a *PillarApi) GetWeightHistory(pillarName string, pageIndex, pageSize uint32) (*PillarWeightHistoryList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.PillarContract)
+	if err != nil {
+		return nil, err
+	}
+
+	// get latest epoch
+	lastEpoch, err := definition.GetLastEpochUpdate(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	weightAt := func(epoch int64) (*big.Int, error) {
+		if epoch < 0 {
+			return big.NewInt(0), nil
+		}
+		pillars, err := definition.GetPillarEpochHistoryList(context.Storage(), uint64(epoch))
+		if err != nil {
+			return nil, err
+		}
+		for _, pillar := range pillars {
+			if pillar.Name == pillarName {
+				return pillar.Weight, nil
+			}
+		}
+		return big.NewInt(0), nil
+	}
+
+	epoch := lastEpoch.LastEpoch - int64(pageIndex*pageSize)
+	result := &PillarWeightHistoryList{
+		Count: lastEpoch.LastEpoch + 1,
+		List:  make([]*PillarWeightEntry, 0, pageSize),
+	}
+	if epoch < 0 {
+		return result, nil
+	}
+	weight, err := weightAt(epoch)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < int(pageSize) && epoch >= 0; i += 1 {
+		previous, err := weightAt(epoch - 1)
+		if err != nil {
+			return nil, err
+		}
+		result.List = append(result.List, &PillarWeightEntry{
+			Epoch:        uint64(epoch),
+			Weight:       weight,
+			WeightChange: new(big.Int).Sub(weight, previous),
+		})
+		weight = previous
+		epoch -= 1
+	}
+	return result, nil
+}
+
 func (a *PillarApi) GetPillarsHistoryByEpoch(epoch uint64, pageIndex, pageSize uint32) (*PillarEpochHistoryList, error) {
 	if pageSize > api.RpcMaxPageSize {
 		return nil, api.ErrPageSizeParamTooBig
@@ -453,3 +712,80 @@ func (a *PillarApi) GetPillarsHistoryByEpoch(epoch uint64, pageIndex, pageSize u
 		List:  pillars[start:end],
 	}, nil
 }
+
+type PillarEpochPerformance struct {
+	Epoch uint64 `json:"epoch"`
+	PillarStats
+}
+type PillarPerformance struct {
+	Name string `json:"name"`
+	PillarStats
+	Epochs []*PillarEpochPerformance `json:"epochs"`
+}
+type PillarPerformanceList struct {
+	Count int                  `json:"count"`
+	List  []*PillarPerformance `json:"list"`
+}
+
+// GetPerformanceHistory returns the produced and expected momentums of all pillars over the last epochs,
+// both in total and per epoch, newest epoch first. Epochs in which a pillar had no history are skipped.
+func (a *PillarApi) GetPerformanceHistory(epochs uint64, pageIndex, pageSize uint32) (*PillarPerformanceList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	if epochs > api.RpcMaxCountSize {
+		return nil, api.ErrCountParamTooBig
+	}
+
+	_, context, err := api.GetFrontierContext(a.chain, types.PillarContract)
+	if err != nil {
+		return nil, err
+	}
+
+	// get latest epoch
+	lastEpoch, err := definition.GetLastEpochUpdate(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*PillarPerformance)
+	for epoch := lastEpoch.LastEpoch; epoch >= 0 && lastEpoch.LastEpoch-epoch < int64(epochs); epoch -= 1 {
+		pillars, err := definition.GetPillarEpochHistoryList(context.Storage(), uint64(epoch))
+		if err != nil {
+			return nil, err
+		}
+		for _, pillar := range pillars {
+			performance, ok := byName[pillar.Name]
+			if !ok {
+				performance = &PillarPerformance{
+					Name:   pillar.Name,
+					Epochs: make([]*PillarEpochPerformance, 0),
+				}
+				byName[pillar.Name] = performance
+			}
+			performance.ProducedMomentums += uint64(pillar.ProducedBlockNum)
+			performance.ExpectedMomentums += uint64(pillar.ExpectedBlockNum)
+			performance.Epochs = append(performance.Epochs, &PillarEpochPerformance{
+				Epoch: pillar.Epoch,
+				PillarStats: PillarStats{
+					ProducedMomentums: uint64(pillar.ProducedBlockNum),
+					ExpectedMomentums: uint64(pillar.ExpectedBlockNum),
+				},
+			})
+		}
+	}
+
+	list := make([]*PillarPerformance, 0, len(byName))
+	for _, performance := range byName {
+		list = append(list, performance)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(list)))
+	return &PillarPerformanceList{
+		Count: len(list),
+		List:  list[start:end],
+	}, nil
+}
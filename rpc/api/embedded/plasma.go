@@ -15,6 +15,8 @@ import (
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -217,6 +219,50 @@ func (a *PlasmaApi) GetEntriesByAddress(address types.Address, pageIndex, pageSi
 	return &FusionEntryList{amount, listLen, entryList}, nil
 }
 
+// GetExpiringEntriesByAddress returns the fusion entries of address which can be revoked within the next
+// withinMomentums momentums, including the ones which can already be revoked, ordered by expiration height.
+// QsrAmount is the amount of all these entries.
+func (a *PlasmaApi) GetExpiringEntriesByAddress(address types.Address, withinMomentums uint64, pageIndex, pageSize uint32) (*FusionEntryList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	frontier, context, err := api.GetFrontierContext(a.chain, types.PlasmaContract)
+	if err != nil {
+		return nil, err
+	}
+	all, _, err := definition.GetFusionInfoListByOwner(context.Storage(), address)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries can be revoked from their expiration height
+	list := make([]*definition.FusionInfo, 0)
+	amount := big.NewInt(0)
+	for _, info := range all {
+		if info.ExpirationHeight <= frontier.Height || info.ExpirationHeight-frontier.Height <= withinMomentums {
+			list = append(list, info)
+			amount.Add(amount, info.Amount)
+		}
+	}
+	sort.Sort(SortFusionEntryByHeight(list))
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(list)))
+	entryList := make([]*FusionEntry, 0, end-start)
+	for _, info := range list[start:end] {
+		entryList = append(entryList, &FusionEntry{
+			QsrAmount:        info.Amount,
+			Beneficiary:      info.Beneficiary,
+			ExpirationHeight: info.ExpirationHeight,
+			Id:               info.Id,
+		})
+	}
+	return &FusionEntryList{
+		QsrAmount: amount,
+		Count:     len(list),
+		Fusions:   entryList,
+	}, nil
+}
+
 type GetRequiredParam struct {
 	SelfAddr  types.Address  `json:"address"`
 	BlockType uint64         `json:"blockType"`
@@ -278,3 +324,80 @@ func (a *PlasmaApi) GetRequiredPoWForAccountBlock(param GetRequiredParam) (*GetR
 		}, nil
 	}
 }
+
+type DraftBlockPlasma struct {
+	RequiredPlasma     uint64 `json:"requiredPlasma"`
+	AvailablePlasma    uint64 `json:"availablePlasma"`
+	RequiredDifficulty uint64 `json:"requiredDifficulty"`
+	// EmbeddedMethod is the embedded contract method called by the block, empty for other blocks
+	EmbeddedMethod string `json:"embeddedMethod"`
+}
+
+// GetRequiredPoWForDraftBlock estimates the plasma of a fully-populated unsigned user block, as computed by the
+// node when the block is published. The data of calls to embedded contracts is validated as well, so malformed
+// calls are reported before any PoW is computed. Only the blockType, address, toAddress, amount, tokenStandard,
+// fromBlockHash and data fields are used.
+func (a *PlasmaApi) GetRequiredPoWForDraftBlock(draft *nom.AccountBlock) (*DraftBlockPlasma, error) {
+	if draft == nil {
+		return nil, api.ErrParamIsNull
+	}
+	if draft.BlockType != nom.BlockTypeUserSend && draft.BlockType != nom.BlockTypeUserReceive {
+		return nil, api.ErrTemplateBlockType
+	}
+	if types.IsEmbeddedAddress(draft.Address) {
+		return nil, api.ErrTemplateFromEmbedded
+	}
+	_, context, err := api.GetFrontierContext(a.chain, draft.Address)
+	if err != nil {
+		return nil, err
+	}
+	frontierMomentum, err := context.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	block := &nom.AccountBlock{
+		BlockType:            draft.BlockType,
+		Address:              draft.Address,
+		MomentumAcknowledged: frontierMomentum.Identifier(),
+	}
+	result := new(DraftBlockPlasma)
+	if block.BlockType == nom.BlockTypeUserSend {
+		block.ToAddress = draft.ToAddress
+		block.TokenStandard = draft.TokenStandard
+		block.Amount = draft.Amount
+		block.Data = draft.Data
+		if block.Amount == nil {
+			block.Amount = big.NewInt(0)
+		}
+
+		if method, err := embedded.GetEmbeddedMethod(context, block.ToAddress, block.Data); err == nil {
+			if err := method.ValidateSendBlock(block); err != nil {
+				return nil, err
+			}
+			if result.EmbeddedMethod, err = embedded.GetEmbeddedMethodName(context, block.ToAddress, block.Data); err != nil {
+				return nil, err
+			}
+		} else if err != constants.ErrNotContractAddress {
+			return nil, err
+		}
+	} else {
+		if draft.FromBlockHash.IsZero() {
+			return nil, errors.New("fromBlockHash is zero")
+		}
+		block.FromBlockHash = draft.FromBlockHash
+	}
+
+	if result.RequiredPlasma, err = vm.GetBasePlasmaForAccountBlock(context, block); err != nil {
+		return nil, err
+	}
+	if result.AvailablePlasma, err = vm.AvailablePlasma(context.MomentumStore(), context); err != nil {
+		return nil, err
+	}
+	if result.AvailablePlasma < result.RequiredPlasma {
+		if result.RequiredDifficulty, err = vm.GetDifficultyForPlasma(result.RequiredPlasma - result.AvailablePlasma); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
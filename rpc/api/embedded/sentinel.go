@@ -1,6 +1,8 @@
 package embedded
 
 import (
+	"sort"
+
 	"github.com/inconshreveable/log15"
 
 	"github.com/zenon-network/go-zenon/chain"
@@ -29,6 +31,16 @@ type SentinelInfoList struct {
 	List  []*SentinelInfo `json:"list"`
 }
 
+// SentinelRegistration is a sentinel, active or revoked, along with the time it was revoked at.
+type SentinelRegistration struct {
+	*SentinelInfo
+	RevokeTimestamp int64 `json:"revokeTimestamp"`
+}
+type SentinelRegistrationList struct {
+	Count int                     `json:"count"`
+	List  []*SentinelRegistration `json:"list"`
+}
+
 func NewSentinelApi(z zenon.Zenon) *SentinelApi {
 	return &SentinelApi{
 		chain: z.Chain(),
@@ -89,6 +101,40 @@ func (api *SentinelApi) GetAllActive(pageIndex, pageSize uint32) (*SentinelInfoL
 	}, nil
 }
 
+// GetAll returns the sentinels ever registered, including the revoked ones, sorted by registration time.
+func (api *SentinelApi) GetAll(pageIndex, pageSize uint32) (*SentinelRegistrationList, error) {
+	if pageSize > rpcapi.RpcMaxPageSize {
+		return nil, rpcapi.ErrPageSizeParamTooBig
+	}
+	_, context, err := rpcapi.GetFrontierContext(api.chain, types.SentinelContract)
+	if err != nil {
+		return nil, err
+	}
+
+	rawList := definition.GetAllSentinelInfo(context.Storage())
+	sort.SliceStable(rawList, func(i, j int) bool {
+		return rawList[i].RegistrationTimestamp < rawList[j].RegistrationTimestamp
+	})
+	start, end := rpcapi.GetRange(pageIndex, pageSize, uint32(len(rawList)))
+
+	list := make([]*SentinelRegistration, 0, end-start)
+	for _, raw := range rawList[start:end] {
+		list = append(list, &SentinelRegistration{
+			SentinelInfo:    api.toSentinelInfo(raw),
+			RevokeTimestamp: raw.RevokeTimestamp,
+		})
+	}
+	return &SentinelRegistrationList{
+		Count: len(rawList),
+		List:  list,
+	}, nil
+}
+
+// GetRewardHistory returns the rewards of the address between the epochs, both inclusive, along with their totals.
+func (api *SentinelApi) GetRewardHistory(address types.Address, startEpoch, endEpoch uint64, pageIndex, pageSize uint32) (*RewardHistoryRange, error) {
+	return getRewardHistoryRange(api.chain, types.SentinelContract, address, startEpoch, endEpoch, pageIndex, pageSize)
+}
+
 // === Shared RPCs ===
 
 func (api *SentinelApi) GetDepositedQsr(address types.Address) (string, error) {
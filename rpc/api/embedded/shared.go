@@ -113,3 +113,95 @@ func getFrontierRewardByPage(chain chain.Chain, contract types.Address, address
 
 	return result, err
 }
+
+// RewardHistoryRange is a page of the reward history between two epochs along with the totals of the whole range.
+type RewardHistoryRange struct {
+	Count    int64                 `json:"count"`
+	TotalZnn *big.Int              `json:"totalZnnAmount"`
+	TotalQsr *big.Int              `json:"totalQsrAmount"`
+	List     []*RewardHistoryEntry `json:"list"`
+}
+
+type RewardHistoryRangeMarshal struct {
+	Count    int64                 `json:"count"`
+	TotalZnn string                `json:"totalZnnAmount"`
+	TotalQsr string                `json:"totalQsrAmount"`
+	List     []*RewardHistoryEntry `json:"list"`
+}
+
+func (r *RewardHistoryRange) ToRewardHistoryRangeMarshal() *RewardHistoryRangeMarshal {
+	return &RewardHistoryRangeMarshal{
+		Count:    r.Count,
+		TotalZnn: r.TotalZnn.String(),
+		TotalQsr: r.TotalQsr.String(),
+		List:     r.List,
+	}
+}
+
+func (r *RewardHistoryRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ToRewardHistoryRangeMarshal())
+}
+
+func (r *RewardHistoryRange) UnmarshalJSON(data []byte) error {
+	aux := new(RewardHistoryRangeMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	r.Count = aux.Count
+	r.TotalZnn = common.StringToBigInt(aux.TotalZnn)
+	r.TotalQsr = common.StringToBigInt(aux.TotalQsr)
+	r.List = aux.List
+	return nil
+}
+
+// getRewardHistoryRange returns the rewards of the address between the epochs, both inclusive and capped
+// to the last updated epoch. Entries are sorted descending by epoch, like getFrontierRewardByPage.
+func getRewardHistoryRange(chain chain.Chain, contract types.Address, address types.Address, startEpoch, endEpoch uint64, pageIndex, pageSize uint32) (*RewardHistoryRange, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	if endEpoch < startEpoch {
+		return nil, api.ErrInvalidEpochRange
+	}
+
+	_, context, err := api.GetFrontierContext(chain, contract)
+	if err != nil {
+		return nil, err
+	}
+	lastEpoch, err := definition.GetLastEpochUpdate(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RewardHistoryRange{
+		TotalZnn: big.NewInt(0),
+		TotalQsr: big.NewInt(0),
+		List:     make([]*RewardHistoryEntry, 0),
+	}
+	if lastEpoch.LastEpoch < 0 || startEpoch > uint64(lastEpoch.LastEpoch) {
+		return result, nil
+	}
+	if endEpoch > uint64(lastEpoch.LastEpoch) {
+		endEpoch = uint64(lastEpoch.LastEpoch)
+	}
+	result.Count = int64(endEpoch - startEpoch + 1)
+	start, end := api.GetRange(pageIndex, pageSize, uint32(result.Count))
+
+	for i := uint32(0); i < uint32(result.Count); i += 1 {
+		epoch := endEpoch - uint64(i)
+		d, err := definition.GetRewardDepositHistory(context.Storage(), epoch, &address)
+		if err != nil {
+			return nil, err
+		}
+		result.TotalZnn.Add(result.TotalZnn, d.Znn)
+		result.TotalQsr.Add(result.TotalQsr, d.Qsr)
+		if i >= start && i < end {
+			result.List = append(result.List, &RewardHistoryEntry{
+				Epoch: int64(epoch),
+				Znn:   new(big.Int).Set(d.Znn),
+				Qsr:   new(big.Int).Set(d.Qsr),
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package embedded
+
+import (
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+type SimulateApi struct {
+	chain chain.Chain
+	z     zenon.Zenon
+	log   log15.Logger
+}
+
+func NewSimulateApi(z zenon.Zenon) *SimulateApi {
+	return &SimulateApi{
+		chain: z.Chain(),
+		z:     z,
+		log:   common.RPCLogger.New("module", "embedded_simulate_api"),
+	}
+}
+
+// StateChange is a key of the embedded contract storage changed by the simulated call.
+// Value is empty if the key is deleted.
+type StateChange struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type stateChanges []*StateChange
+
+func (c *stateChanges) Put(key []byte, value []byte) {
+	*c = append(*c, &StateChange{Key: common.JoinBytes(key), Value: common.JoinBytes(value)})
+}
+func (c *stateChanges) Delete(key []byte) {
+	*c = append(*c, &StateChange{Key: common.JoinBytes(key), Value: []byte{}})
+}
+
+type SimulationResult struct {
+	SendBlock    *nom.AccountBlock `json:"sendBlock"`
+	ReceiveBlock *nom.AccountBlock `json:"receiveBlock"`
+	Error        *string           `json:"error"`
+	Changes      []*StateChange    `json:"changes"`
+}
+
+// Simulate executes a send-block to an embedded contract against the frontier state and returns the receive-block
+// the contract would generate, along with its state changes and the error returned by the contract, if any.
+// If the send-block itself is invalid, receiveBlock is null and error holds the reason.
+// The block is not published and doesn't need to be signed or to have plasma.
+func (a *SimulateApi) Simulate(template *nom.AccountBlock) (*SimulationResult, error) {
+	if template == nil {
+		return nil, api.ErrParamIsNull
+	}
+	if template.BlockType == 0 {
+		template.BlockType = nom.BlockTypeUserSend
+	}
+
+	supervisor := vm.NewSupervisor(a.chain, a.z.Consensus())
+	simulation, err := supervisor.Simulate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SimulationResult{
+		SendBlock:    simulation.SendBlock,
+		ReceiveBlock: simulation.ReceiveBlock,
+		Changes:      make([]*StateChange, 0),
+	}
+	if simulation.SendError != nil {
+		reason := simulation.SendError.Error()
+		result.Error = &reason
+		return result, nil
+	}
+	if simulation.ReturnedError != nil {
+		reason := simulation.ReturnedError.Error()
+		result.Error = &reason
+	}
+	if err := simulation.Changes.Replay((*stateChanges)(&result.Changes)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
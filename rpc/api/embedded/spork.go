@@ -4,10 +4,12 @@ import (
 	"github.com/inconshreveable/log15"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -28,9 +30,30 @@ func NewSporkApi(z zenon.Zenon) *SporkApi {
 	}
 }
 
+// SporkInfo extends the spork with its activation status at the frontier momentum. ActivationHeight is the
+// height of the frontier momentum at the time of the activation, the spork is enforced from EnforcementHeight on.
+type SporkInfo struct {
+	*definition.Spork
+	ActivationHeight uint64 `json:"activationHeight"`
+	Enforced         bool   `json:"enforced"`
+	Implemented      bool   `json:"implemented"`
+}
+
+func newSporkInfo(spork *definition.Spork, frontierHeight uint64) *SporkInfo {
+	info := &SporkInfo{
+		Spork:       spork,
+		Implemented: types.ImplementedSporksMap[spork.Id],
+	}
+	if spork.Activated {
+		info.ActivationHeight = spork.EnforcementHeight - constants.SporkMinHeightDelay
+		info.Enforced = spork.EnforcementHeight <= frontierHeight
+	}
+	return info
+}
+
 type SporkList struct {
-	Count uint32              `json:"count"`
-	List  []*definition.Spork `json:"list"`
+	Count uint32       `json:"count"`
+	List  []*SporkInfo `json:"list"`
 }
 
 func (a *SporkApi) GetAll(pageIndex, pageSize uint32) (*SporkList, error) {
@@ -38,7 +61,7 @@ func (a *SporkApi) GetAll(pageIndex, pageSize uint32) (*SporkList, error) {
 		return nil, api.ErrPageSizeParamTooBig
 	}
 
-	_, context, err := api.GetFrontierContext(a.chain, types.SporkContract)
+	momentum, context, err := api.GetFrontierContext(a.chain, types.SporkContract)
 	if err != nil {
 		return nil, err
 	}
@@ -47,8 +70,96 @@ func (a *SporkApi) GetAll(pageIndex, pageSize uint32) (*SporkList, error) {
 
 	listLen := uint32(len(sporks))
 	start, end := api.GetRange(pageIndex, pageSize, listLen)
-	return &SporkList{
+	result := &SporkList{
 		Count: listLen,
-		List:  sporks[start:end],
-	}, nil
+		List:  make([]*SporkInfo, 0, end-start),
+	}
+	for _, spork := range sporks[start:end] {
+		result.List = append(result.List, newSporkInfo(spork, momentum.Height))
+	}
+	return result, nil
+}
+
+func (a *SporkApi) GetById(id types.Hash) (*SporkInfo, error) {
+	momentum, context, err := api.GetFrontierContext(a.chain, types.SporkContract)
+	if err != nil {
+		return nil, err
+	}
+
+	spork := definition.GetSporkInfoById(context.Storage(), id)
+	if spork == nil {
+		return nil, nil
+	}
+	return newSporkInfo(spork, momentum.Height), nil
+}
+
+// SporkAdminApi creates and activates sporks by publishing the account-blocks of the spork address, which
+// must be derived from one of the key stores of the node wallet. It must only be served on local endpoints.
+type SporkAdminApi struct {
+	chain  chain.Chain
+	wallet *api.WalletApi
+	log    log15.Logger
+}
+
+func NewSporkAdminApi(z zenon.Zenon, wallet *api.WalletApi) *SporkAdminApi {
+	return &SporkAdminApi{
+		chain:  z.Chain(),
+		wallet: wallet,
+		log:    common.RPCLogger.New("module", "embedded_spork_admin_api"),
+	}
+}
+
+// CreateSpork publishes the creation of a spork, signed with the spork address of the key store at path.
+func (a *SporkAdminApi) CreateSpork(path, name, description string) (*nom.AccountBlock, error) {
+	if len(name) < constants.SporkNameMinLength || len(name) > constants.SporkNameMaxLength {
+		return nil, constants.ErrForbiddenParam
+	}
+	if len(description) > constants.SporkDescriptionMaxLength {
+		return nil, constants.ErrForbiddenParam
+	}
+	data, err := definition.ABISpork.PackMethod(definition.SporkCreateMethodName, name, description)
+	if err != nil {
+		return nil, err
+	}
+	return a.publish(path, data)
+}
+
+// ActivateSpork publishes the activation of the spork, signed with the spork address of the key store at path.
+// The spork is enforced constants.SporkMinHeightDelay momentums after the activation.
+func (a *SporkAdminApi) ActivateSpork(path string, id types.Hash) (*nom.AccountBlock, error) {
+	_, context, err := api.GetFrontierContext(a.chain, types.SporkContract)
+	if err != nil {
+		return nil, err
+	}
+	spork := definition.GetSporkInfoById(context.Storage(), id)
+	if spork == nil {
+		return nil, constants.ErrDataNonExistent
+	}
+	if spork.Activated {
+		return nil, constants.ErrAlreadyActivated
+	}
+	data, err := definition.ABISpork.PackMethod(definition.SporkActivateMethodName, id)
+	if err != nil {
+		return nil, err
+	}
+	return a.publish(path, data)
+}
+
+func (a *SporkAdminApi) publish(path string, data []byte) (*nom.AccountBlock, error) {
+	if types.SporkAddress == nil {
+		return nil, constants.ErrPermissionDenied
+	}
+	block, err := a.wallet.PublishAccountBlock(path, &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       *types.SporkAddress,
+		ToAddress:     types.SporkContract,
+		Amount:        common.Big0,
+		TokenStandard: types.ZnnTokenStandard,
+		Data:          data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.log.Info("published spork block", "hash", block.Hash)
+	return block, nil
 }
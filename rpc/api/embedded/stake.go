@@ -12,7 +12,9 @@ import (
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -175,3 +177,202 @@ func (a *StakeApi) GetEntriesByAddress(address types.Address, pageIndex, pageSiz
 		Entries:             entryList,
 	}, nil
 }
+
+// StakeEntryLifecycle describes the maturity of a stake entry. It's evaluated against the frontier momentum
+// instead of the local clock, since cancelStake succeeds only once the timestamp of the momentum seen by the
+// contract reaches RevocableTimestamp.
+type StakeEntryLifecycle struct {
+	Entry                 *StakeEntry
+	Duration              int64
+	RevocableTimestamp    int64
+	Revocable             bool
+	SecondsUntilRevocable int64
+	// AccruedReward estimates the QSR earned by the entry in the epochs which weren't rewarded yet. The
+	// shares of the current epoch are computed with the entries known now, they change as entries come and go.
+	AccruedReward *big.Int
+}
+
+type StakeEntryLifecycleMarshal struct {
+	Entry                 *StakeEntry `json:"entry"`
+	Duration              int64       `json:"durationInSec"`
+	RevocableTimestamp    int64       `json:"revocableTimestamp"`
+	Revocable             bool        `json:"revocable"`
+	SecondsUntilRevocable int64       `json:"secondsUntilRevocable"`
+	AccruedReward         string      `json:"accruedQsrReward"`
+}
+
+func (s *StakeEntryLifecycle) ToStakeEntryLifecycleMarshal() *StakeEntryLifecycleMarshal {
+	return &StakeEntryLifecycleMarshal{
+		Entry:                 s.Entry,
+		Duration:              s.Duration,
+		RevocableTimestamp:    s.RevocableTimestamp,
+		Revocable:             s.Revocable,
+		SecondsUntilRevocable: s.SecondsUntilRevocable,
+		AccruedReward:         s.AccruedReward.String(),
+	}
+}
+
+func (s *StakeEntryLifecycle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.ToStakeEntryLifecycleMarshal())
+}
+
+func (s *StakeEntryLifecycle) UnmarshalJSON(data []byte) error {
+	aux := new(StakeEntryLifecycleMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	s.Entry = aux.Entry
+	s.Duration = aux.Duration
+	s.RevocableTimestamp = aux.RevocableTimestamp
+	s.Revocable = aux.Revocable
+	s.SecondsUntilRevocable = aux.SecondsUntilRevocable
+	s.AccruedReward = common.StringToBigInt(aux.AccruedReward)
+	return nil
+}
+
+type StakeEntryLifecycleList struct {
+	// FrontierTimestamp is the momentum timestamp the maturity is evaluated against
+	FrontierTimestamp int64                  `json:"frontierTimestamp"`
+	Count             int                    `json:"count"`
+	Entries           []*StakeEntryLifecycle `json:"list"`
+}
+
+// stakeLifecycle evaluates the stake entries against the frontier momentum.
+type stakeLifecycle struct {
+	frontierTimestamp int64
+	entries           []*definition.StakeInfo
+	// pending are the [start, end) time ranges and rewards of the epochs which weren't rewarded yet
+	pending []pendingStakeEpoch
+}
+
+type pendingStakeEpoch struct {
+	startTime      int64
+	endTime        int64
+	reward         *big.Int
+	cumulatedStake *big.Int
+}
+
+func (a *StakeApi) getStakeLifecycle() (*stakeLifecycle, error) {
+	frontier, context, err := api.GetFrontierContext(a.chain, types.StakeContract)
+	if err != nil {
+		return nil, err
+	}
+	lastEpoch, err := definition.GetLastEpochUpdate(context.Storage())
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycle := &stakeLifecycle{
+		frontierTimestamp: frontier.Timestamp.Unix(),
+		entries:           make([]*definition.StakeInfo, 0),
+	}
+	if err := definition.IterateStakeEntries(context.Storage(), func(stakeInfo *definition.StakeInfo) error {
+		lifecycle.entries = append(lifecycle.entries, stakeInfo)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	ticker := a.cs.FixedPillarReader(frontier.Identifier()).EpochTicker()
+	currentEpoch := int64(ticker.ToTick(*frontier.Timestamp))
+	for epoch := lastEpoch.LastEpoch + 1; epoch <= currentEpoch; epoch += 1 {
+		startTime, endTime := ticker.ToTime(uint64(epoch))
+		pending := pendingStakeEpoch{
+			startTime:      startTime.Unix(),
+			endTime:        endTime.Unix(),
+			reward:         constants.StakeQsrRewardPerEpoch(uint64(epoch)),
+			cumulatedStake: big.NewInt(0),
+		}
+		for _, stakeInfo := range lifecycle.entries {
+			pending.cumulatedStake.Add(pending.cumulatedStake, implementation.GetWeightedStake(stakeInfo, pending.startTime, pending.endTime))
+		}
+		lifecycle.pending = append(lifecycle.pending, pending)
+	}
+	return lifecycle, nil
+}
+
+func (l *stakeLifecycle) toRpc(info *definition.StakeInfo) *StakeEntryLifecycle {
+	entry := &StakeEntryLifecycle{
+		Entry: &StakeEntry{
+			Amount:              info.Amount,
+			WeightedAmount:      info.WeightedAmount,
+			StartTimestamp:      info.StartTime,
+			ExpirationTimestamp: info.ExpirationTime,
+			Address:             info.StakeAddress,
+			Id:                  info.Id,
+		},
+		Duration:           info.ExpirationTime - info.StartTime,
+		RevocableTimestamp: info.ExpirationTime,
+		Revocable:          info.ExpirationTime <= l.frontierTimestamp,
+		AccruedReward:      big.NewInt(0),
+	}
+	if !entry.Revocable {
+		entry.SecondsUntilRevocable = info.ExpirationTime - l.frontierTimestamp
+	}
+	for _, pending := range l.pending {
+		if pending.cumulatedStake.Sign() == 0 {
+			continue
+		}
+		// the entry earns up to the frontier, the share is taken of the whole epoch
+		reward := implementation.GetWeightedStake(info, pending.startTime, common.MinInt64(pending.endTime, l.frontierTimestamp))
+		reward.Mul(reward, pending.reward)
+		reward.Quo(reward, pending.cumulatedStake)
+		entry.AccruedReward.Add(entry.AccruedReward, reward)
+	}
+	return entry
+}
+
+// GetEntriesLifecycleByAddress returns the active stake entries of the address, sorted by expiration,
+// with their maturity and the reward accrued since the last reward epoch.
+func (a *StakeApi) GetEntriesLifecycleByAddress(address types.Address, pageIndex, pageSize uint32) (*StakeEntryLifecycleList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	lifecycle, err := a.getStakeLifecycle()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*definition.StakeInfo, 0)
+	for _, info := range lifecycle.entries {
+		if info.RevokeTime == 0 && info.StakeAddress == address {
+			list = append(list, info)
+		}
+	}
+	return lifecycle.page(list, pageIndex, pageSize), nil
+}
+
+// GetEntriesRevocableWithin returns the active stake entries, of all addresses, which become revocable in
+// the next window seconds after the frontier momentum, sorted by expiration.
+func (a *StakeApi) GetEntriesRevocableWithin(window int64, pageIndex, pageSize uint32) (*StakeEntryLifecycleList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+
+	lifecycle, err := a.getStakeLifecycle()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*definition.StakeInfo, 0)
+	for _, info := range lifecycle.entries {
+		if info.RevokeTime == 0 && info.ExpirationTime > lifecycle.frontierTimestamp && info.ExpirationTime <= lifecycle.frontierTimestamp+window {
+			list = append(list, info)
+		}
+	}
+	return lifecycle.page(list, pageIndex, pageSize), nil
+}
+
+func (l *stakeLifecycle) page(list []*definition.StakeInfo, pageIndex, pageSize uint32) *StakeEntryLifecycleList {
+	sort.Sort(definition.StakeByExpirationTime(list))
+
+	start, end := api.GetRange(pageIndex, pageSize, uint32(len(list)))
+	entries := make([]*StakeEntryLifecycle, end-start)
+	for index, info := range list[start:end] {
+		entries[index] = l.toRpc(info)
+	}
+	return &StakeEntryLifecycleList{
+		FrontierTimestamp: l.frontierTimestamp,
+		Count:             len(list),
+		Entries:           entries,
+	}
+}
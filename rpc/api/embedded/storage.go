@@ -0,0 +1,129 @@
+package embedded
+
+import (
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+type StorageApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewStorageApi(z zenon.Zenon) *StorageApi {
+	return &StorageApi{
+		chain: z.Chain(),
+		log:   common.RPCLogger.New("module", "embedded_storage_api"),
+	}
+}
+
+// StoragePage selects a page of the storage entries. The entries with a key greater than After are returned,
+// so the next page starts after the last key of the previous one, and at most Size of them, RpcMaxPageSize
+// if zero. MomentumHeight reads the storage as of a past momentum, zero is the frontier momentum.
+type StoragePage struct {
+	After          []byte `json:"after"`
+	Size           uint32 `json:"size"`
+	MomentumHeight uint64 `json:"momentumHeight"`
+}
+
+// StorageEntry is a raw key-value of the storage of an embedded contract, the key is the one the definition
+// package reads, without the prefix of the account store.
+type StorageEntry struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// StorageEntries is a page of storage entries, in the order of their keys. More is true if there are more
+// entries after the last one.
+type StorageEntries struct {
+	Momentum types.HashHeight `json:"momentum"`
+	List     []*StorageEntry  `json:"list"`
+	More     bool             `json:"more"`
+}
+
+// GetStorageEntries returns the entries of the storage of the embedded contract whose key starts with prefix,
+// all of them for an empty prefix. The first page at the frontier momentum is returned if page is omitted.
+// Returns null for an unknown momentum height.
+func (a *StorageApi) GetStorageEntries(contract types.Address, prefix []byte, page *StoragePage) (*StorageEntries, error) {
+	if !types.IsEmbeddedAddress(contract) {
+		return nil, api.ErrNotEmbeddedContract
+	}
+	if page == nil {
+		page = &StoragePage{}
+	}
+	if page.Size > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	size := int(page.Size)
+	if size == 0 {
+		size = api.RpcMaxPageSize
+	}
+
+	momentumStore, err := a.momentumStore(page.MomentumHeight)
+	if err != nil || momentumStore == nil {
+		return nil, err
+	}
+	momentum, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	// the smallest key greater than After is After followed by a zero byte
+	var start []byte
+	if len(page.After) != 0 {
+		start = common.JoinBytes(page.After, []byte{0})
+	}
+	iterator := momentumStore.GetAccountStore(contract).Storage().NewIteratorFrom(prefix, start)
+	defer iterator.Release()
+	result := &StorageEntries{
+		Momentum: momentum.Identifier(),
+		List:     make([]*StorageEntry, 0),
+	}
+	for iterator.Next() {
+		if len(result.List) == size {
+			result.More = true
+			break
+		}
+		result.List = append(result.List, &StorageEntry{
+			Key:   common.JoinBytes(iterator.Key()),
+			Value: common.JoinBytes(iterator.Value()),
+		})
+	}
+	if err := iterator.Error(); err != nil {
+		a.log.Error("GetStorageEntries failed", "reason", err, "method-called", "iterator.Next")
+		return nil, err
+	}
+	return result, nil
+}
+
+// momentumStore returns the store of the momentum at height, the frontier one if zero and nil if there's
+// no momentum at height. Only the recent momentums are served, rebuilding the state of an older one costs
+// the rollback of all the momentums after it.
+func (a *StorageApi) momentumStore(height uint64) (store.Momentum, error) {
+	frontierStore := a.chain.GetFrontierMomentumStore()
+	if height == 0 {
+		return frontierStore, nil
+	}
+	momentum, err := frontierStore.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, nil
+	}
+	momentumStore, err := a.chain.GetRecentMomentumStore(momentum.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	if momentumStore == nil {
+		return nil, errors.Errorf("state at momentum %v is no longer available", momentum.Identifier())
+	}
+	return momentumStore, nil
+}
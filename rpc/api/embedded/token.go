@@ -1,12 +1,17 @@
 package embedded
 
 import (
+	"encoding/json"
+	"math/big"
+	"time"
+
 	"github.com/inconshreveable/log15"
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
@@ -49,6 +54,9 @@ func (a *TokenAPI) GetAll(pageIndex, pageSize uint32) (*TokenList, error) {
 	}
 	tokenList := api.LedgerTokenInfosToRpc(tokenListRaw)
 	start, end := api.GetRange(pageIndex, pageSize, uint32(len(tokenList)))
+	if err := api.AddTokenMetadata(a.z.TokenMetadata(), tokenList[start:end]...); err != nil {
+		return nil, err
+	}
 	return &TokenList{
 		Count: len(tokenList),
 		List:  tokenList[start:end],
@@ -77,6 +85,9 @@ func (a *TokenAPI) GetByOwner(owner types.Address, pageIndex, pageSize uint32) (
 	}
 
 	start, end := api.GetRange(pageIndex, pageSize, uint32(len(tokenList)))
+	if err := api.AddTokenMetadata(a.z.TokenMetadata(), tokenList[start:end]...); err != nil {
+		return nil, err
+	}
 	return &TokenList{
 		Count: len(tokenList),
 		List:  tokenList[start:end],
@@ -95,7 +106,274 @@ func (a *TokenAPI) GetByZts(zts types.ZenonTokenStandard) (*api.Token, error) {
 		return nil, err
 	}
 	if tokenInfo != nil {
-		return api.LedgerTokenInfoToRpc(tokenInfo), nil
+		token := api.LedgerTokenInfoToRpc(tokenInfo)
+		if err := api.AddTokenMetadata(a.z.TokenMetadata(), token); err != nil {
+			return nil, err
+		}
+		return token, nil
 	}
 	return nil, nil
 }
+
+// TokenMetadataCheck is the result of the last check of the domain of a token. Reason is why the metadata
+// isn't verified. CheckedAt is in seconds.
+type TokenMetadataCheck struct {
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Domain        string                   `json:"domain"`
+	Verified      bool                     `json:"verified"`
+	Method        string                   `json:"method"`
+	Reason        string                   `json:"reason"`
+	LogoURI       string                   `json:"logoURI"`
+	Website       string                   `json:"website"`
+	CheckedAt     int64                    `json:"checkedAt"`
+}
+
+// GetMetadata returns the result of the last check of the domain of zts, null if it wasn't checked yet or the
+// token has no domain. The domain verifies the metadata by listing the token in its well-known file, with a
+// symbol and decimals matching the ones of the token, or by a DNS TXT record.
+// Requires the token metadata to be enabled.
+func (a *TokenAPI) GetMetadata(zts types.ZenonTokenStandard) (*TokenMetadataCheck, error) {
+	registry := a.z.TokenMetadata()
+	if registry == nil {
+		return nil, api.ErrTokenMetadataDisabled
+	}
+	metadata, err := registry.Get(zts)
+	if err != nil {
+		a.log.Error("GetMetadata failed", "reason", err, "method-called", "tokenmeta.Get")
+		return nil, err
+	}
+	if metadata == nil {
+		return nil, nil
+	}
+	return &TokenMetadataCheck{
+		TokenStandard: metadata.TokenStandard,
+		Domain:        metadata.Domain,
+		Verified:      metadata.Verified,
+		Method:        metadata.Method,
+		Reason:        metadata.Reason,
+		LogoURI:       metadata.LogoURI,
+		Website:       metadata.Website,
+		CheckedAt:     int64(metadata.CheckedAt),
+	}, nil
+}
+
+type TokenHolder struct {
+	Address types.Address `json:"address"`
+	Balance *big.Int      `json:"balance"`
+}
+type TokenHolderMarshal struct {
+	Address types.Address `json:"address"`
+	Balance string        `json:"balance"`
+}
+
+func (h *TokenHolder) ToTokenHolderMarshal() *TokenHolderMarshal {
+	return &TokenHolderMarshal{
+		Address: h.Address,
+		Balance: h.Balance.String(),
+	}
+}
+func (h *TokenHolder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.ToTokenHolderMarshal())
+}
+func (h *TokenHolder) UnmarshalJSON(data []byte) error {
+	aux := new(TokenHolderMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	h.Address = aux.Address
+	h.Balance = common.StringToBigInt(aux.Balance)
+	return nil
+}
+
+// TokenHolderList is a page of holders, their balances are the ones at Momentum.
+type TokenHolderList struct {
+	Momentum types.HashHeight `json:"momentum"`
+	List     []*TokenHolder   `json:"list"`
+	Count    int              `json:"count"`
+	More     bool             `json:"more"`
+}
+
+// GetHolders returns the addresses holding zts along with their balances, ordered by address.
+// Requires the indexer. The holders are the ones at the indexer frontier and the balances are always the
+// ones at the frontier momentum, there is no history. While the indexer catches up, an address which
+// no longer holds zts is listed with a zero balance.
+func (a *TokenAPI) GetHolders(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) (*TokenHolderList, error) {
+	if pageSize > api.RpcMaxPageSize {
+		return nil, api.ErrPageSizeParamTooBig
+	}
+	indexer := a.z.Indexer()
+	if indexer == nil {
+		return nil, api.ErrIndexerDisabled
+	}
+
+	holders, more, err := indexer.GetTokenHolders(zts, pageIndex, pageSize)
+	if err != nil {
+		a.log.Error("GetHolders failed", "reason", err, "method-called", "indexer.GetTokenHolders")
+		return nil, err
+	}
+	// the balances of the page are read from a single momentum, the index is only used to find the holders
+	momentumStore := a.chain.GetFrontierMomentumStore()
+	momentum, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		a.log.Error("GetHolders failed", "reason", err, "method-called", "momentumStore.GetFrontierMomentum")
+		return nil, err
+	}
+	list := make([]*TokenHolder, len(holders))
+	for i, holder := range holders {
+		balance, err := momentumStore.GetAccountStore(holder.Address).GetBalance(zts)
+		if err != nil {
+			a.log.Error("GetHolders failed", "reason", err, "method-called", "accountStore.GetBalance")
+			return nil, err
+		}
+		list[i] = &TokenHolder{
+			Address: holder.Address,
+			Balance: balance,
+		}
+	}
+	return &TokenHolderList{
+		Momentum: momentum.Identifier(),
+		List:     list,
+		Count:    len(list),
+		More:     more,
+	}, nil
+}
+
+type TokenSupplyChange struct {
+	Type              string        `json:"type"`
+	Hash              types.Hash    `json:"hash"`
+	Address           types.Address `json:"address"`
+	Amount            *big.Int      `json:"amount"`
+	TotalSupply       *big.Int      `json:"totalSupply"`
+	MomentumHeight    uint64        `json:"momentumHeight"`
+	MomentumTimestamp int64         `json:"momentumTimestamp"`
+	Epoch             uint64        `json:"epoch"`
+}
+type TokenSupplyChangeMarshal struct {
+	Type              string        `json:"type"`
+	Hash              types.Hash    `json:"hash"`
+	Address           types.Address `json:"address"`
+	Amount            string        `json:"amount"`
+	TotalSupply       string        `json:"totalSupply"`
+	MomentumHeight    uint64        `json:"momentumHeight"`
+	MomentumTimestamp int64         `json:"momentumTimestamp"`
+	Epoch             uint64        `json:"epoch"`
+}
+
+func (c *TokenSupplyChange) ToTokenSupplyChangeMarshal() *TokenSupplyChangeMarshal {
+	return &TokenSupplyChangeMarshal{
+		Type:              c.Type,
+		Hash:              c.Hash,
+		Address:           c.Address,
+		Amount:            c.Amount.String(),
+		TotalSupply:       c.TotalSupply.String(),
+		MomentumHeight:    c.MomentumHeight,
+		MomentumTimestamp: c.MomentumTimestamp,
+		Epoch:             c.Epoch,
+	}
+}
+func (c *TokenSupplyChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.ToTokenSupplyChangeMarshal())
+}
+func (c *TokenSupplyChange) UnmarshalJSON(data []byte) error {
+	aux := new(TokenSupplyChangeMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	c.Type = aux.Type
+	c.Hash = aux.Hash
+	c.Address = aux.Address
+	c.Amount = common.StringToBigInt(aux.Amount)
+	c.TotalSupply = common.StringToBigInt(aux.TotalSupply)
+	c.MomentumHeight = aux.MomentumHeight
+	c.MomentumTimestamp = aux.MomentumTimestamp
+	c.Epoch = aux.Epoch
+	return nil
+}
+
+type TokenSupplyHistory struct {
+	Minted *big.Int             `json:"minted"`
+	Burned *big.Int             `json:"burned"`
+	Count  int                  `json:"count"`
+	List   []*TokenSupplyChange `json:"list"`
+}
+type TokenSupplyHistoryMarshal struct {
+	Minted string               `json:"minted"`
+	Burned string               `json:"burned"`
+	Count  int                  `json:"count"`
+	List   []*TokenSupplyChange `json:"list"`
+}
+
+func (h *TokenSupplyHistory) ToTokenSupplyHistoryMarshal() *TokenSupplyHistoryMarshal {
+	return &TokenSupplyHistoryMarshal{
+		Minted: h.Minted.String(),
+		Burned: h.Burned.String(),
+		Count:  h.Count,
+		List:   h.List,
+	}
+}
+func (h *TokenSupplyHistory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.ToTokenSupplyHistoryMarshal())
+}
+func (h *TokenSupplyHistory) UnmarshalJSON(data []byte) error {
+	aux := new(TokenSupplyHistoryMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	h.Minted = common.StringToBigInt(aux.Minted)
+	h.Burned = common.StringToBigInt(aux.Burned)
+	h.Count = aux.Count
+	h.List = aux.List
+	return nil
+}
+
+// GetSupplyHistory returns the issue, mint and burn events of zts confirmed between the epochs, both inclusive,
+// in confirmation order, along with the total supply after each of them. Minted includes the issued supply.
+// Requires the indexer, the tokens created by the genesis start with a genesis event.
+func (a *TokenAPI) GetSupplyHistory(zts types.ZenonTokenStandard, fromEpoch, toEpoch uint64) (*TokenSupplyHistory, error) {
+	if toEpoch < fromEpoch {
+		return nil, api.ErrInvalidEpochRange
+	}
+	ix := a.z.Indexer()
+	if ix == nil {
+		return nil, api.ErrIndexerDisabled
+	}
+	frontier, err := a.chain.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := a.cs.FixedPillarReader(frontier.Identifier()).EpochTicker()
+	fromTime, _ := ticker.ToTime(fromEpoch)
+	_, toTime := ticker.ToTime(toEpoch)
+	events, err := ix.GetSupplyHistory(zts, fromTime.Unix(), toTime.Unix())
+	if err != nil {
+		a.log.Error("GetSupplyHistory failed", "reason", err, "method-called", "indexer.GetSupplyHistory")
+		return nil, err
+	}
+
+	result := &TokenSupplyHistory{
+		Minted: big.NewInt(0),
+		Burned: big.NewInt(0),
+		Count:  len(events),
+		List:   make([]*TokenSupplyChange, len(events)),
+	}
+	for i, event := range events {
+		if event.Type == indexer.SupplyBurn {
+			result.Burned.Add(result.Burned, event.Amount)
+		} else {
+			result.Minted.Add(result.Minted, event.Amount)
+		}
+		timestamp := time.Unix(int64(event.MomentumTimestamp), 0)
+		result.List[i] = &TokenSupplyChange{
+			Type:              event.Type.String(),
+			Hash:              event.Hash,
+			Address:           event.Address,
+			Amount:            event.Amount,
+			TotalSupply:       event.TotalSupply,
+			MomentumHeight:    event.MomentumHeight,
+			MomentumTimestamp: timestamp.Unix(),
+			Epoch:             ticker.ToTick(timestamp),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,114 @@
+// Package embeddedabi decodes the calls of the embedded contracts for the RPC namespaces.
+package embeddedabi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/abi"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+var (
+	ErrNotEmbeddedContract = common.NewErrorWCode(-32000, "address is not an embedded contract")
+	ErrUnknownMethodName   = common.NewErrorWCode(-32000, "unknown method name for embedded contract")
+
+	ABIs = map[types.Address]abi.ABIContract{
+		types.PlasmaContract:      definition.ABIPlasma,
+		types.PillarContract:      definition.ABIPillars,
+		types.TokenContract:       definition.ABIToken,
+		types.SentinelContract:    definition.ABISentinel,
+		types.SwapContract:        definition.ABISwap,
+		types.StakeContract:       definition.ABIStake,
+		types.SporkContract:       definition.ABISpork,
+		types.AcceleratorContract: definition.ABIAccelerator,
+		types.LiquidityContract:   definition.ABILiquidity,
+		types.BridgeContract:      definition.ABIBridge,
+		types.HtlcContract:        definition.ABIHtlc,
+	}
+)
+
+// Argument is a decoded argument of an embedded method. Numbers, addresses, token standards and
+// hashes are strings, bytes are hex encoded and arrays are lists.
+type Argument struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+type Call struct {
+	Method string      `json:"method"`
+	Inputs []*Argument `json:"inputs"`
+}
+
+// Method looks up the method in the ABI of the contract, falling back on the methods shared by the contracts.
+func Method(contract types.Address, name string) (*abi.Method, error) {
+	contractAbi, ok := ABIs[contract]
+	if !ok {
+		return nil, ErrNotEmbeddedContract
+	}
+	method, ok := contractAbi.Methods[name]
+	if !ok {
+		if method, ok = definition.ABICommon.Methods[name]; !ok {
+			return nil, ErrUnknownMethodName
+		}
+	}
+	return &method, nil
+}
+
+// Decode unpacks the data of an account-block sent to an embedded contract.
+func Decode(contract types.Address, data []byte) (*Call, error) {
+	contractAbi, ok := ABIs[contract]
+	if !ok {
+		return nil, ErrNotEmbeddedContract
+	}
+	if len(data) < 4 {
+		return nil, ErrUnknownMethodName
+	}
+	method, err := contractAbi.MethodById(data[:4])
+	if err != nil {
+		if method, err = definition.ABICommon.MethodById(data[:4]); err != nil {
+			return nil, ErrUnknownMethodName
+		}
+	}
+
+	values, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	call := &Call{
+		Method: method.Name,
+		Inputs: make([]*Argument, len(values)),
+	}
+	for i, value := range values {
+		call.Inputs[i] = &Argument{
+			Name:  method.Inputs[i].Name,
+			Type:  method.Inputs[i].Type.String(),
+			Value: formatValue(reflect.ValueOf(value)),
+		}
+	}
+	return call, nil
+}
+
+func formatValue(value reflect.Value) interface{} {
+	if stringer, ok := value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(data), value)
+			return hex.EncodeToString(data)
+		}
+		list := make([]interface{}, value.Len())
+		for i := range list {
+			list[i] = formatValue(value.Index(i))
+		}
+		return list
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
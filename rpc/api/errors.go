@@ -2,12 +2,34 @@ package api
 
 import (
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
 )
 
 var (
-	ErrPageSizeParamTooBig  = common.NewErrorWCode(-32000, "page-size parameter is too big")
-	ErrPageIndexParamTooBig = common.NewErrorWCode(-32000, "page-index parameter is too big")
-	ErrCountParamTooBig     = common.NewErrorWCode(-32000, "count parameter is too big")
-	ErrHeightParamIsZero    = common.NewErrorWCode(-32000, "height parameter must be strictly greater than zero")
-	ErrParamIsNull          = common.NewErrorWCode(-32000, "parameter must not be null")
+	ErrPageSizeParamTooBig   = common.NewErrorWCode(-32000, "page-size parameter is too big")
+	ErrPageIndexParamTooBig  = common.NewErrorWCode(-32000, "page-index parameter is too big")
+	ErrCountParamTooBig      = common.NewErrorWCode(-32000, "count parameter is too big")
+	ErrAddressesParamTooBig  = common.NewErrorWCode(-32000, "addresses parameter is too big")
+	ErrHeightParamIsZero     = common.NewErrorWCode(-32000, "height parameter must be strictly greater than zero")
+	ErrParamIsNull           = common.NewErrorWCode(-32000, "parameter must not be null")
+	ErrNotEmbeddedContract   = embeddedabi.ErrNotEmbeddedContract
+	ErrUnknownMethodName     = embeddedabi.ErrUnknownMethodName
+	ErrTemplateBlockType     = common.NewErrorWCode(-32000, "templates can only be prepared for user-send and user-receive blocks")
+	ErrTemplateFromEmbedded  = common.NewErrorWCode(-32000, "templates can't be prepared for embedded contracts")
+	ErrDifficultyIsZero      = common.NewErrorWCode(-32000, "difficulty parameter must be strictly greater than zero")
+	ErrDifficultyTooBig      = common.NewErrorWCode(-32000, "difficulty parameter is too big")
+	ErrUnknownSortField      = common.NewErrorWCode(-32000, "unknown sort field")
+	ErrIndexerDisabled       = common.NewErrorWCode(-32000, "indexer is disabled")
+	ErrTokenMetadataDisabled = common.NewErrorWCode(-32000, "token metadata is disabled")
+	ErrInvalidTimeRange      = common.NewErrorWCode(-32000, "end time must be greater than start time")
+	ErrInvalidEpochRange     = common.NewErrorWCode(-32000, "end epoch must not be lower than start epoch")
+	ErrInvalidHeightRange    = common.NewErrorWCode(-32000, "end height must not be lower than start height")
+	ErrNotSendBlock          = common.NewErrorWCode(-32000, "account-block is not a send block")
+	ErrNotReceiveBlock       = common.NewErrorWCode(-32000, "account-block is not a receive block")
+	ErrProducerNotConfigured = common.NewErrorWCode(-32000, "no pillar producer address is configured on the node")
+	ErrTracerDisabled        = common.NewErrorWCode(-32000, "tracer is disabled")
+	ErrPoWDisabled           = common.NewErrorWCode(-32000, "PoW generation is disabled")
+	ErrInvalidMemo           = common.NewErrorWCode(-32000, "memo must be a non-empty printable UTF-8 text of at most 512 bytes")
+	ErrInvalidCursor         = common.NewErrorWCode(-32000, "invalid cursor")
+	ErrCursorRolledBack      = common.NewErrorWCode(-32000, "the page of the cursor was rolled back, list again from the start")
 )
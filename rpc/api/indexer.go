@@ -0,0 +1,95 @@
+package api
+
+import (
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/indexer"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+func NewIndexerApi(z zenon.Zenon) *IndexerApi {
+	return &IndexerApi{
+		chain:   z.Chain(),
+		indexer: z.Indexer(),
+		log:     common.RPCLogger.New("module", "indexer_api"),
+	}
+}
+
+type IndexerApi struct {
+	chain   chain.Chain
+	indexer indexer.Indexer
+	log     log15.Logger
+}
+
+func (a *IndexerApi) GetFrontier() types.HashHeight {
+	return a.indexer.Frontier()
+}
+func (a *IndexerApi) GetBlocksByTokenStandard(zts types.ZenonTokenStandard, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	hashes, more, err := a.indexer.GetBlocksByTokenStandard(zts, pageIndex, pageSize)
+	if err != nil {
+		a.log.Error("GetBlocksByTokenStandard failed", "reason", err, "method-called", "indexer.GetBlocksByTokenStandard")
+		return nil, err
+	}
+	return a.toAccountBlockList(hashes, more)
+}
+func (a *IndexerApi) GetBlocksByAddressPair(from, to types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	hashes, more, err := a.indexer.GetBlocksByAddressPair(from, to, pageIndex, pageSize)
+	if err != nil {
+		a.log.Error("GetBlocksByAddressPair failed", "reason", err, "method-called", "indexer.GetBlocksByAddressPair")
+		return nil, err
+	}
+	return a.toAccountBlockList(hashes, more)
+}
+func (a *IndexerApi) GetBlocksByEmbeddedMethod(contract types.Address, methodName string, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	method, err := embeddedabi.Method(contract, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, more, err := a.indexer.GetBlocksByEmbeddedMethod(contract, method.Id(), pageIndex, pageSize)
+	if err != nil {
+		a.log.Error("GetBlocksByEmbeddedMethod failed", "reason", err, "method-called", "indexer.GetBlocksByEmbeddedMethod")
+		return nil, err
+	}
+	return a.toAccountBlockList(hashes, more)
+}
+
+func (a *IndexerApi) toAccountBlockList(hashes []types.Hash, more bool) (*AccountBlockList, error) {
+	momentumStore := a.chain.GetFrontierMomentumStore()
+	blocks := make([]*nom.AccountBlock, 0, len(hashes))
+	for _, hash := range hashes {
+		block, err := momentumStore.GetAccountBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		// the index can be ahead of the store during a rollback
+		if block == nil {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+
+	list, err := ledgerAccountBlocksToRpc(a.chain, blocks, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountBlockList{
+		List:  list,
+		Count: len(list),
+		More:  more,
+	}, nil
+}
@@ -1,6 +1,9 @@
 package api
 
 import (
+	"context"
+	"math/big"
+	"sort"
 	"time"
 
 	"github.com/inconshreveable/log15"
@@ -8,8 +11,11 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/tracing"
 	"github.com/zenon-network/go-zenon/common/types"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
 	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -34,13 +40,18 @@ const (
 	unreceivedMaxPageIndex = 10
 	unreceivedMaxPageSize  = 50
 	unreceivedQuerySize    = unreceivedMaxPageIndex * unreceivedMaxPageSize
+
+	streamMomentumsBatchSize = 100
+
+	// memoScanSize is the number of account-blocks scanned by GetReceivedBlocksByMemo
+	memoScanSize = 10 * RpcMaxCountSize
 )
 
 func (l LedgerApi) String() string {
 	return "LedgerApi"
 }
 
-func (l *LedgerApi) PublishRawTransaction(block *AccountBlock) error {
+func (l *LedgerApi) PublishRawTransaction(ctx context.Context, block *AccountBlock) error {
 	defer common.RecoverStack()
 	if block == nil {
 		return ErrParamIsNull
@@ -63,16 +74,95 @@ func (l *LedgerApi) PublishRawTransaction(block *AccountBlock) error {
 	}
 
 	supervisor := vm.NewSupervisor(l.z.Chain(), l.z.Consensus())
-	transaction, err := supervisor.ApplyBlock(lb)
+	transaction, err := supervisor.ApplyBlockContext(ctx, lb)
 
 	if err != nil {
 		return err
 	}
 
+	_, span := tracing.StartChildSpan(ctx, "chain.insertAccountBlock")
 	l.z.Broadcaster().CreateAccountBlock(transaction)
+	span.Finish(nil)
 	return nil
 }
 
+type AccountBlockTemplate struct {
+	Block              *nom.AccountBlock `json:"block"`
+	AvailablePlasma    uint64            `json:"availablePlasma"`
+	BasePlasma         uint64            `json:"basePlasma"`
+	RequiredDifficulty uint64            `json:"requiredDifficulty"`
+}
+
+// PrepareAccountBlockTemplate fills in the height, previous hash, momentum acknowledged and plasma fields of an
+// unsigned user block. If the fused plasma of the address is not enough, the required difficulty is set and the
+// caller needs to compute the nonce before signing.
+func (l *LedgerApi) PrepareAccountBlockTemplate(template *nom.AccountBlock) (*AccountBlockTemplate, error) {
+	if template == nil {
+		return nil, ErrParamIsNull
+	}
+	block := template
+	if block.BlockType != nom.BlockTypeUserSend && block.BlockType != nom.BlockTypeUserReceive {
+		return nil, ErrTemplateBlockType
+	}
+	if types.IsEmbeddedAddress(block.Address) {
+		return nil, ErrTemplateFromEmbedded
+	}
+	if block.BlockType == nom.BlockTypeUserSend {
+		if err := checkTokenIdValid(l.chain, &block.TokenStandard); err != nil {
+			return nil, err
+		}
+	} else if block.FromBlockHash.IsZero() {
+		return nil, errors.New("fromBlockHash is zero")
+	}
+
+	// the template is filled from scratch
+	block.PreviousHash = types.ZeroHash
+	block.Height = 0
+	block.MomentumAcknowledged = types.HashHeight{}
+	block.FusedPlasma = 0
+	block.Difficulty = 0
+	block.Nonce = nom.Nonce{}
+	block.Hash = types.ZeroHash
+	block.PublicKey = nil
+	block.Signature = nil
+
+	supervisor := vm.NewSupervisor(l.chain, l.z.Consensus())
+	if err := supervisor.FillTemplate(block); err != nil {
+		return nil, err
+	}
+
+	_, context, err := GetFrontierContext(l.chain, block.Address)
+	if err != nil {
+		return nil, err
+	}
+	availablePlasma, err := vm.AvailablePlasma(context.MomentumStore(), context)
+	if err != nil {
+		return nil, err
+	}
+	basePlasma, err := vm.GetBasePlasmaForAccountBlock(context, block)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountBlockTemplate{
+		Block:           block,
+		AvailablePlasma: availablePlasma,
+		BasePlasma:      basePlasma,
+	}
+	if availablePlasma >= basePlasma {
+		block.FusedPlasma = basePlasma
+	} else {
+		difficulty, err := vm.GetDifficultyForPlasma(basePlasma - availablePlasma)
+		if err != nil {
+			return nil, err
+		}
+		block.FusedPlasma = availablePlasma
+		block.Difficulty = difficulty
+		result.RequiredDifficulty = difficulty
+	}
+	return result, nil
+}
+
 // Unconfirmed AccountBlocks
 func (l *LedgerApi) GetUnconfirmedBlocksByAddress(address types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
 	if pageSize > RpcMaxPageSize {
@@ -81,7 +171,7 @@ func (l *LedgerApi) GetUnconfirmedBlocksByAddress(address types.Address, pageInd
 
 	unreceived := l.chain.GetUncommittedAccountBlocksByAddress(address)
 	start, end := GetRange(pageIndex, pageSize, uint32(len(unreceived)))
-	a, err := ledgerAccountBlocksToRpc(l.chain, unreceived[start:end])
+	a, err := ledgerAccountBlocksToRpc(l.chain, unreceived[start:end], nil)
 
 	if err != nil {
 		return nil, err
@@ -104,9 +194,12 @@ func (l *LedgerApi) GetFrontierAccountBlock(address types.Address) (*AccountBloc
 	if block == nil {
 		return nil, nil
 	}
-	return ledgerAccountBlockToRpc(l.chain, block)
+	return ledgerAccountBlockToRpc(l.chain, block, nil)
 }
-func (l *LedgerApi) GetAccountBlockByHash(blockHash types.Hash) (*AccountBlock, error) {
+
+// GetAccountBlockByHash returns the account-block with the extra info selected by the options, all of it if
+// they are omitted, see AccountBlockOptions.
+func (l *LedgerApi) GetAccountBlockByHash(blockHash types.Hash, options *AccountBlockOptions) (*AccountBlock, error) {
 	momentumStore := l.chain.GetFrontierMomentumStore()
 	block, err := momentumStore.GetAccountBlockByHash(blockHash)
 	if err != nil {
@@ -117,9 +210,115 @@ func (l *LedgerApi) GetAccountBlockByHash(blockHash types.Hash) (*AccountBlock,
 		return nil, nil
 	}
 
-	return ledgerAccountBlockToRpc(l.chain, block)
+	return ledgerAccountBlockToRpc(l.chain, block, options)
+}
+
+// GetReceiveBlockBySendHash returns the block which receives the send block, confirmed or not.
+// Returns nil if the send block is not confirmed.
+func (l *LedgerApi) GetReceiveBlockBySendHash(sendBlockHash types.Hash) (*ReceiveBlockInfo, error) {
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	sendBlock, err := momentumStore.GetAccountBlockByHash(sendBlockHash)
+	if err != nil {
+		l.log.Error("GetReceiveBlockBySendHash failed", "reason", err, "method-called", "momentumStore.GetAccountBlockByHash")
+		return nil, err
+	}
+	if sendBlock == nil {
+		return nil, nil
+	}
+	if !sendBlock.IsSendBlock() {
+		return nil, ErrNotSendBlock
+	}
+
+	status := ReceiveStatusConfirmed
+	receiveBlock, err := momentumStore.GetBlockWhichReceives(sendBlockHash)
+	if err != nil {
+		l.log.Error("GetReceiveBlockBySendHash failed", "reason", err, "method-called", "momentumStore.GetBlockWhichReceives")
+		return nil, err
+	}
+	if receiveBlock == nil {
+		status = ReceiveStatusUnconfirmed
+		for _, block := range l.chain.GetUncommittedAccountBlocksByAddress(sendBlock.ToAddress) {
+			if block.IsReceiveBlock() && block.FromBlockHash == sendBlockHash {
+				receiveBlock = block
+				break
+			}
+		}
+	}
+	if receiveBlock == nil {
+		return &ReceiveBlockInfo{Status: ReceiveStatusUnreceived}, nil
+	}
+
+	block, err := ledgerAccountBlockToRpc(l.chain, receiveBlock, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ReceiveBlockInfo{
+		Status: status,
+		Block:  block,
+	}, nil
 }
-func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, count uint64) (*AccountBlockList, error) {
+
+// GetAccountBlockReceipt returns the receipt of a receive-block, null if the block is unknown or not confirmed.
+func (l *LedgerApi) GetAccountBlockReceipt(blockHash types.Hash) (*AccountBlockReceipt, error) {
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	block, err := momentumStore.GetAccountBlockByHash(blockHash)
+	if err != nil {
+		l.log.Error("GetAccountBlockReceipt failed", "reason", err, "method-called", "momentumStore.GetAccountBlockByHash")
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+	if !block.IsReceiveBlock() {
+		return nil, ErrNotReceiveBlock
+	}
+
+	height, err := momentumStore.GetBlockConfirmationHeight(block.Hash)
+	if err != nil {
+		l.log.Error("GetAccountBlockReceipt failed", "reason", err, "method-called", "momentumStore.GetBlockConfirmationHeight")
+		return nil, err
+	}
+	momentum, err := momentumStore.GetMomentumByHeight(height)
+	if err != nil {
+		l.log.Error("GetAccountBlockReceipt failed", "reason", err, "method-called", "momentumStore.GetMomentumByHeight")
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, nil
+	}
+	logs, err := l.chain.GetAccountBlockLogs(block.Hash)
+	if err != nil {
+		l.log.Error("GetAccountBlockReceipt failed", "reason", err, "method-called", "chain.GetAccountBlockLogs")
+		return nil, err
+	}
+
+	receipt := &AccountBlockReceipt{
+		BlockHash:        block.Hash,
+		Address:          block.Address,
+		FromBlockHash:    block.FromBlockHash,
+		Status:           ReceiptStatusSuccess,
+		DescendantBlocks: make([]types.Hash, 0, len(block.DescendantBlocks)),
+		MomentumHeight:   momentum.Height,
+		MomentumHash:     momentum.Hash,
+		Logs:             make([]*nom.Log, 0, len(logs)),
+	}
+	if !vm.ReceiveSucceeded(block) {
+		receipt.Status = ReceiptStatusFailed
+		if receipt.Failure, err = l.chain.GetAccountBlockFailure(block.Hash); err != nil {
+			l.log.Error("GetAccountBlockReceipt failed", "reason", err, "method-called", "chain.GetAccountBlockFailure")
+			return nil, err
+		}
+	}
+	for _, descendant := range block.DescendantBlocks {
+		receipt.DescendantBlocks = append(receipt.DescendantBlocks, descendant.Hash)
+	}
+	receipt.Logs = append(receipt.Logs, logs...)
+	return receipt, nil
+}
+
+// GetAccountBlocksByHeight returns count account-blocks of address from height, with the extra info selected by
+// the options like GetAccountBlockByHash.
+func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, count uint64, options *AccountBlockOptions) (*AccountBlockList, error) {
 	if height == 0 {
 		return nil, ErrHeightParamIsZero
 	}
@@ -146,7 +345,7 @@ func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, coun
 		return nil, err
 	}
 
-	list, err := ledgerAccountBlocksToRpc(l.chain, accountBlocks)
+	list, err := ledgerAccountBlocksToRpc(l.chain, accountBlocks, options)
 	if err != nil {
 		l.log.Error("GetAccountBlocksByHeight failed", "reason", err, "method-called", "ledgerAccountBlocksToRpc")
 		return nil, err
@@ -157,7 +356,7 @@ func (l *LedgerApi) GetAccountBlocksByHeight(address types.Address, height, coun
 		Count: int(frontier.Height),
 	}, nil
 }
-func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pageSize uint32, options *AccountBlockOptions) (*AccountBlockList, error) {
 	if pageSize > RpcMaxPageSize {
 		return nil, ErrPageSizeParamTooBig
 	}
@@ -190,7 +389,7 @@ func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pag
 		}, nil
 	}
 
-	ans, err := l.GetAccountBlocksByHeight(address, uint64(startHeight), uint64(count))
+	ans, err := l.GetAccountBlocksByHeight(address, uint64(startHeight), uint64(count), options)
 	if err != nil {
 		return nil, err
 	}
@@ -200,11 +399,287 @@ func (l *LedgerApi) GetAccountBlocksByPage(address types.Address, pageIndex, pag
 	}
 	return ans, nil
 }
+
+// GetAccountBlocksByCursor returns the account-blocks of address, newest first, like GetAccountBlocksByPage. The first
+// page is returned for an empty cursor, the next ones for the Cursor of the previous page, which is set while there
+// are older blocks. Unlike the page index, the cursor doesn't move as new blocks are added, so deep pages are
+// fetched directly and stay the same. Count is the height of the frontier account-block. The options select the
+// extra info of the blocks like GetAccountBlockByHash.
+func (l *LedgerApi) GetAccountBlocksByCursor(address types.Address, cursor string, pageSize uint32, options *AccountBlockOptions) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	position, err := decodeCursor(accountBlocksCursor, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	accountStore := l.chain.GetFrontierAccountStore(address)
+	frontier, err := accountStore.Frontier()
+	if err != nil {
+		l.log.Error("GetAccountBlocksByCursor failed", "reason", err, "method-called", "accountStore.Frontier")
+		return nil, err
+	}
+	if frontier == nil {
+		if position != nil {
+			return nil, ErrCursorRolledBack
+		}
+		return &AccountBlockList{
+			List:  make([]*AccountBlock, 0),
+			Count: 0,
+		}, nil
+	}
+
+	top := frontier.Height
+	if position != nil {
+		block, err := accountStore.ByHeight(position.height)
+		if err != nil {
+			l.log.Error("GetAccountBlocksByCursor failed", "reason", err, "method-called", "accountStore.ByHeight")
+			return nil, err
+		}
+		if block == nil {
+			return nil, ErrCursorRolledBack
+		}
+		if err := position.check(&block.Hash); err != nil {
+			return nil, err
+		}
+		top = position.height - 1
+	}
+	if top == 0 || pageSize == 0 {
+		return &AccountBlockList{
+			List:   make([]*AccountBlock, 0),
+			Count:  int(frontier.Height),
+			More:   top != 0,
+			Cursor: cursor,
+		}, nil
+	}
+
+	start := uint64(1)
+	if top > uint64(pageSize) {
+		start = top - uint64(pageSize) + 1
+	}
+	blocks, err := accountStore.MoreByHeight(start, top-start+1)
+	if err != nil {
+		l.log.Error("GetAccountBlocksByCursor failed", "reason", err, "method-called", "accountStore.MoreByHeight")
+		return nil, err
+	}
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	list, err := ledgerAccountBlocksToRpc(l.chain, blocks, options)
+	if err != nil {
+		l.log.Error("GetAccountBlocksByCursor failed", "reason", err, "method-called", "ledgerAccountBlocksToRpc")
+		return nil, err
+	}
+
+	result := &AccountBlockList{
+		List:  list,
+		Count: int(frontier.Height),
+		More:  start > 1,
+	}
+	if result.More {
+		last := blocks[len(blocks)-1]
+		result.Cursor = encodeCursor(accountBlocksCursor, last.Height, last.Hash)
+	}
+	return result, nil
+}
+
+// GetAccountBlocksByTimeRange returns the account blocks of address confirmed by momentums
+// with a timestamp in [startTime, endTime), ordered by height. Count is the number of
+// blocks in the time range.
+func (l *LedgerApi) GetAccountBlocksByTimeRange(address types.Address, startTime, endTime int64, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	if endTime <= startTime {
+		return nil, ErrInvalidTimeRange
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierAccountBlock(address)
+	if err != nil {
+		l.log.Error("GetAccountBlocksByTimeRange failed", "reason", err, "method-called", "GetFrontierAccountBlock")
+		return nil, err
+	}
+	if frontier == nil {
+		return &AccountBlockList{
+			List:  make([]*AccountBlock, 0),
+			Count: 0,
+		}, nil
+	}
+
+	// the momentums in the time range are the ones after the last momentum before startTime,
+	// up to and including the last momentum before endTime
+	startMomentumHeight, endMomentumHeight := uint64(1), uint64(0)
+	for i, timestamp := range []int64{startTime, endTime} {
+		t := time.Unix(timestamp, 0)
+		momentum, err := momentumStore.GetMomentumBeforeTime(&t)
+		if err != nil {
+			l.log.Error("GetAccountBlocksByTimeRange failed", "reason", err, "method-called", "GetMomentumBeforeTime")
+			return nil, err
+		}
+		if momentum == nil {
+			continue
+		}
+		if i == 0 {
+			startMomentumHeight = momentum.Height + 1
+		} else {
+			endMomentumHeight = momentum.Height
+		}
+	}
+
+	start, err := l.searchConfirmedAfter(momentumStore, address, frontier.Height, startMomentumHeight)
+	if err != nil {
+		return nil, err
+	}
+	end, err := l.searchConfirmedAfter(momentumStore, address, frontier.Height, endMomentumHeight+1)
+	if err != nil {
+		return nil, err
+	}
+
+	total := end - start
+	first, last := GetRange(pageIndex, pageSize, uint32(total))
+	if first == last {
+		return &AccountBlockList{
+			List:  make([]*AccountBlock, 0),
+			Count: int(total),
+			More:  false,
+		}, nil
+	}
+	accountBlocks, err := momentumStore.GetAccountBlocksByHeight(address, start+uint64(first), uint64(last-first))
+	if err != nil {
+		l.log.Error("GetAccountBlocksByTimeRange failed", "reason", err, "method-called", "GetAccountBlocksByHeight")
+		return nil, err
+	}
+	list, err := ledgerAccountBlocksToRpc(l.chain, accountBlocks, nil)
+	if err != nil {
+		l.log.Error("GetAccountBlocksByTimeRange failed", "reason", err, "method-called", "ledgerAccountBlocksToRpc")
+		return nil, err
+	}
+	return &AccountBlockList{
+		List:  list,
+		Count: int(total),
+		More:  uint64(last) < total,
+	}, nil
+}
+
+// searchConfirmedAfter returns the height of the first account block of address which is
+// confirmed by a momentum with a height greater than or equal to momentumHeight, or
+// frontierHeight+1 if there is none. Account blocks are confirmed in the order of their heights.
+func (l *LedgerApi) searchConfirmedAfter(momentumStore store.Momentum, address types.Address, frontierHeight, momentumHeight uint64) (uint64, error) {
+	var err error
+	i := sort.Search(int(frontierHeight), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		var block *nom.AccountBlock
+		block, err = momentumStore.GetAccountBlockByHeight(address, uint64(i)+1)
+		if err != nil {
+			return true
+		}
+		if block == nil {
+			err = errors.Errorf("GetAccountBlockByHeight failed; reason: block is nil; height: %v", i+1)
+			return true
+		}
+		var confirmationHeight uint64
+		confirmationHeight, err = momentumStore.GetBlockConfirmationHeight(block.Hash)
+		if err != nil {
+			return true
+		}
+		return confirmationHeight >= momentumHeight
+	})
+	if err != nil {
+		l.log.Error("GetAccountBlocksByTimeRange failed", "reason", err, "method-called", "searchConfirmedAfter")
+		return 0, err
+	}
+	return uint64(i) + 1, nil
+}
 func (l *LedgerApi) GetAccountInfoByAddress(address types.Address) (*AccountInfo, error) {
 	l.log.Info("GetAccountInfoByAddress")
 
 	momentumStore := l.chain.GetFrontierMomentumStore()
 	accountStore := l.chain.GetFrontierAccountStore(address)
+	return l.getAccountInfo(momentumStore, accountStore, address)
+}
+
+// GetAccountInfoByAddressAtHeight returns the account info as of the momentum at momentumHeight,
+// the state is rebuilt by undoing the momentums applied after it. Only the last chain.MaxRecentStateDepth
+// momentums are served, about an hour, older heights return an error. Returns null for unknown heights.
+func (l *LedgerApi) GetAccountInfoByAddressAtHeight(address types.Address, momentumHeight uint64) (*AccountInfo, error) {
+	l.log.Info("GetAccountInfoByAddressAtHeight", "address", address, "momentum-height", momentumHeight)
+
+	momentum, err := l.chain.GetFrontierMomentumStore().GetMomentumByHeight(momentumHeight)
+	if err != nil {
+		l.log.Error("GetAccountInfoByAddressAtHeight failed", "reason", err, "method-called", "momentumStore.GetMomentumByHeight")
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, nil
+	}
+
+	momentumStore, err := l.chain.GetRecentMomentumStore(momentum.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	if momentumStore == nil {
+		return nil, errors.Errorf("state at momentum %v is no longer available", momentum.Identifier())
+	}
+	return l.getAccountInfo(momentumStore, momentumStore.GetAccountStore(address), address)
+}
+
+// GetAccountInfosByAddresses returns the account infos and the unreceived block counts of all addresses,
+// read from the same frontier momentum, along with the combined balances and unreceived blocks.
+func (l *LedgerApi) GetAccountInfosByAddresses(addresses []types.Address) (*AccountSummaryList, error) {
+	l.log.Info("GetAccountInfosByAddresses", "num-addresses", len(addresses))
+	if len(addresses) > RpcMaxCountSize {
+		return nil, ErrAddressesParamTooBig
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	result := &AccountSummaryList{
+		List:          make([]*AccountSummary, 0, len(addresses)),
+		TotalBalances: make(map[types.ZenonTokenStandard]*BalanceInfo),
+	}
+	for _, address := range addresses {
+		accountStore := momentumStore.GetAccountStore(address)
+		info, err := l.getAccountInfo(momentumStore, accountStore, address)
+		if err != nil {
+			return nil, err
+		}
+		hashList, err := momentumStore.GetAccountMailbox(address).GetUnreceivedAccountBlockHashes(unreceivedQuerySize)
+		if err != nil {
+			l.log.Error("GetUnreceivedAccountBlockHashes failed, error is "+err.Error(), "method", "GetAccountInfosByAddresses")
+			return nil, err
+		}
+		unreceived := 0
+		for _, hash := range hashList {
+			if !accountStore.IsReceived(hash) {
+				unreceived += 1
+			}
+		}
+
+		for zts, balanceInfo := range info.BalanceInfoMap {
+			total, ok := result.TotalBalances[zts]
+			if !ok {
+				total = &BalanceInfo{
+					TokenInfo: balanceInfo.TokenInfo,
+					Balance:   big.NewInt(0),
+				}
+				result.TotalBalances[zts] = total
+			}
+			total.Balance.Add(total.Balance, balanceInfo.Balance)
+		}
+		result.TotalUnreceived += unreceived
+		result.List = append(result.List, &AccountSummary{
+			AccountInfo:     *info,
+			UnreceivedCount: unreceived,
+			UnreceivedMore:  len(hashList) == unreceivedQuerySize,
+		})
+	}
+	result.Count = len(result.List)
+	return result, nil
+}
+func (l *LedgerApi) getAccountInfo(momentumStore store.Momentum, accountStore store.Account, address types.Address) (*AccountInfo, error) {
 	frontierAccountBlock, err := accountStore.Frontier()
 	if err != nil {
 		l.log.Error("GetFrontierAccountBlock failed, error is "+err.Error(), "method", "GetAccountInfoByAddress")
@@ -230,8 +705,13 @@ func (l *LedgerApi) GetAccountInfoByAddress(address types.Address) (*AccountInfo
 			continue
 		}
 
+		token := LedgerTokenInfoToRpc(tokenInfo)
+		if err := AddTokenMetadata(l.z.TokenMetadata(), token); err != nil {
+			l.log.Error("AddTokenMetadata failed, error is "+err.Error(), "method", "GetAccountInfoByAddress")
+			return nil, err
+		}
 		balanceInfoMap[zts] = &BalanceInfo{
-			TokenInfo: LedgerTokenInfoToRpc(tokenInfo),
+			TokenInfo: token,
 			Balance:   balance,
 		}
 	}
@@ -251,10 +731,30 @@ func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageInde
 		return nil, ErrPageIndexParamTooBig
 	}
 
+	blockList, isMore, err := l.getUnreceivedBlocks(address)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := GetRange(pageIndex, pageSize, uint32(len(blockList)))
+	a, err := ledgerAccountBlocksToRpc(l.chain, blockList[start:end], nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountBlockList{
+		List:  a,
+		Count: len(blockList),
+		More:  isMore,
+	}, nil
+}
+
+func (l *LedgerApi) getUnreceivedBlocks(address types.Address) ([]*nom.AccountBlock, bool, error) {
 	accountStore := l.chain.GetFrontierAccountStore(address)
 	hashList, err := l.chain.GetFrontierMomentumStore().GetAccountMailbox(address).GetUnreceivedAccountBlockHashes(unreceivedQuerySize)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	ledgerFrontier := l.chain.GetFrontierMomentumStore()
@@ -266,7 +766,7 @@ func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageInde
 		block, err := ledgerFrontier.GetAccountBlockByHash(hash)
 
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		blockList = append(blockList, block)
 	}
@@ -276,21 +776,213 @@ func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, pageInde
 	if len(hashList) == unreceivedQuerySize {
 		isMore = true
 	}
+	return blockList, isMore, nil
+}
 
-	start, end := GetRange(pageIndex, pageSize, uint32(len(blockList)))
-	a, err := ledgerAccountBlocksToRpc(l.chain, blockList[start:end])
+// GetUnreceivedBlocksByMemo returns the unreceived blocks sent to address with the memo, see nom.ParseMemo.
+// Like for GetUnreceivedBlocksByAddress, at most unreceivedQuerySize unreceived blocks are searched and More is set
+// if there may be others.
+func (l *LedgerApi) GetUnreceivedBlocksByMemo(address types.Address, memo string, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > unreceivedMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	if pageIndex >= unreceivedMaxPageIndex {
+		return nil, ErrPageIndexParamTooBig
+	}
+	if _, ok := nom.ParseMemo([]byte(memo)); !ok {
+		return nil, ErrInvalidMemo
+	}
 
+	blockList, isMore, err := l.getUnreceivedBlocks(address)
 	if err != nil {
+		l.log.Error("GetUnreceivedBlocksByMemo failed", "reason", err, "method-called", "getUnreceivedBlocks")
 		return nil, err
 	}
+	matching := make([]*nom.AccountBlock, 0)
+	for _, block := range blockList {
+		if blockMemo, ok := block.Memo(); ok && blockMemo == memo {
+			matching = append(matching, block)
+		}
+	}
 
+	start, end := GetRange(pageIndex, pageSize, uint32(len(matching)))
+	list, err := ledgerAccountBlocksToRpc(l.chain, matching[start:end], nil)
+	if err != nil {
+		return nil, err
+	}
 	return &AccountBlockList{
-		List:  a,
-		Count: len(blockList),
+		List:  list,
+		Count: len(matching),
 		More:  isMore,
 	}, nil
 }
 
+// GetReceivedBlocksByMemo returns the receive blocks of address, newest first, whose send block has the memo, see
+// nom.ParseMemo. Only the last memoScanSize account-blocks of address are searched, More is set if there are older ones.
+// Count is the number of matching blocks found.
+func (l *LedgerApi) GetReceivedBlocksByMemo(address types.Address, memo string, pageIndex, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	if _, ok := nom.ParseMemo([]byte(memo)); !ok {
+		return nil, ErrInvalidMemo
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	accountStore := l.chain.GetFrontierAccountStore(address)
+	frontier, err := accountStore.Frontier()
+	if err != nil {
+		l.log.Error("GetReceivedBlocksByMemo failed", "reason", err, "method-called", "accountStore.Frontier")
+		return nil, err
+	}
+	if frontier == nil {
+		return &AccountBlockList{
+			List:  make([]*AccountBlock, 0),
+			Count: 0,
+		}, nil
+	}
+
+	lowest := uint64(1)
+	if frontier.Height > memoScanSize {
+		lowest = frontier.Height - memoScanSize + 1
+	}
+	matching, _, err := l.scanReceivedByMemo(momentumStore, accountStore, memo, frontier.Height, lowest, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := GetRange(pageIndex, pageSize, uint32(len(matching)))
+	list, err := ledgerAccountBlocksToRpc(l.chain, matching[start:end], nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountBlockList{
+		List:  list,
+		Count: len(matching),
+		More:  lowest > 1,
+	}, nil
+}
+
+// GetReceivedBlocksByMemoCursor returns the receive blocks of address, newest first, whose send block has the memo,
+// like GetReceivedBlocksByMemo but paged by cursors, see GetAccountBlocksByCursor. Each call searches at most
+// memoScanSize account-blocks below the cursor, so a page may have fewer than pageSize blocks and still a Cursor,
+// from which the search continues. Count is the number of blocks in the page.
+func (l *LedgerApi) GetReceivedBlocksByMemoCursor(address types.Address, memo string, cursor string, pageSize uint32) (*AccountBlockList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	if _, ok := nom.ParseMemo([]byte(memo)); !ok {
+		return nil, ErrInvalidMemo
+	}
+	position, err := decodeCursor(memoCursor, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	accountStore := l.chain.GetFrontierAccountStore(address)
+	frontier, err := accountStore.Frontier()
+	if err != nil {
+		l.log.Error("GetReceivedBlocksByMemoCursor failed", "reason", err, "method-called", "accountStore.Frontier")
+		return nil, err
+	}
+	if frontier == nil && position != nil {
+		return nil, ErrCursorRolledBack
+	}
+
+	var top uint64
+	if frontier != nil {
+		top = frontier.Height
+	}
+	if position != nil {
+		block, err := accountStore.ByHeight(position.height)
+		if err != nil {
+			l.log.Error("GetReceivedBlocksByMemoCursor failed", "reason", err, "method-called", "accountStore.ByHeight")
+			return nil, err
+		}
+		if block == nil {
+			return nil, ErrCursorRolledBack
+		}
+		if err := position.check(&block.Hash); err != nil {
+			return nil, err
+		}
+		top = position.height - 1
+	}
+	if top == 0 || pageSize == 0 {
+		return &AccountBlockList{
+			List:   make([]*AccountBlock, 0),
+			Count:  0,
+			More:   top != 0,
+			Cursor: cursor,
+		}, nil
+	}
+
+	lowest := uint64(1)
+	if top > memoScanSize {
+		lowest = top - memoScanSize + 1
+	}
+	matching, last, err := l.scanReceivedByMemo(momentumStore, accountStore, memo, top, lowest, int(pageSize))
+	if err != nil {
+		return nil, err
+	}
+	list, err := ledgerAccountBlocksToRpc(l.chain, matching, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountBlockList{
+		List:  list,
+		Count: len(list),
+		More:  last.Height > 1,
+	}
+	if result.More {
+		result.Cursor = encodeCursor(memoCursor, last.Height, last.Hash)
+	}
+	return result, nil
+}
+
+// scanReceivedByMemo searches the account-blocks of address from top down to lowest for the receive blocks whose send
+// block has the memo. The search stops once limit blocks are found, zero for no limit. It returns the matching blocks,
+// newest first, and the last account-block searched.
+func (l *LedgerApi) scanReceivedByMemo(momentumStore store.Momentum, accountStore store.Account, memo string, top, lowest uint64, limit int) ([]*nom.AccountBlock, *nom.AccountBlock, error) {
+	matching := make([]*nom.AccountBlock, 0)
+	var last *nom.AccountBlock
+	for ; top >= lowest; top -= RpcMaxCountSize {
+		height := lowest
+		if top >= lowest+RpcMaxCountSize {
+			height = top - RpcMaxCountSize + 1
+		}
+		blocks, err := accountStore.MoreByHeight(height, top-height+1)
+		if err != nil {
+			l.log.Error("scanReceivedByMemo failed", "reason", err, "method-called", "accountStore.MoreByHeight")
+			return nil, nil, err
+		}
+		for i := len(blocks) - 1; i >= 0; i -= 1 {
+			last = blocks[i]
+			if blocks[i].BlockType != nom.BlockTypeUserReceive {
+				continue
+			}
+			sendBlock, err := momentumStore.GetAccountBlockByHash(blocks[i].FromBlockHash)
+			if err != nil {
+				l.log.Error("scanReceivedByMemo failed", "reason", err, "method-called", "momentumStore.GetAccountBlockByHash")
+				return nil, nil, err
+			}
+			if sendBlock == nil {
+				continue
+			}
+			if sendMemo, ok := sendBlock.Memo(); ok && sendMemo == memo {
+				matching = append(matching, blocks[i])
+				if len(matching) == limit {
+					return matching, last, nil
+				}
+			}
+		}
+		if height == lowest {
+			break
+		}
+	}
+	return matching, last, nil
+}
+
 // Momentum
 func (l *LedgerApi) GetFrontierMomentum() (*Momentum, error) {
 	momentum, err := l.chain.GetFrontierMomentumStore().GetFrontierMomentum()
@@ -384,7 +1076,151 @@ func (l *LedgerApi) GetMomentumsByPage(pageIndex, pageSize uint32) (*MomentumLis
 	}
 	return ans, nil
 }
-func (l *LedgerApi) GetDetailedMomentumsByHeight(height, count uint64) (*DetailedMomentumList, error) {
+
+// GetMomentumsByCursor returns the momentums, newest first, like GetMomentumsByPage but paged by cursors, see
+// GetAccountBlocksByCursor. Count is the height of the frontier momentum.
+func (l *LedgerApi) GetMomentumsByCursor(cursor string, pageSize uint32) (*MomentumList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	position, err := decodeCursor(momentumsCursor, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		l.log.Error("GetMomentumsByCursor failed", "reason", err, "method-called", "momentumStore.GetFrontierMomentum")
+		return nil, err
+	}
+
+	top := frontier.Height
+	if position != nil {
+		momentum, err := momentumStore.GetMomentumByHeight(position.height)
+		if err != nil {
+			l.log.Error("GetMomentumsByCursor failed", "reason", err, "method-called", "momentumStore.GetMomentumByHeight")
+			return nil, err
+		}
+		if momentum == nil {
+			return nil, ErrCursorRolledBack
+		}
+		if err := position.check(&momentum.Hash); err != nil {
+			return nil, err
+		}
+		top = position.height - 1
+	}
+	if top == 0 || pageSize == 0 {
+		result := &MomentumList{
+			List:  make([]*Momentum, 0),
+			Count: int(frontier.Height),
+		}
+		if top != 0 {
+			result.Cursor = cursor
+		}
+		return result, nil
+	}
+
+	start := uint64(1)
+	if top > uint64(pageSize) {
+		start = top - uint64(pageSize) + 1
+	}
+	momentums, err := momentumStore.GetMomentumsByHeight(start, true, top-start+1)
+	if err != nil {
+		l.log.Error("GetMomentumsByCursor failed", "reason", err, "method-called", "momentumStore.GetMomentumsByHeight")
+		return nil, err
+	}
+	for i, j := 0, len(momentums)-1; i < j; i, j = i+1, j-1 {
+		momentums[i], momentums[j] = momentums[j], momentums[i]
+	}
+	list, err := ledgerMomentumsToRpc(momentums)
+	if err != nil {
+		l.log.Error("GetMomentumsByCursor failed", "reason", err, "method-called", "ledgerMomentumsToRpc")
+		return nil, err
+	}
+
+	result := &MomentumList{
+		List:  list,
+		Count: int(frontier.Height),
+	}
+	if start > 1 {
+		last := momentums[len(momentums)-1]
+		result.Cursor = encodeCursor(momentumsCursor, last.Height, last.Hash)
+	}
+	return result, nil
+}
+
+// GetMomentumsByTimestampRange returns the headers of the momentums with a timestamp in [startTime, endTime),
+// ordered by height. Count is the number of momentums in the time range.
+func (l *LedgerApi) GetMomentumsByTimestampRange(startTime, endTime int64, pageIndex, pageSize uint32) (*MomentumHeaderList, error) {
+	if pageSize > RpcMaxPageSize {
+		return nil, ErrPageSizeParamTooBig
+	}
+	if endTime <= startTime {
+		return nil, ErrInvalidTimeRange
+	}
+
+	momentumStore := l.chain.GetFrontierMomentumStore()
+	start, err := l.momentumHeightByTime(momentumStore, startTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := l.momentumHeightByTime(momentumStore, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	total := end - start
+	first, last := GetRange(pageIndex, pageSize, uint32(total))
+	momentums, err := momentumStore.GetMomentumsByHeight(start+uint64(first), true, uint64(last-first))
+	if err != nil {
+		l.log.Error("GetMomentumsByTimestampRange failed", "reason", err, "method-called", "momentumStore.GetMomentumsByHeight")
+		return nil, err
+	}
+	list := make([]*MomentumHeader, 0, len(momentums))
+	for _, momentum := range momentums {
+		list = append(list, &MomentumHeader{
+			Hash:      momentum.Hash,
+			Height:    momentum.Height,
+			Timestamp: momentum.Timestamp.Unix(),
+		})
+	}
+	return &MomentumHeaderList{
+		List:  list,
+		Count: int(total),
+		More:  uint64(last) < total,
+	}, nil
+}
+
+// momentumHeightByTime returns the height of the first momentum with a timestamp greater than or equal to timestamp,
+// or the frontier height + 1 if there is none. The time index of the indexer is used if it's enabled and caught up.
+func (l *LedgerApi) momentumHeightByTime(momentumStore store.Momentum, timestamp int64) (uint64, error) {
+	if indexer := l.z.Indexer(); indexer != nil {
+		height, ok, err := indexer.GetMomentumHeightByTime(timestamp)
+		if err != nil {
+			l.log.Error("GetMomentumsByTimestampRange failed", "reason", err, "method-called", "indexer.GetMomentumHeightByTime")
+			return 0, err
+		}
+		if ok {
+			return height, nil
+		}
+	}
+
+	t := time.Unix(timestamp, 0)
+	momentum, err := momentumStore.GetMomentumBeforeTime(&t)
+	if err != nil {
+		l.log.Error("GetMomentumsByTimestampRange failed", "reason", err, "method-called", "momentumStore.GetMomentumBeforeTime")
+		return 0, err
+	}
+	if momentum == nil {
+		return 1, nil
+	}
+	return momentum.Height + 1, nil
+}
+
+// GetDetailedMomentumsByHeight returns count momentums from height along with the account-blocks they confirm,
+// with the extra info selected by the options like GetAccountBlockByHash.
+func (l *LedgerApi) GetDetailedMomentumsByHeight(height, count uint64, options *AccountBlockOptions) (*DetailedMomentumList, error) {
 	l.log.Info("GetDetailedMomentumsByHeight", "height", height, "count", count)
 	if count > RpcMaxCountSize {
 		return nil, ErrCountParamTooBig
@@ -394,5 +1230,188 @@ func (l *LedgerApi) GetDetailedMomentumsByHeight(height, count uint64) (*Detaile
 	if err != nil {
 		return nil, err
 	}
-	return momentumListToDetailedList(l.chain, ans)
+	return momentumListToDetailedList(l.chain, ans, options)
+}
+
+// StreamMomentums pushes the momentums from fromHeight to toHeight, capped at the frontier, in batches of
+// ascending height followed by an empty batch once done. Each batch is read from disk only after the
+// previous one was written to the connection, so a slow client slows down the stream. The options select the
+// extra info of the account-blocks of the detailed momentums like GetAccountBlockByHash.
+func (l *LedgerApi) StreamMomentums(ctx context.Context, fromHeight, toHeight uint64, detailed bool, options *AccountBlockOptions) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	if fromHeight == 0 {
+		return nil, ErrHeightParamIsZero
+	}
+	if toHeight < fromHeight {
+		return nil, ErrInvalidHeightRange
+	}
+	frontier, err := l.chain.GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		l.log.Error("StreamMomentums failed", "reason", err, "method-called", "momentumStore.GetFrontierMomentum")
+		return nil, err
+	}
+	if toHeight > frontier.Height {
+		toHeight = frontier.Height
+	}
+	l.log.Info("new subscription", "type", "StreamMomentums", "from", fromHeight, "to", toHeight, "detailed", detailed)
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		defer common.RecoverStack()
+		// notifications sent before activation are buffered in memory
+		select {
+		case <-notifier.Activated():
+		case <-rpcSub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+
+		for height := fromHeight; height <= toHeight; height += streamMomentumsBatchSize {
+			select {
+			case err := <-rpcSub.Err():
+				l.log.Info("unsubscribing due to rpc-sub", "reason", err)
+				return
+			case <-notifier.Closed():
+				l.log.Info("unsubscribing", "reason", "notifier-closed")
+				return
+			default:
+			}
+
+			count := toHeight - height + 1
+			if count > streamMomentumsBatchSize {
+				count = streamMomentumsBatchSize
+			}
+			batch, size, err := l.streamBatch(height, count, detailed, options)
+			if err != nil {
+				l.log.Error("StreamMomentums failed", "reason", err, "method-called", "streamBatch")
+				return
+			}
+			// the chain was rolled back below height
+			if size == 0 {
+				break
+			}
+			if err := notifier.Notify(rpcSub.ID, batch); err != nil {
+				l.log.Info("failed to notify", "reason", err)
+				return
+			}
+		}
+		if err := notifier.Notify(rpcSub.ID, []interface{}{}); err != nil {
+			l.log.Info("failed to notify", "reason", err)
+		}
+	}()
+	return rpcSub, nil
+}
+
+// streamBatch reads count momentums starting at height, returns the batch and the number of momentums in it.
+func (l *LedgerApi) streamBatch(height, count uint64, detailed bool, options *AccountBlockOptions) (interface{}, int, error) {
+	momentums, err := l.chain.GetFrontierMomentumStore().GetMomentumsByHeight(height, true, count)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := ledgerMomentumsToRpc(momentums)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !detailed {
+		return list, len(list), nil
+	}
+	detailedList, err := momentumListToDetailedList(l.chain, &MomentumList{List: list}, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	return detailedList.List, len(list), nil
+}
+
+// GetRollbackInfo returns the momentum rollbacks seen since the node started.
+func (l *LedgerApi) GetRollbackInfo() *chain.RollbackInfo {
+	return l.chain.GetRollbackInfo()
+}
+
+// Snapshots
+
+// GetAccountSnapshot returns the state of the address at the momentum, only the last chain.MaxRecentStateDepth
+// momentums are served. Returns null for unknown momentums. It replaces the requested ledger.getAccountProof,
+// which was declined: the momentums don't commit to a state root, so there is nothing to prove the balances
+// against and the snapshot is as trusted as the node serving it.
+func (l *LedgerApi) GetAccountSnapshot(address types.Address, momentumHash types.Hash) (*AccountSnapshot, error) {
+	momentum, err := l.chain.GetFrontierMomentumStore().GetMomentumByHash(momentumHash)
+	if err != nil {
+		l.log.Error("GetAccountSnapshot failed", "reason", err, "method-called", "momentumStore.GetMomentumByHash")
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, nil
+	}
+
+	momentumStore, err := l.chain.GetRecentMomentumStore(momentum.Identifier())
+	if err != nil {
+		return nil, err
+	}
+	if momentumStore == nil {
+		return nil, errors.Errorf("state at momentum %v is no longer available", momentum.Identifier())
+	}
+	accountStore := momentumStore.GetAccountStore(address)
+
+	snapshot := &AccountSnapshot{
+		Address:      address,
+		BalanceMap:   make(map[types.ZenonTokenStandard]*big.Int),
+		ContentIndex: -1,
+	}
+	if snapshot.Momentum, err = ledgerMomentumToRpc(momentum); err != nil {
+		return nil, err
+	}
+
+	balanceMap, err := accountStore.GetBalanceMap()
+	if err != nil {
+		l.log.Error("GetAccountSnapshot failed", "reason", err, "method-called", "accountStore.GetBalanceMap")
+		return nil, err
+	}
+	for zts, balance := range balanceMap {
+		snapshot.BalanceMap[zts] = balance
+	}
+
+	frontier, err := accountStore.Frontier()
+	if err != nil {
+		l.log.Error("GetAccountSnapshot failed", "reason", err, "method-called", "accountStore.Frontier")
+		return nil, err
+	}
+	if frontier == nil {
+		return snapshot, nil
+	}
+	snapshot.AccountHeight = frontier.Height
+	snapshot.FrontierBlock = frontier
+
+	confirmationHeight, err := momentumStore.GetBlockConfirmationHeight(frontier.Hash)
+	if err != nil {
+		l.log.Error("GetAccountSnapshot failed", "reason", err, "method-called", "momentumStore.GetBlockConfirmationHeight")
+		return nil, err
+	}
+	confirmation, err := momentumStore.GetMomentumByHeight(confirmationHeight)
+	if err != nil {
+		l.log.Error("GetAccountSnapshot failed", "reason", err, "method-called", "momentumStore.GetMomentumByHeight")
+		return nil, err
+	}
+	if confirmation == nil {
+		return nil, errors.Errorf("failed to get confirmation momentum for %v", frontier.Header())
+	}
+	if snapshot.ConfirmationMomentum, err = ledgerMomentumToRpc(confirmation); err != nil {
+		return nil, err
+	}
+
+	header := frontier.Header()
+	for index, entry := range confirmation.Content {
+		if *entry == header {
+			snapshot.ContentIndex = index
+			break
+		}
+	}
+	if snapshot.ContentIndex == -1 {
+		return nil, errors.Errorf("account-block %v is not part of confirmation momentum %v", header, confirmation.Identifier())
+	}
+
+	return snapshot, nil
 }
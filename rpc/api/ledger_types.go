@@ -6,8 +6,11 @@ import (
 
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/tokenmeta"
+	"github.com/zenon-network/go-zenon/vm"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
@@ -36,19 +39,27 @@ type AccountBlock struct {
 	TokenInfo          *Token                          `json:"token"`
 	ConfirmationDetail *AccountBlockConfirmationDetail `json:"confirmationDetail"`
 	PairedAccountBlock *AccountBlock                   `json:"pairedAccountBlock"`
+	// Failure is set on the confirmed contract receive-blocks whose call the embedded contract rejected
+	Failure *nom.ReceiveFailure `json:"failure,omitempty"`
 }
 
 type AccountBlockMarshal struct {
 	nom.AccountBlockMarshal
+	Memo               *string                         `json:"memo,omitempty"`
 	TokenInfo          *TokenMarshal                   `json:"token"`
 	ConfirmationDetail *AccountBlockConfirmationDetail `json:"confirmationDetail"`
 	PairedAccountBlock *AccountBlockMarshal            `json:"pairedAccountBlock"`
+	Failure            *nom.ReceiveFailure             `json:"failure,omitempty"`
 }
 
 func (block *AccountBlock) ToAccountBlockMarshal() *AccountBlockMarshal {
 	aux := &AccountBlockMarshal{
 		AccountBlockMarshal: *block.AccountBlock.ToNomMarshalJson(),
 		ConfirmationDetail:  block.ConfirmationDetail,
+		Failure:             block.Failure,
+	}
+	if memo, ok := block.AccountBlock.Memo(); ok {
+		aux.Memo = &memo
 	}
 	if block.TokenInfo != nil {
 		aux.TokenInfo = block.TokenInfo.ToTokenMarshal()
@@ -74,6 +85,7 @@ func (block *AccountBlock) UnmarshalJSON(data []byte) error {
 		block.TokenInfo = aux.TokenInfo.FromTokenMarshal()
 	}
 	block.ConfirmationDetail = aux.ConfirmationDetail
+	block.Failure = aux.Failure
 	if aux.PairedAccountBlock != nil {
 		block.PairedAccountBlock = aux.PairedAccountBlock.FromApiMarshalJson()
 	}
@@ -83,6 +95,7 @@ func (block *AccountBlock) UnmarshalJSON(data []byte) error {
 func (a *AccountBlockMarshal) FromApiMarshalJson() *AccountBlock {
 	aux := &AccountBlock{
 		ConfirmationDetail: a.ConfirmationDetail,
+		Failure:            a.Failure,
 	}
 	block := a.FromNomMarshalJson()
 	aux.AccountBlock = *block
@@ -95,11 +108,60 @@ func (a *AccountBlockMarshal) FromApiMarshalJson() *AccountBlock {
 	return aux
 }
 
+const (
+	ReceiveStatusUnreceived  = "unreceived"
+	ReceiveStatusUnconfirmed = "unconfirmed"
+	ReceiveStatusConfirmed   = "confirmed"
+)
+
+// ReceiveBlockInfo is the receive block of a send block. Block is nil while the send block is unreceived,
+// an unconfirmed receive block has no confirmation detail.
+type ReceiveBlockInfo struct {
+	Status string        `json:"status"`
+	Block  *AccountBlock `json:"block"`
+}
+
+const (
+	ReceiptStatusSuccess = "success"
+	ReceiptStatusFailed  = "failed"
+)
+
+// AccountBlockReceipt is the outcome of a confirmed receive-block, with the logs emitted by the embedded
+// contract which received it. A failed contract call has no logs, its failure says why it was rejected and
+// its only descendant block refunds the sent tokens, if any.
+type AccountBlockReceipt struct {
+	BlockHash        types.Hash    `json:"blockHash"`
+	Address          types.Address `json:"address"`
+	FromBlockHash    types.Hash    `json:"fromBlockHash"`
+	Status           string        `json:"status"`
+	DescendantBlocks []types.Hash  `json:"descendantBlocks"`
+	MomentumHeight   uint64        `json:"momentumHeight"`
+	MomentumHash     types.Hash    `json:"momentumHash"`
+	Logs             []*nom.Log    `json:"logs"`
+	// Failure is why the embedded contract rejected the call, null if it succeeded
+	Failure *nom.ReceiveFailure `json:"failure"`
+}
+
 type AccountInfo struct {
 	Address        types.Address                             `json:"address"`
 	AccountHeight  uint64                                    `json:"accountHeight"`
 	BalanceInfoMap map[types.ZenonTokenStandard]*BalanceInfo `json:"balanceInfoMap"`
 }
+
+// AccountSummary is the account info of an address along with the number of blocks it has to receive.
+// UnreceivedMore is true if the address has more unreceived blocks than the ones counted.
+type AccountSummary struct {
+	AccountInfo
+	UnreceivedCount int  `json:"unreceivedCount"`
+	UnreceivedMore  bool `json:"unreceivedMore"`
+}
+type AccountSummaryList struct {
+	List            []*AccountSummary                         `json:"list"`
+	Count           int                                       `json:"count"`
+	TotalBalances   map[types.ZenonTokenStandard]*BalanceInfo `json:"totalBalances"`
+	TotalUnreceived int                                       `json:"totalUnreceived"`
+}
+
 type BalanceInfo struct {
 	TokenInfo *Token   `json:"token"`
 	Balance   *big.Int `json:"balance"`
@@ -112,8 +174,11 @@ type BalanceInfoMarshal struct {
 
 func (b *BalanceInfo) ToBalanceInfoMarshal() BalanceInfoMarshal {
 	aux := BalanceInfoMarshal{
-		TokenInfo: b.TokenInfo.ToTokenMarshal(),
-		Balance:   b.Balance.String(),
+		Balance: b.Balance.String(),
+	}
+	// light nodes don't know the tokens
+	if b.TokenInfo != nil {
+		aux.TokenInfo = b.TokenInfo.ToTokenMarshal()
 	}
 	return aux
 }
@@ -126,11 +191,74 @@ func (b *BalanceInfo) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	b.TokenInfo = aux.TokenInfo.FromTokenMarshal()
+	if aux.TokenInfo != nil {
+		b.TokenInfo = aux.TokenInfo.FromTokenMarshal()
+	}
 	b.Balance = common.StringToBigInt(aux.Balance)
 	return nil
 }
 
+// AccountSnapshot is the state of an account at a momentum. It is not a proof: momentums don't commit
+// to a state root, so the balances can't be checked against Momentum and must be trusted to the node.
+// Only the frontier of the account can be checked, FrontierBlock is found at ContentIndex in the
+// content of ConfirmationMomentum.
+type AccountSnapshot struct {
+	Momentum             *Momentum                             `json:"momentum"`
+	Address              types.Address                         `json:"address"`
+	AccountHeight        uint64                                `json:"accountHeight"`
+	BalanceMap           map[types.ZenonTokenStandard]*big.Int `json:"balanceMap"`
+	FrontierBlock        *nom.AccountBlock                     `json:"frontierBlock"`
+	ConfirmationMomentum *Momentum                             `json:"confirmationMomentum"`
+	ContentIndex         int                                   `json:"contentIndex"`
+}
+
+type AccountSnapshotMarshal struct {
+	Momentum             *Momentum                           `json:"momentum"`
+	Address              types.Address                       `json:"address"`
+	AccountHeight        uint64                              `json:"accountHeight"`
+	BalanceMap           map[types.ZenonTokenStandard]string `json:"balanceMap"`
+	FrontierBlock        *nom.AccountBlock                   `json:"frontierBlock"`
+	ConfirmationMomentum *Momentum                           `json:"confirmationMomentum"`
+	ContentIndex         int                                 `json:"contentIndex"`
+}
+
+func (p *AccountSnapshot) ToAccountSnapshotMarshal() *AccountSnapshotMarshal {
+	aux := &AccountSnapshotMarshal{
+		Momentum:             p.Momentum,
+		Address:              p.Address,
+		AccountHeight:        p.AccountHeight,
+		BalanceMap:           make(map[types.ZenonTokenStandard]string, len(p.BalanceMap)),
+		FrontierBlock:        p.FrontierBlock,
+		ConfirmationMomentum: p.ConfirmationMomentum,
+		ContentIndex:         p.ContentIndex,
+	}
+	for zts, balance := range p.BalanceMap {
+		aux.BalanceMap[zts] = balance.String()
+	}
+	return aux
+}
+func (p *AccountSnapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.ToAccountSnapshotMarshal())
+}
+func (p *AccountSnapshot) UnmarshalJSON(data []byte) error {
+	aux := new(AccountSnapshotMarshal)
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	p.Momentum = aux.Momentum
+	p.Address = aux.Address
+	p.AccountHeight = aux.AccountHeight
+	p.BalanceMap = make(map[types.ZenonTokenStandard]*big.Int, len(aux.BalanceMap))
+	for zts, balance := range aux.BalanceMap {
+		p.BalanceMap[zts] = common.StringToBigInt(balance)
+	}
+	p.FrontierBlock = aux.FrontierBlock
+	p.ConfirmationMomentum = aux.ConfirmationMomentum
+	p.ContentIndex = aux.ContentIndex
+	return nil
+}
+
 type Token struct {
 	TokenName          string                   `json:"name"`
 	TokenSymbol        string                   `json:"symbol"`
@@ -143,6 +271,7 @@ type Token struct {
 	IsBurnable         bool                     `json:"isBurnable"`
 	IsMintable         bool                     `json:"isMintable"`
 	IsUtility          bool                     `json:"isUtility"`
+	Metadata           *TokenMetadata           `json:"metadata,omitempty"`
 }
 
 type TokenMarshal struct {
@@ -157,6 +286,7 @@ type TokenMarshal struct {
 	IsBurnable         bool                     `json:"isBurnable"`
 	IsMintable         bool                     `json:"isMintable"`
 	IsUtility          bool                     `json:"isUtility"`
+	Metadata           *TokenMetadata           `json:"metadata,omitempty"`
 }
 
 func (t *Token) ToTokenMarshal() *TokenMarshal {
@@ -172,6 +302,7 @@ func (t *Token) ToTokenMarshal() *TokenMarshal {
 		IsBurnable:         t.IsBurnable,
 		IsMintable:         t.IsMintable,
 		IsUtility:          t.IsUtility,
+		Metadata:           t.Metadata,
 	}
 	return aux
 }
@@ -195,6 +326,7 @@ func (t *Token) UnmarshalJSON(data []byte) error {
 	t.IsBurnable = aux.IsBurnable
 	t.IsMintable = aux.IsMintable
 	t.IsUtility = aux.IsUtility
+	t.Metadata = aux.Metadata
 	return nil
 }
 
@@ -211,38 +343,70 @@ func (t *TokenMarshal) FromTokenMarshal() *Token {
 		IsBurnable:         t.IsBurnable,
 		IsMintable:         t.IsMintable,
 		IsUtility:          t.IsUtility,
+		Metadata:           t.Metadata,
+	}
+}
+
+// TokenMetadata is the metadata published by the domain of a token, see tokenmeta.Registry. Tokens have it
+// only once it's verified, Method tells how.
+type TokenMetadata struct {
+	LogoURI   string `json:"logoURI"`
+	Website   string `json:"website"`
+	Method    string `json:"method"`
+	CheckedAt int64  `json:"checkedAt"`
+}
+
+// AddTokenMetadata sets the verified metadata of the tokens, if the node fetches it.
+func AddTokenMetadata(registry tokenmeta.Registry, tokens ...*Token) error {
+	if registry == nil {
+		return nil
+	}
+	for _, token := range tokens {
+		if token == nil {
+			continue
+		}
+		metadata, err := registry.Get(token.ZenonTokenStandard)
+		if err != nil {
+			return err
+		}
+		if metadata == nil || !metadata.Verified {
+			continue
+		}
+		token.Metadata = &TokenMetadata{
+			LogoURI:   metadata.LogoURI,
+			Website:   metadata.Website,
+			Method:    metadata.Method,
+			CheckedAt: int64(metadata.CheckedAt),
+		}
 	}
+	return nil
 }
 
+// AccountBlockList is a page of account-blocks. Cursor is the continuation token of the next page, set by the
+// lists which are paged by cursors while there are more blocks.
 type AccountBlockList struct {
-	List  []*AccountBlock `json:"list"`
-	Count int             `json:"count"`
-	More  bool            `json:"more"`
+	List   []*AccountBlock `json:"list"`
+	Count  int             `json:"count"`
+	More   bool            `json:"more"`
+	Cursor string          `json:"cursor,omitempty"`
 }
 
 type AccountBlockListMarshal struct {
-	List  []*AccountBlockMarshal `json:"list"`
-	Count int                    `json:"count"`
-	More  bool                   `json:"more"`
+	List   []*AccountBlockMarshal `json:"list"`
+	Count  int                    `json:"count"`
+	More   bool                   `json:"more"`
+	Cursor string                 `json:"cursor,omitempty"`
 }
 
 func (abl *AccountBlockList) ToAccountBlockListMarshal() *AccountBlockListMarshal {
 	aux := &AccountBlockListMarshal{
-		Count: abl.Count,
-		More:  abl.More,
+		Count:  abl.Count,
+		More:   abl.More,
+		Cursor: abl.Cursor,
 	}
 	aux.List = make([]*AccountBlockMarshal, 0)
-	for idx, block := range abl.List {
-		aux.List = append(aux.List, &AccountBlockMarshal{
-			AccountBlockMarshal: *block.ToNomMarshalJson(),
-			ConfirmationDetail:  block.ConfirmationDetail,
-		})
-		if block.TokenInfo != nil {
-			aux.List[idx].TokenInfo = block.TokenInfo.ToTokenMarshal()
-		}
-		if block.PairedAccountBlock != nil {
-			aux.List[idx].PairedAccountBlock = block.PairedAccountBlock.ToAccountBlockMarshal()
-		}
+	for _, block := range abl.List {
+		aux.List = append(aux.List, block.ToAccountBlockMarshal())
 	}
 	return aux
 }
@@ -262,12 +426,20 @@ func (abl *AccountBlockList) UnmarshalJSON(data []byte) error {
 	}
 	abl.Count = aux.Count
 	abl.More = aux.More
+	abl.Cursor = aux.Cursor
 	return nil
 }
 
+// MomentumList is a page of momentums, Cursor is the continuation token of the next page, see AccountBlockList.
 type MomentumList struct {
-	List  []*Momentum `json:"list"`
-	Count int         `json:"count"`
+	List   []*Momentum `json:"list"`
+	Count  int         `json:"count"`
+	Cursor string      `json:"cursor,omitempty"`
+}
+type MomentumHeaderList struct {
+	List  []*MomentumHeader `json:"list"`
+	Count int               `json:"count"`
+	More  bool              `json:"more"`
 }
 type DetailedMomentumList struct {
 	List  []*DetailedMomentum `json:"list"`
@@ -285,24 +457,97 @@ func (block *AccountBlock) ComputeHash() (*types.Hash, error) {
 	hash := lAb.ComputeHash()
 	return &hash, nil
 }
-func (block *AccountBlock) prefetchToken(chain chain.Chain) error {
-	store := chain.GetFrontierMomentumStore()
-	if block.TokenStandard != types.ZeroTokenStandard {
-		token, err := store.GetTokenInfoByTs(block.TokenStandard)
-		if err != nil {
-			return err
-		}
-		block.TokenInfo = LedgerTokenInfoToRpc(token)
+
+// AccountBlockOptions selects the extra info of the account-blocks returned by the ledger RPCs, all of it is
+// included if the options are omitted. Each of them costs store lookups for every block, so the clients which
+// don't show them, like the lists of the explorers, can skip them.
+type AccountBlockOptions struct {
+	PairedAccountBlock bool `json:"pairedAccountBlock"`
+	TokenInfo          bool `json:"tokenInfo"`
+	ConfirmationDetail bool `json:"confirmationDetail"`
+}
+
+var allAccountBlockInfo = &AccountBlockOptions{
+	PairedAccountBlock: true,
+	TokenInfo:          true,
+	ConfirmationDetail: true,
+}
+
+// blockInfo adds the extra info to the account-blocks of a response. They are all read at the same frontier
+// momentum and the tokens and momentums shared by the blocks are only looked up once.
+type blockInfo struct {
+	chain    chain.Chain
+	store    store.Momentum
+	frontier *nom.Momentum
+	options  *AccountBlockOptions
+
+	tokens    map[types.ZenonTokenStandard]*Token
+	momentums map[uint64]*nom.Momentum
+}
+
+func newBlockInfo(chain chain.Chain, options *AccountBlockOptions) *blockInfo {
+	if options == nil {
+		options = allAccountBlockInfo
+	}
+	return &blockInfo{
+		chain:     chain,
+		store:     chain.GetFrontierMomentumStore(),
+		options:   options,
+		tokens:    make(map[types.ZenonTokenStandard]*Token),
+		momentums: make(map[uint64]*nom.Momentum),
+	}
+}
+
+func (i *blockInfo) token(zts types.ZenonTokenStandard) (*Token, error) {
+	if token, ok := i.tokens[zts]; ok {
+		return token, nil
+	}
+	tokenInfo, err := i.store.GetTokenInfoByTs(zts)
+	if err != nil {
+		return nil, err
+	}
+	token := LedgerTokenInfoToRpc(tokenInfo)
+	i.tokens[zts] = token
+	return token, nil
+}
+func (i *blockInfo) momentum(height uint64) (*nom.Momentum, error) {
+	if momentum, ok := i.momentums[height]; ok {
+		return momentum, nil
+	}
+	momentum, err := i.store.GetMomentumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	i.momentums[height] = momentum
+	return momentum, nil
+}
+func (i *blockInfo) frontierMomentum() (*nom.Momentum, error) {
+	if i.frontier != nil {
+		return i.frontier, nil
 	}
+	frontier, err := i.store.GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+	i.frontier = frontier
+	return frontier, nil
+}
+
+func (i *blockInfo) addToken(block *AccountBlock) error {
+	if block.TokenStandard == types.ZeroTokenStandard {
+		return nil
+	}
+	token, err := i.token(block.TokenStandard)
+	if err != nil {
+		return err
+	}
+	block.TokenInfo = token
 	return nil
 }
-func (block *AccountBlock) prefetchPaired(chain chain.Chain) error {
-	store := chain.GetFrontierMomentumStore()
-	var err error
-	var paired *nom.AccountBlock
+func (i *blockInfo) addPaired(block *AccountBlock) error {
 	if block.BlockType == nom.BlockTypeGenesisReceive {
-		genesis := chain.GetGenesisMomentum()
-		frontier, _ := store.GetFrontierMomentum()
+		genesis := i.chain.GetGenesisMomentum()
+		frontier, _ := i.frontierMomentum()
 		block.PairedAccountBlock = &AccountBlock{
 			AccountBlock: nom.AccountBlock{
 				BlockType:        nom.BlockTypeContractSend,
@@ -319,10 +564,12 @@ func (block *AccountBlock) prefetchPaired(chain chain.Chain) error {
 		return nil
 	}
 
+	var paired *nom.AccountBlock
+	var err error
 	if nom.IsSendBlock(block.BlockType) {
-		paired, err = store.GetBlockWhichReceives(block.Hash)
+		paired, err = i.store.GetBlockWhichReceives(block.Hash)
 	} else {
-		paired, err = store.GetAccountBlockByHash(block.FromBlockHash)
+		paired, err = i.store.GetAccountBlockByHash(block.FromBlockHash)
 	}
 	if err != nil {
 		return err
@@ -331,64 +578,119 @@ func (block *AccountBlock) prefetchPaired(chain chain.Chain) error {
 		block.PairedAccountBlock = &AccountBlock{
 			AccountBlock: *paired.Copy(),
 		}
-		if err := block.PairedAccountBlock.prefetchToken(chain); err != nil {
+		// the paired block has its token and confirmation detail regardless of the options
+		if err := i.addToken(block.PairedAccountBlock); err != nil {
 			return err
 		}
-		if err := block.PairedAccountBlock.addConfirmationInfo(chain); err != nil {
+		if err := i.addConfirmation(block.PairedAccountBlock); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
-func (block *AccountBlock) addConfirmationInfo(chain chain.Chain) error {
-	store := chain.GetFrontierMomentumStore()
-	frontier, err := store.GetFrontierMomentum()
-	confirmationHeight, err := chain.GetFrontierMomentumStore().GetBlockConfirmationHeight(block.Hash)
+func (i *blockInfo) addConfirmation(block *AccountBlock) error {
+	confirmationHeight, err := i.store.GetBlockConfirmationHeight(block.Hash)
 	if err != nil {
 		return err
 	}
-	confirmedBlock, err := chain.GetFrontierMomentumStore().GetMomentumByHeight(confirmationHeight)
+	if confirmationHeight == 0 {
+		return nil
+	}
+	confirmedBy, err := i.momentum(confirmationHeight)
 	if err != nil {
 		return err
 	}
-	if confirmedBlock != nil && frontier != nil && confirmedBlock.Height <= frontier.Height {
+	return i.setConfirmation(block, confirmedBy)
+}
+
+// setConfirmation sets the confirmation detail of a block confirmed by the momentum.
+func (i *blockInfo) setConfirmation(block *AccountBlock, confirmedBy *nom.Momentum) error {
+	frontier, err := i.frontierMomentum()
+	if err != nil {
+		return err
+	}
+	if confirmedBy != nil && frontier != nil && confirmedBy.Height <= frontier.Height {
 		block.ConfirmationDetail = &AccountBlockConfirmationDetail{
-			NumConfirmations:  frontier.Height - confirmedBlock.Height + 1,
-			MomentumHeight:    confirmedBlock.Height,
-			MomentumHash:      confirmedBlock.Hash,
-			MomentumTimestamp: confirmedBlock.Timestamp.Unix(),
+			NumConfirmations:  frontier.Height - confirmedBy.Height + 1,
+			MomentumHeight:    confirmedBy.Height,
+			MomentumHash:      confirmedBy.Hash,
+			MomentumTimestamp: confirmedBy.Timestamp.Unix(),
 		}
 	}
-
 	return nil
 }
-func (block *AccountBlock) addAllExtraInfo(chain chain.Chain) error {
-	if err := block.prefetchPaired(chain); err != nil {
-		return err
-	}
-	if err := block.prefetchToken(chain); err != nil {
-		return err
+func (i *blockInfo) addFailure(block *AccountBlock) error {
+	if vm.ReceiveSucceeded(&block.AccountBlock) {
+		return nil
 	}
-	if err := block.addConfirmationInfo(chain); err != nil {
+	failure, err := i.chain.GetAccountBlockFailure(block.Hash)
+	if err != nil {
 		return err
 	}
-
+	block.Failure = failure
 	return nil
 }
 
-func momentumListToDetailedList(chain chain.Chain, list *MomentumList) (*DetailedMomentumList, error) {
+// toRpc returns the block with the extra info selected by the options, confirmedBy is the momentum which
+// confirmed it if the caller knows it.
+func (i *blockInfo) toRpc(lAb *nom.AccountBlock, confirmedBy *nom.Momentum) (*AccountBlock, error) {
+	block := &AccountBlock{
+		AccountBlock: *lAb.Copy(),
+	}
+	if i.options.PairedAccountBlock {
+		if err := i.addPaired(block); err != nil {
+			return nil, err
+		}
+	}
+	if i.options.TokenInfo {
+		if err := i.addToken(block); err != nil {
+			return nil, err
+		}
+	}
+	if i.options.ConfirmationDetail {
+		var err error
+		if confirmedBy != nil {
+			err = i.setConfirmation(block, confirmedBy)
+		} else {
+			err = i.addConfirmation(block)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := i.addFailure(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+func (i *blockInfo) listToRpc(list []*nom.AccountBlock, confirmedBy *nom.Momentum) ([]*AccountBlock, error) {
+	blocks := make([]*AccountBlock, 0, len(list))
+	for _, lAb := range list {
+		if lAb == nil {
+			continue
+		}
+		block, err := i.toRpc(lAb, confirmedBy)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func momentumListToDetailedList(chain chain.Chain, list *MomentumList, options *AccountBlockOptions) (*DetailedMomentumList, error) {
 	ans := &DetailedMomentumList{
 		Count: list.Count,
 		List:  make([]*DetailedMomentum, len(list.List)),
 	}
+	info := newBlockInfo(chain, options)
 	for index, momentum := range list.List {
-		store := chain.GetFrontierMomentumStore()
-		m, err := store.PrefetchMomentum(momentum.Momentum)
+		m, err := info.store.PrefetchMomentum(momentum.Momentum)
 		if err != nil {
 			return nil, err
 		}
-		accountBlocks, err := ledgerAccountBlocksToRpc(chain, m.AccountBlocks)
+		// the blocks are confirmed by the momentum, which spares looking it up for each of them
+		accountBlocks, err := info.listToRpc(m.AccountBlocks, momentum.Momentum)
 		if err != nil {
 			return nil, err
 		}
@@ -434,33 +736,11 @@ func ledgerMomentumsToRpc(list []*nom.Momentum) ([]*Momentum, error) {
 
 	return momentums, nil
 }
-func ledgerAccountBlockToRpc(chain chain.Chain, lAb *nom.AccountBlock) (*AccountBlock, error) {
-	rpcBlock := &AccountBlock{
-		AccountBlock: *lAb.Copy(),
-	}
-	if err := rpcBlock.addAllExtraInfo(chain); err != nil {
-		return nil, err
-	}
-
-	return rpcBlock, nil
+func ledgerAccountBlockToRpc(chain chain.Chain, lAb *nom.AccountBlock, options *AccountBlockOptions) (*AccountBlock, error) {
+	return newBlockInfo(chain, options).toRpc(lAb, nil)
 }
-func ledgerAccountBlocksToRpc(chain chain.Chain, list []*nom.AccountBlock) ([]*AccountBlock, error) {
-	if list == nil {
-		return []*AccountBlock{}, nil
-	}
-
-	blocks := make([]*AccountBlock, 0, len(list))
-	for _, block := range list {
-		if block == nil {
-		} else {
-			rpc, err := ledgerAccountBlockToRpc(chain, block)
-			if err != nil {
-				return nil, err
-			}
-			blocks = append(blocks, rpc)
-		}
-	}
-	return blocks, nil
+func ledgerAccountBlocksToRpc(chain chain.Chain, list []*nom.AccountBlock, options *AccountBlockOptions) ([]*AccountBlock, error) {
+	return newBlockInfo(chain, options).listToRpc(list, nil)
 }
 func LedgerTokenInfoToRpc(tokenInfo *definition.TokenInfo) *Token {
 	var rt *Token = nil
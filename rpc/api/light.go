@@ -0,0 +1,181 @@
+package api
+
+import (
+	"math/big"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/protocol"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+// LightLedgerApi serves the ledger methods which a light node can answer. Momentums come from the
+// verified headers, the state of the accounts is fetched from the peers on demand and has no token
+// details. Account-blocks are checked for their hash and signature only and are verified by the peers.
+type LightLedgerApi struct {
+	client *protocol.LightClient
+	log    log15.Logger
+}
+
+func NewLightLedgerApi(client *protocol.LightClient) *LightLedgerApi {
+	return &LightLedgerApi{
+		client: client,
+		log:    common.RPCLogger.New("module", "light_ledger_api"),
+	}
+}
+
+func (l *LightLedgerApi) PublishRawTransaction(block *AccountBlock) error {
+	if block == nil {
+		return ErrParamIsNull
+	}
+	if block.ChainIdentifier != 0 && block.ChainIdentifier != l.client.ChainIdentifier() {
+		return errors.Errorf("the block has a different network Id (%d) from the node (%d)", block.ChainIdentifier, l.client.ChainIdentifier())
+	}
+	lb, err := block.ToLedgerBlock()
+	if err != nil {
+		return err
+	}
+	if lb.ComputeHash() != lb.Hash {
+		return errors.Errorf("the block hash %v doesn't match its content", lb.Hash)
+	}
+	verified, err := wallet.VerifySignature(lb.PublicKey, lb.Hash.Bytes(), lb.Signature)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.New("the block signature is invalid")
+	}
+	l.client.BroadcastAccountBlock(lb)
+	return nil
+}
+
+func (l *LightLedgerApi) GetFrontierMomentum() (*Momentum, error) {
+	return ledgerMomentumToRpc(l.client.GetFrontierMomentum())
+}
+func (l *LightLedgerApi) GetMomentumByHash(hash types.Hash) (*Momentum, error) {
+	momentum, err := l.client.GetMomentumByHash(hash)
+	if err != nil {
+		l.log.Error("GetMomentumByHash failed", "reason", err, "method-called", "client.GetMomentumByHash")
+		return nil, err
+	}
+	return ledgerMomentumToRpc(momentum)
+}
+func (l *LightLedgerApi) GetMomentumsByHeight(height, count uint64) (*MomentumList, error) {
+	if height == 0 {
+		return nil, ErrHeightParamIsZero
+	}
+	if count > RpcMaxCountSize {
+		return nil, ErrCountParamTooBig
+	}
+
+	frontier := l.client.GetFrontierMomentum()
+	list := make([]*Momentum, 0, count)
+	for current := height; current < height+count && current <= frontier.Height; current += 1 {
+		momentum, err := l.client.GetMomentumByHeight(current)
+		if err != nil {
+			l.log.Error("GetMomentumsByHeight failed", "reason", err, "method-called", "client.GetMomentumByHeight")
+			return nil, err
+		}
+		rpc, err := ledgerMomentumToRpc(momentum)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rpc)
+	}
+	return &MomentumList{
+		List:  list,
+		Count: int(frontier.Height),
+	}, nil
+}
+
+// GetAccountInfoByAddress returns the account info at the frontier header, without the token details.
+func (l *LightLedgerApi) GetAccountInfoByAddress(address types.Address) (*AccountInfo, error) {
+	l.log.Info("GetAccountInfoByAddress")
+	snapshot, err := l.GetAccountSnapshot(address, l.client.GetFrontierMomentum().Hash)
+	if err != nil {
+		return nil, err
+	}
+	info := &AccountInfo{
+		Address:        address,
+		AccountHeight:  snapshot.AccountHeight,
+		BalanceInfoMap: make(map[types.ZenonTokenStandard]*BalanceInfo, len(snapshot.BalanceMap)),
+	}
+	for zts, balance := range snapshot.BalanceMap {
+		info.BalanceInfoMap[zts] = &BalanceInfo{Balance: balance}
+	}
+	return info, nil
+}
+
+// GetAccountSnapshot fetches the state of the address at a synced momentum from the peers. The frontier
+// account-block must be the latest one confirmed by the synced headers, the balances can't be verified
+// against the headers and are only served if all the peers asked agree on them.
+func (l *LightLedgerApi) GetAccountSnapshot(address types.Address, momentumHash types.Hash) (*AccountSnapshot, error) {
+	momentum, err := l.client.GetMomentumByHash(momentumHash)
+	if err != nil {
+		return nil, err
+	}
+	if momentum == nil {
+		return nil, nil
+	}
+	raw, err := l.client.GetAccountProof(address, momentumHash)
+	if err != nil {
+		l.log.Error("GetAccountSnapshot failed", "reason", err, "method-called", "client.GetAccountProof")
+		return nil, err
+	}
+
+	snapshot := &AccountSnapshot{
+		Address:      address,
+		BalanceMap:   make(map[types.ZenonTokenStandard]*big.Int, len(raw.Balances)),
+		ContentIndex: -1,
+	}
+	if snapshot.Momentum, err = ledgerMomentumToRpc(momentum); err != nil {
+		return nil, err
+	}
+	for _, balance := range raw.Balances {
+		snapshot.BalanceMap[balance.TokenStandard] = balance.Amount
+	}
+	if raw.FrontierBlock == nil {
+		return snapshot, nil
+	}
+	snapshot.AccountHeight = raw.FrontierBlock.Height
+	snapshot.FrontierBlock = raw.FrontierBlock
+
+	confirmation, err := l.client.GetMomentumByHeight(raw.ConfirmationHeight)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.ConfirmationMomentum, err = ledgerMomentumToRpc(confirmation); err != nil {
+		return nil, err
+	}
+	header := raw.FrontierBlock.Header()
+	for index, entry := range confirmation.Content {
+		if *entry == header {
+			snapshot.ContentIndex = index
+			break
+		}
+	}
+	return snapshot, nil
+}
+
+// LightStatsApi reports the progress of the header sync of a light node.
+type LightStatsApi struct {
+	client *protocol.LightClient
+}
+
+func NewLightStatsApi(client *protocol.LightClient) *LightStatsApi {
+	return &LightStatsApi{
+		client: client,
+	}
+}
+
+func (api *LightStatsApi) SyncInfo() (*protocol.SyncInfo, error) {
+	return api.client.SyncInfo(), nil
+}
+
+func (api *LightStatsApi) RpcMetrics() (*rpc.RPCMetrics, error) {
+	return rpc.Metrics(), nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/p2p"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+)
+
+type NetApi struct {
+	p2p *p2p.Server
+	log log15.Logger
+}
+
+func NewNetApi(p2p *p2p.Server) *NetApi {
+	return &NetApi{
+		p2p: p2p,
+		log: common.RPCLogger.New("module", "net_api"),
+	}
+}
+
+// PeerDetails is a connected peer with its session. LastDisconnect is the previous session
+// of the same peer, if it's among the recent disconnects of the node.
+type PeerDetails struct {
+	*Peer
+	Inbound        bool            `json:"inbound"`
+	Version        uint64          `json:"version"`   // base protocol version of the peer
+	Protocols      []string        `json:"protocols"` // protocols which run with the peer, in their Cap notation
+	Uptime         int64           `json:"uptime"`    // session duration in seconds
+	LastDisconnect *p2p.Disconnect `json:"lastDisconnect"`
+	// Metadata is the telemetry the peer advertised, nil unless it opted in
+	Metadata *p2p.PeerMetadata `json:"metadata"`
+}
+
+// Peers returns the connected peers along with their measured latency and session.
+func (api *NetApi) Peers() ([]*PeerDetails, error) {
+	peersRaw := api.p2p.Peers()
+	peers := make([]*PeerDetails, 0, len(peersRaw))
+	for _, raw := range peersRaw {
+		peer, err := p2pPeerToPeer(raw)
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, &PeerDetails{
+			Peer:           peer,
+			Inbound:        raw.Inbound(),
+			Version:        raw.Version(),
+			Protocols:      raw.Protocols(),
+			Uptime:         int64(raw.Uptime() / time.Second),
+			LastDisconnect: api.p2p.LastDisconnect(peer.PublicKey),
+			Metadata:       raw.Metadata(),
+		})
+	}
+	return peers, nil
+}
+
+// PeerEvents pushes the peer lifecycle events of the p2p server (add, drop, handshakeFailed).
+func (api *NetApi) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	api.log.Info("new subscription", "type", "PeerEvents")
+
+	rpcSub := notifier.CreateSubscription()
+	events := make(chan *p2p.PeerEvent, p2p.PeerEventChanSize)
+	unsubscribe := api.p2p.SubscribeEvents(events)
+
+	go func() {
+		defer common.RecoverStack()
+		defer unsubscribe()
+		for {
+			select {
+			case event := <-events:
+				if err := notifier.Notify(rpcSub.ID, []interface{}{event}); err != nil {
+					api.log.Info("failed to notify", "reason", err)
+				}
+			case err := <-rpcSub.Err():
+				api.log.Info("unsubscribing due to rpc-sub", "reason", err)
+				return
+			case <-notifier.Closed():
+				api.log.Info("unsubscribing", "reason", "notifier-closed")
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/inconshreveable/log15"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/pillar"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+type ProducerApi struct {
+	z   zenon.Zenon
+	log log15.Logger
+}
+
+func NewProducerApi(z zenon.Zenon) *ProducerApi {
+	return &ProducerApi{
+		z:   z,
+		log: common.RPCLogger.New("module", "producer_api"),
+	}
+}
+
+// GetStats reports the health of the momentum production of the pillar configured on the node: the
+// upcoming slots, the missed slots in the current epoch, the clock drift and the last produced momentum.
+func (api *ProducerApi) GetStats() (*pillar.Stats, error) {
+	producer := api.z.Producer()
+	if producer == nil || producer.GetCoinBase() == nil {
+		return nil, ErrProducerNotConfigured
+	}
+	return producer.GetStats()
+}
@@ -1,18 +1,23 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/shirou/gopsutil/host"
 	"github.com/shirou/gopsutil/mem"
 
+	"github.com/zenon-network/go-zenon/chain/momentum"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/metadata"
 	"github.com/zenon-network/go-zenon/p2p"
 	"github.com/zenon-network/go-zenon/p2p/discover"
 	"github.com/zenon-network/go-zenon/protocol"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -80,6 +85,7 @@ type Peer struct {
 	PublicKey string `json:"publicKey"`
 	IP        string `json:"ip"`
 	Name      string `json:"name"`
+	Latency   int64  `json:"latency"` // Latency is the round trip time in milliseconds, 0 until measured
 }
 type NetworkInfoResponse struct {
 	NumPeers int     `json:"numPeers"`
@@ -94,6 +100,7 @@ func p2pPeerToPeer(peer *p2p.Peer) (*Peer, error) {
 		PublicKey: peer.ID().String(),
 		IP:        splits[0],
 		Name:      peer.Name(),
+		Latency:   peer.Latency().Milliseconds(),
 	}, nil
 }
 func selfToPeer(node *discover.Node) *Peer {
@@ -125,3 +132,78 @@ func (api *StatsApi) NetworkInfo() (*NetworkInfoResponse, error) {
 func (api *StatsApi) SyncInfo() (*protocol.SyncInfo, error) {
 	return api.z.Broadcaster().SyncInfo(), nil
 }
+
+// HealthResponse reports whether the node is fit to serve clients: synced, connected to enough
+// peers and with a recent frontier momentum. Reasons lists the failed checks.
+type HealthResponse struct {
+	Healthy        bool               `json:"healthy"`
+	SyncState      protocol.SyncState `json:"syncState"`
+	NumPeers       int                `json:"numPeers"`
+	MinPeers       int                `json:"minPeers"`
+	FrontierHeight uint64             `json:"frontierHeight"`
+	MomentumAge    int64              `json:"momentumAge"` // seconds since the frontier momentum
+	MaxMomentumAge int64              `json:"maxMomentumAge"`
+	Reasons        []string           `json:"reasons"`
+}
+
+// NodeHealth checks the node against the health thresholds of its config, it also backs the /health endpoint.
+func NodeHealth(z zenon.Zenon, p2p *p2p.Server) (*HealthResponse, error) {
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HealthResponse{
+		SyncState:      z.Broadcaster().SyncInfo().State,
+		FrontierHeight: frontier.Height,
+		MomentumAge:    common.Clock.Now().Unix() - int64(frontier.TimestampUnix),
+		Reasons:        make([]string, 0),
+	}
+	if p2p != nil {
+		result.NumPeers = p2p.PeerCount()
+	}
+	if config := z.Config(); config != nil {
+		result.MinPeers = config.HealthMinPeers
+		result.MaxMomentumAge = int64(config.HealthMaxMomentumAge / time.Second)
+	}
+
+	if result.SyncState != protocol.SyncDone {
+		result.Reasons = append(result.Reasons, "node is not in sync")
+	}
+	if result.MinPeers != 0 && result.NumPeers < result.MinPeers {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("node has %v peers, expected at least %v", result.NumPeers, result.MinPeers))
+	}
+	if result.MaxMomentumAge != 0 && result.MomentumAge > result.MaxMomentumAge {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("frontier momentum is %v seconds old, expected at most %v", result.MomentumAge, result.MaxMomentumAge))
+	}
+	result.Healthy = len(result.Reasons) == 0
+	return result, nil
+}
+
+func (api *StatsApi) Health() (*HealthResponse, error) {
+	return NodeHealth(api.z, api.p2p)
+}
+
+// RpcMetrics returns the call counts, error rates and latency histograms of the RPC methods, grouped by namespace,
+// along with the most recent slow queries.
+func (api *StatsApi) RpcMetrics() (*rpc.RPCMetrics, error) {
+	return rpc.Metrics(), nil
+}
+
+// ApiKeyUsage returns the calls made with the API keys since they were added, the clients of the HTTP and WS
+// endpoints only see the usage of their own key.
+func (api *StatsApi) ApiKeyUsage(ctx context.Context) ([]*rpc.APIKeyUsage, error) {
+	return rpc.GetAPIKeyUsage(ctx), nil
+}
+
+// CacheStats returns the hits and misses of the cache of the momentums, account-blocks and token infos,
+// null if the cache is disabled.
+func (api *StatsApi) CacheStats() (*momentum.CacheStats, error) {
+	return api.z.Chain().GetCacheStats(), nil
+}
+
+// ClockDrift reports how far the local clock is off, measured against the timestamps of the momentums and against
+// the NTP server, along with the thresholds past which warnings are logged and the pillar stops producing.
+func (api *StatsApi) ClockDrift() (*zenon.ClockDrift, error) {
+	return api.z.ClockDrift(), nil
+}
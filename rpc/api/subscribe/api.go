@@ -3,6 +3,7 @@ package subscribe
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/inconshreveable/log15"
 
@@ -10,12 +11,20 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
 	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 )
 
 const (
 	acChanSize    = 100
 	mChanSize     = 100
+	rChanSize     = 10
+	sChanSize     = 10
+	lChanSize     = 100
+	cChanSize     = 100
+	fChanSize     = 10
 	installSize   = 100
 	uninstallSize = 100
 )
@@ -25,6 +34,8 @@ var (
 	singleton    *Server
 )
 
+var ErrExpirationWindowTooBig = common.NewErrorWCode(-32000, "the expiration window is longer than the fusion duration")
+
 type Momentum struct {
 	Hash   types.Hash `json:"hash"`
 	Height uint64     `json:"height"`
@@ -38,6 +49,100 @@ type AccountBlock struct {
 	FromHash  types.Hash    `json:"fromHash"`
 }
 
+const (
+	SporkEventActivated = "activated"
+	SporkEventEnforced  = "enforced"
+)
+
+// SporkEvent is sent once a spork is activated and once it's enforced, at EnforcementHeight.
+type SporkEvent struct {
+	Event             string     `json:"event"`
+	Id                types.Hash `json:"id"`
+	Name              string     `json:"name"`
+	EnforcementHeight uint64     `json:"enforcementHeight"`
+	Momentum          *Momentum  `json:"momentum"`
+}
+
+// Log is a log emitted by an embedded contract, sent once the momentum which confirms the receive-block is inserted.
+// LogIndex is the position of the log among the ones of the receive-block.
+type Log struct {
+	Address   types.Address `json:"address"`
+	Topics    []types.Hash  `json:"topics"`
+	Data      []byte        `json:"data"`
+	BlockHash types.Hash    `json:"blockHash"`
+	LogIndex  uint32        `json:"logIndex"`
+	Momentum  *Momentum     `json:"momentum"`
+}
+
+// ContractCall is a call of an embedded contract, sent once the momentum which confirms the receive-block is inserted.
+// Method and Inputs are decoded from the data of the send-block, Method is empty if the data isn't a call of the contract.
+type ContractCall struct {
+	Contract         types.Address            `json:"contract"`
+	ReceiveBlockHash types.Hash               `json:"receiveBlockHash"`
+	SendBlockHash    types.Hash               `json:"sendBlockHash"`
+	Sender           types.Address            `json:"sender"`
+	TokenStandard    types.ZenonTokenStandard `json:"tokenStandard"`
+	Amount           string                   `json:"amount"`
+	Method           string                   `json:"method"`
+	Inputs           []*embeddedabi.Argument  `json:"inputs"`
+	Momentum         *Momentum                `json:"momentum"`
+}
+
+// FusionExpiration is sent when a fusion entry of the owner can be revoked within the expiration window of the
+// subscription, at the momentum whose height is ExpirationHeight minus the window.
+type FusionExpiration struct {
+	Owner            types.Address `json:"owner"`
+	Id               types.Hash    `json:"id"`
+	Beneficiary      types.Address `json:"beneficiary"`
+	QsrAmount        string        `json:"qsrAmount"`
+	ExpirationHeight uint64        `json:"expirationHeight"`
+	Momentum         *Momentum     `json:"momentum"`
+}
+
+// LogFilter selects logs by contract and topics. Empty Addresses matches all contracts.
+// Topics[i] lists the accepted values of the i-th topic, an empty entry matches any value.
+type LogFilter struct {
+	Addresses []types.Address `json:"addresses"`
+	Topics    [][]types.Hash  `json:"topics"`
+}
+
+func (f *LogFilter) matches(log *Log) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Addresses) != 0 {
+		found := false
+		for _, address := range f.Addresses {
+			if address == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, accepted := range f.Topics {
+		if len(accepted) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range accepted {
+			if topic == log.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func newAccountBlock(block *nom.AccountBlock) []*AccountBlock {
 	all := make([]*AccountBlock, 1, len(block.DescendantBlocks)+1)
 	all[0] = &AccountBlock{
@@ -66,26 +171,42 @@ type Server struct {
 	uninstallCh   chan *Subscription // remove subscription
 	acCh          chan []*AccountBlock
 	mCh           chan *Momentum
+	rCh           chan *chain.RollbackEvent
+	sCh           chan []*SporkEvent
+	lCh           chan []*Log
+	cCh           chan []*ContractCall
+	fCh           chan *chain.AccountChainConflict // account-chain forks
 	stopped       chan struct{}
 	subscriptions map[SubscriptionType]map[rpc.ID]*Subscription
+	// number of installed subscriptions by type, read when a momentum is inserted to skip the events nobody
+	// subscribed to, subscriptions is only accessed by the event loop
+	installed [LastSubscriptionType]int32
+
+	// activated sporks at the last inserted momentum, nil until the first momentum after a start or rollback
+	sporks map[types.Hash]bool
 
 	wg sync.WaitGroup
 }
 
-func GetSubscribeServer(chain chain.Chain) *Server {
+func GetSubscribeServer(ch chain.Chain) *Server {
 	oneSingleton.Lock()
 	defer oneSingleton.Unlock()
 
 	if singleton == nil {
 		singleton = &Server{
 			Api: &Api{
-				chain:     chain,
+				chain:     ch,
 				log:       common.RPCLogger.New("module", "subscribe_api"),
 				installCh: make(chan *Subscription, installSize),
 			},
 
 			acCh:          make(chan []*AccountBlock, acChanSize),
 			mCh:           make(chan *Momentum, mChanSize),
+			rCh:           make(chan *chain.RollbackEvent, rChanSize),
+			sCh:           make(chan []*SporkEvent, sChanSize),
+			lCh:           make(chan []*Log, lChanSize),
+			cCh:           make(chan []*ContractCall, cChanSize),
+			fCh:           make(chan *chain.AccountChainConflict, fChanSize),
 			uninstallCh:   make(chan *Subscription, uninstallSize),
 			stopped:       make(chan struct{}),
 			subscriptions: make(map[SubscriptionType]map[rpc.ID]*Subscription),
@@ -157,10 +278,160 @@ func (s *Server) InsertMomentum(detailed *nom.DetailedMomentum) {
 	default:
 		s.log.Error("can't insert account-blocks for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
 	}
+
+	if logs := s.logs(detailed); len(logs) != 0 {
+		select {
+		case s.lCh <- logs:
+		default:
+			s.log.Error("can't insert logs for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+		}
+	}
+
+	// the send-blocks are read and their data decoded for every contract receive-block
+	if s.subscribed(ContractCallsSubscription) {
+		if calls := s.contractCalls(detailed); len(calls) != 0 {
+			select {
+			case s.cCh <- calls:
+			default:
+				s.log.Error("can't insert contract calls for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+			}
+		}
+	}
+
+	if sEvents := s.sporkEvents(detailed.Momentum); len(sEvents) != 0 {
+		select {
+		case s.sCh <- sEvents:
+		default:
+			s.log.Error("can't insert spork events for broadcast", "reason", "channel is full", "momentum-identifier", detailed.Momentum.Identifier())
+		}
+	}
 	return
 }
+
+// sporkEvents compares the sporks at the momentum with the ones at the previous momentum.
+// The first momentum only records the activated sporks since there is nothing to compare against.
+func (s *Server) sporkEvents(momentum *nom.Momentum) []*SporkEvent {
+	sporks, err := s.chain.GetMomentumStore(momentum.Identifier()).GetAllDefinedSporks()
+	if err != nil {
+		s.log.Error("failed to get sporks", "reason", err, "momentum-identifier", momentum.Identifier())
+		return nil
+	}
+
+	previous := s.sporks
+	s.sporks = make(map[types.Hash]bool, len(sporks))
+	events := make([]*SporkEvent, 0)
+	for _, spork := range sporks {
+		if !spork.Activated {
+			continue
+		}
+		s.sporks[spork.Id] = true
+		newEvent := func(event string) *SporkEvent {
+			return &SporkEvent{
+				Event:             event,
+				Id:                spork.Id,
+				Name:              spork.Name,
+				EnforcementHeight: spork.EnforcementHeight,
+				Momentum: &Momentum{
+					Hash:   momentum.Hash,
+					Height: momentum.Height,
+				},
+			}
+		}
+		if previous != nil && !previous[spork.Id] {
+			events = append(events, newEvent(SporkEventActivated))
+		}
+		if spork.EnforcementHeight == momentum.Height {
+			events = append(events, newEvent(SporkEventEnforced))
+		}
+	}
+	return events
+}
+
+// logs returns the logs of the contract receive-blocks confirmed by the momentum.
+func (s *Server) logs(detailed *nom.DetailedMomentum) []*Log {
+	momentum := &Momentum{
+		Hash:   detailed.Momentum.Hash,
+		Height: detailed.Momentum.Height,
+	}
+	all := make([]*Log, 0)
+	for _, block := range detailed.AccountBlocks {
+		if block.BlockType != nom.BlockTypeContractReceive {
+			continue
+		}
+		logs, err := s.chain.GetAccountBlockLogs(block.Hash)
+		if err != nil {
+			s.log.Error("failed to get logs", "reason", err, "block-hash", block.Hash)
+			continue
+		}
+		for index, log := range logs {
+			all = append(all, &Log{
+				Address:   log.Address,
+				Topics:    log.Topics,
+				Data:      log.Data,
+				BlockHash: block.Hash,
+				LogIndex:  uint32(index),
+				Momentum:  momentum,
+			})
+		}
+	}
+	return all
+}
+
+// contractCalls returns the calls of the contract receive-blocks confirmed by the momentum.
+func (s *Server) contractCalls(detailed *nom.DetailedMomentum) []*ContractCall {
+	momentum := &Momentum{
+		Hash:   detailed.Momentum.Hash,
+		Height: detailed.Momentum.Height,
+	}
+	momentumStore := s.chain.GetMomentumStore(detailed.Momentum.Identifier())
+	if momentumStore == nil {
+		return nil
+	}
+	all := make([]*ContractCall, 0)
+	for _, block := range detailed.AccountBlocks {
+		if block.BlockType != nom.BlockTypeContractReceive {
+			continue
+		}
+		send, err := momentumStore.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil || send == nil {
+			s.log.Error("failed to get send-block", "reason", err, "block-hash", block.Hash)
+			continue
+		}
+		call := &ContractCall{
+			Contract:         block.Address,
+			ReceiveBlockHash: block.Hash,
+			SendBlockHash:    send.Hash,
+			Sender:           send.Address,
+			TokenStandard:    send.TokenStandard,
+			Amount:           send.Amount.String(),
+			Inputs:           []*embeddedabi.Argument{},
+			Momentum:         momentum,
+		}
+		if decoded, err := embeddedabi.Decode(block.Address, send.Data); err == nil {
+			call.Method = decoded.Method
+			call.Inputs = decoded.Inputs
+		}
+		all = append(all, call)
+	}
+	return all
+}
 func (s *Server) DeleteMomentum(*nom.DetailedMomentum) {
 }
+func (s *Server) Rollback(event *chain.RollbackEvent) {
+	s.sporks = nil
+	select {
+	case s.rCh <- event:
+	default:
+		s.log.Error("can't insert rollback for broadcast", "reason", "channel is full", "common-ancestor", event.CommonAncestor)
+	}
+}
+func (s *Server) AccountChainConflict(conflict *chain.AccountChainConflict) {
+	select {
+	case s.fCh <- conflict:
+	default:
+		s.log.Error("can't insert account-chain conflict for broadcast", "reason", "channel is full", "address", conflict.Address, "height", conflict.Height)
+	}
+}
 
 func (s *Server) work() {
 	log := s.log.New("module", "worker")
@@ -177,10 +448,20 @@ func (s *Server) work() {
 			s.install(sub)
 		case sub := <-s.uninstallCh:
 			s.uninstall(sub)
+		case event := <-s.rCh:
+			s.broadcastRollback(event)
 		case momentums := <-s.mCh:
 			s.broadcastMomentums(momentums)
 		case blocks := <-s.acCh:
 			s.broadcastBlocks(blocks)
+		case events := <-s.sCh:
+			s.broadcastSporks(events)
+		case logs := <-s.lCh:
+			s.broadcastLogs(logs)
+		case calls := <-s.cCh:
+			s.broadcastContractCalls(calls)
+		case conflict := <-s.fCh:
+			s.broadcastAccountChainConflict(conflict)
 		}
 	}
 }
@@ -192,11 +473,24 @@ type BroadcastStats struct {
 
 func (s *Server) install(subscription *Subscription) {
 	s.log.Info("install", "id", subscription.rpc.ID)
-	s.subscriptions[subscription.options.subscriptionType][subscription.rpc.ID] = subscription
+	subscriptionType := subscription.options.subscriptionType
+	if _, ok := s.subscriptions[subscriptionType][subscription.rpc.ID]; !ok {
+		atomic.AddInt32(&s.installed[subscriptionType], 1)
+	}
+	s.subscriptions[subscriptionType][subscription.rpc.ID] = subscription
 }
 func (s *Server) uninstall(subscription *Subscription) {
 	s.log.Info("uninstall", "id", subscription.rpc.ID)
-	delete(s.subscriptions[subscription.options.subscriptionType], subscription.rpc.ID)
+	subscriptionType := subscription.options.subscriptionType
+	if _, ok := s.subscriptions[subscriptionType][subscription.rpc.ID]; ok {
+		atomic.AddInt32(&s.installed[subscriptionType], -1)
+	}
+	delete(s.subscriptions[subscriptionType], subscription.rpc.ID)
+}
+
+// subscribed returns whether there is any subscription of the type, it's safe to call outside the event loop.
+func (s *Server) subscribed(subscriptionType SubscriptionType) bool {
+	return atomic.LoadInt32(&s.installed[subscriptionType]) > 0
 }
 func (s *Server) broadcast(subscription *Subscription, data interface{}, stats *BroadcastStats) {
 	if subscription.Closed() {
@@ -217,9 +511,119 @@ func (s *Server) broadcastMomentums(momentum *Momentum) {
 	for _, f := range s.subscriptions[MomentumsSubscription] {
 		s.broadcast(f, []interface{}{momentum}, stats)
 	}
+	s.broadcastFusionExpirations(momentum, stats)
 
 	s.log.Info("finish broadcasting momentum", "identifier", momentum, "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
 }
+
+// broadcastFusionExpirations notifies the fusion entries which can be revoked within the window of each subscription.
+// The entries are read once per owner from the plasma contract at the momentum.
+func (s *Server) broadcastFusionExpirations(momentum *Momentum, stats *BroadcastStats) {
+	if len(s.subscriptions[FusionExpirationsSubscription]) == 0 {
+		return
+	}
+	momentumStore := s.chain.GetMomentumStore(types.HashHeight{Hash: momentum.Hash, Height: momentum.Height})
+	if momentumStore == nil {
+		return
+	}
+	storage := momentumStore.GetAccountStore(types.PlasmaContract).Storage()
+
+	byOwner := make(map[types.Address][]*definition.FusionInfo)
+	for _, f := range s.subscriptions[FusionExpirationsSubscription] {
+		owner := f.options.address
+		fusions, ok := byOwner[owner]
+		if !ok {
+			var err error
+			fusions, _, err = definition.GetFusionInfoListByOwner(storage, owner)
+			if err != nil {
+				s.log.Error("failed to get fusion entries", "reason", err, "owner", owner)
+				continue
+			}
+			byOwner[owner] = fusions
+		}
+
+		expiring := make([]*FusionExpiration, 0)
+		for _, fusion := range fusions {
+			if fusion.ExpirationHeight == momentum.Height+f.options.expirationWindow {
+				expiring = append(expiring, &FusionExpiration{
+					Owner:            owner,
+					Id:               fusion.Id,
+					Beneficiary:      fusion.Beneficiary,
+					QsrAmount:        fusion.Amount.String(),
+					ExpirationHeight: fusion.ExpirationHeight,
+					Momentum:         momentum,
+				})
+			}
+		}
+		if len(expiring) != 0 {
+			s.broadcast(f, expiring, stats)
+		}
+	}
+}
+func (s *Server) broadcastRollback(event *chain.RollbackEvent) {
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	for _, f := range s.subscriptions[RollbacksSubscription] {
+		s.broadcast(f, []interface{}{event}, stats)
+	}
+
+	s.log.Info("finish broadcasting rollback", "event", event, "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+func (s *Server) broadcastAccountChainConflict(conflict *chain.AccountChainConflict) {
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	for _, f := range s.subscriptions[AccountChainConflictsSubscription] {
+		s.broadcast(f, []interface{}{conflict}, stats)
+	}
+
+	s.log.Info("finish broadcasting account-chain conflict", "address", conflict.Address, "height", conflict.Height, "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+func (s *Server) broadcastSporks(events []*SporkEvent) {
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	for _, f := range s.subscriptions[SporksSubscription] {
+		s.broadcast(f, events, stats)
+	}
+
+	s.log.Info("finish broadcasting spork events", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+func (s *Server) broadcastLogs(logs []*Log) {
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	for _, f := range s.subscriptions[LogsSubscription] {
+		matching := make([]*Log, 0, len(logs))
+		for _, log := range logs {
+			if f.options.logFilter.matches(log) {
+				matching = append(matching, log)
+			}
+		}
+		if len(matching) != 0 {
+			s.broadcast(f, matching, stats)
+		}
+	}
+
+	s.log.Info("finish broadcasting logs", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
+func (s *Server) broadcastContractCalls(calls []*ContractCall) {
+	startTime := common.Clock.Now()
+	stats := &BroadcastStats{}
+
+	byContract := make(map[types.Address][]*ContractCall)
+	for _, call := range calls {
+		byContract[call.Contract] = append(byContract[call.Contract], call)
+	}
+	for _, f := range s.subscriptions[ContractCallsSubscription] {
+		if calls, ok := byContract[f.options.address]; ok {
+			s.broadcast(f, calls, stats)
+		}
+	}
+
+	s.log.Info("finish broadcasting contract calls", "elapsed", common.Clock.Now().Sub(startTime), "stats", stats)
+}
 func (s *Server) broadcastBlocks(blocks []*AccountBlock) {
 	if len(blocks) == 0 {
 		return
@@ -285,3 +689,47 @@ func (s *Api) UnreceivedAccountBlocksByAddress(ctx context.Context, address type
 	s.log.Info("new subscription", "type", "UnreceivedAccountBlocksByAddress")
 	return s.subscribe(ctx, NewToUnreceivedBlocksSubscription(address))
 }
+func (s *Api) Rollbacks(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "Rollbacks")
+	return s.subscribe(ctx, NewRollbacksSubscription())
+}
+
+// AccountChainConflicts notifies the account-blocks seen by the node at the height of another block of their
+// account-chain, see debug.getAccountChainConflicts.
+func (s *Api) AccountChainConflicts(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "AccountChainConflicts")
+	return s.subscribe(ctx, NewAccountChainConflictsSubscription())
+}
+
+// Logs notifies the logs emitted by embedded contracts which match the filter, once their receive-block is confirmed.
+func (s *Api) Logs(ctx context.Context, filter *LogFilter) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "Logs")
+	return s.subscribe(ctx, NewLogsSubscription(filter))
+}
+
+// ContractCalls notifies the calls received by an embedded contract with the decoded method and arguments,
+// once their receive-block is confirmed.
+func (s *Api) ContractCalls(ctx context.Context, contract types.Address) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "ContractCalls")
+	if _, ok := embeddedabi.ABIs[contract]; !ok {
+		return nil, embeddedabi.ErrNotEmbeddedContract
+	}
+	return s.subscribe(ctx, NewContractCallsSubscription(contract))
+}
+
+// FusionExpirations notifies the fusion entries of owner once they can be revoked within withinMomentums momentums,
+// so wallets can prompt to reuse the fused QSR. Zero notifies them once they can be revoked. The window can't be
+// longer than the fusion duration, constants.FuseExpiration.
+func (s *Api) FusionExpirations(ctx context.Context, owner types.Address, withinMomentums uint64) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "FusionExpirations")
+	if withinMomentums > constants.FuseExpiration {
+		return nil, ErrExpirationWindowTooBig
+	}
+	return s.subscribe(ctx, NewFusionExpirationsSubscription(owner, withinMomentums))
+}
+
+// Sporks notifies when a spork is activated and when it's enforced.
+func (s *Api) Sporks(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("new subscription", "type", "Sporks")
+	return s.subscribe(ctx, NewSporksSubscription())
+}
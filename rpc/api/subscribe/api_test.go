@@ -0,0 +1,150 @@
+package subscribe
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/common/types"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// testChain serves the send-blocks of the contract calls from a single momentum store.
+type testChain struct {
+	chain.Chain
+	store *testMomentumStore
+}
+
+func (c *testChain) Register(chain.MomentumEventListener)   {}
+func (c *testChain) UnRegister(chain.MomentumEventListener) {}
+func (c *testChain) GetMomentumStore(types.HashHeight) store.Momentum {
+	return c.store
+}
+func (c *testChain) GetAccountBlockLogs(types.Hash) ([]*nom.Log, error) {
+	return nil, nil
+}
+
+type testMomentumStore struct {
+	store.Momentum
+
+	lock   sync.Mutex
+	blocks map[types.Hash]*nom.AccountBlock
+	reads  int
+}
+
+func (s *testMomentumStore) GetAccountBlockByHash(hash types.Hash) (*nom.AccountBlock, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.reads += 1
+	return s.blocks[hash], nil
+}
+func (s *testMomentumStore) GetAllDefinedSporks() ([]*definition.Spork, error) {
+	return nil, nil
+}
+func (s *testMomentumStore) blockReads() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.reads
+}
+
+// newTestServer starts a subscribe server and returns a client of its API.
+func newTestServer(t *testing.T, momentumStore *testMomentumStore) (*Server, *rpc.Client) {
+	server := GetSubscribeServer(&testChain{store: momentumStore})
+	if err := server.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Stop() })
+
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("ledger", server.Api); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(rpcServer.Stop)
+	client := rpc.DialInProc(rpcServer)
+	t.Cleanup(client.Close)
+	return server, client
+}
+
+// contractCallMomentum returns a momentum confirming the receive-block of a call of the pillar contract,
+// and adds its send-block to the store.
+func contractCallMomentum(momentumStore *testMomentumStore) *nom.DetailedMomentum {
+	send := &nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       types.ParseAddressPanic("z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz"),
+		ToAddress:     types.PillarContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(0),
+		Data:          definition.ABICommon.PackMethodPanic(definition.CollectRewardMethodName),
+	}
+	send.Hash = send.ComputeHash()
+	receive := &nom.AccountBlock{
+		BlockType:     nom.BlockTypeContractReceive,
+		Address:       types.PillarContract,
+		FromBlockHash: send.Hash,
+	}
+	receive.Hash = receive.ComputeHash()
+	momentumStore.blocks = map[types.Hash]*nom.AccountBlock{send.Hash: send}
+
+	momentum := &nom.Momentum{Height: 2, Content: nom.NewMomentumContent([]*nom.AccountBlock{receive})}
+	momentum.Hash = momentum.ComputeHash()
+	return &nom.DetailedMomentum{Momentum: momentum, AccountBlocks: []*nom.AccountBlock{receive}}
+}
+
+// Test contract calls
+//   - test the send-blocks aren't read while nobody subscribed to the contract calls
+//   - test the calls of the contract are sent with the decoded method once subscribed
+func TestServer_ContractCalls(t *testing.T) {
+	momentumStore := &testMomentumStore{}
+	server, client := newTestServer(t, momentumStore)
+	detailed := contractCallMomentum(momentumStore)
+
+	server.InsertMomentum(detailed)
+	if reads := momentumStore.blockReads(); reads != 0 {
+		t.Fatalf("read %v send-blocks without subscriptions", reads)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	calls := make(chan []*ContractCall, 1)
+	sub, err := client.Subscribe(ctx, "ledger", calls, "contractCalls", types.PillarContract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	// the subscription is installed by the event loop
+	for !server.subscribed(ContractCallsSubscription) {
+		select {
+		case <-ctx.Done():
+			t.Fatal("subscription wasn't installed")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	server.InsertMomentum(detailed)
+	select {
+	case received := <-calls:
+		if len(received) != 1 {
+			t.Fatalf("received %v calls, expected 1", len(received))
+		}
+		call := received[0]
+		if call.Contract != types.PillarContract || call.ReceiveBlockHash != detailed.AccountBlocks[0].Hash || call.Method != definition.CollectRewardMethodName {
+			t.Fatalf("unexpected call %+v", call)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("contract call wasn't sent")
+	}
+	if reads := momentumStore.blockReads(); reads != 1 {
+		t.Fatalf("read %v send-blocks, expected 1", reads)
+	}
+}
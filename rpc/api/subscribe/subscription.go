@@ -18,6 +18,12 @@ const (
 	AccountBlocksSubscriptionByAddress
 	UnreceivedAccountBlocksSubscriptionByAddress
 	MomentumsSubscription
+	RollbacksSubscription
+	SporksSubscription
+	LogsSubscription
+	ContractCallsSubscription
+	FusionExpirationsSubscription
+	AccountChainConflictsSubscription
 	LastSubscriptionType
 )
 
@@ -25,6 +31,8 @@ type subscriptionOptions struct {
 	subscriptionType SubscriptionType
 	createTime       time.Time
 	address          types.Address
+	logFilter        *LogFilter
+	expirationWindow uint64
 }
 
 func newSubscription(subscriptionType SubscriptionType) *subscriptionOptions {
@@ -49,6 +57,32 @@ func NewToUnreceivedBlocksSubscription(addr types.Address) *subscriptionOptions
 func NewMomentumsSubscription() *subscriptionOptions {
 	return newSubscription(MomentumsSubscription)
 }
+func NewRollbacksSubscription() *subscriptionOptions {
+	return newSubscription(RollbacksSubscription)
+}
+func NewAccountChainConflictsSubscription() *subscriptionOptions {
+	return newSubscription(AccountChainConflictsSubscription)
+}
+func NewSporksSubscription() *subscriptionOptions {
+	return newSubscription(SporksSubscription)
+}
+func NewLogsSubscription(filter *LogFilter) *subscriptionOptions {
+	sub := newSubscription(LogsSubscription)
+	sub.logFilter = filter
+	return sub
+}
+
+func NewContractCallsSubscription(contract types.Address) *subscriptionOptions {
+	sub := newSubscription(ContractCallsSubscription)
+	sub.address = contract
+	return sub
+}
+func NewFusionExpirationsSubscription(owner types.Address, withinMomentums uint64) *subscriptionOptions {
+	sub := newSubscription(FusionExpirationsSubscription)
+	sub.address = owner
+	sub.expirationWindow = withinMomentums
+	return sub
+}
 
 type Subscription struct {
 	log      log15.Logger
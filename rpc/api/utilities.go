@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
+	"github.com/zenon-network/go-zenon/vm/abi"
+	"github.com/zenon-network/go-zenon/vm/constants"
+)
+
+type UtilitiesApi struct {
+	// pool is nil if the PoW generation is disabled, the other utilities are always served
+	pool *pow.Pool
+	log  log15.Logger
+}
+
+func NewUtilitiesApi(pool *pow.Pool) *UtilitiesApi {
+	return &UtilitiesApi{
+		pool: pool,
+		log:  common.RPCLogger.New("module", "utilities_api"),
+	}
+}
+
+// GeneratePoW computes the nonce for the data hash of an account-block, the hash of its address and previous hash.
+// The job is cancelled if the client goes away before it's done.
+func (api *UtilitiesApi) GeneratePoW(ctx context.Context, dataHash types.Hash, difficulty uint64) (*nom.Nonce, error) {
+	if api.pool == nil {
+		return nil, ErrPoWDisabled
+	}
+	if difficulty == 0 {
+		return nil, ErrDifficultyIsZero
+	}
+	if difficulty > constants.MaxDifficultyForAccountBlock {
+		return nil, ErrDifficultyTooBig
+	}
+
+	nonce, err := api.pool.Generate(ctx, dataHash, difficulty)
+	if err != nil {
+		api.log.Debug("failed to generate PoW", "data-hash", dataHash, "difficulty", difficulty, "reason", err)
+		return nil, err
+	}
+	return nonce, nil
+}
+
+func (api *UtilitiesApi) GetPoWStats() (*pow.PoolStats, error) {
+	if api.pool == nil {
+		return nil, ErrPoWDisabled
+	}
+	return api.pool.Stats(), nil
+}
+
+// AbiArgument is a decoded argument of an embedded method, see embeddedabi.Argument.
+type AbiArgument = embeddedabi.Argument
+type AbiCall = embeddedabi.Call
+
+// AbiEncode packs the call of an embedded method into the data of an account-block. Arguments are
+// given in the format returned by AbiDecode, numbers and booleans may also be JSON values.
+func (api *UtilitiesApi) AbiEncode(contract types.Address, methodName string, args []interface{}) ([]byte, error) {
+	method, err := embeddedabi.Method(contract, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != len(method.Inputs) {
+		return nil, errors.Errorf("method %v expects %v arguments, got %v", method.Name, len(method.Inputs), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		input := method.Inputs[i]
+		if values[i], err = parseAbiArgument(input.Type, arg); err != nil {
+			return nil, errors.Errorf("invalid argument %v of type %v: %v", input.Name, input.Type, err)
+		}
+	}
+	packed, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.Id(), packed...), nil
+}
+
+// AbiDecode unpacks the data of an account-block sent to an embedded contract.
+func (api *UtilitiesApi) AbiDecode(contract types.Address, data []byte) (*AbiCall, error) {
+	return embeddedabi.Decode(contract, data)
+}
+
+func parseAbiArgument(t abi.Type, arg interface{}) (interface{}, error) {
+	if t.T == abi.SliceTy || t.T == abi.ArrayTy {
+		list, ok := arg.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected a list")
+		}
+		var value reflect.Value
+		if t.T == abi.SliceTy {
+			value = reflect.MakeSlice(t.Type, len(list), len(list))
+		} else if len(list) != t.Size {
+			return nil, errors.Errorf("expected %v elements, got %v", t.Size, len(list))
+		} else {
+			value = reflect.New(t.Type).Elem()
+		}
+		for i, elem := range list {
+			parsed, err := parseAbiArgument(*t.Elem, elem)
+			if err != nil {
+				return nil, err
+			}
+			value.Index(i).Set(reflect.ValueOf(parsed))
+		}
+		return value.Interface(), nil
+	}
+
+	var str string
+	switch v := arg.(type) {
+	case string:
+		str = v
+	case bool:
+		str = strconv.FormatBool(v)
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, errors.Errorf("expected a string")
+	}
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(str, 10)
+		if !ok {
+			return nil, errors.Errorf("%q is not a number", str)
+		}
+		if t.T == abi.UintTy && n.Sign() < 0 {
+			return nil, errors.Errorf("%v is negative", n)
+		}
+		if t.Kind == reflect.Ptr {
+			if n.BitLen() > t.Size {
+				return nil, errors.Errorf("%v overflows", n)
+			}
+			return n, nil
+		}
+		value := reflect.New(t.Type).Elem()
+		if t.T == abi.UintTy {
+			if !n.IsUint64() || value.OverflowUint(n.Uint64()) {
+				return nil, errors.Errorf("%v overflows", n)
+			}
+			value.SetUint(n.Uint64())
+		} else {
+			if !n.IsInt64() || value.OverflowInt(n.Int64()) {
+				return nil, errors.Errorf("%v overflows", n)
+			}
+			value.SetInt(n.Int64())
+		}
+		return value.Interface(), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(str)
+	case abi.StringTy:
+		return str, nil
+	case abi.AddressTy:
+		return types.ParseAddress(str)
+	case abi.TokenStandardTy:
+		return types.ParseZTS(str)
+	case abi.HashTy:
+		return types.HexToHash(str)
+	case abi.BytesTy:
+		return hex.DecodeString(str)
+	case abi.FixedBytesTy:
+		data, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != t.Size {
+			return nil, errors.Errorf("expected %v bytes, got %v", t.Size, len(data))
+		}
+		value := reflect.New(t.Type).Elem()
+		reflect.Copy(value, reflect.ValueOf(data))
+		return value.Interface(), nil
+	default:
+		return nil, errors.Errorf("unsupported type")
+	}
+}
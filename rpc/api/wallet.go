@@ -0,0 +1,236 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/zenon"
+)
+
+const (
+	walletDefaultUnlockDuration = 300 // seconds
+	walletMaxAddressCount       = 128
+)
+
+// WalletApi signs account-blocks with the key stores of the node wallet directory.
+// It must only be served on local endpoints.
+type WalletApi struct {
+	z       zenon.Zenon
+	manager *wallet.Manager
+	log     log15.Logger
+
+	// protects manager and timers
+	lock   sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func NewWalletApi(z zenon.Zenon, manager *wallet.Manager) *WalletApi {
+	return &WalletApi{
+		z:       z,
+		manager: manager,
+		log:     common.RPCLogger.New("module", "wallet_api"),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+type WalletKeyStore struct {
+	Path        string        `json:"path"`
+	BaseAddress types.Address `json:"baseAddress"`
+	Unlocked    bool          `json:"unlocked"`
+	// NeedsUpgrade is true if the key store is encrypted with weaker argon2 parameters than the defaults
+	// or has an older key file version
+	NeedsUpgrade bool `json:"needsUpgrade"`
+}
+
+func (a *WalletApi) GetKeyStores() ([]*WalletKeyStore, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	keyFiles, err := a.manager.ListEntropyFilesInStandardDir()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*WalletKeyStore, 0, len(keyFiles))
+	for _, keyFile := range keyFiles {
+		unlocked, _ := a.manager.IsUnlocked(keyFile.Path)
+		result = append(result, &WalletKeyStore{
+			Path:         keyFile.Path,
+			BaseAddress:  keyFile.BaseAddress,
+			Unlocked:     unlocked,
+			NeedsUpgrade: keyFile.NeedsUpgrade(),
+		})
+	}
+	return result, nil
+}
+
+// Unlock decrypts the key store for duration seconds, or for walletDefaultUnlockDuration if 0.
+func (a *WalletApi) Unlock(path, password string, duration uint64) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	path = a.manager.MakePathAbsolut(path)
+	if err := a.manager.Unlock(path, password); err != nil {
+		return err
+	}
+	if duration == 0 {
+		duration = walletDefaultUnlockDuration
+	}
+	if timer, ok := a.timers[path]; ok {
+		timer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Duration(duration)*time.Second, func() {
+		a.lock.Lock()
+		defer a.lock.Unlock()
+		// the timer may have fired while an Unlock replaced it, which extends the duration
+		if a.timers[path] != timer {
+			return
+		}
+		a.log.Info("unlock duration expired", "path", path)
+		a.lockKeyStore(path)
+	})
+	a.timers[path] = timer
+	return nil
+}
+func (a *WalletApi) Lock(path string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.lockKeyStore(a.manager.MakePathAbsolut(path))
+}
+func (a *WalletApi) lockKeyStore(path string) {
+	if timer, ok := a.timers[path]; ok {
+		timer.Stop()
+		delete(a.timers, path)
+	}
+	a.manager.Lock(path)
+}
+
+// ChangePassword re-encrypts the key store with newPassword, an unlocked key store stays unlocked.
+func (a *WalletApi) ChangePassword(path, password, newPassword string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.log.Info("ChangePassword", "path", path)
+	return a.manager.ChangePassword(path, password, newPassword)
+}
+
+// Upgrade re-encrypts the key store with the default argon2 parameters if it uses weaker ones.
+func (a *WalletApi) Upgrade(path, password string) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.log.Info("Upgrade", "path", path)
+	return a.manager.Upgrade(path, password)
+}
+
+// UpgradeAll upgrades all the key stores of the wallet directory which can be decrypted with password.
+func (a *WalletApi) UpgradeAll(password string) ([]*wallet.UpgradeResult, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.log.Info("UpgradeAll")
+	return a.manager.UpgradeAll(password)
+}
+
+// GetAddresses returns the first count addresses of an unlocked key store.
+func (a *WalletApi) GetAddresses(path string, count uint32) ([]types.Address, error) {
+	if count > walletMaxAddressCount {
+		return nil, ErrCountParamTooBig
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	keyStore, err := a.manager.GetKeyStore(path)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]types.Address, 0, count)
+	for index := uint32(0); index < count; index += 1 {
+		_, keyPair, err := keyStore.DeriveForIndexPath(index)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, keyPair.Address)
+	}
+	return addresses, nil
+}
+
+// SignAccountBlock fills in the missing fields of the template and signs it
+// without publishing it. The template must use fused plasma since no PoW is computed.
+func (a *WalletApi) SignAccountBlock(path string, template *nom.AccountBlock) (*nom.AccountBlock, error) {
+	transaction, err := a.generate(path, template)
+	if err != nil {
+		return nil, err
+	}
+	return transaction.Block, nil
+}
+
+// PublishAccountBlock signs the template like SignAccountBlock and broadcasts it.
+func (a *WalletApi) PublishAccountBlock(path string, template *nom.AccountBlock) (*nom.AccountBlock, error) {
+	transaction, err := a.generate(path, template)
+	if err != nil {
+		return nil, err
+	}
+	a.z.Broadcaster().CreateAccountBlock(transaction)
+	return transaction.Block, nil
+}
+
+func (a *WalletApi) generate(path string, template *nom.AccountBlock) (*nom.AccountBlockTransaction, error) {
+	defer common.RecoverStack()
+	if template == nil {
+		return nil, ErrParamIsNull
+	}
+	if template.ChainIdentifier != 0 && template.ChainIdentifier != a.z.Chain().ChainIdentifier() {
+		return nil, errors.Errorf("the block has a different network Id (%d) from the node (%d)", template.ChainIdentifier, a.z.Chain().ChainIdentifier())
+	}
+	if err := checkTokenIdValid(a.z.Chain(), &template.TokenStandard); err != nil {
+		return nil, err
+	}
+
+	a.lock.Lock()
+	keyStore, err := a.manager.GetKeyStore(path)
+	var keyPair *wallet.KeyPair
+	if err == nil {
+		keyPair, _, err = keyStore.FindAddress(template.Address)
+	}
+	a.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	supervisor := vm.NewSupervisor(a.z.Chain(), a.z.Consensus())
+	return supervisor.GenerateFromTemplate(template.Copy(), keyPair.Signer)
+}
+
+// findKeyPair returns the key pair of the address from the first unlocked key store which derives it,
+// along with the path of the key store.
+func (a *WalletApi) findKeyPair(address types.Address) (string, *wallet.KeyPair, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for _, path := range a.manager.UnlockedPaths() {
+		keyStore, err := a.manager.GetKeyStore(path)
+		if err != nil {
+			continue
+		}
+		if keyPair, _, err := keyStore.FindAddress(address); err == nil {
+			return path, keyPair, nil
+		}
+	}
+	return "", nil, wallet.ErrAddressNotFound
+}
+func (a *WalletApi) unlockedPaths() []string {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.manager.UnlockedPaths()
+}
+func (a *WalletApi) isUnlocked(path string) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	unlocked, _ := a.manager.IsUnlocked(path)
+	return unlocked
+}
@@ -2,10 +2,13 @@ package rpc
 
 import (
 	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
 	"github.com/zenon-network/go-zenon/rpc/api/subscribe"
 	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/wallet"
 	"github.com/zenon-network/go-zenon/zenon"
 )
 
@@ -97,6 +100,24 @@ func getApi(z zenon.Zenon, p2p *p2p.Server, apiModule string) []rpc.API {
 				Service:   embedded.NewLiquidityApi(z),
 				Public:    true,
 			},
+			{
+				Namespace: "embedded",
+				Version:   "1.0",
+				Service:   embedded.NewSimulateApi(z),
+				Public:    true,
+			},
+			{
+				Namespace: "embedded",
+				Version:   "1.0",
+				Service:   embedded.NewGovernanceApi(z),
+				Public:    true,
+			},
+			{
+				Namespace: "embedded",
+				Version:   "1.0",
+				Service:   embedded.NewStorageApi(z),
+				Public:    true,
+			},
 		}
 	case "stats":
 		return []rpc.API{
@@ -107,6 +128,54 @@ func getApi(z zenon.Zenon, p2p *p2p.Server, apiModule string) []rpc.API {
 				Public:    true,
 			},
 		}
+	case "producer":
+		return []rpc.API{
+			{
+				Namespace: "producer",
+				Version:   "1.0",
+				Service:   api.NewProducerApi(z),
+				Public:    true,
+			},
+		}
+	case "net":
+		return []rpc.API{
+			{
+				Namespace: "net",
+				Version:   "1.0",
+				Service:   api.NewNetApi(p2p),
+				Public:    true,
+			},
+		}
+	case "admin":
+		return []rpc.API{
+			{
+				Namespace: "admin",
+				Version:   "1.0",
+				Service:   api.NewAdminApi(p2p),
+				Public:    false,
+			},
+		}
+	case "debug":
+		return []rpc.API{
+			{
+				Namespace: "debug",
+				Version:   "1.0",
+				Service:   api.NewDebugApi(z),
+				Public:    false,
+			},
+		}
+	case "indexer":
+		if z.Indexer() == nil {
+			return []rpc.API{}
+		}
+		return []rpc.API{
+			{
+				Namespace: "indexer",
+				Version:   "1.0",
+				Service:   api.NewIndexerApi(z),
+				Public:    true,
+			},
+		}
 	default:
 		return []rpc.API{}
 	}
@@ -119,5 +188,73 @@ func GetApis(z zenon.Zenon, p2p *p2p.Server, apiModule ...string) []rpc.API {
 	return apis
 }
 func GetPublicApis(z zenon.Zenon, p2p *p2p.Server) []rpc.API {
-	return GetApis(z, p2p, "ledger", "ledgerSubscribe", "embedded", "stats")
+	return GetApis(z, p2p, "ledger", "ledgerSubscribe", "embedded", "stats", "producer", "net", "indexer", "admin", "debug")
+}
+
+// GetLightApis returns the apis served by a light node.
+func GetLightApis(client *protocol.LightClient, p2p *p2p.Server) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "ledger",
+			Version:   "1.0",
+			Service:   api.NewLightLedgerApi(client),
+			Public:    true,
+		},
+		{
+			Namespace: "stats",
+			Version:   "1.0",
+			Service:   api.NewLightStatsApi(client),
+			Public:    true,
+		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   api.NewAdminApi(p2p),
+			Public:    false,
+		},
+	}
+}
+
+// GetWalletApis returns the apis which sign with the key stores of the node.
+// They must only be served on local endpoints.
+func GetWalletApis(z zenon.Zenon, manager *wallet.Manager) []rpc.API {
+	walletApi := api.NewWalletApi(z, manager)
+	return []rpc.API{
+		{
+			Namespace: "wallet",
+			Version:   "1.0",
+			Service:   walletApi,
+			Public:    true,
+		},
+		{
+			Namespace: "embedded.spork",
+			Version:   "1.0",
+			Service:   embedded.NewSporkAdminApi(z, walletApi),
+			Public:    true,
+		},
+	}
+}
+
+// NewAutoReceiver returns the auto-receiver which signs with the key stores unlocked through the wallet
+// namespace of walletAPIs, the apis returned by GetWalletApis.
+func NewAutoReceiver(z zenon.Zenon, walletAPIs []rpc.API, pool *pow.Pool, config *api.AutoReceiveConfig) *api.AutoReceiver {
+	for _, walletAPI := range walletAPIs {
+		if walletApi, ok := walletAPI.Service.(*api.WalletApi); ok {
+			return api.NewAutoReceiver(z, walletApi, pool, config)
+		}
+	}
+	return nil
+}
+
+// GetUtilitiesApis returns the utilities apis, the PoW is computed with the CPU of the node by pool
+// and disabled if nil.
+func GetUtilitiesApis(pool *pow.Pool) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "utilities",
+			Version:   "1.0",
+			Service:   api.NewUtilitiesApi(pool),
+			Public:    true,
+		},
+	}
 }
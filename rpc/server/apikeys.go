@@ -0,0 +1,299 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// apiKeyHeader carries the API key of the HTTP requests and of the WS handshakes. It isn't read from the
+	// query of the URL, which is kept in the logs of the proxies and in the history of the browsers.
+	apiKeyHeader = "X-API-Key"
+	// AnonymousAPIKey is the name the calls without a key are accounted under, see SetAPIKeys.
+	AnonymousAPIKey = "anonymous"
+
+	apiKeyErrorCode    = -32001
+	rateLimitErrorCode = -32005
+)
+
+// apiKeys are the API keys of the clients of all the servers of the process, they are only checked by the
+// servers which called CheckAPIKeys.
+var apiKeys = newAPIKeyRegistry()
+
+var _ Error = new(apiKeyError)
+
+// the call was rejected because of the API key of the client
+type apiKeyError struct {
+	code    int
+	message string
+}
+
+func (e *apiKeyError) ErrorCode() int { return e.code }
+
+func (e *apiKeyError) Error() string { return e.message }
+
+var (
+	errMissingAPIKey = &apiKeyError{apiKeyErrorCode, "missing api key"}
+	errInvalidAPIKey = &apiKeyError{apiKeyErrorCode, "invalid api key"}
+)
+
+type apiKeyContextKey struct{}
+type apiKeyScopeKey struct{}
+
+// APIKey is a key of the clients of the public endpoints. Allowed and Denied are method names or wildcards,
+// see MatchesMethod, all methods are allowed if Allowed is empty. RateLimit is the number of calls per second,
+// zero is unlimited.
+type APIKey struct {
+	Name      string   `json:"name"`
+	Key       string   `json:"key,omitempty"`
+	Allowed   []string `json:"allowed"`
+	Denied    []string `json:"denied"`
+	RateLimit int      `json:"rateLimit"`
+}
+
+// APIKeyUsage are the calls made with a key since it was added, LastUsed is a unix timestamp.
+type APIKeyUsage struct {
+	Name     string            `json:"name"`
+	Calls    uint64            `json:"calls"`
+	Rejected uint64            `json:"rejected"`
+	LastUsed int64             `json:"lastUsed"`
+	Methods  map[string]uint64 `json:"methods"`
+}
+
+type apiKeyEntry struct {
+	key APIKey
+
+	lock     sync.Mutex
+	tokens   float64
+	refilled time.Time
+	usage    APIKeyUsage
+}
+
+func newAPIKeyEntry(key *APIKey) (*apiKeyEntry, error) {
+	if key.Name == "" || key.Key == "" {
+		return nil, fmt.Errorf("api key must have a name and a key")
+	}
+	if key.RateLimit < 0 {
+		return nil, fmt.Errorf("invalid rate limit %v for api key %v", key.RateLimit, key.Name)
+	}
+	return &apiKeyEntry{
+		key:      *key,
+		tokens:   float64(key.RateLimit),
+		refilled: time.Now(),
+		usage:    APIKeyUsage{Name: key.Name, Methods: make(map[string]uint64)},
+	}, nil
+}
+
+// allows reports whether the key can call method now and accounts the call.
+func (e *apiKeyEntry) allows(method string, now time.Time) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if MatchesMethod(method, e.key.Denied) || (len(e.key.Allowed) != 0 && !MatchesMethod(method, e.key.Allowed)) {
+		e.usage.Rejected += 1
+		return &apiKeyError{apiKeyErrorCode, fmt.Sprintf("the method %s is not allowed for the api key", method)}
+	}
+	if e.key.RateLimit != 0 {
+		// a token bucket which holds the calls of one second
+		limit := float64(e.key.RateLimit)
+		e.tokens += now.Sub(e.refilled).Seconds() * limit
+		if e.tokens > limit {
+			e.tokens = limit
+		}
+		e.refilled = now
+		if e.tokens < 1 {
+			e.usage.Rejected += 1
+			return &apiKeyError{rateLimitErrorCode, fmt.Sprintf("rate limit of %v calls per second exceeded", e.key.RateLimit)}
+		}
+		e.tokens -= 1
+	}
+	e.usage.Calls += 1
+	e.usage.Methods[method] += 1
+	e.usage.LastUsed = now.Unix()
+	return nil
+}
+
+func (e *apiKeyEntry) getUsage() *APIKeyUsage {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	usage := e.usage
+	usage.Methods = make(map[string]uint64, len(e.usage.Methods))
+	for method, calls := range e.usage.Methods {
+		usage.Methods[method] = calls
+	}
+	return &usage
+}
+
+type apiKeyRegistry struct {
+	lock sync.RWMutex
+	// keys are indexed by their hash, so looking them up doesn't leak their content through timing
+	keys map[[sha256.Size]byte]*apiKeyEntry
+	// anonymous limits the calls without a key, they are rejected if nil
+	anonymous *apiKeyEntry
+}
+
+func newAPIKeyRegistry() *apiKeyRegistry {
+	return &apiKeyRegistry{keys: make(map[[sha256.Size]byte]*apiKeyEntry)}
+}
+
+// SetAPIKeys replaces the API keys. Once there are keys the calls without one are rejected, unless anonymous
+// is set. They are limited by anonymous then, its Key is ignored and its Name is AnonymousAPIKey.
+func SetAPIKeys(keys []*APIKey, anonymous *APIKey) error {
+	entries := make(map[[sha256.Size]byte]*apiKeyEntry, len(keys))
+	names := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		entry, err := newAPIKeyEntry(key)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256([]byte(key.Key))
+		if _, ok := entries[hash]; ok || names[key.Name] || key.Name == AnonymousAPIKey {
+			return fmt.Errorf("duplicate api key %v", key.Name)
+		}
+		entries[hash] = entry
+		names[key.Name] = true
+	}
+	var anonymousEntry *apiKeyEntry
+	if anonymous != nil {
+		key := *anonymous
+		key.Name, key.Key = AnonymousAPIKey, AnonymousAPIKey
+		entry, err := newAPIKeyEntry(&key)
+		if err != nil {
+			return err
+		}
+		anonymousEntry = entry
+	}
+
+	apiKeys.lock.Lock()
+	defer apiKeys.lock.Unlock()
+	apiKeys.keys = entries
+	apiKeys.anonymous = anonymousEntry
+	return nil
+}
+
+// AddAPIKey adds a key, its name and key must not be used by another one.
+func AddAPIKey(key *APIKey) error {
+	entry, err := newAPIKeyEntry(key)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(key.Key))
+
+	apiKeys.lock.Lock()
+	defer apiKeys.lock.Unlock()
+	if _, ok := apiKeys.keys[hash]; ok || apiKeys.find(key.Name) != nil || key.Name == AnonymousAPIKey {
+		return fmt.Errorf("duplicate api key %v", key.Name)
+	}
+	apiKeys.keys[hash] = entry
+	return nil
+}
+
+// RemoveAPIKey removes the key named name, its usage is lost.
+func RemoveAPIKey(name string) error {
+	apiKeys.lock.Lock()
+	defer apiKeys.lock.Unlock()
+	for hash, entry := range apiKeys.keys {
+		if entry.key.Name == name {
+			delete(apiKeys.keys, hash)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown api key %v", name)
+}
+
+// APIKeys returns the keys sorted by name, without their secret.
+func APIKeys() []*APIKey {
+	apiKeys.lock.RLock()
+	defer apiKeys.lock.RUnlock()
+	result := make([]*APIKey, 0, len(apiKeys.keys))
+	for _, entry := range apiKeys.keys {
+		key := entry.key
+		key.Key = ""
+		result = append(result, &key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// GetAPIKeyUsage returns the usage of the keys sorted by name. The calls served by a server which checks the
+// API keys only see the usage of their own key.
+func GetAPIKeyUsage(ctx context.Context) []*APIKeyUsage {
+	own, scoped := ctx.Value(apiKeyScopeKey{}).(*apiKeyEntry)
+	if scoped {
+		if own == nil {
+			return make([]*APIKeyUsage, 0)
+		}
+		return []*APIKeyUsage{own.getUsage()}
+	}
+
+	apiKeys.lock.RLock()
+	defer apiKeys.lock.RUnlock()
+	result := make([]*APIKeyUsage, 0, len(apiKeys.keys)+1)
+	for _, entry := range apiKeys.keys {
+		result = append(result, entry.getUsage())
+	}
+	if apiKeys.anonymous != nil {
+		result = append(result, apiKeys.anonymous.getUsage())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// find returns the key named name, the caller must hold the lock.
+func (r *apiKeyRegistry) find(name string) *apiKeyEntry {
+	for _, entry := range r.keys {
+		if entry.key.Name == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// authorize checks the API key of a call of method and returns the context of the call, scoped to the key.
+func (r *apiKeyRegistry) authorize(ctx context.Context, method string) (context.Context, error) {
+	key, _ := ctx.Value(apiKeyContextKey{}).(string)
+
+	r.lock.RLock()
+	entry := r.keys[sha256.Sum256([]byte(key))]
+	anonymous := r.anonymous
+	enabled := len(r.keys) != 0
+	r.lock.RUnlock()
+
+	if !enabled {
+		return ctx, nil
+	}
+	if key == "" {
+		if anonymous == nil {
+			return ctx, errMissingAPIKey
+		}
+		if err := anonymous.allows(method, time.Now()); err != nil {
+			return ctx, err
+		}
+		// the anonymous usage is shared by all the clients without a key, it isn't shown to them
+		return context.WithValue(ctx, apiKeyScopeKey{}, (*apiKeyEntry)(nil)), nil
+	}
+	if entry == nil {
+		return ctx, errInvalidAPIKey
+	}
+	if err := entry.allows(method, time.Now()); err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, apiKeyScopeKey{}, entry), nil
+}
+
+// contextWithAPIKey adds the API key of the request to ctx, if it has one.
+func contextWithAPIKey(ctx context.Context, r *http.Request) context.Context {
+	key := r.Header.Get(apiKeyHeader)
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// setTestAPIKeys replaces the API keys until the test ends.
+func setTestAPIKeys(t *testing.T, keys []*APIKey, anonymous *APIKey) {
+	if err := SetAPIKeys(keys, anonymous); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetAPIKeys(nil, nil) })
+}
+
+func contextWithKey(key string) context.Context {
+	r := httptest.NewRequest("POST", "/", nil)
+	if key != "" {
+		r.Header.Set(apiKeyHeader, key)
+	}
+	return contextWithAPIKey(context.Background(), r)
+}
+
+func authorizeCode(ctx context.Context, method string) int {
+	_, err := apiKeys.authorize(ctx, method)
+	if err == nil {
+		return 0
+	}
+	return err.(Error).ErrorCode()
+}
+
+// Test the API keys
+//   - test the calls aren't checked while there are no keys
+//   - test the calls without a key or with an unknown one are rejected by default
+//   - test the allowed and denied methods of a key
+//   - test the usage is only accounted for the calls of the key
+func TestAPIKeys_Authorize(t *testing.T) {
+	if code := authorizeCode(contextWithKey(""), "ledger.getAccountInfo"); code != 0 {
+		t.Fatalf("call rejected without keys, code %v", code)
+	}
+
+	setTestAPIKeys(t, []*APIKey{
+		{Name: "ledger", Key: "ledger-key", Allowed: []string{"ledger.*"}, Denied: []string{"ledger.getAccountInfo"}},
+		{Name: "all", Key: "all-key"},
+	}, nil)
+	for _, test := range []struct {
+		key    string
+		method string
+		code   int
+	}{
+		{"", "ledger.getFrontierMomentum", apiKeyErrorCode},
+		{"unknown-key", "ledger.getFrontierMomentum", apiKeyErrorCode},
+		{"ledger-key", "ledger.getFrontierMomentum", 0},
+		{"ledger-key", "ledger.getAccountInfo", apiKeyErrorCode},
+		{"ledger-key", "stats.networkInfo", apiKeyErrorCode},
+		{"all-key", "stats.networkInfo", 0},
+	} {
+		if code := authorizeCode(contextWithKey(test.key), test.method); code != test.code {
+			t.Errorf("%q calling %v: code %v, expected %v", test.key, test.method, code, test.code)
+		}
+	}
+
+	usage := GetAPIKeyUsage(context.Background())
+	if len(usage) != 2 || usage[1].Name != "ledger" || usage[1].Calls != 1 || usage[1].Rejected != 2 || usage[1].Methods["ledger.getFrontierMomentum"] != 1 {
+		t.Fatalf("unexpected usage %+v", usage[1])
+	}
+	ctx, err := apiKeys.authorize(contextWithKey("all-key"), "stats.apiKeyUsage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if own := GetAPIKeyUsage(ctx); len(own) != 1 || own[0].Name != "all" {
+		t.Fatalf("key sees the usage of the others: %+v", own)
+	}
+}
+
+// Test the rate limits
+//   - test a key is limited to RateLimit calls per second and refilled over time
+//   - test the calls without a key are limited by the anonymous key once allowed
+//   - test the anonymous key can't be added or used as a name
+func TestAPIKeys_RateLimit(t *testing.T) {
+	setTestAPIKeys(t, []*APIKey{{Name: "limited", Key: "limited-key", RateLimit: 2}}, &APIKey{RateLimit: 1})
+
+	entry := apiKeys.keys[sha256.Sum256([]byte("limited-key"))]
+	now := time.Now()
+	for i, allowed := range []bool{true, true, false} {
+		if err := entry.allows("ledger.getFrontierMomentum", now); (err == nil) != allowed {
+			t.Fatalf("call %v: %v", i, err)
+		}
+	}
+	if err := entry.allows("ledger.getFrontierMomentum", now.Add(500*time.Millisecond)); err != nil {
+		t.Fatalf("bucket not refilled: %v", err)
+	}
+
+	if code := authorizeCode(contextWithKey(""), "ledger.getFrontierMomentum"); code != 0 {
+		t.Fatalf("anonymous call rejected, code %v", code)
+	}
+	if code := authorizeCode(contextWithKey(""), "ledger.getFrontierMomentum"); code != rateLimitErrorCode {
+		t.Fatalf("anonymous call not limited, code %v", code)
+	}
+	usage := GetAPIKeyUsage(context.Background())
+	if len(usage) != 2 || usage[0].Name != AnonymousAPIKey || usage[0].Calls != 1 || usage[0].Rejected != 1 {
+		t.Fatalf("unexpected anonymous usage %+v", usage[0])
+	}
+
+	if err := AddAPIKey(&APIKey{Name: AnonymousAPIKey, Key: "another-key"}); err == nil {
+		t.Fatal("anonymous key added")
+	}
+	if err := SetAPIKeys([]*APIKey{{Name: AnonymousAPIKey, Key: "another-key"}}, nil); err == nil {
+		t.Fatal("anonymous key set")
+	}
+}
+
+// Test the key is only read from the header, not from the query of the URL
+func TestContextWithAPIKey(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?apikey=query-key", nil)
+	if key := contextWithAPIKey(context.Background(), r).Value(apiKeyContextKey{}); key != nil {
+		t.Fatalf("key %v read from the query", key)
+	}
+	r.Header.Set(apiKeyHeader, "header-key")
+	if key := contextWithAPIKey(context.Background(), r).Value(apiKeyContextKey{}); key != "header-key" {
+		t.Fatalf("key %v read instead of the header", key)
+	}
+}
@@ -23,8 +23,9 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 )
 
-// StartIPCEndpoint starts an IPC endpoint.
-func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, error) {
+// StartIPCEndpoint starts an IPC endpoint. Methods for which allowed returns false are not served,
+// all are served if allowed is nil.
+func StartIPCEndpoint(ipcEndpoint string, apis []API, allowed func(method string) bool) (net.Listener, *Server, error) {
 	// Register all the APIs exposed by the services.
 	var (
 		handler    = NewServer()
@@ -41,6 +42,9 @@ func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, er
 			regMap[api.Namespace] = struct{}{}
 		}
 	}
+	if allowed != nil {
+		handler.FilterMethods(allowed)
+	}
 	log.Debug("IPCs registered", "namespaces", strings.Join(registered, ","))
 	// All APIs registered, start the IPC listener.
 	listener, err := ipcListen(ipcEndpoint)
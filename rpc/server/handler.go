@@ -26,6 +26,8 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/zenon-network/go-zenon/common/tracing"
 )
 
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
@@ -333,12 +335,31 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if callb == nil {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	callCtx := cp.ctx
+	if callb != h.unsubscribeCb {
+		var err error
+		if callCtx, err = h.reg.authorize(cp.ctx, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, span := tracing.StartSpan(callCtx, "rpc "+msg.Method, tracing.SpanKindServer)
+	span.SetAttribute("rpc.system", "jsonrpc")
+	span.SetAttribute("rpc.method", msg.Method)
+	requestID, _ := cp.ctx.Value(requestIDHeader).(string)
+	if requestID != "" {
+		span.SetAttribute("http.request_id", requestID)
+	}
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error != nil {
+		span.Finish(answer.Error)
+	} else {
+		span.Finish(nil)
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -351,6 +372,9 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		}
 		rpcServingTimer.UpdateSince(start)
 		newRPCServingTimer(msg.Method, answer.Error == nil).UpdateSince(start)
+		if rpcStats.record(msg.Method, requestID, start, answer.Error) {
+			slowQueryLog.Warn("slow rpc call", "method", msg.Method, "request-id", requestID, "duration", time.Since(start), "params", logParams(msg.Method, msg.Params))
+		}
 	}
 	return answer
 }
@@ -371,6 +395,10 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	if callb == nil {
 		return msg.errorResponse(&subscriptionNotFoundError{namespace, name})
 	}
+	callCtx, err := h.reg.authorize(cp.ctx, namespace+serviceMethodSeparator+name)
+	if err != nil {
+		return msg.errorResponse(err)
+	}
 
 	// Parse subscription name arg too, but remove it before calling the callback.
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
@@ -381,9 +409,9 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	args = args[1:]
 
 	// Install notifier in context so the subscription handler can find it.
-	n := &Notifier{h: h, namespace: namespace}
+	n := &Notifier{h: h, namespace: namespace, activatedCh: make(chan struct{})}
 	cp.notifiers = append(cp.notifiers, n)
-	ctx := context.WithValue(cp.ctx, notifierKey{}, n)
+	ctx := context.WithValue(callCtx, notifierKey{}, n)
 
 	return h.runMethod(ctx, msg, callb, args)
 }
@@ -19,6 +19,8 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -28,11 +30,15 @@ import (
 	"net/url"
 	"sync"
 	"time"
+
+	"github.com/zenon-network/go-zenon/common/tracing"
 )
 
 const (
 	maxRequestContentLength = 1024 * 1024 * 5
 	contentType             = "application/json"
+	requestIDHeader         = "X-Request-Id"
+	maxRequestIDLength      = 128
 )
 
 // https://www.jsonrpc.org/historical/json-rpc-over-http.html#id13
@@ -248,13 +254,43 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if origin := r.Header.Get("Origin"); origin != "" {
 		ctx = context.WithValue(ctx, "Origin", origin)
 	}
+	// Keep the request id set by a reverse proxy, so the node logs and spans can be matched with the proxy ones
+	requestID := r.Header.Get(requestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = newRequestID()
+	}
+	ctx = context.WithValue(ctx, requestIDHeader, requestID)
+	ctx = contextWithAPIKey(ctx, r)
+	if sc, ok := tracing.ParseTraceparent(r.Header.Get("traceparent")); ok {
+		ctx = tracing.ContextWithRemoteParent(ctx, sc)
+	}
 
 	w.Header().Set("content-type", contentType)
+	w.Header().Set(requestIDHeader, requestID)
 	codec := newHTTPServerConn(r, w)
 	defer codec.close()
 	s.serveSingleRequest(ctx, codec)
 }
 
+// validRequestID accepts the ids of common proxies, which are up to 128 printable ASCII characters.
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
 func validateRequest(r *http.Request) (int, error) {
@@ -66,12 +66,29 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// FilterMethods unregisters the methods and subscriptions for which allowed returns false. Methods are
+// named as called by clients (e.g. "ledger.getFrontierMomentum"), subscriptions by their namespace and
+// name (e.g. "ledger.momentums"). The meta information service is never filtered.
+func (s *Server) FilterMethods(allowed func(method string) bool) {
+	s.services.filter(allowed)
+}
+
+// CheckAPIKeys makes the server check the API keys of the calls, see SetAPIKeys.
+func (s *Server) CheckAPIKeys() {
+	s.services.setCheckAPIKeys()
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(context.Background(), codec)
+}
+
+// serveCodec is ServeCodec with the context of the connection, the calls are served with its values.
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec) {
 	defer codec.close()
 
 	// Don't serve if server is stopped.
@@ -83,7 +100,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services)
+	c := initClient(ctx, codec, s.idgen, &s.services)
 	<-codec.closed()
 	c.Close()
 }
@@ -37,8 +37,9 @@ var (
 )
 
 type serviceRegistry struct {
-	mu       sync.Mutex
-	services map[string]service
+	mu           sync.Mutex
+	services     map[string]service
+	checkAPIKeys bool
 }
 
 // service represents a registered object.
@@ -92,6 +93,62 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// filter removes the callbacks for which allowed returns false, and the services left empty.
+func (r *serviceRegistry) filter(allowed func(method string) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, svc := range r.services {
+		if name == MetadataApi {
+			continue
+		}
+		for method := range svc.callbacks {
+			if !allowed(name + serviceMethodSeparator + method) {
+				delete(svc.callbacks, method)
+			}
+		}
+		for subscription := range svc.subscriptions {
+			if !allowed(name + serviceMethodSeparator + subscription) {
+				delete(svc.subscriptions, subscription)
+			}
+		}
+		if len(svc.callbacks) == 0 && len(svc.subscriptions) == 0 {
+			delete(r.services, name)
+		}
+	}
+}
+
+func (r *serviceRegistry) setCheckAPIKeys() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkAPIKeys = true
+}
+
+// authorize checks the API key of a call of method if the server checks them, see apiKeyRegistry.authorize.
+func (r *serviceRegistry) authorize(ctx context.Context, method string) (context.Context, error) {
+	r.mu.Lock()
+	check := r.checkAPIKeys
+	r.mu.Unlock()
+	if !check {
+		return ctx, nil
+	}
+	return apiKeys.authorize(ctx, method)
+}
+
+// MatchesMethod reports whether method is one of patterns, or is nested under one of their wildcards. Patterns
+// are method names (e.g. "ledger.getFrontierMomentum") or wildcards matching all methods of a namespace and of
+// the namespaces nested under it (e.g. "ledger.*").
+func MatchesMethod(method string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == method {
+			return true
+		}
+		if strings.HasSuffix(pattern, ".*") && strings.HasPrefix(method, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
 // callback returns the callback corresponding to the given RPC method name.
 func (r *serviceRegistry) callback(method string) *callback {
 	endIndex := strings.LastIndex(method, serviceMethodSeparator)
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+)
+
+const (
+	maxSlowQueries            = 100 // Number of the most recent slow queries kept for Metrics
+	maxSlowQueryParams        = 256 // Bytes of the params logged for a slow query
+	defaultSlowQueryThreshold = time.Second
+)
+
+// latencyBuckets are the upper bounds, in milliseconds, of the latency histograms of the methods.
+// The calls above the last bound are counted in an extra unbounded bucket.
+var latencyBuckets = []int64{1, 5, 10, 50, 100, 500, 1000, 5000}
+
+// slowQueryLog is the logger of common.RPCLogger, which can't be imported by the server.
+var slowQueryLog = log15.New("module", "rpc", "submodule", "slow-query")
+
+// redactedNamespaces take secrets, such as the passwords of the wallet, so their params are never logged.
+var redactedNamespaces = map[string]bool{
+	"wallet": true,
+}
+
+// rpcStats collects the statistics of the calls of all the servers of the process.
+var rpcStats = newCallStats()
+
+type methodStats struct {
+	calls   uint64
+	errors  uint64
+	total   time.Duration
+	max     time.Duration
+	buckets []uint64
+}
+
+type callStats struct {
+	lock          sync.Mutex
+	methods       map[string]*methodStats
+	slowQueries   []*SlowQuery
+	slowThreshold time.Duration
+}
+
+func newCallStats() *callStats {
+	return &callStats{
+		methods:       make(map[string]*methodStats),
+		slowThreshold: defaultSlowQueryThreshold,
+	}
+}
+
+// SetSlowQueryThreshold sets the duration above which the calls are logged as slow queries, zero disables the log.
+func SetSlowQueryThreshold(threshold time.Duration) {
+	rpcStats.lock.Lock()
+	defer rpcStats.lock.Unlock()
+	rpcStats.slowThreshold = threshold
+}
+
+// record accounts a call of method and returns whether it's a slow query. The params of the slow queries
+// are only logged, see logParams, they aren't kept.
+func (s *callStats) record(method string, requestID string, start time.Time, err *jsonError) bool {
+	duration := time.Since(start)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	stats, ok := s.methods[method]
+	if !ok {
+		stats = &methodStats{buckets: make([]uint64, len(latencyBuckets)+1)}
+		s.methods[method] = stats
+	}
+	stats.calls += 1
+	if err != nil {
+		stats.errors += 1
+	}
+	stats.total += duration
+	if duration > stats.max {
+		stats.max = duration
+	}
+	bucket := sort.Search(len(latencyBuckets), func(i int) bool {
+		return duration <= time.Duration(latencyBuckets[i])*time.Millisecond
+	})
+	stats.buckets[bucket] += 1
+
+	if s.slowThreshold == 0 || duration < s.slowThreshold {
+		return false
+	}
+	query := &SlowQuery{
+		Method:    method,
+		RequestID: requestID,
+		Timestamp: start.Unix(),
+		Duration:  duration.Milliseconds(),
+	}
+	if err != nil {
+		query.Error = err.Message
+	}
+	if len(s.slowQueries) == maxSlowQueries {
+		s.slowQueries = append(s.slowQueries[:0], s.slowQueries[1:]...)
+	}
+	s.slowQueries = append(s.slowQueries, query)
+	return true
+}
+
+// logParams returns the params of a slow query of method for the log: they are redacted for the
+// redactedNamespaces and truncated otherwise, since they can be large.
+func logParams(method string, params json.RawMessage) string {
+	namespace := method
+	if index := strings.Index(method, serviceMethodSeparator); index != -1 {
+		namespace = method[:index]
+	}
+	if redactedNamespaces[namespace] {
+		return "[redacted]"
+	}
+	if len(params) > maxSlowQueryParams {
+		return string(params[:maxSlowQueryParams]) + "..."
+	}
+	return string(params)
+}
+
+// RPCMetrics are the statistics of the RPC calls served since the node started, durations are in milliseconds.
+type RPCMetrics struct {
+	SlowQueryThreshold int64               `json:"slowQueryThreshold"`
+	Namespaces         []*NamespaceMetrics `json:"namespaces"`
+	// SlowQueries are the most recent calls above the threshold, oldest first
+	SlowQueries []*SlowQuery `json:"slowQueries"`
+}
+
+type NamespaceMetrics struct {
+	Namespace string           `json:"namespace"`
+	Calls     uint64           `json:"calls"`
+	Errors    uint64           `json:"errors"`
+	Methods   []*MethodMetrics `json:"methods"`
+}
+
+type MethodMetrics struct {
+	Method         string           `json:"method"`
+	Calls          uint64           `json:"calls"`
+	Errors         uint64           `json:"errors"`
+	ErrorRate      float64          `json:"errorRate"`
+	AverageLatency float64          `json:"averageLatency"`
+	MaxLatency     int64            `json:"maxLatency"`
+	Histogram      []*LatencyBucket `json:"histogram"`
+}
+
+// LatencyBucket counts the calls which took at most Le milliseconds and more than the bound of the previous bucket.
+// Le is zero for the last bucket, which is unbounded.
+type LatencyBucket struct {
+	Le    int64  `json:"le"`
+	Count uint64 `json:"count"`
+}
+
+// SlowQuery is a call above the slow-query threshold. Its params aren't kept, they may hold secrets.
+type SlowQuery struct {
+	Method    string `json:"method"`
+	RequestID string `json:"requestId,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	Duration  int64  `json:"duration"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Metrics returns the statistics of the RPC calls, grouped by namespace and sorted by name.
+func Metrics() *RPCMetrics {
+	rpcStats.lock.Lock()
+	defer rpcStats.lock.Unlock()
+
+	result := &RPCMetrics{
+		SlowQueryThreshold: rpcStats.slowThreshold.Milliseconds(),
+		Namespaces:         make([]*NamespaceMetrics, 0),
+		SlowQueries:        append(make([]*SlowQuery, 0, len(rpcStats.slowQueries)), rpcStats.slowQueries...),
+	}
+	namespaces := make(map[string]*NamespaceMetrics)
+	for method, stats := range rpcStats.methods {
+		name := method
+		if index := strings.LastIndex(method, serviceMethodSeparator); index != -1 {
+			name = method[:index]
+		}
+		namespace, ok := namespaces[name]
+		if !ok {
+			namespace = &NamespaceMetrics{Namespace: name, Methods: make([]*MethodMetrics, 0)}
+			namespaces[name] = namespace
+			result.Namespaces = append(result.Namespaces, namespace)
+		}
+		namespace.Calls += stats.calls
+		namespace.Errors += stats.errors
+
+		metrics := &MethodMetrics{
+			Method:         method,
+			Calls:          stats.calls,
+			Errors:         stats.errors,
+			ErrorRate:      float64(stats.errors) / float64(stats.calls),
+			AverageLatency: float64(stats.total.Microseconds()) / float64(stats.calls) / 1000,
+			MaxLatency:     stats.max.Milliseconds(),
+			Histogram:      make([]*LatencyBucket, len(stats.buckets)),
+		}
+		for i, count := range stats.buckets {
+			metrics.Histogram[i] = &LatencyBucket{Count: count}
+			if i < len(latencyBuckets) {
+				metrics.Histogram[i].Le = latencyBuckets[i]
+			}
+		}
+		namespace.Methods = append(namespace.Methods, metrics)
+	}
+
+	sort.Slice(result.Namespaces, func(i, j int) bool {
+		return result.Namespaces[i].Namespace < result.Namespaces[j].Namespace
+	})
+	for _, namespace := range result.Namespaces {
+		sort.Slice(namespace.Methods, func(i, j int) bool {
+			return namespace.Methods[i].Method < namespace.Methods[j].Method
+		})
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inconshreveable/log15"
+)
+
+type testSlowService struct{}
+
+func (s *testSlowService) Unlock(path, password string) error {
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+func (s *testSlowService) Echo(value string) string {
+	time.Sleep(5 * time.Millisecond)
+	return value
+}
+
+func TestLogParams(t *testing.T) {
+	for method, expected := range map[string]string{
+		"wallet.unlock":          "[redacted]",
+		"wallet.changePassword":  "[redacted]",
+		"ledger.getAccountInfo":  `["z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz"]`,
+		"embedded.pillar.getAll": `["z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz"]`,
+	} {
+		if params := logParams(method, json.RawMessage(`["z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz"]`)); params != expected {
+			t.Errorf("logged %v for %v, expected %v", params, method, expected)
+		}
+	}
+	long := json.RawMessage(`["` + strings.Repeat("a", maxSlowQueryParams) + `"]`)
+	if params := logParams("ledger.echo", long); len(params) != maxSlowQueryParams+3 || !strings.HasSuffix(params, "...") {
+		t.Errorf("long params weren't truncated: %v", params)
+	}
+}
+
+// Test slow queries
+//   - test the params of the wallet namespace are redacted in the slow-query log
+//   - test the params of the other namespaces are logged
+//   - test the params aren't exposed by the metrics
+func TestSlowQueries(t *testing.T) {
+	var lock sync.Mutex
+	logged := make(map[string]string)
+	slowQueryLog.SetHandler(log15.FuncHandler(func(r *log15.Record) error {
+		lock.Lock()
+		defer lock.Unlock()
+		var method, params string
+		for i := 0; i+1 < len(r.Ctx); i += 2 {
+			switch r.Ctx[i] {
+			case "method":
+				method = r.Ctx[i+1].(string)
+			case "params":
+				params = r.Ctx[i+1].(string)
+			}
+		}
+		logged[method] = params
+		return nil
+	}))
+	SetSlowQueryThreshold(time.Millisecond)
+	t.Cleanup(func() {
+		slowQueryLog.SetHandler(log15.DiscardHandler())
+		SetSlowQueryThreshold(defaultSlowQueryThreshold)
+	})
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("wallet", new(testSlowService)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("ledger", new(testSlowService)); err != nil {
+		t.Fatal(err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "wallet.unlock", "/wallet/key", "secret-password"); err != nil {
+		t.Fatal(err)
+	}
+	var echo string
+	if err := client.Call(&echo, "ledger.echo", "visible"); err != nil {
+		t.Fatal(err)
+	}
+
+	lock.Lock()
+	if params := logged["wallet.unlock"]; params != "[redacted]" {
+		t.Errorf("logged wallet params %q", params)
+	}
+	if params := logged["ledger.echo"]; params != `["visible"]` {
+		t.Errorf("logged ledger params %q", params)
+	}
+	lock.Unlock()
+
+	metrics, err := json.Marshal(Metrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(metrics), "secret-password") || strings.Contains(string(metrics), "visible") {
+		t.Fatalf("metrics expose the params: %s", metrics)
+	}
+	if !strings.Contains(string(metrics), `"method":"wallet.unlock"`) {
+		t.Fatalf("metrics miss the slow query: %s", metrics)
+	}
+}
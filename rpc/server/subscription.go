@@ -99,6 +99,7 @@ type Notifier struct {
 	buffer       []json.RawMessage
 	callReturned bool
 	activated    bool
+	activatedCh  chan struct{}
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -141,6 +142,13 @@ func (n *Notifier) Notify(id ID, data interface{}) error {
 	return nil
 }
 
+// Activated returns a channel that is closed once the subscription ID was sent to the client.
+// From then on Notify writes directly to the connection, so a producer which waits for it
+// is slowed down to the pace of the client instead of filling the buffer.
+func (n *Notifier) Activated() <-chan struct{} {
+	return n.activatedCh
+}
+
 // Closed returns a channel that is closed when the RPC connection is closed.
 // Deprecated: use subscription error channel
 func (n *Notifier) Closed() <-chan interface{} {
@@ -169,6 +177,7 @@ func (n *Notifier) activate() error {
 		}
 	}
 	n.activated = true
+	close(n.activatedCh)
 	return nil
 }
 
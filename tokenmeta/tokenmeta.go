@@ -0,0 +1,189 @@
+package tokenmeta
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+const (
+	// scanInterval is how often the tokens are scanned for the ones to check.
+	scanInterval = time.Hour
+	// refreshInterval is the age of a verified metadata after which it's checked again.
+	refreshInterval = 24 * time.Hour
+	// retryInterval is the age of an unverified metadata after which it's checked again.
+	retryInterval = 6 * time.Hour
+)
+
+var metadataPrefix = []byte{0}
+
+func getMetadataKey(zts types.ZenonTokenStandard) []byte {
+	return common.JoinBytes(metadataPrefix, zts.Bytes())
+}
+
+// Methods which verified a metadata.
+const (
+	MethodWellKnown = "well-known"
+	MethodDNS       = "dns"
+)
+
+// Metadata is the result of the last check of the domain of a token. A verified metadata is vouched for by the
+// domain, either by listing the token in its well-known file, in which case the logo and the website come from
+// it, or by a DNS TXT record. Reason is why the metadata isn't verified. CheckedAt is in seconds.
+type Metadata struct {
+	TokenStandard types.ZenonTokenStandard
+	Domain        string
+	Verified      bool
+	Method        string
+	Reason        string
+	LogoURI       string
+	Website       string
+	CheckedAt     uint64
+}
+
+// Registry keeps the metadata published by the domains of the tokens. The domains are checked in the background,
+// a token is checked once a scan finds it, then again once its metadata is old.
+type Registry interface {
+	Start() error
+	Stop() error
+
+	// Get returns the metadata of zts, nil if its domain wasn't checked yet or the token has no domain.
+	Get(zts types.ZenonTokenStandard) (*Metadata, error)
+}
+
+type registry struct {
+	log      common.Logger
+	chain    chain.Chain
+	db       db.DB
+	verifier *verifier
+
+	lock   sync.Mutex
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewRegistry(db db.DB, chain chain.Chain) Registry {
+	return &registry{
+		log:      common.TokenMetaLogger,
+		chain:    chain,
+		db:       db,
+		verifier: newVerifier(),
+		closed:   make(chan struct{}),
+	}
+}
+
+func (r *registry) Start() error {
+	r.log.Info("starting token metadata registry")
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.loop()
+	}()
+	return nil
+}
+func (r *registry) Stop() error {
+	close(r.closed)
+	r.wg.Wait()
+	return nil
+}
+
+func (r *registry) loop() {
+	defer common.RecoverStack()
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.scan(); err != nil {
+			r.log.Error("failed to check the token domains", "reason", err)
+		}
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan checks the domains of the tokens which have no metadata or an old one.
+func (r *registry) scan() error {
+	store := r.chain.GetFrontierMomentumStore()
+	tokens, err := definition.GetTokenInfoList(store.GetAccountStore(types.TokenContract).Storage())
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		select {
+		case <-r.closed:
+			return nil
+		default:
+		}
+		if token.TokenDomain == "" {
+			continue
+		}
+		current, err := r.Get(token.TokenStandard)
+		if err != nil {
+			return err
+		}
+		if !stale(current) {
+			continue
+		}
+		if err := r.update(token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stale(metadata *Metadata) bool {
+	if metadata == nil {
+		return true
+	}
+	age := common.Clock.Now().Sub(time.Unix(int64(metadata.CheckedAt), 0))
+	if metadata.Verified {
+		return age >= refreshInterval
+	}
+	return age >= retryInterval
+}
+
+// update checks the domain of the token and stores the result.
+func (r *registry) update(token *definition.TokenInfo) error {
+	metadata := r.verifier.check(token)
+	if metadata.Verified {
+		r.log.Debug("verified token metadata", "zts", token.TokenStandard, "domain", metadata.Domain, "method", metadata.Method)
+	} else {
+		r.log.Debug("failed to verify token metadata", "zts", token.TokenStandard, "domain", metadata.Domain, "reason", metadata.Reason)
+	}
+
+	data, err := rlp.EncodeToBytes(metadata)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.db.Put(getMetadataKey(token.TokenStandard), data)
+}
+
+func (r *registry) Get(zts types.ZenonTokenStandard) (*Metadata, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	data, err := r.db.Get(getMetadataKey(zts))
+	if err == leveldb.ErrNotFound || (err == nil && len(data) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	metadata := new(Metadata)
+	if err := rlp.DecodeBytes(data, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
@@ -0,0 +1,173 @@
+package tokenmeta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+const (
+	// WellKnownPath is the file in which a domain lists the metadata of its tokens, served over https.
+	WellKnownPath = "/.well-known/zenon-tokens.json"
+	// TXTPrefix is the label of the TXT records of a domain which vouch for its tokens with a "zts=<zts>" value.
+	TXTPrefix = "_zenon."
+
+	requestTimeout  = 10 * time.Second
+	maxWellKnownLen = 64 * 1024
+	maxURILen       = 256
+)
+
+// WellKnown is the content of the well-known file of a domain.
+type WellKnown struct {
+	Tokens []*WellKnownToken `json:"tokens"`
+}
+
+// WellKnownToken is the metadata of a token in the well-known file. Symbol and Decimals are optional and, when
+// set, must match the ones of the token.
+type WellKnownToken struct {
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Symbol        string                   `json:"symbol"`
+	Decimals      *uint8                   `json:"decimals"`
+	LogoURI       string                   `json:"logoURI"`
+	Website       string                   `json:"website"`
+}
+
+// verifier checks the domains of the tokens. The lookups are fields so they can be replaced by the tests.
+type verifier struct {
+	fetch     func(domain string) ([]byte, error)
+	lookupTXT func(name string) ([]string, error)
+}
+
+func newVerifier() *verifier {
+	client := &http.Client{Timeout: requestTimeout}
+	return &verifier{
+		fetch: func(domain string) ([]byte, error) {
+			response, err := client.Get("https://" + domain + WellKnownPath)
+			if err != nil {
+				return nil, err
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				return nil, errors.Errorf("unexpected status %v", response.Status)
+			}
+			return io.ReadAll(io.LimitReader(response.Body, maxWellKnownLen))
+		},
+		lookupTXT: func(name string) ([]string, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+			return net.DefaultResolver.LookupTXT(ctx, name)
+		},
+	}
+}
+
+// check returns the metadata of the token published by its domain. The well-known file is preferred, since
+// it carries the logo, a token listed there with a symbol or decimals which don't match isn't verified.
+func (v *verifier) check(token *definition.TokenInfo) *Metadata {
+	domain := strings.ToLower(token.TokenDomain)
+	metadata := &Metadata{
+		TokenStandard: token.TokenStandard,
+		Domain:        domain,
+		CheckedAt:     uint64(common.Clock.Now().Unix()),
+	}
+
+	entry, wellKnownErr := v.wellKnown(domain, token.TokenStandard)
+	if entry != nil {
+		if err := validate(token, entry); err != nil {
+			metadata.Reason = err.Error()
+			return metadata
+		}
+		metadata.Verified = true
+		metadata.Method = MethodWellKnown
+		metadata.LogoURI = entry.LogoURI
+		metadata.Website = entry.Website
+		return metadata
+	}
+
+	listed, txtErr := v.txt(domain, token.TokenStandard)
+	if listed {
+		metadata.Verified = true
+		metadata.Method = MethodDNS
+		return metadata
+	}
+	metadata.Reason = fmt.Sprintf("the domain doesn't list the token (well-known: %v, dns: %v)", wellKnownErr, txtErr)
+	return metadata
+}
+
+// wellKnown returns the entry of zts in the well-known file of the domain.
+func (v *verifier) wellKnown(domain string, zts types.ZenonTokenStandard) (*WellKnownToken, error) {
+	data, err := v.fetch(domain)
+	if err != nil {
+		return nil, err
+	}
+	file := new(WellKnown)
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, errors.Errorf("invalid file: %v", err)
+	}
+	for _, entry := range file.Tokens {
+		if entry != nil && entry.TokenStandard == zts {
+			return entry, nil
+		}
+	}
+	return nil, errors.New("not listed")
+}
+
+// txt returns true if a TXT record of the domain vouches for zts.
+func (v *verifier) txt(domain string, zts types.ZenonTokenStandard) (bool, error) {
+	records, err := v.lookupTXT(TXTPrefix + domain)
+	if err != nil {
+		return false, err
+	}
+	expected := "zts=" + zts.String()
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true, nil
+		}
+	}
+	return false, errors.New("not listed")
+}
+
+func validate(token *definition.TokenInfo, entry *WellKnownToken) error {
+	if entry.Symbol != "" && entry.Symbol != token.TokenSymbol {
+		return errors.Errorf("the domain lists the symbol %v but the token has %v", entry.Symbol, token.TokenSymbol)
+	}
+	if entry.Decimals != nil && *entry.Decimals != token.Decimals {
+		return errors.Errorf("the domain lists %v decimals but the token has %v", *entry.Decimals, token.Decimals)
+	}
+	if err := validateURI(entry.LogoURI); err != nil {
+		return errors.Errorf("invalid logo: %v", err)
+	}
+	if err := validateURI(entry.Website); err != nil {
+		return errors.Errorf("invalid website: %v", err)
+	}
+	return nil
+}
+
+// validateURI accepts empty and https URIs, so wallets never load the logos over plain http.
+func validateURI(uri string) error {
+	if uri == "" {
+		return nil
+	}
+	if len(uri) > maxURILen {
+		return errors.Errorf("longer than %v characters", maxURILen)
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return errors.New("not an https URI")
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package tokenmeta
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+func fakeVerifier(wellKnown string, txt ...string) *verifier {
+	return &verifier{
+		fetch: func(domain string) ([]byte, error) {
+			if domain != "zenon.network" {
+				return nil, errors.Errorf("unexpected domain %v", domain)
+			}
+			if wellKnown == "" {
+				return nil, errors.New("unexpected status 404 Not Found")
+			}
+			return []byte(wellKnown), nil
+		},
+		lookupTXT: func(name string) ([]string, error) {
+			if name != "_zenon.zenon.network" {
+				return nil, errors.Errorf("unexpected name %v", name)
+			}
+			if len(txt) == 0 {
+				return nil, errors.New("no such host")
+			}
+			return txt, nil
+		},
+	}
+}
+
+func znnInfo() *definition.TokenInfo {
+	return &definition.TokenInfo{
+		TokenSymbol:   "ZNN",
+		TokenDomain:   "Zenon.Network",
+		Decimals:      8,
+		TokenStandard: types.ZnnTokenStandard,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name      string
+		wellKnown string
+		txt       []string
+		verified  bool
+		method    string
+		logo      string
+		reason    string
+	}{
+		{
+			name:      "well-known",
+			wellKnown: `{"tokens":[{"tokenStandard":"zts1qsrxxxxxxxxxxxxxmrhjll"},{"tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","symbol":"ZNN","decimals":8,"logoURI":"https://zenon.network/znn.png"}]}`,
+			verified:  true,
+			method:    MethodWellKnown,
+			logo:      "https://zenon.network/znn.png",
+		},
+		{
+			name:      "decimals mismatch",
+			wellKnown: `{"tokens":[{"tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","decimals":18}]}`,
+			txt:       []string{"zts=zts1znnxxxxxxxxxxxxx9z4ulx"},
+			reason:    "the domain lists 18 decimals but the token has 8",
+		},
+		{
+			name:      "symbol mismatch",
+			wellKnown: `{"tokens":[{"tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","symbol":"QSR"}]}`,
+			reason:    "the domain lists the symbol QSR but the token has ZNN",
+		},
+		{
+			name:      "plain http logo",
+			wellKnown: `{"tokens":[{"tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","logoURI":"http://zenon.network/znn.png"}]}`,
+			reason:    "invalid logo: not an https URI",
+		},
+		{
+			name:     "dns",
+			txt:      []string{"v=spf1 -all", " zts=zts1znnxxxxxxxxxxxxx9z4ulx "},
+			verified: true,
+			method:   MethodDNS,
+		},
+		{
+			name:      "unlisted",
+			wellKnown: `{"tokens":[]}`,
+			txt:       []string{"zts=zts1qsrxxxxxxxxxxxxxmrhjll"},
+			reason:    "the domain doesn't list the token (well-known: not listed, dns: not listed)",
+		},
+		{
+			name:   "unreachable",
+			reason: "the domain doesn't list the token (well-known: unexpected status 404 Not Found, dns: no such host)",
+		},
+	}
+	for _, c := range cases {
+		metadata := fakeVerifier(c.wellKnown, c.txt...).check(znnInfo())
+		if metadata.Domain != "zenon.network" || metadata.CheckedAt == 0 {
+			t.Errorf("%v: unexpected domain %v or check time %v", c.name, metadata.Domain, metadata.CheckedAt)
+		}
+		if metadata.Verified != c.verified || metadata.Method != c.method || metadata.LogoURI != c.logo || metadata.Reason != c.reason {
+			t.Errorf("%v: unexpected metadata %+v", c.name, metadata)
+		}
+	}
+}
+
+func TestRegistryUpdate(t *testing.T) {
+	r := &registry{
+		log:      common.TokenMetaLogger,
+		db:       db.NewMemDB(),
+		verifier: fakeVerifier(`{"tokens":[{"tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","website":"https://zenon.network"}]}`),
+	}
+	metadata, err := r.Get(types.ZnnTokenStandard)
+	if err != nil || metadata != nil {
+		t.Fatalf("expected no metadata, got %+v %v", metadata, err)
+	}
+	if !stale(metadata) {
+		t.Fatal("expected a missing metadata to be stale")
+	}
+
+	if err := r.update(znnInfo()); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err = r.Get(types.ZnnTokenStandard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata == nil || !metadata.Verified || metadata.TokenStandard != types.ZnnTokenStandard || metadata.Website != "https://zenon.network" {
+		t.Fatalf("unexpected metadata %+v", metadata)
+	}
+	if stale(metadata) {
+		t.Fatal("expected a fresh metadata")
+	}
+	if other, err := r.Get(types.QsrTokenStandard); err != nil || other != nil {
+		t.Fatalf("expected no metadata for another token, got %+v %v", other, err)
+	}
+}
@@ -24,11 +24,13 @@ func isContractReceive(block *nom.AccountBlock) bool {
 type AccountBlockVerifier interface {
 	AccountBlock(block *nom.AccountBlock) error
 	AccountBlockTransaction(transaction *nom.AccountBlockTransaction) error
+	PrecheckAccountBlocks(blocks []*nom.AccountBlock) error
 }
 
 type accountVerifier struct {
-	chain     chain.Chain
-	consensus consensus.Consensus
+	chain      chain.Chain
+	consensus  consensus.Consensus
+	prechecked *prechecked
 }
 
 func (av *accountVerifier) getContext(block *nom.AccountBlock) (store.Account, store.Momentum, error) {
@@ -90,6 +92,7 @@ func (av *accountVerifier) AccountBlock(block *nom.AccountBlock) error {
 		block:         block,
 		accountStore:  accountStore,
 		momentumStore: momentumStore,
+		prechecked:    av.prechecked,
 	}).all()
 }
 func (av *accountVerifier) AccountBlockTransaction(transaction *nom.AccountBlockTransaction) error {
@@ -106,13 +109,15 @@ func (av *accountVerifier) AccountBlockTransaction(transaction *nom.AccountBlock
 		transaction:   transaction,
 		accountStore:  accountStore,
 		momentumStore: momentumStore,
+		prechecked:    av.prechecked,
 	}).all()
 }
 
 func NewAccountBlockVerifier(chain chain.Chain, consensus consensus.Consensus) AccountBlockVerifier {
 	return &accountVerifier{
-		chain:     chain,
-		consensus: consensus,
+		chain:      chain,
+		consensus:  consensus,
+		prechecked: &prechecked{},
 	}
 }
 
@@ -120,6 +125,7 @@ type accountBlockVerifier struct {
 	block         *nom.AccountBlock
 	accountStore  store.Account
 	momentumStore store.Momentum
+	prechecked    *prechecked
 }
 
 func (abv *accountBlockVerifier) all() error {
@@ -135,6 +141,9 @@ func (abv *accountBlockVerifier) all() error {
 	if err := abv.amounts(); err != nil {
 		return err
 	}
+	if err := abv.data(); err != nil {
+		return err
+	}
 	if err := abv.pow(); err != nil {
 		return err
 	}
@@ -228,11 +237,27 @@ func (abv *accountBlockVerifier) amounts() error {
 	}
 	return nil
 }
+func (abv *accountBlockVerifier) data() error {
+	if !chain.IsDataLimited(abv.block) {
+		return nil
+	}
+	limits, err := chain.GetPayloadLimits(abv.momentumStore)
+	if err != nil {
+		return InternalError(err)
+	}
+	if len(abv.block.Data) > limits.MaxDataLength {
+		return ErrABDataTooBig
+	}
+	return nil
+}
 func (abv *accountBlockVerifier) pow() error {
 	if abv.block.Difficulty != 0 {
 		if types.IsEmbeddedAddress(abv.block.Address) {
 			return ErrABPoWInvalid
 		}
+		if abv.prechecked.pow(abv.block) {
+			return nil
+		}
 		if !pow.CheckPoWNonce(abv.block) {
 			return ErrABPoWInvalid
 		}
@@ -365,6 +390,7 @@ type accountBlockTransactionVerifier struct {
 	transaction   *nom.AccountBlockTransaction
 	accountStore  store.Account
 	momentumStore store.Momentum
+	prechecked    *prechecked
 }
 
 func (abvt *accountBlockTransactionVerifier) all() error {
@@ -401,6 +427,9 @@ func (abvt *accountBlockTransactionVerifier) signature() error {
 	if len(block.PublicKey) == 0 {
 		return ErrABPublicKeyMissing
 	}
+	if abvt.prechecked.signature(block) {
+		return nil
+	}
 	isVerified, err := wallet.VerifySignature(block.PublicKey, block.Hash.Bytes(), block.Signature)
 	if err != nil {
 		return ErrABSignatureInvalid
@@ -14,6 +14,18 @@ func DescendantVerifyError(err error) error {
 	return fmt.Errorf("%w - %v", ErrABDescendantVerify, err)
 }
 
+// IsInvalidMomentum returns true if err proves that a momentum, or one of its account-blocks, was forged or
+// produced against the consensus rules. Such errors don't depend on the local chain state, so the peer which
+// propagated the momentum is at fault.
+func IsInvalidMomentum(err error) bool {
+	for _, invalid := range invalidMomentumErrors {
+		if errors.Is(err, invalid) {
+			return true
+		}
+	}
+	return false
+}
+
 var (
 	ErrVerifierInternal = errors.New("internal error while verifying")
 
@@ -81,4 +93,11 @@ var (
 	ErrMNotGenesis              = errors.New("momentum is not genesis-momentum")
 	ErrMProducerInvalid         = errors.New("momentum producer is invalid")
 	ErrMPreviousMissing         = errors.New("momentum previous momentum is missing")
+
+	invalidMomentumErrors = []error{
+		ErrMVersionMissing, ErrMVersionInvalid, ErrMChainIdentifierMissing, ErrMChainIdentifierMismatch,
+		ErrMDataMustBeZero, ErrMChangesHashInvalid, ErrMHashInvalid, ErrMContentTooBig, ErrMTimestampMissing,
+		ErrMSignatureMissing, ErrMPublicKeyMissing, ErrMSignatureInvalid, ErrMProducerInvalid,
+		ErrABHashInvalid, ErrABPublicKeyWrongAddress, ErrABSignatureInvalid, ErrABSignatureMissing, ErrABPoWInvalid,
+	}
 )
@@ -156,7 +156,11 @@ func (rmv *rawMomentumVerifier) data() error {
 	return nil
 }
 func (rmv *rawMomentumVerifier) content() error {
-	if len(rmv.momentum.Content) > chain.MaxAccountBlocksInMomentum {
+	limits, err := chain.GetPayloadLimits(rmv.momentumStore)
+	if err != nil {
+		return InternalError(err)
+	}
+	if len(rmv.momentum.Content) > limits.MaxAccountBlocksInMomentum {
 		return ErrMContentTooBig
 	}
 	blocksLookup := make(map[types.HashHeight]*nom.AccountBlock)
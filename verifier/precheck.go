@@ -0,0 +1,124 @@
+package verifier
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	// maxPrecheckedBatches is the number of prechecked batches remembered, so the sync can precheck the
+	// next batches while the current one is applied.
+	maxPrecheckedBatches = 4
+)
+
+// prechecked remembers the account-blocks whose hash, signature and PoW were checked ahead of their
+// application, so the serial apply path doesn't check them again. The signature is only skipped for
+// the same signature and public key, the PoW is covered by the hash which is always checked again.
+// Only the most recent batches are kept, older blocks are checked again if they are applied later.
+type prechecked struct {
+	mu      sync.RWMutex
+	batches []map[types.Hash]*nom.AccountBlock
+}
+
+func (p *prechecked) add(blocks map[types.Hash]*nom.AccountBlock) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches = append(p.batches, blocks)
+	if len(p.batches) > maxPrecheckedBatches {
+		p.batches = p.batches[len(p.batches)-maxPrecheckedBatches:]
+	}
+}
+func (p *prechecked) get(hash types.Hash) (*nom.AccountBlock, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for i := len(p.batches) - 1; i >= 0; i -= 1 {
+		if checked, ok := p.batches[i][hash]; ok {
+			return checked, true
+		}
+	}
+	return nil, false
+}
+func (p *prechecked) pow(block *nom.AccountBlock) bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.get(block.Hash)
+	return ok
+}
+func (p *prechecked) signature(block *nom.AccountBlock) bool {
+	if p == nil {
+		return false
+	}
+	checked, ok := p.get(block.Hash)
+	return ok && bytes.Equal(checked.Signature, block.Signature) && bytes.Equal(checked.PublicKey, block.PublicKey)
+}
+
+// PrecheckAccountBlocks checks the hash, signature and PoW of the user account-blocks in a worker pool.
+// These checks don't depend on the chain state, so the blocks don't need to be applied first.
+// The error of the first invalid block is returned, in which case none of the blocks are remembered.
+// Blocks which are already remembered aren't checked again.
+func (av *accountVerifier) PrecheckAccountBlocks(blocks []*nom.AccountBlock) error {
+	var (
+		errs    = make([]error, len(blocks))
+		jobs    = make(chan int)
+		wg      sync.WaitGroup
+		workers = runtime.NumCPU()
+	)
+	if workers > len(blocks) {
+		workers = len(blocks)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				errs[index] = precheckAccountBlock(blocks[index])
+			}
+		}()
+	}
+	for index, block := range blocks {
+		if !needsPrecheck(block) || av.prechecked.signature(block) {
+			continue
+		}
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	checked := make(map[types.Hash]*nom.AccountBlock, len(blocks))
+	for index, block := range blocks {
+		if errs[index] != nil {
+			return errs[index]
+		}
+		if needsPrecheck(block) {
+			checked[block.Hash] = block
+		}
+	}
+	av.prechecked.add(checked)
+	return nil
+}
+
+// needsPrecheck reports whether the block is signed by a user, contract blocks are produced by the node.
+func needsPrecheck(block *nom.AccountBlock) bool {
+	return block.BlockType != nom.BlockTypeContractSend && !types.IsEmbeddedAddress(block.Address)
+}
+
+func precheckAccountBlock(block *nom.AccountBlock) error {
+	abvt := &accountBlockTransactionVerifier{
+		transaction: &nom.AccountBlockTransaction{Block: block},
+	}
+	if err := abvt.hash(); err != nil {
+		return err
+	}
+	if err := abvt.signature(); err != nil {
+		return err
+	}
+	if err := abvt.producer(); err != nil {
+		return err
+	}
+	return (&accountBlockVerifier{block: block}).pow()
+}
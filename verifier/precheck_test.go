@@ -0,0 +1,140 @@
+package verifier
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const testPrecheckDifficulty = 1000
+
+func testPrecheckKeyPair(t *testing.T, index uint32) *wallet.KeyPair {
+	keyPair, err := wallet.DeriveWithIndex(index%4, []byte("precheck test seed of the blocks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyPair
+}
+
+// testPrecheckBlocks returns count signed user blocks, every third one with PoW, and a contract send block.
+func testPrecheckBlocks(t *testing.T, count int) []*nom.AccountBlock {
+	blocks := make([]*nom.AccountBlock, 0, count+1)
+	for i := 0; i < count; i++ {
+		keyPair := testPrecheckKeyPair(t, uint32(i))
+		block := &nom.AccountBlock{
+			Version:         1,
+			ChainIdentifier: 1,
+			BlockType:       nom.BlockTypeUserSend,
+			Height:          uint64(i + 1),
+			PreviousHash:    types.NewHash([]byte{byte(i)}),
+			Address:         keyPair.Address,
+			ToAddress:       types.PillarContract,
+			Amount:          big.NewInt(int64(i)),
+			TokenStandard:   types.ZnnTokenStandard,
+		}
+		if i%3 == 0 {
+			block.Difficulty = testPrecheckDifficulty
+			block.Nonce = nom.DeSerializeNonce(pow.GetPoWNonce(big.NewInt(testPrecheckDifficulty), pow.GetAccountBlockHash(block)))
+		}
+		block.Hash = block.ComputeHash()
+		block.PublicKey = keyPair.Public
+		block.Signature = keyPair.Sign(block.Hash.Bytes())
+		blocks = append(blocks, block)
+	}
+	contract := &nom.AccountBlock{BlockType: nom.BlockTypeContractSend, Address: types.PillarContract}
+	return append(blocks, contract)
+}
+
+// serialPrecheck checks the blocks one after the other, like the apply path without the pool.
+func serialPrecheck(blocks []*nom.AccountBlock) error {
+	for _, block := range blocks {
+		if !needsPrecheck(block) {
+			continue
+		}
+		if err := precheckAccountBlock(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Test PrecheckAccountBlocks
+//   - test the pool returns the result of the serial checks, the error of the first invalid block
+//   - test the blocks are only remembered once all of them are valid
+//   - test a remembered block with another signature is checked again
+func TestPrecheckAccountBlocks(t *testing.T) {
+	blocks := testPrecheckBlocks(t, 40)
+	av := &accountVerifier{prechecked: &prechecked{}}
+	if err := serialPrecheck(blocks); err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(block *nom.AccountBlock, keyPair *wallet.KeyPair) {
+		block.Hash = block.ComputeHash()
+		block.PublicKey = keyPair.Public
+		block.Signature = keyPair.Sign(block.Hash.Bytes())
+	}
+	invalidations := []struct {
+		err        error
+		invalidate func(block *nom.AccountBlock)
+	}{
+		{ErrABHashInvalid, func(block *nom.AccountBlock) { block.Amount = big.NewInt(-1) }},
+		{ErrABSignatureInvalid, func(block *nom.AccountBlock) { block.Signature = append([]byte{}, blocks[0].Signature...) }},
+		{ErrABPublicKeyWrongAddress, func(block *nom.AccountBlock) {
+			sign(block, testPrecheckKeyPair(t, uint32(block.Height)))
+		}},
+		{ErrABPoWInvalid, func(block *nom.AccountBlock) {
+			block.Difficulty = 1 << 40
+			sign(block, testPrecheckKeyPair(t, uint32(block.Height-1)))
+		}},
+	}
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 20; round++ {
+		invalid := make([]*nom.AccountBlock, len(blocks))
+		for i, block := range blocks {
+			invalid[i] = block.Copy()
+		}
+		// two invalid blocks, the pool must report the first one whatever the worker checking it
+		first := 2 + r.Intn(len(blocks)-3)
+		second := first + 1 + r.Intn(len(blocks)-1-first)
+		firstInvalidation, secondInvalidation := invalidations[round%len(invalidations)], invalidations[(round+1)%len(invalidations)]
+		firstInvalidation.invalidate(invalid[first])
+		if second < len(blocks)-1 {
+			secondInvalidation.invalidate(invalid[second])
+		}
+
+		expected := serialPrecheck(invalid)
+		if expected != firstInvalidation.err {
+			t.Fatalf("round %v: serial checks returned %v, expected %v", round, expected, firstInvalidation.err)
+		}
+		if err := av.PrecheckAccountBlocks(invalid); err != expected {
+			t.Fatalf("round %v: pool returned %v, the serial checks %v", round, err, expected)
+		}
+		if _, ok := av.prechecked.get(invalid[0].Hash); ok {
+			t.Fatalf("round %v: blocks remembered after a failure", round)
+		}
+	}
+
+	if err := av.PrecheckAccountBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		if av.prechecked.signature(block) != needsPrecheck(block) || av.prechecked.pow(block) != needsPrecheck(block) {
+			t.Fatalf("block %v not remembered", block.Height)
+		}
+	}
+	resigned := blocks[1].Copy()
+	resigned.Signature = blocks[0].Signature
+	if av.prechecked.signature(resigned) {
+		t.Fatal("block with another signature not checked again")
+	}
+	abvt := &accountBlockTransactionVerifier{transaction: &nom.AccountBlockTransaction{Block: resigned}, prechecked: av.prechecked}
+	if err := abvt.signature(); err != ErrABSignatureInvalid {
+		t.Fatalf("expected %v, got %v", ErrABSignatureInvalid, err)
+	}
+}
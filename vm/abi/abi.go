@@ -6,11 +6,13 @@ import (
 	"io"
 
 	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
 )
 
 type ABIContract struct {
 	Methods   map[string]Method
 	Variables map[string]Variable
+	Events    map[string]Event
 }
 
 func JSONToABIContract(reader io.Reader) ABIContract {
@@ -60,6 +62,50 @@ func (abi ABIContract) PackVariablePanic(name string, args ...interface{}) []byt
 	return data
 }
 
+// PackEvent returns the topics and the data of a log. The topics are the event id followed by the
+// indexed arguments, the other arguments are packed in the data.
+func (abi ABIContract) PackEvent(name string, args ...interface{}) ([]types.Hash, []byte, error) {
+	event, exist := abi.Events[name]
+	if !exist {
+		return nil, nil, errEventNotFound(name)
+	}
+	if len(args) != len(event.Inputs) {
+		return nil, nil, errArgLengthMismatch(args, event.Inputs)
+	}
+
+	topics := []types.Hash{event.Id()}
+	nonIndexed := make(Arguments, 0, len(event.Inputs))
+	values := make([]interface{}, 0, len(args))
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, input)
+			values = append(values, args[i])
+			continue
+		}
+		packed, err := Arguments{input}.Pack(args[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		topic, err := types.BytesToHash(packed)
+		if err != nil {
+			return nil, nil, err
+		}
+		topics = append(topics, topic)
+	}
+	data, err := nonIndexed.Pack(values...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return topics, data, nil
+}
+func (abi ABIContract) PackEventPanic(name string, args ...interface{}) ([]types.Hash, []byte) {
+	topics, data, err := abi.PackEvent(name, args...)
+	if err != nil {
+		panic(err)
+	}
+	return topics, data
+}
+
 func (abi ABIContract) UnpackMethod(v interface{}, name string, input []byte) (err error) {
 	if len(input) <= 4 {
 		return errEmptyInput
@@ -89,6 +135,15 @@ func (abi ABIContract) UnpackVariable(v interface{}, name string, input []byte)
 	}
 	return errCouldNotLocateNamedVariable
 }
+
+// UnpackEvent unpacks the non-indexed arguments of an event from the data of a log.
+func (abi ABIContract) UnpackEvent(v interface{}, name string, data []byte) error {
+	event, ok := abi.Events[name]
+	if !ok {
+		return errEventNotFound(name)
+	}
+	return event.nonIndexed().Unpack(v, data)
+}
 func (abi ABIContract) UnpackVariablePanic(v interface{}, name string, input []byte) {
 	common.DealWithErr(abi.UnpackVariable(v, name, input))
 }
@@ -107,6 +162,16 @@ func (abi *ABIContract) MethodById(sigdata []byte) (*Method, error) {
 	return nil, errNoMethodId(sigdata[:4])
 }
 
+// EventById looks up an event by its id, the first topic of its logs
+func (abi *ABIContract) EventById(id types.Hash) (*Event, error) {
+	for _, event := range abi.Events {
+		if event.Id() == id {
+			return &event, nil
+		}
+	}
+	return nil, errNoEventId(id)
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface
 func (abi *ABIContract) UnmarshalJSON(data []byte) error {
 	var fields []struct {
@@ -122,6 +187,7 @@ func (abi *ABIContract) UnmarshalJSON(data []byte) error {
 
 	abi.Methods = make(map[string]Method)
 	abi.Variables = make(map[string]Variable)
+	abi.Events = make(map[string]Event)
 	for _, field := range fields {
 		switch field.Type {
 		case "function":
@@ -134,6 +200,13 @@ func (abi *ABIContract) UnmarshalJSON(data []byte) error {
 				Name:   field.Name,
 				Inputs: field.Inputs,
 			}
+		case "event":
+			for _, input := range field.Inputs {
+				if input.Indexed && (input.Type.requiresLengthPrefix() || input.Type.T == ArrayTy) {
+					return errInvalidIndexedArgument(field.Name, input.Name)
+				}
+			}
+			abi.Events[field.Name] = newEvent(field.Name, field.Inputs)
 		}
 	}
 	return nil
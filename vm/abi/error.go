@@ -39,6 +39,15 @@ func errNoMethodId(sigdata []byte) error {
 func errVariableNotFound(name string) error {
 	return fmt.Errorf("varible '%s' not found", name)
 }
+func errEventNotFound(name string) error {
+	return fmt.Errorf("event '%s' not found", name)
+}
+func errNoEventId(id fmt.Stringer) error {
+	return fmt.Errorf("no event with id: %v", id)
+}
+func errInvalidIndexedArgument(event, argument string) error {
+	return fmt.Errorf("abi: indexed argument '%s' of event '%s' must have a static type", argument, event)
+}
 
 // type errors
 func errType(expected, got interface{}) error {
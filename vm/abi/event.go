@@ -0,0 +1,57 @@
+package abi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+// Event is a log emitted by built-in contracts.
+// The first topic of the log is the event id, followed by the indexed arguments, see ABIContract.PackEvent.
+type Event struct {
+	Name   string
+	id     types.Hash
+	Inputs Arguments
+}
+
+func newEvent(name string, inputs Arguments) Event {
+	e := Event{
+		Name:   name,
+		Inputs: inputs,
+	}
+	e.id = types.NewHash([]byte(e.Sig()))
+	return e
+}
+
+func (event Event) Sig() string {
+	types := make([]string, len(event.Inputs))
+	for i, input := range event.Inputs {
+		types[i] = input.Type.String()
+	}
+	return fmt.Sprintf("%v(%v)", event.Name, strings.Join(types, ","))
+}
+func (event Event) String() string {
+	inputs := make([]string, len(event.Inputs))
+	for i, input := range event.Inputs {
+		if input.Indexed {
+			inputs[i] = fmt.Sprintf("%v indexed %v", input.Type, input.Name)
+		} else {
+			inputs[i] = fmt.Sprintf("%v %v", input.Type, input.Name)
+		}
+	}
+	return fmt.Sprintf("event %v(%v)", event.Name, strings.Join(inputs, ", "))
+}
+func (event Event) Id() types.Hash {
+	return event.id
+}
+
+func (event Event) nonIndexed() Arguments {
+	arguments := make(Arguments, 0, len(event.Inputs))
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			arguments = append(arguments, input)
+		}
+	}
+	return arguments
+}
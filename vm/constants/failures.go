@@ -0,0 +1,90 @@
+package constants
+
+import "github.com/pkg/errors"
+
+// failureCodes lists the errors with which the embedded contracts reject calls, the code of an
+// error is its index plus one. Codes are stored in the receipts, so errors are only ever appended.
+var failureCodes = []error{
+	ErrNothingToWithdraw,
+	ErrNotEnoughDepositedQsr,
+	ErrInvalidTokenOrAmount,
+	ErrNotContractAddress,
+	ErrContractDoesntExist,
+	ErrContractMethodNotFound,
+	ErrDataNonExistent,
+	ErrUnpackError,
+	ErrInsufficientBalance,
+	ErrPermissionDenied,
+	ErrInvalidArguments,
+	ErrInvalidB64Decode,
+	ErrForbiddenParam,
+	ErrNotEnoughSlots,
+	ErrUpdateTooRecent,
+	ErrEpochUpdateTooRecent,
+	ErrAcceleratorEnded,
+	ErrAcceleratorInvalidFunds,
+	ErrInvalidDescription,
+	ErrInvalidName,
+	ErrNotUnique,
+	ErrNotActive,
+	ErrIDNotUnique,
+	ErrTokenInvalidText,
+	ErrTokenInvalidAmount,
+	RevokeNotDue,
+	ErrInvalidStakingPeriod,
+	ErrBlockPlasmaLimitReached,
+	ErrNotEnoughPlasma,
+	ErrNotEnoughTotalPlasma,
+	ErrInvalidSwapCode,
+	ErrInvalidSignature,
+	ErrAlreadyRevoked,
+	ErrAlreadyRegistered,
+	ErrAlreadyActivated,
+	ReclaimNotDue,
+	ErrInvalidHashType,
+	ErrInvalidHashDigest,
+	ErrInvalidPreimage,
+	ErrInvalidExpirationTime,
+	ErrExpired,
+	ErrUnknownNetwork,
+	ErrInvalidToAddress,
+	ErrBridgeNotInitialized,
+	ErrOrchestratorNotInitialized,
+	ErrTokenNotBridgeable,
+	ErrNotGuardian,
+	ErrTokenNotRedeemable,
+	ErrBridgeHalted,
+	ErrInvalidRedeemPeriod,
+	ErrInvalidRedeemRequest,
+	ErrInvalidTransactionHash,
+	ErrInvalidNetworkName,
+	ErrInvalidContractAddress,
+	ErrInvalidToken,
+	ErrTokenNotFound,
+	ErrInvalidEDDSASignature,
+	ErrInvalidEDDSAPubKey,
+	ErrInvalidECDSASignature,
+	ErrInvalidDecompressedECDSAPubKeyLength,
+	ErrInvalidCompressedECDSAPubKeyLength,
+	ErrNotAllowedToChangeTss,
+	ErrInvalidJsonContent,
+	ErrInvalidMinAmount,
+	ErrTimeChallengeNotDue,
+	ErrNotEmergency,
+	ErrInvalidGuardians,
+	ErrSecurityNotInitialized,
+	ErrBridgeNotHalted,
+	ErrInvalidPercentages,
+	ErrInvalidRewards,
+}
+
+// FailureCode returns the code of the error with which an embedded contract rejected a call,
+// 0 if it's not one of the known errors, in which case only its reason describes it.
+func FailureCode(err error) uint32 {
+	for index, known := range failureCodes {
+		if errors.Is(err, known) {
+			return uint32(index + 1)
+		}
+	}
+	return 0
+}
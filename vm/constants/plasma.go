@@ -40,6 +40,8 @@ const (
 
 	// MaxDataLength defines limit of account-block data to 16Kb
 	MaxDataLength = 1024 * 16
+	// MaxDataLengthAfterPayloadSpork raises the limit to 64Kb once the payload limits spork is enforced
+	MaxDataLengthAfterPayloadSpork = 1024 * 64
 
 	// MaxPlasmaForAccountBlock defines max available plasma for an account block.
 	MaxPlasmaForAccountBlock = MaxFusionPlasmaForAccount
@@ -0,0 +1,211 @@
+package bridge
+
+import (
+	"sync"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+	"github.com/zenon-network/go-zenon/wallet"
+)
+
+const (
+	// Momentums after which a wrap request is signed again if the published signature wasn't applied.
+	resignDelay = 30
+)
+
+// Orchestrator signs the wrap requests of the bridge contract with the registered Signer once they
+// reached finality, and publishes the signatures with its key pair. It does nothing until both are set.
+type Orchestrator interface {
+	Init() error
+	Start() error
+	Stop() error
+
+	// RegisterSigner sets the signer of the wrap requests, nil unregisters it.
+	RegisterSigner(signer Signer)
+	// SetKeyPair sets the account which publishes the signatures, it needs fused plasma.
+	SetKeyPair(keyPair *wallet.KeyPair)
+}
+
+type orchestrator struct {
+	log common.Logger
+
+	chain       chain.Chain
+	supervisor  *vm.Supervisor
+	broadcaster protocol.Broadcaster
+
+	// protects signer and keyPair
+	lock    sync.Mutex
+	signer  Signer
+	keyPair *wallet.KeyPair
+
+	// wrap requests with published signatures, by the height until which they aren't signed again
+	pending map[types.Hash]uint64
+
+	changes chan struct{}
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+func NewOrchestrator(chain chain.Chain, consensus consensus.Consensus, broadcaster protocol.Broadcaster) Orchestrator {
+	return &orchestrator{
+		log:         common.EmbeddedLogger.New("contract", "bridge", "submodule", "orchestrator"),
+		chain:       chain,
+		supervisor:  vm.NewSupervisor(chain, consensus),
+		broadcaster: broadcaster,
+		pending:     make(map[types.Hash]uint64),
+		changes:     make(chan struct{}, 1),
+	}
+}
+
+func (o *orchestrator) Init() error {
+	return nil
+}
+func (o *orchestrator) Start() error {
+	o.closed = make(chan struct{})
+	o.chain.Register(o)
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		o.loop()
+	}()
+	return nil
+}
+func (o *orchestrator) Stop() error {
+	o.chain.UnRegister(o)
+	close(o.closed)
+	o.wg.Wait()
+	return nil
+}
+
+func (o *orchestrator) RegisterSigner(signer Signer) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.signer = signer
+}
+func (o *orchestrator) SetKeyPair(keyPair *wallet.KeyPair) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.keyPair = keyPair
+}
+
+func (o *orchestrator) InsertMomentum(*nom.DetailedMomentum) {
+	select {
+	case o.changes <- struct{}{}:
+	default:
+	}
+}
+func (o *orchestrator) DeleteMomentum(*nom.DetailedMomentum) {
+}
+
+func (o *orchestrator) loop() {
+	defer common.RecoverStack()
+	for {
+		select {
+		case <-o.closed:
+			return
+		case <-o.changes:
+			o.process()
+		}
+	}
+}
+
+func (o *orchestrator) process() {
+	o.lock.Lock()
+	signer, keyPair := o.signer, o.keyPair
+	o.lock.Unlock()
+	if signer == nil || keyPair == nil {
+		return
+	}
+	if o.broadcaster.SyncInfo().State != protocol.SyncDone {
+		return
+	}
+
+	momentumStore := o.chain.GetFrontierMomentumStore()
+	frontier, err := momentumStore.GetFrontierMomentum()
+	if err != nil {
+		o.log.Error("failed to get frontier momentum", "reason", err)
+		return
+	}
+	context := vm_context.NewAccountContext(momentumStore, o.chain.GetFrontierAccountStore(types.BridgeContract), nil)
+	bridgeInfo, orchestratorInfo, err := implementation.CanPerformAction(context)
+	if err != nil {
+		o.log.Debug("bridge can't perform actions", "reason", err)
+		return
+	}
+	requests, err := definition.GetWrapTokenRequests(context.Storage())
+	if err != nil {
+		o.log.Error("failed to get wrap requests", "reason", err)
+		return
+	}
+
+	for id, height := range o.pending {
+		if height < frontier.Height {
+			delete(o.pending, id)
+		}
+	}
+	for _, request := range requests {
+		if request.Signature != "" || o.pending[request.Id] != 0 {
+			continue
+		}
+		if frontier.Height < request.CreationMomentumHeight+uint64(orchestratorInfo.ConfirmationsToFinality) {
+			continue
+		}
+		if err := o.signWrapRequest(context, signer, keyPair, bridgeInfo, request); err != nil {
+			o.log.Error("failed to sign wrap request", "id", request.Id, "reason", err)
+			continue
+		}
+		o.pending[request.Id] = frontier.Height + resignDelay
+	}
+}
+
+func (o *orchestrator) signWrapRequest(context vm_context.AccountVmContext, signer Signer, keyPair *wallet.KeyPair, bridgeInfo *definition.BridgeInfoVariable, request *definition.WrapTokenRequest) error {
+	networkInfo, err := definition.GetNetworkInfoVariable(context.Storage(), request.NetworkClass, request.ChainId)
+	if err != nil {
+		return err
+	}
+	contractAddress := ecommon.HexToAddress(networkInfo.ContractAddress)
+	message, err := implementation.GetWrapTokenRequestMessage(request, &contractAddress)
+	if err != nil {
+		return err
+	}
+
+	signature, err := signer.Sign(&SignRequest{
+		Method:  definition.UpdateWrapRequestMethodName,
+		Id:      request.Id,
+		Message: message,
+	})
+	if err != nil {
+		return err
+	}
+	// don't spend plasma on signatures which the contract rejects
+	if _, err := implementation.CheckECDSASignature(message, bridgeInfo.DecompressedTssECDSAPubKey, signature); err != nil {
+		return err
+	}
+
+	data, err := definition.ABIBridge.PackMethod(definition.UpdateWrapRequestMethodName, request.Id, signature)
+	if err != nil {
+		return err
+	}
+	transaction, err := o.supervisor.GenerateFromTemplate(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   keyPair.Address,
+		ToAddress: types.BridgeContract,
+		Data:      data,
+	}, keyPair.Signer)
+	if err != nil {
+		return err
+	}
+	o.broadcaster.CreateAccountBlock(transaction)
+	o.log.Info("published wrap request signature", "id", request.Id, "hash", transaction.Block.Hash)
+	return nil
+}
@@ -0,0 +1,119 @@
+package bridge
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+const (
+	remoteSignerTimeout = 30 * time.Second
+)
+
+// SignRequest describes a message of the bridge contract which must be signed with the TSS key.
+type SignRequest struct {
+	Method  string     `json:"method"`  // contract method which verifies the signature, see definition.UpdateWrapRequestMethodName
+	Id      types.Hash `json:"id"`      // id of the request the signature is for
+	Message []byte     `json:"message"` // hash to sign, as computed by the contract
+}
+
+// Signer signs the messages of the bridge contract with the TSS key. The signature is the base64 encoding
+// of the 65 bytes [R || S || V] ECDSA signature, as verified by implementation.CheckECDSASignature.
+// Orchestrators register a Signer with the node, be it a local key, a remote HSM or a TSS coordinator.
+type Signer interface {
+	Sign(request *SignRequest) (string, error)
+}
+
+// SignerFunc adapts a signing callback to the Signer interface.
+type SignerFunc func(request *SignRequest) (string, error)
+
+func (f SignerFunc) Sign(request *SignRequest) (string, error) {
+	return f(request)
+}
+
+type localSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewLocalSigner signs with a TSS key held by the node, it's meant for test networks.
+func NewLocalSigner(key *ecdsa.PrivateKey) Signer {
+	return &localSigner{key: key}
+}
+
+// NewLocalSignerFromFile loads the hex encoded TSS key from file.
+func NewLocalSignerFromFile(file string) (Signer, error) {
+	key, err := crypto.LoadECDSA(file)
+	if err != nil {
+		return nil, errors.Errorf("failed to load TSS key. Reason: %v", err)
+	}
+	return NewLocalSigner(key), nil
+}
+
+func (s *localSigner) Sign(request *SignRequest) (string, error) {
+	signature, err := crypto.Sign(request.Message, s.key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+type remoteSigner struct {
+	url    string
+	client *http.Client
+}
+
+type remoteSignRequest struct {
+	Method  string     `json:"method"`
+	Id      types.Hash `json:"id"`
+	Message string     `json:"message"` // hex encoded
+}
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+	Error     string `json:"error"`
+}
+
+// NewRemoteSigner posts the requests as JSON objects to url, which answers with the signature or an error.
+// It lets a HSM or a TSS coordinator sign without the orchestrator racing the node state.
+func NewRemoteSigner(url string) Signer {
+	return &remoteSigner{
+		url:    url,
+		client: &http.Client{Timeout: remoteSignerTimeout},
+	}
+}
+
+func (s *remoteSigner) Sign(request *SignRequest) (string, error) {
+	body, err := json.Marshal(&remoteSignRequest{
+		Method:  request.Method,
+		Id:      request.Id,
+		Message: hex.EncodeToString(request.Message),
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	response := new(remoteSignResponse)
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return "", errors.Errorf("failed to decode the response of the remote signer. Reason: %v", err)
+	}
+	if response.Error != "" {
+		return "", errors.Errorf("remote signer failed. Reason: %v", response.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("remote signer failed with status %v", resp.Status)
+	}
+	return response.Signature, nil
+}
@@ -138,6 +138,47 @@ const (
 			{"name":"estimatedMomentumTime","type":"uint32"}
 		]},
 
+		{"type":"event","name":"NetworkSet","inputs":[
+			{"name":"networkClass","type":"uint32","indexed":true},
+			{"name":"chainId","type":"uint32","indexed":true},
+			{"name":"administrator","type":"address","indexed":true},
+			{"name":"name","type":"string"},
+			{"name":"contractAddress","type":"string"},
+			{"name":"metadata","type":"string"}
+		]},
+		{"type":"event","name":"NetworkRemoved","inputs":[
+			{"name":"networkClass","type":"uint32","indexed":true},
+			{"name":"chainId","type":"uint32","indexed":true},
+			{"name":"administrator","type":"address","indexed":true}
+		]},
+		{"type":"event","name":"NetworkMetadataSet","inputs":[
+			{"name":"networkClass","type":"uint32","indexed":true},
+			{"name":"chainId","type":"uint32","indexed":true},
+			{"name":"administrator","type":"address","indexed":true},
+			{"name":"metadata","type":"string"}
+		]},
+		{"type":"event","name":"TokenPairSet","inputs":[
+			{"name":"networkClass","type":"uint32","indexed":true},
+			{"name":"chainId","type":"uint32","indexed":true},
+			{"name":"administrator","type":"address","indexed":true},
+			{"name":"tokenStandard","type":"tokenStandard"},
+			{"name":"tokenAddress","type":"string"},
+			{"name":"bridgeable","type":"bool"},
+			{"name":"redeemable","type":"bool"},
+			{"name":"owned","type":"bool"},
+			{"name":"minAmount","type":"uint256"},
+			{"name":"feePercentage","type":"uint32"},
+			{"name":"redeemDelay","type":"uint32"},
+			{"name":"metadata","type":"string"}
+		]},
+		{"type":"event","name":"TokenPairRemoved","inputs":[
+			{"name":"networkClass","type":"uint32","indexed":true},
+			{"name":"chainId","type":"uint32","indexed":true},
+			{"name":"administrator","type":"address","indexed":true},
+			{"name":"tokenStandard","type":"tokenStandard"},
+			{"name":"tokenAddress","type":"string"}
+		]},
+
 		{"type":"variable","name":"wrapRequest","inputs":[
 			{"name":"networkClass","type":"uint32"},
 			{"name":"chainId", "type":"uint32"},
@@ -231,6 +272,12 @@ const (
 	SetNetworkMetadataMethodName = "SetNetworkMetadata"
 	SetBridgeMetadataMethodName  = "SetBridgeMetadata"
 
+	NetworkSetEventName         = "NetworkSet"
+	NetworkRemovedEventName     = "NetworkRemoved"
+	NetworkMetadataSetEventName = "NetworkMetadataSet"
+	TokenPairSetEventName       = "TokenPairSet"
+	TokenPairRemovedEventName   = "TokenPairRemoved"
+
 	requestPairVariableName   = "requestPair"
 	wrapRequestVariableName   = "wrapRequest"
 	unwrapRequestVariableName = "unwrapRequest"
@@ -48,6 +48,26 @@ const (
 		{"type":"function","name":"DenyProxyUnlock","inputs":[]},
 		{"type":"function","name":"AllowProxyUnlock","inputs":[]},
 
+		{"type":"event","name":"HtlcCreated","inputs":[
+			{"name":"id","type":"hash","indexed":true},
+			{"name":"timeLocked","type":"address","indexed":true},
+			{"name":"hashLocked","type":"address","indexed":true},
+			{"name":"tokenStandard","type":"tokenStandard"},
+			{"name":"amount","type":"uint256"},
+			{"name":"expirationTime","type":"int64"},
+			{"name":"hashType","type":"uint8"},
+			{"name":"hashLock","type":"bytes"}
+		]},
+		{"type":"event","name":"HtlcUnlocked","inputs":[
+			{"name":"id","type":"hash","indexed":true},
+			{"name":"hashLocked","type":"address","indexed":true},
+			{"name":"preimage","type":"bytes"}
+		]},
+		{"type":"event","name":"HtlcReclaimed","inputs":[
+			{"name":"id","type":"hash","indexed":true},
+			{"name":"timeLocked","type":"address","indexed":true}
+		]},
+
 		{"type":"variable","name":"htlcProxyUnlockInfo","inputs":[
 			{"name":"allowed","type":"bool"}
 		]}
@@ -60,6 +80,10 @@ const (
 	DenyHtlcProxyUnlockMethodName  = "DenyProxyUnlock"
 	AllowHtlcProxyUnlockMethodName = "AllowProxyUnlock"
 
+	HtlcCreatedEventName   = "HtlcCreated"
+	HtlcUnlockedEventName  = "HtlcUnlocked"
+	HtlcReclaimedEventName = "HtlcReclaimed"
+
 	// re: reclaim vs revoke
 	// some other embedded contracts have "revoke" methods
 	// indicating an action which invalidates an entry and returns funds
@@ -20,6 +20,11 @@ const (
 		{"type":"function","name":"Burn","inputs":[]},
 		{"type":"function","name":"UpdateToken","inputs":[{"name":"tokenStandard","type":"tokenStandard"},{"name":"owner","type":"address"},{"name":"isMintable","type":"bool"},{"name":"isBurnable","type":"bool"}]},
 
+		{"type":"event","name":"TokenIssued","inputs":[{"name":"tokenStandard","type":"tokenStandard","indexed":true},{"name":"owner","type":"address","indexed":true},{"name":"totalSupply","type":"uint256"},{"name":"maxSupply","type":"uint256"}]},
+		{"type":"event","name":"TokenMinted","inputs":[{"name":"tokenStandard","type":"tokenStandard","indexed":true},{"name":"receiveAddress","type":"address","indexed":true},{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"TokenBurned","inputs":[{"name":"tokenStandard","type":"tokenStandard","indexed":true},{"name":"burner","type":"address","indexed":true},{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"TokenUpdated","inputs":[{"name":"tokenStandard","type":"tokenStandard","indexed":true},{"name":"owner","type":"address","indexed":true},{"name":"isMintable","type":"bool"},{"name":"isBurnable","type":"bool"}]},
+
 		{"type":"variable","name":"tokenInfo","inputs":[
 			{"name":"owner","type":"address"},
 			{"name":"tokenName","type":"string"},
@@ -38,6 +43,11 @@ const (
 	BurnMethodName        = "Burn"
 	UpdateTokenMethodName = "UpdateToken"
 
+	TokenIssuedEventName  = "TokenIssued"
+	TokenMintedEventName  = "TokenMinted"
+	TokenBurnedEventName  = "TokenBurned"
+	TokenUpdatedEventName = "TokenUpdated"
+
 	tokenInfoVariableName = "tokenInfo"
 )
 
@@ -211,8 +211,28 @@ func getOrigin() map[types.Address]*embeddedImplementation {
 // - returns constants.ErrContractDoesntExist in case the address doesn't link to a valid embedded contract
 // - returns constants.ErrContractMethodNotFound if the method doesn't exist
 func GetEmbeddedMethod(context vm_context.AccountVmContext, address types.Address, abiSelector []byte) (Method, error) {
+	_, method, err := getEmbeddedMethod(context, address, abiSelector)
+	return method, err
+}
+
+// GetEmbeddedMethodName returns the name of the method found by GetEmbeddedMethod, with the same errors.
+func GetEmbeddedMethodName(context vm_context.AccountVmContext, address types.Address, abiSelector []byte) (string, error) {
+	abiMethod, _, err := getEmbeddedMethod(context, address, abiSelector)
+	if err != nil {
+		return "", err
+	}
+	return abiMethod.Name, nil
+}
+
+// GetEmbeddedAbiMethod returns the ABI of the method found by GetEmbeddedMethod, with the same errors.
+func GetEmbeddedAbiMethod(context vm_context.AccountVmContext, address types.Address, abiSelector []byte) (*abi.Method, error) {
+	abiMethod, _, err := getEmbeddedMethod(context, address, abiSelector)
+	return abiMethod, err
+}
+
+func getEmbeddedMethod(context vm_context.AccountVmContext, address types.Address, abiSelector []byte) (*abi.Method, Method, error) {
 	if !types.IsEmbeddedAddress(address) {
-		return nil, constants.ErrNotContractAddress
+		return nil, nil, constants.ErrNotContractAddress
 	}
 
 	var contractsMap map[types.Address]*embeddedImplementation
@@ -234,11 +254,11 @@ func GetEmbeddedMethod(context vm_context.AccountVmContext, address types.Addres
 			// method must exist in the map
 			c, ok := p.m[method.Name]
 			if ok {
-				return c, nil
+				return method, c, nil
 			}
 		}
-		return nil, constants.ErrContractMethodNotFound
+		return nil, nil, constants.ErrContractMethodNotFound
 	} else {
-		return nil, constants.ErrContractDoesntExist
+		return nil, nil, constants.ErrContractDoesntExist
 	}
 }
@@ -555,6 +555,8 @@ func (p *SetNetworkMethod) ReceiveBlock(context vm_context.AccountVmContext, sen
 		return nil, err
 	}
 	common.DealWithErr(networkInfoVariable.Save(context.Storage()))
+	emitEvent(context, definition.ABIBridge, definition.NetworkSetEventName, param.NetworkClass, param.ChainId, sendBlock.Address,
+		param.Name, param.ContractAddress, param.Metadata)
 	return nil, nil
 }
 
@@ -612,6 +614,7 @@ func (p *RemoveNetworkMethod) ReceiveBlock(context vm_context.AccountVmContext,
 		return nil, err
 	}
 	common.DealWithErr(networkInfoVariable.Delete(context.Storage()))
+	emitEvent(context, definition.ABIBridge, definition.NetworkRemovedEventName, param.NetworkClass, param.ChainId, sendBlock.Address)
 	return nil, nil
 }
 
@@ -674,6 +677,7 @@ func (p *SetNetworkMetadataMethod) ReceiveBlock(context vm_context.AccountVmCont
 		return nil, err
 	}
 	common.DealWithErr(networkInfoVariable.Save(context.Storage()))
+	emitEvent(context, definition.ABIBridge, definition.NetworkMetadataSetEventName, param.NetworkClass, param.ChainId, sendBlock.Address, param.Metadata)
 	return nil, nil
 }
 
@@ -802,6 +806,9 @@ func (p *SetTokenPairMethod) ReceiveBlock(context vm_context.AccountVmContext, s
 		return nil, err
 	}
 	common.DealWithErr(networkInfoVariable.Save(context.Storage()))
+	emitEvent(context, definition.ABIBridge, definition.TokenPairSetEventName, param.NetworkClass, param.ChainId, sendBlock.Address,
+		tokenPair.TokenStandard, tokenPair.TokenAddress, tokenPair.Bridgeable, tokenPair.Redeemable, tokenPair.Owned,
+		tokenPair.MinAmount, tokenPair.FeePercentage, tokenPair.RedeemDelay, tokenPair.Metadata)
 	return nil, nil
 }
 
@@ -877,6 +884,8 @@ func (p *RemoveTokenPairMethod) ReceiveBlock(context vm_context.AccountVmContext
 		return nil, err
 	}
 	common.DealWithErr(networkInfoVariable.Save(context.Storage()))
+	emitEvent(context, definition.ABIBridge, definition.TokenPairRemovedEventName, param.NetworkClass, param.ChainId, sendBlock.Address,
+		param.TokenStandard, param.TokenAddress)
 	return nil, nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/abi"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/vm/vm_context"
@@ -81,6 +82,17 @@ func checkAndPerformUpdateEpoch(context vm_context.AccountVmContext, epoch *defi
 	return epoch.Save(context.Storage())
 }
 
+// emitEvent adds a log of the contract to the receive-block, see abi.ABIContract.PackEvent.
+// The log is dropped if the method fails afterwards.
+func emitEvent(context vm_context.AccountVmContext, contract abi.ABIContract, name string, args ...interface{}) {
+	topics, data := contract.PackEventPanic(name, args...)
+	context.AddLog(&nom.Log{
+		Address: *context.Address(),
+		Topics:  topics,
+		Data:    data,
+	})
+}
+
 // CollectRewardMethod is a common embedded.method used to issue tokens to users based on RewardDeposit object.
 // When issuing rewards, the embedded adds the respected value in the RewardDeposit object in the DB and afterwards,
 // the users will call this method to receive the tokens.
@@ -115,6 +115,8 @@ func (p *CreateHtlcMethod) ReceiveBlock(context vm_context.AccountVmContext, sen
 
 	common.DealWithErr(htlcInfo.Save(context.Storage()))
 	htlcLog.Debug("created", "htlcInfo", htlcInfo)
+	emitEvent(context, definition.ABIHtlc, definition.HtlcCreatedEventName, htlcInfo.Id, htlcInfo.TimeLocked, htlcInfo.HashLocked,
+		htlcInfo.TokenStandard, htlcInfo.Amount, htlcInfo.ExpirationTime, htlcInfo.HashType, htlcInfo.HashLock)
 	return nil, nil
 }
 
@@ -174,6 +176,7 @@ func (p *ReclaimHtlcMethod) ReceiveBlock(context vm_context.AccountVmContext, se
 
 	common.DealWithErr(htlcInfo.Delete(context.Storage()))
 	htlcLog.Debug("reclaimed", "htlcInfo", htlcInfo)
+	emitEvent(context, definition.ABIHtlc, definition.HtlcReclaimedEventName, htlcInfo.Id, htlcInfo.TimeLocked)
 
 	return []*nom.AccountBlock{
 		{
@@ -264,6 +267,7 @@ func (p *UnlockHtlcMethod) ReceiveBlock(context vm_context.AccountVmContext, sen
 
 	common.DealWithErr(htlcInfo.Delete(context.Storage()))
 	htlcLog.Debug("unlocked", "htlcInfo", htlcInfo, "preimage", hex.EncodeToString(param.Preimage))
+	emitEvent(context, definition.ABIHtlc, definition.HtlcUnlockedEventName, htlcInfo.Id, htlcInfo.HashLocked, param.Preimage)
 
 	return []*nom.AccountBlock{
 		{
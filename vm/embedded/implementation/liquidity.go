@@ -3,6 +3,7 @@ package implementation
 import (
 	"bytes"
 	eabi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/inconshreveable/log15"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/crypto"
@@ -522,6 +523,95 @@ func (method *UpdateRewardEmbeddedLiquidityMethod) ReceiveBlock(context vm_conte
 	return updateLiquidityStakeRewards(context)
 }
 
+// LiquidityAdditionalRewardAvailable reports whether the balance of the liquidity contract covers the additional
+// rewards set by the administrator, which are then distributed on top of the emission of the epoch.
+func LiquidityAdditionalRewardAvailable(liquidityInfo *definition.LiquidityInfo, znnBalance, qsrBalance *big.Int) bool {
+	return znnBalance.Cmp(liquidityInfo.ZnnReward) != -1 && qsrBalance.Cmp(liquidityInfo.QsrReward) != -1
+}
+
+// LiquidityStakeReward is the reward of a liquidity stake entry for an epoch.
+type LiquidityStakeReward struct {
+	Entry *definition.LiquidityStakeEntry
+	Znn   *big.Int
+	Qsr   *big.Int
+}
+
+// GetLiquidityStakeRewards splits the rewards of the epoch [startTime, endTime) between the entries, first by the
+// percentages of the token tuples and then by the weighted stake of each entry. Entries of tokens without a tuple,
+// or of tuples without stake in the epoch, get no reward and are left out.
+func GetLiquidityStakeRewards(tokenTuples []definition.TokenTuple, entries []*definition.LiquidityStakeEntry, totalZnnAmount, totalQsrAmount *big.Int, startTime, endTime int64) []*LiquidityStakeReward {
+	return liquidityStakeRewards(nil, tokenTuples, entries, totalZnnAmount, totalQsrAmount, startTime, endTime)
+}
+
+// liquidityStakeRewards is GetLiquidityStakeRewards which logs the split between the token tuples, if log isn't nil.
+func liquidityStakeRewards(log log15.Logger, tokenTuples []definition.TokenTuple, entries []*definition.LiquidityStakeEntry, totalZnnAmount, totalQsrAmount *big.Int, startTime, endTime int64) []*LiquidityStakeReward {
+	znnRewards := make(map[string]*big.Int)
+	qsrRewards := make(map[string]*big.Int)
+
+	for _, token := range tokenTuples {
+		totalZnn := new(big.Int).Set(totalZnnAmount)
+		totalQsr := new(big.Int).Set(totalQsrAmount)
+		znnReward := totalZnn.Mul(totalZnn, big.NewInt(int64(token.ZnnPercentage)))
+		znnReward = znnReward.Div(znnReward, big.NewInt(int64(constants.LiquidityZnnTotalPercentages)))
+		znnRewards[token.TokenStandard] = znnReward
+		qsrReward := totalQsr.Mul(totalQsr, big.NewInt(int64(token.QsrPercentage)))
+		qsrReward = qsrReward.Div(qsrReward, big.NewInt(int64(constants.LiquidityQsrTotalPercentages)))
+		qsrRewards[token.TokenStandard] = qsrReward
+
+		if log != nil {
+			log.Debug("calculating percentages for each token", "token-standard", token.TokenStandard, "znn-percentage", token.ZnnPercentage, "qsr-percentage", token.QsrPercentage, "znn-rewards", znnRewards[token.TokenStandard], "qsr-rewards", qsrRewards[token.TokenStandard])
+		}
+	}
+
+	cumulatedStake := make(map[string]*big.Int)
+	for _, stakeEntry := range entries {
+		weightedLiquidityStake := getWeightedLiquidityStake(stakeEntry, startTime, endTime)
+		currentCumulatedStake, ok := cumulatedStake[stakeEntry.TokenStandard.String()]
+		if !ok {
+			currentCumulatedStake = big.NewInt(0)
+		}
+		currentCumulatedStake.Add(currentCumulatedStake, weightedLiquidityStake)
+		cumulatedStake[stakeEntry.TokenStandard.String()] = currentCumulatedStake
+	}
+
+	rewards := make([]*LiquidityStakeReward, 0, len(entries))
+	for _, stakeEntry := range entries {
+		znnReward, ok := znnRewards[stakeEntry.TokenStandard.String()]
+		if !ok {
+			continue
+		}
+		qsrReward, ok := qsrRewards[stakeEntry.TokenStandard.String()]
+		if !ok {
+			continue
+		}
+
+		znnAmount := new(big.Int).Set(znnReward)
+		qsrAmount := new(big.Int).Set(qsrReward)
+
+		totalCumulatedStake, ok := cumulatedStake[stakeEntry.TokenStandard.String()]
+		if !ok {
+			continue
+		}
+		if totalCumulatedStake.Sign() == 0 {
+			continue
+		}
+
+		weight := getWeightedLiquidityStake(stakeEntry, startTime, endTime)
+		znnAmount.Mul(znnAmount, weight)
+		znnAmount.Quo(znnAmount, totalCumulatedStake)
+
+		qsrAmount.Mul(qsrAmount, weight)
+		qsrAmount.Quo(qsrAmount, totalCumulatedStake)
+
+		rewards = append(rewards, &LiquidityStakeReward{
+			Entry: stakeEntry,
+			Znn:   znnAmount,
+			Qsr:   qsrAmount,
+		})
+	}
+	return rewards
+}
+
 // weighted liquidity stake amount over time
 func getWeightedLiquidityStake(info *definition.LiquidityStakeEntry, startTime, endTime int64) *big.Int {
 	startTime = common.MaxInt64(startTime, info.StartTime)
@@ -579,7 +669,7 @@ func computeLiquidityStakeRewardsForEpoch(context vm_context.AccountVmContext, e
 		return nil, err
 	}
 	blocks := make([]*nom.AccountBlock, 0)
-	if znnBalance.Cmp(liquidityInfo.ZnnReward) != -1 && qsrBalance.Cmp(liquidityInfo.QsrReward) != -1 {
+	if LiquidityAdditionalRewardAvailable(liquidityInfo, znnBalance, qsrBalance) {
 		if liquidityInfo.ZnnReward.Sign() > 0 {
 			totalZnnAmount = totalZnnAmount.Add(totalZnnAmount, liquidityInfo.ZnnReward)
 			znnBurnBlock := &nom.AccountBlock{
@@ -613,71 +703,19 @@ func computeLiquidityStakeRewardsForEpoch(context vm_context.AccountVmContext, e
 
 	liquidityLog.Debug("updating liquidity stake reward", "epoch", epoch, "znn-total-amount", totalZnnAmount, "qsr-total-amount", totalQsrAmount)
 
-	znnRewards := make(map[string]*big.Int)
-	qsrRewards := make(map[string]*big.Int)
-
-	for _, token := range liquidityInfo.TokenTuples {
-		totalZnn := new(big.Int).Set(totalZnnAmount)
-		totalQsr := new(big.Int).Set(totalQsrAmount)
-		znnReward := totalZnn.Mul(totalZnn, big.NewInt(int64(token.ZnnPercentage)))
-		znnReward = znnReward.Div(znnReward, big.NewInt(int64(constants.LiquidityZnnTotalPercentages)))
-		znnRewards[token.TokenStandard] = znnReward
-		qsrReward := totalQsr.Mul(totalQsr, big.NewInt(int64(token.QsrPercentage)))
-		qsrReward = qsrReward.Div(qsrReward, big.NewInt(int64(constants.LiquidityQsrTotalPercentages)))
-		qsrRewards[token.TokenStandard] = qsrReward
-
-		liquidityLog.Debug("calculating percentages for each token", "epoch", epoch, "token-standard", token.TokenStandard, "znn-percentage", token.ZnnPercentage, "qsr-percentage", token.QsrPercentage, "znn-rewards", znnRewards[token.TokenStandard], "qsr-rewards", qsrRewards[token.TokenStandard])
-	}
 	liquidityStakeList := definition.GetAllLiquidityStakeEntries(context.Storage())
-
-	cumulatedStake := make(map[string]*big.Int)
-	for _, stakeEntry := range liquidityStakeList {
-		weightedLiquidityStake := getWeightedLiquidityStake(stakeEntry, startTime.Unix(), endTime.Unix())
-		currentCumulatedStake, ok := cumulatedStake[stakeEntry.TokenStandard.String()]
-		if !ok {
-			currentCumulatedStake = big.NewInt(0)
-		}
-		currentCumulatedStake.Add(currentCumulatedStake, weightedLiquidityStake)
-		cumulatedStake[stakeEntry.TokenStandard.String()] = currentCumulatedStake
-	}
-
-	for _, stakeEntry := range liquidityStakeList {
-		znnReward, ok := znnRewards[stakeEntry.TokenStandard.String()]
-		if !ok {
-			continue
-		}
-		qsrReward, ok := qsrRewards[stakeEntry.TokenStandard.String()]
-		if !ok {
-			continue
-		}
-
-		znnAmount := new(big.Int).Set(znnReward)
-		qsrAmount := new(big.Int).Set(qsrReward)
-
-		totalCumulatedStake, ok := cumulatedStake[stakeEntry.TokenStandard.String()]
-		if !ok {
-			continue
-		}
-		if totalCumulatedStake.Sign() == 0 {
-			continue
-		}
-
-		weight := getWeightedLiquidityStake(stakeEntry, startTime.Unix(), endTime.Unix())
-		znnAmount.Mul(znnAmount, weight)
-		znnAmount.Quo(znnAmount, totalCumulatedStake)
-
-		qsrAmount.Mul(qsrAmount, weight)
-		qsrAmount.Quo(qsrAmount, totalCumulatedStake)
-
+	rewards := liquidityStakeRewards(liquidityLog.New("epoch", epoch), liquidityInfo.TokenTuples, liquidityStakeList, totalZnnAmount, totalQsrAmount, startTime.Unix(), endTime.Unix())
+	for _, reward := range rewards {
+		stakeEntry := reward.Entry
 		addReward(context, epoch, definition.RewardDeposit{
 			Address: &stakeEntry.StakeAddress,
-			Znn:     znnAmount,
-			Qsr:     qsrAmount,
+			Znn:     reward.Znn,
+			Qsr:     reward.Qsr,
 		})
 
-		totalZnnFunds = totalZnnFunds.Add(totalZnnFunds, znnAmount)
-		totalQsrFunds = totalQsrFunds.Add(totalQsrFunds, qsrAmount)
-		liquidityLog.Debug("updating liquidity stake reward", "id", stakeEntry.Id, "stake-address", stakeEntry.StakeAddress, "token-standard", stakeEntry.TokenStandard, "znn-amount", znnAmount, "qsr-amount", qsrAmount)
+		totalZnnFunds = totalZnnFunds.Add(totalZnnFunds, reward.Znn)
+		totalQsrFunds = totalQsrFunds.Add(totalQsrFunds, reward.Qsr)
+		liquidityLog.Debug("updating liquidity stake reward", "id", stakeEntry.Id, "stake-address", stakeEntry.StakeAddress, "token-standard", stakeEntry.TokenStandard, "znn-amount", reward.Znn, "qsr-amount", reward.Qsr)
 		if stakeEntry.RevokeTime != 0 && stakeEntry.RevokeTime < endTime.Unix() {
 			common.DealWithErr(stakeEntry.Delete(context.Storage()))
 		}
@@ -21,8 +21,8 @@ var (
 	pillarLog = common.EmbeddedLogger.New("contract", "pillar")
 )
 
-// Performs basic static checks to determine if a pillar name is valid
-func checkPillarNameStatic(name string) error {
+// CheckPillarNameStatic performs basic static checks to determine if a pillar name is valid
+func CheckPillarNameStatic(name string) error {
 	if len(name) == 0 ||
 		len(name) > constants.PillarNameLengthMax {
 		return constants.ErrInvalidName
@@ -65,7 +65,7 @@ func checkPillarPercentages(param *definition.RegisterParam) error {
 // - registers pillar and producing address in DB
 func checkAndRegisterPillar(context vm_context.AccountVmContext, param *definition.RegisterParam, ownerAddress types.Address, pillarType uint8) error {
 	// check pillar param
-	if err := checkPillarNameStatic(param.Name); err != nil {
+	if err := CheckPillarNameStatic(param.Name); err != nil {
 		return err
 	}
 	if err := checkPillarPercentages(param); err != nil {
@@ -153,7 +153,7 @@ func (p *RegisterMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	if err := checkPillarNameStatic(param.Name); err != nil {
+	if err := CheckPillarNameStatic(param.Name); err != nil {
 		return err
 	}
 	if err := checkPillarPercentages(param); err != nil {
@@ -215,7 +215,7 @@ func (p *LegacyRegisterMethod) ValidateSendBlock(block *nom.AccountBlock) error
 		return constants.ErrUnpackError
 	}
 
-	if err := checkPillarNameStatic(param.Name); err != nil {
+	if err := CheckPillarNameStatic(param.Name); err != nil {
 		return err
 	}
 	if err := checkPillarPercentages(&param.RegisterParam); err != nil {
@@ -299,7 +299,7 @@ func (p *RevokeMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	if err := checkPillarNameStatic(*param); err != nil {
+	if err := CheckPillarNameStatic(*param); err != nil {
 		return err
 	}
 	if block.Amount.Sign() != 0 {
@@ -590,7 +590,7 @@ func (p *UpdatePillarMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	if err := checkPillarNameStatic(param.Name); err != nil {
+	if err := CheckPillarNameStatic(param.Name); err != nil {
 		return err
 	}
 	if err := checkPillarPercentages(param); err != nil {
@@ -675,7 +675,7 @@ func (p *DelegateMethod) ValidateSendBlock(block *nom.AccountBlock) error {
 		return constants.ErrUnpackError
 	}
 
-	if err := checkPillarNameStatic(*param); err != nil {
+	if err := CheckPillarNameStatic(*param); err != nil {
 		return err
 	}
 	if block.Amount.Sign() != 0 {
@@ -172,8 +172,8 @@ func (p *UpdateEmbeddedStakeMethod) ReceiveBlock(context vm_context.AccountVmCon
 	return nil, err
 }
 
-// weighted stake amount over time
-func getWeightedStake(info *definition.StakeInfo, startTime, endTime int64) *big.Int {
+// GetWeightedStake returns the weighted stake amount of the entry over [startTime, endTime)
+func GetWeightedStake(info *definition.StakeInfo, startTime, endTime int64) *big.Int {
 	startTime = common.MaxInt64(startTime, info.StartTime)
 	if info.RevokeTime != 0 {
 		endTime = common.MinInt64(endTime, info.RevokeTime)
@@ -195,7 +195,7 @@ func computeStakeRewardsForEpoch(context vm_context.AccountVmContext, epoch uint
 	totalAmount := constants.StakeQsrRewardPerEpoch(epoch)
 
 	err := definition.IterateStakeEntries(context.Storage(), func(stakeInfo *definition.StakeInfo) error {
-		cumulatedStake.Add(cumulatedStake, getWeightedStake(stakeInfo, startTime.Unix(), endTime.Unix()))
+		cumulatedStake.Add(cumulatedStake, GetWeightedStake(stakeInfo, startTime.Unix(), endTime.Unix()))
 		return nil
 	})
 	if err != nil {
@@ -209,7 +209,7 @@ func computeStakeRewardsForEpoch(context vm_context.AccountVmContext, epoch uint
 
 	err = definition.IterateStakeEntries(context.Storage(), func(stakeInfo *definition.StakeInfo) error {
 		reward := new(big.Int).Set(totalAmount)
-		reward.Mul(reward, getWeightedStake(stakeInfo, startTime.Unix(), endTime.Unix()))
+		reward.Mul(reward, GetWeightedStake(stakeInfo, startTime.Unix(), endTime.Unix()))
 		reward.Quo(reward, cumulatedStake)
 
 		addReward(context, epoch, definition.RewardDeposit{
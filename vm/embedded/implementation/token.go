@@ -141,6 +141,7 @@ func (p *IssueMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBloc
 	// add minted token to TokenContract
 	context.AddBalance(&tokenStandard, param.TotalSupply)
 	tokenLog.Debug("issued ZTS", "token", tokenInfo)
+	emitEvent(context, definition.ABIToken, definition.TokenIssuedEventName, tokenStandard, sendBlock.Address, param.TotalSupply, param.MaxSupply)
 	return []*nom.AccountBlock{
 		{
 			Address:       types.TokenContract,
@@ -217,6 +218,7 @@ func (p *MintMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock
 	// add minted token to TokenContract
 	context.AddBalance(&param.TokenStandard, param.Amount)
 	tokenLog.Debug("minted ZTS", "token", tokenInfo, "minted-amount", param.Amount, "to-address", param.ReceiveAddress)
+	emitEvent(context, definition.ABIToken, definition.TokenMintedEventName, param.TokenStandard, param.ReceiveAddress, param.Amount)
 	var data []byte
 	if types.IsEmbeddedAddress(param.ReceiveAddress) {
 		data, err = definition.ABICommon.PackMethod(definition.DonateMethodName)
@@ -282,6 +284,7 @@ func (p *BurnMethod) ReceiveBlock(context vm_context.AccountVmContext, sendBlock
 	// remove received token from TokenContract
 	context.SubBalance(&sendBlock.TokenStandard, sendBlock.Amount)
 	tokenLog.Debug("burned ZTS", "token", tokenInfo, "burned-amount", sendBlock.Amount)
+	emitEvent(context, definition.ABIToken, definition.TokenBurnedEventName, sendBlock.TokenStandard, sendBlock.Address, sendBlock.Amount)
 	return nil, nil
 }
 
@@ -347,5 +350,6 @@ func (p *UpdateTokenMethod) ReceiveBlock(context vm_context.AccountVmContext, se
 
 	tokenLog.Debug("updated ZTS", "token", tokenInfo)
 	common.DealWithErr(tokenInfo.Save(context.Storage()))
+	emitEvent(context, definition.ABIToken, definition.TokenUpdatedEventName, param.TokenStandard, tokenInfo.Owner, tokenInfo.IsMintable, tokenInfo.IsBurnable)
 	return nil, nil
 }
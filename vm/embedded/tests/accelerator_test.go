@@ -3114,3 +3114,209 @@ t=2001-09-09T04:47:20+0000 lvl=info msg="received donation" module=embedded cont
 	z.InsertMomentumsTo(60*6*2 + 2)
 	z.InsertMomentumsTo(60*6*4 + 2)
 }
+
+// Filter and sort projects
+//   - by owner, status and case-insensitive search in name & description
+//   - sorted by requested funds instead of last update
+//   - unknown sort fields are rejected
+func TestAccelerator_GetProjects(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	activateAccelerator(z)
+	acceleratorAPI := embedded.NewAcceleratorApi(z)
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.AcceleratorContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        constants.ProjectCreationAmount,
+		Data: definition.ABIAccelerator.PackMethodPanic(definition.CreateProjectMethodName,
+			"Test Project 1",   //param.Name
+			"TEST DESCRIPTION", //param.Description
+			"test.com",         //param.Url
+			big.NewInt(500),    //param.ZnnFundsNeeded
+			big.NewInt(1000),   //param.QsrFundsNeeded
+		),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // cemented send block
+	z.InsertNewMomentum() // cemented token-receive-block
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User2.Address,
+		ToAddress:     types.AcceleratorContract,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        constants.ProjectCreationAmount,
+		Data: definition.ABIAccelerator.PackMethodPanic(definition.CreateProjectMethodName,
+			"Wallet",                  //param.Name
+			"a mobile wallet for nom", //param.Description
+			"test.com",                //param.Url
+			big.NewInt(100),           //param.ZnnFundsNeeded
+			big.NewInt(1000),          //param.QsrFundsNeeded
+		),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // cemented send block
+	z.InsertNewMomentum() // cemented token-receive-block
+
+	status := definition.VotingStatus
+	common.Json(acceleratorAPI.GetProjects(&embedded.ProjectFilter{
+		Status: &status,
+		Owner:  &g.User2.Address,
+		Search: "MOBILE",
+	}, 0, 10)).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"id": "3e8ac64692d4782e3ae21cfabc1ca9f1c1ccc936428450b23772a4d013f060c7",
+			"owner": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"name": "Wallet",
+			"description": "a mobile wallet for nom",
+			"url": "test.com",
+			"znnFundsNeeded": "100",
+			"qsrFundsNeeded": "1000",
+			"creationTimestamp": 1000000220,
+			"lastUpdateTimestamp": 1000000220,
+			"status": 0,
+			"phaseIds": [],
+			"votes": {
+				"id": "3e8ac64692d4782e3ae21cfabc1ca9f1c1ccc936428450b23772a4d013f060c7",
+				"total": 0,
+				"yes": 0,
+				"no": 0
+			},
+			"phases": []
+		}
+	]
+}`)
+	common.Json(acceleratorAPI.GetProjects(&embedded.ProjectFilter{Owner: &g.User3.Address}, 0, 10)).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+	common.Json(acceleratorAPI.GetProjects(&embedded.ProjectFilter{SortBy: "znnFunds"}, 0, 1)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"id": "ba0b4b88096c0d46d4aab138ea061a333ec30afaee056ea78867d5a6c0c5f3e3",
+			"owner": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"name": "Test Project 1",
+			"description": "TEST DESCRIPTION",
+			"url": "test.com",
+			"znnFundsNeeded": "500",
+			"qsrFundsNeeded": "1000",
+			"creationTimestamp": 1000000200,
+			"lastUpdateTimestamp": 1000000200,
+			"status": 0,
+			"phaseIds": [],
+			"votes": {
+				"id": "ba0b4b88096c0d46d4aab138ea061a333ec30afaee056ea78867d5a6c0c5f3e3",
+				"total": 0,
+				"yes": 0,
+				"no": 0
+			},
+			"phases": []
+		}
+	]
+}`)
+	common.Json(acceleratorAPI.GetProjects(&embedded.ProjectFilter{SortBy: "votes"}, 0, 10)).Error(t, api.ErrUnknownSortField)
+}
+
+func TestAccelerator_GetVotesByPillar(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	activateAccelerator(z)
+	governanceAPI := embedded.NewGovernanceApi(z)
+
+	for _, name := range []string{"Test Project 1", "Test Project 2"} {
+		defer z.CallContract(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     types.AcceleratorContract,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        constants.ProjectCreationAmount,
+			Data: definition.ABIAccelerator.PackMethodPanic(definition.CreateProjectMethodName,
+				name,               //param.Name
+				"TEST DESCRIPTION", //param.Description
+				"test.com",         //param.Url
+				big.NewInt(100),    //param.ZnnFundsNeeded
+				big.NewInt(1000),   //param.QsrFundsNeeded
+			),
+		}).Error(t, nil)
+		z.InsertNewMomentum() // cemented send block
+		z.InsertNewMomentum() // cemented token-receive-block
+	}
+
+	projectList, err := embedded.NewAcceleratorApi(z).GetProjects(&embedded.ProjectFilter{SortBy: "creation", Ascending: true}, 0, 10)
+	common.FailIfErr(t, err)
+	for index, vote := range []uint8{definition.VoteYes, definition.VoteAbstain} {
+		defer z.CallContract(&nom.AccountBlock{
+			Address:   g.Pillar1.Address,
+			ToAddress: types.AcceleratorContract,
+			Data: definition.ABIAccelerator.PackMethodPanic(definition.VoteByNameMethodName,
+				projectList.List[index].Id,
+				g.Pillar1Name,
+				vote,
+			),
+		}).Error(t, nil)
+		z.InsertNewMomentum() // cemented send block
+		z.InsertNewMomentum() // cemented token-receive-block
+	}
+
+	common.Json(governanceAPI.GetVotesByPillar(g.Pillar1Name, 0, 10)).Equals(t, `
+{
+	"count": 2,
+	"participation": {
+		"total": 2,
+		"yes": 1,
+		"no": 0,
+		"abstain": 1,
+		"notVoted": 0
+	},
+	"list": [
+		{
+			"type": "project",
+			"id": "b5293add5e177096dba0eaf030550156955f4d098aa60028449d377e2b81ab70",
+			"projectId": "b5293add5e177096dba0eaf030550156955f4d098aa60028449d377e2b81ab70",
+			"name": "Test Project 2",
+			"status": 0,
+			"creationTimestamp": 1000000220,
+			"vote": 2
+		},
+		{
+			"type": "project",
+			"id": "c24a5a6166c8948aba23d68aa39e206fc1410138ad218500749b75e2ae92d730",
+			"projectId": "c24a5a6166c8948aba23d68aa39e206fc1410138ad218500749b75e2ae92d730",
+			"name": "Test Project 1",
+			"status": 0,
+			"creationTimestamp": 1000000200,
+			"vote": 0
+		}
+	],
+	"guardianVotes": []
+}`)
+	common.Json(governanceAPI.GetVotesByPillar(g.Pillar2Name, 1, 1)).Equals(t, `
+{
+	"count": 2,
+	"participation": {
+		"total": 2,
+		"yes": 0,
+		"no": 0,
+		"abstain": 0,
+		"notVoted": 2
+	},
+	"list": [
+		{
+			"type": "project",
+			"id": "c24a5a6166c8948aba23d68aa39e206fc1410138ad218500749b75e2ae92d730",
+			"projectId": "c24a5a6166c8948aba23d68aa39e206fc1410138ad218500749b75e2ae92d730",
+			"name": "Test Project 1",
+			"status": 0,
+			"creationTimestamp": 1000000200,
+			"vote": null
+		}
+	],
+	"guardianVotes": []
+}`)
+	_, err = governanceAPI.GetVotesByPillar("missing-pillar", 0, 10)
+	common.Expect(t, err, constants.ErrDataNonExistent)
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/momentum"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Test the cache of the momentum stores
+//   - test momentums, account-blocks and token infos read twice
+//     -> served from the cache the second time
+//   - test the frontier of an older store
+//     -> its own frontier
+//   - test a rollback and a new momentum at the same height
+//     -> the new momentum is read, the store created before the rollback keeps its own
+func TestChainCache(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	statsApi := api.NewStatsApi(z, nil)
+	defer z.StopPanic()
+
+	simpleSendSetup(t, z)
+	z.InsertMomentumsTo(10)
+	var err error
+
+	var before *momentum.CacheStats
+	for i := 0; i < 2; i += 1 {
+		if i == 1 {
+			before, err = statsApi.CacheStats()
+			common.FailIfErr(t, err)
+		}
+		momentumStore := z.Chain().GetFrontierMomentumStore()
+		send, err := momentumStore.GetMomentumByHeight(2)
+		common.FailIfErr(t, err)
+		block, err := momentumStore.GetAccountBlockByHash(send.Content[0].Hash)
+		common.FailIfErr(t, err)
+		common.Expect(t, block.Hash, send.Content[0].Hash)
+		token, err := momentumStore.GetTokenInfoByTs(types.ZnnTokenStandard)
+		common.FailIfErr(t, err)
+		common.Expect(t, token.TokenSymbol, "ZNN")
+	}
+	after, err := statsApi.CacheStats()
+	common.FailIfErr(t, err)
+	common.Expect(t, after.Momentums.Hits-before.Momentums.Hits, 1)
+	common.Expect(t, after.AccountBlocks.Hits-before.AccountBlocks.Hits, 1)
+	common.Expect(t, after.TokenInfos.Hits-before.TokenInfos.Hits, 1)
+	common.Expect(t, after.Momentums.Misses+after.AccountBlocks.Misses+after.TokenInfos.Misses,
+		before.Momentums.Misses+before.AccountBlocks.Misses+before.TokenInfos.Misses)
+
+	old, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(5)
+	common.FailIfErr(t, err)
+	_, err = z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(9)
+	common.FailIfErr(t, err)
+	oldFrontier, err := z.Chain().GetMomentumStore(old.Identifier()).GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.Expect(t, oldFrontier.Hash, old.Hash)
+
+	previousStore := z.Chain().GetFrontierMomentumStore()
+	previous, err := previousStore.GetMomentumByHeight(9)
+	common.FailIfErr(t, err)
+	rollbackTo, err := previousStore.GetMomentumByHeight(8)
+	common.FailIfErr(t, err)
+	insert := z.Chain().AcquireInsert("test rollback")
+	common.FailIfErr(t, z.Chain().RollbackTo(insert, rollbackTo.Identifier()))
+	insert.Unlock()
+	// the new momentum confirms a send, it differs from the rolled back one
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	replaced, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(9)
+	common.FailIfErr(t, err)
+	if replaced.Hash == previous.Hash {
+		t.Fatalf("momentum 9 wasn't replaced by the rollback")
+	}
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.Expect(t, frontier.Hash, replaced.Hash)
+	kept, err := previousStore.GetMomentumByHeight(9)
+	common.FailIfErr(t, err)
+	common.Expect(t, kept.Hash, previous.Hash)
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/zenon-network/go-zenon/chain/export"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Export the account-chain of user2 after receiving from user1 and sending to user3
+//   - test that receives have the amount, token and counterparty of the send block
+//     -> amounts are in display units
+//   - test unknown formats
+//     -> error
+func TestExport_AccountChain(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	simpleSendSetup(t, z)
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User2.Address,
+		ToAddress:     g.User3.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(15 * g.Zexp / 10),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	buffer := new(bytes.Buffer)
+	common.FailIfErr(t, export.AccountChain(z.Chain(), g.User2.Address, export.FormatCSV, buffer))
+	common.ExpectString(t, buffer.String(), `
+height,hash,blockType,address,counterparty,fromBlockHash,tokenStandard,tokenSymbol,amount,momentumHeight,momentumTimestamp
+1,57b6b7c6edb82b38ec4c992d99c84bf8016f03bf0727ff9daa811d2e862fa77a,genesisReceive,z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx,z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f,0000000000000000000000000000000000000000000000000000000000000000,,,0,1,2001-09-09T01:46:40Z
+2,f845e19928c2452b96c88ff49b60d5e3fa7632a86006a951f80fe8a22dbeb810,userReceive,z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx,z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz,6e9bf5f7512931a4b74d3d1dd20b0f8105a006b1ae059e1535f935e283f2a66c,zts1znnxxxxxxxxxxxxx9z4ulx,ZNN,100,3,2001-09-09T01:47:00Z
+3,a4a185a156ff50b3f18a6b201f1b76a6976c377ed4cbf85ad126ea74c2ac9c88,userSend,z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx,z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac,0000000000000000000000000000000000000000000000000000000000000000,zts1znnxxxxxxxxxxxxx9z4ulx,ZNN,1.5,4,2001-09-09T01:47:10Z
+`)
+
+	buffer.Reset()
+	common.FailIfErr(t, export.AccountChain(z.Chain(), g.User2.Address, export.FormatJSON, buffer))
+	common.ExpectString(t, buffer.String(), `
+[
+{"height":1,"hash":"57b6b7c6edb82b38ec4c992d99c84bf8016f03bf0727ff9daa811d2e862fa77a","blockType":"genesisReceive","address":"z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx","counterparty":"z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f","fromBlockHash":"0000000000000000000000000000000000000000000000000000000000000000","tokenStandard":"","tokenSymbol":"","amount":"0","momentumHeight":1,"momentumTimestamp":"2001-09-09T01:46:40Z"},
+{"height":2,"hash":"f845e19928c2452b96c88ff49b60d5e3fa7632a86006a951f80fe8a22dbeb810","blockType":"userReceive","address":"z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx","counterparty":"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz","fromBlockHash":"6e9bf5f7512931a4b74d3d1dd20b0f8105a006b1ae059e1535f935e283f2a66c","tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","tokenSymbol":"ZNN","amount":"100","momentumHeight":3,"momentumTimestamp":"2001-09-09T01:47:00Z"},
+{"height":3,"hash":"a4a185a156ff50b3f18a6b201f1b76a6976c377ed4cbf85ad126ea74c2ac9c88","blockType":"userSend","address":"z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx","counterparty":"z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac","fromBlockHash":"0000000000000000000000000000000000000000000000000000000000000000","tokenStandard":"zts1znnxxxxxxxxxxxxx9z4ulx","tokenSymbol":"ZNN","amount":"1.5","momentumHeight":4,"momentumTimestamp":"2001-09-09T01:47:10Z"}
+]
+`)
+
+	common.Expect(t, export.AccountChain(z.Chain(), g.User2.Address, "xml", buffer), `unknown export format "xml", expected csv or json`)
+}
+
+// decodeExportedMessages returns the fields of the size-prefixed protobuf messages of data, one line per
+// message with the fields in order as number=value.
+func decodeExportedMessages(t *testing.T, data []byte) []string {
+	messages := make([]string, 0)
+	for len(data) != 0 {
+		size, n := protowire.ConsumeVarint(data)
+		common.ExpectTrue(t, n > 0 && int(size) <= len(data)-n)
+		message := data[n : n+int(size)]
+		data = data[n+int(size):]
+
+		fields := make([]string, 0)
+		for len(message) != 0 {
+			number, typ, n := protowire.ConsumeTag(message)
+			message = message[n:]
+			switch typ {
+			case protowire.VarintType:
+				value, n := protowire.ConsumeVarint(message)
+				message = message[n:]
+				fields = append(fields, fmt.Sprintf("%v=%v", number, value))
+			case protowire.BytesType:
+				value, n := protowire.ConsumeString(message)
+				message = message[n:]
+				fields = append(fields, fmt.Sprintf("%v=%v", number, value))
+			default:
+				t.Fatalf("unexpected wire type %v", typ)
+			}
+		}
+		messages = append(messages, strings.Join(fields, " "))
+	}
+	return messages
+}
+
+// Export the momentums 3 to 4, which confirm a fuse of user1 and its receive by the plasma contract
+//   - test that the calls of the embedded contracts are decoded
+//   - test the parquet framing, the magic and the footer length, the content is decoded by TestParquetWriter
+//   - test invalid ranges and unknown formats
+//     -> error
+func TestExport_Ledger(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	z.InsertNewMomentum()
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	momentums := new(bytes.Buffer)
+	accountBlocks := new(bytes.Buffer)
+	common.FailIfErr(t, export.Ledger(z.Chain(), 3, 4, export.FormatProtobuf, momentums, accountBlocks, nil))
+	common.ExpectString(t, "\n"+strings.Join(decodeExportedMessages(t, momentums.Bytes()), "\n")+"\n", `
+1=3 2=ea7ef9ea9b3a1ab96d7b2365f07cb8f4ba5b59b6782586ed63467ee60c87e6ad 3=3e0e29241b309558dd792503cd81fdabe547aad3124a7dd5dac180e864306212 4=1000000020 5=z1qqc8hqalt8je538849rf78nhgek30axq8h0g69 6=1
+1=4 2=5908faecda1c2a980332373d590f6c603fa01777664bf029373d7e82ea6e0338 3=ea7ef9ea9b3a1ab96d7b2365f07cb8f4ba5b59b6782586ed63467ee60c87e6ad 4=1000000030 5=z1qz8v73ea2vy2rrlq7skssngu8cm8mknjjkr2ju 6=1
+`)
+	common.ExpectString(t, "\n"+strings.Join(decodeExportedMessages(t, accountBlocks.Bytes()), "\n")+"\n", `
+1=3 2=1000000020 3=bcb8d946a3d843d8bede4331263844687be3a5e127a5d6cabcee0bd79bd1a759 4=2 5=userSend 6=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz 7=z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp 8=0000000000000000000000000000000000000000000000000000000000000000 10=zts1qsrxxxxxxxxxxxxxmrhjll 11=1000000000 12=5ac942e8000000000000000000000000001ab7ebf370a9e344e377c9c5e0d47206bfbc93 13=Fuse 14=[{"name":"address","type":"address","value":"z1qqdt06lnwz57x38rwlyutcx5wgrtl0ynkfe3kv"}] 15=52500 16=52500 17=52500
+1=4 2=1000000030 3=c9a8a10ed6ed4466b20c71c408c9bd9041b6e0c686a2f84052b201a1e8d408f5 4=2 5=contractReceive 6=z1qxemdeddedxplasmaxxxxxxxxxxxxxxxxsctrp 7=z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f 8=bcb8d946a3d843d8bede4331263844687be3a5e127a5d6cabcee0bd79bd1a759 11=0 12=0000000000000001
+`)
+
+	momentums.Reset()
+	accountBlocks.Reset()
+	common.FailIfErr(t, export.Ledger(z.Chain(), 0, 0, export.FormatParquet, momentums, accountBlocks, nil))
+	for _, data := range [][]byte{momentums.Bytes(), accountBlocks.Bytes()} {
+		common.ExpectTrue(t, bytes.HasPrefix(data, []byte("PAR1")) && bytes.HasSuffix(data, []byte("PAR1")))
+		footer := binary.LittleEndian.Uint32(data[len(data)-8:])
+		common.ExpectTrue(t, int(footer) < len(data)-12)
+	}
+
+	common.Expect(t, export.Ledger(z.Chain(), 5, 3, export.FormatParquet, momentums, accountBlocks, nil), "invalid range 5 to 3, the frontier momentum is at height 4")
+	common.Expect(t, export.Ledger(z.Chain(), 1, 0, "csv", momentums, accountBlocks, nil), `unknown export format "csv", expected parquet or protobuf`)
+}
@@ -0,0 +1,134 @@
+// Package tests holds the tests of the embedded contracts, which run on a mock node started from the genesis of
+// chain/genesis/mock. Its Harness can be imported by the tests of other packages, like the ones of a proposed
+// change of an embedded contract, so they don't have to copy the scaffolding.
+//
+// The tests are written for the shortened time windows of UseTestConstants, which they call from TestMain.
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embeddedabi"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// UseTestConstants shortens the time windows of the contracts, like the sentinel lock and the fusion expiration,
+// to the values the tests of the package are written for. It changes the constants of the whole process, so it's
+// meant to be called from TestMain before any node starts:
+//
+//	func TestMain(m *testing.M) {
+//		tests.UseTestConstants()
+//		os.Exit(m.Run())
+//	}
+func UseTestConstants() {
+	constants.SentinelLockTimeWindow = 40   // 40 momentums
+	constants.SentinelRevokeTimeWindow = 20 // 20 momentums
+	constants.RewardTimeLimit = 0           // 0 seconds
+	constants.UpdateMinNumMomentums = 360   // exactly one hour
+	constants.FuseExpiration = 100
+	constants.StakeTimeUnitSec = 60 * 60
+	constants.StakeTimeMinSec = constants.StakeTimeUnitSec * 1
+	constants.StakeTimeMaxSec = constants.StakeTimeUnitSec * 12
+}
+
+// Harness is a mock node for the tests of the embedded contracts. The blocks can be signed by the accounts of
+// chain/genesis/mock and the momentums are only inserted when asked, one momentum confirms the blocks sent to
+// a contract and the next one the receive blocks of the contract.
+type Harness struct {
+	mock.MockZenon
+	t *testing.T
+}
+
+// NewHarness starts a mock node which is stopped at the end of the test.
+func NewHarness(t *testing.T) *Harness {
+	z := mock.NewMockZenon(t)
+	t.Cleanup(z.StopPanic)
+	return &Harness{MockZenon: z, t: t}
+}
+
+// Height returns the height of the frontier momentum.
+func (h *Harness) Height() uint64 {
+	return h.Chain().GetFrontierMomentumStore().Identifier().Height
+}
+
+// InsertMomentums inserts count momentums.
+func (h *Harness) InsertMomentums(count int) {
+	for i := 0; i < count; i += 1 {
+		h.InsertNewMomentum()
+	}
+}
+
+// Call sends a call of the method of an embedded contract from the address, without funds, see CallWithFunds.
+func (h *Harness) Call(from, contract types.Address, method string, args ...interface{}) *common.Expecter {
+	return h.CallWithFunds(from, contract, types.ZeroTokenStandard, nil, method, args...)
+}
+
+// CallWithFunds sends a call of the method of an embedded contract from the address with an amount of the token.
+// The returned Expecter holds the error of the call once a momentum confirmed the receive block of the
+// contract, so it's usually checked with a defer:
+//
+//	defer h.Call(g.User1.Address, types.PlasmaContract, definition.CancelFuseMethodName, id).Error(t, nil)
+//	h.InsertMomentums(2)
+func (h *Harness) CallWithFunds(from, contract types.Address, zts types.ZenonTokenStandard, amount *big.Int, method string, args ...interface{}) *common.Expecter {
+	if _, err := embeddedabi.Method(contract, method); err != nil {
+		h.t.Fatalf("can't call %v of %v: %v", method, contract, err)
+	}
+	contractAbi := embeddedabi.ABIs[contract]
+	if _, ok := contractAbi.Methods[method]; !ok {
+		contractAbi = definition.ABICommon
+	}
+	data, err := contractAbi.PackMethod(method, args...)
+	common.FailIfErr(h.t, err)
+
+	template := &nom.AccountBlock{
+		Address:   from,
+		ToAddress: contract,
+		Data:      data,
+	}
+	if amount != nil {
+		template.TokenStandard = zts
+		template.Amount = amount
+	}
+	return h.CallContract(template)
+}
+
+// Send sends an amount of the token between two accounts, the receiver has to receive it, see ReceiveAll.
+func (h *Harness) Send(from, to types.Address, zts types.ZenonTokenStandard, amount *big.Int) *nom.AccountBlock {
+	return h.InsertSendBlock(&nom.AccountBlock{
+		Address:       from,
+		ToAddress:     to,
+		TokenStandard: zts,
+		Amount:        amount,
+	}, nil, mock.SkipVmChanges)
+}
+
+// ReceiveAll receives the blocks sent to the address which are confirmed by a momentum.
+func (h *Harness) ReceiveAll(address types.Address) {
+	receiveAll(h.t, h.MockZenon, address)
+}
+
+// Balance returns the balance of the token of the address at the frontier momentum.
+func (h *Harness) Balance(address types.Address, zts types.ZenonTokenStandard) *big.Int {
+	balance, err := h.Chain().GetFrontierAccountStore(address).GetBalance(zts)
+	common.FailIfErr(h.t, err)
+	if balance == nil {
+		balance = big.NewInt(0)
+	}
+	return balance
+}
+
+func receiveAll(t common.T, z mock.MockZenon, address types.Address) {
+	ledgerApi := api.NewLedgerApi(z)
+	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(address, 0, 50)
+	common.FailIfErr(t, err)
+	for _, block := range unreceived.List {
+		z.InsertReceiveBlock(block.AccountBlock.Header(), nil, nil, mock.SkipVmChanges)
+	}
+}
@@ -0,0 +1,44 @@
+package tests_test
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/vm/embedded/tests"
+)
+
+func TestMain(m *testing.M) {
+	// set local time to UTC for logging purposes
+	time.Local = time.UTC
+	tests.UseTestConstants()
+	os.Exit(m.Run())
+}
+
+// Use the harness from another package, like the tests of a proposed contract change
+//   - test that user1 fuses qsr for user6
+//     -> user6 gets the plasma
+func TestHarness_Fuse(t *testing.T) {
+	h := tests.NewHarness(t)
+	plasmaApi := embedded.NewPlasmaApi(h)
+
+	h.InsertMomentums(1)
+	defer h.CallWithFunds(g.User1.Address, types.PlasmaContract, types.QsrTokenStandard, big.NewInt(10*g.Zexp),
+		definition.FuseMethodName, g.User6.Address).Error(t, nil)
+	h.InsertMomentums(2)
+
+	common.Expect(t, h.Height(), uint64(4))
+	common.Expect(t, h.Balance(g.User1.Address, types.QsrTokenStandard).String(), big.NewInt(119990*g.Zexp).String())
+	common.Json(plasmaApi.Get(g.User6.Address)).Equals(t, `
+{
+	"currentPlasma": 21000,
+	"maxPlasma": 21000,
+	"qsrAmount": "1000000000"
+}`)
+}
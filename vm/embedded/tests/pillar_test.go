@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 	"time"
@@ -9,6 +10,8 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pillar"
+	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
@@ -729,3 +732,246 @@ t=2001-09-09T02:47:00+0000 lvl=info msg="received donation" module=embedded cont
 	]
 }`)
 }
+
+// Query the produced & expected momentums of pillars over the last epochs
+//   - only the requested number of epochs is aggregated, newest first
+//   - pillars are ordered by name and paginated
+func TestPillar_GetPerformanceHistory(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	pillarApi := embedded.NewPillarApi(z, true)
+
+	z.InsertMomentumsTo(momentumsInHour*3 + 10)
+
+	common.Json(pillarApi.GetPerformanceHistory(2, 0, 2)).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"name": "TEST-pillar-1",
+			"producedMomentums": 240,
+			"expectedMomentums": 240,
+			"epochs": [
+				{
+					"epoch": 2,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				},
+				{
+					"epoch": 1,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				}
+			]
+		},
+		{
+			"name": "TEST-pillar-cool",
+			"producedMomentums": 240,
+			"expectedMomentums": 240,
+			"epochs": [
+				{
+					"epoch": 2,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				},
+				{
+					"epoch": 1,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				}
+			]
+		}
+	]
+}`)
+	common.Json(pillarApi.GetPerformanceHistory(10, 1, 2)).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"name": "TEST-pillar-znn",
+			"producedMomentums": 360,
+			"expectedMomentums": 360,
+			"epochs": [
+				{
+					"epoch": 2,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				},
+				{
+					"epoch": 1,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				},
+				{
+					"epoch": 0,
+					"producedMomentums": 120,
+					"expectedMomentums": 120
+				}
+			]
+		}
+	]
+}`)
+	common.Json(pillarApi.GetPerformanceHistory(10000, 0, 10)).Error(t, api.ErrCountParamTooBig)
+}
+
+// Query the delegators of a pillar and its weight over the epochs
+//   - delegators are ordered by weight, genesis delegations have no delegation momentum
+//   - delegation momentum is the one which confirmed the Delegate receive-block
+//   - weight changes are computed against the previous epoch, newest epoch first
+func TestPillar_GetDelegatorsAndWeightHistory(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	pillarApi := embedded.NewPillarApi(z, true)
+
+	z.InsertMomentumsTo(10 * 30)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PillarContract,
+		Data:          definition.ABIPillars.PackMethodPanic(definition.DelegateMethodName, g.Pillar2Name),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        common.Big0,
+	}).Error(t, nil)
+	z.InsertMomentumsTo(momentumsInHour*3 + 10)
+	waitIndexer(t, z)
+
+	common.Json(pillarApi.GetDelegators(g.Pillar2Name, 0, 10)).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"weight": "1200000000000",
+			"delegationHeight": 302,
+			"delegationTimestamp": 1000003010
+		},
+		{
+			"address": "z1qrs2lpccnsneglhnnfwvlsj0qncnxjnwlfmjac",
+			"weight": "100000000000",
+			"delegationHeight": 0,
+			"delegationTimestamp": 0
+		},
+		{
+			"address": "z1qz8v73ea2vy2rrlq7skssngu8cm8mknjjkr2ju",
+			"weight": "100000000000",
+			"delegationHeight": 0,
+			"delegationTimestamp": 0
+		}
+	]
+}`)
+	common.Json(pillarApi.GetDelegators(g.Pillar1Name, 0, 10)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"weight": "800000000000",
+			"delegationHeight": 0,
+			"delegationTimestamp": 0
+		},
+		{
+			"address": "z1qqq43dyrswfehx9w9td43exflqzcxrt7g6alah",
+			"weight": "100000000000",
+			"delegationHeight": 0,
+			"delegationTimestamp": 0
+		}
+	]
+}`)
+	common.Json(pillarApi.GetWeightHistory(g.Pillar2Name, 0, 10)).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"epoch": 2,
+			"weight": "1400000000000",
+			"weightChange": "0"
+		},
+		{
+			"epoch": 1,
+			"weight": "1400000000000",
+			"weightChange": "1200000000000"
+		},
+		{
+			"epoch": 0,
+			"weight": "200000000000",
+			"weightChange": "200000000000"
+		}
+	]
+}`)
+	common.Json(pillarApi.GetWeightHistory(g.Pillar1Name, 1, 2)).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"epoch": 0,
+			"weight": "2100000000000",
+			"weightChange": "2100000000000"
+		}
+	]
+}`)
+}
+
+// frontierBlock returns the frontier account-block of address, including the ones not confirmed yet.
+func frontierBlock(t *testing.T, z mock.MockZenon, address types.Address) *nom.AccountBlock {
+	block, err := z.Chain().GetFrontierAccountStore(address).Frontier()
+	common.FailIfErr(t, err)
+	return block
+}
+
+// Test the automatic reward collection of the producer
+//   - test no CollectReward is submitted while the uncollected reward is below the threshold
+//   - test it's submitted once the threshold is reached, and not again while it's pending
+//   - test the reward is collected once the CollectReward is received
+func TestPillar_RewardCollection(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	pillarApi := embedded.NewPillarApi(z, true)
+
+	z.InsertMomentumsTo(momentumsInHour*2 + 2)
+	reward, err := pillarApi.GetUncollectedReward(g.Pillar1.Address)
+	common.FailIfErr(t, err)
+	if reward.Znn.Sign() == 0 {
+		t.Fatal("expected an uncollected reward")
+	}
+	previous := frontierBlock(t, z, g.Pillar1.Address)
+
+	z.Producer().SetRewardCollection(&pillar.RewardCollection{
+		ZnnThreshold: new(big.Int).Add(reward.Znn, big.NewInt(1)),
+		Interval:     10 * time.Millisecond,
+	})
+	time.Sleep(100 * time.Millisecond)
+	if frontierBlock(t, z, g.Pillar1.Address).Hash != previous.Hash {
+		t.Fatal("collected a reward below the threshold")
+	}
+
+	z.Producer().SetRewardCollection(&pillar.RewardCollection{
+		ZnnThreshold: reward.Znn,
+		Interval:     10 * time.Millisecond,
+	})
+	var collect *nom.AccountBlock
+	for i := 0; i < 100 && collect == nil; i += 1 {
+		time.Sleep(10 * time.Millisecond)
+		if block := frontierBlock(t, z, g.Pillar1.Address); block.Hash != previous.Hash {
+			collect = block
+		}
+	}
+	if collect == nil {
+		t.Fatal("reward wasn't collected")
+	}
+	if collect.ToAddress != types.PillarContract || !bytes.Equal(collect.Data, definition.ABICommon.PackMethodPanic(definition.CollectRewardMethodName)) {
+		t.Fatalf("unexpected block %v to %v", collect.Hash, collect.ToAddress)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if block := frontierBlock(t, z, g.Pillar1.Address); block.Hash != collect.Hash {
+		t.Fatal("collected the reward again while the previous CollectReward is pending")
+	}
+
+	z.Producer().SetRewardCollection(nil)
+	z.InsertNewMomentum() // cemented send-block
+	z.InsertNewMomentum() // cemented pillar receive-block
+	common.Json(pillarApi.GetUncollectedReward(g.Pillar1.Address)).Equals(t, `
+{
+	"address": "z1qqq43dyrswfehx9w9td43exflqzcxrt7g6alah",
+	"znnAmount": "0",
+	"qsrAmount": "0"
+}`)
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/zenon-network/go-zenon/pow"
 	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/verifier"
 	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon/mock"
@@ -266,6 +268,63 @@ func TestPlasma_rpc(t *testing.T) {
 }`)
 }
 
+// - test plasma.GetRequiredPoWForDraftBlock rpc with a data payload
+// - test plasma.GetRequiredPoWForDraftBlock rpc with an embedded method call
+// - test plasma.GetRequiredPoWForDraftBlock rpc with an invalid embedded method call
+// - test plasma.GetRequiredPoWForDraftBlock rpc with a too big data payload
+func TestPlasma_GetRequiredPoWForDraftBlock(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	plasmaApi := embedded.NewPlasmaApi(z)
+	defer z.StopPanic()
+
+	common.Json(plasmaApi.GetRequiredPoWForDraftBlock(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User6.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(1 * g.Zexp),
+		Data:          make([]byte, 100),
+	})).Equals(t, `
+{
+	"requiredPlasma": 27800,
+	"availablePlasma": 0,
+	"requiredDifficulty": 41700000,
+	"embeddedMethod": ""
+}`)
+	common.Json(plasmaApi.GetRequiredPoWForDraftBlock(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+	})).Equals(t, `
+{
+	"requiredPlasma": 52500,
+	"availablePlasma": 10500000,
+	"requiredDifficulty": 0,
+	"embeddedMethod": "Fuse"
+}`)
+	common.Json(plasmaApi.GetRequiredPoWForDraftBlock(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(1 * g.Zexp),
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+	})).Error(t, constants.ErrInvalidTokenOrAmount)
+	common.Json(plasmaApi.GetRequiredPoWForDraftBlock(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserSend,
+		Address:   g.User1.Address,
+		ToAddress: g.User2.Address,
+		Data:      make([]byte, constants.MaxDataLength+1),
+	})).Error(t, verifier.ErrABDataTooBig)
+	common.Json(plasmaApi.GetRequiredPoWForDraftBlock(&nom.AccountBlock{
+		BlockType: nom.BlockTypeUserReceive,
+		Address:   g.User1.Address,
+	})).Error(t, errors.New("fromBlockHash is zero"))
+}
+
 // - test revoke plasma entry which was in genesis (expiration height = 0)
 // - test that you have an unreceived block
 // - test that the plasma.Get RPC returns 0 now
@@ -619,3 +678,60 @@ t=2001-09-09T01:46:50+0000 lvl=dbug msg="fused new entry" module=embedded contra
 	}).Error(t, constants.ErrDataNonExistent)
 	z.InsertNewMomentum()
 }
+
+// - the genesis entries of user 1 can already be revoked
+// - a new entry is listed once it can be revoked within the window
+func TestPlasma_GetExpiringEntriesByAddress(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	plasmaApi := embedded.NewPlasmaApi(z)
+	defer z.StopPanic()
+	constants.FuseExpiration = 30
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User1.Address),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertMomentumsTo(10)
+
+	common.Json(plasmaApi.GetExpiringEntriesByAddress(g.User1.Address, 10, 0, 10)).Equals(t, `
+{
+	"qsrAmount": "2000000000000",
+	"count": 2,
+	"list": [
+		{
+			"qsrAmount": "1000000000000",
+			"beneficiary": "z1qqfmjdays57w488sta69ykc2ey7r6d0q9wdvtj",
+			"expirationHeight": 0,
+			"id": "0000000000000000000000000000000000000000000000000000000000000000"
+		},
+		{
+			"qsrAmount": "1000000000000",
+			"beneficiary": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"expirationHeight": 0,
+			"id": "117613e734b6cb0fd7b7583f5b0e863a3f0c856cd32fa36f1b60b464d068c5a6"
+		}
+	]
+}`)
+	common.Json(plasmaApi.GetExpiringEntriesByAddress(g.User1.Address, 22, 1, 2)).HideHashes().Equals(t, `
+{
+	"qsrAmount": "2001000000000",
+	"count": 3,
+	"list": [
+		{
+			"qsrAmount": "1000000000",
+			"beneficiary": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"expirationHeight": 32,
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+		}
+	]
+}`)
+	common.Json(plasmaApi.GetExpiringEntriesByAddress(g.User6.Address, 100, 0, 10)).Equals(t, `
+{
+	"qsrAmount": "0",
+	"count": 0,
+	"list": []
+}`)
+}
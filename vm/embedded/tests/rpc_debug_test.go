@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Test TraceAccountBlock
+//   - test with the tracer off
+//     -> error
+//   - test unknown hash
+//     -> null
+//   - test contract receive block of a token issue
+//     -> method, inputs, storage writes and minted amount
+//   - test contract receive block of a failed mint
+//     -> the returned error
+func TestRPCDebug_TraceAccountBlock(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	debugApi := api.NewDebugApi(z)
+	defer z.StopPanic()
+
+	common.Json(debugApi.TraceAccountBlock(types.NewHash([]byte{'1'}))).Error(t, api.ErrTracerDisabled)
+
+	vm.SetTracer(vm.NewTracer(db.NewMemDB()))
+	defer vm.SetTracer(nil)
+
+	issueBlock := z.InsertSendBlock(issue(g.User1.Address, "test.tok3n_na-m3", "TEST", "", big.NewInt(100), big.NewInt(1000), 1, true, true, false), nil, mock.SkipVmChanges)
+	mintBlock := z.InsertSendBlock(mint(g.User1.Address, types.ZnnTokenStandard, big.NewInt(1), g.User1.Address), nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(debugApi.TraceAccountBlock(types.NewHash([]byte{'1'}))).Equals(t, `null`)
+
+	issueReceive, err := ledgerApi.GetReceiveBlockBySendHash(issueBlock.Hash)
+	common.FailIfErr(t, err)
+	common.Json(debugApi.TraceAccountBlock(issueReceive.Block.Hash)).Equals(t, `
+{
+	"blockHash": "4490e85d31840bbd9ac030e76a8a448846337fefbffaff0d954a18ec07434c09",
+	"sendBlockHash": "5e22abb61fc684ef8cc7a92e1a9dc961e361829773f1e5067820c4e4bb27e451",
+	"contract": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+	"method": "IssueToken",
+	"inputs": [
+		{
+			"name": "tokenName",
+			"type": "string",
+			"value": "test.tok3n_na-m3"
+		},
+		{
+			"name": "tokenSymbol",
+			"type": "string",
+			"value": "TEST"
+		},
+		{
+			"name": "tokenDomain",
+			"type": "string",
+			"value": ""
+		},
+		{
+			"name": "totalSupply",
+			"type": "uint256",
+			"value": "100"
+		},
+		{
+			"name": "maxSupply",
+			"type": "uint256",
+			"value": "1000"
+		},
+		{
+			"name": "decimals",
+			"type": "uint8",
+			"value": "1"
+		},
+		{
+			"name": "isMintable",
+			"type": "bool",
+			"value": "true"
+		},
+		{
+			"name": "isBurnable",
+			"type": "bool",
+			"value": "true"
+		},
+		{
+			"name": "isUtility",
+			"type": "bool",
+			"value": "false"
+		}
+	],
+	"reads": [
+		{
+			"key": "017c570ed0809a385c2432",
+			"value": ""
+		}
+	],
+	"writes": [
+		{
+			"key": "017c570ed0809a385c2432",
+			"value": "00000000000000000000000000bbfd629028e53999aa179ac6de460ef72aa76e0000000000000000000000000000000000000000000000000000000000000140000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000001c0000000000000000000000000000000000000000000000000000000000000006400000000000000000000000000000000000000000000000000000000000003e800000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000010746573742e746f6b336e5f6e612d6d3300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000454455354000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+		}
+	],
+	"minted": [
+		{
+			"tokenStandard": "zts103tsa5yqngu9cfpj2m0z9u",
+			"amount": "100"
+		}
+	],
+	"burned": [],
+	"descendantBlocks": [
+		"d838ac9602957e750fc8370460ee5589834b713478a79b61a230c131f4d2ed4b"
+	],
+	"error": ""
+}`)
+
+	mintReceive, err := ledgerApi.GetReceiveBlockBySendHash(mintBlock.Hash)
+	common.FailIfErr(t, err)
+	common.Json(debugApi.TraceAccountBlock(mintReceive.Block.Hash)).Equals(t, `
+{
+	"blockHash": "6c7eb244af531dfaf39e4e2d042bbc1fdea7ed2bcc673431dabc245af8d6a968",
+	"sendBlockHash": "373f9b46501c53c2fa6d49a82ac534cca095bddae01b797e694ac29592d173bf",
+	"contract": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+	"method": "Mint",
+	"inputs": [
+		{
+			"name": "tokenStandard",
+			"type": "tokenStandard",
+			"value": "zts1znnxxxxxxxxxxxxx9z4ulx"
+		},
+		{
+			"name": "amount",
+			"type": "uint256",
+			"value": "1"
+		},
+		{
+			"name": "receiveAddress",
+			"type": "address",
+			"value": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz"
+		}
+	],
+	"reads": [
+		{
+			"key": "0114e66318c6318c6318c6",
+			"value": "00000000000000000000000001b3b6e5adcb4c127ffd198c6318c6318c6318c60000000000000000000000000000000000000000000000000000000000000140000000000000000000000000000000000000000000000000000000000000018000000000000000000000000000000000000000000000000000000000000001c0000000000000000000000000000000000000000000000000000011bc3292b8000000000000000000000000000000000000000000000000003fffffffffffffff0000000000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000000100000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000000a5a656e6f6e20436f696e0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000035a4e4e0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000d7a656e6f6e2e6e6574776f726b00000000000000000000000000000000000000"
+		}
+	],
+	"writes": [],
+	"minted": [],
+	"burned": [],
+	"descendantBlocks": [],
+	"error": "address cannot call this method"
+}`)
+}
+
+// Test CheckMomentumContent
+//   - test the genesis and momentums with account-blocks
+//     -> no anomalies
+//   - test invalid ranges
+//     -> errors
+func TestRPCDebug_CheckMomentumContent(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	debugApi := api.NewDebugApi(z)
+	defer z.StopPanic()
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertMomentumsTo(10)
+
+	common.Json(debugApi.CheckMomentumContent(1, 100)).Equals(t, `
+{
+	"fromHeight": 1,
+	"toHeight": 10,
+	"accountBlocks": 19,
+	"anomalies": []
+}`)
+	common.Json(debugApi.CheckMomentumContent(0, 10)).Error(t, api.ErrHeightParamIsZero)
+	common.Json(debugApi.CheckMomentumContent(10, 9)).Error(t, api.ErrInvalidHeightRange)
+	common.Json(debugApi.CheckMomentumContent(1, 20000)).Error(t, api.ErrCountParamTooBig)
+}
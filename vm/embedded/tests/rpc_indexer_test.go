@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// waitIndexer waits for the background indexer to reach the chain frontier
+func waitIndexer(t *testing.T, z mock.MockZenon) {
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	for i := 0; i < 500; i += 1 {
+		if z.Indexer().Frontier().Height >= frontier.Height {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("indexer did not reach momentum height %v", frontier.Height)
+}
+
+func TestRPCIndexer(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	indexerApi := api.NewIndexerApi(z)
+	defer z.StopPanic()
+
+	for i := 0; i < 3; i += 1 {
+		z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     g.User2.Address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(10 * g.Zexp),
+		}, nil, mock.SkipVmChanges)
+	}
+	z.InsertNewMomentum()
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.PlasmaContract,
+		Data:          definition.ABIPlasma.PackMethodPanic(definition.FuseMethodName, g.User6.Address),
+		TokenStandard: types.QsrTokenStandard,
+		Amount:        big.NewInt(10 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	waitIndexer(t, z)
+
+	common.Json(indexerApi.GetFrontier(), nil).Equals(t, `
+{
+	"hash": "8965bb025a7d8d5282290f7df8fb4603231d5dbcecf6861301410b1686f442c5",
+	"height": 3
+}`)
+	common.Json(indexerApi.GetBlocksByAddressPair(g.User1.Address, g.User2.Address, 0, 2)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"height": 3
+		},
+		{
+			"height": 2
+		}
+	]
+}`)
+	common.Json(indexerApi.GetBlocksByAddressPair(g.User1.Address, g.User2.Address, 1, 2)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"height": 4
+		}
+	]
+}`)
+	common.Json(indexerApi.GetBlocksByEmbeddedMethod(types.PlasmaContract, definition.FuseMethodName, 0, 10)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"height": 5
+		}
+	]
+}`)
+	common.Json(indexerApi.GetBlocksByEmbeddedMethod(types.PlasmaContract, definition.CancelFuseMethodName, 0, 10)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+	common.Json(indexerApi.GetBlocksByEmbeddedMethod(types.PlasmaContract, "unknown", 0, 10)).Error(t, api.ErrUnknownMethodName)
+	common.Json(indexerApi.GetBlocksByEmbeddedMethod(g.User1.Address, definition.FuseMethodName, 0, 10)).Error(t, api.ErrNotEmbeddedContract)
+}
@@ -1,16 +1,22 @@
 package tests
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/zenon-network/go-zenon/chain"
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/rpc/api"
+	rpc "github.com/zenon-network/go-zenon/rpc/server"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 )
 
@@ -21,7 +27,7 @@ func TestRPCLedger_PublishRawTX_internal(t *testing.T) {
 
 	simpleSendSetup(t, z)
 
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 2, 1)).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 2, 1, nil)).Equals(t, `
 {
 	"list": [
 		{
@@ -109,7 +115,7 @@ func TestRPCLedger_PublishRawTX_internal(t *testing.T) {
 	"count": 2,
 	"more": false
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User2.Address, 2, 1)).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User2.Address, 2, 1, nil)).Equals(t, `
 {
 	"list": [
 		{
@@ -198,6 +204,42 @@ func TestRPCLedger_PublishRawTX_internal(t *testing.T) {
 	"more": false
 }`)
 }
+func TestRPCLedger_GetAccountSnapshot(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	simpleSendSetup(t, z)
+	z.InsertMomentumsTo(6)
+
+	momentums, err := ledgerApi.GetMomentumsByHeight(4, 1)
+	common.DealWithErr(err)
+	common.Json(ledgerApi.GetAccountSnapshot(g.User2.Address, momentums.List[0].Hash)).SubJson(&struct {
+		Momentum             *Height           `json:"momentum"`
+		AccountHeight        uint64            `json:"accountHeight"`
+		BalanceMap           map[string]string `json:"balanceMap"`
+		ConfirmationMomentum *Height           `json:"confirmationMomentum"`
+		ContentIndex         int               `json:"contentIndex"`
+	}{}).Equals(t, `
+{
+	"momentum": {
+		"height": 4
+	},
+	"accountHeight": 2,
+	"balanceMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": "8000000000000",
+		"zts1znnxxxxxxxxxxxxx9z4ulx": "810000000000"
+	},
+	"confirmationMomentum": {
+		"height": 3
+	},
+	"contentIndex": 0
+}`)
+
+	z.InsertMomentumsTo(chain.MaxRecentStateDepth + 5)
+	_, err = ledgerApi.GetAccountSnapshot(g.User2.Address, momentums.List[0].Hash)
+	common.ExpectError(t, err, chain.ErrStateTooOld)
+}
 func TestRPCLedger_PublishRawTransaction(t *testing.T) {
 	z := mock.NewMockZenon(t)
 	ledgerApi := api.NewLedgerApi(z)
@@ -228,7 +270,109 @@ func TestRPCLedger_PublishRawTransaction(t *testing.T) {
   "publicKey": "GYyn77OXTL31zPbDBCe/eKir+VCF3hv+LxiOUF3XcJY=",
   "signature": "130sas2Jlmu5AC5SsvJ3I0m31WtvzTKmB3DfoAROQ7kuvx/Hd/g+eZn5rSW5+o5jxV5BJtq1vITs/3lCieGaAw=="
 }`), a))
-	common.FailIfErr(t, ledgerApi.PublishRawTransaction(a))
+	common.FailIfErr(t, ledgerApi.PublishRawTransaction(context.Background(), a))
+}
+
+func TestRPCLedger_PrepareAccountBlockTemplate(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(10)
+
+	template, err := ledgerApi.PrepareAccountBlockTemplate(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserSend,
+		Address:       g.User1.Address,
+		ToAddress:     g.User6.Address,
+		Amount:        big.NewInt(10 * g.Zexp),
+		TokenStandard: types.ZnnTokenStandard,
+	})
+	common.Json(template, err).Equals(t, `
+{
+	"block": {
+		"version": 1,
+		"chainIdentifier": 100,
+		"blockType": 2,
+		"hash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"previousHash": "598fa623dd308bec7163bb375aa7546ec4aced3b71a1c9278709903e69280dbd",
+		"height": 2,
+		"momentumAcknowledged": {
+			"hash": "033c1c35a2c78f48f1e8698c466f8e6b780fba7bc65d94e88ec0ffb918b51228",
+			"height": 10
+		},
+		"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"toAddress": "z1qqdt06lnwz57x38rwlyutcx5wgrtl0ynkfe3kv",
+		"amount": "1000000000",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"fromBlockHash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"descendantBlocks": [],
+		"data": null,
+		"fusedPlasma": 21000,
+		"difficulty": 0,
+		"nonce": "0000000000000000",
+		"basePlasma": 0,
+		"usedPlasma": 0,
+		"changesHash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"publicKey": null,
+		"signature": null
+	},
+	"availablePlasma": 10500000,
+	"basePlasma": 21000,
+	"requiredDifficulty": 0
+}`)
+
+	// sign & publish the template
+	block := template.Block
+	block.Hash = block.ComputeHash()
+	block.PublicKey = g.User1.Public
+	block.Signature = g.User1.Sign(block.Hash.Bytes())
+	common.FailIfErr(t, ledgerApi.PublishRawTransaction(context.Background(), &api.AccountBlock{AccountBlock: *block}))
+	z.InsertNewMomentum()
+	common.Json(ledgerApi.GetFrontierAccountBlock(g.User1.Address)).SubJson(&Height{}).Equals(t, `
+{
+	"height": 2
+}`)
+
+	common.Json(ledgerApi.PrepareAccountBlockTemplate(&nom.AccountBlock{
+		BlockType:     nom.BlockTypeUserReceive,
+		Address:       g.User6.Address,
+		FromBlockHash: block.Hash,
+	})).Equals(t, `
+{
+	"block": {
+		"version": 1,
+		"chainIdentifier": 100,
+		"blockType": 3,
+		"hash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"previousHash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"height": 1,
+		"momentumAcknowledged": {
+			"hash": "8dd90653acba0f3f135c67fa8c0c9fad33ea67c7b97af905f00a136b91afad8a",
+			"height": 11
+		},
+		"address": "z1qqdt06lnwz57x38rwlyutcx5wgrtl0ynkfe3kv",
+		"toAddress": "z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f",
+		"amount": "0",
+		"tokenStandard": "zts1qqqqqqqqqqqqqqqqtq587y",
+		"fromBlockHash": "a06f7c7c117c180b7c542fca2919b86d760694035aa568b9bd15f26a3288cb8f",
+		"descendantBlocks": [],
+		"data": null,
+		"fusedPlasma": 0,
+		"difficulty": 31500000,
+		"nonce": "0000000000000000",
+		"basePlasma": 0,
+		"usedPlasma": 0,
+		"changesHash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"publicKey": null,
+		"signature": null
+	},
+	"availablePlasma": 0,
+	"basePlasma": 21000,
+	"requiredDifficulty": 31500000
+}`)
+	common.Json(ledgerApi.PrepareAccountBlockTemplate(&nom.AccountBlock{
+		BlockType: nom.BlockTypeContractSend,
+		Address:   types.PlasmaContract,
+	})).Error(t, api.ErrTemplateBlockType)
 }
 
 func ExpectGetFrontierAccountBlock(t *testing.T, z mock.MockZenon) {
@@ -240,7 +384,7 @@ func ExpectGetFrontierAccountBlock(t *testing.T, z mock.MockZenon) {
 }
 func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 3, 2)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 3, 2, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -252,7 +396,7 @@ func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 5)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 5, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -273,12 +417,12 @@ func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 20, 5)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 20, 5, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": []
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 10, 5)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 10, 5, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -294,22 +438,22 @@ func ExpectGetAccountBlocksByHeight(t *testing.T, z mock.MockZenon) {
 func ExpectGetAccountBlockByHash(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
 
-	blocks, err := ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 10)
+	blocks, err := ledgerApi.GetAccountBlocksByHeight(g.User1.Address, 1, 10, nil)
 	common.FailIfErr(t, err)
-	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[0].Hash)).SubJson(&Height{}).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[0].Hash, nil)).SubJson(&Height{}).Equals(t, `
 {
 	"height": 1
 }`)
-	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[5].Hash)).SubJson(&Height{}).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlockByHash(blocks.List[5].Hash, nil)).SubJson(&Height{}).Equals(t, `
 {
 	"height": 6
 }`)
-	common.Json(ledgerApi.GetAccountBlockByHash(types.NewHash([]byte{'1'}))).SubJson(&Height{}).Equals(t, `null`)
+	common.Json(ledgerApi.GetAccountBlockByHash(types.NewHash([]byte{'1'}), nil)).SubJson(&Height{}).Equals(t, `null`)
 }
 func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
 
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 0, 2)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 0, 2, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -321,7 +465,7 @@ func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 2)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 2, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -333,7 +477,7 @@ func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 1, 8)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 1, 8, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": [
@@ -348,12 +492,74 @@ func ExpectGetAccountBlocksByPage(t *testing.T, z mock.MockZenon) {
 		}
 	]
 }`)
-	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 8)).SubJson(ListOfHeight()).Equals(t, `
+	common.Json(ledgerApi.GetAccountBlocksByPage(g.User1.Address, 2, 8, nil)).SubJson(ListOfHeight()).Equals(t, `
 {
 	"count": 11,
 	"list": []
 }`)
 }
+func ExpectGetAccountBlocksByCursor(t *testing.T, z mock.MockZenon) {
+	ledgerApi := api.NewLedgerApi(z)
+
+	first, err := ledgerApi.GetAccountBlocksByCursor(g.User1.Address, "", 4, nil)
+	common.Json(first, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 11,
+	"list": [
+		{
+			"height": 11
+		},
+		{
+			"height": 10
+		},
+		{
+			"height": 9
+		},
+		{
+			"height": 8
+		}
+	]
+}`)
+	second, err := ledgerApi.GetAccountBlocksByCursor(g.User1.Address, first.Cursor, 4, nil)
+	common.Json(second, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 11,
+	"list": [
+		{
+			"height": 7
+		},
+		{
+			"height": 6
+		},
+		{
+			"height": 5
+		},
+		{
+			"height": 4
+		}
+	]
+}`)
+	last, err := ledgerApi.GetAccountBlocksByCursor(g.User1.Address, second.Cursor, 4, nil)
+	common.Json(last, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 11,
+	"list": [
+		{
+			"height": 3
+		},
+		{
+			"height": 2
+		},
+		{
+			"height": 1
+		}
+	]
+}`)
+	common.Expect(t, first.More && second.More && !last.More && last.Cursor == "", true)
+
+	common.Json(ledgerApi.GetAccountBlocksByCursor(g.User2.Address, first.Cursor, 4, nil)).Error(t, api.ErrCursorRolledBack)
+	common.Json(ledgerApi.GetAccountBlocksByCursor(g.User1.Address, "not-a-cursor", 4, nil)).Error(t, api.ErrInvalidCursor)
+}
 func ExpectGetAccountInfoByAddress(t *testing.T, z mock.MockZenon) {
 	ledgerApi := api.NewLedgerApi(z)
 
@@ -523,6 +729,7 @@ func TestRPCLedger_UnconfirmedAccountBlocks(t *testing.T) {
 	ExpectGetAccountBlocksByHeight(t, z)
 	//ExpectGetAccountBlockByHash(t, z)
 	ExpectGetAccountBlocksByPage(t, z)
+	ExpectGetAccountBlocksByCursor(t, z)
 	ExpectGetAccountInfoByAddress(t, z)
 	ExpectGetUnreceivedBlocksByAddress(t, z)
 }
@@ -551,6 +758,7 @@ func TestRPCLedger_ConfirmedAccountBlocks(t *testing.T) {
 	ExpectGetAccountBlocksByHeight(t, z)
 	ExpectGetAccountBlockByHash(t, z)
 	ExpectGetAccountBlocksByPage(t, z)
+	ExpectGetAccountBlocksByCursor(t, z)
 	ExpectGetAccountInfoByAddress(t, z)
 	ExpectGetUnreceivedBlocksByAddress(t, z)
 }
@@ -566,6 +774,88 @@ func TestRPCLedger_GetFrontierMomentum(t *testing.T) {
 	"height": 10
 }`)
 }
+
+// rollbackListener records the rollback events.
+type rollbackListener struct {
+	events []*chain.RollbackEvent
+}
+
+func (l *rollbackListener) InsertMomentum(*nom.DetailedMomentum) {}
+func (l *rollbackListener) DeleteMomentum(*nom.DetailedMomentum) {}
+func (l *rollbackListener) Rollback(event *chain.RollbackEvent) {
+	l.events = append(l.events, event)
+}
+
+// Test GetRollbackInfo
+//   - test the rollback is sent to the listeners and counted once it's committed, before any insert
+//   - test the next momentums don't send it again
+func TestRPCLedger_GetRollbackInfo(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(10)
+
+	common.Json(ledgerApi.GetRollbackInfo(), nil).Equals(t, `
+{
+	"count": 0,
+	"maxDepth": 0,
+	"last": null
+}`)
+
+	listener := new(rollbackListener)
+	z.Chain().Register(listener)
+	defer z.Chain().UnRegister(listener)
+	oldFrontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	momentum, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(7)
+	common.FailIfErr(t, err)
+	insert := z.Chain().AcquireInsert("test rollback")
+	common.FailIfErr(t, z.Chain().RollbackTo(insert, momentum.Identifier()))
+	insert.Unlock()
+
+	expected := `
+{
+	"count": 1,
+	"maxDepth": 3,
+	"last": {
+		"oldFrontier": {
+			"height": 10
+		},
+		"commonAncestor": {
+			"height": 7
+		},
+		"depth": 3
+	}
+}`
+	rollbackInfo := &struct {
+		Count    uint64 `json:"count"`
+		MaxDepth uint64 `json:"maxDepth"`
+		Last     struct {
+			OldFrontier    Height `json:"oldFrontier"`
+			CommonAncestor Height `json:"commonAncestor"`
+			Depth          uint64 `json:"depth"`
+		} `json:"last"`
+	}{}
+	common.Json(ledgerApi.GetRollbackInfo(), nil).SubJson(rollbackInfo).Equals(t, expected)
+	common.Expect(t, len(listener.events), 1)
+	common.Json(listener.events[0], nil).Equals(t, fmt.Sprintf(`
+{
+	"oldFrontier": {
+		"hash": "%v",
+		"height": 10
+	},
+	"commonAncestor": {
+		"hash": "%v",
+		"height": 7
+	},
+	"depth": 3
+}`, oldFrontier.Hash, momentum.Hash))
+
+	z.InsertMomentumsTo(12)
+	common.Expect(t, len(listener.events), 1)
+	common.Json(ledgerApi.GetRollbackInfo(), nil).SubJson(rollbackInfo).Equals(t, expected)
+}
+
 func TestRPCLedger_GetMomentumBeforeTime(t *testing.T) {
 	z := mock.NewMockZenon(t)
 	ledgerApi := api.NewLedgerApi(z)
@@ -586,6 +876,72 @@ func TestRPCLedger_GetMomentumBeforeTime(t *testing.T) {
 }`)
 	common.Json(ledgerApi.GetMomentumBeforeTime(genesis.Timestamp.Add(-time.Hour).Unix())).SubJson(&Height{}).Equals(t, `null`)
 }
+
+// Test GetMomentumsByTimestampRange
+//   - test first page of a range, before and after the indexer caught up
+//     -> the same momentums, found with and without the time index
+//   - test range which starts before genesis
+//     -> genesis momentum
+//   - test range after the frontier
+//     -> no momentums
+//   - test empty range
+//     -> error
+func TestRPCLedger_GetMomentumsByTimestampRange(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(20)
+
+	momentums, err := ledgerApi.GetMomentumsByHeight(1, 1)
+	common.DealWithErr(err)
+	genesis := momentums.List[0].Timestamp.Unix()
+
+	expected := `
+{
+	"list": [
+		{
+			"hash": "69d1a6097920cd5698ad8759ee3e434209ff00d67c87d03d64b11048d1d900c9",
+			"height": 3,
+			"timestamp": 1000000020
+		},
+		{
+			"hash": "3e83aeac836943879b0de130ed97dbce3c2649d031489e3b390c52472e22d1fd",
+			"height": 4,
+			"timestamp": 1000000030
+		},
+		{
+			"hash": "335ece3b0ac3a2a23f9ecb3934ebfe814d2d117ea9feb969d72a7241fab02054",
+			"height": 5,
+			"timestamp": 1000000040
+		}
+	],
+	"count": 6,
+	"more": true
+}`
+	common.Json(ledgerApi.GetMomentumsByTimestampRange(genesis+15, genesis+75, 0, 3)).Equals(t, expected)
+	waitIndexer(t, z)
+	common.Json(ledgerApi.GetMomentumsByTimestampRange(genesis+15, genesis+75, 0, 3)).Equals(t, expected)
+
+	common.Json(ledgerApi.GetMomentumsByTimestampRange(genesis-3600, genesis+1, 0, 10)).Equals(t, `
+{
+	"list": [
+		{
+			"hash": "0385d849ee33b94c8783288c148e3ae741c2ecec98b08b3f59d6bcc219168fe5",
+			"height": 1,
+			"timestamp": 1000000000
+		}
+	],
+	"count": 1,
+	"more": false
+}`)
+	common.Json(ledgerApi.GetMomentumsByTimestampRange(genesis+3600, genesis+7200, 0, 10)).Equals(t, `
+{
+	"list": [],
+	"count": 0,
+	"more": false
+}`)
+	common.Json(ledgerApi.GetMomentumsByTimestampRange(genesis+10, genesis+10, 0, 10)).Error(t, api.ErrInvalidTimeRange)
+}
 func TestRPCLedger_GetMomentumByHash(t *testing.T) {
 	z := mock.NewMockZenon(t)
 	ledgerApi := api.NewLedgerApi(z)
@@ -695,13 +1051,70 @@ func TestRPCLedger_GetMomentumsByPage(t *testing.T) {
 	"list": []
 }`)
 }
+func TestRPCLedger_GetMomentumsByCursor(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(10)
+
+	first, err := ledgerApi.GetMomentumsByCursor("", 6)
+	common.Json(first, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 10,
+	"list": [
+		{
+			"height": 10
+		},
+		{
+			"height": 9
+		},
+		{
+			"height": 8
+		},
+		{
+			"height": 7
+		},
+		{
+			"height": 6
+		},
+		{
+			"height": 5
+		}
+	]
+}`)
+	// the next page continues below the cursor even after new momentums
+	z.InsertMomentumsTo(15)
+	last, err := ledgerApi.GetMomentumsByCursor(first.Cursor, 6)
+	common.Json(last, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 15,
+	"list": [
+		{
+			"height": 4
+		},
+		{
+			"height": 3
+		},
+		{
+			"height": 2
+		},
+		{
+			"height": 1
+		}
+	]
+}`)
+	common.Expect(t, last.Cursor, "")
+
+	common.Json(ledgerApi.GetMomentumsByCursor("", 1234)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(ledgerApi.GetMomentumsByCursor(first.Cursor[1:], 6)).Error(t, api.ErrInvalidCursor)
+}
 func TestRPCLedger_GetDetailedMomentumsByHeight(t *testing.T) {
 	z := mock.NewMockZenon(t)
 	ledgerApi := api.NewLedgerApi(z)
 	defer z.StopPanic()
 	z.InsertMomentumsTo(10)
 	z.InsertNewMomentum()
-	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 3)).SubJson(ListOf(func() interface{} {
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 3, nil)).SubJson(ListOf(func() interface{} {
 		return new(struct {
 			AccountBlocks *listToCount `json:"blocks"`
 			Momentum      *struct {
@@ -733,12 +1146,626 @@ func TestRPCLedger_GetDetailedMomentumsByHeight(t *testing.T) {
 	]
 }`)
 }
+func TestRPCLedger_AccountBlockOptions(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+	simpleSendSetup(t, z)
+
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	detailed, err := ledgerApi.GetDetailedMomentumsByHeight(2, frontier.Height-1, nil)
+	common.FailIfErr(t, err)
+	blocks := make([]*api.AccountBlock, 0)
+	for _, momentum := range detailed.List {
+		blocks = append(blocks, momentum.AccountBlocks...)
+	}
+	common.Expect(t, len(blocks) > 0, true)
+
+	// the blocks of the detailed momentums have the same extra info as the ones looked up by hash
+	for _, block := range blocks {
+		common.Expect(t, block.ConfirmationDetail != nil, true)
+		expected, err := json.MarshalIndent(block, "", "\t")
+		common.FailIfErr(t, err)
+		common.Json(ledgerApi.GetAccountBlockByHash(block.Hash, nil)).Equals(t, string(expected))
+	}
+
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(2, 1, &api.AccountBlockOptions{})).SubJson(ListOf(func() interface{} {
+		return new(struct {
+			AccountBlocks []*struct {
+				TokenInfo          *api.Token                          `json:"token"`
+				ConfirmationDetail *api.AccountBlockConfirmationDetail `json:"confirmationDetail"`
+				PairedAccountBlock *api.AccountBlock                   `json:"pairedAccountBlock"`
+			} `json:"blocks"`
+		})
+	})).Equals(t, `
+{
+	"count": 3,
+	"list": [
+		{
+			"blocks": [
+				{
+					"token": null,
+					"confirmationDetail": null,
+					"pairedAccountBlock": null
+				}
+			]
+		}
+	]
+}`)
+	block, err := ledgerApi.GetAccountBlockByHash(blocks[0].Hash, &api.AccountBlockOptions{TokenInfo: true})
+	common.FailIfErr(t, err)
+	common.Expect(t, block.TokenInfo.TokenSymbol, "ZNN")
+	common.Expect(t, block.ConfirmationDetail == nil && block.PairedAccountBlock == nil, true)
+}
 func TestRPCLedger_Errors(t *testing.T) {
 	z := mock.NewMockZenon(t)
 	ledgerApi := api.NewLedgerApi(z)
 	defer z.StopPanic()
 
-	common.Json(ledgerApi.GetDetailedMomentumsByHeight(0, 3)).Error(t, api.ErrHeightParamIsZero)
-	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 1234)).Error(t, api.ErrCountParamTooBig)
-	common.Json(ledgerApi.GetAccountBlocksByPage(types.ZeroAddress, 0, 1234)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(0, 3, nil)).Error(t, api.ErrHeightParamIsZero)
+	common.Json(ledgerApi.GetDetailedMomentumsByHeight(1, 1234, nil)).Error(t, api.ErrCountParamTooBig)
+	common.Json(ledgerApi.GetAccountBlocksByPage(types.ZeroAddress, 0, 1234, nil)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(ledgerApi.GetAccountBlocksByTimeRange(types.ZeroAddress, 0, 1, 0, 1234)).Error(t, api.ErrPageSizeParamTooBig)
+	common.Json(ledgerApi.GetAccountBlocksByTimeRange(types.ZeroAddress, 1, 1, 0, 10)).Error(t, api.ErrInvalidTimeRange)
+}
+
+// Test GetAccountBlocksByTimeRange
+//   - returns the blocks confirmed by momentums in [startTime, endTime)
+//   - pages over the blocks in the time range
+func TestRPCLedger_GetAccountBlocksByTimeRange(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	// account blocks 2, 3 and 4 are confirmed by the momentums at 1000000010, 1000000020 and 1000000030
+	for i := 0; i < 3; i += 1 {
+		z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     g.User2.Address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(10 * g.Zexp),
+		}, nil, mock.SkipVmChanges)
+		z.InsertNewMomentum()
+	}
+
+	common.Json(ledgerApi.GetAccountBlocksByTimeRange(g.User1.Address, 1000000010, 1000000030, 0, 10)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"height": 2
+		},
+		{
+			"height": 3
+		}
+	]
+}`)
+	common.Json(ledgerApi.GetAccountBlocksByTimeRange(g.User1.Address, 1000000010, 1000000030, 1, 1)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"height": 3
+		}
+	]
+}`)
+	common.Json(ledgerApi.GetAccountBlocksByTimeRange(g.User1.Address, 0, 2000000000, 0, 10)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 4,
+	"list": [
+		{
+			"height": 1
+		},
+		{
+			"height": 2
+		},
+		{
+			"height": 3
+		},
+		{
+			"height": 4
+		}
+	]
+}`)
+	common.Json(ledgerApi.GetAccountBlocksByTimeRange(g.User1.Address, 1000000031, 2000000000, 0, 10)).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+}
+
+// Test GetAccountInfoByAddressAtHeight
+//   - returns the balances as of past momentums
+//   - returns null for heights above the frontier
+//   - returns an error for heights more than chain.MaxRecentStateDepth below the frontier
+func TestRPCLedger_GetAccountInfoByAddressAtHeight(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	simpleSendSetup(t, z)
+	z.InsertMomentumsTo(6)
+
+	balances := &struct {
+		AccountHeight  uint64 `json:"accountHeight"`
+		BalanceInfoMap map[string]struct {
+			Balance string `json:"balance"`
+		} `json:"balanceInfoMap"`
+	}{}
+	common.Json(ledgerApi.GetAccountInfoByAddressAtHeight(g.User2.Address, 1)).SubJson(balances).Equals(t, `
+{
+	"accountHeight": 1,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"balance": "8000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"balance": "800000000000"
+		}
+	}
+}`)
+	common.Json(ledgerApi.GetAccountInfoByAddressAtHeight(g.User2.Address, 4)).SubJson(balances).Equals(t, `
+{
+	"accountHeight": 2,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"balance": "8000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"balance": "810000000000"
+		}
+	}
+}`)
+	common.Json(ledgerApi.GetAccountInfoByAddressAtHeight(g.User2.Address, 100)).Equals(t, `null`)
+
+	z.InsertMomentumsTo(chain.MaxRecentStateDepth + 2)
+	_, err := ledgerApi.GetAccountInfoByAddressAtHeight(g.User2.Address, 1)
+	common.ExpectError(t, err, chain.ErrStateTooOld)
+	common.Json(ledgerApi.GetAccountInfoByAddressAtHeight(g.User2.Address, 2)).SubJson(balances).Equals(t, `
+{
+	"accountHeight": 1,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"balance": "8000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"balance": "800000000000"
+		}
+	}
+}`)
+}
+
+// Test GetAccountInfosByAddresses
+//   - returns the account infos and unreceived counts in the order of the addresses
+//   - combines the balances and the unreceived counts of all addresses
+func TestRPCLedger_GetAccountInfosByAddresses(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	for i := 0; i < 2; i += 1 {
+		z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     g.User2.Address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(10 * g.Zexp),
+		}, nil, mock.SkipVmChanges)
+	}
+	z.InsertNewMomentum()
+
+	summary, err := ledgerApi.GetAccountInfosByAddresses([]types.Address{g.User1.Address, g.User2.Address})
+	common.Json(summary, err).SubJson(&struct {
+		List []*struct {
+			Address         types.Address `json:"address"`
+			AccountHeight   uint64        `json:"accountHeight"`
+			UnreceivedCount int           `json:"unreceivedCount"`
+			UnreceivedMore  bool          `json:"unreceivedMore"`
+		} `json:"list"`
+		Count           int `json:"count"`
+		TotalUnreceived int `json:"totalUnreceived"`
+	}{}).Equals(t, `
+{
+	"list": [
+		{
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"accountHeight": 3,
+			"unreceivedCount": 0,
+			"unreceivedMore": false
+		},
+		{
+			"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"accountHeight": 1,
+			"unreceivedCount": 2,
+			"unreceivedMore": false
+		}
+	],
+	"count": 2,
+	"totalUnreceived": 2
+}`)
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 11980*g.Zexp)
+	z.ExpectBalance(g.User2.Address, types.ZnnTokenStandard, 8000*g.Zexp)
+	common.ExpectAmount(t, summary.TotalBalances[types.ZnnTokenStandard].Balance, big.NewInt(19980*g.Zexp))
+	common.Json(ledgerApi.GetAccountInfosByAddresses(make([]types.Address, api.RpcMaxCountSize+1))).Error(t, api.ErrAddressesParamTooBig)
+}
+
+type receiveBlockStatus struct {
+	Status string  `json:"status"`
+	Block  *Height `json:"block"`
+}
+
+// Test GetReceiveBlockBySendHash
+//   - test unknown hash
+//     -> null
+//   - test receive block hash
+//     -> error
+//   - test send block through its lifecycle
+//     -> unreceived, unconfirmed and confirmed receive block
+func TestRPCLedger_GetReceiveBlockBySendHash(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	sendBlock := z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100 * g.Zexp),
+	}, nil, mock.SkipVmChanges)
+
+	// send block is not confirmed yet
+	common.Json(ledgerApi.GetReceiveBlockBySendHash(sendBlock.Hash)).Equals(t, `null`)
+	common.Json(ledgerApi.GetReceiveBlockBySendHash(types.NewHash([]byte{'1'}))).Equals(t, `null`)
+	z.InsertNewMomentum()
+
+	common.Json(ledgerApi.GetReceiveBlockBySendHash(sendBlock.Hash)).SubJson(&receiveBlockStatus{}).Equals(t, `
+{
+	"status": "unreceived",
+	"block": null
+}`)
+
+	receiveBlock := z.InsertReceiveBlock(sendBlock.Header(), nil, nil, mock.SkipVmChanges)
+	common.Json(ledgerApi.GetReceiveBlockBySendHash(sendBlock.Hash)).SubJson(&receiveBlockStatus{}).Equals(t, `
+{
+	"status": "unconfirmed",
+	"block": {
+		"height": 2
+	}
+}`)
+	z.InsertNewMomentum()
+
+	common.Json(ledgerApi.GetReceiveBlockBySendHash(sendBlock.Hash)).SubJson(&receiveBlockStatus{}).Equals(t, `
+{
+	"status": "confirmed",
+	"block": {
+		"height": 2
+	}
+}`)
+	common.Json(ledgerApi.GetReceiveBlockBySendHash(receiveBlock.Hash)).Error(t, api.ErrNotSendBlock)
+}
+
+// Test GetAccountBlockReceipt
+//   - test unknown hash
+//     -> null
+//   - test send block hash
+//     -> error
+//   - test contract receive block of a token issue
+//     -> success with the TokenIssued log
+//   - test contract receive block of a failed mint
+//     -> failed, without logs
+func TestRPCLedger_GetAccountBlockReceipt(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	issueBlock := z.InsertSendBlock(issue(g.User1.Address, "test.tok3n_na-m3", "TEST", "", big.NewInt(100), big.NewInt(1000), 1, true, true, false), nil, mock.SkipVmChanges)
+	mintBlock := z.InsertSendBlock(mint(g.User1.Address, types.ZnnTokenStandard, big.NewInt(1), g.User1.Address), nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(ledgerApi.GetAccountBlockReceipt(types.NewHash([]byte{'1'}))).Equals(t, `null`)
+	common.Json(ledgerApi.GetAccountBlockReceipt(issueBlock.Hash)).Error(t, api.ErrNotReceiveBlock)
+
+	issueReceive, err := ledgerApi.GetReceiveBlockBySendHash(issueBlock.Hash)
+	common.FailIfErr(t, err)
+	receipt, err := ledgerApi.GetAccountBlockReceipt(issueReceive.Block.Hash)
+	common.Json(receipt, err).Equals(t, `
+{
+	"blockHash": "4490e85d31840bbd9ac030e76a8a448846337fefbffaff0d954a18ec07434c09",
+	"address": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+	"fromBlockHash": "5e22abb61fc684ef8cc7a92e1a9dc961e361829773f1e5067820c4e4bb27e451",
+	"status": "success",
+	"descendantBlocks": [
+		"d838ac9602957e750fc8370460ee5589834b713478a79b61a230c131f4d2ed4b"
+	],
+	"momentumHeight": 3,
+	"momentumHash": "ddad535d824fa7a2e8c37e9efb9a9d7e18e38c344d2a15b82e9641f334e331c3",
+	"logs": [
+		{
+			"address": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+			"topics": [
+				"0b8d8deebed482dbb7d5712ffb884f7278c5f504f01a07cd0feee4178e099b3a",
+				"000000000000000000000000000000000000000000007c570ed0809a385c2432",
+				"00000000000000000000000000bbfd629028e53999aa179ac6de460ef72aa76e"
+			],
+			"data": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAD6A=="
+		}
+	],
+	"failure": null
+}`)
+
+	event := new(struct {
+		TotalSupply *big.Int
+		MaxSupply   *big.Int
+	})
+	common.FailIfErr(t, definition.ABIToken.UnpackEvent(event, definition.TokenIssuedEventName, receipt.Logs[0].Data))
+	common.Json(event, nil).Equals(t, `
+{
+	"TotalSupply": 100,
+	"MaxSupply": 1000
+}`)
+
+	mintReceive, err := ledgerApi.GetReceiveBlockBySendHash(mintBlock.Hash)
+	common.FailIfErr(t, err)
+	common.Json(ledgerApi.GetAccountBlockReceipt(mintReceive.Block.Hash)).Equals(t, `
+{
+	"blockHash": "6c7eb244af531dfaf39e4e2d042bbc1fdea7ed2bcc673431dabc245af8d6a968",
+	"address": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+	"fromBlockHash": "373f9b46501c53c2fa6d49a82ac534cca095bddae01b797e694ac29592d173bf",
+	"status": "failed",
+	"descendantBlocks": [],
+	"momentumHeight": 3,
+	"momentumHash": "ddad535d824fa7a2e8c37e9efb9a9d7e18e38c344d2a15b82e9641f334e331c3",
+	"logs": [],
+	"failure": {
+		"code": 10,
+		"reason": "address cannot call this method"
+	}
+}`)
+	common.Json(mintReceive.Block.Failure, nil).Equals(t, `
+{
+	"code": 10,
+	"reason": "address cannot call this method"
+}`)
+}
+
+// streamMomentums subscribes to ledger.streamMomentums and collects the batches until the empty one.
+func streamMomentums(t *testing.T, z mock.MockZenon, fromHeight, toHeight uint64, detailed bool) ([]json.RawMessage, error) {
+	server := rpc.NewServer()
+	common.FailIfErr(t, server.RegisterName("ledger", api.NewLedgerApi(z)))
+	defer server.Stop()
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	batches := make(chan json.RawMessage)
+	sub, err := client.Subscribe(ctx, "ledger", batches, "streamMomentums", fromHeight, toHeight, detailed)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	var result []json.RawMessage
+	for {
+		select {
+		case batch := <-batches:
+			if string(batch) == "[]" {
+				return result, nil
+			}
+			result = append(result, batch)
+		case err := <-sub.Err():
+			return nil, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Test StreamMomentums
+//   - test range above the frontier
+//     -> batches of 100 up to the frontier
+//   - test detailed
+//     -> momentums with their account blocks
+//   - test invalid range
+//     -> error
+func TestRPCLedger_StreamMomentums(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	z.InsertMomentumsTo(250)
+
+	batches, err := streamMomentums(t, z, 2, 1000, false)
+	common.FailIfErr(t, err)
+	heights := make([][]uint64, len(batches))
+	for index, batch := range batches {
+		momentums := make([]*api.Momentum, 0)
+		common.FailIfErr(t, json.Unmarshal(batch, &momentums))
+		heights[index] = []uint64{uint64(len(momentums)), momentums[0].Height, momentums[len(momentums)-1].Height}
+	}
+	common.Json(heights, nil).Equals(t, `
+[
+	[
+		100,
+		2,
+		101
+	],
+	[
+		100,
+		102,
+		201
+	],
+	[
+		49,
+		202,
+		250
+	]
+]`)
+
+	batches, err = streamMomentums(t, z, 1, 2, true)
+	common.FailIfErr(t, err)
+	momentums := make([]*api.DetailedMomentum, 0)
+	common.FailIfErr(t, json.Unmarshal(batches[0], &momentums))
+	common.Json(momentums, nil).SubJson(&[]struct {
+		AccountBlocks *listToCount `json:"blocks"`
+		Momentum      *struct {
+			Height uint64 `json:"height"`
+		} `json:"momentum"`
+	}{}).Equals(t, `
+[
+	{
+		"blocks": 18,
+		"momentum": {
+			"height": 1
+		}
+	},
+	{
+		"blocks": 0,
+		"momentum": {
+			"height": 2
+		}
+	}
+]`)
+
+	_, err = streamMomentums(t, z, 3, 2, false)
+	common.ExpectString(t, err.Error(), api.ErrInvalidHeightRange.Error())
+}
+
+// Test the memos of the blocks sent between users
+//   - test memo parsing
+//     -> only non-empty printable UTF-8 texts of at most 512 bytes are memos
+//   - test unreceived and received blocks filtered by memo
+//     -> only the blocks sent with the memo, which is decoded in the send blocks
+func TestRPCLedger_Memo(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	ledgerApi := api.NewLedgerApi(z)
+	defer z.StopPanic()
+
+	for _, data := range [][]byte{{}, {0xff, 0xfe}, []byte("a\x00b"), []byte("a\nb"), []byte(strings.Repeat("a", nom.MaxMemoSize+1))} {
+		if _, ok := nom.ParseMemo(data); ok {
+			t.Fatalf("expected %q not to be a memo", data)
+		}
+	}
+	for _, data := range []string{"deposit-42", "dépôt 42 ✓", strings.Repeat("a", nom.MaxMemoSize)} {
+		if memo, ok := nom.ParseMemo([]byte(data)); !ok || memo != data {
+			t.Fatalf("expected %q to be a memo", data)
+		}
+	}
+
+	sendBlocks := make([]*nom.AccountBlock, 0)
+	for _, data := range [][]byte{[]byte("deposit-42"), []byte("deposit-7"), {0xff, 0x00}, []byte("deposit-42")} {
+		sendBlocks = append(sendBlocks, z.InsertSendBlock(&nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     g.User2.Address,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        big.NewInt(1 * g.Zexp),
+			Data:          data,
+		}, nil, mock.SkipVmChanges))
+	}
+	z.InsertNewMomentum()
+
+	common.Json(ledgerApi.GetUnreceivedBlocksByMemo(g.User2.Address, "deposit-42", 0, 10)).SubJson(&struct {
+		Count int `json:"count"`
+		List  []struct {
+			Height uint64  `json:"height"`
+			Memo   *string `json:"memo"`
+		} `json:"list"`
+	}{}).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"height": 5,
+			"memo": "deposit-42"
+		},
+		{
+			"height": 2,
+			"memo": "deposit-42"
+		}
+	]
+}`)
+	common.Json(ledgerApi.GetUnreceivedBlocksByMemo(g.User2.Address, "deposit-1", 0, 10)).Equals(t, `
+{
+	"list": [],
+	"count": 0,
+	"more": false
+}`)
+
+	for _, sendBlock := range sendBlocks {
+		z.InsertReceiveBlock(sendBlock.Header(), nil, nil, mock.SkipVmChanges)
+	}
+	z.InsertNewMomentum()
+
+	common.Json(ledgerApi.GetReceivedBlocksByMemo(g.User2.Address, "deposit-42", 0, 10)).SubJson(&struct {
+		Count int  `json:"count"`
+		More  bool `json:"more"`
+		List  []struct {
+			Height             uint64  `json:"height"`
+			Memo               *string `json:"memo"`
+			PairedAccountBlock struct {
+				Height uint64  `json:"height"`
+				Memo   *string `json:"memo"`
+			} `json:"pairedAccountBlock"`
+		} `json:"list"`
+	}{}).Equals(t, `
+{
+	"count": 2,
+	"more": false,
+	"list": [
+		{
+			"height": 5,
+			"memo": null,
+			"pairedAccountBlock": {
+				"height": 5,
+				"memo": "deposit-42"
+			}
+		},
+		{
+			"height": 2,
+			"memo": null,
+			"pairedAccountBlock": {
+				"height": 2,
+				"memo": "deposit-42"
+			}
+		}
+	]
+}`)
+	first, err := ledgerApi.GetReceivedBlocksByMemoCursor(g.User2.Address, "deposit-42", "", 1)
+	common.Json(first, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"height": 5
+		}
+	]
+}`)
+	second, err := ledgerApi.GetReceivedBlocksByMemoCursor(g.User2.Address, "deposit-42", first.Cursor, 1)
+	common.Json(second, err).SubJson(ListOfHeight()).Equals(t, `
+{
+	"count": 1,
+	"list": [
+		{
+			"height": 2
+		}
+	]
+}`)
+	common.Json(ledgerApi.GetReceivedBlocksByMemoCursor(g.User2.Address, "deposit-42", second.Cursor, 1)).Equals(t, `
+{
+	"list": [],
+	"count": 0,
+	"more": false
+}`)
+	// cursors only continue the list which returned them
+	common.Json(ledgerApi.GetAccountBlocksByCursor(g.User2.Address, first.Cursor, 1, nil)).Error(t, api.ErrInvalidCursor)
+	common.Json(ledgerApi.GetAccountBlockByHash(sendBlocks[2].Hash, nil)).SubJson(&struct {
+		Data []byte  `json:"data"`
+		Memo *string `json:"memo"`
+	}{}).Equals(t, `
+{
+	"data": "/wA=",
+	"memo": null
+}`)
+
+	common.Json(ledgerApi.GetReceivedBlocksByMemo(g.User2.Address, "", 0, 10)).Error(t, api.ErrInvalidMemo)
+	common.Json(ledgerApi.GetUnreceivedBlocksByMemo(g.User2.Address, "a\x00b", 0, 10)).Error(t, api.ErrInvalidMemo)
 }
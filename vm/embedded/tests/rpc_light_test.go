@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/p2p"
+	"github.com/zenon-network/go-zenon/p2p/discover"
+	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// newLightClient connects a light client to a protocol manager serving the chain of the mock.
+func newLightClient(t *testing.T, z mock.MockZenon) *protocol.LightClient {
+	bridge := protocol.NewChainBridge(z.Chain(), z.Consensus(), verifier.NewVerifier(z.Chain(), z.Consensus()), vm.NewSupervisor(z.Chain(), z.Consensus()))
+	pm := protocol.NewProtocolManager(1, p2p.DefaultPropagationDiversity, z.Chain().ChainIdentifier(), bridge)
+	client, err := protocol.NewLightClient(db.NewMemDB(), z.Chain().GetGenesisMomentum())
+	common.FailIfErr(t, err)
+
+	full, light := p2p.MsgPipe()
+	caps := []p2p.Cap{{Name: "eth", Version: 66}}
+	go pm.SubProtocols[0].Run(p2p.NewPeer(discover.NodeID{1}, "light", caps), full)
+	go client.SubProtocols[0].Run(p2p.NewPeer(discover.NodeID{2}, "full", caps), light)
+	return client
+}
+
+func waitLightClient(t *testing.T, client *protocol.LightClient, height uint64) {
+	for i := 0; i < 500; i += 1 {
+		if client.GetFrontierMomentum().Height >= height {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("light client did not reach momentum height %v", height)
+}
+
+// Test light client
+//   - test header sync over more than one batch
+//     -> same frontier as the full node
+//   - test account info and snapshot at the frontier
+//     -> balances from the peer, frontier block found in the genesis content
+func TestRPCLight(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	ledgerApi := api.NewLedgerApi(z)
+
+	z.InsertMomentumsTo(protocol.MaxHeaderFetch + 10)
+	client := newLightClient(t, z)
+	client.Start()
+	defer client.Stop()
+	lightApi := api.NewLightLedgerApi(client)
+
+	waitLightClient(t, client, protocol.MaxHeaderFetch+10)
+	expected, err := ledgerApi.GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	frontier, err := lightApi.GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	common.ExpectString(t, frontier.Hash.String(), expected.Hash.String())
+	common.Json(lightApi.GetMomentumsByHeight(2, 2)).SubJson(&struct {
+		Count uint64
+	}{}).Equals(t, `
+{
+	"Count": 202
+}`)
+
+	common.Json(lightApi.GetAccountInfoByAddress(g.User1.Address)).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"accountHeight": 1,
+	"balanceInfoMap": {
+		"zts1qsrxxxxxxxxxxxxxmrhjll": {
+			"token": null,
+			"balance": "12000000000000"
+		},
+		"zts1znnxxxxxxxxxxxxx9z4ulx": {
+			"token": null,
+			"balance": "1200000000000"
+		}
+	}
+}`)
+	common.Json(lightApi.GetAccountSnapshot(g.User1.Address, frontier.Hash)).SubJson(&struct {
+		AccountHeight uint64
+		ContentIndex  int
+	}{}).Equals(t, `
+{
+	"AccountHeight": 1,
+	"ContentIndex": 10
+}`)
+}
+
+// Test light client account proofs of old momentums
+//   - test the full node doesn't serve the state of the momentums older than chain.MaxRecentStateDepth
+func TestRPCLight_OldState(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(chain.MaxRecentStateDepth + 5)
+	client := newLightClient(t, z)
+	client.Start()
+	defer client.Stop()
+	lightApi := api.NewLightLedgerApi(client)
+	waitLightClient(t, client, chain.MaxRecentStateDepth+5)
+
+	old, err := lightApi.GetMomentumsByHeight(2, 1)
+	common.FailIfErr(t, err)
+	_, err = lightApi.GetAccountSnapshot(g.User1.Address, old.List[0].Hash)
+	common.ExpectString(t, fmt.Sprint(err), "no peer delivered a valid account proof")
+	frontier, err := lightApi.GetFrontierMomentum()
+	common.FailIfErr(t, err)
+	_, err = lightApi.GetAccountSnapshot(g.User1.Address, frontier.Hash)
+	common.FailIfErr(t, err)
+}
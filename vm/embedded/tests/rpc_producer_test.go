@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Test GetStats
+//   - test pillar of the node after producing momentums
+//     -> upcoming slots, production in the current epoch and the last produced momentum
+func TestRPCProducer_GetStats(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	producerApi := api.NewProducerApi(z)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(60)
+	common.Json(producerApi.GetStats()).Equals(t, `
+{
+	"name": "TEST-pillar-1",
+	"producerAddress": "z1qqq43dyrswfehx9w9td43exflqzcxrt7g6alah",
+	"epoch": 0,
+	"expectedMomentums": 20,
+	"producedMomentums": 19,
+	"missedMomentums": 1,
+	"nextSlots": [
+		{
+			"startTime": 1000000600,
+			"endTime": 1000000610
+		},
+		{
+			"startTime": 1000000690,
+			"endTime": 1000000700
+		},
+		{
+			"startTime": 1000000710,
+			"endTime": 1000000720
+		},
+		{
+			"startTime": 1000000720,
+			"endTime": 1000000730
+		},
+		{
+			"startTime": 1000000750,
+			"endTime": 1000000760
+		},
+		{
+			"startTime": 1000000760,
+			"endTime": 1000000770
+		},
+		{
+			"startTime": 1000000800,
+			"endTime": 1000000810
+		},
+		{
+			"startTime": 1000000820,
+			"endTime": 1000000830
+		},
+		{
+			"startTime": 1000000860,
+			"endTime": 1000000870
+		},
+		{
+			"startTime": 1000000890,
+			"endTime": 1000000900
+		}
+	],
+	"timeDrift": 0,
+	"lastProduced": {
+		"hash": "fb65708f9ecb660b3073ff58cd307020af339d4032af8513edd738b833c90973",
+		"height": 55,
+		"timestamp": 1000000540
+	},
+	"backup": false,
+	"producing": true
+}`)
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Test Simulate
+//   - returns the receive-block and the state changes of a successful call
+//   - returns the reason of an invalid send-block or a failed call
+//   - doesn't commit anything
+func TestRPCSimulate(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	simulateApi := embedded.NewSimulateApi(z)
+	tokenApi := embedded.NewTokenApi(z)
+
+	issue := func(amount *big.Int) *nom.AccountBlock {
+		return &nom.AccountBlock{
+			Address:       g.User1.Address,
+			ToAddress:     types.TokenContract,
+			TokenStandard: types.ZnnTokenStandard,
+			Amount:        amount,
+			Data: definition.ABIToken.PackMethodPanic(definition.IssueMethodName,
+				"test.tok3n_na-m3", //param.TokenName
+				"TEST",             //param.TokenSymbol
+				"",                 //param.TokenDomain
+				big.NewInt(100),    //param.TotalSupply
+				big.NewInt(1000),   //param.MaxSupply
+				uint8(1),           //param.Decimals
+				true,               //param.IsMintable
+				true,               //param.IsBurnable
+				false,              //param.IsUtility
+			),
+		}
+	}
+
+	common.Json(simulateApi.Simulate(issue(constants.TokenIssueAmount))).Equals(t, `
+{
+	"sendBlock": {
+		"version": 1,
+		"chainIdentifier": 100,
+		"blockType": 2,
+		"hash": "e0432f50feadf51c3727cd0b0af10fcd454756c59ffa58c894b48320b659e6f2",
+		"previousHash": "598fa623dd308bec7163bb375aa7546ec4aced3b71a1c9278709903e69280dbd",
+		"height": 2,
+		"momentumAcknowledged": {
+			"hash": "0385d849ee33b94c8783288c148e3ae741c2ecec98b08b3f59d6bcc219168fe5",
+			"height": 1
+		},
+		"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+		"toAddress": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+		"amount": "100000000",
+		"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+		"fromBlockHash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"descendantBlocks": [],
+		"data": "vEELkQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABoAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABkAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA+gAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQdGVzdC50b2szbl9uYS1tMwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABFRFU1QAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		"fusedPlasma": 0,
+		"difficulty": 0,
+		"nonce": "0000000000000000",
+		"basePlasma": 0,
+		"usedPlasma": 0,
+		"changesHash": "0000000000000000000000000000000000000000000000000000000000000000",
+		"publicKey": null,
+		"signature": null
+	},
+	"receiveBlock": {
+		"version": 1,
+		"chainIdentifier": 100,
+		"blockType": 5,
+		"hash": "1773049a10a78699c67f962e3669d0780ff12b4600d55bcb6c1dcc85ddfe76c2",
+		"previousHash": "1b24964383d1322fa77baed9283c5c3786110d8c1d0ab23dc81bdb8d35401dd9",
+		"height": 3,
+		"momentumAcknowledged": {
+			"hash": "0385d849ee33b94c8783288c148e3ae741c2ecec98b08b3f59d6bcc219168fe5",
+			"height": 1
+		},
+		"address": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+		"toAddress": "z1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqsggv2f",
+		"amount": "0",
+		"tokenStandard": "zts1qqqqqqqqqqqqqqqqtq587y",
+		"fromBlockHash": "e0432f50feadf51c3727cd0b0af10fcd454756c59ffa58c894b48320b659e6f2",
+		"descendantBlocks": [
+			{
+				"version": 1,
+				"chainIdentifier": 100,
+				"blockType": 4,
+				"hash": "1b24964383d1322fa77baed9283c5c3786110d8c1d0ab23dc81bdb8d35401dd9",
+				"previousHash": "f71c88c94c3c606e4019d180a99004e582f8972451fcdb091f56881bd1d1bf48",
+				"height": 2,
+				"momentumAcknowledged": {
+					"hash": "0385d849ee33b94c8783288c148e3ae741c2ecec98b08b3f59d6bcc219168fe5",
+					"height": 1
+				},
+				"address": "z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0",
+				"toAddress": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+				"amount": "100",
+				"tokenStandard": "zts1g0m7cdlydd8krzgkc8hrzu",
+				"fromBlockHash": "0000000000000000000000000000000000000000000000000000000000000000",
+				"descendantBlocks": [],
+				"data": "",
+				"fusedPlasma": 0,
+				"difficulty": 0,
+				"nonce": "0000000000000000",
+				"basePlasma": 0,
+				"usedPlasma": 0,
+				"changesHash": "0000000000000000000000000000000000000000000000000000000000000000",
+				"publicKey": null,
+				"signature": null
+			}
+		],
+		"data": "AAAAAAAAAAE=",
+		"fusedPlasma": 0,
+		"difficulty": 0,
+		"nonce": "0000000000000000",
+		"basePlasma": 0,
+		"usedPlasma": 0,
+		"changesHash": "5ece9544a7cd5336bcd6514f538614adeef09b9c02b063d9392f38870bff302e",
+		"publicKey": null,
+		"signature": null
+	},
+	"error": null,
+	"changes": [
+		{
+			"key": "AxTmYxjGMYxjGMY=",
+			"value": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAX14QA="
+		},
+		{
+			"key": "A0P37Dfka09hiRY=",
+			"value": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+		},
+		{
+			"key": "BAFD9+w35GtPYYkW",
+			"value": "AAAAAAAAAAAAAAAAALv9YpAo5TmZqheaxt5GDvcqp24AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAcAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAZAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAPoAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAEHRlc3QudG9rM25fbmEtbTMAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAARURVNUAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+		}
+	]
+}`)
+	common.Json(simulateApi.Simulate(issue(big.NewInt(1)))).SubJson(&struct {
+		ReceiveBlock *nom.AccountBlock `json:"receiveBlock"`
+		Error        *string           `json:"error"`
+	}{}).Equals(t, `
+{
+	"receiveBlock": null,
+	"error": "invalid token or amount"
+}`)
+	common.Json(simulateApi.Simulate(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.TokenContract,
+		Data:      definition.ABIToken.PackMethodPanic(definition.MintMethodName, types.ZnnTokenStandard, big.NewInt(10), g.User1.Address),
+	})).SubJson(&struct {
+		Error   *string        `json:"error"`
+		Changes []*interface{} `json:"changes"`
+	}{}).Equals(t, `
+{
+	"error": "address cannot call this method",
+	"changes": [
+		{
+			"key": "AwAAAAAAAAAAAAA=",
+			"value": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+		}
+	]
+}`)
+
+	// nothing was committed
+	z.ExpectBalance(g.User1.Address, types.ZnnTokenStandard, 12000*g.Zexp)
+	common.Json(tokenApi.GetByOwner(g.User1.Address, 0, 10)).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+}
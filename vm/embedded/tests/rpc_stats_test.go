@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// Test Health
+//   - test synced node without health thresholds
+//     -> healthy, with the age of the frontier momentum
+func TestRPCStats_Health(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	statsApi := api.NewStatsApi(z, nil)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(5)
+	common.Json(statsApi.Health()).Equals(t, `
+{
+	"healthy": true,
+	"syncState": 2,
+	"numPeers": 0,
+	"minPeers": 0,
+	"frontierHeight": 5,
+	"momentumAge": 0,
+	"maxMomentumAge": 0,
+	"reasons": []
+}`)
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain"
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/rpc/api/embedded"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+// Test GetStorageEntries
+//   - pages follow the last key of the previous one, also within a prefix
+//   - past momentums are read before the later changes
+//   - only the state of the recent momentums is served
+//   - only embedded contracts can be read
+func TestRPCStorage_GetStorageEntries(t *testing.T) {
+	h := NewHarness(t)
+	storageApi := embedded.NewStorageApi(h)
+
+	all, err := storageApi.GetStorageEntries(types.PillarContract, nil, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, all.More, false)
+	common.Expect(t, len(all.List) > 2, true)
+	common.Expect(t, all.Momentum.Height, 1)
+
+	entries := make([]*embedded.StorageEntry, 0)
+	page := &embedded.StoragePage{Size: 2}
+	for {
+		result, err := storageApi.GetStorageEntries(types.PillarContract, nil, page)
+		common.FailIfErr(t, err)
+		entries = append(entries, result.List...)
+		if !result.More {
+			break
+		}
+		common.Expect(t, len(result.List), 2)
+		page.After = result.List[len(result.List)-1].Key
+	}
+	expected, err := json.MarshalIndent(all.List, "", "\t")
+	common.FailIfErr(t, err)
+	common.Json(entries, nil).Equals(t, string(expected))
+
+	prefix := all.List[1].Key[:1]
+	expectedInPrefix := make([]*embedded.StorageEntry, 0)
+	for _, entry := range all.List[2:] {
+		if bytes.HasPrefix(entry.Key, prefix) {
+			expectedInPrefix = append(expectedInPrefix, entry)
+		}
+	}
+	common.Expect(t, len(expectedInPrefix) > 0, true)
+	inPrefix, err := storageApi.GetStorageEntries(types.PillarContract, prefix, &embedded.StoragePage{After: all.List[1].Key})
+	common.FailIfErr(t, err)
+	expected, err = json.MarshalIndent(expectedInPrefix, "", "\t")
+	common.FailIfErr(t, err)
+	common.Json(inPrefix.List, nil).Equals(t, string(expected))
+
+	tokensBefore, err := storageApi.GetStorageEntries(types.TokenContract, nil, nil)
+	common.FailIfErr(t, err)
+	defer h.CallWithFunds(g.User1.Address, types.TokenContract, types.ZnnTokenStandard, constants.TokenIssueAmount, definition.IssueMethodName,
+		"test.tok3n_na-m3", "TEST", "", big.NewInt(100), big.NewInt(1000), uint8(1), true, true, false).Error(t, nil)
+	h.InsertMomentums(2)
+
+	tokensAfter, err := storageApi.GetStorageEntries(types.TokenContract, nil, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, len(tokensAfter.List), len(tokensBefore.List)+1)
+	tokensAtGenesis, err := storageApi.GetStorageEntries(types.TokenContract, nil, &embedded.StoragePage{MomentumHeight: 1})
+	common.FailIfErr(t, err)
+	expected, err = json.MarshalIndent(tokensBefore, "", "\t")
+	common.FailIfErr(t, err)
+	common.Json(tokensAtGenesis, nil).Equals(t, string(expected))
+
+	common.Json(storageApi.GetStorageEntries(types.TokenContract, nil, &embedded.StoragePage{MomentumHeight: 100})).Equals(t, `null`)
+
+	h.InsertMomentums(chain.MaxRecentStateDepth)
+	_, err = storageApi.GetStorageEntries(types.TokenContract, nil, &embedded.StoragePage{MomentumHeight: 1})
+	common.ExpectError(t, err, chain.ErrStateTooOld)
+	common.Json(storageApi.GetStorageEntries(g.User1.Address, nil, nil)).Error(t, api.ErrNotEmbeddedContract)
+	common.Json(storageApi.GetStorageEntries(types.TokenContract, nil, &embedded.StoragePage{Size: 2000})).Error(t, api.ErrPageSizeParamTooBig)
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/pow"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+)
+
+func TestRPCUtilities_GeneratePoW(t *testing.T) {
+	pool := pow.NewPool(1, 0)
+	defer pool.Stop()
+	utilitiesApi := api.NewUtilitiesApi(pool)
+
+	block := &nom.AccountBlock{
+		Address:      g.User1.Address,
+		PreviousHash: types.HexToHashPanic("598fa623dd308bec7163bb375aa7546ec4aced3b71a1c9278709903e69280dbd"),
+		Difficulty:   150000,
+	}
+	nonce, err := utilitiesApi.GeneratePoW(context.Background(), pow.GetAccountBlockHash(block), block.Difficulty)
+	common.FailIfErr(t, err)
+	block.Nonce = *nonce
+	if !pow.CheckPoWNonce(block) {
+		t.Fatal("generated nonce doesn't satisfy the difficulty")
+	}
+
+	// cancelled jobs return right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	common.Json(utilitiesApi.GeneratePoW(ctx, pow.GetAccountBlockHash(block), constants.MaxDifficultyForAccountBlock)).Error(t, context.Canceled)
+
+	common.Json(utilitiesApi.GeneratePoW(context.Background(), types.ZeroHash, 0)).Error(t, api.ErrDifficultyIsZero)
+	common.Json(utilitiesApi.GeneratePoW(context.Background(), types.ZeroHash, constants.MaxDifficultyForAccountBlock+1)).Error(t, api.ErrDifficultyTooBig)
+
+	common.Json(utilitiesApi.GetPoWStats()).Equals(t, `
+{
+	"maxJobs": 1,
+	"maxQueuedJobs": 0,
+	"running": 0,
+	"queued": 0,
+	"completed": 1,
+	"cancelled": 1,
+	"rejected": 0
+}`)
+}
+
+// Test AbiEncode & AbiDecode
+//   - test encoding matches the ABI definitions, including lists and the methods shared by contracts
+//   - test decoding returns the arguments in the format accepted by AbiEncode
+//   - test invalid contracts, methods and arguments
+//   - test the PoW methods fail without a pool, the ABI ones are served anyway
+func TestRPCUtilities_AbiEncodeDecode(t *testing.T) {
+	utilitiesApi := api.NewUtilitiesApi(nil)
+
+	data, err := utilitiesApi.AbiEncode(types.PillarContract, definition.RegisterMethodName, []interface{}{"pillar", g.User1.Address.String(), g.User2.Address.String(), "0", float64(100)})
+	common.FailIfErr(t, err)
+	common.ExpectString(t, hex.EncodeToString(data), hex.EncodeToString(definition.ABIPillars.PackMethodPanic(definition.RegisterMethodName, "pillar", g.User1.Address, g.User2.Address, uint8(0), uint8(100))))
+	common.Json(utilitiesApi.AbiDecode(types.PillarContract, data)).Equals(t, `
+{
+	"method": "Register",
+	"inputs": [
+		{
+			"name": "name",
+			"type": "string",
+			"value": "pillar"
+		},
+		{
+			"name": "producerAddress",
+			"type": "address",
+			"value": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz"
+		},
+		{
+			"name": "rewardAddress",
+			"type": "address",
+			"value": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx"
+		},
+		{
+			"name": "giveBlockRewardPercentage",
+			"type": "uint8",
+			"value": "0"
+		},
+		{
+			"name": "giveDelegateRewardPercentage",
+			"type": "uint8",
+			"value": "100"
+		}
+	]
+}`)
+
+	data, err = utilitiesApi.AbiEncode(types.BridgeContract, definition.NominateGuardiansMethodName, []interface{}{[]interface{}{g.User1.Address.String(), g.User2.Address.String()}})
+	common.FailIfErr(t, err)
+	common.ExpectString(t, hex.EncodeToString(data), hex.EncodeToString(definition.ABIBridge.PackMethodPanic(definition.NominateGuardiansMethodName, []types.Address{g.User1.Address, g.User2.Address})))
+	common.Json(utilitiesApi.AbiDecode(types.BridgeContract, data)).Equals(t, `
+{
+	"method": "NominateGuardians",
+	"inputs": [
+		{
+			"name": "guardians",
+			"type": "address[]",
+			"value": [
+				"z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+				"z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx"
+			]
+		}
+	]
+}`)
+
+	data, err = utilitiesApi.AbiEncode(types.StakeContract, definition.CollectRewardMethodName, []interface{}{})
+	common.FailIfErr(t, err)
+	common.Json(utilitiesApi.AbiDecode(types.StakeContract, data)).Equals(t, `
+{
+	"method": "CollectReward",
+	"inputs": []
+}`)
+
+	common.Json(utilitiesApi.AbiEncode(g.User1.Address, definition.CollectRewardMethodName, nil)).Error(t, api.ErrNotEmbeddedContract)
+	common.Json(utilitiesApi.AbiEncode(types.StakeContract, "Unknown", nil)).Error(t, api.ErrUnknownMethodName)
+	common.Json(utilitiesApi.AbiEncode(types.PillarContract, definition.DelegateMethodName, nil)).Error(t, errors.Errorf("method Delegate expects 1 arguments, got 0"))
+	common.Json(utilitiesApi.AbiEncode(types.PillarContract, definition.RegisterMethodName, []interface{}{"pillar", g.User1.Address.String(), g.User2.Address.String(), "0", "256"})).Error(t, errors.Errorf("invalid argument giveDelegateRewardPercentage of type uint8: 256 overflows"))
+	common.Json(utilitiesApi.AbiDecode(types.PillarContract, []byte{1, 2, 3, 4})).Error(t, api.ErrUnknownMethodName)
+
+	common.Json(utilitiesApi.GeneratePoW(context.Background(), types.ZeroHash, 1)).Error(t, api.ErrPoWDisabled)
+	common.Json(utilitiesApi.GetPoWStats()).Error(t, api.ErrPoWDisabled)
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
+	"github.com/zenon-network/go-zenon/vm/embedded/definition"
+	"github.com/zenon-network/go-zenon/wallet"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// testKeyFile is encrypted with the password "password" and weak argon2 parameters, so it's fast to unlock.
+const testKeyFile = `{
+	"baseAddress": "z1qz4gdq5zjc4p5t9u9yultl2a3xj2fl0l0vq893",
+	"crypto": {
+		"cipherName": "aes-256-gcm",
+		"kdf": "argon2.IDKey",
+		"cipherData": "0x02b04c318784f64c772d4418de4e9b0f593b4040436b1077e0f76193be6683a61285eb3d811a9aff40ae5264dde6bd86",
+		"nonce": "0xf4d6282765f55d32b15b7e8a",
+		"argon2Params": {
+			"salt": "0x7c3f63d89642c3b40036ced64dafbc88",
+			"time": 1,
+			"memory": 64,
+			"threads": 1
+		}
+	},
+	"version": 1,
+	"timestamp": 1700000000
+}`
+
+var (
+	// addresses of the first indexes of testKeyFile
+	testKeyFileAddresses = []types.Address{
+		types.ParseAddressPanic("z1qz4gdq5zjc4p5t9u9yultl2a3xj2fl0l0vq893"),
+		types.ParseAddressPanic("z1qz3z8tlz444nzj2pfv7jh985x448hwk3edne5l"),
+		types.ParseAddressPanic("z1qq2xazwft50xr54clmh2tjv3f0jtx8m624sgx2"),
+	}
+)
+
+// newTestWallet returns a started wallet manager whose directory has testKeyFile, along with its path.
+func newTestWallet(t *testing.T) (*wallet.Manager, string) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test-key")
+	common.DealWithErr(os.WriteFile(path, []byte(testKeyFile), 0600))
+	manager := wallet.New(&wallet.Config{WalletDir: dir})
+	common.DealWithErr(manager.Start())
+	t.Cleanup(manager.Stop)
+	return manager, path
+}
+
+func testKeyStoreUnlocked(t *testing.T, walletApi *api.WalletApi) bool {
+	keyStores, err := walletApi.GetKeyStores()
+	common.DealWithErr(err)
+	if len(keyStores) != 1 {
+		t.Fatalf("expected a single key store, got %v", len(keyStores))
+	}
+	return keyStores[0].Unlocked
+}
+
+// Test Unlock
+//   - test addresses of an unlocked key store
+//   - test key store locked after the unlock duration
+//   - test Lock before the unlock duration
+func TestRPCWallet_Unlock(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	manager, path := newTestWallet(t)
+	walletApi := api.NewWalletApi(z, manager)
+
+	_, err := walletApi.GetAddresses(path, 3)
+	common.ExpectError(t, err, wallet.ErrKeyStoreLocked)
+	common.ExpectError(t, walletApi.Unlock(path, "wrong", 1), wallet.ErrWrongPassword)
+	common.DealWithErr(walletApi.Unlock(path, "password", 1))
+	common.Json(walletApi.GetAddresses(path, 3)).Equals(t, `
+[
+	"z1qz4gdq5zjc4p5t9u9yultl2a3xj2fl0l0vq893",
+	"z1qz3z8tlz444nzj2pfv7jh985x448hwk3edne5l",
+	"z1qq2xazwft50xr54clmh2tjv3f0jtx8m624sgx2"
+]`)
+
+	time.Sleep(1500 * time.Millisecond)
+	if testKeyStoreUnlocked(t, walletApi) {
+		t.Fatal("key store still unlocked after the unlock duration")
+	}
+
+	common.DealWithErr(walletApi.Unlock(path, "password", 300))
+	walletApi.Lock(path)
+	if testKeyStoreUnlocked(t, walletApi) {
+		t.Fatal("key store still unlocked after Lock")
+	}
+}
+
+// Test concurrent use of the key store while the unlock timers fire, meant to be run with -race
+//   - test key store unlocked again while its timers expire
+//     -> the last unlock duration wins, the expired timers don't lock it
+func TestRPCWallet_UnlockRace(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	manager, path := newTestWallet(t)
+	walletApi := api.NewWalletApi(z, manager)
+
+	deadline := time.Now().Add(1500 * time.Millisecond)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 4; i += 1 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				switch i {
+				case 0:
+					_ = walletApi.Unlock(path, "password", 1)
+				case 1:
+					walletApi.Lock(path)
+					time.Sleep(50 * time.Millisecond)
+				case 2:
+					_, _ = walletApi.GetAddresses(path, 1)
+				case 3:
+					_, _ = manager.IsUnlocked(path)
+					_, _ = manager.GetKeyStore(path)
+					_ = manager.UnlockedPaths()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	common.DealWithErr(walletApi.Unlock(path, "password", 300))
+	time.Sleep(1500 * time.Millisecond)
+	if !testKeyStoreUnlocked(t, walletApi) {
+		t.Fatal("key store locked by an expired unlock timer")
+	}
+	walletApi.Lock(path)
+}
+
+// waitAccountHeight waits until the account chain of the address reaches the height.
+func waitAccountHeight(t *testing.T, h *Harness, address types.Address, height uint64) {
+	deadline := time.Now().Add(10 * time.Second)
+	for h.Chain().GetFrontierAccountStore(address).Identifier().Height < height {
+		if time.Now().After(deadline) {
+			t.Fatalf("account %v didn't reach height %v", address, height)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Test AutoReceiver
+//   - test the blocks aren't received while no unlocked key store derives the address
+//   - test they are received once the key store is unlocked, over several intervals with MaxBlocks
+//   - test the blocks aren't received once the key store is locked again
+func TestRPCWallet_AutoReceive(t *testing.T) {
+	h := NewHarness(t)
+	manager, path := newTestWallet(t)
+	walletApi := api.NewWalletApi(h, manager)
+	address := testKeyFileAddresses[0]
+
+	h.InsertMomentums(1)
+	defer h.CallWithFunds(g.User1.Address, types.PlasmaContract, types.QsrTokenStandard, big.NewInt(100*g.Zexp),
+		definition.FuseMethodName, address).Error(t, nil)
+	h.InsertMomentums(2)
+	for i := 0; i < 3; i += 1 {
+		h.Send(g.User1.Address, address, types.ZnnTokenStandard, big.NewInt(g.Zexp))
+	}
+	h.InsertMomentums(1)
+
+	receiver := api.NewAutoReceiver(h, walletApi, nil, &api.AutoReceiveConfig{
+		Addresses: []types.Address{g.User2.Address, address},
+		Interval:  10 * time.Millisecond,
+		MaxBlocks: 2,
+	})
+	common.FailIfErr(t, receiver.Start())
+	defer receiver.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	common.Expect(t, h.Chain().GetFrontierAccountStore(address).Identifier().Height, 0)
+
+	common.DealWithErr(walletApi.Unlock(path, "password", 300))
+	waitAccountHeight(t, h, address, 3)
+	h.InsertMomentums(1)
+	common.Expect(t, h.Balance(address, types.ZnnTokenStandard).String(), big.NewInt(3*g.Zexp).String())
+
+	walletApi.Lock(path)
+	h.Send(g.User1.Address, address, types.ZnnTokenStandard, big.NewInt(g.Zexp))
+	h.InsertMomentums(1)
+	time.Sleep(100 * time.Millisecond)
+	common.Expect(t, h.Chain().GetFrontierAccountStore(address).Identifier().Height, 3)
+}
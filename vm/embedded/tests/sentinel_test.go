@@ -1145,3 +1145,146 @@ t=2001-09-09T03:47:10+0000 lvl=info msg="received donation" module=embedded cont
 	]
 }`)
 }
+
+//   - test embedded.sentinel.getAll RPC
+//     -> revoked sentinels are listed along with their revoke timestamp
+//     -> sentinels are sorted by registration timestamp and paginated
+func TestSentinel_GetAllRPC(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	sentinelApi := embedded.NewSentinelApi(z)
+
+	common.Json(sentinelApi.GetAll(0, 5)).Equals(t, `
+{
+	"count": 0,
+	"list": []
+}`)
+	registerSentinel(z, t, g.Pillar4.Address)
+	registerSentinel(z, t, g.User1.Address)
+	z.InsertMomentumsTo(120)
+
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.Pillar4.Address,
+		ToAddress: types.SentinelContract,
+		Data:      definition.ABISentinel.PackMethodPanic(definition.RevokeSentinelMethodName),
+	}).Error(t, nil)
+	z.InsertNewMomentum()
+	z.InsertNewMomentum()
+
+	common.Json(sentinelApi.GetAll(0, 5)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"owner": "z1qplpsv3wcm64js30jlumxlatgxxkqr6hgv30fg",
+			"registrationTimestamp": 1000000020,
+			"isRevocable": true,
+			"revokeCooldown": 10,
+			"active": false,
+			"revokeTimestamp": 1000001200
+		},
+		{
+			"owner": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"registrationTimestamp": 1000000040,
+			"isRevocable": false,
+			"revokeCooldown": 10,
+			"active": true,
+			"revokeTimestamp": 0
+		}
+	]
+}`)
+	common.Json(sentinelApi.GetAll(1, 1)).Equals(t, `
+{
+	"count": 2,
+	"list": [
+		{
+			"owner": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"registrationTimestamp": 1000000040,
+			"isRevocable": false,
+			"revokeCooldown": 10,
+			"active": true,
+			"revokeTimestamp": 0
+		}
+	]
+}`)
+	_, err := sentinelApi.GetAll(0, api.RpcMaxPageSize+1)
+	common.ExpectError(t, err, api.ErrPageSizeParamTooBig)
+}
+
+//   - test embedded.sentinel.getRewardHistory RPC
+//     -> totals cover the whole epoch range, not only the page
+//     -> the range is capped to the last updated epoch
+func TestSentinel_GetRewardHistory(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	sentinelApi := embedded.NewSentinelApi(z)
+
+	registerSentinel(z, t, g.User1.Address)
+	z.InsertMomentumsTo(50)
+	registerSentinel(z, t, g.User2.Address)
+	z.InsertMomentumsTo(60*6*3 + 50)
+
+	common.Json(sentinelApi.GetRewardHistory(g.User1.Address, 0, 10, 0, 5)).Equals(t, `
+{
+	"count": 3,
+	"totalZnnAmount": "374400000000",
+	"totalQsrAmount": "1000000000000",
+	"list": [
+		{
+			"epoch": 2,
+			"znnAmount": "93600000000",
+			"qsrAmount": "250000000000"
+		},
+		{
+			"epoch": 1,
+			"znnAmount": "93600000000",
+			"qsrAmount": "250000000000"
+		},
+		{
+			"epoch": 0,
+			"znnAmount": "187200000000",
+			"qsrAmount": "500000000000"
+		}
+	]
+}`)
+	common.Json(sentinelApi.GetRewardHistory(g.User1.Address, 0, 10, 1, 2)).Equals(t, `
+{
+	"count": 3,
+	"totalZnnAmount": "374400000000",
+	"totalQsrAmount": "1000000000000",
+	"list": [
+		{
+			"epoch": 0,
+			"znnAmount": "187200000000",
+			"qsrAmount": "500000000000"
+		}
+	]
+}`)
+	common.Json(sentinelApi.GetRewardHistory(g.User2.Address, 0, 1, 0, 5)).Equals(t, `
+{
+	"count": 2,
+	"totalZnnAmount": "93600000000",
+	"totalQsrAmount": "250000000000",
+	"list": [
+		{
+			"epoch": 1,
+			"znnAmount": "93600000000",
+			"qsrAmount": "250000000000"
+		},
+		{
+			"epoch": 0,
+			"znnAmount": "0",
+			"qsrAmount": "0"
+		}
+	]
+}`)
+	common.Json(sentinelApi.GetRewardHistory(g.User2.Address, 5, 10, 0, 5)).Equals(t, `
+{
+	"count": 0,
+	"totalZnnAmount": "0",
+	"totalQsrAmount": "0",
+	"list": []
+}`)
+	_, err := sentinelApi.GetRewardHistory(g.User1.Address, 2, 1, 0, 5)
+	common.ExpectError(t, err, api.ErrInvalidEpochRange)
+}
@@ -388,3 +388,77 @@ func TestSimple_MomentumContent(t *testing.T) {
 		z.InsertNewMomentum()
 	}
 }
+
+func activatePayloadLimits(z mock.MockZenon) {
+	sporkAPI := embedded.NewSporkApi(z)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.Spork.Address,
+		ToAddress: types.SporkContract,
+		Data: definition.ABISpork.PackMethodPanic(definition.SporkCreateMethodName,
+			"spork-payload-limits",              // name
+			"activate spork for payload limits", // description
+		),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+
+	sporkList, _ := sporkAPI.GetAll(0, 10)
+	id := sporkList.List[0].Id
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.Spork.Address,
+		ToAddress: types.SporkContract,
+		Data: definition.ABISpork.PackMethodPanic(definition.SporkActivateMethodName,
+			id, // id
+		),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	types.PayloadLimitsSpork.SporkId = id
+	types.ImplementedSporksMap[id] = true
+	z.InsertMomentumsTo(20)
+}
+
+// Send account-blocks with data bigger than the initial limit
+//   - test that the data limit is raised only once the payload limits spork is enforced
+//     -> ErrABDataTooBig before, accepted after
+//   - test that the momentum content limit is raised as well
+//     -> GetPayloadLimits returns the raised limits
+func TestSimple_PayloadLimitsSpork(t *testing.T) {
+	sporkId := types.PayloadLimitsSpork.SporkId
+	defer func() {
+		types.PayloadLimitsSpork.SporkId = sporkId
+	}()
+
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	limits, err := chain.GetPayloadLimits(z.Chain().GetFrontierMomentumStore())
+	common.Json(limits, err).Equals(t, `
+{
+	"MaxDataLength": 16384,
+	"MaxAccountBlocksInMomentum": 100
+}`)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: g.User2.Address,
+		Data:      make([]byte, constants.MaxDataLength+1),
+	}, verifier.ErrABDataTooBig, mock.NoVmChanges)
+
+	activatePayloadLimits(z)
+	limits, err = chain.GetPayloadLimits(z.Chain().GetFrontierMomentumStore())
+	common.Json(limits, err).Equals(t, `
+{
+	"MaxDataLength": 65536,
+	"MaxAccountBlocksInMomentum": 200
+}`)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: g.User2.Address,
+		Data:      make([]byte, constants.MaxDataLength+1),
+	}, nil, mock.SkipVmChanges)
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: g.User2.Address,
+		Data:      make([]byte, constants.MaxDataLengthAfterPayloadSpork+1),
+	}, verifier.ErrABDataTooBig, mock.NoVmChanges)
+	z.InsertNewMomentum()
+}
@@ -41,7 +41,10 @@ t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork s
 			"name": "spork-1",
 			"description": "spork description",
 			"activated": false,
-			"enforcementHeight": 0
+			"enforcementHeight": 0,
+			"activationHeight": 0,
+			"enforced": false,
+			"implemented": false
 		}
 	]
 }`)
@@ -103,14 +106,20 @@ t=2001-09-09T01:47:00+0000 lvl=dbug msg=created module=embedded contract=spork s
 			"name": "spork-2",
 			"description": "spork description",
 			"activated": false,
-			"enforcementHeight": 0
+			"enforcementHeight": 0,
+			"activationHeight": 0,
+			"enforced": false,
+			"implemented": false
 		},
 		{
 			"id": "eedcf4003fedfa69a0494e8b09c156f70c3e790af563642d0222514c3078966f",
 			"name": "spork-1",
 			"description": "spork description",
 			"activated": false,
-			"enforcementHeight": 0
+			"enforcementHeight": 0,
+			"activationHeight": 0,
+			"enforced": false,
+			"implemented": false
 		}
 	]
 }`)
@@ -156,10 +165,26 @@ t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork
 			"name": "spork-1",
 			"description": "spork description",
 			"activated": true,
-			"enforcementHeight": 9
+			"enforcementHeight": 9,
+			"activationHeight": 3,
+			"enforced": false,
+			"implemented": false
 		}
 	]
 }`)
 	types.ImplementedSporksMap[types.HexToHashPanic("eedcf4003fedfa69a0494e8b09c156f70c3e790af563642d0222514c3078966f")] = true
 	z.InsertMomentumsTo(20)
+	common.Json(sporkAPI.GetById(id)).Equals(t, `
+{
+	"id": "eedcf4003fedfa69a0494e8b09c156f70c3e790af563642d0222514c3078966f",
+	"name": "spork-1",
+	"description": "spork description",
+	"activated": true,
+	"enforcementHeight": 9,
+	"activationHeight": 3,
+	"enforced": true,
+	"implemented": true
+}`)
+	common.Json(sporkAPI.GetById(types.HexToHashPanic("0000000000000000000000000000000000000000000000000000000000000001"))).Equals(t, `
+null`)
 }
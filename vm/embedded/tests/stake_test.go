@@ -1158,3 +1158,89 @@ func TestStake_CheckRpc(t *testing.T) {
 	"list": []
 }`)
 }
+
+// Test GetEntriesLifecycleByAddress & GetEntriesRevocableWithin
+//   - User1 stakes at 30 minutes, User2 at 45 minutes of epoch 0, checked at 50 minutes
+//     -> maturity relative to the frontier momentum, reward accrued up to the frontier
+//   - test windows before and after the expiration
+//     -> entries expiring in the window
+func TestStake_EntriesLifecycle(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	stakeApi := embedded.NewStakeApi(z)
+	defer z.StopPanic()
+
+	z.InsertMomentumsTo(30 * 6)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.StakeContract,
+		Data:          definition.ABIStake.PackMethodPanic(definition.StakeMethodName, constants.StakeTimeMinSec),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertMomentumsTo(45 * 6)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User2.Address,
+		ToAddress:     types.StakeContract,
+		Data:          definition.ABIStake.PackMethodPanic(definition.StakeMethodName, constants.StakeTimeMinSec*2),
+		TokenStandard: types.ZnnTokenStandard,
+		Amount:        big.NewInt(100 * g.Zexp),
+	}).Error(t, nil)
+	z.InsertMomentumsTo(50 * 6)
+
+	common.Json(stakeApi.GetEntriesLifecycleByAddress(g.User1.Address, 0, 10)).HideHashes().Equals(t, `
+{
+	"frontierTimestamp": 1000002990,
+	"count": 1,
+	"list": [
+		{
+			"entry": {
+				"amount": "10000000000",
+				"weightedAmount": "10000000000",
+				"startTimestamp": 1000001800,
+				"expirationTimestamp": 1000005400,
+				"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+				"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+			},
+			"durationInSec": 3600,
+			"revocableTimestamp": 1000005400,
+			"revocable": false,
+			"secondsUntilRevocable": 2410,
+			"accruedQsrReward": "426523297491"
+		}
+	]
+}`)
+	common.Json(stakeApi.GetEntriesLifecycleByAddress(g.User2.Address, 0, 10)).HideHashes().Equals(t, `
+{
+	"frontierTimestamp": 1000002990,
+	"count": 1,
+	"list": [
+		{
+			"entry": {
+				"amount": "10000000000",
+				"weightedAmount": "11000000000",
+				"startTimestamp": 1000002700,
+				"expirationTimestamp": 1000009900,
+				"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+				"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX"
+			},
+			"durationInSec": 7200,
+			"revocableTimestamp": 1000009900,
+			"revocable": false,
+			"secondsUntilRevocable": 6910,
+			"accruedQsrReward": "114336917562"
+		}
+	]
+}`)
+	common.Json(stakeApi.GetEntriesRevocableWithin(constants.StakeTimeMinSec, 0, 10)).SubJson(&struct {
+		Count int `json:"count"`
+	}{}).Equals(t, `
+{
+	"count": 1
+}`)
+	common.Json(stakeApi.GetEntriesRevocableWithin(constants.StakeTimeMinSec*2, 0, 10)).SubJson(&struct {
+		Count int `json:"count"`
+	}{}).Equals(t, `
+{
+	"count": 2
+}`)
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"math/big"
 	"testing"
+	"time"
 
 	g "github.com/zenon-network/go-zenon/chain/genesis/mock"
 	"github.com/zenon-network/go-zenon/chain/nom"
@@ -1144,3 +1145,207 @@ t=2001-09-09T01:48:10+0000 lvl=dbug msg="burned ZTS" module=embedded contract=to
 	"isUtility": false
 }`)
 }
+
+// Test GetHolders
+// - lists the holders of a token ordered by address
+// - pages over the holders
+// - drops addresses which no longer hold the token
+func TestToken_GetHolders(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+	tokenAPI := embedded.NewTokenApi(z)
+
+	issueTokenSetup(t, z)
+	autoreceive(t, z, g.User1.Address)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.TokenContract,
+		Data:      definition.ABIToken.PackMethodPanic(definition.MintMethodName, customZts, big.NewInt(20), g.User2.Address),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // cemented send-block
+	z.InsertNewMomentum() // cemented token receive-block
+	autoreceive(t, z, g.User2.Address)
+	z.InsertNewMomentum()
+	waitIndexer(t, z)
+
+	common.Json(tokenAPI.GetHolders(customZts, 0, 10)).Equals(t, `
+{
+	"momentum": {
+		"hash": "67e3ed4695a1b0973497b64102b5f42e3c9d3c965932c44435e68732ec23bd98",
+		"height": 6
+	},
+	"list": [
+		{
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"balance": "100"
+		},
+		{
+			"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"balance": "20"
+		}
+	],
+	"count": 2,
+	"more": false
+}`)
+	common.Json(tokenAPI.GetHolders(customZts, 0, 1)).SubJson(&struct {
+		Count int  `json:"count"`
+		More  bool `json:"more"`
+	}{}).Equals(t, `
+{
+	"count": 1,
+	"more": true
+}`)
+
+	z.InsertSendBlock(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     g.User2.Address,
+		TokenStandard: customZts,
+		Amount:        big.NewInt(100),
+	}, nil, mock.SkipVmChanges)
+	z.InsertNewMomentum()
+	autoreceive(t, z, g.User2.Address)
+	z.InsertNewMomentum()
+	waitIndexer(t, z)
+
+	common.Json(tokenAPI.GetHolders(customZts, 0, 10)).Equals(t, `
+{
+	"momentum": {
+		"hash": "a4b5338ebf1650e5a66a91766f5c31b1b5092a492961cc01c3b2ea669538810d",
+		"height": 8
+	},
+	"list": [
+		{
+			"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"balance": "120"
+		}
+	],
+	"count": 1,
+	"more": false
+}`)
+}
+
+// Test GetSupplyHistory
+// - the tokens created by the genesis start with a genesis event
+// - lists the issue, mint and burn events of a token with the total supply after each of them
+// - filters the events by epoch
+func TestToken_GetSupplyHistory(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	tokenAPI := embedded.NewTokenApi(z)
+
+	issueTokenSetup(t, z)
+	autoreceive(t, z, g.User1.Address)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.TokenContract,
+		Data:      definition.ABIToken.PackMethodPanic(definition.MintMethodName, customZts, big.NewInt(20), g.User2.Address),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // cemented send-block
+	z.InsertNewMomentum() // cemented token receive-block
+	defer z.CallContract(&nom.AccountBlock{
+		Address:       g.User1.Address,
+		ToAddress:     types.TokenContract,
+		Data:          definition.ABIToken.PackMethodPanic(definition.BurnMethodName),
+		TokenStandard: customZts,
+		Amount:        big.NewInt(30),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // cemented send-block
+	z.InsertNewMomentum() // cemented token receive-block
+	waitIndexer(t, z)
+
+	common.Json(tokenAPI.GetSupplyHistory(customZts, 0, 10)).Equals(t, `
+{
+	"minted": "120",
+	"burned": "30",
+	"count": 3,
+	"list": [
+		{
+			"type": "issue",
+			"hash": "d4df6779e4f5569e8a2a467709b7bdacfb31bba1264fbfe9cee609d2732c9128",
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"amount": "100",
+			"totalSupply": "100",
+			"momentumHeight": 3,
+			"momentumTimestamp": 1000000020,
+			"epoch": 0
+		},
+		{
+			"type": "mint",
+			"hash": "346d3dd9278b2cdf8f8e6e4ae82db0aa374f3e35a4d3b6eb108a570a234f0146",
+			"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"amount": "20",
+			"totalSupply": "120",
+			"momentumHeight": 5,
+			"momentumTimestamp": 1000000040,
+			"epoch": 0
+		},
+		{
+			"type": "burn",
+			"hash": "ae4aa7f03ead27190eb49207a509cd6c3d41edf87f21fe48e986779da15727bc",
+			"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+			"amount": "30",
+			"totalSupply": "90",
+			"momentumHeight": 7,
+			"momentumTimestamp": 1000000060,
+			"epoch": 0
+		}
+	]
+}`)
+	common.Json(tokenAPI.GetSupplyHistory(types.ZnnTokenStandard, 0, 0)).Equals(t, `
+{
+	"minted": "19500000000000",
+	"burned": "0",
+	"count": 1,
+	"list": [
+		{
+			"type": "genesis",
+			"hash": "0000000000000000000000000000000000000000000000000000000000000000",
+			"address": "z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg",
+			"amount": "19500000000000",
+			"totalSupply": "19500000000000",
+			"momentumHeight": 1,
+			"momentumTimestamp": 1000000000,
+			"epoch": 0
+		}
+	]
+}`)
+
+	z.InsertMomentumsTo(60*6 + 2)
+	defer z.CallContract(&nom.AccountBlock{
+		Address:   g.User1.Address,
+		ToAddress: types.TokenContract,
+		Data:      definition.ABIToken.PackMethodPanic(definition.MintMethodName, customZts, big.NewInt(5), g.User2.Address),
+	}).Error(t, nil)
+	z.InsertNewMomentum() // cemented send-block
+	z.InsertNewMomentum() // cemented token receive-block
+	waitIndexer(t, z)
+	common.Json(tokenAPI.GetSupplyHistory(customZts, 1, 1)).Equals(t, `
+{
+	"minted": "5",
+	"burned": "0",
+	"count": 1,
+	"list": [
+		{
+			"type": "mint",
+			"hash": "56ea71ece493502d5cdcd9478e9fd1e61449d9ce135e819c6143107dcc5952e5",
+			"address": "z1qr4pexnnfaexqqz8nscjjcsajy5hdqfkgadvwx",
+			"amount": "5",
+			"totalSupply": "95",
+			"momentumHeight": 364,
+			"momentumTimestamp": 1000003630,
+			"epoch": 1
+		}
+	]
+}`)
+	common.Json(tokenAPI.GetSupplyHistory(customZts, 0, 1)).SubJson(&struct {
+		Minted string `json:"minted"`
+		Burned string `json:"burned"`
+		Count  int    `json:"count"`
+	}{}).Equals(t, `
+{
+	"minted": "125",
+	"burned": "30",
+	"count": 4
+}`)
+	common.Json(tokenAPI.GetSupplyHistory(customZts, 1, 0)).Error(t, api.ErrInvalidEpochRange)
+}
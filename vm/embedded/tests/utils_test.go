@@ -4,12 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
-	"time"
 
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
-	"github.com/zenon-network/go-zenon/rpc/api"
-	"github.com/zenon-network/go-zenon/vm/constants"
 	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 )
@@ -85,28 +82,8 @@ func ListOfName() interface{} {
 	})
 }
 
-func init() {
-	// set local time to UTC for logging purposes
-	time.Local = time.UTC
-
-	constants.SentinelLockTimeWindow = 40   // 40 momentums
-	constants.SentinelRevokeTimeWindow = 20 // 20 momentums
-	constants.RewardTimeLimit = 0           // 0 seconds
-	constants.UpdateMinNumMomentums = 360   // exactly one hour
-	constants.FuseExpiration = 100
-	constants.StakeTimeUnitSec = 60 * 60
-	constants.StakeTimeMinSec = constants.StakeTimeUnitSec * 1
-	constants.StakeTimeMaxSec = constants.StakeTimeUnitSec * 12
-
-}
-
 func autoreceive(t *testing.T, z mock.MockZenon, address types.Address) {
-	ledgerApi := api.NewLedgerApi(z)
-	unreceived, err := ledgerApi.GetUnreceivedBlocksByAddress(address, 0, 50)
-	common.FailIfErr(t, err)
-	for _, block := range unreceived.List {
-		z.InsertReceiveBlock(block.AccountBlock.Header(), nil, nil, mock.SkipVmChanges)
-	}
+	receiveAll(t, z, address)
 }
 
 func signRetrieveAssetsMessage(t *testing.T, address types.Address, prv []byte, pub string) string {
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/chain/store"
+	"github.com/zenon-network/go-zenon/chain/verify"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/zenon/mock"
+)
+
+// corruptChain serves a tampered copy of the changes of momentum patchHeight and of the account-block blockHash.
+type corruptChain struct {
+	chain.Chain
+	patchHeight uint64
+	blockHash   types.Hash
+}
+
+func (c *corruptChain) GetMomentumPatch(identifier types.HashHeight) db.Patch {
+	if identifier.Height == c.patchHeight {
+		return db.NewPatch()
+	}
+	return c.Chain.GetMomentumPatch(identifier)
+}
+func (c *corruptChain) GetFrontierMomentumStore() store.Momentum {
+	return &corruptMomentumStore{Momentum: c.Chain.GetFrontierMomentumStore(), blockHash: c.blockHash}
+}
+
+type corruptMomentumStore struct {
+	store.Momentum
+	blockHash types.Hash
+}
+
+func (s *corruptMomentumStore) GetAccountBlock(header types.AccountHeader) (*nom.AccountBlock, error) {
+	block, err := s.Momentum.GetAccountBlock(header)
+	if block != nil && block.Hash == s.blockHash {
+		block.Signature = append([]byte{}, block.Signature...)
+		block.Signature[0] ^= 1
+	}
+	return block, err
+}
+
+// Verify the momentums of a chain with sends and receives
+//   - test the whole chain and a range
+//     -> no corruption
+//   - test tampered changes and account-block signatures
+//     -> first corrupt height
+//   - test a range above the frontier
+//     -> error
+func TestVerify_Momentums(t *testing.T) {
+	z := mock.NewMockZenon(t)
+	defer z.StopPanic()
+
+	simpleSendSetup(t, z)
+	z.InsertNewMomentum()
+	frontier, err := z.Chain().GetFrontierMomentumStore().GetFrontierMomentum()
+	common.FailIfErr(t, err)
+
+	var verified []uint64
+	corruption, err := verify.Momentums(z.Chain(), 0, 0, func(height uint64) {
+		verified = append(verified, height)
+	})
+	common.FailIfErr(t, err)
+	common.Expect(t, corruption, nil)
+	common.Expect(t, uint64(len(verified)), frontier.Height)
+
+	corruption, err = verify.Momentums(z.Chain(), 2, 3, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, corruption, nil)
+
+	corruption, err = verify.Momentums(&corruptChain{Chain: z.Chain(), patchHeight: 3}, 0, 0, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, corruption.Height, 3)
+
+	// the send of user1, confirmed by momentum 2
+	send, err := z.Chain().GetFrontierMomentumStore().GetMomentumByHeight(2)
+	common.FailIfErr(t, err)
+	corruption, err = verify.Momentums(&corruptChain{Chain: z.Chain(), blockHash: send.Content[0].Hash}, 0, 0, nil)
+	common.FailIfErr(t, err)
+	common.Expect(t, corruption.Height, 2)
+
+	_, err = verify.Momentums(z.Chain(), frontier.Height+1, 0, nil)
+	common.Expect(t, err, `invalid range 5 to 4, the frontier momentum is at height 4`)
+}
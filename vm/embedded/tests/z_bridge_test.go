@@ -3,11 +3,15 @@ package tests
 import (
 	"crypto/ecdsa"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	eabi "github.com/ethereum/go-ethereum/accounts/abi"
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/zenon-network/go-zenon/vm/embedded/implementation"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"testing"
 	"time"
@@ -16,8 +20,10 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/rpc/api"
 	"github.com/zenon-network/go-zenon/rpc/api/embedded"
 	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/embedded/bridge"
 	"github.com/zenon-network/go-zenon/vm/embedded/definition"
 	"github.com/zenon-network/go-zenon/zenon/mock"
 )
@@ -2549,6 +2555,242 @@ t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork
 }`)
 }
 
+// Test GetNetworkConfigHistory
+// - lists the changes of the network and of its token pairs made by the administrator
+// - the rejected calls and the time challenges of SetTokenPair are not listed
+// - keeps the changes of a removed network
+// - lists the removal of the network last
+func TestBridge_GetNetworkConfigHistory(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	activateBridgeStep0(t, z)
+
+	bridgeAPI := embedded.NewBridgeApi(z)
+	networkClass := uint32(2)
+	chainId := uint32(123)
+
+	defer z.CallContract(addNetwork(g.User5.Address, networkClass, chainId, "Ethereum", "0x323b5d4c32345ced77393b3530b1eed0f346429d", "{}")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(addNetwork(g.User4.Address, networkClass, chainId, "Ethereum", "0x323b5d4c32345ced77393b3530b1eed0f346429d", "{}")).
+		Error(t, constants.ErrPermissionDenied)
+	insertMomentums(z, 2)
+	defer z.CallContract(setNetworkMetadata(g.User5.Address, networkClass, chainId, `{"APR":15}`)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	securityInfo, err := bridgeAPI.GetSecurityInfo()
+	common.FailIfErr(t, err)
+	setTokenPair(t, z, g.User5.Address, securityInfo.SoftDelay, networkClass, chainId, types.ZnnTokenStandard, "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		true, true, false, big.NewInt(100), uint32(15), uint32(20), `{"APR": 15}`)
+	waitIndexer(t, z)
+
+	common.Json(bridgeAPI.GetNetworkConfigHistory(networkClass, chainId, 0, 10)).Equals(t, `
+{
+	"network": {
+		"networkClass": 2,
+		"chainId": 123,
+		"name": "Ethereum",
+		"contractAddress": "0x323b5d4c32345ced77393b3530b1eed0f346429d",
+		"metadata": "{\"APR\":15}",
+		"tokenPairs": [
+			{
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+				"bridgeable": true,
+				"redeemable": true,
+				"owned": false,
+				"minAmount": "100",
+				"feePercentage": 15,
+				"redeemDelay": 20,
+				"metadata": "{\"APR\": 15}"
+			}
+		]
+	},
+	"count": 3,
+	"more": false,
+	"list": [
+		{
+			"type": "setNetwork",
+			"hash": "ead77abee8df13c9c5c0cec698ce8069325e907089014a0e9c4328ad7f5fd2e9",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "Ethereum",
+			"contractAddress": "0x323b5d4c32345ced77393b3530b1eed0f346429d",
+			"metadata": "{}",
+			"tokenPair": null,
+			"momentumHeight": 12,
+			"momentumTimestamp": 1000000110
+		},
+		{
+			"type": "setNetworkMetadata",
+			"hash": "3d392a692e4317c2a574b34ba642d6e94ceafcf02594b27b88fa5dbff07e0bc1",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "",
+			"contractAddress": "",
+			"metadata": "{\"APR\":15}",
+			"tokenPair": null,
+			"momentumHeight": 16,
+			"momentumTimestamp": 1000000150
+		},
+		{
+			"type": "setTokenPair",
+			"hash": "158882f94497055094b532d66fb71446d461a39385731b3205b047393a9d0b03",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "",
+			"contractAddress": "",
+			"metadata": "",
+			"tokenPair": {
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+				"bridgeable": true,
+				"redeemable": true,
+				"owned": false,
+				"minAmount": "100",
+				"feePercentage": 15,
+				"redeemDelay": 20,
+				"metadata": "{\"APR\": 15}"
+			},
+			"momentumHeight": 30,
+			"momentumTimestamp": 1000000290
+		}
+	]
+}`)
+	common.Json(bridgeAPI.GetNetworkConfigHistory(networkClass, chainId, 1, 2)).Equals(t, `
+{
+	"network": {
+		"networkClass": 2,
+		"chainId": 123,
+		"name": "Ethereum",
+		"contractAddress": "0x323b5d4c32345ced77393b3530b1eed0f346429d",
+		"metadata": "{\"APR\":15}",
+		"tokenPairs": [
+			{
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+				"bridgeable": true,
+				"redeemable": true,
+				"owned": false,
+				"minAmount": "100",
+				"feePercentage": 15,
+				"redeemDelay": 20,
+				"metadata": "{\"APR\": 15}"
+			}
+		]
+	},
+	"count": 1,
+	"more": false,
+	"list": [
+		{
+			"type": "setTokenPair",
+			"hash": "158882f94497055094b532d66fb71446d461a39385731b3205b047393a9d0b03",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "",
+			"contractAddress": "",
+			"metadata": "",
+			"tokenPair": {
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+				"bridgeable": true,
+				"redeemable": true,
+				"owned": false,
+				"minAmount": "100",
+				"feePercentage": 15,
+				"redeemDelay": 20,
+				"metadata": "{\"APR\": 15}"
+			},
+			"momentumHeight": 30,
+			"momentumTimestamp": 1000000290
+		}
+	]
+}`)
+
+	defer z.CallContract(removeTokenPair(g.User5.Address, networkClass, chainId, types.ZnnTokenStandard, "0x5fbdb2315678afecb367f032d93f642f64180aa3")).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(removeNetwork(g.User5.Address, networkClass, chainId)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	waitIndexer(t, z)
+
+	common.Json(bridgeAPI.GetNetworkConfigHistory(networkClass, chainId, 1, 2)).Equals(t, `
+{
+	"network": null,
+	"count": 2,
+	"more": true,
+	"list": [
+		{
+			"type": "setTokenPair",
+			"hash": "158882f94497055094b532d66fb71446d461a39385731b3205b047393a9d0b03",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "",
+			"contractAddress": "",
+			"metadata": "",
+			"tokenPair": {
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+				"bridgeable": true,
+				"redeemable": true,
+				"owned": false,
+				"minAmount": "100",
+				"feePercentage": 15,
+				"redeemDelay": 20,
+				"metadata": "{\"APR\": 15}"
+			},
+			"momentumHeight": 30,
+			"momentumTimestamp": 1000000290
+		},
+		{
+			"type": "removeTokenPair",
+			"hash": "b4c5914127b3b1f674825b4c968a1ebc424151a02300d94d144143ae7e30b449",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "",
+			"contractAddress": "",
+			"metadata": "",
+			"tokenPair": {
+				"tokenStandard": "zts1znnxxxxxxxxxxxxx9z4ulx",
+				"tokenAddress": "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+				"bridgeable": false,
+				"redeemable": false,
+				"owned": false,
+				"minAmount": "0",
+				"feePercentage": 0,
+				"redeemDelay": 0,
+				"metadata": ""
+			},
+			"momentumHeight": 32,
+			"momentumTimestamp": 1000000310
+		}
+	]
+}`)
+	common.Json(bridgeAPI.GetNetworkConfigHistory(networkClass, chainId, 2, 2)).Equals(t, `
+{
+	"network": null,
+	"count": 1,
+	"more": false,
+	"list": [
+		{
+			"type": "removeNetwork",
+			"hash": "ecef5e144fb5877cddff12315f25b3f69e1096ceb3bd56930a5a41502ef0b03d",
+			"administrator": "z1qqaswvt0e3cc5sm7lygkyza9ra63cr8e6zre09",
+			"name": "",
+			"contractAddress": "",
+			"metadata": "",
+			"tokenPair": null,
+			"momentumHeight": 34,
+			"momentumTimestamp": 1000000330
+		}
+	]
+}`)
+	common.Json(bridgeAPI.GetNetworkConfigHistory(networkClass, 5, 0, 10)).Equals(t, `
+{
+	"network": null,
+	"count": 0,
+	"more": false,
+	"list": []
+}`)
+	common.Json(bridgeAPI.GetNetworkConfigHistory(networkClass, chainId, 0, api.RpcMaxPageSize+1)).Error(t, api.ErrPageSizeParamTooBig)
+}
+
 func TestBridge_Halt(t *testing.T) {
 	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
 	defer z.StopPanic()
@@ -3179,6 +3421,122 @@ t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork
 }`)
 }
 
+// Test the signers which orchestrators register with the node
+//   - test local signer
+//     -> the signature matches the one of the tss key and is accepted by the contract check
+//   - test remote signer
+//     -> the request is posted as JSON and the returned signature is used
+//   - test remote signer error
+//     -> the error of the remote signer is returned
+func TestBridge_Signer(t *testing.T) {
+	privateKey := "tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI="
+	keyBytes, err := base64.StdEncoding.DecodeString(privateKey)
+	common.FailIfErr(t, err)
+	key, err := crypto.ToECDSA(keyBytes)
+	common.FailIfErr(t, err)
+	pubKey := base64.StdEncoding.EncodeToString(crypto.FromECDSAPub(&key.PublicKey))
+
+	request := &embedded.WrapTokenRequest{
+		WrapTokenRequest: &definition.WrapTokenRequest{
+			NetworkClass:           2,
+			ChainId:                123,
+			Id:                     types.HexToHashPanic("0123456789012345678901234567890123456789012345678901234567890123"),
+			ToAddress:              "0x323b5d4c32345ced77393b3530b1eed0f346429d",
+			TokenStandard:          types.ZnnTokenStandard,
+			TokenAddress:           "0x5fbdb2315678afecb367f032d93f642f64180aa3",
+			Amount:                 big.NewInt(100),
+			Fee:                    big.NewInt(1),
+			CreationMomentumHeight: 500,
+		},
+	}
+	contractAddress := ecommon.HexToAddress("0x323b5d4c32345ced77393b3530b1eed0f346429d")
+	message, err := implementation.GetWrapTokenRequestMessage(request.WrapTokenRequest, &contractAddress)
+	common.FailIfErr(t, err)
+	signRequest := &bridge.SignRequest{
+		Method:  definition.UpdateWrapRequestMethodName,
+		Id:      request.Id,
+		Message: message,
+	}
+	expected := getUpdateWrapTokenSignature(request, contractAddress, privateKey)
+
+	signature, err := bridge.NewLocalSigner(key).Sign(signRequest)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, signature, expected)
+	_, err = implementation.CheckECDSASignature(message, pubKey, signature)
+	common.FailIfErr(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make(map[string]string)
+		common.FailIfErr(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["method"] != definition.UpdateWrapRequestMethodName || body["message"] != hex.EncodeToString(message) {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "unexpected request"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"signature": expected})
+	}))
+	defer server.Close()
+
+	signature, err = bridge.NewRemoteSigner(server.URL).Sign(signRequest)
+	common.FailIfErr(t, err)
+	common.ExpectString(t, signature, expected)
+	signRequest.Message = []byte{1}
+	_, err = bridge.NewRemoteSigner(server.URL).Sign(signRequest)
+	common.Expect(t, err, "remote signer failed. Reason: unexpected request")
+}
+
+// Test the orchestrator which signs the wrap requests with the registered signer
+//   - test nothing is signed without a signer
+//   - test signatures which the contract check rejects aren't published
+//   - test the wrap requests are signed with the tss key and the signatures are applied
+func TestBridge_Orchestrator(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	activateBridgeStep6(t, z)
+	bridgeAPI := embedded.NewBridgeApi(z)
+
+	orchestrator := bridge.NewOrchestrator(z.Chain(), z.Consensus(), z.Broadcaster())
+	common.FailIfErr(t, orchestrator.Start())
+	defer orchestrator.Stop()
+
+	signed := func() int {
+		requests, err := bridgeAPI.GetAllWrapTokenRequests(0, 5)
+		common.FailIfErr(t, err)
+		count := 0
+		for _, request := range requests.List {
+			if request.Signature != "" {
+				count += 1
+			}
+		}
+		return count
+	}
+	// the orchestrator processes the momentums on its own goroutine
+	insertMomentumsUntil := func(count int, done func() bool) {
+		for i := 0; i < count && !done(); i += 1 {
+			z.InsertNewMomentum()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	// past the confirmations to finality of the wrap requests
+	orchestrator.SetKeyPair(g.User1)
+	insertMomentumsUntil(20, func() bool { return false })
+	common.ExpectUint64(t, uint64(signed()), 0)
+
+	wrongKey, err := crypto.GenerateKey()
+	common.FailIfErr(t, err)
+	orchestrator.RegisterSigner(bridge.NewLocalSigner(wrongKey))
+	insertMomentumsUntil(5, func() bool { return false })
+	common.ExpectUint64(t, uint64(signed()), 0)
+
+	keyBytes, err := base64.StdEncoding.DecodeString("tuSwrTEUyJI1/3y5J8L8DSjzT/AQG2IK3JG+93qhhhI=")
+	common.FailIfErr(t, err)
+	key, err := crypto.ToECDSA(keyBytes)
+	common.FailIfErr(t, err)
+	orchestrator.RegisterSigner(bridge.NewLocalSigner(key))
+	insertMomentumsUntil(20, func() bool { return signed() == 2 })
+	common.ExpectUint64(t, uint64(signed()), 2)
+}
+
 func proposeAdministrator(guardian types.Address, proposedAdministrator types.Address) *nom.AccountBlock {
 	return &nom.AccountBlock{
 		Address:       guardian,
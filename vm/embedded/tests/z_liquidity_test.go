@@ -2236,6 +2236,135 @@ t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating contract state" module=embedde
 }`)
 }
 
+// Add LIQ1 token tuple (min: 1000, percentage: 70% znn, 30% qsr)
+// Add LIQ2 token tuple (min: 2000, percentage: 30% znn, 70% qsr)
+// Register two entries for User 1 (token: LIQ1, amounts: 10*10^8 and 30*10^8) and one (token: LIQ2, amount: 10*10^8)
+// Expected rewards before the update -> LIQ1 share split by the weighted stake of the entries, the LIQ2 entry gets the whole LIQ2 share
+// Uncollected rewards after the update match the expected rewards
+func TestLiquidity_GetExpectedRewards(t *testing.T) {
+	z := mock.NewMockZenonWithCustomEpochDuration(t, time.Hour)
+	defer z.StopPanic()
+	defer z.SaveLogs(common.EmbeddedLogger).HideHashes().Equals(t, `
+t=2001-09-09T01:46:50+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX Name:spork-accelerator Description:activate spork for accelerator Activated:false EnforcementHeight:0}"
+t=2001-09-09T01:47:00+0000 lvl=dbug msg=activated module=embedded contract=spork spork="&{Id:XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX Name:spork-accelerator Description:activate spork for accelerator Activated:true EnforcementHeight:9}"
+t=2001-09-09T01:50:00+0000 lvl=dbug msg=created module=embedded contract=spork spork="&{Id:XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX Name:spork-bridge Description:activate spork for bridge Activated:false EnforcementHeight:0}"
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg current-height=361 last-update-height=0
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="computer pillar-reward" module=embedded contract=pillar epoch=0 pillar-name=TEST-pillar-1 reward="&{DelegationReward:+10363852800000 BlockReward:+8568000000000 TotalReward:+18931852800000 ProducedBlockNum:119 ExpectedBlockNum:120 Weight:+2100000000000}" total-weight=2500000000000 self-weight=2100000000000
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="computer pillar-reward" module=embedded contract=pillar epoch=0 pillar-name=TEST-pillar-cool reward="&{DelegationReward:+995328000000 BlockReward:+8640000000000 TotalReward:+9635328000000 ProducedBlockNum:120 ExpectedBlockNum:120 Weight:+200000000000}" total-weight=2500000000000 self-weight=200000000000
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="computer pillar-reward" module=embedded contract=pillar epoch=0 pillar-name=TEST-pillar-znn reward="&{DelegationReward:+995328000000 BlockReward:+8640000000000 TotalReward:+9635328000000 ProducedBlockNum:120 ExpectedBlockNum:120 Weight:+200000000000}" total-weight=2500000000000 self-weight=200000000000
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="invalid update - rewards not due yet" module=embedded contract=pillar epoch=1
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r current-height=361 last-update-height=0
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating sentinel reward" module=embedded contract=sentinel epoch=0 total-znn-reward=187200000000 total-qsr-reward=500000000000 cumulated-sentinel=0 start-time=1000000000 end-time=1000003600
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="invalid update - rewards not due yet" module=embedded contract=sentinel epoch=1
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62 current-height=361 last-update-height=0
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating stake reward" module=embedded contract=stake epoch=0 total-reward=1000000000000 cumulated-stake=0 start-time=1000000000 end-time=1000003600
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="invalid update - rewards not due yet" module=embedded contract=stake epoch=1
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae current-height=361 last-update-height=0
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating liquidity stake reward" module=embedded contract=liquidity epoch=0 znn-total-amount=187200000000 qsr-total-amount=500000000000
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating liquidity balance" module=embedded contract=liquidity epoch=0 znnReward=187200000000
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating liquidity balance" module=embedded contract=liquidity epoch=0 qsrReward=500000000000
+t=2001-09-09T02:46:40+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxaccelerat0rxxxxxxxxxxp4tk22 current-height=361 last-update-height=0
+t=2001-09-09T02:46:50+0000 lvl=dbug msg="minted ZTS" module=embedded contract=token token="&{Owner:z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg TokenName:Zenon Coin TokenSymbol:ZNN TokenDomain:zenon.network TotalSupply:+19687200000000 MaxSupply:+4611686018427387903 Decimals:8 IsMintable:true IsBurnable:true IsUtility:true TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx}" minted-amount=187200000000 to-address=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae
+t=2001-09-09T02:46:50+0000 lvl=dbug msg="minted ZTS" module=embedded contract=token token="&{Owner:z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62 TokenName:QuasarCoin TokenSymbol:QSR TokenDomain:zenon.network TotalSupply:+181050000000000 MaxSupply:+4611686018427387903 Decimals:8 IsMintable:true IsBurnable:true IsUtility:true TokenStandard:zts1qsrxxxxxxxxxxxxxmrhjll}" minted-amount=500000000000 to-address=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae
+t=2001-09-09T02:47:00+0000 lvl=info msg="received donation" module=embedded contract=common embedded=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae from-address=z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0 zts=zts1znnxxxxxxxxxxxxx9z4ulx amount=187200000000
+t=2001-09-09T02:47:00+0000 lvl=info msg="received donation" module=embedded contract=common embedded=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae from-address=z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0 zts=zts1qsrxxxxxxxxxxxxxmrhjll amount=500000000000
+t=2001-09-09T03:14:20+0000 lvl=dbug msg="issued ZTS" module=embedded contract=token token="{Owner:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz TokenName:test.tok3n_liquidity-1 TokenSymbol:LIQ1 TokenDomain: TotalSupply:+10000000000 MaxSupply:+100000000000 Decimals:6 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1992nq43xn2urz3wttklc8z}"
+t=2001-09-09T03:14:40+0000 lvl=dbug msg="issued ZTS" module=embedded contract=token token="{Owner:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz TokenName:test.tok3n_liquidity-2 TokenSymbol:LIQ2 TokenDomain: TotalSupply:+20000000000 MaxSupply:+200000000000 Decimals:6 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1fres5c39axw805xswvt55j}"
+t=2001-09-09T03:15:00+0000 lvl=dbug msg="minted ZTS" module=embedded contract=token token="&{Owner:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz TokenName:test.tok3n_liquidity-1 TokenSymbol:LIQ1 TokenDomain: TotalSupply:+20000000000 MaxSupply:+100000000000 Decimals:6 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1992nq43xn2urz3wttklc8z}" minted-amount=10000000000 to-address=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz
+t=2001-09-09T03:15:20+0000 lvl=dbug msg="minted ZTS" module=embedded contract=token token="&{Owner:z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz TokenName:test.tok3n_liquidity-2 TokenSymbol:LIQ2 TokenDomain: TotalSupply:+40000000000 MaxSupply:+200000000000 Decimals:6 IsMintable:true IsBurnable:true IsUtility:false TokenStandard:zts1fres5c39axw805xswvt55j}" minted-amount=20000000000 to-address=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz
+t=2001-09-09T03:18:00+0000 lvl=dbug msg="created liquidity stake entry" module=embedded contract=stake id=XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX owner=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz amount=1000000000 weighted-amount=1000000000 duration-in-days=0
+t=2001-09-09T03:18:20+0000 lvl=dbug msg="created liquidity stake entry" module=embedded contract=stake id=XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX owner=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz amount=3000000000 weighted-amount=3000000000 duration-in-days=0
+t=2001-09-09T03:18:40+0000 lvl=dbug msg="created liquidity stake entry" module=embedded contract=stake id=XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX owner=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz amount=1000000000 weighted-amount=1000000000 duration-in-days=0
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg current-height=722 last-update-height=361
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="computer pillar-reward" module=embedded contract=pillar epoch=1 pillar-name=TEST-pillar-1 reward="&{DelegationReward:+10450877642587 BlockReward:+8640000000000 TotalReward:+19090877642587 ProducedBlockNum:120 ExpectedBlockNum:120 Weight:+2099916666666}" total-weight=2499916666666 self-weight=2099916666666
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="computer pillar-reward" module=embedded contract=pillar epoch=1 pillar-name=TEST-pillar-cool reward="&{DelegationReward:+995361178706 BlockReward:+8640000000000 TotalReward:+9635361178706 ProducedBlockNum:120 ExpectedBlockNum:120 Weight:+200000000000}" total-weight=2499916666666 self-weight=200000000000
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="computer pillar-reward" module=embedded contract=pillar epoch=1 pillar-name=TEST-pillar-znn reward="&{DelegationReward:+995361178706 BlockReward:+8640000000000 TotalReward:+9635361178706 ProducedBlockNum:120 ExpectedBlockNum:120 Weight:+200000000000}" total-weight=2499916666666 self-weight=200000000000
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="invalid update - rewards not due yet" module=embedded contract=pillar epoch=2
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxsentynelxxxxxxxxxxxxxwy0r2r current-height=722 last-update-height=361
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating sentinel reward" module=embedded contract=sentinel epoch=1 total-znn-reward=187200000000 total-qsr-reward=500000000000 cumulated-sentinel=0 start-time=1000003600 end-time=1000007200
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="invalid update - rewards not due yet" module=embedded contract=sentinel epoch=2
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62 current-height=722 last-update-height=361
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating stake reward" module=embedded contract=stake epoch=1 total-reward=1000000000000 cumulated-stake=0 start-time=1000003600 end-time=1000007200
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="invalid update - rewards not due yet" module=embedded contract=stake epoch=2
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae current-height=722 last-update-height=361
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating liquidity stake reward" module=embedded contract=liquidity epoch=1 znn-total-amount=187200000000 qsr-total-amount=500000000000
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="calculating percentages for each token" module=embedded contract=liquidity epoch=1 token-standard=zts1992nq43xn2urz3wttklc8z znn-percentage=7000 qsr-percentage=3000 znn-rewards=131040000000 qsr-rewards=150000000000
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="calculating percentages for each token" module=embedded contract=liquidity epoch=1 token-standard=zts1fres5c39axw805xswvt55j znn-percentage=3000 qsr-percentage=7000 znn-rewards=56160000000 qsr-rewards=350000000000
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating liquidity stake reward" module=embedded contract=liquidity id=XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX stake-address=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz token-standard=zts1992nq43xn2urz3wttklc8z znn-amount=97991788856 qsr-amount=112170087976
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating liquidity stake reward" module=embedded contract=liquidity id=XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX stake-address=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz token-standard=zts1fres5c39axw805xswvt55j znn-amount=56160000000 qsr-amount=350000000000
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating liquidity stake reward" module=embedded contract=liquidity id=XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX stake-address=z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz token-standard=zts1992nq43xn2urz3wttklc8z znn-amount=33048211143 qsr-amount=37829912023
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating liquidity balance" module=embedded contract=liquidity epoch=1 znnReward=1
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating liquidity balance" module=embedded contract=liquidity epoch=1 qsrReward=1
+t=2001-09-09T03:46:50+0000 lvl=dbug msg="updating contract state" module=embedded contract=common contract=z1qxemdeddedxaccelerat0rxxxxxxxxxxp4tk22 current-height=722 last-update-height=361
+t=2001-09-09T03:47:00+0000 lvl=dbug msg="minted ZTS" module=embedded contract=token token="&{Owner:z1qxemdeddedxpyllarxxxxxxxxxxxxxxxsy3fmg TokenName:Zenon Coin TokenSymbol:ZNN TokenDomain:zenon.network TotalSupply:+19687200000001 MaxSupply:+4611686018427387903 Decimals:8 IsMintable:true IsBurnable:true IsUtility:true TokenStandard:zts1znnxxxxxxxxxxxxx9z4ulx}" minted-amount=1 to-address=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae
+t=2001-09-09T03:47:00+0000 lvl=dbug msg="minted ZTS" module=embedded contract=token token="&{Owner:z1qxemdeddedxstakexxxxxxxxxxxxxxxxjv8v62 TokenName:QuasarCoin TokenSymbol:QSR TokenDomain:zenon.network TotalSupply:+181050000000001 MaxSupply:+4611686018427387903 Decimals:8 IsMintable:true IsBurnable:true IsUtility:true TokenStandard:zts1qsrxxxxxxxxxxxxxmrhjll}" minted-amount=1 to-address=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae
+t=2001-09-09T03:47:10+0000 lvl=info msg="received donation" module=embedded contract=common embedded=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae from-address=z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0 zts=zts1znnxxxxxxxxxxxxx9z4ulx amount=1
+t=2001-09-09T03:47:10+0000 lvl=info msg="received donation" module=embedded contract=common embedded=z1qxemdeddedxlyquydytyxxxxxxxxxxxxflaaae from-address=z1qxemdeddedxt0kenxxxxxxxxxxxxxxxxh9amk0 zts=zts1qsrxxxxxxxxxxxxxmrhjll amount=1`)
+
+	// activate sporks and set guardians
+	activateLiquidityStep1(t, z)
+
+	liquidityAPI := embedded.NewLiquidityApi(z)
+	znnPercentages := []uint32{uint32(7000), uint32(3000)}
+	qsrPercentages := []uint32{uint32(3000), uint32(7000)}
+	minAmounts := []*big.Int{big.NewInt(1000), big.NewInt(2000)}
+	issueMultipleTokensSetup(t, z)
+	setTokensTuple(t, z, g.User5.Address, tokensString, znnPercentages, qsrPercentages, minAmounts)
+
+	defer z.CallContract(liquidityStake(g.User1.Address, tokens[0], big.NewInt(10*g.Zexp), 1)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(liquidityStake(g.User1.Address, tokens[0], big.NewInt(30*g.Zexp), 1)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+	defer z.CallContract(liquidityStake(g.User1.Address, tokens[1], big.NewInt(10*g.Zexp), 1)).
+		Error(t, nil)
+	insertMomentums(z, 2)
+
+	common.Json(liquidityAPI.GetExpectedRewards(g.User2.Address)).Equals(t, `
+{
+	"fromEpoch": 1,
+	"toEpoch": 1,
+	"znnAmount": "0",
+	"qsrAmount": "0",
+	"list": []
+}`)
+	common.Json(liquidityAPI.GetExpectedRewards(g.User1.Address)).HideHashes().Equals(t, `
+{
+	"fromEpoch": 1,
+	"toEpoch": 1,
+	"znnAmount": "187199999999",
+	"qsrAmount": "499999999999",
+	"list": [
+		{
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"tokenStandard": "zts1992nq43xn2urz3wttklc8z",
+			"znnAmount": "97991788856",
+			"qsrAmount": "112170087976"
+		},
+		{
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"tokenStandard": "zts1fres5c39axw805xswvt55j",
+			"znnAmount": "56160000000",
+			"qsrAmount": "350000000000"
+		},
+		{
+			"id": "XXXHASHXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX",
+			"tokenStandard": "zts1992nq43xn2urz3wttklc8z",
+			"znnAmount": "33048211143",
+			"qsrAmount": "37829912023"
+		}
+	]
+}`)
+	insertMomentums(z, 300)
+	common.Json(liquidityAPI.GetUncollectedReward(g.User1.Address)).Equals(t, `
+{
+	"address": "z1qzal6c5s9rjnnxd2z7dvdhjxpmmj4fmw56a0mz",
+	"znnAmount": "187199999999",
+	"qsrAmount": "499999999999"
+}`)
+}
+
 // Add LIQ1 token tuple (min: 1000, percentage: 70% znn, 30% qsr)
 // Add LIQ2 token tuple (min: 2000, percentage: 30% znn, 70% qsr)
 // Register an entry for User 1 (token: LIQ1, amount: 10*10^8)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/types"
@@ -91,7 +92,11 @@ func GetBasePlasmaForAccountBlock(context vm_context.AccountVmContext, block *no
 		return constants.AccountBlockBasePlasma, nil
 	} else {
 		if method, err := embedded.GetEmbeddedMethod(context, block.ToAddress, block.Data); err == constants.ErrNotContractAddress {
-			if len(block.Data) > constants.MaxDataLength {
+			limits, err := chain.GetPayloadLimits(context.MomentumStore())
+			if err != nil {
+				return 0, err
+			}
+			if len(block.Data) > limits.MaxDataLength {
 				return 0, verifier.ErrABDataTooBig
 			}
 			return uint64(len(block.Data)*constants.ABByteDataPlasma + constants.AccountBlockBasePlasma), nil
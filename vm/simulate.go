@@ -0,0 +1,73 @@
+package vm
+
+import (
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/constants"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+// Simulation is the outcome of a send-block to an embedded contract, executed on top of the frontier state.
+type Simulation struct {
+	SendBlock     *nom.AccountBlock
+	ReceiveBlock  *nom.AccountBlock // nil if the send-block is invalid
+	SendError     error             // the reason the send-block is invalid
+	ReturnedError error             // the error returned by the embedded method
+	Changes       db.Patch          // state changes of the embedded contract
+}
+
+// Simulate fills in and applies the send-block template, then generates the receive-block of the embedded
+// contract. Nothing is published or committed and the plasma of the send-block isn't checked.
+func (s *Supervisor) Simulate(template *nom.AccountBlock) (result *Simulation, internalErr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			s.log.Error("vm panic when simulating block", "reason", err, "stack", string(debug.Stack()))
+
+			result = nil
+			internalErr = constants.ErrVmRunPanic
+		}
+	}()
+
+	if template.BlockType != nom.BlockTypeUserSend {
+		return nil, errors.Errorf("can only simulate BlockTypeUserSend")
+	}
+	if !types.IsEmbeddedAddress(template.ToAddress) {
+		return nil, constants.ErrNotContractAddress
+	}
+	if err := s.setAll(template); err != nil {
+		return nil, err
+	}
+	template.Hash = template.ComputeHash()
+
+	result = &Simulation{
+		SendBlock: template,
+	}
+	if err := NewVM(s.newBlockContext(template)).applySend(template); err != nil {
+		result.SendError = err
+		return result, nil
+	}
+
+	context := vm_context.NewAccountContext(
+		s.chain.GetMomentumStore(template.MomentumAcknowledged),
+		s.chain.GetFrontierAccountStore(template.ToAddress),
+		s.consensus.FixedPillarReader(template.MomentumAcknowledged),
+	)
+	block, methodErr, err := NewVM(context).executeEmbedded(template)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := context.Changes()
+	if err != nil {
+		return nil, err
+	}
+	s.setBlockFields(block)
+	result.ReceiveBlock = block
+	result.ReturnedError = methodErr
+	result.Changes = changes
+	return result, nil
+}
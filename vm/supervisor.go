@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"runtime/debug"
@@ -11,6 +12,7 @@ import (
 	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/common"
 	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/tracing"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
 	"github.com/zenon-network/go-zenon/verifier"
@@ -63,6 +65,13 @@ func (s *Supervisor) newBlockContext(block *nom.AccountBlock) vm_context.Account
 		cache,
 	)
 }
+
+// newVM returns a VM which traces the embedded methods it executes if tracing is on, see SetTracer.
+func (s *Supervisor) newVM(context vm_context.AccountVmContext) *VM {
+	vm := NewVM(context)
+	vm.tracer = GetTracer()
+	return vm
+}
 func (s *Supervisor) newMomentumContext(momentum *nom.Momentum) vm_context.MomentumVMContext {
 	return vm_context.NewMomentumVMContext(
 		s.chain.GetMomentumStore(momentum.Previous()),
@@ -70,10 +79,21 @@ func (s *Supervisor) newMomentumContext(momentum *nom.Momentum) vm_context.Momen
 }
 
 func (s *Supervisor) ApplyBlock(block *nom.AccountBlock) (*nom.AccountBlockTransaction, error) {
+	return s.ApplyBlockContext(context.Background(), block)
+}
+
+// ApplyBlockContext is ApplyBlock traced as a child of the span of ctx, if any.
+func (s *Supervisor) ApplyBlockContext(ctx context.Context, block *nom.AccountBlock) (*nom.AccountBlockTransaction, error) {
 	if block.BlockType == nom.BlockTypeContractSend {
 		return nil, errors.Errorf("can't apply BlockTypeContractSend")
 	}
-	return s.applyBlock(block, nil)
+	return s.applyBlock(ctx, block, nil)
+}
+
+// PrecheckAccountBlocks verifies the hash, signature and PoW of the blocks in parallel ahead of
+// ApplyBlock, which doesn't verify them again. See verifier.AccountBlockVerifier.
+func (s *Supervisor) PrecheckAccountBlocks(blocks []*nom.AccountBlock) error {
+	return s.verifier.PrecheckAccountBlocks(blocks)
 }
 func (s *Supervisor) ApplyMomentum(detailed *nom.DetailedMomentum) (result *nom.MomentumTransaction, internalErr error) {
 	momentum := detailed.Momentum
@@ -106,11 +126,17 @@ func (s *Supervisor) GenerateFromTemplate(template *nom.AccountBlock, signFunc S
 	if err := s.setAll(template); err != nil {
 		return nil, err
 	}
-	context := s.newBlockContext(template)
-	if err := s.setBlockPlasma(context, template); err != nil {
+	blockContext := s.newBlockContext(template)
+	if err := s.setBlockPlasma(blockContext, template); err != nil {
 		return nil, err
 	}
-	return s.applyBlock(template, signFunc)
+	return s.applyBlock(context.Background(), template, signFunc)
+}
+
+// FillTemplate sets the momentum-acknowledged, height, previous-hash and chain fields of the template,
+// without computing the plasma or applying the block.
+func (s *Supervisor) FillTemplate(template *nom.AccountBlock) error {
+	return s.setAll(template)
 }
 func (s *Supervisor) GenerateAutoReceive(sendBlock *nom.AccountBlock) (*ContractExecution, error) {
 	template := &nom.AccountBlock{
@@ -129,7 +155,7 @@ func (s *Supervisor) GenerateAutoReceive(sendBlock *nom.AccountBlock) (*Contract
 	if err := s.setBlockPlasma(context, template); err != nil {
 		return nil, err
 	}
-	vm := NewVM(context)
+	vm := s.newVM(context)
 	block, methodErr, err := vm.generateEmbeddedReceive(template.FromBlockHash)
 	if err := s.verifier.AccountBlock(block); err != nil {
 		return nil, err
@@ -142,6 +168,8 @@ func (s *Supervisor) GenerateAutoReceive(sendBlock *nom.AccountBlock) (*Contract
 	if err != nil {
 		return nil, err
 	}
+	transaction.Failure = vm.failure
+	vm.saveTrace()
 
 	return &ContractExecution{
 		Transaction:   transaction,
@@ -197,7 +225,11 @@ func (s *Supervisor) GenerateGenesisMomentum(template *nom.Momentum, pool chain.
 	return transaction, nil
 }
 
-func (s *Supervisor) applyBlock(block *nom.AccountBlock, signFunc SignFunc) (transaction *nom.AccountBlockTransaction, internalErr error) {
+func (s *Supervisor) applyBlock(ctx context.Context, block *nom.AccountBlock, signFunc SignFunc) (transaction *nom.AccountBlockTransaction, internalErr error) {
+	ctx, span := tracing.StartChildSpan(ctx, "vm.applyBlock")
+	span.SetAttribute("block.address", block.Address.String())
+	span.SetAttribute("block.height", block.Height)
+	span.SetAttribute("block.type", block.BlockType)
 	defer func() {
 		if err := recover(); err != nil {
 			l := s.log.New("block", block.Header())
@@ -206,22 +238,32 @@ func (s *Supervisor) applyBlock(block *nom.AccountBlock, signFunc SignFunc) (tra
 			transaction = nil
 			internalErr = constants.ErrVmRunPanic
 		}
+		span.Finish(internalErr)
 	}()
 
-	if err := s.verifier.AccountBlock(block); err != nil {
+	_, verifySpan := tracing.StartChildSpan(ctx, "verifier.accountBlock")
+	err := s.verifier.AccountBlock(block)
+	verifySpan.Finish(err)
+	if err != nil {
 		return nil, err
 	}
+	_, executeSpan := tracing.StartChildSpan(ctx, "vm.execute")
 	context := s.newBlockContext(block)
-	vm := NewVM(context)
-	err := vm.applyBlock(block)
+	vm := s.newVM(context)
+	err = vm.applyBlock(block)
+	executeSpan.Finish(err)
 	if err != nil {
 		return nil, err
 	}
 
+	_, packSpan := tracing.StartChildSpan(ctx, "vm.packBlock")
 	transaction, err = s.packBlock(context, block, signFunc)
+	packSpan.Finish(err)
 	if err != nil {
 		return nil, err
 	}
+	transaction.Failure = vm.failure
+	vm.saveTrace()
 
 	return transaction, nil
 }
@@ -265,6 +307,7 @@ func (s *Supervisor) packBlock(context vm_context.AccountVmContext, block *nom.A
 	transaction := &nom.AccountBlockTransaction{
 		Block:   block,
 		Changes: changes,
+		Logs:    context.Logs(),
 	}
 	if err := s.verifier.AccountBlockTransaction(transaction); err != nil {
 		return nil, err
@@ -0,0 +1,274 @@
+package vm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/common/types"
+	"github.com/zenon-network/go-zenon/vm/embedded"
+	"github.com/zenon-network/go-zenon/vm/vm_context"
+)
+
+var (
+	tracerLock sync.RWMutex
+	tracer     *Tracer
+)
+
+// SetTracer turns on the tracing of the embedded methods called by the contract receive-blocks
+// which are applied or generated from now on, a nil tracer turns it off.
+func SetTracer(t *Tracer) {
+	tracerLock.Lock()
+	defer tracerLock.Unlock()
+	tracer = t
+}
+
+// GetTracer returns the tracer set by SetTracer, nil if tracing is off.
+func GetTracer() *Tracer {
+	tracerLock.RLock()
+	defer tracerLock.RUnlock()
+	return tracer
+}
+
+// TraceInput is a decoded argument of the embedded method.
+type TraceInput struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// TraceStorageAccess is a read or a write of the storage of the contract, the value of a deleted key is empty.
+// Keys and values are hex encoded.
+type TraceStorageAccess struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type TraceAmount struct {
+	TokenStandard types.ZenonTokenStandard `json:"tokenStandard"`
+	Amount        string                   `json:"amount"`
+}
+
+// Trace records what the embedded method called by a contract receive-block did.
+// Reads and writes are in the order of the calls and include the ones reverted if the method failed.
+type Trace struct {
+	BlockHash        types.Hash            `json:"blockHash"`
+	SendBlockHash    types.Hash            `json:"sendBlockHash"`
+	Contract         types.Address         `json:"contract"`
+	Method           string                `json:"method"`
+	Inputs           []*TraceInput         `json:"inputs"`
+	Reads            []*TraceStorageAccess `json:"reads"`
+	Writes           []*TraceStorageAccess `json:"writes"`
+	Minted           []*TraceAmount        `json:"minted"`
+	Burned           []*TraceAmount        `json:"burned"`
+	DescendantBlocks []types.Hash          `json:"descendantBlocks"`
+	// Error is returned by the method, an empty one means the call succeeded
+	Error string `json:"error"`
+}
+
+func newTrace(context vm_context.AccountVmContext, sendBlock *nom.AccountBlock) *Trace {
+	trace := &Trace{
+		SendBlockHash:    sendBlock.Hash,
+		Contract:         sendBlock.ToAddress,
+		Inputs:           make([]*TraceInput, 0),
+		Reads:            make([]*TraceStorageAccess, 0),
+		Writes:           make([]*TraceStorageAccess, 0),
+		Minted:           make([]*TraceAmount, 0),
+		Burned:           make([]*TraceAmount, 0),
+		DescendantBlocks: make([]types.Hash, 0),
+	}
+
+	abiMethod, err := embedded.GetEmbeddedAbiMethod(context, sendBlock.ToAddress, sendBlock.Data)
+	if err != nil {
+		return trace
+	}
+	trace.Method = abiMethod.Name
+	values, err := abiMethod.Inputs.UnpackValues(sendBlock.Data[4:])
+	if err != nil {
+		return trace
+	}
+	for i, value := range values {
+		trace.Inputs = append(trace.Inputs, &TraceInput{
+			Name:  abiMethod.Inputs[i].Name,
+			Type:  abiMethod.Inputs[i].Type.String(),
+			Value: formatTraceValue(value),
+		})
+	}
+	return trace
+}
+
+func formatTraceValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return hex.EncodeToString(v)
+	case [32]byte:
+		return hex.EncodeToString(v[:])
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+func newTraceAmount(ts *types.ZenonTokenStandard, amount *big.Int) *TraceAmount {
+	return &TraceAmount{
+		TokenStandard: *ts,
+		Amount:        amount.String(),
+	}
+}
+
+// tracingContext records the calls of the embedded method to the storage and the balance of the contract.
+type tracingContext struct {
+	vm_context.AccountVmContext
+	trace *Trace
+}
+
+func newTracingContext(context vm_context.AccountVmContext, trace *Trace) vm_context.AccountVmContext {
+	return &tracingContext{
+		AccountVmContext: context,
+		trace:            trace,
+	}
+}
+
+func (ctx *tracingContext) Storage() db.DB {
+	return &tracingDB{
+		DB:    ctx.AccountVmContext.Storage(),
+		trace: ctx.trace,
+	}
+}
+func (ctx *tracingContext) AddBalance(ts *types.ZenonTokenStandard, amount *big.Int) {
+	ctx.trace.Minted = append(ctx.trace.Minted, newTraceAmount(ts, amount))
+	ctx.AccountVmContext.AddBalance(ts, amount)
+}
+func (ctx *tracingContext) SubBalance(ts *types.ZenonTokenStandard, amount *big.Int) {
+	ctx.trace.Burned = append(ctx.trace.Burned, newTraceAmount(ts, amount))
+	ctx.AccountVmContext.SubBalance(ts, amount)
+}
+
+type tracingDB struct {
+	db.DB
+	prefix []byte
+	trace  *Trace
+}
+
+func (d *tracingDB) read(key, value []byte) {
+	d.trace.Reads = append(d.trace.Reads, &TraceStorageAccess{
+		Key:   hex.EncodeToString(common.JoinBytes(d.prefix, key)),
+		Value: hex.EncodeToString(value),
+	})
+}
+func (d *tracingDB) write(key, value []byte) {
+	d.trace.Writes = append(d.trace.Writes, &TraceStorageAccess{
+		Key:   hex.EncodeToString(common.JoinBytes(d.prefix, key)),
+		Value: hex.EncodeToString(value),
+	})
+}
+
+func (d *tracingDB) Get(key []byte) ([]byte, error) {
+	value, err := d.DB.Get(key)
+	if err == nil {
+		d.read(key, value)
+	}
+	return value, err
+}
+func (d *tracingDB) Put(key, value []byte) error {
+	d.write(key, value)
+	return d.DB.Put(key, value)
+}
+func (d *tracingDB) Delete(key []byte) error {
+	d.write(key, nil)
+	return d.DB.Delete(key)
+}
+func (d *tracingDB) NewIterator(prefix []byte) db.StorageIterator {
+	return &tracingIterator{
+		StorageIterator: d.DB.NewIterator(prefix),
+		db:              d,
+	}
+}
+func (d *tracingDB) NewIteratorFrom(prefix, start []byte) db.StorageIterator {
+	return &tracingIterator{
+		StorageIterator: d.DB.NewIteratorFrom(prefix, start),
+		db:              d,
+	}
+}
+func (d *tracingDB) Subset(prefix []byte) db.DB {
+	return &tracingDB{
+		DB:     d.DB.Subset(prefix),
+		prefix: common.JoinBytes(d.prefix, prefix),
+		trace:  d.trace,
+	}
+}
+
+type tracingIterator struct {
+	db.StorageIterator
+	db *tracingDB
+}
+
+func (it *tracingIterator) Next() bool {
+	if !it.StorageIterator.Next() {
+		return false
+	}
+	it.db.read(it.Key(), it.Value())
+	return true
+}
+
+var (
+	traceKeyPrefix = []byte{1}
+)
+
+func getTraceKey(hash types.Hash) []byte {
+	return common.JoinBytes(traceKeyPrefix, hash.Bytes())
+}
+
+// Tracer keeps the traces of the contract receive-blocks, keyed by the hash of the receive-block.
+// Traces of blocks which are rolled back are kept, the same block always has the same trace.
+type Tracer struct {
+	log     common.Logger
+	db      db.DB
+	changes sync.Mutex
+}
+
+func NewTracer(db db.DB) *Tracer {
+	return &Tracer{
+		log: common.VmLogger.New("submodule", "tracer"),
+		db:  db,
+	}
+}
+
+func (t *Tracer) save(trace *Trace) {
+	t.changes.Lock()
+	defer t.changes.Unlock()
+
+	data, err := rlp.EncodeToBytes(trace)
+	if err == nil {
+		err = t.db.Put(getTraceKey(trace.BlockHash), data)
+	}
+	if err != nil {
+		t.log.Error("failed to save trace", "block-hash", trace.BlockHash, "reason", err)
+	}
+}
+
+// GetTrace returns the trace of the contract receive-block, nil if it wasn't traced.
+func (t *Tracer) GetTrace(hash types.Hash) (*Trace, error) {
+	t.changes.Lock()
+	defer t.changes.Unlock()
+
+	data, err := t.db.Get(getTraceKey(hash))
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	trace := new(Trace)
+	if err := rlp.DecodeBytes(data, trace); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
@@ -34,8 +34,21 @@ func errToStatus(err error) uint64 {
 	}
 }
 
+// ReceiveSucceeded reports whether the embedded contract accepted the call of a contract receive-block,
+// the changes of a failed call are reverted and the sent tokens refunded. Other blocks always succeed.
+func ReceiveSucceeded(block *nom.AccountBlock) bool {
+	return block.BlockType != nom.BlockTypeContractReceive || common.BytesToUint64(block.Data) == resultSuccess
+}
+
 type VM struct {
 	context vm_context.AccountVmContext
+
+	// tracer is only set by the supervisor when tracing is on, see SetTracer
+	tracer *Tracer
+	trace  *Trace
+
+	// failure is set when the embedded contract rejects the call of the receive-block
+	failure *nom.ReceiveFailure
 }
 
 func NewVM(context vm_context.AccountVmContext) *VM {
@@ -164,38 +177,46 @@ func (vm *VM) generateEmbeddedReceive(fromBlockHash types.Hash) (*nom.AccountBlo
 	if err != nil {
 		return nil, nil, err
 	}
+	return vm.executeEmbedded(sendBlock)
+}
+
+// executeEmbedded calls the embedded method of the send-block and generates the receive-block.
+// The send-block doesn't have to be in the chain, which allows simulating contract calls.
+func (vm *VM) executeEmbedded(sendBlock *nom.AccountBlock) (*nom.AccountBlock, error, error) {
 	method, err := embedded.GetEmbeddedMethod(vm.context, sendBlock.ToAddress, sendBlock.Data)
 
 	// can happen when a method is deleted in a spork (height 100) and someone calls it before the spork (height 95)
 	// and the autoReceive uses momentum height 105 for various reasons
 	if err == constants.ErrContractMethodNotFound {
-		return vm.rollbackEmbedded(fromBlockHash, err)
+		return vm.rollbackEmbedded(sendBlock, err)
 	}
 
 	vm.context.Save()
 	// balance
 	vm.context.AddBalance(&sendBlock.TokenStandard, sendBlock.Amount)
 	// call code
-	descendantBlocks, err := method.ReceiveBlock(vm.context, sendBlock)
+	context := vm.context
+	if vm.tracer != nil {
+		vm.trace = newTrace(vm.context, sendBlock)
+		context = newTracingContext(vm.context, vm.trace)
+	}
+	descendantBlocks, err := method.ReceiveBlock(context, sendBlock)
 	if err != nil {
-		return vm.rollbackEmbedded(fromBlockHash, err)
+		return vm.rollbackEmbedded(sendBlock, err)
 	}
 	// apply send-descendant-blocks
 	for _, dblock := range descendantBlocks {
 		err := vm.applySend(dblock)
 		if err != nil {
-			return vm.rollbackEmbedded(fromBlockHash, err)
+			return vm.rollbackEmbedded(sendBlock, err)
 		}
 	}
 
 	// everything went right, no rollback required
 	vm.context.Done()
-	return vm.finalizeEmbedded(fromBlockHash, descendantBlocks, nil)
+	return vm.finalizeEmbedded(sendBlock.Hash, descendantBlocks, nil)
 }
-func (vm *VM) rollbackEmbedded(fromBlockHash types.Hash, methodErr error) (*nom.AccountBlock, error, error) {
-	sendBlock, err := vm.context.MomentumStore().GetAccountBlockByHash(fromBlockHash)
-	common.DealWithErr(err) // impossible to not find send-block at rollback
-
+func (vm *VM) rollbackEmbedded(sendBlock *nom.AccountBlock, methodErr error) (*nom.AccountBlock, error, error) {
 	vm.context.Reset()
 	// If sendBlock contains amount, add current amount to embedded to be able to refund it
 	// This operation was rollbacked with vm.context.Reset()
@@ -221,7 +242,7 @@ func (vm *VM) rollbackEmbedded(fromBlockHash types.Hash, methodErr error) (*nom.
 		descendantBlocks = append(descendantBlocks, dBlock)
 	}
 
-	return vm.finalizeEmbedded(fromBlockHash, descendantBlocks, methodErr)
+	return vm.finalizeEmbedded(sendBlock.Hash, descendantBlocks, methodErr)
 }
 func (vm *VM) finalizeEmbedded(fromBlockHash types.Hash, descendantBlocks []*nom.AccountBlock, executionError error) (*nom.AccountBlock, error, error) {
 	var err error
@@ -269,9 +290,31 @@ func (vm *VM) finalizeEmbedded(fromBlockHash types.Hash, descendantBlocks []*nom
 	}
 
 	block.Hash = block.ComputeHash()
+	if executionError != nil {
+		vm.failure = &nom.ReceiveFailure{
+			Code:   constants.FailureCode(executionError),
+			Reason: executionError.Error(),
+		}
+	}
+	if vm.trace != nil {
+		vm.trace.BlockHash = block.Hash
+		for _, dblock := range descendantBlocks {
+			vm.trace.DescendantBlocks = append(vm.trace.DescendantBlocks, dblock.Hash)
+		}
+		if executionError != nil {
+			vm.trace.Error = executionError.Error()
+		}
+	}
 	return block, executionError, nil
 }
 
+// saveTrace saves the trace of the executed embedded method, if any.
+func (vm *VM) saveTrace() {
+	if vm.trace != nil {
+		vm.tracer.save(vm.trace)
+	}
+}
+
 type MomentumVM struct {
 	context vm_context.MomentumVMContext
 }
@@ -2,6 +2,7 @@ package vm_context
 
 import (
 	"github.com/zenon-network/go-zenon/chain/account"
+	"github.com/zenon-network/go-zenon/chain/nom"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
@@ -13,12 +14,22 @@ type accountVmContext struct {
 	api.PillarReader
 	store.Account
 	momentumStore store.Momentum
+
+	logs      []*nom.Log
+	savedLogs int
 }
 
 func (ctx *accountVmContext) MomentumStore() store.Momentum {
 	return ctx.momentumStore
 }
 
+func (ctx *accountVmContext) AddLog(log *nom.Log) {
+	ctx.logs = append(ctx.logs, log)
+}
+func (ctx *accountVmContext) Logs() []*nom.Log {
+	return ctx.logs
+}
+
 func NewAccountContext(momentumStore store.Momentum, accountBlock store.Account, pillarReader api.PillarReader) AccountVmContext {
 	return &accountVmContext{
 		momentumStore: momentumStore,
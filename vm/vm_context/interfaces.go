@@ -30,6 +30,12 @@ type AccountVmContext interface {
 	AddBalance(ts *types.ZenonTokenStandard, amount *big.Int)
 	SubBalance(ts *types.ZenonTokenStandard, amount *big.Int)
 
+	// ====== Logs ======
+
+	// AddLog records a log emitted by the contract, logs added after Save are dropped by Reset.
+	AddLog(log *nom.Log)
+	Logs() []*nom.Log
+
 	// ====== Spork ======
 
 	IsAcceleratorSporkEnforced() bool
@@ -8,10 +8,12 @@ func (ctx *accountVmContext) Save() {
 	s := ctx.Account.Snapshot()
 	ctx.accountStoreSnapshot = ctx.Account
 	ctx.Account = s
+	ctx.savedLogs = len(ctx.logs)
 }
 func (ctx *accountVmContext) Reset() {
 	ctx.Account = ctx.accountStoreSnapshot
 	ctx.accountStoreSnapshot = nil
+	ctx.logs = ctx.logs[:ctx.savedLogs]
 }
 func (ctx *accountVmContext) Done() {
 	changes, _ := ctx.Account.Changes()
@@ -8,6 +8,7 @@ var (
 	ErrKeyFileInvalidVersion = errors.New("unable to read KeyFile. Invalid version")
 	ErrKeyFileInvalidCipher  = errors.New("unable to read KeyFile. Invalid cipherName")
 	ErrKeyFileInvalidKDF     = errors.New("unable to read KeyFile. Invalid key derivation function (KDF)")
+	ErrKeyFileInvalidArgon2  = errors.New("unable to read KeyFile. Missing argon2 parameters")
 
 	// === keyStore errors ===
 
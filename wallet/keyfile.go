@@ -10,9 +10,12 @@ import (
 )
 
 const (
-	cryptoStoreVersion = 1
-	aesMode            = "aes-256-gcm"
-	argonName          = "argon2.IDKey"
+	// cryptoStoreVersion of the new key files. Version 2 records the argon2 parameters, which the
+	// nodes that only know version 1 would ignore and derive the wrong key with.
+	cryptoStoreVersion       = 2
+	legacyCryptoStoreVersion = 1
+	aesMode                  = "aes-256-gcm"
+	argonName                = "argon2.IDKey"
 )
 
 type KeyFile struct {
@@ -34,8 +37,28 @@ type cryptoParams struct {
 	Argon2Params argon2Params  `json:"argon2Params"`
 }
 
+// argon2Params of the key derivation, the legacy key files only have the salt.
 type argon2Params struct {
-	Salt hexutil.Bytes `json:"salt"`
+	Salt    hexutil.Bytes `json:"salt"`
+	Time    uint32        `json:"time,omitempty"`
+	Memory  uint32        `json:"memory,omitempty"`
+	Threads uint8         `json:"threads,omitempty"`
+}
+
+func (p argon2Params) get() (time, memory uint32, threads uint8) {
+	if p.isLegacy() {
+		return legacyArgon2Time, legacyArgon2Memory, legacyArgon2Threads
+	}
+	return p.Time, p.Memory, p.Threads
+}
+func (p argon2Params) isLegacy() bool {
+	return p.Time == 0 || p.Memory == 0 || p.Threads == 0
+}
+
+// isWeak is true if the parameters are weaker than the defaults, which includes the legacy ones.
+func (p argon2Params) isWeak() bool {
+	time, memory, _ := p.get()
+	return time < DefaultArgon2Time || memory < DefaultArgon2Memory
 }
 
 func ReadKeyFile(path string) (*KeyFile, error) {
@@ -51,7 +74,13 @@ func ReadKeyFile(path string) (*KeyFile, error) {
 	if err := json.Unmarshal(keyFileJson, k); err != nil {
 		return nil, err
 	}
-	if k.Version != cryptoStoreVersion {
+	switch k.Version {
+	case legacyCryptoStoreVersion:
+	case cryptoStoreVersion:
+		if k.Crypto.Argon2Params.isLegacy() {
+			return nil, ErrKeyFileInvalidArgon2
+		}
+	default:
 		return nil, ErrKeyFileInvalidVersion
 	}
 
@@ -69,7 +98,19 @@ func (kf *KeyFile) Write() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(kf.Path, keyFileJson, 0700)
+	// write next to the key file and rename it, so an interrupted write never loses the existing key file;
+	// files ending in ~ are skipped by ListEntropyFilesInStandardDir
+	tmpPath := kf.Path + "~"
+	if err := os.WriteFile(tmpPath, keyFileJson, 0700); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, kf.Path)
+}
+
+// NeedsUpgrade is true if the key file is encrypted with weaker argon2 parameters than the defaults
+// or has an older version.
+func (kf *KeyFile) NeedsUpgrade() bool {
+	return kf.Crypto.Argon2Params.isWeak() || kf.Version < cryptoStoreVersion
 }
 
 func (kf *KeyFile) Decrypt(password string) (*KeyStore, error) {
@@ -86,3 +127,21 @@ func (kf *KeyFile) Decrypt(password string) (*KeyStore, error) {
 
 	return keyStoreFromEntropy(entropy)
 }
+
+// Reencrypt returns the key file encrypted with newPassword and the default argon2 parameters,
+// at the same path and with the current version. The key file isn't written.
+func (kf *KeyFile) Reencrypt(password, newPassword string) (*KeyFile, error) {
+	ks, err := kf.Decrypt(password)
+	if err != nil {
+		return nil, err
+	}
+	defer ks.Zero()
+
+	upgraded, err := ks.Encrypt(newPassword)
+	if err != nil {
+		return nil, err
+	}
+	upgraded.Path = kf.Path
+	upgraded.Timestamp = kf.Timestamp
+	return upgraded, nil
+}
@@ -0,0 +1,187 @@
+package wallet
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/zenon-network/go-zenon/common/types"
+)
+
+var testEntropy = hexutil.MustDecode("0x6d1e0a3c7c9f4ac7c2f6e0bd6c4b8d1a9f2e3c4b5a69788796a5b4c3d2e1f001")
+
+// weakArgon2Params keep the tests fast, the key files encrypted with them need an upgrade.
+var weakArgon2Params = argon2Params{Salt: hexutil.MustDecode("0x7c3f63d89642c3b40036ced64dafbc88"), Time: 1, Memory: 64, Threads: 1}
+
+// testKeyFile encrypts testEntropy with password and params, like KeyStore.Encrypt with other parameters.
+func testKeyFile(t *testing.T, password string, params argon2Params, version int) *KeyFile {
+	derivedKey := new(passwordHash)
+	if err := derivedKey.SetFromJSON(password, params); err != nil {
+		t.Fatal(err)
+	}
+	cipherData, nonce, err := aesGCMEncrypt(derivedKey.password[:], testEntropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := keyStoreFromEntropy(testEntropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &KeyFile{
+		Path:        filepath.Join(t.TempDir(), "key"),
+		BaseAddress: ks.BaseAddress,
+		Crypto: cryptoParams{
+			CipherName:   aesMode,
+			KDF:          argonName,
+			CipherData:   cipherData,
+			AesNonce:     nonce,
+			Argon2Params: params,
+		},
+		Version:   version,
+		Timestamp: 1700000000,
+	}
+}
+
+func testWriteAndDecrypt(t *testing.T, kf *KeyFile, password string) *KeyFile {
+	if err := kf.Write(); err != nil {
+		t.Fatal(err)
+	}
+	return testReadAndDecrypt(t, kf.Path, kf.BaseAddress, password)
+}
+
+func testReadAndDecrypt(t *testing.T, path string, baseAddress types.Address, password string) *KeyFile {
+	read, err := ReadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := read.Decrypt(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ks.Entropy, testEntropy) || ks.BaseAddress != baseAddress {
+		t.Fatalf("decrypted key store %v doesn't match %v", ks.BaseAddress, baseAddress)
+	}
+	if _, err := read.Decrypt("wrong"); err != ErrWrongPassword {
+		t.Fatalf("expected %v, got %v", ErrWrongPassword, err)
+	}
+	return read
+}
+
+// Test KeyFile
+//   - test key stores encrypted with the default parameters are written as version 2 and decrypted back
+//   - test version 1 key files are still read, with and without argon2 parameters
+//   - test version 2 key files without argon2 parameters and unknown versions are rejected
+func TestKeyFile_Versions(t *testing.T) {
+	ks, err := keyStoreFromEntropy(testEntropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kf, err := ks.Encrypt("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kf.Path = filepath.Join(t.TempDir(), "key")
+	if kf.Version != 2 {
+		t.Fatalf("expected version 2, got %v", kf.Version)
+	}
+	if read := testWriteAndDecrypt(t, kf, "password"); read.NeedsUpgrade() {
+		t.Fatal("key file with the default parameters needs an upgrade")
+	}
+
+	if read := testWriteAndDecrypt(t, testKeyFile(t, "password", weakArgon2Params, 1), "password"); !read.NeedsUpgrade() {
+		t.Fatal("version 1 key file doesn't need an upgrade")
+	}
+	legacy := argon2Params{Salt: weakArgon2Params.Salt}
+	if read := testWriteAndDecrypt(t, testKeyFile(t, "password", legacy, 1), "password"); !read.NeedsUpgrade() {
+		t.Fatal("legacy key file doesn't need an upgrade")
+	}
+
+	for version, expected := range map[int]error{2: ErrKeyFileInvalidArgon2, 3: ErrKeyFileInvalidVersion, 0: ErrKeyFileInvalidVersion} {
+		kf := testKeyFile(t, "password", legacy, version)
+		if err := kf.Write(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadKeyFile(kf.Path); err != expected {
+			t.Fatalf("expected %v for version %v, got %v", expected, version, err)
+		}
+	}
+}
+
+// Test the upgrade of a version 1 key file through the manager
+//   - test the key file is rewritten as version 2 with the default parameters
+//   - test it's decrypted with the same password and derives the same addresses
+func TestManager_UpgradeVersion1(t *testing.T) {
+	kf := testKeyFile(t, "password", weakArgon2Params, 1)
+	if err := kf.Write(); err != nil {
+		t.Fatal(err)
+	}
+	manager := New(&Config{WalletDir: filepath.Dir(kf.Path)})
+	if err := manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Stop()
+
+	upgraded, err := manager.Upgrade(kf.Path, "password")
+	if err != nil || !upgraded {
+		t.Fatalf("expected the key file to be upgraded, got %v %v", upgraded, err)
+	}
+	data, err := os.ReadFile(kf.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"version": 2`)) {
+		t.Fatalf("unexpected upgraded key file %s", data)
+	}
+	read := testReadAndDecrypt(t, kf.Path, kf.BaseAddress, "password")
+	if read.NeedsUpgrade() || read.Timestamp != kf.Timestamp || read.BaseAddress != kf.BaseAddress {
+		t.Fatalf("unexpected upgraded key file %+v", read)
+	}
+	if upgraded, err := manager.Upgrade(kf.Path, "password"); err != nil || upgraded {
+		t.Fatalf("expected no second upgrade, got %v %v", upgraded, err)
+	}
+}
+
+// Test the paths of the key files read by the manager
+//   - test a key file added to the wallet directory after Start is unlocked by its name or absolute path
+//   - test the key files outside of the wallet directory aren't read, by absolute path or with ".."
+func TestManager_KeyFilePaths(t *testing.T) {
+	walletDir := filepath.Join(t.TempDir(), "wallet")
+	manager := New(&Config{WalletDir: walletDir})
+	if err := manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Stop()
+
+	added := testKeyFile(t, "password", weakArgon2Params, 2)
+	added.Path = filepath.Join(walletDir, "added")
+	if err := added.Write(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"added", added.Path} {
+		if err := manager.Unlock(path, "password"); err != nil {
+			t.Fatalf("%v not unlocked: %v", path, err)
+		}
+		manager.Lock(path)
+	}
+
+	outside := testKeyFile(t, "password", weakArgon2Params, 2)
+	outside.Path = filepath.Join(filepath.Dir(walletDir), "outside")
+	if err := outside.Write(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{outside.Path, "../outside", filepath.Join(walletDir, "..", "outside"), "/etc/passwd"} {
+		if err := manager.Unlock(path, "password"); err != ErrKeyStoreNotFound {
+			t.Fatalf("expected %v unlocking %v, got %v", ErrKeyStoreNotFound, path, err)
+		}
+		if err := manager.ChangePassword(path, "password", "new password"); err != ErrKeyStoreNotFound {
+			t.Fatalf("expected %v changing the password of %v, got %v", ErrKeyStoreNotFound, path, err)
+		}
+		if _, err := manager.Upgrade(path, "password"); err != ErrKeyStoreNotFound {
+			t.Fatalf("expected %v upgrading %v, got %v", ErrKeyStoreNotFound, path, err)
+		}
+	}
+	testReadAndDecrypt(t, outside.Path, outside.BaseAddress, "password")
+}
@@ -76,7 +76,7 @@ func (ks *KeyStore) FindAddress(address types.Address) (key *KeyPair, index uint
 
 func (ks *KeyStore) Encrypt(password string) (*KeyFile, error) {
 	derivedKey := new(passwordHash)
-	err := derivedKey.Set(password)
+	params, err := derivedKey.Set(password)
 	if err != nil {
 		return nil, err
 	}
@@ -89,13 +89,11 @@ func (ks *KeyStore) Encrypt(password string) (*KeyFile, error) {
 	return &KeyFile{
 		BaseAddress: ks.BaseAddress,
 		Crypto: cryptoParams{
-			CipherName: aesMode,
-			KDF:        argonName,
-			CipherData: cipherData,
-			AesNonce:   nonce,
-			Argon2Params: argon2Params{
-				Salt: derivedKey.salt,
-			},
+			CipherName:   aesMode,
+			KDF:          argonName,
+			CipherData:   cipherData,
+			AesNonce:     nonce,
+			Argon2Params: params,
 		},
 		Version:   cryptoStoreVersion,
 		Timestamp: time.Now().UTC().Unix(),
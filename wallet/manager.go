@@ -3,7 +3,9 @@ package wallet
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/zenon-network/go-zenon/common"
 )
@@ -21,6 +23,8 @@ type Manager struct {
 	config *Config
 	log    common.Logger
 
+	// protects encrypted and decrypted, the WalletApi and its unlock timers use them concurrently
+	lock      sync.Mutex
 	encrypted map[string]*KeyFile  // map from path to
 	decrypted map[string]*KeyStore // map from path to
 }
@@ -49,23 +53,27 @@ func (m *Manager) Start() error {
 	}
 	m.log.Info("successfully ensured WalletDir exists", "wallet-dir-path", m.config.WalletDir)
 
-	m.encrypted = make(map[string]*KeyFile)
 	keyFiles, err := m.ListEntropyFilesInStandardDir()
 	if err != nil {
 		m.log.Error("wallet start err", "err", err)
 		return err
 	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.encrypted = make(map[string]*KeyFile)
 	for _, keyFile := range keyFiles {
 		m.encrypted[keyFile.Path] = keyFile
 	}
 	return nil
 }
 func (m *Manager) Stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	for _, ks := range m.decrypted {
 		ks.Zero()
 	}
-	m.decrypted = nil
-	m.encrypted = nil
+	m.decrypted = make(map[string]*KeyStore)
+	m.encrypted = make(map[string]*KeyFile)
 }
 
 func (m *Manager) MakePathAbsolut(path string) string {
@@ -76,8 +84,37 @@ func (m *Manager) MakePathAbsolut(path string) string {
 	}
 }
 
+// keyFilePath resolves the path of a key file which isn't loaded yet. The key files are only read from
+// WalletDir: the relative paths are names in it, the absolute ones must be files of it, as listed by the
+// manager. The other paths, including the ones leaving WalletDir with "..", return ErrKeyStoreNotFound.
+func (m *Manager) keyFilePath(path string) (string, error) {
+	if !filepath.IsAbs(path) && !filepath.IsLocal(path) {
+		return "", ErrKeyStoreNotFound
+	}
+	path = filepath.Clean(m.MakePathAbsolut(path))
+	if filepath.Dir(path) != filepath.Clean(m.config.WalletDir) {
+		return "", ErrKeyStoreNotFound
+	}
+	return path, nil
+}
+
+// readKeyFile reads a key file added to WalletDir after Start.
+func (m *Manager) readKeyFile(path string) (*KeyFile, error) {
+	path, err := m.keyFilePath(path)
+	if err != nil {
+		return nil, err
+	}
+	kf, err := ReadKeyFile(path)
+	if err != nil {
+		return nil, ErrKeyStoreNotFound
+	}
+	return kf, nil
+}
+
 func (m *Manager) GetKeyFile(path string) (*KeyFile, error) {
 	path = m.MakePathAbsolut(path)
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if kf, ok := m.encrypted[path]; !ok {
 		return nil, ErrKeyStoreNotFound
 	} else {
@@ -86,6 +123,8 @@ func (m *Manager) GetKeyFile(path string) (*KeyFile, error) {
 }
 func (m *Manager) GetKeyStore(path string) (*KeyStore, error) {
 	path = m.MakePathAbsolut(path)
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if _, ok := m.encrypted[path]; !ok {
 		return nil, ErrKeyStoreNotFound
 	} else if ks, ok := m.decrypted[path]; !ok {
@@ -132,30 +171,127 @@ func (m *Manager) ListEntropyFilesInStandardDir() ([]*KeyFile, error) {
 func (m *Manager) Unlock(path, password string) error {
 	path = m.MakePathAbsolut(path)
 	kf, err := m.GetKeyFile(path)
-	if err != nil {
+	if err == ErrKeyStoreNotFound {
+		// the key file might have been added after Start
+		if kf, err = m.readKeyFile(path); err != nil {
+			return err
+		}
+	} else if err != nil {
 		return err
 	}
 	ks, err := kf.Decrypt(password)
 	if err != nil {
 		return err
 	}
+	if kf.NeedsUpgrade() {
+		m.log.Warn("key file is encrypted with weak argon2 parameters or an older version, upgrade it with `znnd wallet upgrade`", "path", path)
+	}
 
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	m.encrypted[path] = kf
 	m.decrypted[path] = ks
 	return nil
 }
 func (m *Manager) Lock(path string) {
 	path = m.MakePathAbsolut(path)
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if ks, ok := m.decrypted[path]; ok {
 		ks.Zero()
-		m.decrypted[path] = nil
+		delete(m.decrypted, path)
 	}
 }
 func (m *Manager) IsUnlocked(path string) (bool, error) {
 	path = m.MakePathAbsolut(path)
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if _, ok := m.encrypted[path]; !ok {
 		return false, ErrKeyStoreNotFound
 	}
 	_, ok := m.decrypted[path]
 	return ok, nil
 }
+
+// UnlockedPaths returns the paths of the unlocked key stores, sorted.
+func (m *Manager) UnlockedPaths() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	paths := make([]string, 0, len(m.decrypted))
+	for path := range m.decrypted {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ChangePassword re-encrypts the key file with newPassword and the default argon2 parameters.
+// An unlocked key store stays unlocked.
+func (m *Manager) ChangePassword(path, password, newPassword string) error {
+	path = m.MakePathAbsolut(path)
+	kf, err := m.GetKeyFile(path)
+	if err == ErrKeyStoreNotFound {
+		if kf, err = m.readKeyFile(path); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	upgraded, err := kf.Reencrypt(password, newPassword)
+	if err != nil {
+		return err
+	}
+	if err := upgraded.Write(); err != nil {
+		return err
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.encrypted[path] = upgraded
+	m.log.Info("re-encrypted key file", "path", path)
+	return nil
+}
+
+// Upgrade re-encrypts the key file with the default argon2 parameters and the current version if it
+// uses weaker ones or an older version, returns whether it was upgraded.
+func (m *Manager) Upgrade(path, password string) (bool, error) {
+	kf, err := m.GetKeyFile(path)
+	if err == ErrKeyStoreNotFound {
+		if kf, err = m.readKeyFile(path); err != nil {
+			return false, err
+		}
+	} else if err != nil {
+		return false, err
+	}
+	if !kf.NeedsUpgrade() {
+		return false, nil
+	}
+	if err := m.ChangePassword(path, password, password); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpgradeResult is the outcome of the upgrade of a key file by UpgradeAll.
+type UpgradeResult struct {
+	Path     string `json:"path"`
+	Upgraded bool   `json:"upgraded"`
+	Error    string `json:"error,omitempty"`
+}
+
+// UpgradeAll upgrades the key files of the wallet directory which can be decrypted with password,
+// the others are reported with their error and left untouched.
+func (m *Manager) UpgradeAll(password string) ([]*UpgradeResult, error) {
+	keyFiles, err := m.ListEntropyFilesInStandardDir()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*UpgradeResult, 0, len(keyFiles))
+	for _, keyFile := range keyFiles {
+		result := &UpgradeResult{Path: keyFile.Path}
+		if result.Upgraded, err = m.Upgrade(keyFile.Path, password); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
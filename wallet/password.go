@@ -5,24 +5,42 @@ import (
 	"golang.org/x/crypto/argon2"
 )
 
+// Argon2 parameters of the key files which don't record them, used by all the key files created
+// before the parameters were stored.
+const (
+	legacyArgon2Time    = 1
+	legacyArgon2Memory  = 64 * 1024 // KiB
+	legacyArgon2Threads = 4
+)
+
+// Argon2 parameters of the new and the upgraded key files.
+const (
+	DefaultArgon2Time    = 3
+	DefaultArgon2Memory  = 256 * 1024 // KiB
+	DefaultArgon2Threads = 4
+)
+
 // passwordHash of a password
 type passwordHash struct {
 	password [32]byte
 	salt     hexutil.Bytes
 }
 
-// Set updates the password hash to be of the provided password
-func (h *passwordHash) Set(password string) error {
-	h.salt = GetEntropyCSPRNG(16)
-	// pw is the salted, hashed password
-	pw := argon2.IDKey([]byte(password), h.salt, 1, 64*1024, 4, 32)
-	copy(h.password[:], pw[:32])
-	return nil
+// Set updates the password hash to be of the provided password, with a new salt and the default parameters
+func (h *passwordHash) Set(password string) (argon2Params, error) {
+	params := argon2Params{
+		Salt:    GetEntropyCSPRNG(16),
+		Time:    DefaultArgon2Time,
+		Memory:  DefaultArgon2Memory,
+		Threads: DefaultArgon2Threads,
+	}
+	return params, h.SetFromJSON(password, params)
 }
 func (h *passwordHash) SetFromJSON(password string, params argon2Params) error {
 	h.salt = params.Salt
+	time, memory, threads := params.get()
 	// pw is the salted, hashed password
-	pw := argon2.IDKey([]byte(password), h.salt, 1, 64*1024, 4, 32)
+	pw := argon2.IDKey([]byte(password), h.salt, time, memory, threads, 32)
 	copy(h.password[:], pw[:32])
 	return nil
 }
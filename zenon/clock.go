@@ -0,0 +1,219 @@
+package zenon
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/zenon-network/go-zenon/chain/nom"
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/ntp"
+	"github.com/zenon-network/go-zenon/pillar"
+	"github.com/zenon-network/go-zenon/protocol"
+)
+
+const (
+	// ntpCheckInterval is how often the NTP server is queried.
+	ntpCheckInterval = 10 * time.Minute
+	// momentumDriftSamples is the number of inserted momentums over which the momentum drift is measured.
+	momentumDriftSamples = 30
+	// minMomentumDriftSamples is the number of samples needed before the momentum drift is reported.
+	minMomentumDriftSamples = 6
+	// driftWarningInterval is the least time between two warnings about the same drift.
+	driftWarningInterval = 10 * time.Minute
+)
+
+var (
+	momentumDriftGauge = metrics.NewRegisteredGauge("chain/momentums/drift", nil)
+	ntpDriftGauge      = metrics.NewRegisteredGauge("clock/ntp/drift", nil)
+)
+
+// ClockDrift describes how far the local clock is off, in milliseconds. MomentumDrift is the median delay
+// between the timestamps of the recently inserted momentums and their arrival by the local clock. Momentums
+// propagate in well under a second, so a large or negative one means the local clock or the one of the
+// producers is off. NTPDrift is positive if the local clock is ahead of NTPServer, null until it's measured.
+type ClockDrift struct {
+	MomentumDrift   int64   `json:"momentumDrift"`
+	MomentumSamples int     `json:"momentumSamples"`
+	NTPServer       string  `json:"ntpServer"`
+	NTPDrift        *int64  `json:"ntpDrift"`
+	NTPCheckedAt    int64   `json:"ntpCheckedAt"`
+	NTPError        *string `json:"ntpError"`
+
+	DriftWarning     int64 `json:"driftWarning"`
+	MaxProducerDrift int64 `json:"maxProducerDrift"`
+	// ProductionAllowed is false while the NTP drift is past MaxProducerDrift
+	ProductionAllowed bool `json:"productionAllowed"`
+}
+
+// clockMonitor measures the drift of the local clock against the timestamps of the inserted momentums and
+// against an NTP server, logs the drifts past the warning threshold and guards the momentum production.
+type clockMonitor struct {
+	log         common.Logger
+	broadcaster protocol.Broadcaster
+	ntpServer   string
+	warning     time.Duration
+	maxProducer time.Duration
+
+	lock            sync.Mutex
+	drifts          []time.Duration
+	ntpDrift        *time.Duration
+	ntpCheckedAt    time.Time
+	ntpErr          error
+	lastMomentumLog time.Time
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newClockMonitor(cfg *Config, broadcaster protocol.Broadcaster) *clockMonitor {
+	return &clockMonitor{
+		log:         common.ZenonLogger.New("submodule", "clock"),
+		broadcaster: broadcaster,
+		ntpServer:   cfg.NTPServer,
+		warning:     cfg.ClockDriftWarning,
+		maxProducer: cfg.MaxProducerDrift,
+	}
+}
+
+func (m *clockMonitor) start() {
+	m.closed = make(chan struct{})
+	if m.ntpServer == "" {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.loop()
+	}()
+}
+func (m *clockMonitor) stop() {
+	close(m.closed)
+	m.wg.Wait()
+}
+
+func (m *clockMonitor) loop() {
+	defer common.RecoverStack()
+	ticker := time.NewTicker(ntpCheckInterval)
+	defer ticker.Stop()
+	for {
+		m.checkNTP()
+		select {
+		case <-m.closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *clockMonitor) checkNTP() {
+	drift, err := ntp.Drift(m.ntpServer, ntp.Measurements)
+
+	m.lock.Lock()
+	m.ntpCheckedAt = common.Clock.Now()
+	m.ntpErr = err
+	if err != nil {
+		m.lock.Unlock()
+		m.log.Warn("failed to measure the drift of the local clock", "server", m.ntpServer, "reason", err)
+		return
+	}
+	m.ntpDrift = &drift
+	m.lock.Unlock()
+
+	ntpDriftGauge.Update(drift.Milliseconds())
+	switch {
+	case m.maxProducer != 0 && abs(drift) > m.maxProducer:
+		m.log.Error("the local clock drifts too much, momentums are not produced until it's synchronized", "drift", drift, "server", m.ntpServer, "max-producer-drift", m.maxProducer)
+	case m.warning != 0 && abs(drift) > m.warning:
+		m.log.Warn("the local clock drifts, synchronize it", "drift", drift, "server", m.ntpServer)
+	default:
+		m.log.Debug("measured the drift of the local clock", "drift", drift, "server", m.ntpServer)
+	}
+}
+
+func (m *clockMonitor) InsertMomentum(detailed *nom.DetailedMomentum) {
+	// momentums inserted while syncing arrive long after their timestamp
+	if m.broadcaster.SyncInfo().State != protocol.SyncDone {
+		return
+	}
+	now := common.Clock.Now()
+
+	m.lock.Lock()
+	m.drifts = append(m.drifts, now.Sub(*detailed.Momentum.Timestamp))
+	if len(m.drifts) > momentumDriftSamples {
+		m.drifts = m.drifts[len(m.drifts)-momentumDriftSamples:]
+	}
+	drift, ok := m.momentumDrift()
+	warn := ok && m.warning != 0 && abs(drift) > m.warning && now.Sub(m.lastMomentumLog) > driftWarningInterval
+	if warn {
+		m.lastMomentumLog = now
+	}
+	m.lock.Unlock()
+
+	if ok {
+		momentumDriftGauge.Update(drift.Milliseconds())
+	}
+	if warn {
+		m.log.Warn("the momentums arrive far from their timestamps, the local clock may be off", "drift", drift, "samples", momentumDriftSamples)
+	}
+}
+func (m *clockMonitor) DeleteMomentum(*nom.DetailedMomentum) {
+}
+
+// momentumDrift returns the median of the drift samples, false if there are too few. The lock must be held.
+func (m *clockMonitor) momentumDrift() (time.Duration, bool) {
+	if len(m.drifts) < minMomentumDriftSamples {
+		return 0, false
+	}
+	sorted := append([]time.Duration{}, m.drifts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2], true
+}
+
+// checkProduction returns pillar.ErrClockDrift while the NTP drift is past the producer limit. A failed or
+// missing measurement doesn't stop the production.
+func (m *clockMonitor) checkProduction() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.maxProducer != 0 && m.ntpErr == nil && m.ntpDrift != nil && abs(*m.ntpDrift) > m.maxProducer {
+		return pillar.ErrClockDrift
+	}
+	return nil
+}
+
+func (m *clockMonitor) drift() *ClockDrift {
+	result := &ClockDrift{
+		NTPServer:         m.ntpServer,
+		DriftWarning:      m.warning.Milliseconds(),
+		MaxProducerDrift:  m.maxProducer.Milliseconds(),
+		ProductionAllowed: m.checkProduction() == nil,
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if drift, ok := m.momentumDrift(); ok {
+		result.MomentumDrift = drift.Milliseconds()
+	}
+	result.MomentumSamples = len(m.drifts)
+	if m.ntpDrift != nil {
+		drift := m.ntpDrift.Milliseconds()
+		result.NTPDrift = &drift
+	}
+	if !m.ntpCheckedAt.IsZero() {
+		result.NTPCheckedAt = m.ntpCheckedAt.Unix()
+	}
+	if m.ntpErr != nil {
+		reason := m.ntpErr.Error()
+		result.NTPError = &reason
+	}
+	return result
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+package zenon
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/zenon-network/go-zenon/pillar"
+)
+
+// Test checkProduction
+//   - test the production stops while the NTP drift, ahead or behind, is past MaxProducerDrift
+//   - test a failed or missing measurement doesn't stop the production
+//   - test a zero MaxProducerDrift disables the check
+func TestClockMonitor_CheckProduction(t *testing.T) {
+	drift := func(d time.Duration) *time.Duration { return &d }
+	for _, test := range []struct {
+		maxProducer time.Duration
+		ntpDrift    *time.Duration
+		ntpErr      error
+		expected    error
+	}{
+		{3 * time.Second, nil, nil, nil},
+		{3 * time.Second, drift(time.Second), nil, nil},
+		{3 * time.Second, drift(4 * time.Second), nil, pillar.ErrClockDrift},
+		{3 * time.Second, drift(-4 * time.Second), nil, pillar.ErrClockDrift},
+		{3 * time.Second, drift(4 * time.Second), errors.New("timeout"), nil},
+		{0, drift(time.Hour), nil, nil},
+	} {
+		m := newClockMonitor(&Config{MaxProducerDrift: test.maxProducer}, nil)
+		m.ntpDrift = test.ntpDrift
+		m.ntpErr = test.ntpErr
+		if err := m.checkProduction(); err != test.expected {
+			t.Errorf("max producer drift %v, drift %v and error %v: expected %v, got %v", test.maxProducer, test.ntpDrift, test.ntpErr, test.expected, err)
+		}
+		if allowed := m.drift().ProductionAllowed; allowed != (test.expected == nil) {
+			t.Errorf("reported production allowed %v, expected %v", allowed, test.expected == nil)
+		}
+	}
+}
+
+// Test an NTP server which doesn't answer doesn't stop the production after a drift past the limit was measured
+func TestClockMonitor_CheckNTPFailure(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := conn.LocalAddr().String()
+	conn.Close()
+
+	m := newClockMonitor(&Config{NTPServer: server, MaxProducerDrift: time.Second}, nil)
+	drift := time.Hour
+	m.ntpDrift = &drift
+	if err := m.checkProduction(); err != pillar.ErrClockDrift {
+		t.Fatalf("expected %v, got %v", pillar.ErrClockDrift, err)
+	}
+	m.checkNTP()
+	if m.ntpErr == nil {
+		t.Fatal("closed port measured")
+	}
+	if err := m.checkProduction(); err != nil {
+		t.Fatalf("failed measurement stopped the production: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package zenon
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/zenon-network/go-zenon/common"
+	"github.com/zenon-network/go-zenon/common/db"
+)
+
+const (
+	// compactionCheckInterval is how often the scheduler checks whether the window started.
+	compactionCheckInterval = time.Minute
+)
+
+// CompactionWindow is a daily time range, in UTC, during which the databases are compacted once.
+// It wraps around midnight if End is before Start.
+type CompactionWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseCompactionWindow parses a window such as "02:00-04:30".
+func ParseCompactionWindow(window string) (*CompactionWindow, error) {
+	bounds := strings.Split(window, "-")
+	if len(bounds) != 2 {
+		return nil, errors.Errorf("invalid compaction window %q, expected HH:MM-HH:MM", window)
+	}
+	var parsed [2]time.Duration
+	for i, bound := range bounds {
+		t, err := time.Parse("15:04", strings.TrimSpace(bound))
+		if err != nil {
+			return nil, errors.Errorf("invalid compaction window %q, expected HH:MM-HH:MM", window)
+		}
+		parsed[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if parsed[0] == parsed[1] {
+		return nil, errors.Errorf("invalid compaction window %q, it's empty", window)
+	}
+	return &CompactionWindow{Start: parsed[0], End: parsed[1]}, nil
+}
+
+func (w *CompactionWindow) String() string {
+	format := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return format(w.Start) + "-" + format(w.End)
+}
+
+// opening returns the start of the window which contains now, ok is false if now is outside the window.
+func (w *CompactionWindow) opening(now time.Time) (time.Time, bool) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	offset := now.Sub(midnight)
+	switch {
+	case w.Start < w.End && offset >= w.Start && offset < w.End:
+		return midnight.Add(w.Start), true
+	case w.Start > w.End && offset >= w.Start:
+		return midnight.Add(w.Start), true
+	case w.Start > w.End && offset < w.End:
+		return midnight.AddDate(0, 0, -1).Add(w.Start), true
+	}
+	return time.Time{}, false
+}
+
+// compactionScheduler compacts the databases once in every compaction window, so the
+// compactions LevelDB would run on its own while momentums are inserted are done off-peak.
+type compactionScheduler struct {
+	log       common.Logger
+	window    *CompactionWindow
+	databases func() map[string]func() error
+
+	// opening of the last window in which the databases were compacted
+	last   time.Time
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newCompactionScheduler(window *CompactionWindow, databases func() map[string]func() error) *compactionScheduler {
+	return &compactionScheduler{
+		log:       common.ZenonLogger.New("submodule", "compaction"),
+		window:    window,
+		databases: databases,
+	}
+}
+
+func (s *compactionScheduler) start() {
+	s.closed = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop()
+	}()
+}
+
+// stop doesn't wait for a running compaction, it ends once the databases are closed, see wait.
+func (s *compactionScheduler) stop() {
+	close(s.closed)
+}
+func (s *compactionScheduler) wait() {
+	s.wg.Wait()
+}
+
+func (s *compactionScheduler) loop() {
+	defer common.RecoverStack()
+	s.log.Info("scheduled the compaction of the databases", "window", s.window)
+	ticker := time.NewTicker(compactionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case now := <-ticker.C:
+			opening, ok := s.window.opening(now)
+			if ok && !opening.Equal(s.last) {
+				s.last = opening
+				s.compact()
+			}
+		}
+	}
+}
+
+func (s *compactionScheduler) compact() {
+	for name, compact := range s.databases() {
+		select {
+		case <-s.closed:
+			return
+		default:
+		}
+		if _, ok := s.window.opening(time.Now()); !ok {
+			s.log.Warn("the compaction window ended before all the databases were compacted")
+			return
+		}
+		start := time.Now()
+		s.log.Info("compacting the database", "name", name)
+		if err := compact(); err != nil {
+			select {
+			case <-s.closed:
+				return
+			default:
+			}
+			s.log.Error("failed to compact the database", "name", name, "reason", err)
+			continue
+		}
+		s.log.Info("compacted the database", "name", name, "elapsed", time.Since(start))
+	}
+}
+
+// compactableDatabases returns the compaction of each open database of the node.
+func (z *zenon) compactableDatabases() map[string]func() error {
+	databases := make(map[string]func() error)
+	if compacter, ok := z.nomManager.(db.Compacter); ok {
+		databases["nom"] = compacter.Compact
+	}
+	for name, ldb := range map[string]*leveldb.DB{
+		"consensus": z.levelDb,
+		"receipts":  z.receiptsDb,
+		"indexer":   z.indexerDb,
+		"tokenmeta": z.tokenMetaDb,
+		"tracer":    z.tracerDb,
+	} {
+		if ldb != nil {
+			ldb := ldb
+			databases[name] = func() error { return db.CompactLevelDB(ldb) }
+		}
+	}
+	return databases
+}
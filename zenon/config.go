@@ -2,25 +2,103 @@ package zenon
 
 import (
 	"path"
+	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
 
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/chain/momentum"
 	"github.com/zenon-network/go-zenon/chain/store"
 	"github.com/zenon-network/go-zenon/common/db"
+	"github.com/zenon-network/go-zenon/pillar"
+	"github.com/zenon-network/go-zenon/vm/embedded/bridge"
 	"github.com/zenon-network/go-zenon/wallet"
 )
 
 type Config struct {
 	MinPeers          int
 	MinConnectedPeers int
-	DataDir           string
-	ProducingKeyPair  *wallet.KeyPair
-	GenesisConfig     store.Genesis
+
+	// PropagationDiversity is the percentage of the momentum propagation slots which go to peers
+	// from distinct networks instead of the ones with the lowest latency.
+	PropagationDiversity int
+
+	// HealthMinPeers and HealthMaxMomentumAge are the thresholds of the health check,
+	// nodes below them are reported as unhealthy. Zero disables the check.
+	HealthMinPeers       int
+	HealthMaxMomentumAge time.Duration
+
+	DataDir        string
+	ProducerSigner pillar.Signer
+	GenesisConfig  store.Genesis
+	EnableIndexer  bool
+	EnableTracer   bool
+
+	// EnableTokenMetadata checks the domains of the tokens for the metadata they publish, see tokenmeta.Registry.
+	EnableTokenMetadata bool
+
+	// ProducerLease makes the node produce only while it holds the lease, BackupProducer makes it stand by
+	// while the primary holds it, see pillar.Manager.SetLease.
+	ProducerLease  pillar.Lease
+	BackupProducer bool
+
+	// SkipEmptyMomentums produces momentums only when there are account-blocks to confirm, see pillar.Manager.SetSkipEmpty.
+	SkipEmptyMomentums bool
+
+	// RewardCollection collects the rewards of the producer automatically, if set.
+	RewardCollection *pillar.RewardCollection
+
+	// AncientDir keeps the momentums and account-blocks older than AncientThreshold momentums, if set.
+	AncientDir       string
+	AncientThreshold uint64
+
+	// ChainCacheSize is the number of momentums, account-blocks and token infos each kept in memory
+	// by the chain, see chain.NewChainWithCache.
+	ChainCacheSize int
+
+	// CompactionWindow compacts the databases once a day during the window, if set.
+	CompactionWindow *CompactionWindow
+
+	// BridgeKeyPair publishes the signatures of the wrap requests produced by BridgeSigner.
+	// Orchestrators can register the signer at runtime as well, see Zenon.Bridge.
+	BridgeKeyPair *wallet.KeyPair
+	BridgeSigner  bridge.Signer
+
+	// ReplicaSource is the data directory of another znnd, if set the chain is a read-only replica of
+	// its databases, refreshed every ReplicaInterval, see chain.ReplicaChain.
+	ReplicaSource   string
+	ReplicaInterval time.Duration
+
+	// NTPServer is queried for the drift of the local clock, if set. Drifts past ClockDriftWarning are logged
+	// and the pillar doesn't produce while the NTP drift is past MaxProducerDrift, zero disables either.
+	NTPServer         string
+	ClockDriftWarning time.Duration
+	MaxProducerDrift  time.Duration
 }
 
 func (c *Config) NewDBManager(inside string) db.Manager {
+	if c.AncientDir != "" {
+		return db.NewLevelDBManagerWithAncient(path.Join(c.DataDir, inside), &db.AncientConfig{
+			Dir:       path.Join(c.AncientDir, inside),
+			Threshold: c.AncientThreshold,
+			IsAncient: momentum.IsAncientKey,
+		})
+	}
 	return db.NewLevelDBManager(path.Join(c.DataDir, inside))
 }
+func (c *Config) NewReplicaChain() (chain.ReplicaChain, error) {
+	dir := path.Join(c.DataDir, "replica")
+	manager, err := db.NewReplicaManager(path.Join(c.ReplicaSource, "nom"), dir)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := db.NewReplicaDB(path.Join(c.ReplicaSource, "receipts"), dir)
+	if err != nil {
+		manager.Stop()
+		return nil, err
+	}
+	return chain.NewReplicaChain(manager, receipts, c.GenesisConfig, c.ChainCacheSize), nil
+}
 func (c *Config) NewLevelDB(inside string) (db.DB, *leveldb.DB) {
 	return db.NewLevelDB(path.Join(c.DataDir, inside))
 }
@@ -3,9 +3,12 @@ package zenon
 import (
 	"github.com/zenon-network/go-zenon/chain"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/tokenmeta"
 	"github.com/zenon-network/go-zenon/verifier"
+	"github.com/zenon-network/go-zenon/vm/embedded/bridge"
 )
 
 type Zenon interface {
@@ -20,4 +23,11 @@ type Zenon interface {
 	Producer() pillar.Manager
 	Config() *Config
 	Broadcaster() protocol.Broadcaster
+	// Indexer returns nil if the indexer is not enabled.
+	Indexer() indexer.Indexer
+	// TokenMetadata returns nil if the token metadata is not enabled.
+	TokenMetadata() tokenmeta.Registry
+	Bridge() bridge.Orchestrator
+	// ClockDrift reports how far the local clock is off, nil if it's not monitored.
+	ClockDrift() *ClockDrift
 }
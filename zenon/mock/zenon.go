@@ -18,10 +18,13 @@ import (
 	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/common/types"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
+	"github.com/zenon-network/go-zenon/tokenmeta"
 	"github.com/zenon-network/go-zenon/verifier"
 	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded/bridge"
 	"github.com/zenon-network/go-zenon/vm/vm_context"
 	"github.com/zenon-network/go-zenon/zenon"
 )
@@ -126,6 +129,7 @@ type mockZenon struct {
 	chain      chain.Chain
 	consensus  consensus.Consensus
 	supervisor *vm.Supervisor
+	indexer    indexer.Indexer
 
 	loggers              []log15.Logger
 	handlers             []log15.Handler
@@ -137,6 +141,7 @@ func (zenon *mockZenon) SyncInfo() *protocol.SyncInfo {
 		State:         protocol.SyncDone,
 		CurrentHeight: 0,
 		TargetHeight:  0,
+		Peers:         []*protocol.SyncPeerInfo{},
 	}
 }
 func (zenon *mockZenon) SyncState() protocol.SyncState {
@@ -294,6 +299,7 @@ func (zenon *mockZenon) ExpectBalance(address types.Address, standard types.Zeno
 func (zenon *mockZenon) Init() error {
 	common.DealWithErr(zenon.chain.Init())
 	common.DealWithErr(zenon.consensus.Init())
+	common.DealWithErr(zenon.indexer.Init())
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Init())
 	}
@@ -302,6 +308,7 @@ func (zenon *mockZenon) Init() error {
 func (zenon *mockZenon) Start() error {
 	common.DealWithErr(zenon.chain.Start())
 	common.DealWithErr(zenon.consensus.Start())
+	common.DealWithErr(zenon.indexer.Start())
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Start())
 	}
@@ -311,11 +318,13 @@ func (zenon *mockZenon) Stop() error {
 	for _, pillarE := range zenon.pillars {
 		common.DealWithErr(pillarE.Stop())
 	}
+	common.DealWithErr(zenon.indexer.Stop())
 	common.DealWithErr(zenon.consensus.Stop())
 	common.DealWithErr(zenon.chain.Stop())
 
 	zenon.chain = nil
 	zenon.consensus = nil
+	zenon.indexer = nil
 	zenon.pillars = nil
 
 	for i := range zenon.loggers {
@@ -343,7 +352,11 @@ func (zenon *mockZenon) Protocol() *protocol.ProtocolManager {
 	return nil
 }
 func (zenon *mockZenon) Producer() pillar.Manager {
-	return nil
+	// act as a node configured with the first genesis pillar
+	if len(zenon.pillars) == 0 {
+		return nil
+	}
+	return zenon.pillars[0]
 }
 func (zenon *mockZenon) Config() *zenon.Config {
 	return nil
@@ -351,6 +364,18 @@ func (zenon *mockZenon) Config() *zenon.Config {
 func (zenon *mockZenon) Broadcaster() protocol.Broadcaster {
 	return zenon
 }
+func (zenon *mockZenon) Indexer() indexer.Indexer {
+	return zenon.indexer
+}
+func (zenon *mockZenon) TokenMetadata() tokenmeta.Registry {
+	return nil
+}
+func (zenon *mockZenon) ClockDrift() *zenon.ClockDrift {
+	return nil
+}
+func (zenon *mockZenon) Bridge() bridge.Orchestrator {
+	return nil
+}
 
 func NewMockZenon(t common.T) MockZenon {
 	return newMockZenon(t, consensus.EpochDuration)
@@ -366,7 +391,7 @@ func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
 	common.SupervisorLogger.SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.StderrHandler))
 	consensus.EpochDuration = customEpochDuration
 
-	ch := chain.NewChain(db.NewLevelDBManager(t.TempDir()), genesis.NewGenesis(g.EmbeddedGenesis))
+	ch := chain.NewChainWithCache(db.NewLevelDBManager(t.TempDir()), db.NewMemDB(), genesis.NewGenesis(g.EmbeddedGenesis), 1024)
 	cs := consensus.NewConsensus(db.NewMemDB(), ch, true)
 	supervisor := vm.NewSupervisor(ch, cs)
 	zenon := &mockZenon{
@@ -375,6 +400,7 @@ func newMockZenon(t common.T, customEpochDuration time.Duration) MockZenon {
 		chain:                ch,
 		consensus:            cs,
 		supervisor:           supervisor,
+		indexer:              indexer.NewIndexer(db.NewMemDB(), ch),
 		loggers:              make([]log15.Logger, len(AllLoggers)),
 		handlers:             make([]log15.Handler, len(AllLoggers)),
 		initialEpochDuration: consensus.EpochDuration,
@@ -0,0 +1,57 @@
+package zenon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common"
+)
+
+// replicaRefresher refreshes a replica chain periodically, the momentums copied from the primary
+// reach the consensus, indexer and subscriptions like inserted ones.
+type replicaRefresher struct {
+	log      common.Logger
+	chain    chain.ReplicaChain
+	interval time.Duration
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newReplicaRefresher(chain chain.ReplicaChain, interval time.Duration) *replicaRefresher {
+	return &replicaRefresher{
+		log:      common.ZenonLogger.New("submodule", "replica"),
+		chain:    chain,
+		interval: interval,
+	}
+}
+
+func (r *replicaRefresher) start() {
+	r.closed = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.loop()
+	}()
+}
+func (r *replicaRefresher) stop() {
+	close(r.closed)
+	r.wg.Wait()
+}
+
+func (r *replicaRefresher) loop() {
+	defer common.RecoverStack()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+			if err := r.chain.Refresh(); err != nil {
+				r.log.Error("failed to refresh the replica", "reason", err)
+			}
+		}
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/zenon-network/go-zenon/chain"
+	"github.com/zenon-network/go-zenon/common/db"
 	"github.com/zenon-network/go-zenon/consensus"
+	"github.com/zenon-network/go-zenon/indexer"
 	"github.com/zenon-network/go-zenon/pillar"
 	"github.com/zenon-network/go-zenon/protocol"
 	"github.com/zenon-network/go-zenon/rpc/api/subscribe"
+	"github.com/zenon-network/go-zenon/tokenmeta"
 	"github.com/zenon-network/go-zenon/verifier"
 	"github.com/zenon-network/go-zenon/vm"
+	"github.com/zenon-network/go-zenon/vm/embedded/bridge"
 )
 
 type zenon struct {
@@ -19,11 +23,22 @@ type zenon struct {
 	subscribe   *subscribe.Server
 	verifier    verifier.Verifier
 	chain       chain.Chain
+	replica     *replicaRefresher
+	compaction  *compactionScheduler
+	clock       *clockMonitor
 	pillar      pillar.Manager
+	bridge      bridge.Orchestrator
 	consensus   consensus.Consensus
 	evPrinter   EventPrinter
 	broadcaster protocol.Broadcaster
+	indexer     indexer.Indexer
+	tokenMeta   tokenmeta.Registry
+	nomManager  db.Manager
 	levelDb     *leveldb.DB
+	indexerDb   *leveldb.DB
+	tokenMetaDb *leveldb.DB
+	receiptsDb  *leveldb.DB
+	tracerDb    *leveldb.DB
 }
 
 func NewZenon(cfg *Config) (Zenon, error) {
@@ -31,22 +46,68 @@ func NewZenon(cfg *Config) (Zenon, error) {
 		config: cfg,
 	}
 
-	z.chain = chain.NewChain(cfg.NewDBManager("nom"), cfg.GenesisConfig)
+	if cfg.ReplicaSource != "" {
+		replicaChain, err := cfg.NewReplicaChain()
+		if err != nil {
+			return nil, err
+		}
+		z.chain = replicaChain
+		z.replica = newReplicaRefresher(replicaChain, cfg.ReplicaInterval)
+	} else {
+		receiptsDb, receiptsLevelDb := cfg.NewLevelDB("receipts")
+		z.nomManager = cfg.NewDBManager("nom")
+		z.chain = chain.NewChainWithCache(z.nomManager, receiptsDb, cfg.GenesisConfig, cfg.ChainCacheSize)
+		z.receiptsDb = receiptsLevelDb
+	}
 	db, levelDb := cfg.NewLevelDB("consensus")
 	z.consensus = consensus.NewConsensus(db, z.chain, false)
 	z.verifier = verifier.NewVerifier(z.chain, z.consensus)
 	z.levelDb = levelDb
 
 	chainBridge := protocol.NewChainBridge(z.chain, z.consensus, z.verifier, vm.NewSupervisor(z.chain, z.consensus))
-	z.protocol = protocol.NewProtocolManager(cfg.MinPeers, z.chain.ChainIdentifier(), chainBridge)
+	z.protocol = protocol.NewProtocolManager(cfg.MinPeers, cfg.PropagationDiversity, z.chain.ChainIdentifier(), chainBridge)
 	z.broadcaster = protocol.NewBroadcaster(z.chain, z.protocol)
 
 	z.evPrinter = NewEventPrinter(z.chain, z.broadcaster)
 	z.subscribe = subscribe.GetSubscribeServer(z.chain)
 	z.pillar = pillar.NewPillar(z.chain, z.consensus, z.broadcaster)
+	z.bridge = bridge.NewOrchestrator(z.chain, z.consensus, z.broadcaster)
+
+	if cfg.EnableIndexer {
+		db, indexerDb := cfg.NewLevelDB("indexer")
+		z.indexer = indexer.NewIndexer(db, z.chain)
+		z.indexerDb = indexerDb
+	}
+	if cfg.EnableTokenMetadata {
+		db, tokenMetaDb := cfg.NewLevelDB("tokenmeta")
+		z.tokenMeta = tokenmeta.NewRegistry(db, z.chain)
+		z.tokenMetaDb = tokenMetaDb
+	}
+	if cfg.EnableTracer {
+		db, tracerDb := cfg.NewLevelDB("tracer")
+		vm.SetTracer(vm.NewTracer(db))
+		z.tracerDb = tracerDb
+	}
 
-	if cfg.ProducingKeyPair != nil {
-		z.pillar.SetCoinBase(cfg.ProducingKeyPair)
+	if cfg.CompactionWindow != nil {
+		z.compaction = newCompactionScheduler(cfg.CompactionWindow, z.compactableDatabases)
+	}
+
+	z.clock = newClockMonitor(cfg, z.broadcaster)
+	z.pillar.SetClockGuard(z.clock.checkProduction)
+	z.pillar.SetSkipEmpty(cfg.SkipEmptyMomentums)
+	z.pillar.SetLease(cfg.ProducerLease, cfg.BackupProducer)
+	if cfg.ProducerSigner != nil {
+		z.pillar.SetSigner(cfg.ProducerSigner)
+	}
+	if cfg.RewardCollection != nil {
+		z.pillar.SetRewardCollection(cfg.RewardCollection)
+	}
+	if cfg.BridgeKeyPair != nil {
+		z.bridge.SetKeyPair(cfg.BridgeKeyPair)
+	}
+	if cfg.BridgeSigner != nil {
+		z.bridge.RegisterSigner(cfg.BridgeSigner)
 	}
 
 	return z, nil
@@ -69,6 +130,14 @@ func (z *zenon) Init() error {
 	if err := z.pillar.Init(); err != nil {
 		return err
 	}
+	if err := z.bridge.Init(); err != nil {
+		return err
+	}
+	if z.indexer != nil {
+		if err := z.indexer.Init(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -88,12 +157,54 @@ func (z *zenon) Start() error {
 	if err := z.pillar.Start(); err != nil {
 		return err
 	}
+	if err := z.bridge.Start(); err != nil {
+		return err
+	}
+	if z.indexer != nil {
+		if err := z.indexer.Start(); err != nil {
+			return err
+		}
+	}
+	if z.tokenMeta != nil {
+		if err := z.tokenMeta.Start(); err != nil {
+			return err
+		}
+	}
 	z.protocol.Start()
+	if z.replica != nil {
+		z.replica.start()
+	}
+	if z.compaction != nil {
+		z.compaction.start()
+	}
+	z.chain.Register(z.clock)
+	z.clock.start()
 
 	return nil
 }
 func (z *zenon) Stop() error {
+	z.chain.UnRegister(z.clock)
+	z.clock.stop()
+	if z.compaction != nil {
+		z.compaction.stop()
+	}
+	if z.replica != nil {
+		z.replica.stop()
+	}
 	z.protocol.Stop()
+	if z.tokenMeta != nil {
+		if err := z.tokenMeta.Stop(); err != nil {
+			return err
+		}
+	}
+	if z.indexer != nil {
+		if err := z.indexer.Stop(); err != nil {
+			return err
+		}
+	}
+	if err := z.bridge.Stop(); err != nil {
+		return err
+	}
 	if err := z.pillar.Stop(); err != nil {
 		return err
 	}
@@ -112,6 +223,30 @@ func (z *zenon) Stop() error {
 	if err := z.levelDb.Close(); err != nil {
 		return err
 	}
+	if z.receiptsDb != nil {
+		if err := z.receiptsDb.Close(); err != nil {
+			return err
+		}
+	}
+	if z.indexerDb != nil {
+		if err := z.indexerDb.Close(); err != nil {
+			return err
+		}
+	}
+	if z.tokenMetaDb != nil {
+		if err := z.tokenMetaDb.Close(); err != nil {
+			return err
+		}
+	}
+	if z.tracerDb != nil {
+		vm.SetTracer(nil)
+		if err := z.tracerDb.Close(); err != nil {
+			return err
+		}
+	}
+	if z.compaction != nil {
+		z.compaction.wait()
+	}
 
 	return nil
 }
@@ -137,3 +272,15 @@ func (z *zenon) Config() *Config {
 func (z *zenon) Broadcaster() protocol.Broadcaster {
 	return z.broadcaster
 }
+func (z *zenon) Indexer() indexer.Indexer {
+	return z.indexer
+}
+func (z *zenon) TokenMetadata() tokenmeta.Registry {
+	return z.tokenMeta
+}
+func (z *zenon) Bridge() bridge.Orchestrator {
+	return z.bridge
+}
+func (z *zenon) ClockDrift() *ClockDrift {
+	return z.clock.drift()
+}